@@ -56,6 +56,7 @@ const SETTINGS_INDEX: SearchResult[] = [
     { label: 'Transcription Provider', keywords: 'whisper deepgram openai gemini flash lite cloudflare workers ai provider api', breadcrumb: 'Config → Options → Transcription', icon: 'smart_toy', tab: 0, configSection: 'options', optionPanel: 'transcriptionExpanded' },
     { label: 'Gemini Flash-Lite', keywords: 'gemini flash lite transcription google ai studio', breadcrumb: 'Config → Options → Transcription', icon: 'auto_awesome', tab: 0, configSection: 'options', optionPanel: 'transcriptionExpanded' },
     { label: 'Cloudflare Workers AI', keywords: 'cloudflare workers ai whisper account id api token transcription', breadcrumb: 'Config → Options → Transcription', icon: 'cloud', tab: 0, configSection: 'options', optionPanel: 'transcriptionExpanded' },
+    { label: 'whisper.cpp (local)', keywords: 'whisper cpp whisper.cpp local offline model ggml threads binary transcription', breadcrumb: 'Config → Options → Transcription', icon: 'memory', tab: 0, configSection: 'options', optionPanel: 'transcriptionExpanded' },
     { label: 'Transcription Language', keywords: 'language locale transcription', breadcrumb: 'Config → Options → Transcription', icon: 'language', tab: 0, configSection: 'options', optionPanel: 'transcriptionExpanded' },
     { label: 'Worker Pool Size', keywords: 'worker pool threads concurrent transcription', breadcrumb: 'Config → Options → Transcription', icon: 'memory', tab: 0, configSection: 'options', optionPanel: 'transcriptionExpanded' },
     { label: 'Hallucination Detection', keywords: 'hallucination detect filter transcription', breadcrumb: 'Config → Options → Transcription', icon: 'psychology', tab: 0, configSection: 'options', optionPanel: 'transcriptionExpanded' },
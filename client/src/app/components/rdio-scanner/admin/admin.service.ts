@@ -22,7 +22,7 @@ import { HttpClient, HttpErrorResponse, HttpHeaders } from '@angular/common/http
 import { EventEmitter, Injectable, OnDestroy } from '@angular/core';
 import { AbstractControl, FormArray, FormBuilder, FormGroup, ValidationErrors, ValidatorFn, Validators } from '@angular/forms';
 import { MatSnackBar } from '@angular/material/snack-bar';
-import { firstValueFrom, timer, timeout, Observable, race } from 'rxjs';
+import { firstValueFrom, timer, timeout, Observable, race, Subscription } from 'rxjs';
 import { AppUpdateService } from '../../../shared/update/update.service';
 import { RdioScannerToneSet } from '../rdio-scanner';
 import type { TranscriptConfig } from './config/transcript-parser/transcript-parser.types';
@@ -160,6 +160,7 @@ export interface KeywordList {
     keywords?: string[];
     order?: number;
     createdAt?: number;
+    userGroupIds?: number[];
 }
 
 export interface CallNature {
@@ -178,7 +179,10 @@ export interface UserGroup {
     connectionLimit?: number;
     delay?: number;
     maxUsers?: number;
+    reconnectionGracePeriod?: number;
+    reconnectionMaxBufferSize?: number;
     allowAddExistingUsers?: boolean;
+    defaultLivefeedTags?: string;
     isPublicRegistration?: boolean;
     billingEnabled?: boolean;
     billingMode?: string;
@@ -269,6 +273,10 @@ export interface Log {
     level: 'error' | 'info' | 'warn' | string;
     category?: string;
     message: string;
+    callId?: number;
+    systemId?: number;
+    talkgroupId?: number;
+    context?: Record<string, unknown>;
 }
 
 export interface LogCategory {
@@ -281,10 +289,13 @@ export interface LogsQuery {
     dateStart: Date;
     dateStop: Date;
     options: LogsQueryOptions;
+    window?: LogsQueryWindow;
     logs: Log[];
 }
 
 export interface LogsQueryOptions {
+    allDates?: boolean;
+    callId?: number;
     categories?: string[];
     date?: Date;
     level?: 'error' | 'info' | 'warn';
@@ -292,6 +303,13 @@ export interface LogsQueryOptions {
     offset: number;
     search?: string;
     sort: number;
+    systemId?: number;
+}
+
+export interface LogsQueryWindow {
+    start?: string;
+    stop?: string;
+    defaultLookback: boolean;
 }
 
 export interface CallSearchResult {
@@ -324,8 +342,23 @@ export interface CallsQueryOptions {
     talkgroup?: number;
 }
 
+export interface PrunePreview {
+    pruneDays: number;
+    calls: number;
+    audioBytes: number;     // audio kept in the database
+    storedObjects: number;  // audio in external storage
+    logPruneDays: number;
+    logs: number;
+}
+
 export interface Options {
 	audioConversion?: 0 | 1 | 2 | 3;
+	audioConversionForce?: boolean;
+	audioFormat?: 'aac' | 'flac';
+	audioLoudnormTwoPass?: boolean;
+	silenceTrimEnabled?: boolean;
+	silenceTrimThreshold?: number;
+	silenceTrimDuration?: number;
 	autoPopulate?: boolean;
 	branding?: string;
 	defaultSystemDelay?: number;
@@ -343,6 +376,7 @@ export interface Options {
 	showListenersCount?: boolean;
 	sortTalkgroups?: boolean;
 	time12hFormat?: boolean;
+    defaultLivefeedTags?: string[];
     radioReferenceEnabled?: boolean;
     radioReferenceUsername?: string;
     radioReferencePassword?: string;
@@ -400,6 +434,9 @@ export interface Options {
         cloudflareAccountID?: string;
         cloudflareAPIToken?: string;
         cloudflareModel?: string;
+        whisperCppBinary?: string;
+        whisperCppModel?: string;
+        whisperCppThreads?: number;
         hallucinationPatterns?: string[];
         hallucinationDetectionMode?: string;
         hallucinationMinOccurrences?: number;
@@ -409,6 +446,7 @@ export interface Options {
         collectorAPIKey?: string;
     };
     alertRetentionDays?: number;
+    toneSetCooldownSeconds?: number;
     systemHealthAlertsEnabled?: boolean;
     transcriptionFailureAlertsEnabled?: boolean;
     transcriptionFailureThreshold?: number;
@@ -443,6 +481,9 @@ export interface Options {
     centralManagementAPIKey?: string;
     centralManagementServerName?: string;
     centralManagementServerID?: string;
+    centralManagementStrictGrants?: boolean;
+    centralManagementWebhookSecret?: string;
+    centralManagementRequireSignature?: boolean;
     /** Word lists for transcript unit/channel parsing (full admin config includes this). */
     transcriptParserConfig?: TranscriptConfig;
     openAIIntegration?: OpenAIIntegration;
@@ -490,6 +531,7 @@ export interface ToneImportResponse {
     count: number;
     toneSets: RdioScannerToneSet[];
     warnings?: string[];
+    voiceCaptureWindowSeconds?: number;
 }
 
 export interface ToneHistorySampleCall {
@@ -525,6 +567,22 @@ export interface ToneHistoryAnalyzeResponse {
     message?: string;
 }
 
+export interface ToneReplayResponse {
+    callId: number;
+    systemId: number;
+    talkgroupId: number;
+    talkgroupRef: number;
+    toneSetsTested: number;
+    tones: { frequency: number; duration: number; startTime: number; endTime: number; toneType: string; matchedToneSets: string[] }[];
+    matchedToneSets: { id: string; label: string }[];
+    log: string[];
+}
+
+export interface ToneSearchResponse {
+    callsScanned: number;
+    results: { callId: number; systemId: number; talkgroupId: number; timestamp: number; detectedTones: { frequency: number; duration: number }[]; display: string }[];
+}
+
 export interface Site {
     id?: number | null;
     label?: string;
@@ -559,6 +617,8 @@ export interface System {
     /** When true, merge heard unit ID + label from calls into this system's unit list (default off; independent of autoPopulate) */
     autoPopulateUnits?: boolean;
     transcriptionPrompt?: string;       // Custom Whisper/AssemblyAI prompt; overrides global when non-empty
+    audioFormat?: string;               // 'aac' or 'flac'; overrides the global audio format when non-empty
+    audioBitrate?: number;              // AAC bitrate in kbps; 0 = default
     autoLearnToneSets?: boolean;
     autoLearnToneSetsTagIds?: number[];
     autoLearnToneSetsAutoOffDays?: number;
@@ -609,13 +669,23 @@ export interface Talkgroup {
     linkedVoiceTalkgroupRef?: number;
     linkedVoiceWindowSeconds?: number;
     linkedVoiceMinDurationSeconds?: number;
+    linkedVoiceTalkgroupRefs?: number[] | string;
+    // Hold tone alerts open for follow-up voice on this talkgroup (0 = disabled)
+    voiceCaptureWindowSeconds?: number;
     // Admin toggle: false disables all alerts & transcription for this talkgroup
     alertsEnabled?: boolean;
     // Custom transcription prompt; overrides system and global prompts when non-empty
     transcriptionPrompt?: string;
+    // Language hint for this talkgroup's transcriptions (e.g. "es"); empty uses the global language
+    transcriptionLanguage?: string;
+    // Missed calls are protected in the reconnection buffer and delivered first
+    reconnectionPriority?: boolean;
+    // Talkgroup is known to be encrypted (from RadioReference on import)
+    encrypted?: boolean;
     autoLearnToneSets?: boolean;
     autoLearnUnitAliases?: boolean;
     alertingTalkgroup?: boolean;
+    allowDebugAudio?: boolean;
     retentionDays?: number;             // Days to retain calls; 0 = inherit system/global
     incidentMapping?: IncidentMappingConfig;
 }
@@ -641,6 +711,7 @@ enum url {
     copilotChat = 'copilot/chat',
     options = 'options',
     password = 'password',
+    prunePreview = 'prune-preview',
     purge = 'purge',
     systemhealth = 'systemhealth',
     systemNoAudioSettings = 'system-no-audio-settings',
@@ -651,6 +722,7 @@ enum url {
     noAudioMultiplier = 'no-audio-multiplier',
     systemHealthAlertsEnabled = 'system-health-alerts-enabled',
     systemHealthAlertSettings = 'system-health-alert-settings',
+    tokenRefresh = 'token/refresh',
 }
 
 const SESSION_STORAGE_KEY = 'rdio-scanner-admin-token';
@@ -674,6 +746,8 @@ export class RdioScannerAdminService implements OnDestroy {
 
     private configWebSocket: WebSocket | undefined;
 
+    private tokenRefreshSubscription: Subscription | undefined;
+
     private _docker = false;
     private _passwordNeedChange = false;
 
@@ -709,6 +783,8 @@ export class RdioScannerAdminService implements OnDestroy {
         } else {
             window?.sessionStorage?.removeItem(SESSION_STORAGE_KEY);
         }
+
+        this.tokenRefreshSchedule();
     }
 
     constructor(
@@ -718,12 +794,16 @@ export class RdioScannerAdminService implements OnDestroy {
         private ngHttpClient: HttpClient,
     ) {
         this.configWebSocketOpen();
+
+        this.tokenRefreshSchedule();
     }
 
     ngOnDestroy(): void {
         this.event.complete();
 
         this.configWebSocketClose();
+
+        this.tokenRefreshSubscription?.unsubscribe();
     }
 
     async changePassword(currentPassword: string, newPassword: string): Promise<void> {
@@ -1581,6 +1661,19 @@ export class RdioScannerAdminService implements OnDestroy {
         }
     }
 
+    async getPrunePreview(pruneDays: number, systems: { id: number; retentionDays: number }[]): Promise<PrunePreview | undefined> {
+        try {
+            return await firstValueFrom(this.ngHttpClient.post<PrunePreview>(
+                this.getUrl(url.prunePreview),
+                { pruneDays, systems },
+                { headers: this.getHeaders(), responseType: 'json' },
+            ));
+        } catch (error) {
+            this.errorHandler(error);
+            return undefined;
+        }
+    }
+
     async saveSystemRetentionSettings(systemId: number, retentionDays: number): Promise<void> {
         try {
             await firstValueFrom(this.ngHttpClient.post(
@@ -1725,7 +1818,10 @@ export class RdioScannerAdminService implements OnDestroy {
             connectionLimit: this.ngFormBuilder.control(userGroup?.connectionLimit),
             delay: this.ngFormBuilder.control(userGroup?.delay),
             maxUsers: this.ngFormBuilder.control(userGroup?.maxUsers),
+            reconnectionGracePeriod: this.ngFormBuilder.control(userGroup?.reconnectionGracePeriod || 0),
+            reconnectionMaxBufferSize: this.ngFormBuilder.control(userGroup?.reconnectionMaxBufferSize || 0),
             allowAddExistingUsers: this.ngFormBuilder.control(userGroup?.allowAddExistingUsers),
+            defaultLivefeedTags: this.ngFormBuilder.control(userGroup?.defaultLivefeedTags || ''),
             isPublicRegistration: this.ngFormBuilder.control(userGroup?.isPublicRegistration),
             billingEnabled: this.ngFormBuilder.control(userGroup?.billingEnabled),
             billingMode: this.ngFormBuilder.control(userGroup?.billingMode || ''),
@@ -1749,6 +1845,7 @@ export class RdioScannerAdminService implements OnDestroy {
             keywords: this.ngFormBuilder.control(list?.keywords || []),
             order: this.ngFormBuilder.control(list?.order || 0),
             createdAt: this.ngFormBuilder.control(list?.createdAt),
+            userGroupIds: this.ngFormBuilder.control(list?.userGroupIds || []),
         });
     }
 
@@ -1776,10 +1873,19 @@ export class RdioScannerAdminService implements OnDestroy {
             cloudflareAccountID: '',
             cloudflareAPIToken: '',
             cloudflareModel: '@cf/openai/whisper-large-v3-turbo',
+            whisperCppBinary: 'whisper-cli',
+            whisperCppModel: '',
+            whisperCppThreads: 0,
         };
         
 		return this.ngFormBuilder.group({
 		audioConversion: this.ngFormBuilder.control(options?.audioConversion),
+		audioConversionForce: this.ngFormBuilder.control(options?.audioConversionForce ?? false),
+		audioFormat: this.ngFormBuilder.control(options?.audioFormat ?? 'aac'),
+		audioLoudnormTwoPass: this.ngFormBuilder.control(options?.audioLoudnormTwoPass ?? false),
+		silenceTrimEnabled: this.ngFormBuilder.control(options?.silenceTrimEnabled ?? false),
+		silenceTrimThreshold: this.ngFormBuilder.control(options?.silenceTrimThreshold ?? -50, [Validators.required, Validators.min(-90), Validators.max(-20)]),
+		silenceTrimDuration: this.ngFormBuilder.control(options?.silenceTrimDuration ?? 500, [Validators.required, Validators.min(100), Validators.max(5000)]),
 		autoPopulate: this.ngFormBuilder.control(options?.autoPopulate),
 		branding: this.ngFormBuilder.control(options?.branding),
 			defaultSystemDelay: this.ngFormBuilder.control(options?.defaultSystemDelay ?? 0, [Validators.required, Validators.min(0)]),
@@ -1802,6 +1908,7 @@ export class RdioScannerAdminService implements OnDestroy {
             pruneDays: this.ngFormBuilder.control(options?.pruneDays ?? 0, [Validators.required, Validators.min(0)]),
             showListenersCount: this.ngFormBuilder.control(options?.showListenersCount),
             sortTalkgroups: this.ngFormBuilder.control(options?.sortTalkgroups),
+            defaultLivefeedTags: this.ngFormBuilder.control((options?.defaultLivefeedTags || []).join(', ')),
             time12hFormat: this.ngFormBuilder.control(options?.time12hFormat),
             radioReferenceEnabled: this.ngFormBuilder.control(options?.radioReferenceEnabled),
             radioReferenceUsername: this.ngFormBuilder.control(options?.radioReferenceUsername, 
@@ -1866,6 +1973,9 @@ export class RdioScannerAdminService implements OnDestroy {
                 cloudflareAccountID: this.ngFormBuilder.control(transcriptionConfig?.cloudflareAccountID || ''),
                 cloudflareAPIToken: this.ngFormBuilder.control(transcriptionConfig?.cloudflareAPIToken || ''),
                 cloudflareModel: this.ngFormBuilder.control(transcriptionConfig?.cloudflareModel || '@cf/openai/whisper-large-v3-turbo'),
+                whisperCppBinary: this.ngFormBuilder.control(transcriptionConfig?.whisperCppBinary || 'whisper-cli'),
+                whisperCppModel: this.ngFormBuilder.control(transcriptionConfig?.whisperCppModel || ''),
+                whisperCppThreads: this.ngFormBuilder.control(transcriptionConfig?.whisperCppThreads ?? 0, [Validators.min(0)]),
                 hallucinationPatterns: this.ngFormBuilder.control(
                     (transcriptionConfig?.hallucinationPatterns || []).join('\n')
                 ),
@@ -1877,6 +1987,7 @@ export class RdioScannerAdminService implements OnDestroy {
                 ),
             }),
             alertRetentionDays: this.ngFormBuilder.control(options?.alertRetentionDays || 30, [Validators.min(0)]),
+            toneSetCooldownSeconds: this.ngFormBuilder.control(options?.toneSetCooldownSeconds ?? 0, [Validators.min(0)]),
             systemHealthAlertsEnabled: this.ngFormBuilder.control(options?.systemHealthAlertsEnabled ?? false),
             transcriptionFailureAlertsEnabled: this.ngFormBuilder.control(options?.transcriptionFailureAlertsEnabled ?? false),
             transcriptionFailureThreshold: this.ngFormBuilder.control(options?.transcriptionFailureThreshold || 5, [Validators.min(1)]),
@@ -1909,6 +2020,9 @@ export class RdioScannerAdminService implements OnDestroy {
             centralManagementAPIKey: this.ngFormBuilder.control(options?.centralManagementAPIKey || ''),
             centralManagementServerName: this.ngFormBuilder.control(options?.centralManagementServerName || ''),
             centralManagementServerID: this.ngFormBuilder.control(options?.centralManagementServerID || ''),
+            centralManagementStrictGrants: this.ngFormBuilder.control(options?.centralManagementStrictGrants || false),
+            centralManagementWebhookSecret: this.ngFormBuilder.control(options?.centralManagementWebhookSecret || ''),
+            centralManagementRequireSignature: this.ngFormBuilder.control(options?.centralManagementRequireSignature || false),
             openAIIntegration: this.ngFormBuilder.group({
                 baseUrl: this.ngFormBuilder.control(
                     options?.openAIIntegration?.baseUrl
@@ -1971,6 +2085,8 @@ export class RdioScannerAdminService implements OnDestroy {
             autoPopulateAlertsEnabled: this.ngFormBuilder.control(system?.autoPopulateAlertsEnabled !== false),
             autoPopulateUnits: this.ngFormBuilder.control(system?.autoPopulateUnits === true),
             transcriptionPrompt: this.ngFormBuilder.control(system?.transcriptionPrompt || ''),
+            audioFormat: this.ngFormBuilder.control(system?.audioFormat || ''),
+            audioBitrate: this.ngFormBuilder.control(system?.audioBitrate ?? 0, [Validators.min(0), Validators.max(320)]),
             autoLearnToneSets: this.ngFormBuilder.control(system?.autoLearnToneSets || false),
             autoLearnToneSetsTagIds: this.ngFormBuilder.control(system?.autoLearnToneSetsTagIds || []),
             autoLearnToneSetsAutoOffDays: this.ngFormBuilder.control(system?.autoLearnToneSetsAutoOffDays || 0, [Validators.min(0)]),
@@ -2034,6 +2150,8 @@ export class RdioScannerAdminService implements OnDestroy {
                     downstreamEnabled: this.ngFormBuilder.control(toneSet.downstreamEnabled || false),
                     downstreamURL: this.ngFormBuilder.control(toneSet.downstreamURL || ''),
                     downstreamAPIKey: this.ngFormBuilder.control(toneSet.downstreamAPIKey || ''),
+                    cooldownSeconds: this.ngFormBuilder.control(toneSet.cooldownSeconds ?? null, Validators.min(0)),
+                    userGroupIds: this.ngFormBuilder.control(toneSet.userGroupIds || []),
                     geoCity: this.ngFormBuilder.control(toneSet.geoCity || ''),
                     geoLat: this.ngFormBuilder.control(toneSet.geoLat ?? null),
                     geoLon: this.ngFormBuilder.control(toneSet.geoLon ?? null),
@@ -2067,11 +2185,23 @@ export class RdioScannerAdminService implements OnDestroy {
             linkedVoiceTalkgroupRef: this.ngFormBuilder.control(talkgroup?.linkedVoiceTalkgroupRef || 0, Validators.min(0)),
             linkedVoiceWindowSeconds: this.ngFormBuilder.control(talkgroup?.linkedVoiceWindowSeconds || 0, Validators.min(0)),
             linkedVoiceMinDurationSeconds: this.ngFormBuilder.control(talkgroup?.linkedVoiceMinDurationSeconds || 0, Validators.min(0)),
+            linkedVoiceTalkgroupRefs: this.ngFormBuilder.control(
+                [talkgroup?.linkedVoiceTalkgroupRefs ?? []].flat().join(', '),
+                Validators.pattern(/^[\d\s,;]*$/),
+            ),
+            voiceCaptureWindowSeconds: this.ngFormBuilder.control(talkgroup?.voiceCaptureWindowSeconds || 0, [Validators.min(0), Validators.max(120)]),
             alertsEnabled: this.ngFormBuilder.control(talkgroup?.alertsEnabled !== false), // Default to true
             transcriptionPrompt: this.ngFormBuilder.control(talkgroup?.transcriptionPrompt || ''),
+            transcriptionLanguage: this.ngFormBuilder.control(
+                talkgroup?.transcriptionLanguage || '',
+                Validators.pattern(/^\s*(auto|[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,4})?)?\s*$/),
+            ),
+            reconnectionPriority: this.ngFormBuilder.control(talkgroup?.reconnectionPriority || false),
+            encrypted: this.ngFormBuilder.control(talkgroup?.encrypted || false),
             autoLearnToneSets: this.ngFormBuilder.control(talkgroup?.autoLearnToneSets || false),
             autoLearnUnitAliases: this.ngFormBuilder.control(talkgroup?.autoLearnUnitAliases || false),
             alertingTalkgroup: this.ngFormBuilder.control(talkgroup?.alertingTalkgroup || false),
+            allowDebugAudio: this.ngFormBuilder.control(talkgroup?.allowDebugAudio || false),
             retentionDays: this.ngFormBuilder.control(talkgroup?.retentionDays ?? 0, [Validators.min(0)]),
             incidentMapping: this.newIncidentMappingForm(talkgroup?.incidentMapping, { inherit: true }),
         });
@@ -2201,7 +2331,7 @@ export class RdioScannerAdminService implements OnDestroy {
         ).pipe(timeout(180000)));
     }
 
-    importToneSets(format: 'twotone' | 'csv', content: string): Observable<ToneImportResponse> {
+    importToneSets(format: 'twotone' | 'csv' | 'thinline', content: string): Observable<ToneImportResponse> {
         return this.ngHttpClient.post<ToneImportResponse>(
             '/api/admin/tone-import',
             { format, content },
@@ -2209,6 +2339,14 @@ export class RdioScannerAdminService implements OnDestroy {
         );
     }
 
+    exportToneSets(systemId: number, talkgroupId: number): Observable<Blob> {
+        return this.ngHttpClient.get('/api/admin/tone-export', {
+            headers: this.getHeaders(),
+            params: { systemId: systemId.toString(), talkgroupId: talkgroupId.toString() },
+            responseType: 'blob',
+        });
+    }
+
     syncToneSets(url: string, apiKey: string, toneSets: { id: string; label: string }[]): Observable<any> {
         return this.ngHttpClient.post<any>(
             '/api/admin/sync-tone-sets',
@@ -2225,6 +2363,22 @@ export class RdioScannerAdminService implements OnDestroy {
         ).pipe(timeout(900000));
     }
 
+    replayToneDetection(callId: number): Observable<ToneReplayResponse> {
+        return this.ngHttpClient.post<ToneReplayResponse>(
+            '/api/admin/tone-replay',
+            { callId },
+            { headers: this.getHeaders() },
+        ).pipe(timeout(120000));
+    }
+
+    searchCallsByTones(frequencies: number[], systemId?: number, talkgroupId?: number, tolerance = 10, hours = 168): Observable<ToneSearchResponse> {
+        return this.ngHttpClient.post<ToneSearchResponse>(
+            '/api/admin/tone-search',
+            { frequencies, systemId, talkgroupId, tolerance, hours },
+            { headers: this.getHeaders() },
+        ).pipe(timeout(120000));
+    }
+
     private generateToneSetId(): string {
         return `tone-set-${Date.now()}-${Math.random().toString(36).substr(2, 9)}`;
     }
@@ -2305,6 +2459,48 @@ export class RdioScannerAdminService implements OnDestroy {
         }
     }
 
+    /**
+     * Renew an expiring token (Central Management admin sessions) shortly before it
+     * lapses. Tokens without an expiry, such as password logins, are left alone.
+     */
+    private tokenRefreshSchedule(): void {
+        this.tokenRefreshSubscription?.unsubscribe();
+        this.tokenRefreshSubscription = undefined;
+
+        let exp = 0;
+        try {
+            exp = JSON.parse(atob(this.token.split('.')[1].replace(/-/g, '+').replace(/_/g, '/')))?.exp || 0;
+        } catch {
+            return;
+        }
+        if (!exp) {
+            return;
+        }
+
+        const remaining = exp * 1000 - Date.now();
+        const delay = Math.max(Math.min(remaining - 60000, remaining / 2), 0);
+
+        this.tokenRefreshSubscription = timer(delay).subscribe(() => this.tokenRefresh());
+    }
+
+    private async tokenRefresh(): Promise<void> {
+        try {
+            const res = await firstValueFrom(this.ngHttpClient.post<{ token: string }>(
+                this.getUrl(url.tokenRefresh),
+                null,
+                { headers: this.getHeaders(), responseType: 'json' },
+            ));
+
+            if (res?.token) {
+                this.token = res.token;
+            }
+
+        } catch (error) {
+            // The session reached its maximum lifetime or the token was revoked.
+            this.errorHandler(error);
+        }
+    }
+
     private getHeaders(): HttpHeaders {
         return new HttpHeaders({
             Authorization: this.token || '',
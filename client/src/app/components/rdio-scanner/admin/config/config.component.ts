@@ -21,7 +21,7 @@
 import { ChangeDetectionStrategy, ChangeDetectorRef, Component, Input, OnDestroy, OnInit, ViewChild, ViewEncapsulation } from '@angular/core';
 import { FormArray, FormControl, FormGroup } from '@angular/forms';
 import { MatSnackBar } from '@angular/material/snack-bar';
-import { AdminEvent, RdioScannerAdminService, Config, Group, Tag } from '../admin.service';
+import { AdminEvent, RdioScannerAdminService, Config, Group, Tag, UserGroup } from '../admin.service';
 import { RdioScannerAdminUsersComponent } from './users/users.component';
 import { RdioScannerAdminUserGroupsComponent } from './user-groups/user-groups.component';
 import { RdioScannerAdminOptionsComponent } from './options/options.component';
@@ -78,6 +78,11 @@ export class RdioScannerAdminConfigComponent implements OnDestroy, OnInit {
         return this.tags?.value || [];
     }
 
+    /** Raw user group values for tone set notification routing */
+    get userGroupsValue(): UserGroup[] {
+        return this.userGroups?.value || [];
+    }
+
     /** Raw apikey values for passing to the system component */
     get apikeysValue(): any[] {
         return this.apikeys?.value || [];
@@ -443,6 +448,13 @@ export class RdioScannerAdminConfigComponent implements OnDestroy, OnInit {
                         if (toneSet.locationContext) {
                             converted.locationContext = toneSet.locationContext;
                         }
+                        // Empty inherits the global cooldown; 0 turns it off for this tone set
+                        if (toneSet.cooldownSeconds != null && toneSet.cooldownSeconds !== '') {
+                            converted.cooldownSeconds = Number(toneSet.cooldownSeconds);
+                        }
+                        if (Array.isArray(toneSet.userGroupIds) && toneSet.userGroupIds.length > 0) {
+                            converted.userGroupIds = toneSet.userGroupIds;
+                        }
 
                         return converted;
                     });
@@ -20,7 +20,7 @@
 import { ChangeDetectorRef, Component, Input, OnDestroy, OnInit } from '@angular/core';
 import { FormBuilder, FormGroup, Validators } from '@angular/forms';
 import { Subscription } from 'rxjs';
-import { KeywordList, RdioScannerAdminService } from '../../admin.service';
+import { KeywordList, RdioScannerAdminService, UserGroup } from '../../admin.service';
 
 @Component({
     selector: 'rdio-scanner-admin-keyword-lists',
@@ -34,6 +34,9 @@ export class RdioScannerAdminKeywordListsComponent implements OnInit, OnDestroy
      */
     @Input() initialLists: KeywordList[] | null | undefined;
 
+    /** User groups a list's alerts can be routed to. */
+    @Input() userGroups: UserGroup[] | null | undefined;
+
     keywordLists: KeywordList[] = [];
     loading = false;
     editingIndex: number | null = null;
@@ -82,6 +85,7 @@ export class RdioScannerAdminKeywordListsComponent implements OnInit, OnDestroy
             description: list.description || '',
             keywords: list.keywords ? [...list.keywords] : [],
             order: list.order ?? 0,
+            userGroupIds: list.userGroupIds ? [...list.userGroupIds] : [],
         };
     }
 
@@ -111,6 +115,7 @@ export class RdioScannerAdminKeywordListsComponent implements OnInit, OnDestroy
             description: [list.description || ''],
             keywords: [list.keywords || []],
             order: [list.order || 0],
+            userGroupIds: [list.userGroupIds || []],
         });
         this.editingKeywords = [...(list.keywords || [])];
         const keywordsControl = this.editingForm.get('keywords');
@@ -172,6 +177,7 @@ export class RdioScannerAdminKeywordListsComponent implements OnInit, OnDestroy
             description: '',
             keywords: [],
             order: 0,
+            userGroupIds: [],
         });
         this.startEdit(0);
     }
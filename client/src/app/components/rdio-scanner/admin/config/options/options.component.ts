@@ -82,7 +82,7 @@ const DEFAULT_RELAY_PUSH_ENFORCEMENT_NOTICE =
 const OPTIONS_PANEL_DEFS: Record<OptionsPanelId, OptionsPanelDef> = {
     alerts: {
         keys: [
-            'alertRetentionDays', 'systemHealthAlertsEnabled',
+            'alertRetentionDays', 'toneSetCooldownSeconds', 'systemHealthAlertsEnabled',
             'transcriptionFailureAlertsEnabled', 'transcriptionFailureThreshold',
             'transcriptionFailureTimeWindow', 'transcriptionFailureRepeatMinutes',
             'toneDetectionAlertsEnabled', 'toneDetectionIssueThreshold',
@@ -94,7 +94,8 @@ const OPTIONS_PANEL_DEFS: Record<OptionsPanelId, OptionsPanelDef> = {
     },
     security: {
         keys: [
-            'audioConversion', 'disableDuplicateDetection', 'duplicateTimestampWindow',
+            'audioConversion', 'audioFormat', 'audioConversionForce', 'audioLoudnormTwoPass', 'silenceTrimEnabled', 'silenceTrimThreshold',
+            'silenceTrimDuration', 'disableDuplicateDetection', 'duplicateTimestampWindow',
             'duplicateDetectionTimeFrame', 'audioEncryptionEnabled', 'rateLimitingEnabled',
             'maxDownloadsPerWindow', 'downloadWindowMinutes',
         ],
@@ -125,7 +126,7 @@ const OPTIONS_PANEL_DEFS: Record<OptionsPanelId, OptionsPanelDef> = {
         keys: [
             'time12hFormat', 'autoPopulate', 'defaultSystemDelay', 'playbackGoesLive',
             'keypadBeeps', 'maxClients', 'pruneDays', 'showListenersCount', 'sortTalkgroups',
-            'reconnectionGracePeriod', 'reconnectionMaxBufferSize', 'configSyncEnabled', 'configSyncPath',
+            'defaultLivefeedTags', 'reconnectionGracePeriod', 'reconnectionMaxBufferSize', 'configSyncEnabled', 'configSyncPath',
         ],
         systemsRetention: true,
     },
@@ -142,6 +143,7 @@ const OPTIONS_PANEL_DEFS: Record<OptionsPanelId, OptionsPanelDef> = {
         keys: [
             'userRegistrationEnabled', 'publicRegistrationEnabled', 'publicRegistrationMode',
             'emailVerificationRequired', 'turnstileEnabled', 'turnstileSiteKey', 'turnstileSecretKey',
+            'centralManagementStrictGrants', 'centralManagementRequireSignature',
         ],
     },
     mapping: {
@@ -165,6 +167,7 @@ const OPTIONS_PANEL_LABELS: Record<OptionsPanelId, string> = {
 
 const OPTIONS_FIELD_LABELS: Record<string, string> = {
     alertRetentionDays: 'Alert retention days',
+    toneSetCooldownSeconds: 'Tone set repeat cooldown',
     systemHealthAlertsEnabled: 'System health alerts',
     transcriptionFailureAlertsEnabled: 'Transcription failure alerts',
     transcriptionFailureThreshold: 'Transcription failure threshold',
@@ -186,6 +189,12 @@ const OPTIONS_FIELD_LABELS: Record<string, string> = {
     noAudioThresholdMinutes: 'No-audio threshold (minutes)',
     noAudioRepeatMinutes: 'No-audio repeat interval',
     audioConversion: 'Audio conversion',
+    audioFormat: 'Audio format',
+    audioConversionForce: 'Always re-encode',
+    audioLoudnormTwoPass: 'Two-pass normalization',
+    silenceTrimEnabled: 'Silence trimming',
+    silenceTrimThreshold: 'Silence threshold (dB)',
+    silenceTrimDuration: 'Silence kept (ms)',
     disableDuplicateDetection: 'Disable duplicate detection',
     duplicateTimestampWindow: 'Duplicate timestamp window',
     duplicateDetectionTimeFrame: 'Duplicate cache retention',
@@ -229,6 +238,7 @@ const OPTIONS_FIELD_LABELS: Record<string, string> = {
     pruneDays: 'Prune days',
     showListenersCount: 'Show listeners count',
     sortTalkgroups: 'Sort talkgroups',
+    defaultLivefeedTags: 'Default livefeed tags',
     reconnectionGracePeriod: 'Reconnection grace period',
     reconnectionMaxBufferSize: 'Reconnection max buffer size',
     configSyncEnabled: 'Config sync',
@@ -257,6 +267,9 @@ const OPTIONS_FIELD_LABELS: Record<string, string> = {
     'transcriptionConfig.cloudflareAccountID': 'Cloudflare account ID',
     'transcriptionConfig.cloudflareAPIToken': 'Cloudflare API token',
     'transcriptionConfig.cloudflareModel': 'Cloudflare model',
+    'transcriptionConfig.whisperCppBinary': 'whisper.cpp binary',
+    'transcriptionConfig.whisperCppModel': 'whisper.cpp model',
+    'transcriptionConfig.whisperCppThreads': 'whisper.cpp threads',
     'transcriptionConfig.language': 'Transcription language',
     'transcriptionConfig.prompt': 'Transcription prompt',
     'transcriptionConfig.timeoutSeconds': 'Transcription timeout',
@@ -273,6 +286,8 @@ const OPTIONS_FIELD_LABELS: Record<string, string> = {
     turnstileEnabled: 'Cloudflare Turnstile',
     turnstileSiteKey: 'Turnstile site key',
     turnstileSecretKey: 'Turnstile secret key',
+    centralManagementStrictGrants: 'Strict Central Management grants',
+    centralManagementRequireSignature: 'Require signed Central Management requests',
 };
 
 export interface UnsavedPanelChanges {
@@ -319,6 +334,10 @@ export class RdioScannerAdminOptionsComponent implements OnInit, AfterViewInit,
     // Central Management Integration
     centralConnectionStatus: 'success' | 'error' | null = null;
     centralConnectionMessage: string = '';
+    pairingSecretInput: string = '';
+    pairingSecret: string = '';
+    pairingSecretExpiresAt: number = 0;
+    settingPairingSecret = false;
     showExternalAPIKey: boolean = false;
     readonly openAIChatModels = OPENAI_CHAT_MODEL_OPTIONS;
 
@@ -616,6 +635,10 @@ export class RdioScannerAdminOptionsComponent implements OnInit, AfterViewInit,
         }
 
         const def = OPTIONS_PANEL_DEFS[panelId];
+        if (def.systemsRetention && !(await this.confirmPrune(panelId))) {
+            return;
+        }
+
         const payload = this.buildPayloadForKeys(def.keys);
         this.appendSharedGeminiApiKeyPayload(panelId, payload);
 
@@ -651,6 +674,29 @@ export class RdioScannerAdminOptionsComponent implements OnInit, AfterViewInit,
         this.cdr.markForCheck();
     }
 
+    /** When retention settings changed, asks before saving what the next prune would delete. */
+    private async confirmPrune(panelId: OptionsPanelId): Promise<boolean> {
+        const baseline = JSON.parse(this.panelBaselines[panelId] || '{}') as Record<string, unknown>;
+        const current = this.snapshotPanel(panelId) as Record<string, unknown>;
+        if (baseline['pruneDays'] === current['pruneDays'] &&
+            this.valuesEqual(baseline['__systemsRetention'], current['__systemsRetention'])) {
+            return true;
+        }
+
+        const retention = current['__systemsRetention'] as { id: number; retentionDays: number }[] | undefined;
+        const systems = (retention || []).filter((s) => !!s.id);
+        const preview = await this.adminService.getPrunePreview(Number(current['pruneDays']) || 0, systems);
+        if (!preview || (preview.calls === 0 && preview.logs === 0)) {
+            return true;
+        }
+
+        let audio = `${(preview.audioBytes / 1024 ** 3).toFixed(2)} GB of audio`;
+        if (preview.storedObjects > 0) {
+            audio += `, ${preview.storedObjects} audio files in external storage`;
+        }
+        return confirm(`The next prune will delete ${preview.calls} calls (${audio}) and ${preview.logs} log entries. Save?`);
+    }
+
     private appendSharedGeminiApiKeyPayload(panelId: OptionsPanelId, payload: Record<string, any>): void {
         const def = OPTIONS_PANEL_DEFS[panelId];
         if (!def.sharedGeminiApiKey || !this.form) {
@@ -719,6 +765,11 @@ export class RdioScannerAdminOptionsComponent implements OnInit, AfterViewInit,
             }
         }
 
+        if (typeof result['defaultLivefeedTags'] === 'string') {
+            result['defaultLivefeedTags'] = result['defaultLivefeedTags']
+                .split(',').map((l: string) => l.trim()).filter((l: string) => l.length > 0);
+        }
+
         if ('relayServerAPIKey' in result) {
             result['relayServerURL'] = RELAY_SERVER_URL;
         }
@@ -2458,7 +2509,12 @@ export class RdioScannerAdminOptionsComponent implements OnInit, AfterViewInit,
     }
 
     testCentralConnection(): void {
-        const url = this.form?.get('centralManagementURL')?.value;
+        // The option may list failover URLs; test against the primary.
+        const url = String(this.form?.get('centralManagementURL')?.value || '')
+            .replace(/^\s*\[|\]\s*$/g, '')
+            .split(',')[0]
+            .replace(/["\s]/g, '')
+            .replace(/\/+$/, '');
         const apiKey = this.form?.get('centralManagementAPIKey')?.value;
 
         if (!url || !apiKey) {
@@ -2489,6 +2545,44 @@ export class RdioScannerAdminOptionsComponent implements OnInit, AfterViewInit,
         });
     }
 
+    /**
+     * Set the secret Central Management must prove knowledge of to pair with this server.
+     * Leaving the field empty has the server generate one. The raw admin password is never sent.
+     */
+    setPairingSecret(): void {
+        const token = this.adminService.getToken();
+        if (!token) {
+            this.snackBar.open('Not authenticated. Please log in again.', 'Close', { duration: 3000 });
+            return;
+        }
+
+        const headers = new HttpHeaders({
+            'Authorization': token,
+            'Content-Type': 'application/json'
+        });
+
+        this.settingPairingSecret = true;
+
+        this.http.post<{ secret: string; expires_at: number }>(
+            `${window.location.origin}/api/central-management/pairing-secret`,
+            { secret: this.pairingSecretInput.trim() },
+            { headers })
+            .subscribe({
+                next: (response) => {
+                    this.settingPairingSecret = false;
+                    this.pairingSecret = response.secret;
+                    this.pairingSecretExpiresAt = response.expires_at;
+                    this.pairingSecretInput = '';
+                    this.cdr.detectChanges();
+                },
+                error: (error) => {
+                    this.settingPairingSecret = false;
+                    this.snackBar.open(error?.error?.error || 'Failed to set the pairing secret', 'Close', { duration: 5000 });
+                    this.cdr.detectChanges();
+                }
+            });
+    }
+
     // Helper methods for array handling in templates
     getAssemblyAIWordBoostDisplay(): string {
         const wordBoost = this.form?.get('transcriptionConfig')?.get('assemblyAIWordBoost')?.value;
@@ -24,7 +24,7 @@ import { FormArray, FormControl, FormGroup } from '@angular/forms';
 import { MatSnackBar } from '@angular/material/snack-bar';
 import { MatDialog } from '@angular/material/dialog';
 import { Subscription } from 'rxjs';
-import { RdioScannerAdminService, Group, Tag } from '../../../admin.service';
+import { RdioScannerAdminService, Group, Tag, UserGroup } from '../../../admin.service';
 import { ToneSetLocationDialogComponent } from './tone-set-location-dialog.component';
 import { TalkgroupLocationDialogComponent } from './talkgroup-location-dialog.component';
 
@@ -38,6 +38,7 @@ export class RdioScannerAdminSystemComponent implements OnInit, OnChanges, OnDes
     @Input() form = new FormGroup({});
     @Input() groups: Group[] = [];
     @Input() tags: Tag[] = [];
+    @Input() userGroups: UserGroup[] = [];
     @Input() apikeys: any[] = [];
     @Input() systemData: any; // Original system data for lazy loading
     @Input() saving = false;
@@ -22,7 +22,7 @@ import { AbstractControl, FormArray, FormBuilder, FormGroup, Validators } from '
 import { MatSelectChange } from '@angular/material/select';
 import { MatSnackBar } from '@angular/material/snack-bar';
 import { finalize } from 'rxjs/operators';
-import { RdioScannerAdminService, Group, Tag, ToneHistoryAnalyzeResponse, ToneHistorySuggestion } from '../../../admin.service';
+import { RdioScannerAdminService, Group, Tag, ToneHistoryAnalyzeResponse, ToneHistorySuggestion, ToneReplayResponse, ToneSearchResponse, UserGroup } from '../../../admin.service';
 import { RdioScannerToneSet } from '../../../../rdio-scanner';
 
 @Component({
@@ -35,6 +35,7 @@ export class RdioScannerAdminTalkgroupComponent {
     @Input() form: FormGroup | undefined;
     @Input() groups: Group[] = [];
     @Input() tags: Tag[] = [];
+    @Input() userGroups: UserGroup[] = [];
 
     @Output() blacklist = new EventEmitter<void>();
 
@@ -42,8 +43,12 @@ export class RdioScannerAdminTalkgroupComponent {
 
     @ViewChild('twoToneFileInput') twoToneFileInput?: ElementRef<HTMLInputElement>;
     @ViewChild('csvFileInput') csvFileInput?: ElementRef<HTMLInputElement>;
+    @ViewChild('thinlineFileInput') thinlineFileInput?: ElementRef<HTMLInputElement>;
 
     importingToneSets = false;
+    exportingToneSets = false;
+    // Imported tone sets either update same-label sets in place or replace the whole list.
+    toneImportMode: 'merge' | 'replace' = 'merge';
     syncingToneSets   = false;
     syncToneSetsStatus = '';
     syncSelectedIds   = new Set<string>();
@@ -61,6 +66,16 @@ export class RdioScannerAdminTalkgroupComponent {
     toneHistoryCallsRequired = 3;
     toneHistoryStats: Pick<ToneHistoryAnalyzeResponse, 'callsScanned' | 'callsWithTones' | 'callsWithCandidates' | 'discoverErrors' | 'patternsBelowThreshold' | 'lookbackHours'> | null = null;
 
+    replayCallId: number | null = null;
+    replayingTones = false;
+    toneReplayError = '';
+    toneReplayResult: ToneReplayResponse | null = null;
+
+    toneSearchFrequencies = '';
+    searchingTones = false;
+    toneSearchError = '';
+    toneSearchResult: ToneSearchResponse | null = null;
+
     get apikeys(): any[] {
         return this.form?.root.get('apikeys')?.value as any[] || [];
     }
@@ -93,7 +108,14 @@ export class RdioScannerAdminTalkgroupComponent {
 
     addToneSet(toneSet?: Partial<RdioScannerToneSet>, expand = false): void {
         const id = toneSet?.id || this.generateToneSetId();
-        const toneSetForm = this.formBuilder.group({
+        this.getToneSets().push(this.newToneSetForm(id, toneSet));
+        if (expand) {
+            this.expandedToneSets.add(id);
+        }
+    }
+
+    private newToneSetForm(id: string, toneSet?: Partial<RdioScannerToneSet>): FormGroup {
+        return this.formBuilder.group({
             id: [id],
             label: [toneSet?.label || '', Validators.required],
             aToneFrequency: [toneSet?.aTone?.frequency ?? null],
@@ -110,16 +132,14 @@ export class RdioScannerAdminTalkgroupComponent {
             downstreamEnabled: [(toneSet as any)?.downstreamEnabled ?? false],
             downstreamURL: [(toneSet as any)?.downstreamURL ?? ''],
             downstreamAPIKey: [(toneSet as any)?.downstreamAPIKey ?? ''],
+            cooldownSeconds: [toneSet?.cooldownSeconds ?? null, Validators.min(0)],
+            userGroupIds: [toneSet?.userGroupIds ?? []],
             geoCity: [toneSet?.geoCity ?? ''],
             geoLat: [toneSet?.geoLat ?? null],
             geoLon: [toneSet?.geoLon ?? null],
             geoRadiusMiles: [toneSet?.geoRadiusMiles ?? null],
             locationContext: [toneSet?.locationContext ?? ''],
         });
-        this.getToneSets().push(toneSetForm);
-        if (expand) {
-            this.expandedToneSets.add(id);
-        }
     }
 
     removeToneSet(index: number): void {
@@ -174,11 +194,43 @@ export class RdioScannerAdminTalkgroupComponent {
     triggerToneImport(format: ToneImportFormat): void {
         if (format === 'twotone') {
             this.twoToneFileInput?.nativeElement.click();
+        } else if (format === 'thinline') {
+            this.thinlineFileInput?.nativeElement.click();
         } else {
             this.csvFileInput?.nativeElement.click();
         }
     }
 
+    exportToneSets(): void {
+        const talkgroupId = this.form?.get('id')?.value;
+        const systemId = this.systemId;
+        if (!talkgroupId || !systemId) {
+            this.snackBar.open('Save the talkgroup before exporting its tone sets', '', { duration: 4000 });
+            return;
+        }
+
+        this.exportingToneSets = true;
+        this.adminService.exportToneSets(systemId, talkgroupId)
+            .pipe(finalize(() => {
+                this.exportingToneSets = false;
+                this.cdr.markForCheck();
+            }))
+            .subscribe({
+                next: (blob) => {
+                    const label = (this.form?.get('label')?.value || `talkgroup-${talkgroupId}`).replace(/[^A-Za-z0-9._-]+/g, '-');
+                    const url = window.URL.createObjectURL(blob);
+                    const a = document.createElement('a');
+                    a.href = url;
+                    a.download = `${label}-tone-sets.json`;
+                    a.click();
+                    window.URL.revokeObjectURL(url);
+                },
+                error: () => {
+                    this.snackBar.open('Failed to export tone sets', '', { duration: 5000 });
+                },
+            });
+    }
+
     async handleToneImport(event: Event, format: ToneImportFormat): Promise<void> {
         const input = event.target as HTMLInputElement;
         const file = input?.files?.[0];
@@ -195,6 +247,12 @@ export class RdioScannerAdminTalkgroupComponent {
             return;
         }
 
+        if (this.toneImportMode === 'replace' && this.getToneSets().length > 0 &&
+            !confirm('Replace all existing tone sets on this talkgroup with the imported ones?')) {
+            input.value = '';
+            return;
+        }
+
         this.importingToneSets = true;
         this.adminService.importToneSets(format, content)
             .pipe(finalize(() => {
@@ -208,7 +266,10 @@ export class RdioScannerAdminTalkgroupComponent {
                     const imported = response?.toneSets || [];
                     if (imported.length > 0) {
                         this.appendImportedToneSets(imported);
-                        const label = format === 'twotone' ? 'TwoToneDetect' : 'CSV';
+                        if (response.voiceCaptureWindowSeconds && !this.form?.get('voiceCaptureWindowSeconds')?.value) {
+                            this.form?.get('voiceCaptureWindowSeconds')?.setValue(response.voiceCaptureWindowSeconds);
+                        }
+                        const label = format === 'twotone' ? 'TwoToneDetect' : format === 'thinline' ? 'tone set file' : 'CSV';
                         this.snackBar.open(`Imported ${imported.length} tone set${imported.length === 1 ? '' : 's'} from ${label}`, '', { duration: 4000 });
                     } else {
                         this.snackBar.open('No tone sets were found in the selected file', '', { duration: 5000 });
@@ -352,6 +413,65 @@ export class RdioScannerAdminTalkgroupComponent {
             });
     }
 
+    replayToneDetection(): void {
+        const callId = Number(this.replayCallId);
+        if (!callId || this.replayingTones) {
+            return;
+        }
+
+        this.replayingTones = true;
+        this.toneReplayError = '';
+        this.toneReplayResult = null;
+        this.cdr.markForCheck();
+
+        this.adminService.replayToneDetection(callId)
+            .pipe(finalize(() => {
+                this.replayingTones = false;
+                this.cdr.markForCheck();
+            }))
+            .subscribe({
+                next: (response) => {
+                    this.toneReplayResult = response;
+                    this.cdr.markForCheck();
+                },
+                error: (error) => {
+                    this.toneReplayError = error?.error?.error || 'Tone replay failed';
+                    this.cdr.markForCheck();
+                },
+            });
+    }
+
+    searchCallsByTones(): void {
+        const frequencies = this.toneSearchFrequencies
+            .split(/[,\/\s]+/)
+            .map((value) => parseFloat(value))
+            .filter((value) => !isNaN(value) && value > 0);
+        if (!frequencies.length || this.searchingTones) {
+            return;
+        }
+
+        this.searchingTones = true;
+        this.toneSearchError = '';
+        this.toneSearchResult = null;
+        this.cdr.markForCheck();
+
+        this.adminService.searchCallsByTones(frequencies, this.systemId, this.form?.get('id')?.value || undefined)
+            .pipe(finalize(() => {
+                this.searchingTones = false;
+                this.cdr.markForCheck();
+            }))
+            .subscribe({
+                next: (response) => {
+                    this.toneSearchResult = response;
+                    this.cdr.markForCheck();
+                },
+                error: (error) => {
+                    this.toneSearchError = error?.error?.error || 'Tone search failed';
+                    this.cdr.markForCheck();
+                },
+            });
+    }
+
     addSuggestedToneSet(suggestion: ToneHistorySuggestion): void {
         if (!this.form || !suggestion?.toneSet) {
             return;
@@ -376,7 +496,37 @@ export class RdioScannerAdminTalkgroupComponent {
             this.form.get('toneDetectionEnabled')?.setValue(true);
         }
 
-        toneSets.forEach((toneSet) => this.addToneSet(toneSet));
+        const toneSetsArray = this.getToneSets();
+        if (this.toneImportMode === 'replace') {
+            toneSetsArray.clear();
+            this.expandedToneSets.clear();
+        }
+
+        toneSets.forEach((toneSet) => {
+            const label = (toneSet.label || '').trim().toLowerCase();
+            const index = label
+                ? toneSetsArray.controls.findIndex((ctrl) => (ctrl.get('label')?.value || '').trim().toLowerCase() === label)
+                : -1;
+            if (index < 0) {
+                this.addToneSet(toneSet);
+                return;
+            }
+
+            // Keep the local id and agency-specific routing when updating a same-label set.
+            const existing = toneSetsArray.at(index).value;
+            const merged: Partial<RdioScannerToneSet> = {
+                ...toneSet,
+                id: existing.id,
+                userGroupIds: existing.userGroupIds,
+                downstreamAPIKey: existing.downstreamAPIKey,
+            };
+            if (!toneSet.downstreamURL) {
+                merged.downstreamEnabled = existing.downstreamEnabled;
+                merged.downstreamURL = existing.downstreamURL;
+            }
+            toneSetsArray.setControl(index, this.newToneSetForm(existing.id, merged));
+        });
+        this.cdr.markForCheck();
     }
 
     private generateToneSetId(): string {
@@ -384,4 +534,4 @@ export class RdioScannerAdminTalkgroupComponent {
     }
 }
 
-type ToneImportFormat = 'twotone' | 'csv';
+type ToneImportFormat = 'twotone' | 'csv' | 'thinline';
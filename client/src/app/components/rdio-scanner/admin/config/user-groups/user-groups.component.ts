@@ -40,6 +40,8 @@ interface UserGroup {
   talkgroupDelays: string;
   connectionLimit: number;
   maxUsers: number;
+  reconnectionGracePeriod?: number;
+  reconnectionMaxBufferSize?: number;
   billingEnabled: boolean;
   stripePriceId: string;
   pricingOptions?: PricingOption[];
@@ -49,6 +51,7 @@ interface UserGroup {
   stripeTaxRateId?: string;
   isPublicRegistration: boolean;
   allowAddExistingUsers: boolean;
+  defaultLivefeedTags?: string;
   createdAt: number;
 }
 
@@ -150,6 +153,8 @@ export class RdioScannerAdminUserGroupsComponent implements OnInit, OnChanges {
       talkgroupDelays: [''], // Will be converted to JSON map
       connectionLimit: [0],
       maxUsers: [0],
+      reconnectionGracePeriod: [0], // 0 = use the server-wide setting
+      reconnectionMaxBufferSize: [0],
       billingEnabled: [false],
       stripePriceId: [''],
       pricingOptions: this.fb.array([]),
@@ -159,6 +164,7 @@ export class RdioScannerAdminUserGroupsComponent implements OnInit, OnChanges {
       stripeTaxRateId: [''],
       isPublicRegistration: [false],
       allowAddExistingUsers: [false],
+      defaultLivefeedTags: [''], // Comma-separated in the form, JSON array on the server
       groupAdminUserId: [0],
       newGroupAdminEmail: [''],
       newGroupAdminPassword: [''],
@@ -215,6 +221,8 @@ export class RdioScannerAdminUserGroupsComponent implements OnInit, OnChanges {
         talkgroupDelays: group.talkgroupDelays || '',
         connectionLimit: group.connectionLimit || 0,
         maxUsers: group.maxUsers || 0,
+        reconnectionGracePeriod: group.reconnectionGracePeriod || 0,
+        reconnectionMaxBufferSize: group.reconnectionMaxBufferSize || 0,
         billingEnabled: group.billingEnabled || false,
         stripePriceId: group.stripePriceId || '',
         pricingOptions: group.pricingOptions || [],
@@ -224,6 +232,7 @@ export class RdioScannerAdminUserGroupsComponent implements OnInit, OnChanges {
         stripeTaxRateId: group.stripeTaxRateId || '',
         isPublicRegistration: group.isPublicRegistration || false,
         allowAddExistingUsers: group.allowAddExistingUsers || false,
+        defaultLivefeedTags: group.defaultLivefeedTags || '',
         createdAt: group.createdAt || 0
       }));
       this.cdr.detectChanges();
@@ -439,8 +448,11 @@ export class RdioScannerAdminUserGroupsComponent implements OnInit, OnChanges {
       talkgroupDelays: '',
       connectionLimit: 0,
       maxUsers: 0,
+      reconnectionGracePeriod: 0,
+      reconnectionMaxBufferSize: 0,
       billingEnabled: false,
       isPublicRegistration: false,
+      defaultLivefeedTags: '',
       groupAdminUserId: 0,
       newGroupAdminEmail: '',
       newGroupAdminPassword: '',
@@ -623,6 +635,14 @@ export class RdioScannerAdminUserGroupsComponent implements OnInit, OnChanges {
     
     // Patch the form (excluding pricingOptions which we'll handle separately)
     this.groupForm.patchValue(groupForPatch);
+
+    // Default livefeed tags are stored as a JSON array - show them comma-separated
+    try {
+      const tags = (group.defaultLivefeedTags || '').trim() ? JSON.parse(group.defaultLivefeedTags!) : [];
+      this.groupForm.patchValue({ defaultLivefeedTags: Array.isArray(tags) ? tags.join(', ') : '' });
+    } catch (e) {
+      this.groupForm.patchValue({ defaultLivefeedTags: group.defaultLivefeedTags || '' });
+    }
     
     // Parse system access JSON - support both legacy (array of IDs) and new format (array of objects)
     try {
@@ -801,6 +821,12 @@ export class RdioScannerAdminUserGroupsComponent implements OnInit, OnChanges {
     groupData.talkgroupDelays = Object.keys(talkgroupDelaysMap).length > 0
       ? JSON.stringify(talkgroupDelaysMap)
       : '';
+
+    // Convert default livefeed tags to a JSON array of tag labels
+    const livefeedTags = (groupData.defaultLivefeedTags || '').split(',')
+      .map((tag: string) => tag.trim())
+      .filter((tag: string) => tag.length > 0);
+    groupData.defaultLivefeedTags = livefeedTags.length > 0 ? JSON.stringify(livefeedTags) : '';
     
     if (groupData.id > 0) {
       // Update existing group
@@ -18,7 +18,7 @@
  */
 
 import { Component, Input, OnInit, OnChanges, SimpleChanges, ChangeDetectorRef } from '@angular/core';
-import { FormGroup, FormBuilder, FormControl } from '@angular/forms';
+import { AbstractControl, FormGroup, FormBuilder, FormControl } from '@angular/forms';
 import { HttpClient, HttpHeaders } from '@angular/common/http';
 
 @Component({
@@ -52,6 +52,34 @@ export class RdioScannerAdminUserRegistrationComponent implements OnInit, OnChan
     return options?.get('centralManagementEnabled')?.value === true;
   }
 
+  get strictCentralGrants(): boolean {
+    return this.optionsTarget()?.get('centralManagementStrictGrants')?.value === true;
+  }
+
+  setStrictCentralGrants(enabled: boolean): void {
+    const control = this.optionsTarget()?.get('centralManagementStrictGrants');
+    control?.setValue(enabled);
+    control?.markAsDirty();
+  }
+
+  get hasCentralWebhookSecret(): boolean {
+    return !!this.optionsTarget()?.get('centralManagementWebhookSecret')?.value;
+  }
+
+  get requireCentralSignature(): boolean {
+    return this.optionsTarget()?.get('centralManagementRequireSignature')?.value === true;
+  }
+
+  setRequireCentralSignature(enabled: boolean): void {
+    const control = this.optionsTarget()?.get('centralManagementRequireSignature');
+    control?.setValue(enabled);
+    control?.markAsDirty();
+  }
+
+  private optionsTarget(): AbstractControl | null {
+    return this.form.get('userRegistrationEnabled') !== null ? this.form : this.form.get('options');
+  }
+
   // ── Leave Central Management ──────────────────────────────────────────────
   showLeaveCMForm = false;
   leaveCMCode = '';
@@ -84,10 +112,7 @@ export class RdioScannerAdminUserRegistrationComponent implements OnInit, OnChan
           this.leavingCM = false;
           this.showLeaveCMForm = false;
           // Clear the centralManagementEnabled flag in the form so the UI updates
-          const target = this.form.get('userRegistrationEnabled') !== null
-            ? this.form
-            : this.form.get('options');
-          target?.get('centralManagementEnabled')?.setValue(false);
+          this.optionsTarget()?.get('centralManagementEnabled')?.setValue(false);
           this.cdr.detectChanges();
         },
         error: (err) => {
@@ -60,6 +60,7 @@ export class RdioScannerAdminLogsComponent implements OnInit {
 
     constructor(private adminService: RdioScannerAdminService, private ngFormBuilder: FormBuilder) {
         this.form = this.ngFormBuilder.group({
+            allDates: [false],
             level: [null],
             search: [''],
             sort: [-1],
@@ -195,6 +196,20 @@ export class RdioScannerAdminLogsComponent implements OnInit {
         }
     }
 
+    contextTooltip(log: Log): string {
+        const parts: string[] = [];
+        if (log.systemId) {
+            parts.push(`system ${log.systemId}`);
+        }
+        if (log.talkgroupId) {
+            parts.push(`talkgroup ${log.talkgroupId}`);
+        }
+        for (const [key, value] of Object.entries(log.context ?? {})) {
+            parts.push(`${key}: ${value}`);
+        }
+        return parts.join(', ');
+    }
+
     private buildFilterDate(): Date | undefined {
         if (!this.selectedDate) {
             return undefined;
@@ -210,6 +225,12 @@ export class RdioScannerAdminLogsComponent implements OnInit {
         );
     }
 
+    searchAllDates(): void {
+        this.form.get('allDates')?.setValue(true);
+        this.form.markAsDirty();
+        this.formHandler();
+    }
+
     formHandler(): void {
         this.paginator?.firstPage();
         void this.reload();
@@ -221,6 +242,7 @@ export class RdioScannerAdminLogsComponent implements OnInit {
         this.selectedCategories.clear();
 
         this.form.reset({
+            allDates: false,
             level: null,
             search: '',
             sort: -1,
@@ -281,6 +303,8 @@ export class RdioScannerAdminLogsComponent implements OnInit {
         const filterDate = this.buildFilterDate();
         if (filterDate) {
             options.date = filterDate;
+        } else if (this.form.get('allDates')?.value) {
+            options.allDates = true;
         }
 
         this.logsQueryPending = true;
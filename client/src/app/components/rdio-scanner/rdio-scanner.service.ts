@@ -127,6 +127,7 @@ export class RdioScannerService implements OnDestroy {
     private instanceId = 'default';
 
     private livefeedMap = {} as RdioScannerLivefeedMap;
+    private livefeedMapStored = false;
     private livefeedMapPriorToHoldSystem: RdioScannerLivefeedMap | undefined;
     private livefeedMapPriorToHoldTalkgroup: RdioScannerLivefeedMap | undefined;
     private livefeedMode = RdioScannerLivefeedMode.Offline;
@@ -1460,6 +1461,7 @@ export class RdioScannerService implements OnDestroy {
                         groups: typeof config.groups !== null && typeof config.groups === 'object' ? config.groups : {},
                         groupsData: Array.isArray(config.groupsData) ? config.groupsData : [],
                         keypadBeeps: config.keypadBeeps !== null && typeof config.keypadBeeps === 'object' ? config.keypadBeeps : {},
+                        livefeedDefaults: config.livefeedDefaults !== null && typeof config.livefeedDefaults === 'object' ? config.livefeedDefaults : undefined,
                         options: typeof config.options === 'object' && config.options !== null ? config.options : undefined,
                         playbackGoesLive: typeof config.playbackGoesLive === 'boolean' ? config.playbackGoesLive : false,
                         showListenersCount: typeof config.showListenersCount === 'boolean' ? config.showListenersCount : false,
@@ -1753,6 +1755,9 @@ export class RdioScannerService implements OnDestroy {
                 }
             }
 
+            // An empty saved map (e.g. saved before any systems were visible) counts as fresh
+            this.livefeedMapStored = Object.keys(lfm ?? {}).length > 0;
+
             Object.keys(lfm ?? {}).forEach((sys: string) => {
                 Object.keys(lfm[+sys]).forEach((tg) => {
                     if (!this.livefeedMap[+sys]) this.livefeedMap[+sys] = {};
@@ -1785,6 +1790,9 @@ export class RdioScannerService implements OnDestroy {
     }
 
     private rebuildLivefeedMap(): void {
+        // A client with no saved map starts from the admin's default tags, if any
+        const defaults = this.livefeedMapStored ? undefined : this.config.livefeedDefaults;
+
         const lfm = this.config.systems.reduce((sysMap, sys) => {
             sysMap[sys.id] = sys.talkgroups.reduce((tgMap, tg) => {
                 const group = this.categories.find((cat) => tg.groups.includes(cat.label));
@@ -1799,8 +1807,9 @@ export class RdioScannerService implements OnDestroy {
                     ? this.livefeedMap[sys.id][tg.id]
                     : {
                         // NEW FIX: Default to inactive (false) for new talkgroups/systems
-                        // Users must manually enable them in Channel Select
-                        active: false,
+                        // Users must manually enable them in Channel Select, except that
+                        // a fresh client starts with its default-tag talkgroups enabled
+                        active: defaults?.[sys.id]?.[tg.id] === true,
                     } as RdioScannerLivefeed;
 
                 return tgMap;
@@ -1817,6 +1826,7 @@ export class RdioScannerService implements OnDestroy {
         }
 
         this.saveLivefeedMap();
+        this.livefeedMapStored = this.livefeedMapStored || Object.keys(lfm).length > 0;
 
         this.rebuildCategories();
     }
@@ -68,6 +68,7 @@ export interface RdioScannerCall {
     };
     hasTones?: boolean;
     toneSequence?: RdioScannerToneSequence;
+    detectedTones?: { frequency: number; duration: number }[];
     transcript?: string;
     transcriptConfidence?: number;
     transcriptionStatus?: string;
@@ -98,6 +99,11 @@ export interface RdioScannerToneSet {
     geoLon?: number;
     geoRadiusMiles?: number;
     locationContext?: string;
+    cooldownSeconds?: number;
+    userGroupIds?: number[];
+    downstreamEnabled?: boolean;
+    downstreamURL?: string;
+    downstreamAPIKey?: string;
 }
 
 export interface RdioScannerToneSpec {
@@ -119,6 +125,7 @@ export interface RdioScannerAlert {
     alertSummary?: string;
     transcriptionStatus?: string;
     transcriptAnnotations?: import('./transcript-utils').TranscriptAnnotation[];
+    detectedTones?: string; // e.g. "1122.5 Hz / 1345.0 Hz"
     createdAt: number;
     systemLabel?: string;
     talkgroupLabel?: string;
@@ -228,6 +235,8 @@ export interface RdioScannerConfig {
     groups: { [key: string]: { [key: number]: number[] } };
     groupsData: RdioScannerGroupData[];
     keypadBeeps: RdioScannerKeypadBeeps | undefined;
+    /** Talkgroups enabled by default for a client with no saved livefeed map (from the admin's default tags). */
+    livefeedDefaults?: { [key: number]: { [key: number]: boolean } };
     options?: {
         userRegistrationEnabled?: boolean;
         stripePaywallEnabled?: boolean;
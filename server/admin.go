@@ -100,6 +100,7 @@ type Admin struct {
 	Register         chan *websocket.Conn
 	Tokens           []string
 	Unregister       chan *websocket.Conn
+	cmTokens         []string // Central Management tokens in Tokens, oldest first
 	mutex            sync.Mutex
 	running          bool
 }
@@ -1151,6 +1152,84 @@ func (admin *Admin) SystemRetentionSettingsHandler(w http.ResponseWriter, r *htt
 	})
 }
 
+// PrunePreviewHandler reports what the next prune would delete with the given
+// pruneDays and per-system retention, before an admin saves them. Omitted
+// values keep the current settings. Nothing is deleted.
+func (admin *Admin) PrunePreviewHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		PruneDays *uint `json:"pruneDays"`
+		Systems   []struct {
+			Id            uint64 `json:"id"`
+			RetentionDays uint   `json:"retentionDays"`
+		} `json:"systems"`
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	pruneDays := admin.Controller.Options.PruneDays
+	if request.PruneDays != nil {
+		pruneDays = *request.PruneDays
+	}
+
+	systemDays := map[uint64]uint{}
+	for _, system := range request.Systems {
+		systemDays[system.Id] = system.RetentionDays
+	}
+
+	calls, err := admin.Controller.Calls.PruneCount(admin.Controller.Database, pruneDays, systemDays)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": fmt.Sprintf("failed to count calls: %v", err),
+		})
+		return
+	}
+
+	// Logs follow log_prune_days when set, as in Scheduler.pruneLogs
+	logPruneDays := admin.Controller.Config.LogPruneDays
+	if logPruneDays == 0 {
+		logPruneDays = pruneDays
+	}
+	var logs int64
+	if logPruneDays > 0 {
+		if logs, err = admin.Controller.Logs.PruneCount(admin.Controller.Database, logPruneDays); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": fmt.Sprintf("failed to count logs: %v", err),
+			})
+			return
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]any{
+		"pruneDays":     pruneDays,
+		"calls":         calls.Calls,
+		"audioBytes":    calls.AudioBytes,
+		"storedObjects": calls.StoredObjects,
+		"logPruneDays":  logPruneDays,
+		"logs":          logs,
+	})
+}
+
 // SystemDuplicateDetectionSettingsHandler handles updating per-system duplicate detection toggles.
 func (admin *Admin) SystemDuplicateDetectionSettingsHandler(w http.ResponseWriter, r *http.Request) {
 	t := admin.GetAuthorization(r)
@@ -1211,7 +1290,7 @@ func (admin *Admin) CallAudioHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if r.Method != http.MethodGet {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
@@ -1253,13 +1332,10 @@ func (admin *Admin) CallAudioHandler(w http.ResponseWriter, r *http.Request) {
 		mimeType = "audio/wav" // Default to WAV if not specified
 	}
 
-	w.Header().Set("Content-Type", mimeType)
-	w.Header().Set("Content-Length", strconv.Itoa(len(call.Audio)))
 	w.Header().Set("Cache-Control", "private, max-age=3600")
 	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"call-%d.%s\"", callId, getAudioExtension(mimeType)))
 
-	// Write audio data
-	w.Write(call.Audio)
+	serveCallAudio(w, r, callId, call.Audio, mimeType, call.Timestamp)
 }
 
 // getAudioExtension returns file extension based on MIME type
@@ -1275,6 +1351,8 @@ func getAudioExtension(mimeType string) string {
 		return "aac"
 	case "audio/m4a", "audio/mp4":
 		return "m4a"
+	case "audio/flac":
+		return "flac"
 	default:
 		return "wav"
 	}
@@ -1312,6 +1390,8 @@ func (admin *Admin) ToneImportHandler(w http.ResponseWriter, r *http.Request) {
 		Count:    len(result.toneSets),
 		ToneSets: result.toneSets,
 		Warnings: result.warnings,
+
+		VoiceCaptureWindowSeconds: result.voiceCaptureWindowSeconds,
 	}
 
 	if b, err := json.Marshal(response); err == nil {
@@ -1818,6 +1898,8 @@ func (admin *Admin) ConfigHandler(w http.ResponseWriter, r *http.Request) {
 					_, hasThreshold := m["noAudioThresholdMinutes"]
 					_, hasRetention := m["retentionDays"]
 					_, hasDuplicateDetection := m["duplicateDetectionEnabled"]
+					_, hasAudioFormat := m["audioFormat"]
+					_, hasAudioBitrate := m["audioBitrate"]
 					// Try to find the matching existing system by id, then by systemRef
 					var existing *System
 					if idVal, ok := m["id"].(float64); ok {
@@ -1841,6 +1923,12 @@ func (admin *Admin) ConfigHandler(w http.ResponseWriter, r *http.Request) {
 						if !hasDuplicateDetection {
 							m["duplicateDetectionEnabled"] = existing.DuplicateDetectionEnabled
 						}
+						if !hasAudioFormat {
+							m["audioFormat"] = existing.AudioFormat
+						}
+						if !hasAudioBitrate {
+							m["audioBitrate"] = float64(existing.AudioBitrate)
+						}
 					}
 
 					if tgs, ok := m["talkgroups"].([]any); ok && existing != nil {
@@ -1973,6 +2061,9 @@ func (admin *Admin) ConfigHandler(w http.ResponseWriter, r *http.Request) {
 						existingGroup.StripeTaxRateId = getStringFromMap(groupMap, "stripeTaxRateId")
 						existingGroup.IsPublicRegistration = getBoolFromMap(groupMap, "isPublicRegistration", false)
 						existingGroup.AllowAddExistingUsers = getBoolFromMap(groupMap, "allowAddExistingUsers", false)
+						existingGroup.ReconnectionGracePeriod = uint(getFloat64FromMap(groupMap, "reconnectionGracePeriod"))
+						existingGroup.ReconnectionMaxBufferSize = uint(getFloat64FromMap(groupMap, "reconnectionMaxBufferSize"))
+						existingGroup.DefaultLivefeedTags = getStringFromMap(groupMap, "defaultLivefeedTags")
 						if createdAt, ok := groupMap["createdAt"].(float64); ok {
 							existingGroup.CreatedAt = int64(createdAt)
 						}
@@ -1988,23 +2079,26 @@ func (admin *Admin) ConfigHandler(w http.ResponseWriter, r *http.Request) {
 					} else {
 						// Create new group
 						group := &UserGroup{
-							Name:                  name,
-							Description:           getStringFromMap(groupMap, "description"),
-							SystemAccess:          getStringFromMap(groupMap, "systemAccess"),
-							Delay:                 int(getFloat64FromMap(groupMap, "delay")),
-							SystemDelays:          getStringFromMap(groupMap, "systemDelays"),
-							TalkgroupDelays:       getStringFromMap(groupMap, "talkgroupDelays"),
-							ConnectionLimit:       uint(getFloat64FromMap(groupMap, "connectionLimit")),
-							MaxUsers:              uint(getFloat64FromMap(groupMap, "maxUsers")),
-							BillingEnabled:        getBoolFromMap(groupMap, "billingEnabled", false),
-							StripePriceId:         getStringFromMap(groupMap, "stripePriceId"),
-							PricingOptions:        getStringFromMap(groupMap, "pricingOptions"),
-							BillingMode:           getStringFromMap(groupMap, "billingMode"),
-							CollectSalesTax:       getBoolFromMap(groupMap, "collectSalesTax", false),
-							TaxMode:               getStringFromMap(groupMap, "taxMode"),
-							StripeTaxRateId:       getStringFromMap(groupMap, "stripeTaxRateId"),
-							IsPublicRegistration:  getBoolFromMap(groupMap, "isPublicRegistration", false),
-							AllowAddExistingUsers: getBoolFromMap(groupMap, "allowAddExistingUsers", false),
+							Name:                      name,
+							Description:               getStringFromMap(groupMap, "description"),
+							SystemAccess:              getStringFromMap(groupMap, "systemAccess"),
+							Delay:                     int(getFloat64FromMap(groupMap, "delay")),
+							SystemDelays:              getStringFromMap(groupMap, "systemDelays"),
+							TalkgroupDelays:           getStringFromMap(groupMap, "talkgroupDelays"),
+							ConnectionLimit:           uint(getFloat64FromMap(groupMap, "connectionLimit")),
+							MaxUsers:                  uint(getFloat64FromMap(groupMap, "maxUsers")),
+							BillingEnabled:            getBoolFromMap(groupMap, "billingEnabled", false),
+							StripePriceId:             getStringFromMap(groupMap, "stripePriceId"),
+							PricingOptions:            getStringFromMap(groupMap, "pricingOptions"),
+							BillingMode:               getStringFromMap(groupMap, "billingMode"),
+							CollectSalesTax:           getBoolFromMap(groupMap, "collectSalesTax", false),
+							TaxMode:                   getStringFromMap(groupMap, "taxMode"),
+							StripeTaxRateId:           getStringFromMap(groupMap, "stripeTaxRateId"),
+							IsPublicRegistration:      getBoolFromMap(groupMap, "isPublicRegistration", false),
+							AllowAddExistingUsers:     getBoolFromMap(groupMap, "allowAddExistingUsers", false),
+							ReconnectionGracePeriod:   uint(getFloat64FromMap(groupMap, "reconnectionGracePeriod")),
+							ReconnectionMaxBufferSize: uint(getFloat64FromMap(groupMap, "reconnectionMaxBufferSize")),
+							DefaultLivefeedTags:       getStringFromMap(groupMap, "defaultLivefeedTags"),
 						}
 						if createdAt, ok := groupMap["createdAt"].(float64); ok {
 							group.CreatedAt = int64(createdAt)
@@ -2304,16 +2398,17 @@ func (admin *Admin) ConfigHandler(w http.ResponseWriter, r *http.Request) {
 							}
 
 							keywordsJson, _ := json.Marshal(keywords)
+							userGroupIdsJson, _ := json.Marshal(parseUserGroupIds(listMap["userGroupIds"]))
 
 							// Insert keyword list with preserved ID
 							if admin.Controller.Database.Config.DbType == DbTypePostgresql {
-								query := `INSERT INTO "keywordLists" ("keywordListId", "label", "description", "keywords", "order", "createdAt") VALUES ($1, $2, $3, $4, $5, $6)`
-								if _, err := admin.Controller.Database.Sql.Exec(query, keywordListId, label, description, string(keywordsJson), order, createdAt); err != nil {
+								query := `INSERT INTO "keywordLists" ("keywordListId", "label", "description", "keywords", "order", "createdAt", "userGroupIds") VALUES ($1, $2, $3, $4, $5, $6, $7)`
+								if _, err := admin.Controller.Database.Sql.Exec(query, keywordListId, label, description, string(keywordsJson), order, createdAt, string(userGroupIdsJson)); err != nil {
 									logError(fmt.Errorf("failed to import keyword list %s with ID %d: %v", label, keywordListId, err))
 								}
 							} else {
-								query := `INSERT INTO "keywordLists" ("keywordListId", "label", "description", "keywords", "order", "createdAt") VALUES (?, ?, ?, ?, ?, ?)`
-								if _, err := admin.Controller.Database.Sql.Exec(query, keywordListId, label, description, string(keywordsJson), order, createdAt); err != nil {
+								query := `INSERT INTO "keywordLists" ("keywordListId", "label", "description", "keywords", "order", "createdAt", "userGroupIds") VALUES (?, ?, ?, ?, ?, ?, ?)`
+								if _, err := admin.Controller.Database.Sql.Exec(query, keywordListId, label, description, string(keywordsJson), order, createdAt, string(userGroupIdsJson)); err != nil {
 									logError(fmt.Errorf("failed to import keyword list %s with ID %d: %v", label, keywordListId, err))
 								}
 							}
@@ -2473,8 +2568,13 @@ func (admin *Admin) ConfigHandler(w http.ResponseWriter, r *http.Request) {
 							pushTypeArg = &pushType
 						}
 
-						query := `INSERT INTO "deviceTokens" ("userId", "token", "fcmToken", "pushType", "platform", "sound", "createdAt", "lastUsed") VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
-						if _, err := admin.Controller.Database.Sql.Exec(query, actualUserId, token, fcmTokenArg, pushTypeArg, platform, sound, createdAt, lastUsed); err != nil {
+						subscription := getStringFromMap(tokenMap, "subscription")
+						quietStart := int(getFloat64FromMap(tokenMap, "quietStart"))
+						quietEnd := int(getFloat64FromMap(tokenMap, "quietEnd"))
+						timezone := getStringFromMap(tokenMap, "timezone")
+
+						query := `INSERT INTO "deviceTokens" ("userId", "token", "fcmToken", "pushType", "platform", "sound", "createdAt", "lastUsed", "subscription", "quietStart", "quietEnd", "timezone") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+						if _, err := admin.Controller.Database.Sql.Exec(query, actualUserId, token, fcmTokenArg, pushTypeArg, platform, sound, createdAt, lastUsed, subscription, quietStart, quietEnd, timezone); err != nil {
 							logError(fmt.Errorf("failed to import device token for userId=%d (mapped from %d): %v", actualUserId, importedUserId, err))
 						}
 					}
@@ -2655,6 +2755,14 @@ func (admin *Admin) TagsHandler(w http.ResponseWriter, r *http.Request) {
 
 		admin.mutex.Lock()
 		admin.Controller.Tags.FromMap(list)
+		if err := admin.Controller.Tags.ValidateColors(); err != nil {
+			// Restore the stored list so the rejected edit doesn't linger in memory.
+			admin.Controller.Tags.Read(admin.Controller.Database)
+			admin.mutex.Unlock()
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
 		err := admin.Controller.Tags.Write(admin.Controller.Database)
 		if err == nil {
 			err = admin.Controller.Tags.Read(admin.Controller.Database)
@@ -2678,6 +2786,101 @@ func (admin *Admin) TagsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// TagsReorderHandler persists a drag-and-drop reorder of the tags list.
+//
+//	PATCH /api/admin/tags/reorder    body: { "ids": [...] }  (every tag id, in the new order)
+func (admin *Admin) TagsReorderHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method != http.MethodPatch {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var req struct {
+		Ids []uint64 `json:"ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	admin.mutex.Lock()
+	err := admin.Controller.Tags.Reorder(admin.Controller.Database, req.Ids)
+	admin.mutex.Unlock()
+
+	if err != nil {
+		admin.Controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("admin.tags.reorder: %s", err.Error()))
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	go admin.Controller.EmitConfig()
+	admin.Controller.SyncConfigToFile()
+
+	json.NewEncoder(w).Encode(map[string]any{"tags": admin.Controller.Tags.List})
+}
+
+// TagsMergeHandler consolidates duplicate tags into a single target tag.
+//
+//	POST /api/admin/tags/merge    body: { "sourceIds": [...], "targetId": n }  -> { "repointed": n, "tags": [...] }
+func (admin *Admin) TagsMergeHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var req struct {
+		SourceIds []uint64 `json:"sourceIds"`
+		TargetId  uint64   `json:"targetId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	admin.mutex.Lock()
+	repointed, err := admin.Controller.Tags.Merge(admin.Controller.Database, req.SourceIds, req.TargetId)
+	if err == nil {
+		err = admin.Controller.Systems.Read(admin.Controller.Database)
+	}
+	admin.mutex.Unlock()
+
+	if err != nil {
+		admin.Controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("admin.tags.merge: %s", err.Error()))
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	admin.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("merged tags %v into tag %d (%d talkgroups repointed)", req.SourceIds, req.TargetId, repointed))
+
+	go admin.Controller.EmitConfig()
+	admin.Controller.SyncConfigToFile()
+
+	json.NewEncoder(w).Encode(map[string]any{
+		"repointed": repointed,
+		"tags":      admin.Controller.Tags.List,
+	})
+}
+
 // GroupsConfigHandler is the API-driven endpoint for the admin (talkgroup) Groups screen.
 // Named to avoid collision with the user-group handlers under /api/admin/groups.
 //
@@ -2886,6 +3089,12 @@ func (admin *Admin) SystemSaveHandler(w http.ResponseWriter, r *http.Request) {
 		if _, has := incoming["duplicateDetectionEnabled"]; !has {
 			incoming["duplicateDetectionEnabled"] = existing.DuplicateDetectionEnabled
 		}
+		if _, has := incoming["audioFormat"]; !has {
+			incoming["audioFormat"] = existing.AudioFormat
+		}
+		if _, has := incoming["audioBitrate"]; !has {
+			incoming["audioBitrate"] = float64(existing.AudioBitrate)
+		}
 
 		if tgs, ok := incoming["talkgroups"].([]any); ok {
 			for _, tr := range tgs {
@@ -3217,25 +3426,28 @@ func (admin *Admin) GetConfig() map[string]any {
 	userGroupList := make([]map[string]any, 0, len(userGroups))
 	for _, group := range userGroups {
 		userGroupList = append(userGroupList, map[string]any{
-			"id":                    group.Id,
-			"name":                  group.Name,
-			"description":           group.Description,
-			"systemAccess":          group.SystemAccess,
-			"delay":                 group.Delay,
-			"systemDelays":          group.SystemDelays,
-			"talkgroupDelays":       group.TalkgroupDelays,
-			"connectionLimit":       group.ConnectionLimit,
-			"maxUsers":              group.MaxUsers,
-			"billingEnabled":        group.BillingEnabled,
-			"stripePriceId":         group.StripePriceId,
-			"pricingOptions":        group.PricingOptions,
-			"billingMode":           group.BillingMode,
-			"collectSalesTax":       group.CollectSalesTax,
-			"taxMode":               group.TaxMode,
-			"stripeTaxRateId":       group.StripeTaxRateId,
-			"isPublicRegistration":  group.IsPublicRegistration,
-			"allowAddExistingUsers": group.AllowAddExistingUsers,
-			"createdAt":             group.CreatedAt,
+			"id":                        group.Id,
+			"name":                      group.Name,
+			"description":               group.Description,
+			"systemAccess":              group.SystemAccess,
+			"delay":                     group.Delay,
+			"systemDelays":              group.SystemDelays,
+			"talkgroupDelays":           group.TalkgroupDelays,
+			"connectionLimit":           group.ConnectionLimit,
+			"maxUsers":                  group.MaxUsers,
+			"billingEnabled":            group.BillingEnabled,
+			"stripePriceId":             group.StripePriceId,
+			"pricingOptions":            group.PricingOptions,
+			"billingMode":               group.BillingMode,
+			"collectSalesTax":           group.CollectSalesTax,
+			"taxMode":                   group.TaxMode,
+			"stripeTaxRateId":           group.StripeTaxRateId,
+			"isPublicRegistration":      group.IsPublicRegistration,
+			"allowAddExistingUsers":     group.AllowAddExistingUsers,
+			"reconnectionGracePeriod":   group.ReconnectionGracePeriod,
+			"reconnectionMaxBufferSize": group.ReconnectionMaxBufferSize,
+			"defaultLivefeedTags":       group.DefaultLivefeedTags,
+			"createdAt":                 group.CreatedAt,
 		})
 	}
 
@@ -3244,15 +3456,19 @@ func (admin *Admin) GetConfig() map[string]any {
 	admin.Controller.DeviceTokens.mutex.RLock()
 	for _, token := range admin.Controller.DeviceTokens.tokens {
 		deviceTokenList = append(deviceTokenList, map[string]any{
-			"id":        token.Id,
-			"userId":    token.UserId,
-			"token":     token.Token,
-			"fcmToken":  token.FCMToken,
-			"pushType":  token.PushType,
-			"platform":  token.Platform,
-			"sound":     token.Sound,
-			"createdAt": token.CreatedAt,
-			"lastUsed":  token.LastUsed,
+			"id":           token.Id,
+			"userId":       token.UserId,
+			"token":        token.Token,
+			"fcmToken":     token.FCMToken,
+			"pushType":     token.PushType,
+			"platform":     token.Platform,
+			"sound":        token.Sound,
+			"createdAt":    token.CreatedAt,
+			"lastUsed":     token.LastUsed,
+			"subscription": token.Subscription,
+			"quietStart":   token.QuietStart,
+			"quietEnd":     token.QuietEnd,
+			"timezone":     token.Timezone,
 		})
 	}
 	admin.Controller.DeviceTokens.mutex.RUnlock()
@@ -3262,12 +3478,13 @@ func (admin *Admin) GetConfig() map[string]any {
 	cachedLists := admin.Controller.KeywordListsCache.GetAllLists()
 	for _, list := range cachedLists {
 		keywordListList = append(keywordListList, map[string]any{
-			"id":          list.Id,
-			"label":       list.Label,
-			"description": list.Description,
-			"keywords":    list.Keywords,
-			"order":       list.Order,
-			"createdAt":   list.CreatedAt,
+			"id":           list.Id,
+			"label":        list.Label,
+			"description":  list.Description,
+			"keywords":     list.Keywords,
+			"order":        list.Order,
+			"createdAt":    list.CreatedAt,
+			"userGroupIds": list.UserGroupIds,
 		})
 	}
 
@@ -3416,6 +3633,81 @@ func (admin *Admin) LogsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// TranscriptsSearchHandler finds calls by the words in their transcript, with
+// a highlighted snippet of each (see TranscriptsSearchOptions).
+func (admin *Admin) TranscriptsSearchHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	m := map[string]any{}
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	results, err := admin.Controller.Calls.SearchTranscripts(NewTranscriptsSearchOptions().FromMap(m), admin.Controller.Database)
+	if err != nil {
+		admin.Controller.Logs.LogEvent(LogLevelError, err.Error())
+		w.WriteHeader(http.StatusExpectationFailed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// LogsExportHandler streams the logs matching a search (same body as
+// LogsHandler, without the row cap) as a download. ?format=csv selects CSV,
+// anything else NDJSON.
+func (admin *Admin) LogsExportHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	m := map[string]any{}
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	logOptions := NewLogSearchOptions().FromMap(m)
+
+	format := "ndjson"
+	contentType := "application/x-ndjson"
+	if r.URL.Query().Get("format") == "csv" {
+		format = "csv"
+		contentType = "text/csv; charset=utf-8"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="logs-%s.%s"`, time.Now().Format("20060102-150405"), format))
+
+	rc := http.NewResponseController(w)
+	n, err := admin.Controller.Logs.Export(logOptions, admin.Controller.Database, w, format, func() { rc.Flush() })
+	if err != nil {
+		admin.Controller.Logs.LogEvent(LogLevelError, err.Error())
+		if n == 0 {
+			w.Header().Del("Content-Disposition")
+			w.WriteHeader(http.StatusExpectationFailed)
+		}
+	}
+}
+
 func (admin *Admin) CallsHandler(w http.ResponseWriter, r *http.Request) {
 	t := admin.GetAuthorization(r)
 	if !admin.ValidateToken(t) {
@@ -3965,6 +4257,178 @@ func (admin *Admin) ValidateToken(sToken string) bool {
 	return token.Valid
 }
 
+// adminTokenExpired reports whether a signed admin token carries an exp
+// claim that has passed.  Tokens without one (password logins) never expire.
+func adminTokenExpired(sToken string, now time.Time) bool {
+	claims := jwt.RegisteredClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(sToken, &claims); err != nil {
+		return false
+	}
+	return claims.ExpiresAt != nil && !claims.ExpiresAt.After(now)
+}
+
+// addCMToken registers a Central Management admin token.  Expired tokens are
+// pruned first, then the oldest CM tokens beyond limit are revoked.  The
+// caller must hold admin.mutex.
+func (admin *Admin) addCMToken(sToken string, limit uint, now time.Time) {
+	removed := map[string]bool{}
+
+	cmTokens := []string{}
+	for _, t := range admin.cmTokens {
+		if adminTokenExpired(t, now) {
+			removed[t] = true
+		} else {
+			cmTokens = append(cmTokens, t)
+		}
+	}
+	cmTokens = append(cmTokens, sToken)
+	for limit > 0 && uint(len(cmTokens)) > limit {
+		removed[cmTokens[0]] = true
+		cmTokens = cmTokens[1:]
+	}
+	admin.cmTokens = cmTokens
+
+	tokens := []string{}
+	for _, t := range admin.Tokens {
+		if !removed[t] && !adminTokenExpired(t, now) {
+			tokens = append(tokens, t)
+		}
+	}
+	admin.Tokens = append(tokens, sToken)
+}
+
+// removeCMToken revokes a Central Management admin token.  The caller must
+// hold admin.mutex.
+func (admin *Admin) removeCMToken(sToken string) {
+	cmTokens := []string{}
+	for _, t := range admin.cmTokens {
+		if t != sToken {
+			cmTokens = append(cmTokens, t)
+		}
+	}
+	admin.cmTokens = cmTokens
+
+	tokens := []string{}
+	for _, t := range admin.Tokens {
+		if t != sToken {
+			tokens = append(tokens, t)
+		}
+	}
+	admin.Tokens = tokens
+}
+
+// cmAdminClaims are the claims of a Central Management admin token.  The
+// session start is carried over on every refresh, so however often a token
+// is renewed the session still ends cm_admin_session_max after it began.
+type cmAdminClaims struct {
+	jwt.RegisteredClaims
+	SessionStart int64 `json:"sst,omitempty"`
+}
+
+// cmAdminSessionEnd returns when a Central Management admin session that
+// began at sessionStart can no longer be refreshed.
+func cmAdminSessionEnd(config *Config, sessionStart time.Time) time.Time {
+	lifetime := time.Duration(config.CMAdminSessionMax) * time.Second
+	if lifetime <= 0 {
+		lifetime = time.Duration(defaultCMAdminSessionMax) * time.Second
+	}
+	return sessionStart.Add(lifetime)
+}
+
+// issueCMToken signs a Central Management admin token for the session that
+// began at sessionStart.  It expires after cm_admin_token_ttl, or when the
+// session ends if that comes first.
+func (admin *Admin) issueCMToken(sessionStart time.Time, now time.Time) (string, time.Time, error) {
+	ttl := time.Duration(admin.Controller.Config.CMAdminTokenTTL) * time.Second
+	if ttl <= 0 {
+		ttl = time.Duration(defaultCMAdminTokenTTL) * time.Second
+	}
+	expiresAt := now.Add(ttl)
+	if sessionEnd := cmAdminSessionEnd(admin.Controller.Config, sessionStart); sessionEnd.Before(expiresAt) {
+		expiresAt = sessionEnd
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, cmAdminClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        id.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		SessionStart: sessionStart.Unix(),
+	})
+	sToken, err := token.SignedString([]byte(admin.Controller.Options.secret))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return sToken, expiresAt, nil
+}
+
+// TokenRefreshHandler exchanges a still-valid Central Management admin token
+// for a new one, so an admin tab opened from CM is not logged out mid-task.
+// The old token is revoked.  Refreshing stops once the session reaches
+// cm_admin_session_max.  Tokens that never expire (password and SSO logins)
+// are returned unchanged.
+func (admin *Admin) TokenRefreshHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	claims := cmAdminClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(t, &claims); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if claims.ExpiresAt == nil {
+		json.NewEncoder(w).Encode(map[string]any{"token": t, "expiresAt": 0})
+		return
+	}
+
+	now := time.Now()
+	sessionStart := time.Unix(claims.SessionStart, 0)
+	if claims.SessionStart == 0 && claims.IssuedAt != nil {
+		// Issued before refresh existed; the session started with this token.
+		sessionStart = claims.IssuedAt.Time
+	}
+	sessionEnd := cmAdminSessionEnd(admin.Controller.Config, sessionStart)
+	if !now.Before(sessionEnd) {
+		admin.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("admin: Central Management session from %s reached its maximum lifetime", GetClientIP(r)))
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "session expired"})
+		return
+	}
+
+	sToken, expiresAt, err := admin.issueCMToken(sessionStart, now)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	admin.mutex.Lock()
+	admin.removeCMToken(t)
+	admin.addCMToken(sToken, admin.Controller.Config.CMAdminTokenLimit, now)
+	admin.mutex.Unlock()
+
+	json.NewEncoder(w).Encode(map[string]any{
+		"token":            sToken,
+		"expiresAt":        expiresAt.Unix(),
+		"sessionExpiresAt": sessionEnd.Unix(),
+	})
+}
+
 func (admin *Admin) RadioReferenceTestHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -5263,7 +5727,7 @@ func (admin *Admin) radioReferenceImportToSystemCore(body radioReferenceImportBo
 		// Find or create tag
 		tag, ok := ctrl.Tags.GetTagByLabel(tagLabel)
 		if !ok {
-			tag = &Tag{Label: tagLabel}
+			tag = &Tag{Label: tagLabel, Color: defaultTagColor(tagLabel)}
 			ctrl.Tags.List = append(ctrl.Tags.List, tag)
 			if err := ctrl.Tags.Write(ctrl.Database); err != nil {
 				return created, updated, fmt.Errorf("failed to write tag: %w", err)
@@ -5278,12 +5742,15 @@ func (admin *Admin) radioReferenceImportToSystemCore(body radioReferenceImportBo
 		}
 
 		tgRef := uint(tg.Id)
+		// RadioReference enc: 0 clear, 1 partially encrypted, 2 fully encrypted.
+		encrypted := tg.Enc == 1 || tg.Enc == 2
 
 		if existing, ok := system.Talkgroups.GetTalkgroupByRef(tgRef); ok {
 			existing.Label = tg.AlphaTag
 			existing.Name = tg.Description
 			existing.GroupIds = []uint64{group.Id}
 			existing.TagId = tag.Id
+			existing.Encrypted = encrypted
 			updated++
 		} else {
 			maxOrder := uint(0)
@@ -5299,6 +5766,7 @@ func (admin *Admin) radioReferenceImportToSystemCore(body radioReferenceImportBo
 				GroupIds:     []uint64{group.Id},
 				TagId:        tag.Id,
 				Order:        maxOrder + 1,
+				Encrypted:    encrypted,
 			})
 			created++
 		}
@@ -5400,7 +5868,8 @@ func (admin *Admin) RadioReferenceImportToSystemHandler(w http.ResponseWriter, r
 	})
 }
 
-// Configuration reload handler
+// ConfigReloadHandler re-reads the ini file and the options and applies the
+// settings that can change while running; see Controller.ReloadConfig.
 func (admin *Admin) ConfigReloadHandler(w http.ResponseWriter, r *http.Request) {
 	t := admin.GetAuthorization(r)
 	if !admin.ValidateToken(t) {
@@ -5412,28 +5881,21 @@ func (admin *Admin) ConfigReloadHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Check what's in the database before reload
-	query := `SELECT "key", "value" FROM "options" WHERE "key" IN ('radioReferenceUsername', 'radioReferencePassword', 'radioReferenceEnabled')`
-	rows, err := admin.Controller.Database.Sql.Query(query)
-	if err == nil {
-		defer rows.Close()
-		for rows.Next() {
-			var key, value sql.NullString
-			if err := rows.Scan(&key, &value); err == nil {
-				if key.Valid && value.Valid {
-				}
-			}
-		}
-	}
+	w.Header().Set("Content-Type", "application/json")
 
-	// Reload options from database (this will include the updated Radio Reference settings)
-	if err := admin.Controller.Options.Read(admin.Controller.Database); err != nil {
+	reload, err := admin.Controller.ReloadConfig("admin")
+	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 		return
 	}
 
-	json.NewEncoder(w).Encode(map[string]any{"success": true, "message": "Configuration reloaded successfully"})
+	json.NewEncoder(w).Encode(map[string]any{
+		"success":         true,
+		"message":         "Configuration reloaded successfully",
+		"changed":         reload.Changed,
+		"restartRequired": reload.RestartRequired,
+	})
 }
 
 // EmailLogoUploadHandler handles logo file upload for emails
@@ -6589,10 +7051,14 @@ func (admin *Admin) UserUpdateHandler(w http.ResponseWriter, r *http.Request) {
 			}
 			user.Pin = newPin
 			user.PinExpiresAt = 0
-		} else {
-			if !admin.Controller.Users.IsPinAvailable(pinValue, user.Id) {
-				w.WriteHeader(http.StatusConflict)
-				json.NewEncoder(w).Encode(map[string]string{"error": "PIN already in use"})
+		} else if pinValue != user.Pin {
+			if err := admin.Controller.Users.ValidatePin(pinValue, user.Id, admin.Controller.Config.PinMinLength); err != nil {
+				status := http.StatusBadRequest
+				if errors.Is(err, ErrPinInUse) {
+					status = http.StatusConflict
+				}
+				w.WriteHeader(status)
+				json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 				return
 			}
 			user.Pin = pinValue
@@ -7003,6 +7469,7 @@ func (admin *Admin) DeviceTokenDeleteHandler(w http.ResponseWriter, r *http.Requ
 
 // UpdateCheckHandler handles GET /api/admin/update/check
 // Returns the current and latest version along with whether an update is available.
+// Results are cached for 30 minutes; pass ?force=true to query GitHub again.
 func (admin *Admin) UpdateCheckHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		w.WriteHeader(http.StatusMethodNotAllowed)
@@ -7021,13 +7488,23 @@ func (admin *Admin) UpdateCheckHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	info, err := admin.Controller.Updater.CheckForUpdate()
+	force := r.URL.Query().Get("force") == "true"
+
+	info, err := admin.Controller.Updater.CachedCheckForUpdate(force)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 		return
 	}
 
+	// Free space changes independently of the cached release info, so the
+	// preflight runs on every check and is attached to a copy.
+	if info.UpdateAvailable {
+		withSpace := *info
+		withSpace.DiskSpace = admin.Controller.Updater.PreflightDiskSpace(info)
+		info = &withSpace
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(info)
 }
@@ -7069,6 +7546,16 @@ func (admin *Admin) UpdateApplyHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if space := admin.Controller.Updater.PreflightDiskSpace(info); !space.Sufficient {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInsufficientStorage)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":      space.Error,
+			"disk_space": space,
+		})
+		return
+	}
+
 	// Send the response first — the server will restart shortly after.
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -7084,12 +7571,48 @@ func (admin *Admin) UpdateApplyHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Apply the update in a goroutine so the handler returns cleanly.
 	go func() {
-		if err := admin.Controller.Updater.ApplyUpdate(info.DownloadURL); err != nil {
+		if err := admin.Controller.Updater.ApplyUpdate(info); err != nil {
 			log.Printf("Auto-update apply failed: %v", err)
 		}
 	}()
 }
 
+// UpdateRollbackHandler handles POST /api/admin/update/rollback
+// Restores the binary saved by the last update then triggers a graceful restart.
+func (admin *Admin) UpdateRollbackHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if admin.Controller.Updater == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"error": "updater not initialised"})
+		return
+	}
+
+	version, err := admin.Controller.Updater.RollbackUpdate()
+	if err != nil {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": fmt.Sprintf("Rolling back to %s — server will restart momentarily", version),
+		"from":    Version,
+		"to":      version,
+	})
+}
+
 // TranscriptParserHandler handles GET and PUT for the transcript parser config.
 //
 // GET  /api/admin/transcript-parser — returns the current TranscriptConfig as JSON.
@@ -7157,6 +7680,45 @@ func (admin *Admin) RelaySuspensionStatusHandler(w http.ResponseWriter, r *http.
 	})
 }
 
+// ReconnectionStatsHandler returns the reconnection manager's aggregate stats
+// along with per-user detail for every state currently being held.
+func (admin *Admin) ReconnectionStatsHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	rm := admin.Controller.ReconnectionMgr
+	if rm == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"stats": rm.GetStats(),
+		"users": rm.GetUserStats(),
+	})
+}
+
+// FFMpegStatsHandler returns the ffmpeg conversion limiter's current load.
+func (admin *Admin) FFMpegStatsHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(admin.Controller.FFMpeg.Stats())
+}
+
 // RelayUnlockPublicClientHandler allows the server operator to restore the public web listener
 // while relay full suspension remains (push stays disabled until relay clears suspension).
 func (admin *Admin) RelayUnlockPublicClientHandler(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,225 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type userProfileTalkgroup struct {
+	Id           uint64 `json:"id"`
+	TalkgroupRef uint   `json:"talkgroupRef"`
+	Label        string `json:"label"`
+	Name         string `json:"name"`
+}
+
+type userProfileSystem struct {
+	Id             uint64                 `json:"id"`
+	SystemRef      uint                   `json:"systemRef"`
+	Label          string                 `json:"label"`
+	AllTalkgroups  bool                   `json:"allTalkgroups"`
+	Talkgroups     []userProfileTalkgroup `json:"talkgroups"`
+	TalkgroupCount int                    `json:"talkgroupCount"`
+	TotalCount     int                    `json:"totalTalkgroupCount"`
+}
+
+type userProfileDevice struct {
+	Id         uint64 `json:"id"`
+	Platform   string `json:"platform"`
+	PushType   string `json:"pushType"`
+	Token      string `json:"token"`
+	CreatedAt  int64  `json:"createdAt"`
+	LastUsed   int64  `json:"lastUsed"`
+	QuietStart int    `json:"quietStart"`
+	QuietEnd   int    `json:"quietEnd"`
+	Timezone   string `json:"timezone"`
+}
+
+// UserProfileHandler returns everything that decides what a user can hear in
+// one response, so support does not have to piece it together from the raw
+// Systems/Talkgroups strings, the user group and the device tokens.
+//
+//	GET /api/admin/users/profile?id=42
+//	GET /api/admin/users/profile?email=user@example.com
+func (admin *Admin) UserProfileHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var user *User
+	if idValue := r.URL.Query().Get("id"); idValue != "" {
+		id, err := strconv.ParseUint(idValue, 10, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Invalid user ID format"})
+			return
+		}
+		user = admin.Controller.Users.GetUserById(id)
+	} else if email := strings.TrimSpace(r.URL.Query().Get("email")); email != "" {
+		user = admin.Controller.Users.GetUserByEmail(email)
+	} else {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "id or email is required"})
+		return
+	}
+
+	if user == nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "User not found"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(admin.Controller.userAccessProfile(user))
+}
+
+// userAccessProfile resolves a user's access against the current systems, so
+// "*", per-system talkgroup lists and group restrictions all come out as the
+// actual systems and talkgroups the user receives.
+func (controller *Controller) userAccessProfile(user *User) map[string]any {
+	now := uint64(time.Now().Unix())
+
+	systems := []userProfileSystem{}
+
+	controller.Systems.mutex.RLock()
+	for _, system := range controller.Systems.List {
+		if !controller.userHasSystemScopeAccess(user, system.SystemRef) {
+			continue
+		}
+
+		entry := userProfileSystem{
+			Id:         system.Id,
+			SystemRef:  system.SystemRef,
+			Label:      system.Label,
+			Talkgroups: []userProfileTalkgroup{},
+		}
+
+		if system.Talkgroups != nil {
+			system.Talkgroups.mutex.Lock()
+			for _, talkgroup := range system.Talkgroups.List {
+				entry.TotalCount++
+				if !controller.userHasTalkgroupScopeAccess(user, system.SystemRef, talkgroup.TalkgroupRef) {
+					continue
+				}
+				entry.Talkgroups = append(entry.Talkgroups, userProfileTalkgroup{
+					Id:           talkgroup.Id,
+					TalkgroupRef: talkgroup.TalkgroupRef,
+					Label:        talkgroup.Label,
+					Name:         talkgroup.Name,
+				})
+			}
+			system.Talkgroups.mutex.Unlock()
+		}
+
+		entry.TalkgroupCount = len(entry.Talkgroups)
+		entry.AllTalkgroups = entry.TalkgroupCount == entry.TotalCount
+		sort.Slice(entry.Talkgroups, func(i, j int) bool {
+			return entry.Talkgroups[i].TalkgroupRef < entry.Talkgroups[j].TalkgroupRef
+		})
+
+		systems = append(systems, entry)
+	}
+	controller.Systems.mutex.RUnlock()
+
+	sort.Slice(systems, func(i, j int) bool { return systems[i].SystemRef < systems[j].SystemRef })
+
+	var group map[string]any
+	if user.UserGroupId > 0 {
+		if g := controller.UserGroups.Get(user.UserGroupId); g != nil {
+			group = map[string]any{
+				"id":              g.Id,
+				"name":            g.Name,
+				"isGroupAdmin":    user.IsGroupAdmin,
+				"connectionLimit": g.ConnectionLimit,
+				"systemAccess":    g.SystemAccess,
+			}
+		} else {
+			group = map[string]any{"id": user.UserGroupId, "missing": true}
+		}
+	}
+
+	devices := []userProfileDevice{}
+	for _, token := range controller.DeviceTokens.GetByUser(user.Id) {
+		value := token.FCMToken
+		if value == "" {
+			value = token.Token
+		}
+		devices = append(devices, userProfileDevice{
+			Id:         token.Id,
+			Platform:   token.Platform,
+			PushType:   token.PushType,
+			Token:      redactDeviceToken(value),
+			CreatedAt:  token.CreatedAt,
+			LastUsed:   token.LastUsed,
+			QuietStart: token.QuietStart,
+			QuietEnd:   token.QuietEnd,
+			Timezone:   token.Timezone,
+		})
+	}
+
+	return map[string]any{
+		"user": map[string]any{
+			"id":                 user.Id,
+			"email":              user.Email,
+			"firstName":          user.FirstName,
+			"lastName":           user.LastName,
+			"verified":           user.Verified,
+			"systemAdmin":        user.SystemAdmin,
+			"subscriptionStatus": user.SubscriptionStatus,
+			"accountExpiresAt":   user.AccountExpiresAt,
+			"accountExpired":     user.AccountExpiresAt > 0 && user.AccountExpiresAt <= now,
+			"lastLogin":          user.LastLogin,
+		},
+		"access": map[string]any{
+			"systems":       systems,
+			"rawSystems":    user.Systems,
+			"rawTalkgroups": user.Talkgroups,
+		},
+		"group": group,
+		"connections": map[string]any{
+			"limit":  controller.userEffectiveConnectionLimit(user),
+			"active": controller.Clients.UserConnectionCount(user),
+		},
+		"pin": map[string]any{
+			"set":       user.Pin != "",
+			"active":    user.Pin != "" && (user.PinExpiresAt == 0 || user.PinExpiresAt > now),
+			"expiresAt": user.PinExpiresAt,
+		},
+		"devices": devices,
+	}
+}
+
+// redactDeviceToken keeps only the last few characters of a push token, which
+// is enough to match it against device logs without exposing it.
+func redactDeviceToken(token string) string {
+	if len(token) <= 8 {
+		return strings.Repeat("*", len(token))
+	}
+	return "…" + token[len(token)-6:]
+}
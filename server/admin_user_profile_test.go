@@ -0,0 +1,58 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import "testing"
+
+func TestUserAccessProfileResolvesScopes(t *testing.T) {
+	talkgroups := NewTalkgroups()
+	talkgroups.List = []*Talkgroup{
+		{Id: 1, TalkgroupRef: 100, Label: "Fire"},
+		{Id: 2, TalkgroupRef: 200, Label: "EMS"},
+	}
+	other := NewTalkgroups()
+	other.List = []*Talkgroup{{Id: 3, TalkgroupRef: 300, Label: "PD"}}
+
+	controller := &Controller{
+		Systems:      NewSystems(),
+		UserGroups:   NewUserGroups(),
+		DeviceTokens: NewDeviceTokens(),
+		Clients:      NewClients(),
+	}
+	controller.Systems.List = []*System{
+		{Id: 1, SystemRef: 10, Label: "County", Talkgroups: talkgroups},
+		{Id: 2, SystemRef: 20, Label: "City", Talkgroups: other},
+	}
+
+	user := &User{Id: 7, Systems: `[{"id":10,"talkgroups":[200]}]`, Pin: "1234"}
+	user.loadSystemScopes()
+
+	profile := controller.userAccessProfile(user)
+	systems := profile["access"].(map[string]any)["systems"].([]userProfileSystem)
+
+	if len(systems) != 1 || systems[0].Label != "County" {
+		t.Fatalf("systems = %+v, want only County", systems)
+	}
+	if systems[0].AllTalkgroups || len(systems[0].Talkgroups) != 1 || systems[0].Talkgroups[0].Label != "EMS" {
+		t.Fatalf("talkgroups = %+v, want only EMS", systems[0])
+	}
+	if pin := profile["pin"].(map[string]any); pin["active"] != true {
+		t.Fatalf("pin = %+v, want active", pin)
+	}
+
+	user.Systems = "*"
+	user.loadSystemScopes()
+	systems = controller.userAccessProfile(user)["access"].(map[string]any)["systems"].([]userProfileSystem)
+	if len(systems) != 2 || !systems[0].AllTalkgroups || systems[0].TalkgroupCount != 2 {
+		t.Fatalf("wildcard systems = %+v", systems)
+	}
+}
+
+func TestRedactDeviceToken(t *testing.T) {
+	if got := redactDeviceToken("abcdefghijklmnop"); got != "…klmnop" {
+		t.Fatalf("redactDeviceToken = %q", got)
+	}
+	if got := redactDeviceToken("short"); got != "*****" {
+		t.Fatalf("redactDeviceToken short = %q", got)
+	}
+}
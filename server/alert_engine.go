@@ -37,6 +37,10 @@ type AlertEngine struct {
 	lastToneAlertFiredAt map[uint64]time.Time
 	// toneAlertDispatched prevents duplicate TriggerToneAlerts push batches for the same callId.
 	toneAlertDispatched map[uint64]struct{}
+	// lastPreAlertToneSetAt / lastToneAlertToneSetAt enforce the per-tone-set cooldown, keyed by
+	// "talkgroupId:toneSetId" so a repeated page is dropped without muting other departments.
+	lastPreAlertToneSetAt  map[string]time.Time
+	lastToneAlertToneSetAt map[string]time.Time
 
 	// lastCleanupUnix is the Unix timestamp (seconds) of the most recent
 	// cleanupOldAlerts run.  Compared atomically so that concurrent createAlert
@@ -55,6 +59,9 @@ func NewAlertEngine(controller *Controller) *AlertEngine {
 		lastPreAlertFiredAt:  make(map[uint64]time.Time),
 		lastToneAlertFiredAt: make(map[uint64]time.Time),
 		toneAlertDispatched:  make(map[uint64]struct{}),
+
+		lastPreAlertToneSetAt:  make(map[string]time.Time),
+		lastToneAlertToneSetAt: make(map[string]time.Time),
 	}
 }
 
@@ -132,6 +139,46 @@ func (engine *AlertEngine) recordToneAlertCooldown(talkgroupId uint64) {
 	engine.cooldownMu.Unlock()
 }
 
+// toneSetCooldown returns how long repeat matches of a tone set are suppressed.
+func (engine *AlertEngine) toneSetCooldown(toneSet *ToneSet) time.Duration {
+	if toneSet.CooldownSeconds != nil {
+		return time.Duration(*toneSet.CooldownSeconds) * time.Second
+	}
+	return time.Duration(engine.controller.Options.ToneSetCooldownSeconds) * time.Second
+}
+
+// claimToneSetCooldown records an alert for the tone set on the talkgroup and returns true,
+// or returns false with the time left when the same tone set alerted within its cooldown.
+func (engine *AlertEngine) claimToneSetCooldown(fired map[string]time.Time, talkgroupId uint64, toneSet *ToneSet) (bool, time.Duration) {
+	cooldown := engine.toneSetCooldown(toneSet)
+	if cooldown <= 0 || talkgroupId == 0 {
+		return true, 0
+	}
+	key := fmt.Sprintf("%d:%s", talkgroupId, toneSet.Id)
+	now := time.Now()
+
+	engine.cooldownMu.Lock()
+	defer engine.cooldownMu.Unlock()
+	if last, ok := fired[key]; ok && now.Sub(last) < cooldown {
+		return false, cooldown - now.Sub(last)
+	}
+	fired[key] = now
+	return true, 0
+}
+
+// logToneSetSuppressed reports a tone set match dropped by its cooldown.
+func (engine *AlertEngine) logToneSetSuppressed(alertType string, call *Call, talkgroupId uint64, toneSet *ToneSet, remaining time.Duration) {
+	details := fmt.Sprintf("tone set '%s' repeated within cooldown (%.0fs remaining) — suppressed", toneSet.Label, remaining.Seconds())
+	engine.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("%s: call %d talkgroup %d %s", alertType, call.Id, talkgroupId, details))
+	if engine.controller.DebugLogger != nil {
+		systemId := uint64(0)
+		if call.System != nil {
+			systemId = call.System.Id
+		}
+		engine.controller.DebugLogger.LogAlert(alertType+"-cooldown", call.Id, systemId, talkgroupId, details)
+	}
+}
+
 func (engine *AlertEngine) claimToneAlertDispatch(callId uint64) bool {
 	if callId == 0 {
 		return true
@@ -269,6 +316,11 @@ func (engine *AlertEngine) TriggerPreAlerts(call *Call) {
 		// DEBUG: Log detected tone set details
 		engine.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("🔔 [TONE SET DEBUG] Pre-alert processing: Detected tone set ID='%s', Label='%s' on call %d", matchedToneSet.Id, matchedToneSet.Label, call.Id))
 
+		if ok, remaining := engine.claimToneSetCooldown(engine.lastPreAlertToneSetAt, talkgroupId, matchedToneSet); !ok {
+			engine.logToneSetSuppressed("pre-alert", call, talkgroupId, matchedToneSet, remaining)
+			continue
+		}
+
 		// NOTE: Pre-alerts are NOT saved to database - they're instant notifications only
 		// No need to check for existing alerts or create database records
 		engine.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("preparing pre-alert notifications for call %d, tone set '%s' (not saving to database)", call.Id, matchedToneSet.Label))
@@ -276,6 +328,11 @@ func (engine *AlertEngine) TriggerPreAlerts(call *Call) {
 		// Collect users who should get notifications for this tone set
 		var eligibleUsers []uint64
 		for _, user := range users {
+			if !engine.controller.userInAlertGroups(user.userId, matchedToneSet.UserGroupIds) {
+				engine.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("pre-alert: user %d SKIPPED for '%s' (not in routed user groups)", user.userId, matchedToneSet.Label))
+				continue
+			}
+
 			// Check if user wants this specific tone set
 			// If user has no selected tone sets (empty map), they want all tone sets
 			if len(user.selectedToneSetIds) == 0 {
@@ -400,6 +457,12 @@ func (engine *AlertEngine) TriggerToneAlerts(call *Call) {
 		// DEBUG: Log detected tone set details
 		engine.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("🔔 [TONE SET DEBUG] Tone alert processing: Detected tone set ID='%s', Label='%s' on call %d", matchedToneSet.Id, matchedToneSet.Label, call.Id))
 
+		// A repeated page of the same tone set is one operational event: no second alert or push.
+		if ok, remaining := engine.claimToneSetCooldown(engine.lastToneAlertToneSetAt, cooldownTgId, matchedToneSet); !ok {
+			engine.logToneSetSuppressed("tone", call, cooldownTgId, matchedToneSet, remaining)
+			continue
+		}
+
 		// Check if alert already exists for this call + tone set combination using cache
 		// This prevents duplicate alerts if the function is called multiple times
 		_, alertExists := engine.controller.RecentAlertsCache.AlertExists(
@@ -428,6 +491,12 @@ func (engine *AlertEngine) TriggerToneAlerts(call *Call) {
 		// Collect users who should get notifications for this tone set
 		var eligibleUsers []uint64
 		for _, user := range users {
+			// Tone sets routed to specific user groups only page their members
+			if !engine.controller.userInAlertGroups(user.userId, matchedToneSet.UserGroupIds) {
+				engine.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("tone alert: user %d SKIPPED for '%s' (not in routed user groups)", user.userId, matchedToneSet.Label))
+				continue
+			}
+
 			// Check if user wants alerts for this tone set
 			// If user has no specific tone set selection, they want all tone sets
 			if len(user.selectedToneSetIds) == 0 {
@@ -837,6 +906,23 @@ type AlertRecord struct {
 	CreatedAt         int64  `json:"createdAt"`
 }
 
+// alertCall resolves an alert's system and talkgroup so integrations can
+// describe and filter it like a call. Returns nil if either is gone.
+func (controller *Controller) alertCall(alert *AlertRecord) *Call {
+	if controller.Systems == nil {
+		return nil
+	}
+	system, ok := controller.Systems.GetSystemById(alert.SystemId)
+	if !ok || system.Talkgroups == nil {
+		return nil
+	}
+	talkgroup, ok := system.Talkgroups.GetTalkgroupById(alert.TalkgroupId)
+	if !ok {
+		return nil
+	}
+	return &Call{Id: alert.CallId, System: system, Talkgroup: talkgroup, Timestamp: time.UnixMilli(alert.CreatedAt)}
+}
+
 // createAlert creates an alert in the database
 func (engine *AlertEngine) createAlert(alert *AlertRecord) {
 	var query string
@@ -862,6 +948,9 @@ func (engine *AlertEngine) createAlert(alert *AlertRecord) {
 
 	engine.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("alert created: id=%d, call=%d, type=%s", alert.AlertId, alert.CallId, alert.AlertType))
 
+	go engine.controller.Webhooks.SendAlert(alert)
+	engine.controller.Mqtt.PublishAlert(alert)
+
 	// Add alert to cache for duplicate prevention
 	engine.controller.RecentAlertsCache.AddAlert(
 		alert.AlertId, alert.CallId, alert.SystemId, alert.TalkgroupId, 
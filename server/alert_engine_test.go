@@ -0,0 +1,84 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClaimToneSetCooldown(t *testing.T) {
+	options := NewOptions()
+	options.ToneSetCooldownSeconds = 60
+	engine := &AlertEngine{controller: &Controller{Options: options}}
+	fired := map[string]time.Time{}
+
+	fire := &ToneSet{Id: "fire", Label: "Fire"}
+	ems := &ToneSet{Id: "ems", Label: "EMS"}
+
+	if ok, _ := engine.claimToneSetCooldown(fired, 10, fire); !ok {
+		t.Fatal("first page of a tone set should alert")
+	}
+	ok, remaining := engine.claimToneSetCooldown(fired, 10, fire)
+	if ok {
+		t.Fatal("repeat page within cooldown should be suppressed")
+	}
+	if remaining <= 0 || remaining > 60*time.Second {
+		t.Fatalf("remaining = %v, want within the 60s cooldown", remaining)
+	}
+
+	// Cooldowns are independent per tone set and per talkgroup.
+	if ok, _ := engine.claimToneSetCooldown(fired, 10, ems); !ok {
+		t.Fatal("a different tone set on the same talkgroup should alert")
+	}
+	if ok, _ := engine.claimToneSetCooldown(fired, 11, fire); !ok {
+		t.Fatal("the same tone set on another talkgroup should alert")
+	}
+
+	// An expired cooldown lets the tone set alert again.
+	fired["10:fire"] = time.Now().Add(-61 * time.Second)
+	if ok, _ := engine.claimToneSetCooldown(fired, 10, fire); !ok {
+		t.Fatal("page after the cooldown expired should alert")
+	}
+
+	// A per-tone-set override of 0 disables the global cooldown.
+	off := uint(0)
+	noCooldown := &ToneSet{Id: "tac", Label: "Tac", CooldownSeconds: &off}
+	for i := 0; i < 2; i++ {
+		if ok, _ := engine.claimToneSetCooldown(fired, 10, noCooldown); !ok {
+			t.Fatalf("page %d with cooldown disabled should alert", i+1)
+		}
+	}
+}
+
+func TestUserInAlertGroups(t *testing.T) {
+	users := NewUsers()
+	users.users[1] = &User{Id: 1, UserGroupId: 3}
+	users.users[2] = &User{Id: 2, UserGroupId: 4}
+	users.users[3] = &User{Id: 3}
+	controller := &Controller{Users: users}
+
+	cases := []struct {
+		userId   uint64
+		groupIds []uint64
+		want     bool
+	}{
+		{1, nil, true},
+		{3, nil, true},
+		{1, []uint64{3}, true},
+		{2, []uint64{3}, false},
+		{2, []uint64{3, 4}, true},
+		{3, []uint64{3}, false},
+		{99, []uint64{3}, false},
+	}
+	for _, tc := range cases {
+		if got := controller.userInAlertGroups(tc.userId, tc.groupIds); got != tc.want {
+			t.Fatalf("userInAlertGroups(%d, %v) = %v, want %v", tc.userId, tc.groupIds, got, tc.want)
+		}
+	}
+
+	ids := parseUserGroupIds([]any{float64(3), "4", "x", float64(0)})
+	if len(ids) != 2 || ids[0] != 3 || ids[1] != 4 {
+		t.Fatalf("parseUserGroupIds = %v, want [3 4]", ids)
+	}
+}
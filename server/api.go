@@ -239,9 +239,27 @@ func getSchemeAndHost(r *http.Request) (scheme string, host string) {
 	return scheme, host
 }
 
+// beginUpload counts an upload as in flight, or answers 503 when the server is
+// draining for a restart so the recorder retries against the new process.
+func (api *Api) beginUpload(w http.ResponseWriter) bool {
+	api.Controller.ingestActive.Add(1)
+	if api.Controller.draining.Load() {
+		api.Controller.ingestActive.Add(-1)
+		w.Header().Set("Retry-After", "30")
+		api.exitWithError(w, http.StatusServiceUnavailable, "Server is restarting, please retry")
+		return false
+	}
+	return true
+}
+
 func (api *Api) CallUploadHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodPost:
+		if !api.beginUpload(w) {
+			return
+		}
+		defer api.Controller.ingestActive.Add(-1)
+
 		var (
 			call = NewCall()
 			key  string
@@ -395,6 +413,7 @@ func (api *Api) HandleCall(key string, call *Call, w http.ResponseWriter) {
 			// Use a non-blocking send to avoid deadlocks
 			select {
 			case api.Controller.Ingest <- call:
+				log.Printf("api: call for system %v talkgroup %v ingested under API key %s", systemRef, talkgroupRef, apikey.Label())
 				if err := api.Controller.Apikeys.RecordLastCall(api.Controller.Database, apikey.Id, time.Now().UnixMilli()); err != nil {
 					api.Controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to record API key last call time for key %d: %v", apikey.Id, err))
 				}
@@ -405,12 +424,14 @@ func (api *Api) HandleCall(key string, call *Call, w http.ResponseWriter) {
 			}
 
 		} else {
+			log.Printf("api: API key %s is not allowed to upload to system %v talkgroup %v", apikey.Label(), systemRef, talkgroupRef)
 			w.WriteHeader(http.StatusUnauthorized)
 			w.Write(msg)
 			return
 		}
 
 	} else {
+		log.Printf("api: unknown or disabled API key for system %v talkgroup %v", systemRef, talkgroupRef)
 		w.WriteHeader(http.StatusUnauthorized)
 		w.Write(msg)
 		return
@@ -422,6 +443,11 @@ func (api *Api) HandleCall(key string, call *Call, w http.ResponseWriter) {
 func (api *Api) TrunkRecorderCallUploadHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodPost:
+		if !api.beginUpload(w) {
+			return
+		}
+		defer api.Controller.ingestActive.Add(-1)
+
 		var (
 			call = NewCall()
 			key  string
@@ -2932,7 +2958,7 @@ func (api *Api) AlertsHandler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Query all alerts (no userId filter) with system, talkgroup labels, call transcripts, and tone sequence
-		query := fmt.Sprintf(`SELECT a."alertId", a."callId", a."systemId", a."talkgroupId", a."alertType", a."toneDetected", a."toneSetId", a."keywordsMatched", a."transcriptSnippet", a."createdAt", s."label" as "systemLabel", s."systemRef" as "systemRef", t."label" as "talkgroupLabel", t."name" as "talkgroupName", c."transcript" as "callTranscript", c."transcriptionStatus" as "callTranscriptionStatus", c."toneSequence" as "callToneSequence", c."timestamp" as "callTimestamp", c."alertSummary" as "callAlertSummary", c."incidentAddress", c."incidentLat", c."incidentLon", c."incidentNature", c."incidentGeocodeStatus" FROM "alerts" a LEFT JOIN "systems" s ON s."systemId" = a."systemId" LEFT JOIN "talkgroups" t ON t."talkgroupId" = a."talkgroupId" LEFT JOIN "calls" c ON c."callId" = a."callId" %s ORDER BY a."createdAt" DESC LIMIT %d`, whereClause, maxAlerts)
+		query := fmt.Sprintf(`SELECT a."alertId", a."callId", a."systemId", a."talkgroupId", a."alertType", a."toneDetected", a."toneSetId", a."keywordsMatched", a."transcriptSnippet", a."createdAt", s."label" as "systemLabel", s."systemRef" as "systemRef", t."label" as "talkgroupLabel", t."name" as "talkgroupName", c."transcript" as "callTranscript", c."transcriptionStatus" as "callTranscriptionStatus", c."toneSequence" as "callToneSequence", c."detectedTones" as "callDetectedTones", c."timestamp" as "callTimestamp", c."alertSummary" as "callAlertSummary", c."incidentAddress", c."incidentLat", c."incidentLon", c."incidentNature", c."incidentGeocodeStatus" FROM "alerts" a LEFT JOIN "systems" s ON s."systemId" = a."systemId" LEFT JOIN "talkgroups" t ON t."talkgroupId" = a."talkgroupId" LEFT JOIN "calls" c ON c."callId" = a."callId" %s ORDER BY a."createdAt" DESC LIMIT %d`, whereClause, maxAlerts)
 		rows, err := api.Controller.Database.Sql.Query(query)
 		if err != nil {
 			api.exitWithError(w, http.StatusInternalServerError, fmt.Sprintf("failed to query alerts: %v", err))
@@ -2961,6 +2987,7 @@ func (api *Api) AlertsHandler(w http.ResponseWriter, r *http.Request) {
 				callTranscript          sql.NullString
 				callTranscriptionStatus sql.NullString
 				callToneSequence        sql.NullString
+				callDetectedTones       sql.NullString
 				callTimestamp           sql.NullInt64
 				callAlertSummary        sql.NullString
 				incidentAddress         sql.NullString
@@ -2970,7 +2997,7 @@ func (api *Api) AlertsHandler(w http.ResponseWriter, r *http.Request) {
 				incidentGeocodeStatus   sql.NullString
 			)
 
-			if err := rows.Scan(&alertId, &callId, &systemId, &talkgroupId, &alertType, &toneDetected, &toneSetId, &keywordsMatched, &transcriptSnippet, &createdAt, &systemLabel, &systemRef, &talkgroupLabel, &talkgroupName, &callTranscript, &callTranscriptionStatus, &callToneSequence, &callTimestamp, &callAlertSummary, &incidentAddress, &incidentLat, &incidentLon, &incidentNature, &incidentGeocodeStatus); err != nil {
+			if err := rows.Scan(&alertId, &callId, &systemId, &talkgroupId, &alertType, &toneDetected, &toneSetId, &keywordsMatched, &transcriptSnippet, &createdAt, &systemLabel, &systemRef, &talkgroupLabel, &talkgroupName, &callTranscript, &callTranscriptionStatus, &callToneSequence, &callDetectedTones, &callTimestamp, &callAlertSummary, &incidentAddress, &incidentLat, &incidentLon, &incidentNature, &incidentGeocodeStatus); err != nil {
 				continue
 			}
 
@@ -3099,6 +3126,11 @@ func (api *Api) AlertsHandler(w http.ResponseWriter, r *http.Request) {
 			if callTranscriptionStatus.Valid {
 				alertMap["transcriptionStatus"] = callTranscriptionStatus.String
 			}
+			if callDetectedTones.Valid {
+				if tones := parseDetectedTones(callDetectedTones.String); len(tones) > 0 {
+					alertMap["detectedTones"] = formatDetectedTones(tones)
+				}
+			}
 			if callAlertSummary.Valid && callAlertSummary.String != "" {
 				alertMap["alertSummary"] = callAlertSummary.String
 			}
@@ -3545,7 +3577,7 @@ func (api *Api) TranscriptsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	if search != "" {
 		// Use ILIKE for case-insensitive search in PostgreSQL
-		where = append(where, fmt.Sprintf(`c."transcript" ILIKE '%%%s%%'`, escapeQuotes(search)))
+		where = append(where, fmt.Sprintf(`c."transcript" ILIKE '%%%s%%' ESCAPE '\'`, escapeQuotes(escapeLikePattern(search))))
 	}
 	whereClause := strings.Join(where, " AND ")
 
@@ -3860,6 +3892,10 @@ func (api *Api) AlertPreferencesHandler(w http.ResponseWriter, r *http.Request)
 					}
 				}
 			}
+			if err := ValidateKeywords(keywords); err != nil {
+				api.exitWithError(w, http.StatusBadRequest, err.Error())
+				return
+			}
 			if v, ok := pref["keywordListIds"].([]any); ok {
 				for _, id := range v {
 					switch idVal := id.(type) {
@@ -4022,13 +4058,18 @@ func (api *Api) KeywordListsHandler(w http.ResponseWriter, r *http.Request) {
 
 		lists := []map[string]any{}
 		for _, list := range cachedLists {
+			// Users only see lists routed to their own group
+			if !api.isAdmin(client) && !api.Controller.userInAlertGroups(client.User.Id, list.UserGroupIds) {
+				continue
+			}
 			lists = append(lists, map[string]any{
-				"id":          list.Id,
-				"label":       list.Label,
-				"description": list.Description,
-				"keywords":    list.Keywords,
-				"order":       list.Order,
-				"createdAt":   list.CreatedAt,
+				"id":           list.Id,
+				"label":        list.Label,
+				"description":  list.Description,
+				"keywords":     list.Keywords,
+				"order":        list.Order,
+				"createdAt":    list.CreatedAt,
+				"userGroupIds": list.UserGroupIds,
 			})
 		}
 
@@ -4053,10 +4094,11 @@ func (api *Api) KeywordListsHandler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		var (
-			label       string
-			description string
-			keywords    []string
-			order       uint
+			label        string
+			description  string
+			keywords     []string
+			order        uint
+			userGroupIds []uint64
 		)
 
 		if v, ok := list["label"].(string); ok {
@@ -4075,10 +4117,17 @@ func (api *Api) KeywordListsHandler(w http.ResponseWriter, r *http.Request) {
 		if v, ok := list["order"].(float64); ok {
 			order = uint(v)
 		}
+		userGroupIds = parseUserGroupIds(list["userGroupIds"])
+
+		if err := ValidateKeywords(keywords); err != nil {
+			api.exitWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 
 		keywordsJson, _ := json.Marshal(keywords)
+		userGroupIdsJson, _ := json.Marshal(userGroupIds)
 
-		query := fmt.Sprintf(`INSERT INTO "keywordLists" ("label", "description", "keywords", "order", "createdAt") VALUES ('%s', '%s', '%s', %d, %d) RETURNING "keywordListId"`, escapeQuotes(label), escapeQuotes(description), escapeQuotes(string(keywordsJson)), order, time.Now().UnixMilli())
+		query := fmt.Sprintf(`INSERT INTO "keywordLists" ("label", "description", "keywords", "order", "createdAt", "userGroupIds") VALUES ('%s', '%s', '%s', %d, %d, '%s') RETURNING "keywordListId"`, escapeQuotes(label), escapeQuotes(description), escapeQuotes(string(keywordsJson)), order, time.Now().UnixMilli(), escapeQuotes(string(userGroupIdsJson)))
 
 		var listId uint64
 		if err := api.Controller.Database.Sql.QueryRow(query).Scan(&listId); err != nil {
@@ -4135,10 +4184,11 @@ func (api *Api) KeywordListHandler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		var (
-			label       string
-			description string
-			keywords    []string
-			order       uint
+			label        string
+			description  string
+			keywords     []string
+			order        uint
+			userGroupIds []uint64
 		)
 
 		if v, ok := list["label"].(string); ok {
@@ -4157,10 +4207,17 @@ func (api *Api) KeywordListHandler(w http.ResponseWriter, r *http.Request) {
 		if v, ok := list["order"].(float64); ok {
 			order = uint(v)
 		}
+		userGroupIds = parseUserGroupIds(list["userGroupIds"])
+
+		if err := ValidateKeywords(keywords); err != nil {
+			api.exitWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 
 		keywordsJson, _ := json.Marshal(keywords)
+		userGroupIdsJson, _ := json.Marshal(userGroupIds)
 
-		query := fmt.Sprintf(`UPDATE "keywordLists" SET "label" = '%s', "description" = '%s', "keywords" = '%s', "order" = %d WHERE "keywordListId" = %d`, escapeQuotes(label), escapeQuotes(description), escapeQuotes(string(keywordsJson)), order, listId)
+		query := fmt.Sprintf(`UPDATE "keywordLists" SET "label" = '%s', "description" = '%s', "keywords" = '%s', "order" = %d, "userGroupIds" = '%s' WHERE "keywordListId" = %d`, escapeQuotes(label), escapeQuotes(description), escapeQuotes(string(keywordsJson)), order, escapeQuotes(string(userGroupIdsJson)), listId)
 
 		if _, err := api.Controller.Database.Sql.Exec(query); err != nil {
 			api.exitWithError(w, http.StatusInternalServerError, fmt.Sprintf("failed to update keyword list: %v", err))
@@ -6452,25 +6509,28 @@ func (api *Api) AdminGroupsHandler(w http.ResponseWriter, r *http.Request) {
 	groupList := []map[string]interface{}{}
 	for _, group := range groups {
 		groupList = append(groupList, map[string]interface{}{
-			"id":                    group.Id,
-			"name":                  group.Name,
-			"description":           group.Description,
-			"systemAccess":          group.SystemAccess,
-			"delay":                 group.Delay,
-			"systemDelays":          group.SystemDelays,
-			"talkgroupDelays":       group.TalkgroupDelays,
-			"connectionLimit":       group.ConnectionLimit,
-			"maxUsers":              group.MaxUsers,
-			"billingEnabled":        group.BillingEnabled,
-			"stripePriceId":         group.StripePriceId,
-			"pricingOptions":        group.GetPricingOptions(),
-			"billingMode":           group.BillingMode,
-			"collectSalesTax":       group.CollectSalesTax,
-			"taxMode":               group.TaxMode,
-			"stripeTaxRateId":       group.StripeTaxRateId,
-			"isPublicRegistration":  group.IsPublicRegistration,
-			"allowAddExistingUsers": group.AllowAddExistingUsers,
-			"createdAt":             group.CreatedAt,
+			"id":                        group.Id,
+			"name":                      group.Name,
+			"description":               group.Description,
+			"systemAccess":              group.SystemAccess,
+			"delay":                     group.Delay,
+			"systemDelays":              group.SystemDelays,
+			"talkgroupDelays":           group.TalkgroupDelays,
+			"connectionLimit":           group.ConnectionLimit,
+			"maxUsers":                  group.MaxUsers,
+			"billingEnabled":            group.BillingEnabled,
+			"stripePriceId":             group.StripePriceId,
+			"pricingOptions":            group.GetPricingOptions(),
+			"billingMode":               group.BillingMode,
+			"collectSalesTax":           group.CollectSalesTax,
+			"taxMode":                   group.TaxMode,
+			"stripeTaxRateId":           group.StripeTaxRateId,
+			"isPublicRegistration":      group.IsPublicRegistration,
+			"allowAddExistingUsers":     group.AllowAddExistingUsers,
+			"reconnectionGracePeriod":   group.ReconnectionGracePeriod,
+			"reconnectionMaxBufferSize": group.ReconnectionMaxBufferSize,
+			"defaultLivefeedTags":       group.DefaultLivefeedTags,
+			"createdAt":                 group.CreatedAt,
 		})
 	}
 
@@ -6499,23 +6559,26 @@ func (api *Api) AdminCreateGroupHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	var request struct {
-		Name                  string          `json:"name"`
-		Description           string          `json:"description"`
-		SystemAccess          string          `json:"systemAccess"`
-		Delay                 int             `json:"delay"`
-		SystemDelays          string          `json:"systemDelays"`
-		TalkgroupDelays       string          `json:"talkgroupDelays"`
-		ConnectionLimit       uint            `json:"connectionLimit"`
-		MaxUsers              uint            `json:"maxUsers"`
-		BillingEnabled        bool            `json:"billingEnabled"`
-		StripePriceId         string          `json:"stripePriceId"`
-		PricingOptions        []PricingOption `json:"pricingOptions"`
-		BillingMode           string          `json:"billingMode"`
-		CollectSalesTax       bool            `json:"collectSalesTax"`
-		TaxMode               string          `json:"taxMode"`
-		StripeTaxRateId       string          `json:"stripeTaxRateId"`
-		IsPublicRegistration  bool            `json:"isPublicRegistration"`
-		AllowAddExistingUsers bool            `json:"allowAddExistingUsers"`
+		Name                      string          `json:"name"`
+		Description               string          `json:"description"`
+		SystemAccess              string          `json:"systemAccess"`
+		Delay                     int             `json:"delay"`
+		SystemDelays              string          `json:"systemDelays"`
+		TalkgroupDelays           string          `json:"talkgroupDelays"`
+		ConnectionLimit           uint            `json:"connectionLimit"`
+		MaxUsers                  uint            `json:"maxUsers"`
+		BillingEnabled            bool            `json:"billingEnabled"`
+		StripePriceId             string          `json:"stripePriceId"`
+		PricingOptions            []PricingOption `json:"pricingOptions"`
+		BillingMode               string          `json:"billingMode"`
+		CollectSalesTax           bool            `json:"collectSalesTax"`
+		TaxMode                   string          `json:"taxMode"`
+		StripeTaxRateId           string          `json:"stripeTaxRateId"`
+		IsPublicRegistration      bool            `json:"isPublicRegistration"`
+		AllowAddExistingUsers     bool            `json:"allowAddExistingUsers"`
+		ReconnectionGracePeriod   uint            `json:"reconnectionGracePeriod"`
+		ReconnectionMaxBufferSize uint            `json:"reconnectionMaxBufferSize"`
+		DefaultLivefeedTags       string          `json:"defaultLivefeedTags"`
 		// Group admin assignment
 		AssignExistingUserAsAdmin bool   `json:"assignExistingUserAsAdmin"`
 		GroupAdminUserId          uint64 `json:"groupAdminUserId"`
@@ -6581,24 +6644,27 @@ func (api *Api) AdminCreateGroupHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	group := &UserGroup{
-		Name:                  request.Name,
-		Description:           request.Description,
-		SystemAccess:          request.SystemAccess,
-		Delay:                 request.Delay,
-		SystemDelays:          request.SystemDelays,
-		TalkgroupDelays:       request.TalkgroupDelays,
-		ConnectionLimit:       request.ConnectionLimit,
-		MaxUsers:              request.MaxUsers,
-		BillingEnabled:        request.BillingEnabled,
-		StripePriceId:         request.StripePriceId,
-		PricingOptions:        pricingOptionsJSON,
-		BillingMode:           billingMode,
-		CollectSalesTax:       request.CollectSalesTax,
-		TaxMode:               request.TaxMode,
-		StripeTaxRateId:       request.StripeTaxRateId,
-		IsPublicRegistration:  request.IsPublicRegistration,
-		AllowAddExistingUsers: request.AllowAddExistingUsers,
-		CreatedAt:             time.Now().Unix(),
+		Name:                      request.Name,
+		Description:               request.Description,
+		SystemAccess:              request.SystemAccess,
+		Delay:                     request.Delay,
+		SystemDelays:              request.SystemDelays,
+		TalkgroupDelays:           request.TalkgroupDelays,
+		ConnectionLimit:           request.ConnectionLimit,
+		MaxUsers:                  request.MaxUsers,
+		BillingEnabled:            request.BillingEnabled,
+		StripePriceId:             request.StripePriceId,
+		PricingOptions:            pricingOptionsJSON,
+		BillingMode:               billingMode,
+		CollectSalesTax:           request.CollectSalesTax,
+		TaxMode:                   request.TaxMode,
+		StripeTaxRateId:           request.StripeTaxRateId,
+		IsPublicRegistration:      request.IsPublicRegistration,
+		AllowAddExistingUsers:     request.AllowAddExistingUsers,
+		ReconnectionGracePeriod:   request.ReconnectionGracePeriod,
+		ReconnectionMaxBufferSize: request.ReconnectionMaxBufferSize,
+		DefaultLivefeedTags:       request.DefaultLivefeedTags,
+		CreatedAt:                 time.Now().Unix(),
 	}
 
 	if err := api.Controller.UserGroups.Add(group, api.Controller.Database); err != nil {
@@ -6816,24 +6882,27 @@ func (api *Api) AdminUpdateGroupHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	var request struct {
-		Id                    uint64          `json:"id"`
-		Name                  string          `json:"name"`
-		Description           string          `json:"description"`
-		SystemAccess          string          `json:"systemAccess"`
-		Delay                 int             `json:"delay"`
-		SystemDelays          string          `json:"systemDelays"`
-		TalkgroupDelays       string          `json:"talkgroupDelays"`
-		ConnectionLimit       uint            `json:"connectionLimit"`
-		MaxUsers              uint            `json:"maxUsers"`
-		BillingEnabled        bool            `json:"billingEnabled"`
-		StripePriceId         string          `json:"stripePriceId"`
-		PricingOptions        []PricingOption `json:"pricingOptions"`
-		BillingMode           string          `json:"billingMode"`
-		CollectSalesTax       bool            `json:"collectSalesTax"`
-		TaxMode               string          `json:"taxMode"`
-		StripeTaxRateId       string          `json:"stripeTaxRateId"`
-		IsPublicRegistration  bool            `json:"isPublicRegistration"`
-		AllowAddExistingUsers bool            `json:"allowAddExistingUsers"`
+		Id                        uint64          `json:"id"`
+		Name                      string          `json:"name"`
+		Description               string          `json:"description"`
+		SystemAccess              string          `json:"systemAccess"`
+		Delay                     int             `json:"delay"`
+		SystemDelays              string          `json:"systemDelays"`
+		TalkgroupDelays           string          `json:"talkgroupDelays"`
+		ConnectionLimit           uint            `json:"connectionLimit"`
+		MaxUsers                  uint            `json:"maxUsers"`
+		BillingEnabled            bool            `json:"billingEnabled"`
+		StripePriceId             string          `json:"stripePriceId"`
+		PricingOptions            []PricingOption `json:"pricingOptions"`
+		BillingMode               string          `json:"billingMode"`
+		CollectSalesTax           bool            `json:"collectSalesTax"`
+		TaxMode                   string          `json:"taxMode"`
+		StripeTaxRateId           string          `json:"stripeTaxRateId"`
+		IsPublicRegistration      bool            `json:"isPublicRegistration"`
+		AllowAddExistingUsers     bool            `json:"allowAddExistingUsers"`
+		ReconnectionGracePeriod   uint            `json:"reconnectionGracePeriod"`
+		ReconnectionMaxBufferSize uint            `json:"reconnectionMaxBufferSize"`
+		DefaultLivefeedTags       string          `json:"defaultLivefeedTags"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -6905,6 +6974,9 @@ func (api *Api) AdminUpdateGroupHandler(w http.ResponseWriter, r *http.Request)
 	group.StripeTaxRateId = request.StripeTaxRateId
 	group.IsPublicRegistration = request.IsPublicRegistration
 	group.AllowAddExistingUsers = request.AllowAddExistingUsers
+	group.ReconnectionGracePeriod = request.ReconnectionGracePeriod
+	group.ReconnectionMaxBufferSize = request.ReconnectionMaxBufferSize
+	group.DefaultLivefeedTags = request.DefaultLivefeedTags
 
 	if err := api.Controller.UserGroups.Update(group, api.Controller.Database); err != nil {
 		api.exitWithError(w, http.StatusInternalServerError, "Failed to update group")
@@ -8447,6 +8519,10 @@ func (api *Api) UserDeviceTokenHandler(w http.ResponseWriter, r *http.Request) {
 			PushType  string `json:"push_type"`  // "fcm" or "voip"
 			Platform  string `json:"platform"`   // "ios" or "android"
 			Sound     string `json:"sound"`      // Notification sound preference
+			// Optional quiet hours (minutes of day, 0-1439) in the device's IANA timezone
+			QuietStart *int   `json:"quiet_start"`
+			QuietEnd   *int   `json:"quiet_end"`
+			Timezone   string `json:"timezone"`
 		}
 
 		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -8454,6 +8530,32 @@ func (api *Api) UserDeviceTokenHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if (request.QuietStart == nil) != (request.QuietEnd == nil) {
+			api.exitWithError(w, http.StatusBadRequest, "quiet_start and quiet_end must be set together")
+			return
+		}
+		if request.QuietStart != nil && (*request.QuietStart < 0 || *request.QuietStart >= 1440 || *request.QuietEnd < 0 || *request.QuietEnd >= 1440) {
+			api.exitWithError(w, http.StatusBadRequest, "quiet_start and quiet_end must be between 0 and 1439")
+			return
+		}
+		if request.Timezone != "" {
+			if _, err := time.LoadLocation(request.Timezone); err != nil {
+				api.exitWithError(w, http.StatusBadRequest, "Invalid timezone")
+				return
+			}
+		}
+
+		// Quiet hours are only changed when the client sends them.
+		applyQuietHours := func(token *DeviceToken) {
+			if request.QuietStart != nil {
+				token.QuietStart = *request.QuietStart
+				token.QuietEnd = *request.QuietEnd
+			}
+			if request.Timezone != "" {
+				token.Timezone = request.Timezone
+			}
+		}
+
 		// Accept either FCM token or VoIP token.
 		if request.FCMToken == "" && request.VoIPToken == "" {
 			api.exitWithError(w, http.StatusBadRequest, "fcm_token is required")
@@ -8461,9 +8563,16 @@ func (api *Api) UserDeviceTokenHandler(w http.ResponseWriter, r *http.Request) {
 		}
 		request.PushType = "fcm"
 
-		if request.Platform != "ios" && request.Platform != "android" {
+		// Older app builds omit the platform; anything else must be a known name
+		if strings.TrimSpace(request.Platform) == "" {
 			request.Platform = "android"
 		}
+		platform, err := normalizeDeviceTokenPlatform(request.Platform)
+		if err != nil || platform == "web" {
+			api.exitWithError(w, http.StatusBadRequest, "platform must be ios or android")
+			return
+		}
+		request.Platform = platform
 		if request.Sound == "" {
 			request.Sound = "startup.wav"
 		}
@@ -8483,6 +8592,7 @@ func (api *Api) UserDeviceTokenHandler(w http.ResponseWriter, r *http.Request) {
 			existingToken.Sound = request.Sound
 			existingToken.FCMToken = request.FCMToken
 			existingToken.PushType = request.PushType
+			applyQuietHours(existingToken)
 			if err := api.Controller.DeviceTokens.Update(existingToken, api.Controller.Database); err != nil {
 				api.exitWithError(w, http.StatusInternalServerError, "Failed to update device token")
 				return
@@ -8499,6 +8609,7 @@ func (api *Api) UserDeviceTokenHandler(w http.ResponseWriter, r *http.Request) {
 				CreatedAt: time.Now().Unix(),
 				LastUsed:  time.Now().Unix(),
 			}
+			applyQuietHours(deviceToken)
 
 			if err := api.Controller.DeviceTokens.Add(deviceToken, api.Controller.Database); err != nil {
 				api.exitWithError(w, http.StatusInternalServerError, "Failed to register device token")
@@ -8523,6 +8634,7 @@ func (api *Api) UserDeviceTokenHandler(w http.ResponseWriter, r *http.Request) {
 				existingVoIP.Platform = "ios"
 				existingVoIP.PushType = "voip"
 				existingVoIP.FCMToken = voipRaw
+				applyQuietHours(existingVoIP)
 				api.Controller.DeviceTokens.Update(existingVoIP, api.Controller.Database) //nolint:errcheck
 			} else {
 				voipDeviceToken := &DeviceToken{
@@ -8535,6 +8647,7 @@ func (api *Api) UserDeviceTokenHandler(w http.ResponseWriter, r *http.Request) {
 					CreatedAt: time.Now().Unix(),
 					LastUsed:  time.Now().Unix(),
 				}
+				applyQuietHours(voipDeviceToken)
 				api.Controller.DeviceTokens.Add(voipDeviceToken, api.Controller.Database) //nolint:errcheck
 			}
 		}
@@ -8576,6 +8689,56 @@ func (api *Api) UserDeviceTokenHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// UserTestPushHandler sends a test notification to every device registered to
+// the caller through the normal send path and reports the outcome per token.
+// Tokens the provider rejects are pruned as they would be for a real alert.
+func (api *Api) UserTestPushHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		api.exitWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	client := api.getClient(r)
+	if client == nil || client.User == nil {
+		api.exitWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	serverName := api.Controller.Options.Branding
+	if serverName == "" {
+		serverName = "TLR Server"
+	}
+	notification := &PushNotification{
+		Title:        "TEST NOTIFICATION",
+		Message:      fmt.Sprintf("Push notifications from %s are working", strings.ToUpper(serverName)),
+		DefaultSound: "startup.wav",
+		Data: map[string]interface{}{
+			"type":                 "test",
+			"notification_message": "false",
+		},
+	}
+
+	results := api.Controller.DeviceTokens.SendToUser(api.Controller, client.User.Id, notification)
+	sort.Slice(results, func(i, j int) bool { return results[i].TokenId < results[j].TokenId })
+
+	delivered, failed := 0, 0
+	for _, result := range results {
+		if result.Delivered {
+			delivered++
+		} else {
+			failed++
+		}
+	}
+	log.Printf("Test push notification for user %d: %d delivered, %d failed", client.User.Id, delivered, failed)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"results":   results,
+		"delivered": delivered,
+		"failed":    failed,
+	})
+}
+
 // UserTransferToPublicHandler allows users to transfer themselves to the public registration group
 func (api *Api) UserTransferToPublicHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -150,6 +150,15 @@ func (apikey *Apikey) HasAccess(call *Call) bool {
 	return false
 }
 
+// Label names the key in logs without revealing it: its ident, or its id
+// when no ident is set.
+func (apikey *Apikey) Label() string {
+	if apikey.Ident != "" {
+		return fmt.Sprintf("%q (id %d)", apikey.Ident, apikey.Id)
+	}
+	return fmt.Sprintf("id %d", apikey.Id)
+}
+
 func (apikey *Apikey) MarshalJSON() ([]byte, error) {
 	m := map[string]any{
 		"id":                       apikey.Id,
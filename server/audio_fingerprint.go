@@ -204,6 +204,8 @@ func audioExtFromMime(mime string) string {
 		return ".m4a"
 	case strings.Contains(mime, "ogg"):
 		return ".ogg"
+	case strings.Contains(mime, "flac"):
+		return ".flac"
 	case strings.Contains(mime, "wav"):
 		return ".wav"
 	default:
@@ -0,0 +1,356 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	AudioStorageDb = "db"
+	AudioStorageS3 = "s3"
+
+	audioOffloadBatch = 25
+)
+
+var audioObjectExtension = regexp.MustCompile(`^\.[a-z0-9]{1,5}$`)
+
+// AudioStorage keeps call audio outside the database. The database itself is
+// the default backend: calls without an "audioKey" carry their audio in the
+// "audio" column, so there is no implementation for it.
+type AudioStorage interface {
+	Name() string
+	Put(key string, data []byte, mime string) error
+	Get(key string) ([]byte, error)
+	Delete(key string) error
+}
+
+// NewAudioStorage returns the external storage configured in the ini, or nil.
+// Once configured, the storage stays readable even with audio_storage = db,
+// so calls written to it remain playable.
+func NewAudioStorage(config *Config) (AudioStorage, error) {
+	if config.S3Bucket == "" && config.S3Endpoint == "" {
+		if config.AudioStorage == AudioStorageS3 {
+			return nil, fmt.Errorf("audio_storage = s3 needs s3_endpoint and s3_bucket")
+		}
+		return nil, nil
+	}
+
+	storage, err := NewS3Storage(config.S3Endpoint, config.S3Bucket, config.S3Region, config.S3AccessKey, config.S3SecretKey)
+	if err != nil {
+		return nil, err
+	}
+	return storage, nil
+}
+
+// audioObjectKey names a call's audio object by date, so a bucket can be
+// browsed and lifecycle rules can target old prefixes.
+func audioObjectKey(prefix string, systemRef uint, talkgroupRef uint, timestamp time.Time, filename string) string {
+	b := make([]byte, 4)
+	rand.Read(b)
+
+	ext := strings.ToLower(path.Ext(filename))
+	if !audioObjectExtension.MatchString(ext) {
+		ext = ""
+	}
+
+	return fmt.Sprintf("%scalls/%s/%d-%d-%d-%s%s", prefix, timestamp.UTC().Format("2006/01/02"), systemRef, talkgroupRef, timestamp.UnixMilli(), hex.EncodeToString(b), ext)
+}
+
+// storeCallAudio uploads a new call's audio when audio_storage = s3 and sets
+// call.AudioKey. If the upload fails the audio stays in the database.
+func (controller *Controller) storeCallAudio(call *Call) bool {
+	if controller.AudioStorage == nil || controller.Config == nil || controller.Config.AudioStorage != AudioStorageS3 || len(call.Audio) == 0 || call.System == nil || call.Talkgroup == nil {
+		return false
+	}
+
+	key := audioObjectKey(controller.Config.S3Prefix, call.System.SystemRef, call.Talkgroup.TalkgroupRef, call.Timestamp, call.AudioFilename)
+	if err := controller.AudioStorage.Put(key, call.Audio, call.AudioMime); err != nil {
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("audio storage: %v; keeping the call audio in the database", err))
+		return false
+	}
+
+	call.AudioKey = key
+	return true
+}
+
+// loadCallAudio returns the audio of a calls row: the "audio" column itself,
+// or the object "audioKey" points to.
+func (controller *Controller) loadCallAudio(audio []byte, key string) ([]byte, error) {
+	if key == "" {
+		return audio, nil
+	}
+	if controller.AudioStorage == nil {
+		return nil, fmt.Errorf("call audio %s is in external storage, but none is configured", key)
+	}
+	return controller.AudioStorage.Get(key)
+}
+
+// deleteStoredAudio removes the objects of deleted calls.
+func (controller *Controller) deleteStoredAudio(keys []string) {
+	if controller.AudioStorage == nil || len(keys) == 0 {
+		return
+	}
+
+	failed := 0
+	var lastErr error
+	for _, key := range keys {
+		if err := controller.AudioStorage.Delete(key); err != nil {
+			failed++
+			lastErr = err
+		}
+	}
+	if failed > 0 {
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("audio storage: failed to delete %d of %d objects of deleted calls: %v", failed, len(keys), lastErr))
+	}
+}
+
+// storedAudioKeys returns the object keys of the calls matching where, read
+// before a system or talkgroup is deleted since its calls go by cascade.
+func storedAudioKeys(tx *sql.Tx, where string) ([]string, error) {
+	query := fmt.Sprintf(`SELECT "audioKey" FROM "calls" WHERE %s AND "audioKey" <> ''`, where)
+	rows, err := tx.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("%w in %s", err, query)
+	}
+	defer rows.Close()
+
+	keys := []string{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// execDelete runs a DELETE on "calls" and removes the audio objects of the
+// deleted rows from external storage.
+func (calls *Calls) execDelete(db *Database, query string, args ...any) error {
+	if calls.controller == nil || calls.controller.AudioStorage == nil || db.Config.DbType != DbTypePostgresql {
+		_, err := db.Sql.Exec(query, args...)
+		return err
+	}
+
+	rows, err := db.Sql.Query(query+` RETURNING "audioKey"`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	keys := []string{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return err
+		}
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	go calls.controller.deleteStoredAudio(keys)
+
+	return nil
+}
+
+// audioOffloadState is the progress of moving existing audio out of the
+// database, for the admin UI.
+type audioOffloadState struct {
+	Active     bool   `json:"active"`
+	Done       bool   `json:"done"`
+	Moved      int    `json:"moved"`
+	Failed     int    `json:"failed"`
+	Bytes      int64  `json:"bytes"`
+	Error      string `json:"error,omitempty"`
+	StartedAt  int64  `json:"startedAt,omitempty"`
+	FinishedAt int64  `json:"finishedAt,omitempty"`
+}
+
+type audioOffloader struct {
+	mu    sync.Mutex
+	state audioOffloadState
+}
+
+func (o *audioOffloader) snapshot() audioOffloadState {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.state
+}
+
+// startAudioOffload moves the audio of existing calls from the database to
+// the configured storage, in small batches, in the background. Calls are
+// processed in ID order and each is switched over only after its upload
+// succeeded, so an interrupted or failed run can simply be started again.
+func (controller *Controller) startAudioOffload() error {
+	if controller.AudioStorage == nil {
+		return fmt.Errorf("no audio storage is configured")
+	}
+
+	o := &controller.audioOffload
+	o.mu.Lock()
+	if o.state.Active {
+		o.mu.Unlock()
+		return fmt.Errorf("an audio offload is already in progress")
+	}
+	o.state = audioOffloadState{Active: true, StartedAt: time.Now().UnixMilli()}
+	o.mu.Unlock()
+
+	go func() {
+		err := controller.offloadCallAudio(o)
+
+		o.mu.Lock()
+		o.state.Active = false
+		o.state.Done = true
+		o.state.FinishedAt = time.Now().UnixMilli()
+		if err != nil {
+			o.state.Error = err.Error()
+		}
+		state := o.state
+		o.mu.Unlock()
+
+		level := LogLevelInfo
+		if err != nil || state.Failed > 0 {
+			level = LogLevelWarn
+		}
+		controller.Logs.LogEvent(level, fmt.Sprintf("audio offload: moved %d calls (%d bytes) to %s storage, %d failed", state.Moved, state.Bytes, controller.AudioStorage.Name(), state.Failed))
+	}()
+
+	return nil
+}
+
+func (controller *Controller) offloadCallAudio(o *audioOffloader) error {
+	db := controller.Database
+	lastId := uint64(0)
+
+	for {
+		rows, err := db.Sql.Query(`SELECT "callId", "audio", "audioMime", "audioFilename", "systemRef", "talkgroupRef", "timestamp" FROM "calls" WHERE "callId" > $1 AND "audioKey" = '' AND length("audio") > 0 ORDER BY "callId" LIMIT $2`, lastId, audioOffloadBatch)
+		if err != nil {
+			return err
+		}
+
+		type pending struct {
+			id                      uint64
+			audio                   []byte
+			mime, filename          string
+			systemRef, talkgroupRef uint
+			timestamp               int64
+		}
+		batch := []pending{}
+		for rows.Next() {
+			p := pending{}
+			if err := rows.Scan(&p.id, &p.audio, &p.mime, &p.filename, &p.systemRef, &p.talkgroupRef, &p.timestamp); err != nil {
+				rows.Close()
+				return err
+			}
+			batch = append(batch, p)
+		}
+		rows.Close()
+
+		if len(batch) == 0 {
+			return nil
+		}
+
+		for _, p := range batch {
+			lastId = p.id
+
+			key := audioObjectKey(controller.Config.S3Prefix, p.systemRef, p.talkgroupRef, time.UnixMilli(p.timestamp), p.filename)
+			err := controller.AudioStorage.Put(key, p.audio, p.mime)
+			if err == nil {
+				var res sql.Result
+				if res, err = db.Sql.Exec(`UPDATE "calls" SET "audio" = $1, "audioKey" = $2 WHERE "callId" = $3 AND "audioKey" = ''`, []byte{}, key, p.id); err == nil {
+					if n, _ := res.RowsAffected(); n == 0 {
+						err = fmt.Errorf("call %d changed during the offload", p.id)
+					}
+				}
+				if err != nil {
+					controller.AudioStorage.Delete(key)
+				}
+			}
+
+			o.mu.Lock()
+			if err != nil {
+				o.state.Failed++
+				o.state.Error = err.Error()
+			} else {
+				o.state.Moved++
+				o.state.Bytes += int64(len(p.audio))
+			}
+			o.mu.Unlock()
+		}
+	}
+}
+
+// AudioStorageHandler reports where call audio is stored and the offload
+// progress (GET), or starts moving existing audio out of the database (POST).
+//
+//	GET  /api/admin/audio-storage
+//	POST /api/admin/audio-storage
+func (admin *Admin) AudioStorageHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	controller := admin.Controller
+
+	switch r.Method {
+	case http.MethodGet:
+		storage := ""
+		if controller.AudioStorage != nil {
+			storage = controller.AudioStorage.Name()
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"backend": controller.Config.AudioStorage,
+			"storage": storage,
+			"offload": controller.audioOffload.snapshot(),
+		})
+
+	case http.MethodPost:
+		if err := controller.startAudioOffload(); err != nil {
+			status := http.StatusConflict
+			if controller.AudioStorage == nil {
+				status = http.StatusBadRequest
+			}
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]any{"offload": controller.audioOffload.snapshot()})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
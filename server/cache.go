@@ -18,6 +18,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -207,6 +208,31 @@ type KeywordList struct {
 	Keywords    []string
 	Order       uint
 	CreatedAt   int64
+	// UserGroupIds limits which users' alerts may use this list; empty means all users.
+	UserGroupIds []uint64
+}
+
+// parseUserGroupIds reads a list of user group ids from a decoded JSON value,
+// accepting numbers or numeric strings.
+func parseUserGroupIds(v any) []uint64 {
+	ids := []uint64{}
+	values, ok := v.([]any)
+	if !ok {
+		return ids
+	}
+	for _, value := range values {
+		switch id := value.(type) {
+		case float64:
+			if id > 0 {
+				ids = append(ids, uint64(id))
+			}
+		case string:
+			if parsed, err := strconv.ParseUint(id, 10, 64); err == nil && parsed > 0 {
+				ids = append(ids, parsed)
+			}
+		}
+	}
+	return ids
 }
 
 type KeywordListsCache struct {
@@ -229,7 +255,7 @@ func (cache *KeywordListsCache) Read(db *Database) error {
 	// Clear existing cache
 	cache.lists = make(map[uint64]*KeywordList)
 
-	query := `SELECT "keywordListId", "label", "description", "keywords", "order", "createdAt" 
+	query := `SELECT "keywordListId", "label", "description", "keywords", "order", "createdAt", "userGroupIds" 
 	          FROM "keywordLists" 
 	          ORDER BY "order" ASC, "createdAt" DESC`
 
@@ -242,7 +268,7 @@ func (cache *KeywordListsCache) Read(db *Database) error {
 	count := 0
 	for rows.Next() {
 		list := &KeywordList{}
-		var keywordsJson string
+		var keywordsJson, userGroupIdsJson string
 
 		if err := rows.Scan(
 			&list.Id,
@@ -251,6 +277,7 @@ func (cache *KeywordListsCache) Read(db *Database) error {
 			&keywordsJson,
 			&list.Order,
 			&list.CreatedAt,
+			&userGroupIdsJson,
 		); err != nil {
 			continue
 		}
@@ -262,6 +289,19 @@ func (cache *KeywordListsCache) Read(db *Database) error {
 		if list.Keywords == nil {
 			list.Keywords = []string{}
 		}
+		if userGroupIdsJson != "" && userGroupIdsJson != "[]" {
+			json.Unmarshal([]byte(userGroupIdsJson), &list.UserGroupIds)
+		}
+		if list.UserGroupIds == nil {
+			list.UserGroupIds = []uint64{}
+		}
+
+		// Compile patterns at load so matching never compiles on the hot path
+		if cache.controller != nil && cache.controller.KeywordMatcher != nil {
+			if err := cache.controller.KeywordMatcher.Prepare(list.Keywords); err != nil && cache.controller.Logs != nil {
+				cache.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("keyword list %q: %v", list.Label, err))
+			}
+		}
 
 		cache.lists[list.Id] = list
 		count++
@@ -23,6 +23,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -113,6 +114,10 @@ type Call struct {
 	IsDuplicate bool `json:"isDuplicate,omitempty"`
 	AudioHash   string `json:"audioHash,omitempty"`
 
+	// AudioKey is the object holding the audio in external storage; empty when
+	// the audio is in the calls table.
+	AudioKey string `json:"-"`
+
 	// IsForwarded is set when this call was received from another TLR server via
 	// downstream forwarding. It is runtime-only (never stored in DB) and prevents
 	// the receiving server from re-forwarding the call, breaking circular loops.
@@ -185,6 +190,9 @@ func (call *Call) MarshalJSON() ([]byte, error) {
 
 	if call.ToneSequence != nil {
 		callMap["toneSequence"] = call.ToneSequence
+		if len(call.ToneSequence.Tones) > 0 {
+			callMap["detectedTones"] = detectedTonesFromSequence(call.ToneSequence)
+		}
 	}
 
 	if call.Transcript != "" {
@@ -322,6 +330,9 @@ func (call *Call) MarshalJSONWithEncryption(key []byte) ([]byte, error) {
 
 	if call.ToneSequence != nil {
 		callMap["toneSequence"] = call.ToneSequence
+		if len(call.ToneSequence.Tones) > 0 {
+			callMap["detectedTones"] = detectedTonesFromSequence(call.ToneSequence)
+		}
 	}
 	if call.Transcript != "" {
 		transcript := call.Transcript
@@ -616,10 +627,10 @@ func (calls *Calls) GetCall(id uint64) (*Call, error) {
 	call := Call{Id: id}
 
 	if calls.controller.Database.Config.DbType == DbTypePostgresql {
-		query = fmt.Sprintf(`SELECT c."audio", c."audioFilename", c."audioMime", c."siteRef", c."timestamp", STRING_AGG(CAST(COALESCE(cpt."talkgroupRef", 0) AS text), ','), sy."systemId", t."talkgroupId", c."frequency", c."toneSequence", c."hasTones", c."transcript", c."reviewedTranscript", c."trainingReviewStatus", c."transcriptConfidence", c."transcriptionStatus", c."alertSummary" FROM "calls" AS c LEFT JOIN "callPatches" AS cp on cp."callId" = c."callId" LEFT JOIN "talkgroups" AS cpt ON cpt."talkgroupId" = cp."talkgroupId" LEFT JOIN "systems" AS sy ON sy."systemId" = c."systemId" LEFT JOIN "talkgroups" AS t ON t."talkgroupId" = c."talkgroupId" WHERE c."callId" = %d GROUP BY c."callId", c."audio", c."audioFilename", c."audioMime", c."siteRef", c."timestamp", sy."systemId", t."talkgroupId", c."frequency", c."toneSequence", c."hasTones", c."transcript", c."reviewedTranscript", c."trainingReviewStatus", c."transcriptConfidence", c."transcriptionStatus", c."alertSummary"`, id)
+		query = fmt.Sprintf(`SELECT c."audio", c."audioKey", c."audioFilename", c."audioMime", c."siteRef", c."timestamp", STRING_AGG(CAST(COALESCE(cpt."talkgroupRef", 0) AS text), ','), sy."systemId", t."talkgroupId", c."frequency", c."toneSequence", c."hasTones", c."transcript", c."reviewedTranscript", c."trainingReviewStatus", c."transcriptConfidence", c."transcriptionStatus", c."alertSummary" FROM "calls" AS c LEFT JOIN "callPatches" AS cp on cp."callId" = c."callId" LEFT JOIN "talkgroups" AS cpt ON cpt."talkgroupId" = cp."talkgroupId" LEFT JOIN "systems" AS sy ON sy."systemId" = c."systemId" LEFT JOIN "talkgroups" AS t ON t."talkgroupId" = c."talkgroupId" WHERE c."callId" = %d GROUP BY c."callId", c."audio", c."audioKey", c."audioFilename", c."audioMime", c."siteRef", c."timestamp", sy."systemId", t."talkgroupId", c."frequency", c."toneSequence", c."hasTones", c."transcript", c."reviewedTranscript", c."trainingReviewStatus", c."transcriptConfidence", c."transcriptionStatus", c."alertSummary"`, id)
 
 	} else {
-		query = fmt.Sprintf(`SELECT c."audio", c."audioFilename", c."audioMime", c."siteRef", c."timestamp", GROUP_CONCAT(COALESCE(cpt."talkgroupRef", 0)), sy."systemId", t."talkgroupId", c."frequency", c."toneSequence", c."hasTones", c."transcript", c."reviewedTranscript", c."trainingReviewStatus", c."transcriptConfidence", c."transcriptionStatus", c."alertSummary" FROM "calls" AS c LEFT JOIN "callPatches" AS cp on cp."callId" = c."callId" LEFT JOIN "talkgroups" AS cpt ON cpt."talkgroupId" = cp."talkgroupId" LEFT JOIN "systems" AS sy ON sy."systemId" = c."systemId" LEFT JOIN "talkgroups" AS t ON t."talkgroupId" = c."talkgroupId" WHERE c."callId" = %d GROUP BY c."callId", c."audio", c."audioFilename", c."audioMime", c."siteRef", c."timestamp", sy."systemId", t."talkgroupId", c."frequency", c."toneSequence", c."hasTones", c."transcript", c."reviewedTranscript", c."trainingReviewStatus", c."transcriptConfidence", c."transcriptionStatus", c."alertSummary"`, id)
+		query = fmt.Sprintf(`SELECT c."audio", c."audioKey", c."audioFilename", c."audioMime", c."siteRef", c."timestamp", GROUP_CONCAT(COALESCE(cpt."talkgroupRef", 0)), sy."systemId", t."talkgroupId", c."frequency", c."toneSequence", c."hasTones", c."transcript", c."reviewedTranscript", c."trainingReviewStatus", c."transcriptConfidence", c."transcriptionStatus", c."alertSummary" FROM "calls" AS c LEFT JOIN "callPatches" AS cp on cp."callId" = c."callId" LEFT JOIN "talkgroups" AS cpt ON cpt."talkgroupId" = cp."talkgroupId" LEFT JOIN "systems" AS sy ON sy."systemId" = c."systemId" LEFT JOIN "talkgroups" AS t ON t."talkgroupId" = c."talkgroupId" WHERE c."callId" = %d GROUP BY c."callId", c."audio", c."audioKey", c."audioFilename", c."audioMime", c."siteRef", c."timestamp", sy."systemId", t."talkgroupId", c."frequency", c."toneSequence", c."hasTones", c."transcript", c."reviewedTranscript", c."trainingReviewStatus", c."transcriptConfidence", c."transcriptionStatus", c."alertSummary"`, id)
 	}
 
 	var toneSequenceJson sql.NullString
@@ -630,7 +641,7 @@ func (calls *Calls) GetCall(id uint64) (*Call, error) {
 	var transcriptionStatus sql.NullString
 	var alertSummary sql.NullString
 
-	if err = tx.QueryRow(query).Scan(&call.Audio, &call.AudioFilename, &call.AudioMime, &call.SiteRef, &timestamp, &patch, &systemId, &talkgroupId, &frequency, &toneSequenceJson, &call.HasTones, &transcript, &reviewedTranscript, &trainingReviewStatus, &transcriptConfidence, &transcriptionStatus, &alertSummary); err != nil && err != sql.ErrNoRows {
+	if err = tx.QueryRow(query).Scan(&call.Audio, &call.AudioKey, &call.AudioFilename, &call.AudioMime, &call.SiteRef, &timestamp, &patch, &systemId, &talkgroupId, &frequency, &toneSequenceJson, &call.HasTones, &transcript, &reviewedTranscript, &trainingReviewStatus, &transcriptConfidence, &transcriptionStatus, &alertSummary); err != nil && err != sql.ErrNoRows {
 		tx.Rollback()
 		return nil, formatError(err, query)
 	}
@@ -727,6 +738,13 @@ func (calls *Calls) GetCall(id uint64) (*Call, error) {
 		return nil, formatError(err, "")
 	}
 
+	// Fetched after the commit so the transaction is not held open
+	if call.AudioKey != "" {
+		if call.Audio, err = calls.controller.loadCallAudio(call.Audio, call.AudioKey); err != nil {
+			return nil, formatError(err, "")
+		}
+	}
+
 	return &call, nil
 }
 
@@ -736,7 +754,7 @@ func (calls *Calls) GetCall(id uint64) (*Call, error) {
 //	          callId, timestamp, patches, systemId, talkgroupId, frequency,
 //	          toneSequence, hasTones, transcript, transcriptConfidence,
 //	          transcriptionStatus, alertSummary
-//	Query 2 — audio bytes + filenames: callId, audio, audioFilename, audioMime, siteRef
+//	Query 2 — audio bytes + filenames: callId, audio, audioKey, audioFilename, audioMime, siteRef
 //	Query 3 — units:                   callId, offset, unitRef, label
 //
 // Any IDs currently in the delay queue are silently skipped.
@@ -854,15 +872,20 @@ func (calls *Calls) GetCallsBulk(ids []uint64) []*Call {
 
 	// --- Query 2: audio blobs ---
 	audioRows, err := calls.controller.Database.Sql.Query(
-		`SELECT "callId", "audio", "audioFilename", "audioMime", "siteRef" FROM "calls" WHERE "callId" IN (` + inClause + `)`)
+		`SELECT "callId", "audio", "audioKey", "audioFilename", "audioMime", "siteRef" FROM "calls" WHERE "callId" IN (` + inClause + `)`)
 	if err == nil {
 		defer audioRows.Close()
 		for audioRows.Next() {
 			var cid uint64
 			var audio []byte
-			var filename, mime, siteRef string
-			if audioRows.Scan(&cid, &audio, &filename, &mime, &siteRef) == nil {
+			var key, filename, mime, siteRef string
+			if audioRows.Scan(&cid, &audio, &key, &filename, &mime, &siteRef) == nil {
 				if c, ok := byId[cid]; ok {
+					if audio, err = calls.controller.loadCallAudio(audio, key); err != nil {
+						calls.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("call %d: %v", cid, err))
+						continue
+					}
+					c.AudioKey = key
 					c.Audio = audio
 					c.AudioFilename = filename
 					c.AudioMime = mime
@@ -891,15 +914,38 @@ func (calls *Calls) GetCallsBulk(ids []uint64) []*Call {
 	return ordered
 }
 
+// callRetentionDaysExpr is the SQL for the retention of a call joined with its
+// talkgroup t and system s: the talkgroup's, then the system's, then the
+// global pruneDays. systemDays replaces the stored retention of some systems,
+// to preview a change before it is saved; 0 means the global pruneDays.
+func callRetentionDaysExpr(defaultPruneDays uint, systemDays map[uint64]uint) string {
+	ids := make([]uint64, 0, len(systemDays))
+	for id := range systemDays {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var overrides strings.Builder
+	for _, id := range ids {
+		days := systemDays[id]
+		if days == 0 {
+			days = defaultPruneDays
+		}
+		fmt.Fprintf(&overrides, "\n\t\tWHEN s.\"systemId\" = %d THEN %d", id, days)
+	}
+
+	return fmt.Sprintf(`CASE
+		WHEN t."retentionDays" > 0 THEN t."retentionDays"%s
+		WHEN s."retentionDays" > 0 THEN s."retentionDays"
+		ELSE %d
+	END`, overrides.String(), defaultPruneDays)
+}
+
 func (calls *Calls) Prune(db *Database, defaultPruneDays uint) error {
 	nowMs := time.Now().UnixMilli()
 	dayMs := int64(24 * 60 * 60 * 1000)
 
-	effectiveDaysExpr := fmt.Sprintf(`CASE
-		WHEN t."retentionDays" > 0 THEN t."retentionDays"
-		WHEN s."retentionDays" > 0 THEN s."retentionDays"
-		ELSE %d
-	END`, defaultPruneDays)
+	effectiveDaysExpr := callRetentionDaysExpr(defaultPruneDays, nil)
 
 	var query string
 	if db.Config.DbType == DbTypePostgresql {
@@ -920,17 +966,55 @@ WHERE (%s) > 0
 	AND c."timestamp" < (? - CAST((%s) AS INTEGER) * %d))`, effectiveDaysExpr, effectiveDaysExpr, dayMs)
 	}
 
-	if _, err := db.Sql.Exec(query, nowMs); err != nil {
+	if err := calls.execDelete(db, query, nowMs); err != nil {
 		return fmt.Errorf("%s in %s", err, query)
 	}
 
 	return nil
 }
 
+// CallsPruneCount is what Calls.Prune would delete.
+type CallsPruneCount struct {
+	Calls         int64 `json:"calls"`
+	AudioBytes    int64 `json:"audioBytes"`    // audio kept in the database
+	StoredObjects int64 `json:"storedObjects"` // audio objects in external storage, of unknown size
+}
+
+// PruneCount runs the WHERE clause of Prune as a count, without deleting.
+// systemDays previews retention changes, as in callRetentionDaysExpr.
+func (calls *Calls) PruneCount(db *Database, defaultPruneDays uint, systemDays map[uint64]uint) (*CallsPruneCount, error) {
+	nowMs := time.Now().UnixMilli()
+	dayMs := int64(24 * 60 * 60 * 1000)
+
+	effectiveDaysExpr := callRetentionDaysExpr(defaultPruneDays, systemDays)
+
+	var query string
+	if db.Config.DbType == DbTypePostgresql {
+		query = fmt.Sprintf(`SELECT COUNT(*), COALESCE(SUM(octet_length(c."audio")), 0), COUNT(NULLIF(c."audioKey", '')) FROM "calls" c
+INNER JOIN "talkgroups" t ON c."talkgroupId" = t."talkgroupId"
+INNER JOIN "systems" s ON c."systemId" = s."systemId"
+WHERE (%s) > 0
+	AND c."timestamp" < ($1::bigint - ((%s)::bigint * %d::bigint))`, effectiveDaysExpr, effectiveDaysExpr, dayMs)
+	} else {
+		query = fmt.Sprintf(`SELECT COUNT(*), COALESCE(SUM(length(c."audio")), 0), COUNT(NULLIF(c."audioKey", '')) FROM "calls" c
+INNER JOIN "talkgroups" t ON c."talkgroupId" = t."talkgroupId"
+INNER JOIN "systems" s ON c."systemId" = s."systemId"
+WHERE (%s) > 0
+	AND c."timestamp" < (? - CAST((%s) AS INTEGER) * %d)`, effectiveDaysExpr, effectiveDaysExpr, dayMs)
+	}
+
+	count := &CallsPruneCount{}
+	if err := db.Sql.QueryRow(query, nowMs).Scan(&count.Calls, &count.AudioBytes, &count.StoredObjects); err != nil {
+		return nil, fmt.Errorf("%s in %s", err, query)
+	}
+
+	return count, nil
+}
+
 func (calls *Calls) PurgeAll(db *Database) error {
 	query := `DELETE FROM "calls"`
 
-	if _, err := db.Sql.Exec(query); err != nil {
+	if err := calls.execDelete(db, query); err != nil {
 		return fmt.Errorf("%s in %s", err, query)
 	}
 
@@ -955,7 +1039,7 @@ func (calls *Calls) DeleteByIDs(db *Database, ids []uint64) error {
 
 	query := fmt.Sprintf(`DELETE FROM "calls" WHERE "callId" IN (%s)`, strings.Join(placeholders, ", "))
 
-	if _, err := db.Sql.Exec(query, args...); err != nil {
+	if err := calls.execDelete(db, query, args...); err != nil {
 		return fmt.Errorf("%s in %s", err, query)
 	}
 
@@ -1327,12 +1411,19 @@ func (calls *Calls) Search(searchOptions *CallsSearchOptions, client *Client) (*
 }
 
 func (calls *Calls) WriteCall(call *Call, db *Database) (uint64, error) {
+	// Upload once, outside the (possibly retried) transaction
+	stored := calls.controller != nil && calls.controller.storeCallAudio(call)
+
 	var id uint64
 	err := withPostgresIndexHeal(db, func() error {
 		var writeErr error
 		id, writeErr = calls.writeCall(call, db)
 		return writeErr
 	})
+	if err != nil && stored {
+		go calls.controller.deleteStoredAudio([]string{call.AudioKey})
+		call.AudioKey = ""
+	}
 	return id, err
 }
 
@@ -1382,6 +1473,7 @@ func (calls *Calls) writeCall(call *Call, db *Database) (uint64, error) {
 	if toneSequenceJson == "" {
 		toneSequenceJson = "{}"
 	}
+	detectedTonesJson := serializeDetectedTones(call.ToneSequence)
 
 	// Default transcription status
 	if call.TranscriptionStatus == "" {
@@ -1403,15 +1495,21 @@ func (calls *Calls) writeCall(call *Call, db *Database) (uint64, error) {
 		}
 	}
 
+	// Audio kept in external storage leaves an empty blob in the row
+	audio := call.Audio
+	if call.AudioKey != "" {
+		audio = []byte{}
+	}
+
 	if db.Config.DbType == DbTypePostgresql {
-		query = fmt.Sprintf(`INSERT INTO "calls" ("audio", "audioFilename", "audioMime", "siteRef", "systemId", "talkgroupId", "systemRef", "talkgroupRef", "timestamp", "frequency", "toneSequence", "hasTones", "transcript", "transcriptConfidence", "transcriptionStatus", "transmissionId", "requestId", "signalJobId", "receivedAt", "audioDuration", "isDuplicate", "audioHash") VALUES ($1, $2, $3, %d, %d, %d, %d, %d, %d, %d, $4, %t, $5, %.2f, $6, $7, $8, $9, NOW(), %.4f, %t, $10) RETURNING "callId"`, siteRefInt, call.System.Id, call.Talkgroup.Id, call.System.SystemRef, call.Talkgroup.TalkgroupRef, call.Timestamp.UnixMilli(), frequencyValue, call.HasTones, call.TranscriptConfidence, call.Duration, call.IsDuplicate)
+		query = fmt.Sprintf(`INSERT INTO "calls" ("audio", "audioFilename", "audioMime", "siteRef", "systemId", "talkgroupId", "systemRef", "talkgroupRef", "timestamp", "frequency", "toneSequence", "hasTones", "transcript", "transcriptConfidence", "transcriptionStatus", "transmissionId", "requestId", "signalJobId", "receivedAt", "audioDuration", "isDuplicate", "audioHash", "detectedTones", "audioKey") VALUES ($1, $2, $3, %d, %d, %d, %d, %d, %d, %d, $4, %t, $5, %.2f, $6, $7, $8, $9, NOW(), %.4f, %t, $10, $11, $12) RETURNING "callId"`, siteRefInt, call.System.Id, call.Talkgroup.Id, call.System.SystemRef, call.Talkgroup.TalkgroupRef, call.Timestamp.UnixMilli(), frequencyValue, call.HasTones, call.TranscriptConfidence, call.Duration, call.IsDuplicate)
 
-		err = tx.QueryRow(query, call.Audio, call.AudioFilename, call.AudioMime, toneSequenceJson, call.Transcript, call.TranscriptionStatus, call.TransmissionId, call.RequestId, call.SignalJobId, call.AudioHash).Scan(&call.Id)
+		err = tx.QueryRow(query, audio, call.AudioFilename, call.AudioMime, toneSequenceJson, call.Transcript, call.TranscriptionStatus, call.TransmissionId, call.RequestId, call.SignalJobId, call.AudioHash, detectedTonesJson, call.AudioKey).Scan(&call.Id)
 
 	} else {
-		query = fmt.Sprintf(`INSERT INTO "calls" ("audio", "audioFilename", "audioMime", "siteRef", "systemId", "talkgroupId", "systemRef", "talkgroupRef", "timestamp", "frequency", "toneSequence", "hasTones", "transcript", "transcriptConfidence", "transcriptionStatus", "transmissionId", "requestId", "signalJobId", "receivedAt", "audioDuration", "isDuplicate", "audioHash") VALUES (?, ?, ?, %d, %d, %d, %d, %d, %d, %d, ?, %t, ?, %.2f, ?, ?, ?, ?, CURRENT_TIMESTAMP, %.4f, %t, ?)`, siteRefInt, call.System.Id, call.Talkgroup.Id, call.System.SystemRef, call.Talkgroup.TalkgroupRef, call.Timestamp.UnixMilli(), frequencyValue, call.HasTones, call.TranscriptConfidence, call.Duration, call.IsDuplicate)
+		query = fmt.Sprintf(`INSERT INTO "calls" ("audio", "audioFilename", "audioMime", "siteRef", "systemId", "talkgroupId", "systemRef", "talkgroupRef", "timestamp", "frequency", "toneSequence", "hasTones", "transcript", "transcriptConfidence", "transcriptionStatus", "transmissionId", "requestId", "signalJobId", "receivedAt", "audioDuration", "isDuplicate", "audioHash", "detectedTones", "audioKey") VALUES (?, ?, ?, %d, %d, %d, %d, %d, %d, %d, ?, %t, ?, %.2f, ?, ?, ?, ?, CURRENT_TIMESTAMP, %.4f, %t, ?, ?, ?)`, siteRefInt, call.System.Id, call.Talkgroup.Id, call.System.SystemRef, call.Talkgroup.TalkgroupRef, call.Timestamp.UnixMilli(), frequencyValue, call.HasTones, call.TranscriptConfidence, call.Duration, call.IsDuplicate)
 
-		if res, err = tx.Exec(query, call.Audio, call.AudioFilename, call.AudioMime, toneSequenceJson, call.Transcript, call.TranscriptionStatus, call.TransmissionId, call.RequestId, call.SignalJobId, call.AudioHash); err == nil {
+		if res, err = tx.Exec(query, audio, call.AudioFilename, call.AudioMime, toneSequenceJson, call.Transcript, call.TranscriptionStatus, call.TransmissionId, call.RequestId, call.SignalJobId, call.AudioHash, detectedTonesJson, call.AudioKey); err == nil {
 			if id, err := res.LastInsertId(); err == nil {
 				call.Id = uint64(id)
 			}
@@ -16,10 +16,14 @@
 package main
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // CallAudioDownloadHandler serves raw audio bytes for a call.
@@ -29,15 +33,20 @@ import (
 // Authentication: the same user PIN the mobile app already stores when a
 // user adds a scanner (validated via getClient, which checks against the
 // bcrypt-hashed PIN in the users table).
+//
+// Players may request byte ranges to seek, and revalidate with If-None-Match:
+// the response is cacheable but private and revalidated on every use, so the
+// PIN is still checked each time.
 func (api *Api) CallAudioDownloadHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead && r.Method != http.MethodOptions {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
 
 	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, Range, If-None-Match")
+	w.Header().Set("Access-Control-Expose-Headers", "Accept-Ranges, Content-Length, Content-Range, ETag")
 	if r.Method == http.MethodOptions {
 		w.WriteHeader(http.StatusNoContent)
 		return
@@ -85,10 +94,21 @@ func (api *Api) CallAudioDownloadHandler(w http.ResponseWriter, r *http.Request)
 		filename = fmt.Sprintf("call_%d.m4a", callId)
 	}
 
-	w.Header().Set("Content-Type", mimeType)
 	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, filename))
-	w.Header().Set("Content-Length", strconv.Itoa(len(call.Audio)))
-	w.Header().Set("Cache-Control", "no-store")
-	w.WriteHeader(http.StatusOK)
-	w.Write(call.Audio) //nolint:errcheck
+	w.Header().Set("Cache-Control", "private, no-cache")
+	serveCallAudio(w, r, callId, call.Audio, mimeType, call.Timestamp)
+}
+
+// serveCallAudio writes call audio with support for HEAD, Range and
+// conditional requests. Stored audio never changes, so the ETag only depends
+// on the call and its bytes.
+func serveCallAudio(w http.ResponseWriter, r *http.Request, callId uint64, audio []byte, mimeType string, modTime time.Time) {
+	w.Header().Set("Content-Type", mimeType)
+	w.Header().Set("ETag", callAudioETag(callId, audio))
+	http.ServeContent(w, r, "", modTime, bytes.NewReader(audio))
+}
+
+func callAudioETag(callId uint64, audio []byte) string {
+	sum := sha256.Sum256(audio)
+	return fmt.Sprintf(`"%d-%d-%s"`, callId, len(audio), hex.EncodeToString(sum[:8]))
 }
@@ -0,0 +1,52 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestServeCallAudioRangesAndETag(t *testing.T) {
+	audio := []byte("0123456789")
+	serve := func(method string, headers map[string]string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/api/calls/42/audio", nil)
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		serveCallAudio(w, r, 42, audio, "audio/mp4", time.UnixMilli(1700000000000))
+		return w
+	}
+
+	w := serve(http.MethodGet, nil)
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || w.Body.String() != "0123456789" || etag == "" || w.Header().Get("Accept-Ranges") != "bytes" || w.Header().Get("Content-Type") != "audio/mp4" {
+		t.Fatalf("GET: %d %q, headers %v", w.Code, w.Body.String(), w.Header())
+	}
+	if etag != callAudioETag(42, audio) || etag == callAudioETag(43, audio) || etag == callAudioETag(42, audio[1:]) {
+		t.Fatalf("ETag %s is not specific to the call and its audio", etag)
+	}
+
+	w = serve(http.MethodGet, map[string]string{"Range": "bytes=4-7"})
+	if w.Code != http.StatusPartialContent || w.Body.String() != "4567" || w.Header().Get("Content-Range") != "bytes 4-7/10" {
+		t.Fatalf("Range: %d %q, headers %v", w.Code, w.Body.String(), w.Header())
+	}
+
+	w = serve(http.MethodGet, map[string]string{"Range": "bytes=20-"})
+	if w.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Fatalf("unsatisfiable Range: %d", w.Code)
+	}
+
+	w = serve(http.MethodGet, map[string]string{"If-None-Match": etag})
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Fatalf("If-None-Match: %d, %d bytes", w.Code, w.Body.Len())
+	}
+
+	w = serve(http.MethodHead, nil)
+	if w.Code != http.StatusOK || w.Body.Len() != 0 || w.Header().Get("Content-Length") != "10" {
+		t.Fatalf("HEAD: %d, %d bytes, headers %v", w.Code, w.Body.Len(), w.Header())
+	}
+}
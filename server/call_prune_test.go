@@ -0,0 +1,24 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCallRetentionDaysExpr(t *testing.T) {
+	expr := callRetentionDaysExpr(30, nil)
+	if strings.Contains(expr, `s."systemId"`) || !strings.Contains(expr, "ELSE 30") {
+		t.Fatalf("without overrides: %s", expr)
+	}
+
+	expr = callRetentionDaysExpr(30, map[uint64]uint{7: 0, 3: 10})
+	tg := strings.Index(expr, `WHEN t."retentionDays" > 0`)
+	three := strings.Index(expr, `WHEN s."systemId" = 3 THEN 10`)
+	seven := strings.Index(expr, `WHEN s."systemId" = 7 THEN 30`)
+	stored := strings.Index(expr, `WHEN s."retentionDays" > 0`)
+	if tg < 0 || three < tg || seven < three || stored < seven {
+		t.Fatalf("overrides must follow the talkgroup retention and precede the stored system retention: %s", expr)
+	}
+}
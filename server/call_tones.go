@@ -0,0 +1,222 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	toneSearchDefaultHours     = 168
+	toneSearchMaxHours         = 720
+	toneSearchDefaultTolerance = 10.0
+	toneSearchDefaultLimit     = 100
+	toneSearchMaxLimit         = 500
+	toneSearchScanLimit        = 5000
+)
+
+// DetectedTone is the compact per-call record of a detected tone kept in the
+// calls "detectedTones" column so captures can be displayed and searched.
+type DetectedTone struct {
+	Frequency float64 `json:"frequency"`
+	Duration  float64 `json:"duration"`
+}
+
+// detectedTonesFromSequence reduces a tone sequence to its frequencies and
+// durations, rounded to the precision the detector actually resolves.
+func detectedTonesFromSequence(toneSequence *ToneSequence) []DetectedTone {
+	tones := []DetectedTone{}
+	if toneSequence == nil {
+		return tones
+	}
+	for _, tone := range toneSequence.Tones {
+		tones = append(tones, DetectedTone{
+			Frequency: math.Round(tone.Frequency*10) / 10,
+			Duration:  math.Round(tone.Duration*100) / 100,
+		})
+	}
+	return tones
+}
+
+func serializeDetectedTones(toneSequence *ToneSequence) string {
+	b, err := json.Marshal(detectedTonesFromSequence(toneSequence))
+	if err != nil {
+		return "[]"
+	}
+	return string(b)
+}
+
+func parseDetectedTones(s string) []DetectedTone {
+	tones := []DetectedTone{}
+	if s == "" || s == "[]" {
+		return tones
+	}
+	json.Unmarshal([]byte(s), &tones)
+	return tones
+}
+
+// formatDetectedTones renders tones for display, e.g. "1122.5 Hz / 1345.0 Hz".
+func formatDetectedTones(tones []DetectedTone) string {
+	parts := make([]string, len(tones))
+	for i, tone := range tones {
+		parts[i] = fmt.Sprintf("%.1f Hz", tone.Frequency)
+	}
+	return strings.Join(parts, " / ")
+}
+
+// detectedTonesContain reports whether every wanted frequency appears among
+// the detected tones within tolerance Hz.
+func detectedTonesContain(tones []DetectedTone, frequencies []float64, tolerance float64) bool {
+	if len(frequencies) == 0 {
+		return false
+	}
+	for _, frequency := range frequencies {
+		found := false
+		for _, tone := range tones {
+			if math.Abs(tone.Frequency-frequency) <= tolerance {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+type ToneSearchRequest struct {
+	Frequencies []float64 `json:"frequencies"`
+	Tolerance   float64   `json:"tolerance"`
+	SystemId    uint64    `json:"systemId"`
+	TalkgroupId uint64    `json:"talkgroupId"`
+	Hours       int       `json:"hours"`
+	Limit       int       `json:"limit"`
+}
+
+type ToneSearchResult struct {
+	CallId        uint64         `json:"callId"`
+	SystemId      uint64         `json:"systemId"`
+	TalkgroupId   uint64         `json:"talkgroupId"`
+	Timestamp     int64          `json:"timestamp"`
+	DetectedTones []DetectedTone `json:"detectedTones"`
+	Display       string         `json:"display"`
+}
+
+type ToneSearchResponse struct {
+	CallsScanned int                `json:"callsScanned"`
+	Results      []ToneSearchResult `json:"results"`
+}
+
+// searchCallsByTones finds recent calls whose detected tones include all of
+// the requested frequencies.
+func (controller *Controller) searchCallsByTones(req ToneSearchRequest) (*ToneSearchResponse, error) {
+	if controller == nil || controller.Database == nil {
+		return nil, fmt.Errorf("server not ready")
+	}
+	if len(req.Frequencies) == 0 {
+		return nil, fmt.Errorf("at least one frequency is required")
+	}
+
+	tolerance := req.Tolerance
+	if tolerance <= 0 {
+		tolerance = toneSearchDefaultTolerance
+	}
+	hours := req.Hours
+	if hours <= 0 {
+		hours = toneSearchDefaultHours
+	}
+	if hours > toneSearchMaxHours {
+		hours = toneSearchMaxHours
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = toneSearchDefaultLimit
+	}
+	if limit > toneSearchMaxLimit {
+		limit = toneSearchMaxLimit
+	}
+
+	where := []string{
+		`"hasTones" = true`,
+		`"detectedTones" <> '[]'`,
+		fmt.Sprintf(`"timestamp" >= %d`, time.Now().Add(-time.Duration(hours)*time.Hour).UnixMilli()),
+	}
+	if req.SystemId > 0 {
+		where = append(where, fmt.Sprintf(`"systemId" = %d`, req.SystemId))
+	}
+	if req.TalkgroupId > 0 {
+		where = append(where, fmt.Sprintf(`"talkgroupId" = %d`, req.TalkgroupId))
+	}
+	query := fmt.Sprintf(`SELECT "callId", "systemId", "talkgroupId", "timestamp", "detectedTones" FROM "calls" WHERE %s ORDER BY "timestamp" DESC LIMIT %d`, strings.Join(where, " AND "), toneSearchScanLimit)
+
+	rows, err := controller.Database.Sql.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("tone search failed: %v", err)
+	}
+	defer rows.Close()
+
+	resp := &ToneSearchResponse{Results: []ToneSearchResult{}}
+	for rows.Next() {
+		var (
+			result        ToneSearchResult
+			detectedTones string
+		)
+		if err := rows.Scan(&result.CallId, &result.SystemId, &result.TalkgroupId, &result.Timestamp, &detectedTones); err != nil {
+			continue
+		}
+		resp.CallsScanned++
+
+		result.DetectedTones = parseDetectedTones(detectedTones)
+		if !detectedTonesContain(result.DetectedTones, req.Frequencies, tolerance) {
+			continue
+		}
+		result.Display = formatDetectedTones(result.DetectedTones)
+		resp.Results = append(resp.Results, result)
+		if len(resp.Results) >= limit {
+			break
+		}
+	}
+
+	return resp, nil
+}
+
+// ToneSearchHandler finds stored calls by detected tone frequencies.
+func (admin *Admin) ToneSearchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := admin.GetAuthorization(r)
+	if !admin.ValidateToken(token) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var req ToneSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	result, err := admin.Controller.searchCallsByTones(req)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(fmt.Sprintf(`{"error":"%s"}`, escapeQuotes(err.Error()))))
+		return
+	}
+
+	if b, err := json.Marshal(result); err == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b)
+	} else {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import "testing"
+
+func TestDetectedTones(t *testing.T) {
+	sequence := &ToneSequence{Tones: []Tone{
+		{Frequency: 1122.46, Duration: 1.004, ToneType: "A"},
+		{Frequency: 1345.04, Duration: 3.016, ToneType: "B"},
+	}}
+
+	tones := parseDetectedTones(serializeDetectedTones(sequence))
+	if len(tones) != 2 || tones[0].Frequency != 1122.5 || tones[1].Duration != 3.02 {
+		t.Fatalf("round-tripped tones = %+v", tones)
+	}
+	if got := formatDetectedTones(tones); got != "1122.5 Hz / 1345.0 Hz" {
+		t.Fatalf("formatDetectedTones = %q", got)
+	}
+	if got := serializeDetectedTones(nil); got != "[]" {
+		t.Fatalf("serializeDetectedTones(nil) = %q, want []", got)
+	}
+
+	if !detectedTonesContain(tones, []float64{1345, 1120}, 10) {
+		t.Fatal("tone pair within tolerance should match")
+	}
+	if detectedTonesContain(tones, []float64{1122.5, 1500}, 10) {
+		t.Fatal("all requested frequencies must be present")
+	}
+	if detectedTonesContain(tones, nil, 10) {
+		t.Fatal("an empty search should not match")
+	}
+}
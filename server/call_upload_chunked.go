@@ -0,0 +1,436 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Chunked uploads let recorders on flaky links send a call's audio in pieces
+// and resume after a dropped connection instead of starting over:
+//
+//	POST   /api/call-upload/chunked                 call metadata + key, no audio -> { uploadId, offset }
+//	HEAD   /api/call-upload/chunked/{id}            current offset in Upload-Offset
+//	PATCH  /api/call-upload/chunked/{id}            append the body at Upload-Offset
+//	POST   /api/call-upload/chunked/{id}/finalize   ingest the call like /api/call-upload
+//	DELETE /api/call-upload/chunked/{id}            abandon the upload
+const (
+	chunkedUploadPath     = "/api/call-upload/chunked"
+	chunkedUploadTTL      = time.Hour // incomplete uploads idle this long are dropped
+	chunkedUploadMaxSize  = 256 << 20
+	chunkedUploadMaxChunk = 16 << 20
+	chunkedUploadMaxOpen  = 500
+)
+
+type chunkedUpload struct {
+	mutex  sync.Mutex
+	id     string
+	key    string
+	call   *Call
+	size   int64 // declared total audio size, 0 when unknown
+	offset int64
+	path   string
+	// updatedAt is unix nanos of the last chunk.  It is atomic so expire can
+	// read it without the upload's mutex, which finalize holds while calling
+	// Remove.
+	updatedAt atomic.Int64
+}
+
+// ChunkedUploads holds the call uploads still being received in chunks.  The
+// audio is spooled to a temporary file so large calls are not held in memory.
+type ChunkedUploads struct {
+	mutex   sync.Mutex
+	uploads map[string]*chunkedUpload
+	ttl     time.Duration
+}
+
+func NewChunkedUploads() *ChunkedUploads {
+	uploads := &ChunkedUploads{
+		uploads: map[string]*chunkedUpload{},
+		ttl:     chunkedUploadTTL,
+	}
+
+	go uploads.cleanup()
+
+	return uploads
+}
+
+// Start registers a new upload for call and returns it.
+func (uploads *ChunkedUploads) Start(key string, call *Call, size int64) (*chunkedUpload, error) {
+	if size < 0 || size > chunkedUploadMaxSize {
+		return nil, fmt.Errorf("size must be between 0 and %d bytes", chunkedUploadMaxSize)
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	f, err := os.CreateTemp("", "tlr-upload-*.part")
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+
+	upload := &chunkedUpload{
+		id:   hex.EncodeToString(b),
+		key:  key,
+		call: call,
+		size: size,
+		path: f.Name(),
+	}
+	upload.updatedAt.Store(time.Now().UnixNano())
+
+	uploads.mutex.Lock()
+	defer uploads.mutex.Unlock()
+
+	if len(uploads.uploads) >= chunkedUploadMaxOpen {
+		os.Remove(upload.path)
+		return nil, errors.New("too many uploads in progress")
+	}
+	uploads.uploads[upload.id] = upload
+
+	return upload, nil
+}
+
+func (uploads *ChunkedUploads) Get(id string) (*chunkedUpload, bool) {
+	uploads.mutex.Lock()
+	defer uploads.mutex.Unlock()
+
+	upload, ok := uploads.uploads[id]
+	return upload, ok
+}
+
+// Remove drops an upload and its spooled audio.
+func (uploads *ChunkedUploads) Remove(id string) {
+	uploads.mutex.Lock()
+	upload, ok := uploads.uploads[id]
+	delete(uploads.uploads, id)
+	uploads.mutex.Unlock()
+
+	if ok {
+		os.Remove(upload.path)
+	}
+}
+
+func (uploads *ChunkedUploads) cleanup() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		uploads.expire(time.Now())
+	}
+}
+
+// expire drops uploads that have not received a chunk within the TTL.
+func (uploads *ChunkedUploads) expire(now time.Time) int {
+	expired := []*chunkedUpload{}
+
+	uploads.mutex.Lock()
+	for id, upload := range uploads.uploads {
+		if idle := now.Sub(time.Unix(0, upload.updatedAt.Load())); idle > uploads.ttl {
+			expired = append(expired, upload)
+			delete(uploads.uploads, id)
+		}
+	}
+	uploads.mutex.Unlock()
+
+	for _, upload := range expired {
+		os.Remove(upload.path)
+	}
+
+	if len(expired) > 0 {
+		log.Printf("api: expired %d incomplete chunked call uploads", len(expired))
+	}
+
+	return len(expired)
+}
+
+// Append writes a chunk that must start at the current offset and returns the
+// new offset.  A mismatched offset returns the current one with an error, so
+// the recorder can resume from there.
+func (upload *chunkedUpload) Append(offset int64, r io.Reader) (int64, error) {
+	upload.mutex.Lock()
+	defer upload.mutex.Unlock()
+
+	if offset != upload.offset {
+		return upload.offset, errChunkOffset
+	}
+
+	limit := int64(chunkedUploadMaxSize) - upload.offset
+	if upload.size > 0 {
+		limit = upload.size - upload.offset
+	}
+	if limit > chunkedUploadMaxChunk {
+		limit = chunkedUploadMaxChunk
+	}
+
+	f, err := os.OpenFile(upload.path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return upload.offset, err
+	}
+	defer f.Close()
+
+	n, err := io.Copy(f, io.LimitReader(r, limit+1))
+	if n > limit {
+		// Roll back so the chunk can be resent in smaller pieces.
+		f.Truncate(upload.offset)
+		return upload.offset, errChunkTooLarge
+	}
+	if err != nil {
+		// Keep the bytes that did arrive; the recorder resumes after them.
+		f.Sync()
+	}
+
+	upload.offset += n
+	upload.updatedAt.Store(time.Now().UnixNano())
+
+	return upload.offset, err
+}
+
+// Offset returns how many audio bytes have been received.
+func (upload *chunkedUpload) Offset() int64 {
+	upload.mutex.Lock()
+	defer upload.mutex.Unlock()
+
+	return upload.offset
+}
+
+var (
+	errChunkOffset   = errors.New("chunk offset does not match the upload offset")
+	errChunkTooLarge = errors.New("chunk exceeds the maximum chunk or upload size")
+)
+
+// ChunkedCallUploadHandler serves the chunked call upload protocol.
+func (api *Api) ChunkedCallUploadHandler(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, chunkedUploadPath), "/")
+
+	if path == "" {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		api.chunkedUploadStart(w, r)
+		return
+	}
+
+	id, action, _ := strings.Cut(path, "/")
+
+	upload, ok := api.Controller.ChunkedUploads.Get(id)
+	if !ok {
+		api.exitWithError(w, http.StatusNotFound, "Upload not found or expired")
+		return
+	}
+
+	switch {
+	case action == "" && (r.Method == http.MethodHead || r.Method == http.MethodGet):
+		api.chunkedUploadStatus(w, upload)
+
+	case action == "" && (r.Method == http.MethodPatch || r.Method == http.MethodPut):
+		api.chunkedUploadAppend(w, r, upload)
+
+	case action == "" && r.Method == http.MethodDelete:
+		api.Controller.ChunkedUploads.Remove(upload.id)
+		w.WriteHeader(http.StatusNoContent)
+
+	case action == "finalize" && r.Method == http.MethodPost:
+		api.chunkedUploadFinalize(w, upload)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (api *Api) chunkedUploadStart(w http.ResponseWriter, r *http.Request) {
+	if api.Controller.draining.Load() {
+		w.Header().Set("Retry-After", "30")
+		api.exitWithError(w, http.StatusServiceUnavailable, "Server is restarting, please retry")
+		return
+	}
+
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		api.exitWithError(w, http.StatusBadRequest, "Not a multipart content")
+		return
+	}
+
+	var (
+		call = NewCall()
+		key  string
+		size int64
+	)
+
+	mr := multipart.NewReader(io.LimitReader(r.Body, 1<<20), params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			api.exitWithError(w, http.StatusBadRequest, fmt.Sprintf("multipart: %s", err.Error()))
+			return
+		}
+
+		b, err := io.ReadAll(p)
+		if err != nil {
+			api.exitWithError(w, http.StatusBadRequest, fmt.Sprintf("ioread: %s", err.Error()))
+			return
+		}
+
+		switch p.FormName() {
+		case "key":
+			key = string(b)
+		case "size":
+			if size, err = strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64); err != nil {
+				api.exitWithError(w, http.StatusBadRequest, "Invalid size")
+				return
+			}
+		case "audio":
+			// Audio arrives in chunks, never with the metadata.
+		default:
+			ParseMultipartContent(call, p, b)
+		}
+	}
+
+	// Only recorders holding a valid API key may reserve upload space.  The
+	// key's per-system access is checked again on finalize with the full call.
+	if _, ok := api.Controller.Apikeys.GetApikey(key); !ok {
+		api.exitWithError(w, http.StatusUnauthorized, "Invalid API key")
+		return
+	}
+
+	upload, err := api.Controller.ChunkedUploads.Start(key, call, size)
+	if err != nil {
+		api.exitWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	log.Printf("api: chunked call upload %s started from %s (system %d, talkgroup %d, %d bytes)", upload.id, r.RemoteAddr, call.SystemId, call.TalkgroupId, size)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", fmt.Sprintf("%s/%s", chunkedUploadPath, upload.id))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]any{
+		"uploadId":  upload.id,
+		"offset":    0,
+		"expiresIn": int64(api.Controller.ChunkedUploads.ttl.Seconds()),
+	})
+}
+
+func (api *Api) chunkedUploadStatus(w http.ResponseWriter, upload *chunkedUpload) {
+	offset := upload.Offset()
+
+	w.Header().Set("Upload-Offset", strconv.FormatInt(offset, 10))
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"uploadId": upload.id, "offset": offset, "size": upload.size})
+}
+
+func (api *Api) chunkedUploadAppend(w http.ResponseWriter, r *http.Request, upload *chunkedUpload) {
+	offsetValue := r.Header.Get("Upload-Offset")
+	if offsetValue == "" {
+		offsetValue = r.URL.Query().Get("offset")
+	}
+	offset, err := strconv.ParseInt(offsetValue, 10, 64)
+	if err != nil {
+		api.exitWithError(w, http.StatusBadRequest, "Upload-Offset header is required")
+		return
+	}
+
+	current, err := upload.Append(offset, r.Body)
+
+	w.Header().Set("Upload-Offset", strconv.FormatInt(current, 10))
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case errors.Is(err, errChunkOffset):
+		w.WriteHeader(http.StatusConflict)
+	case errors.Is(err, errChunkTooLarge):
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	case err != nil:
+		// The connection dropped mid-chunk; whatever arrived is kept.
+		log.Printf("api: chunked call upload %s interrupted at %d bytes: %v", upload.id, current, err)
+		w.WriteHeader(http.StatusBadRequest)
+	}
+
+	json.NewEncoder(w).Encode(map[string]any{"uploadId": upload.id, "offset": current})
+}
+
+func (api *Api) chunkedUploadFinalize(w http.ResponseWriter, upload *chunkedUpload) {
+	if !api.beginUpload(w) {
+		return
+	}
+	defer api.Controller.ingestActive.Add(-1)
+
+	upload.mutex.Lock()
+	defer upload.mutex.Unlock()
+
+	if upload.size > 0 && upload.offset != upload.size {
+		w.Header().Set("Upload-Offset", strconv.FormatInt(upload.offset, 10))
+		api.exitWithError(w, http.StatusConflict, fmt.Sprintf("Upload incomplete: %d of %d bytes received", upload.offset, upload.size))
+		return
+	}
+
+	audio, err := os.ReadFile(upload.path)
+	if err != nil {
+		api.exitWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to read upload: %s", err.Error()))
+		return
+	}
+
+	call := upload.call
+	call.Audio = audio
+
+	if ok, err := call.IsValid(); !ok {
+		api.Controller.ChunkedUploads.Remove(upload.id)
+		api.exitWithError(w, http.StatusExpectationFailed, fmt.Sprintf("Incomplete call data: %s", err.Error()))
+		return
+	}
+
+	log.Printf("api: chunked call upload %s complete (%d bytes), passing to HandleCall", upload.id, len(audio))
+
+	rw := &chunkedUploadResponseWriter{ResponseWriter: w, status: http.StatusOK}
+	api.HandleCall(upload.key, call, rw)
+
+	// A busy server answers 503; keep the upload so finalize can be retried.
+	if rw.status != http.StatusServiceUnavailable {
+		api.Controller.ChunkedUploads.Remove(upload.id)
+	}
+}
+
+// chunkedUploadResponseWriter records the status HandleCall answered with.
+type chunkedUploadResponseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rw *chunkedUploadResponseWriter) WriteHeader(code int) {
+	rw.status = code
+	rw.ResponseWriter.WriteHeader(code)
+}
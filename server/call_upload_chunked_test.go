@@ -0,0 +1,138 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestChunkedUploadResume(t *testing.T) {
+	uploads := &ChunkedUploads{uploads: map[string]*chunkedUpload{}, ttl: chunkedUploadTTL}
+
+	upload, err := uploads.Start("key", NewCall(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer uploads.Remove(upload.id)
+
+	if offset, err := upload.Append(0, strings.NewReader("hello")); err != nil || offset != 5 {
+		t.Fatalf("first chunk: offset %d, err %v", offset, err)
+	}
+
+	// A resend of the first chunk after a lost response is rejected with the real offset.
+	if offset, err := upload.Append(0, strings.NewReader("hello")); !errors.Is(err, errChunkOffset) || offset != 5 {
+		t.Fatalf("stale chunk: offset %d, err %v", offset, err)
+	}
+
+	// More than the declared size is refused and leaves the offset alone.
+	if offset, err := upload.Append(5, strings.NewReader("world!")); !errors.Is(err, errChunkTooLarge) || offset != 5 {
+		t.Fatalf("oversize chunk: offset %d, err %v", offset, err)
+	}
+
+	if offset, err := upload.Append(5, strings.NewReader("world")); err != nil || offset != 10 {
+		t.Fatalf("second chunk: offset %d, err %v", offset, err)
+	}
+
+	b, err := os.ReadFile(upload.path)
+	if err != nil || !bytes.Equal(b, []byte("helloworld")) {
+		t.Fatalf("spooled audio = %q, %v", b, err)
+	}
+}
+
+func TestChunkedUploadExpire(t *testing.T) {
+	uploads := &ChunkedUploads{uploads: map[string]*chunkedUpload{}, ttl: time.Minute}
+
+	upload, err := uploads.Start("key", NewCall(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := uploads.expire(time.Now()); n != 0 {
+		t.Fatalf("expired %d fresh uploads", n)
+	}
+	if n := uploads.expire(time.Now().Add(2 * time.Minute)); n != 1 {
+		t.Fatalf("expired %d idle uploads, want 1", n)
+	}
+	if _, ok := uploads.Get(upload.id); ok {
+		t.Fatal("idle upload still registered")
+	}
+	if _, err := os.Stat(upload.path); !os.IsNotExist(err) {
+		t.Fatalf("temp file not removed: %v", err)
+	}
+}
+
+// Finalize removes the upload while holding its mutex; expire must not wait on
+// that mutex while holding the uploads map, or the two deadlock.
+func TestChunkedUploadFinalizeDuringExpire(t *testing.T) {
+	uploads := &ChunkedUploads{uploads: map[string]*chunkedUpload{}, ttl: time.Minute}
+	api := NewApi(&Controller{Logs: NewLogs(), ChunkedUploads: uploads})
+
+	pending := []*chunkedUpload{}
+	for i := 0; i < 50; i++ {
+		// No audio, so finalize rejects the call and removes the upload.
+		upload, err := uploads.Start("key", NewCall(), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pending = append(pending, upload)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		stop := make(chan struct{})
+		expired := make(chan struct{})
+		go func() {
+			defer close(expired)
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					uploads.expire(time.Now())
+				}
+			}
+		}()
+
+		var wg sync.WaitGroup
+		for _, upload := range pending {
+			wg.Add(1)
+			go func(upload *chunkedUpload) {
+				defer wg.Done()
+				w := httptest.NewRecorder()
+				api.chunkedUploadFinalize(w, upload)
+				if w.Code != http.StatusExpectationFailed {
+					t.Errorf("finalize answered %d", w.Code)
+				}
+			}(upload)
+		}
+		wg.Wait()
+
+		close(stop)
+		<-expired
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("finalize and expire deadlocked")
+	}
+
+	for _, upload := range pending {
+		if _, ok := uploads.Get(upload.id); ok {
+			t.Fatal("finalized upload still registered")
+		}
+		if _, err := os.Stat(upload.path); !os.IsNotExist(err) {
+			t.Fatalf("temp file not removed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CentralGrantScopeReport tells Central Management which of the systems and
+// talkgroups in a grant exist on this server.  Talkgroups nested under a
+// system are reported as "systemRef/talkgroupRef".
+type CentralGrantScopeReport struct {
+	AcceptedSystems    []string `json:"acceptedSystems"`
+	RejectedSystems    []string `json:"rejectedSystems"`
+	AcceptedTalkgroups []string `json:"acceptedTalkgroups"`
+	RejectedTalkgroups []string `json:"rejectedTalkgroups"`
+}
+
+func (report *CentralGrantScopeReport) HasRejections() bool {
+	return len(report.RejectedSystems) > 0 || len(report.RejectedTalkgroups) > 0
+}
+
+// centralGrantRef reads a system or talkgroup ref sent by Central Management,
+// which may arrive as a JSON number or a numeric string.
+func centralGrantRef(v any) (uint, bool) {
+	switch value := v.(type) {
+	case float64:
+		if value > 0 && value == float64(uint(value)) {
+			return uint(value), true
+		}
+	case string:
+		if parsed, err := strconv.ParseUint(strings.TrimSpace(value), 10, 32); err == nil && parsed > 0 {
+			return uint(parsed), true
+		}
+	}
+	return 0, false
+}
+
+// validateCentralGrantScopes checks the systems and talkgroups of a grant
+// against the configured systems and returns the entries that exist.  Systems
+// entries are either bare system refs or {"id": ref, "talkgroups": "*"|[refs]}
+// scopes (the format stored on users); top-level talkgroups are refs that must
+// exist on at least one system.  Wildcards and nil pass through unchanged.
+func validateCentralGrantScopes(systems *Systems, reqSystems any, reqTalkgroups any) (any, any, *CentralGrantScopeReport) {
+	report := &CentralGrantScopeReport{
+		AcceptedSystems:    []string{},
+		RejectedSystems:    []string{},
+		AcceptedTalkgroups: []string{},
+		RejectedTalkgroups: []string{},
+	}
+
+	acceptedSystems := reqSystems
+	if entries, ok := reqSystems.([]any); ok {
+		kept := []any{}
+		for _, entry := range entries {
+			scope, isScope := entry.(map[string]any)
+			idVal := entry
+			if isScope {
+				idVal = scope["id"]
+			}
+
+			ref, ok := centralGrantRef(idVal)
+			var system *System
+			if ok {
+				system, ok = systems.GetSystemByRef(ref)
+			}
+			if !ok {
+				report.RejectedSystems = append(report.RejectedSystems, fmt.Sprint(idVal))
+				continue
+			}
+			report.AcceptedSystems = append(report.AcceptedSystems, strconv.FormatUint(uint64(ref), 10))
+
+			if !isScope {
+				kept = append(kept, entry)
+				continue
+			}
+			if talkgroups, ok := scope["talkgroups"].([]any); ok {
+				keptTalkgroups := []any{}
+				for _, tg := range talkgroups {
+					tgRef, ok := centralGrantRef(tg)
+					if ok {
+						_, ok = system.Talkgroups.GetTalkgroupByRef(tgRef)
+					}
+					label := fmt.Sprintf("%d/%v", ref, tg)
+					if !ok {
+						report.RejectedTalkgroups = append(report.RejectedTalkgroups, label)
+						continue
+					}
+					report.AcceptedTalkgroups = append(report.AcceptedTalkgroups, label)
+					keptTalkgroups = append(keptTalkgroups, tg)
+				}
+				filtered := map[string]any{}
+				for k, v := range scope {
+					filtered[k] = v
+				}
+				filtered["talkgroups"] = keptTalkgroups
+				entry = filtered
+			}
+			kept = append(kept, entry)
+		}
+		acceptedSystems = kept
+	}
+
+	acceptedTalkgroups := reqTalkgroups
+	if entries, ok := reqTalkgroups.([]any); ok {
+		kept := []any{}
+		for _, entry := range entries {
+			ref, ok := centralGrantRef(entry)
+			found := false
+			if ok {
+				systems.mutex.RLock()
+				for _, system := range systems.List {
+					if _, exists := system.Talkgroups.GetTalkgroupByRef(ref); exists {
+						found = true
+						break
+					}
+				}
+				systems.mutex.RUnlock()
+			}
+			if !found {
+				report.RejectedTalkgroups = append(report.RejectedTalkgroups, fmt.Sprint(entry))
+				continue
+			}
+			report.AcceptedTalkgroups = append(report.AcceptedTalkgroups, fmt.Sprint(entry))
+			kept = append(kept, entry)
+		}
+		acceptedTalkgroups = kept
+	}
+
+	return acceptedSystems, acceptedTalkgroups, report
+}
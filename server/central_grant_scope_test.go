@@ -0,0 +1,50 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestValidateCentralGrantScopes(t *testing.T) {
+	system := NewSystem()
+	system.SystemRef = 10
+	system.Talkgroups.List = []*Talkgroup{{TalkgroupRef: 100}, {TalkgroupRef: 200}}
+	systems := NewSystems()
+	systems.List = []*System{system}
+
+	var reqSystems, reqTalkgroups any
+	json.Unmarshal([]byte(`[{"id":10,"talkgroups":[100,300]},{"id":99,"talkgroups":"*"},"10"]`), &reqSystems)
+	json.Unmarshal([]byte(`[200,"400"]`), &reqTalkgroups)
+
+	gotSystems, gotTalkgroups, report := validateCentralGrantScopes(systems, reqSystems, reqTalkgroups)
+
+	b, _ := json.Marshal(gotSystems)
+	if string(b) != `[{"id":10,"talkgroups":[100]},"10"]` {
+		t.Fatalf("systems = %s", b)
+	}
+	if b, _ := json.Marshal(gotTalkgroups); string(b) != `[200]` {
+		t.Fatalf("talkgroups = %s", b)
+	}
+
+	want := &CentralGrantScopeReport{
+		AcceptedSystems:    []string{"10", "10"},
+		RejectedSystems:    []string{"99"},
+		AcceptedTalkgroups: []string{"10/100", "200"},
+		RejectedTalkgroups: []string{"10/300", "400"},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Fatalf("report = %+v, want %+v", report, want)
+	}
+	if !report.HasRejections() {
+		t.Fatal("HasRejections = false, want true")
+	}
+
+	// Wildcards are left alone.
+	gotSystems, gotTalkgroups, report = validateCentralGrantScopes(systems, "*", nil)
+	if gotSystems != "*" || gotTalkgroups != nil || report.HasRejections() {
+		t.Fatalf("wildcard grant changed: %v %v %+v", gotSystems, gotTalkgroups, report)
+	}
+}
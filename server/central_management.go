@@ -17,11 +17,17 @@ package main
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"runtime"
 	"strings"
@@ -29,26 +35,82 @@ import (
 	"time"
 
 	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/disk"
 	"github.com/shirou/gopsutil/v4/process"
 )
 
+// configUpdateDebounce is how long NotifyConfigChanged waits for edits to
+// settle before sending a diff, so a bulk import results in one request.
+const configUpdateDebounce = 10 * time.Second
+
+// Pairing challenge lifetimes. The pairing secret is entered by the local
+// admin and is single-use; nonces only need to outlive one CM round trip.
+const (
+	pairingSecretTTL = 15 * time.Minute
+	pairingNonceTTL  = 2 * time.Minute
+)
+
+// Heartbeat cadence. Consecutive failures double the delay up to
+// heartbeatMaxBackoff; the first success drops back to heartbeatInterval.
+const (
+	heartbeatInterval   = 1 * time.Minute
+	heartbeatMaxBackoff = 15 * time.Minute
+)
+
+// heartbeatStatTimeout bounds any heartbeat stat that touches the filesystem,
+// so a hung mount can't push the heartbeat past its 10s HTTP timeout.
+const heartbeatStatTimeout = 2 * time.Second
+
+// cmSystemSnapshot is the part of a system CM tracks, used to compute
+// config-update diffs.
+type cmSystemSnapshot struct {
+	Label      string
+	Kind       string
+	Talkgroups map[uint64]string // talkgroup id -> label
+}
+
 // CentralManagementService handles communication with the centralized management system
 type CentralManagementService struct {
 	controller *Controller
 	stopChan   chan struct{}
 	registered bool
 
+	// activeURL is the CM endpoint that last answered. Requests try it first
+	// and fall back to the other configured URLs on connection errors.
+	urlMu     sync.Mutex
+	activeURL string
+
+	// Heartbeat state, written by heartbeatLoop.
+	heartbeatMu       sync.Mutex
+	heartbeatFailures int
+	lastHeartbeat     time.Time // last successful heartbeat
+	lastHeartbeatErr  string
+	nextHeartbeat     time.Time
+
 	// Pending removal code issued by the CM system (cleared after use or expiry)
 	removalCodeMu     sync.Mutex
 	removalCode       string
 	removalCodeExpiry time.Time
 
+	// Pairing challenge state. The secret never leaves this server; CM proves
+	// knowledge of it by returning HMAC-SHA256(secret, nonce).
+	pairingMu           sync.Mutex
+	pairingSecret       string
+	pairingSecretExpiry time.Time
+	usedNonces          map[string]time.Time
+
 	// CPU sampling state. gopsutil reports % since the previous call to
 	// cpu.Percent / process.Percent, so we keep one *process.Process around for
 	// the whole CMS lifetime and rely on the heartbeat cadence (~1/min) to space
 	// the samples. The first call after init returns 0 which is fine — it just
 	// means the very first heartbeat reports 0% CPU.
 	procSampler *process.Process
+
+	// Config-update diffing. configSnapshot is the systems/talkgroups view
+	// CM last acknowledged; nil until the first successful register.
+	configMu       sync.Mutex
+	configTimer    *time.Timer
+	configSnapshot map[uint64]cmSystemSnapshot
 }
 
 // NewCentralManagementService creates a new central management service
@@ -85,13 +147,19 @@ func (cms *CentralManagementService) Start() {
 		log.Println("Central Management: Successfully registered")
 	}
 
-	// Start heartbeat loop (first heartbeat fires immediately, then every minute)
+	// Start heartbeat loop (first heartbeat after a minute, backing off while CM is down)
 	go cms.heartbeatLoop()
 }
 
 // Stop stops the central management service
 func (cms *CentralManagementService) Stop() {
 	close(cms.stopChan)
+
+	cms.configMu.Lock()
+	if cms.configTimer != nil {
+		cms.configTimer.Stop()
+	}
+	cms.configMu.Unlock()
 }
 
 // register sends registration information to the central system
@@ -137,7 +205,150 @@ func (cms *CentralManagementService) register() error {
 	}
 
 	// Send registration request
-	return cms.sendRequest("POST", "/api/tlr/register", payload)
+	snapshot := cms.snapshotConfig()
+	if err := cms.sendRequest("POST", "/api/tlr/register", payload); err != nil {
+		return err
+	}
+
+	cms.configMu.Lock()
+	cms.configSnapshot = snapshot
+	cms.configMu.Unlock()
+
+	return nil
+}
+
+// NotifyConfigChanged tells CM that systems or talkgroups may have changed.
+// Calls are debounced; once edits settle, only the added, removed and changed
+// system and talkgroup IDs are posted to /api/tlr/config-update.
+func (cms *CentralManagementService) NotifyConfigChanged() {
+	if !cms.controller.Options.CentralManagementEnabled {
+		return
+	}
+
+	cms.configMu.Lock()
+	defer cms.configMu.Unlock()
+
+	if cms.configTimer != nil {
+		cms.configTimer.Reset(configUpdateDebounce)
+		return
+	}
+	cms.configTimer = time.AfterFunc(configUpdateDebounce, cms.sendConfigUpdate)
+}
+
+// sendConfigUpdate posts the diff between the last acknowledged snapshot and
+// the current config. The snapshot only advances when CM accepts the update,
+// so a failed send is folded into the next one.
+func (cms *CentralManagementService) sendConfigUpdate() {
+	cms.configMu.Lock()
+	previous := cms.configSnapshot
+	cms.configMu.Unlock()
+
+	// Never registered: the next register() sends the full list anyway.
+	if previous == nil {
+		return
+	}
+
+	current := cms.snapshotConfig()
+	diff, changed := diffConfigSnapshots(previous, current)
+	if !changed {
+		return
+	}
+
+	if id := cms.controller.Options.CentralManagementServerID; id != "" {
+		diff["server_id"] = id
+	}
+
+	if err := cms.sendRequest("POST", "/api/tlr/config-update", diff); err != nil {
+		log.Printf("Central Management: Config update failed: %v", err)
+		return
+	}
+
+	cms.configMu.Lock()
+	cms.configSnapshot = current
+	cms.configMu.Unlock()
+}
+
+// snapshotConfig captures the systems and talkgroups CM tracks.
+func (cms *CentralManagementService) snapshotConfig() map[uint64]cmSystemSnapshot {
+	snapshot := map[uint64]cmSystemSnapshot{}
+
+	cms.controller.Systems.mutex.RLock()
+	defer cms.controller.Systems.mutex.RUnlock()
+
+	for _, system := range cms.controller.Systems.List {
+		snap := cmSystemSnapshot{
+			Label:      system.Label,
+			Kind:       system.Kind,
+			Talkgroups: map[uint64]string{},
+		}
+		if system.Talkgroups != nil {
+			system.Talkgroups.mutex.Lock()
+			for _, talkgroup := range system.Talkgroups.List {
+				snap.Talkgroups[talkgroup.Id] = talkgroup.Label
+			}
+			system.Talkgroups.mutex.Unlock()
+		}
+		snapshot[system.Id] = snap
+	}
+
+	return snapshot
+}
+
+// diffConfigSnapshots returns the config-update payload and whether anything
+// changed between the two snapshots.
+func diffConfigSnapshots(previous, current map[uint64]cmSystemSnapshot) (map[string]interface{}, bool) {
+	systemsAdded, systemsRemoved, systemsChanged := []uint64{}, []uint64{}, []uint64{}
+	talkgroupsAdded, talkgroupsRemoved, talkgroupsChanged := []uint64{}, []uint64{}, []uint64{}
+
+	for id, cur := range current {
+		prev, ok := previous[id]
+		if !ok {
+			systemsAdded = append(systemsAdded, id)
+			for tgId := range cur.Talkgroups {
+				talkgroupsAdded = append(talkgroupsAdded, tgId)
+			}
+			continue
+		}
+		if prev.Label != cur.Label || prev.Kind != cur.Kind {
+			systemsChanged = append(systemsChanged, id)
+		}
+		for tgId, label := range cur.Talkgroups {
+			if prevLabel, ok := prev.Talkgroups[tgId]; !ok {
+				talkgroupsAdded = append(talkgroupsAdded, tgId)
+			} else if prevLabel != label {
+				talkgroupsChanged = append(talkgroupsChanged, tgId)
+			}
+		}
+		for tgId := range prev.Talkgroups {
+			if _, ok := cur.Talkgroups[tgId]; !ok {
+				talkgroupsRemoved = append(talkgroupsRemoved, tgId)
+			}
+		}
+	}
+	for id, prev := range previous {
+		if _, ok := current[id]; !ok {
+			systemsRemoved = append(systemsRemoved, id)
+			for tgId := range prev.Talkgroups {
+				talkgroupsRemoved = append(talkgroupsRemoved, tgId)
+			}
+		}
+	}
+
+	changed := len(systemsAdded)+len(systemsRemoved)+len(systemsChanged)+
+		len(talkgroupsAdded)+len(talkgroupsRemoved)+len(talkgroupsChanged) > 0
+
+	return map[string]interface{}{
+		"systems": map[string][]uint64{
+			"added":   systemsAdded,
+			"removed": systemsRemoved,
+			"changed": systemsChanged,
+		},
+		"talkgroups": map[string][]uint64{
+			"added":   talkgroupsAdded,
+			"removed": talkgroupsRemoved,
+			"changed": talkgroupsChanged,
+		},
+	}, changed
 }
 
 // heartbeatLoop sends periodic heartbeats to the central system forever.
@@ -151,7 +362,8 @@ func (cms *CentralManagementService) register() error {
 // every brief CM downtime cascaded into a fleet-wide manual repair job.
 //
 // Behaviour now:
-//   - Tick every minute, send a heartbeat.
+//   - Send a heartbeat every minute, backing off exponentially (up to 15
+//     minutes) while CM keeps failing, and back to every minute on success.
 //   - On failure, log + try to re-register (CM's /api/tlr/register is
 //     idempotent: it'll UPDATE an existing row by api_key, claim a pending
 //     row, or INSERT a new one — so this also self-heals when CM has
@@ -165,54 +377,88 @@ func (cms *CentralManagementService) register() error {
 // pairing from the scanner admin UI rather than have the scanner silently
 // commit suicide on its own.
 func (cms *CentralManagementService) heartbeatLoop() {
-	ticker := time.NewTicker(1 * time.Minute)
-	defer ticker.Stop()
+	timer := time.NewTimer(heartbeatInterval)
+	defer timer.Stop()
 
-	consecutiveFailures := 0
+	cms.heartbeatMu.Lock()
+	cms.nextHeartbeat = time.Now().Add(heartbeatInterval)
+	cms.heartbeatMu.Unlock()
 
 	for {
 		select {
-		case <-ticker.C:
-			if err := cms.sendHeartbeat(); err != nil {
-				consecutiveFailures++
-				log.Printf("Central Management: Heartbeat failed (%d consecutive failures, will keep retrying): %v",
-					consecutiveFailures, err)
+		case <-timer.C:
+			err := cms.sendHeartbeat()
+
+			cms.heartbeatMu.Lock()
+			previous := cms.heartbeatFailures
+			repeated := err != nil && previous > 0 && err.Error() == cms.lastHeartbeatErr
+			failures := 0
+			if err != nil {
+				failures = previous + 1
+				cms.lastHeartbeatErr = err.Error()
+			} else {
+				cms.lastHeartbeat = time.Now()
+			}
+			cms.heartbeatFailures = failures
+			delay := heartbeatDelay(failures)
+			cms.nextHeartbeat = time.Now().Add(delay)
+			cms.heartbeatMu.Unlock()
+
+			if err != nil {
+				// The first failure and any new error are logged in full; the
+				// same error again only as a periodic summary.
+				if !repeated {
+					log.Printf("Central Management: Heartbeat to %s failed (%d consecutive failures, retrying in %s): %v",
+						cms.ActiveURL(), failures, delay, err)
+				} else if failures&(failures-1) == 0 {
+					log.Printf("Central Management: Heartbeat to %s still failing after %d attempts, retrying in %s",
+						cms.ActiveURL(), failures, delay)
+				}
 
 				// Always attempt a re-register on heartbeat failure. CM
 				// might have lost our row (DB restore, admin re-add) or we
 				// might never have registered cleanly in the first place;
 				// register() is idempotent on the CM side, so it's cheap
-				// and safe to retry every minute.
-				if regErr := cms.register(); regErr != nil {
-					// Don't log this as a hard error — the heartbeat error
-					// above already explains why we're here, and a follow-
-					// up "re-register also failed" line just adds noise.
-					_ = regErr
-				} else {
+				// and safe to retry on every attempt.
+				if regErr := cms.register(); regErr == nil {
 					cms.registered = true
 					log.Println("Central Management: Re-registration successful")
 				}
 			} else {
-				if consecutiveFailures > 0 {
-					log.Printf("Central Management: Heartbeat recovered after %d consecutive failures", consecutiveFailures)
+				if previous > 0 {
+					log.Printf("Central Management: Heartbeat to %s recovered after %d consecutive failures", cms.ActiveURL(), previous)
 				}
 				cms.registered = true
-				consecutiveFailures = 0
 			}
+
+			timer.Reset(delay)
 		case <-cms.stopChan:
 			return
 		}
 	}
 }
 
+// heartbeatDelay is the wait before the next heartbeat after the given
+// number of consecutive failures.
+func heartbeatDelay(failures int) time.Duration {
+	delay := heartbeatInterval
+	for i := 0; i < failures && delay < heartbeatMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > heartbeatMaxBackoff {
+		delay = heartbeatMaxBackoff
+	}
+	return delay
+}
+
 // sendHeartbeat sends a heartbeat to the central system, including a small
 // snapshot of in-process counters so Central Management can render scanner
 // stats without scanners having to expose any extra HTTP endpoints.
 //
-// Every value here is read from already-tracked memory (Clients map, runtime
-// MemStats, transcription queue depth, workerStats, the database/sql pool,
-// and the per-second RecentCalls ring buffer). One extra runtime.ReadMemStats
-// per minute is the only added cost on the scanner.
+// Almost every value here is read from already-tracked memory (Clients map,
+// runtime MemStats, transcription queue depth, workerStats, the database/sql
+// pool, and the per-second RecentCalls ring buffer). The one exception is the
+// data-directory disk stat, which is bounded by heartbeatStatTimeout.
 func (cms *CentralManagementService) sendHeartbeat() error {
 	payload := cms.gatherStatsPayload()
 	return cms.sendRequest("POST", "/api/tlr/heartbeat", payload)
@@ -236,6 +482,13 @@ func (cms *CentralManagementService) gatherStatsPayload() map[string]interface{}
 		payload["server_id"] = id
 	}
 
+	if ctrl.Config != nil {
+		payload["db_type"] = ctrl.Config.DbType
+	}
+	if !processStartTime.IsZero() {
+		payload["uptime_seconds"] = int64(time.Since(processStartTime).Seconds())
+	}
+
 	// Listener count — already O(1) on Clients.Map.
 	if ctrl.Clients != nil {
 		payload["listener_count"] = ctrl.Clients.Count()
@@ -292,12 +545,185 @@ func (cms *CentralManagementService) gatherStatsPayload() map[string]interface{}
 		payload["db_wait_count"] = dbStats.WaitCount // cumulative; CM converts to a per-minute delta
 	}
 
+	// Free space on the data directory (audio and local DB files live under
+	// BaseDir). Statfs is normally instant, but on a stalled network mount it
+	// can hang, so it is skipped for this beat if it takes too long.
+	if ctrl.Config != nil && ctrl.Config.BaseDir != "" {
+		result := make(chan *disk.UsageStat, 1)
+		go func(dir string) {
+			usage, err := disk.Usage(dir)
+			if err != nil {
+				usage = nil
+			}
+			result <- usage
+		}(ctrl.Config.BaseDir)
+
+		select {
+		case usage := <-result:
+			if usage != nil {
+				payload["disk_free_bytes"] = usage.Free
+				payload["disk_total_bytes"] = usage.Total
+			}
+		case <-time.After(heartbeatStatTimeout):
+		}
+	}
+
 	return payload
 }
 
-// sendRequest sends an HTTP request to the central management system
+// parseCentralManagementURLs splits the centralManagementURL option, which
+// may hold one URL, a comma-separated list, or a JSON array (primary first).
+func parseCentralManagementURLs(raw string) []string {
+	raw = strings.TrimSpace(raw)
+
+	var entries []string
+	if strings.HasPrefix(raw, "[") {
+		if err := json.Unmarshal([]byte(raw), &entries); err != nil {
+			return nil
+		}
+	} else {
+		entries = strings.Split(raw, ",")
+	}
+
+	urls := []string{}
+	for _, entry := range entries {
+		if entry = strings.TrimRight(strings.TrimSpace(entry), "/"); entry != "" {
+			urls = append(urls, entry)
+		}
+	}
+	return urls
+}
+
+// ActiveURL returns the CM URL currently in use: the last one that answered,
+// or the primary when none has yet.
+func (cms *CentralManagementService) ActiveURL() string {
+	urls := parseCentralManagementURLs(cms.controller.Options.CentralManagementURL)
+
+	cms.urlMu.Lock()
+	defer cms.urlMu.Unlock()
+
+	for _, u := range urls {
+		if u == cms.activeURL {
+			return u
+		}
+	}
+	if len(urls) > 0 {
+		return urls[0]
+	}
+	return ""
+}
+
+// maskCentralManagementURL reduces a CM URL to scheme and host so status
+// output never shows credentials, paths or query strings embedded in it.
+func maskCentralManagementURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "***"
+	}
+	masked := u.Scheme + "://" + u.Host
+	if u.Path != "" || u.RawQuery != "" {
+		masked += "/***"
+	}
+	return masked
+}
+
+// Status reports this server's CM connection state for the local admin.
+func (cms *CentralManagementService) Status() map[string]any {
+	opts := cms.controller.Options
+
+	urls := []string{}
+	for _, u := range parseCentralManagementURLs(opts.CentralManagementURL) {
+		urls = append(urls, maskCentralManagementURL(u))
+	}
+	active := ""
+	if u := cms.ActiveURL(); u != "" {
+		active = maskCentralManagementURL(u)
+	}
+
+	unixOrNil := func(t time.Time) any {
+		if t.IsZero() {
+			return nil
+		}
+		return t.Unix()
+	}
+
+	cms.heartbeatMu.Lock()
+	status := map[string]any{
+		"enabled":              opts.CentralManagementEnabled,
+		"paired":               len(urls) > 0 && opts.CentralManagementAPIKey != "",
+		"url":                  active,
+		"urls":                 urls,
+		"server_name":          opts.CentralManagementServerName,
+		"server_id":            opts.CentralManagementServerID,
+		"registered":           cms.registered,
+		"last_heartbeat":       unixOrNil(cms.lastHeartbeat),
+		"next_heartbeat":       unixOrNil(cms.nextHeartbeat),
+		"consecutive_failures": cms.heartbeatFailures,
+		"last_error":           cms.lastHeartbeatErr,
+	}
+	cms.heartbeatMu.Unlock()
+
+	cms.removalCodeMu.Lock()
+	pending := cms.removalCode != "" && time.Now().Before(cms.removalCodeExpiry)
+	status["removal_code_pending"] = pending
+	status["removal_code_expires_at"] = nil
+	if pending {
+		status["removal_code_expires_at"] = cms.removalCodeExpiry.Unix()
+	}
+	cms.removalCodeMu.Unlock()
+
+	return status
+}
+
+// candidateURLs lists the configured URLs with the active one first.
+func (cms *CentralManagementService) candidateURLs() []string {
+	active := cms.ActiveURL()
+	urls := []string{active}
+	for _, u := range parseCentralManagementURLs(cms.controller.Options.CentralManagementURL) {
+		if u != active {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// sendRequest sends an HTTP request to the central management system. When
+// the active CM cannot be reached, the remaining URLs are tried in order; the
+// first to answer becomes active and, unless this was a registration, the
+// server re-registers there. HTTP error statuses do not fail over, as the CM
+// that returned them is up.
 func (cms *CentralManagementService) sendRequest(method, path string, payload interface{}) error {
-	url := cms.controller.Options.CentralManagementURL + path
+	urls := cms.candidateURLs()
+	if len(urls) == 0 || urls[0] == "" {
+		return fmt.Errorf("central management URL not configured")
+	}
+
+	var err error
+	for i, base := range urls {
+		if err = cms.sendRequestTo(base, method, path, payload); err == nil || !errors.Is(err, errCMUnreachable) {
+			if i > 0 && err == nil {
+				cms.urlMu.Lock()
+				cms.activeURL = base
+				cms.urlMu.Unlock()
+
+				log.Printf("Central Management: %s unreachable, failed over to %s", urls[0], base)
+				if path != "/api/tlr/register" {
+					if regErr := cms.register(); regErr != nil {
+						log.Printf("Central Management: re-registration with %s failed: %v", base, regErr)
+					}
+				}
+			}
+			return err
+		}
+	}
+	return err
+}
+
+// errCMUnreachable marks transport failures, the only ones that fail over.
+var errCMUnreachable = errors.New("central management unreachable")
+
+func (cms *CentralManagementService) sendRequestTo(base, method, path string, payload interface{}) error {
+	url := base + path
 
 	var body []byte
 	var err error
@@ -322,17 +748,118 @@ func (cms *CentralManagementService) sendRequest(method, path string, payload in
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return fmt.Errorf("failed to send request to %s: %w: %w", base, errCMUnreachable, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return fmt.Errorf("unexpected status code from %s: %d", base, resp.StatusCode)
 	}
 
 	return nil
 }
 
+// SetPairingSecret stores the secret the local admin entered (or a generated
+// one when empty) for the next pairing attempt.
+func (cms *CentralManagementService) SetPairingSecret(secret string) (string, time.Time, error) {
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		b := make([]byte, 6)
+		if _, err := rand.Read(b); err != nil {
+			return "", time.Time{}, err
+		}
+		secret = strings.ToUpper(hex.EncodeToString(b))
+	} else if len(secret) < 8 {
+		return "", time.Time{}, errors.New("pairing secret must be at least 8 characters")
+	}
+
+	expiry := time.Now().Add(pairingSecretTTL)
+
+	cms.pairingMu.Lock()
+	cms.pairingSecret = secret
+	cms.pairingSecretExpiry = expiry
+	cms.pairingMu.Unlock()
+
+	return secret, expiry, nil
+}
+
+// NewPairingNonce returns a nonce of the form <random>.<expiry>.<signature>,
+// signed with the server secret so it cannot be forged or have its expiry
+// extended.
+func (cms *CentralManagementService) NewPairingNonce() (string, time.Time, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", time.Time{}, err
+	}
+
+	expiry := time.Now().Add(pairingNonceTTL)
+	body := fmt.Sprintf("%s.%d", hex.EncodeToString(b), expiry.Unix())
+
+	return body + "." + cms.signPairingNonce(body), expiry, nil
+}
+
+func (cms *CentralManagementService) signPairingNonce(body string) string {
+	mac := hmac.New(sha256.New, []byte(cms.controller.Options.secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyPairingResponse checks a nonce issued by NewPairingNonce and the
+// HMAC of it keyed by the pairing secret. Each nonce and the pairing secret
+// can only be used once.
+func (cms *CentralManagementService) VerifyPairingResponse(nonce, response string) error {
+	parts := strings.Split(nonce, ".")
+	if len(parts) != 3 {
+		return errors.New("malformed nonce")
+	}
+
+	body := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(cms.signPairingNonce(body))) {
+		return errors.New("invalid nonce signature")
+	}
+
+	var expiresAt int64
+	if _, err := fmt.Sscan(parts[1], &expiresAt); err != nil {
+		return errors.New("malformed nonce")
+	}
+	now := time.Now()
+	if now.After(time.Unix(expiresAt, 0)) {
+		return errors.New("nonce expired")
+	}
+
+	cms.pairingMu.Lock()
+	defer cms.pairingMu.Unlock()
+
+	if cms.usedNonces == nil {
+		cms.usedNonces = map[string]time.Time{}
+	}
+	for n, exp := range cms.usedNonces {
+		if now.After(exp) {
+			delete(cms.usedNonces, n)
+		}
+	}
+	if _, used := cms.usedNonces[nonce]; used {
+		return errors.New("nonce already used")
+	}
+	cms.usedNonces[nonce] = time.Unix(expiresAt, 0)
+
+	if cms.pairingSecret == "" || now.After(cms.pairingSecretExpiry) {
+		cms.pairingSecret = ""
+		return errors.New("no pairing secret set")
+	}
+
+	mac := hmac.New(sha256.New, []byte(cms.pairingSecret))
+	mac.Write([]byte(nonce))
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(strings.ToLower(strings.TrimSpace(response))), []byte(expected)) {
+		return errors.New("invalid pairing response")
+	}
+
+	cms.pairingSecret = ""
+
+	return nil
+}
+
 // TestConnection tests the connection to the central management system with provided credentials.
 // It returns the exact upstream HTTP status and response body for easier troubleshooting in the UI.
 func (cms *CentralManagementService) TestConnection(centralURL, apiKey, serverName, serverURL string) (int, []byte, error) {
@@ -16,19 +16,123 @@
 package main
 
 import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/golang-jwt/jwt/v4"
-	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// constantTimeEqual reports whether a secret supplied by a caller matches the
+// expected one without leaking timing information. Both sides are hashed first
+// so a length mismatch takes the same time as any other mismatch. An empty
+// expected value never matches, so an unset key can't be satisfied by an
+// empty header.
+func constantTimeEqual(given, expected string) bool {
+	if expected == "" {
+		return false
+	}
+	g := sha256.Sum256([]byte(given))
+	e := sha256.Sum256([]byte(expected))
+	return subtle.ConstantTimeCompare(g[:], e[:]) == 1
+}
+
+// centralSignatureMaxSkew is how far X-CM-Timestamp may be from this server's
+// clock before a signed request is refused as a possible replay.
+const centralSignatureMaxSkew = 5 * time.Minute
+
+// centralWebhookMaxBody caps how much of a signed request body is read for
+// verification.
+const centralWebhookMaxBody = 10 << 20
+
+// centralWebhookSignature returns the hex HMAC-SHA256 that Central Management
+// sends in X-CM-Signature: the X-CM-Timestamp value, the method, the request
+// URI (path and query) and the raw body, joined by ".", keyed by the webhook
+// secret. Signing the method and URI keeps a captured request from being
+// replayed against another endpoint.
+func centralWebhookSignature(secret, timestamp, method, requestURI string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + method + "." + requestURI + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyCentralWebhookSignature checks a signed CM request. The timestamp is in
+// unix seconds and must be within centralSignatureMaxSkew of now.
+func verifyCentralWebhookSignature(secret, timestamp, signature, method, requestURI string, body []byte, now time.Time) error {
+	if secret == "" {
+		return errors.New("no webhook secret configured")
+	}
+	ts, err := strconv.ParseInt(strings.TrimSpace(timestamp), 10, 64)
+	if err != nil {
+		return errors.New("missing or invalid X-CM-Timestamp")
+	}
+	if skew := now.Sub(time.Unix(ts, 0)); skew > centralSignatureMaxSkew || skew < -centralSignatureMaxSkew {
+		return fmt.Errorf("stale timestamp (%s from server clock)", skew.Round(time.Second))
+	}
+	given, err := hex.DecodeString(strings.TrimSpace(signature))
+	if err != nil {
+		return errors.New("malformed X-CM-Signature")
+	}
+	expected, _ := hex.DecodeString(centralWebhookSignature(secret, strings.TrimSpace(timestamp), method, requestURI, body))
+	if !hmac.Equal(given, expected) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+// authorizeCentralWebhook authenticates a request from Central Management and
+// writes the 401 itself when it fails. A request carrying X-CM-Signature is
+// verified against the webhook secret; otherwise the static X-API-Key is
+// accepted unless centralManagementRequireSignature is set. The body is read
+// for verification and restored for the handler.
+func (api *Api) authorizeCentralWebhook(w http.ResponseWriter, r *http.Request) bool {
+	options := api.Controller.Options
+
+	reject := func(reason, message string) bool {
+		api.Controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("central webhook %s rejected: %s from %s", r.URL.Path, reason, GetRemoteAddr(r)))
+		api.exitWithError(w, http.StatusUnauthorized, message)
+		return false
+	}
+
+	if signature := r.Header.Get("X-CM-Signature"); signature != "" {
+		var body []byte
+		if r.Body != nil {
+			var err error
+			body, err = io.ReadAll(http.MaxBytesReader(w, r.Body, centralWebhookMaxBody))
+			if err != nil {
+				api.exitWithError(w, http.StatusBadRequest, "Invalid request body")
+				return false
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		if err := verifyCentralWebhookSignature(options.CentralManagementWebhookSecret, r.Header.Get("X-CM-Timestamp"), signature, r.Method, r.URL.RequestURI(), body, time.Now()); err != nil {
+			return reject("invalid signature ("+err.Error()+")", "Invalid signature")
+		}
+		return true
+	}
+
+	if options.CentralManagementRequireSignature {
+		return reject("unsigned request", "Signed request required")
+	}
+	if !constantTimeEqual(r.Header.Get("X-API-Key"), options.CentralManagementAPIKey) {
+		return reject("invalid API key", "Invalid API key")
+	}
+	return true
+}
+
 // CentralUserGrantRequest represents a request to grant user access from central system
 type CentralUserGrantRequest struct {
 	Email           string      `json:"email"`
@@ -39,6 +143,7 @@ type CentralUserGrantRequest struct {
 	Talkgroups      interface{} `json:"talkgroups"`      // can be "*" or array of talkgroup IDs
 	GroupID         *uint64     `json:"group_id"`        // optional user group ID
 	ConnectionLimit uint        `json:"connectionLimit"` // 0 = unlimited
+	PinExpiresAt    uint64      `json:"pinExpiresAt"`    // unix seconds; 0 or omitted = never expires
 }
 
 // CentralUserRevokeRequest represents a request to revoke user access from central system
@@ -47,6 +152,12 @@ type CentralUserRevokeRequest struct {
 	PIN   string `json:"pin"`
 }
 
+// CentralUserSetGroupRequest moves an existing user into a user group (null group_id clears it)
+type CentralUserSetGroupRequest struct {
+	Email   string  `json:"email"`
+	GroupID *uint64 `json:"group_id"`
+}
+
 // CentralWebhookUserGrantHandler handles user access grants from central management system
 func (api *Api) CentralWebhookUserGrantHandler(w http.ResponseWriter, r *http.Request) {
 	// Verify central management is enabled
@@ -55,10 +166,7 @@ func (api *Api) CentralWebhookUserGrantHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	// Verify API key
-	apiKey := r.Header.Get("X-API-Key")
-	if apiKey != api.Controller.Options.CentralManagementAPIKey {
-		api.exitWithError(w, http.StatusUnauthorized, "Invalid API key")
+	if !api.authorizeCentralWebhook(w, r) {
 		return
 	}
 
@@ -69,18 +177,58 @@ func (api *Api) CentralWebhookUserGrantHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	// The trimmed PIN is what gets validated and stored
+	req.PIN = strings.TrimSpace(req.PIN)
+
 	// Validate required fields
 	if req.Email == "" || req.PIN == "" {
 		api.exitWithError(w, http.StatusBadRequest, "Email and PIN are required")
 		return
 	}
 
+	// Drop systems and talkgroups this server doesn't have, or refuse the
+	// whole grant when strict grants are enabled.
+	var scopes *CentralGrantScopeReport
+	req.Systems, req.Talkgroups, scopes = validateCentralGrantScopes(api.Controller.Systems, req.Systems, req.Talkgroups)
+	if scopes.HasRejections() {
+		log.Printf("Central Management: grant for %s references unknown systems %v / talkgroups %v", req.Email, scopes.RejectedSystems, scopes.RejectedTalkgroups)
+		if api.Controller.Options.CentralManagementStrictGrants {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":  "Grant references systems or talkgroups that do not exist on this server",
+				"scopes": scopes,
+			})
+			return
+		}
+	}
+
 	// Check if user already exists
 	existingUser := api.Controller.Users.GetUserByEmail(req.Email)
+
+	// A PIN the user already has is kept even if it predates the PIN rules
+	if existingUser == nil || existingUser.Pin != req.PIN {
+		var userId uint64
+		if existingUser != nil {
+			userId = existingUser.Id
+		}
+		if err := api.Controller.Users.ValidatePin(req.PIN, userId, api.Controller.Config.PinMinLength); err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, ErrPinInUse) {
+				status = http.StatusConflict
+			}
+			api.Controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("central management: grant for %s from %s refused: %v", req.Email, GetRemoteAddr(r), err))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+	}
+
 	if existingUser != nil {
 		// Update existing user
 		existingUser.Pin = req.PIN
-		existingUser.PinExpiresAt = 0 // No expiration for centrally managed users
+		existingUser.PinExpiresAt = req.PinExpiresAt // 0 = no expiration
 		existingUser.FirstName = req.FirstName
 		existingUser.LastName = req.LastName
 		existingUser.Verified = true // Central users are pre-verified
@@ -114,7 +262,7 @@ func (api *Api) CentralWebhookUserGrantHandler(w http.ResponseWriter, r *http.Re
 
 		// Write directly to the DB for this specific user — targeted and reliable.
 		_, dbErr := api.Controller.Database.Sql.Exec(
-			`UPDATE "users" SET "pin"=$1, "pinExpiresAt"=$2, "connectionLimit"=$3, "firstName"=$4, "lastName"=$5, "systems"=$6, "talkgroups"=$7, "userGroupId"=$8, "verified"=$9 WHERE "userId"=$10`,
+			`UPDATE "users" SET "pin"=$1, "pinExpiresAt"=$2, "connectionLimit"=$3, "firstName"=$4, "lastName"=$5, "systems"=$6, "talkgroups"=$7, "userGroupId"=$8, "verified"=$9, "modifiedAt"=$10 WHERE "userId"=$11`,
 			existingUser.Pin,
 			int64(existingUser.PinExpiresAt),
 			int64(existingUser.ConnectionLimit),
@@ -124,19 +272,25 @@ func (api *Api) CentralWebhookUserGrantHandler(w http.ResponseWriter, r *http.Re
 			existingUser.Talkgroups,
 			existingUser.UserGroupId,
 			existingUser.Verified,
+			int64(existingUser.ModifiedAt),
 			existingUser.Id,
 		)
 		if dbErr != nil {
 			log.Printf("Central Management: WARNING - failed to persist updated user %s to DB: %v", req.Email, dbErr)
 		}
 
+		disconnected := api.Controller.enforceUserConnectionLimit(existingUser, centralLimitLoweredMessage)
+
 		log.Printf("Central Management: Updated user %s (PIN: %s, ConnectionLimit: %d)", req.Email, req.PIN, req.ConnectionLimit)
+		api.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("central management: access updated for %s from %s", req.Email, GetRemoteAddr(r)))
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"status":  "updated",
-			"user_id": existingUser.Id,
-			"message": "User access updated successfully",
+			"status":       "updated",
+			"user_id":      existingUser.Id,
+			"message":      "User access updated successfully",
+			"scopes":       scopes,
+			"disconnected": disconnected,
 		})
 		return
 	}
@@ -146,7 +300,7 @@ func (api *Api) CentralWebhookUserGrantHandler(w http.ResponseWriter, r *http.Re
 	user.FirstName = req.FirstName
 	user.LastName = req.LastName
 	user.Pin = req.PIN
-	user.PinExpiresAt = 0 // No expiration
+	user.PinExpiresAt = req.PinExpiresAt // 0 = no expiration
 	user.Verified = true
 	user.ConnectionLimit = req.ConnectionLimit
 	user.CreatedAt = time.Now().Format(time.RFC3339)
@@ -187,6 +341,7 @@ func (api *Api) CentralWebhookUserGrantHandler(w http.ResponseWriter, r *http.Re
 	}
 
 	log.Printf("Central Management: Created user %s (PIN: %s)", req.Email, req.PIN)
+	api.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("central management: access granted to %s from %s", req.Email, GetRemoteAddr(r)))
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -194,6 +349,7 @@ func (api *Api) CentralWebhookUserGrantHandler(w http.ResponseWriter, r *http.Re
 		"status":  "created",
 		"user_id": user.Id,
 		"message": "User access granted successfully",
+		"scopes":  scopes,
 	})
 }
 
@@ -205,10 +361,7 @@ func (api *Api) CentralWebhookUserRevokeHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	// Verify API key
-	apiKey := r.Header.Get("X-API-Key")
-	if apiKey != api.Controller.Options.CentralManagementAPIKey {
-		api.exitWithError(w, http.StatusUnauthorized, "Invalid API key")
+	if !api.authorizeCentralWebhook(w, r) {
 		return
 	}
 
@@ -235,7 +388,12 @@ func (api *Api) CentralWebhookUserRevokeHandler(w http.ResponseWriter, r *http.R
 	// Expire the PIN to revoke access
 	user.PinExpiresAt = uint64(time.Now().Unix())
 	api.Controller.Users.Update(user)
-	api.Controller.Users.Write(api.Controller.Database)
+	if _, err := api.Controller.Database.Sql.Exec(
+		`UPDATE "users" SET "pinExpiresAt"=$1, "modifiedAt"=$2 WHERE "userId"=$3`,
+		int64(user.PinExpiresAt), int64(user.ModifiedAt), user.Id,
+	); err != nil {
+		log.Printf("Central Management: WARNING - failed to persist revoke for %s to DB: %v", user.Email, err)
+	}
 
 	// Disconnect any active connections for this user
 	api.Controller.Clients.mutex.Lock()
@@ -253,7 +411,16 @@ func (api *Api) CentralWebhookUserRevokeHandler(w http.ResponseWriter, r *http.R
 	}
 	api.Controller.Clients.mutex.Unlock()
 
+	// Stop push notifications to the revoked user's devices. Best-effort: a token
+	// cleanup failure must not undo or block the revoke itself.
+	if removed, err := api.Controller.DeviceTokens.RemoveAllForUser(user.Id, api.Controller.Database, api.Controller.Clients); err != nil {
+		log.Printf("Central Management: failed to remove device tokens for revoked user %s: %v", user.Email, err)
+	} else if removed > 0 {
+		log.Printf("Central Management: removed %d device token(s) for revoked user %s", removed, user.Email)
+	}
+
 	log.Printf("Central Management: Revoked access for user %s", req.Email)
+	api.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("central management: access revoked for %s from %s", user.Email, GetRemoteAddr(r)))
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -263,13 +430,88 @@ func (api *Api) CentralWebhookUserRevokeHandler(w http.ResponseWriter, r *http.R
 	})
 }
 
+// CentralWebhookUserSetGroupHandler changes an existing user's group membership without a full re-grant,
+// leaving their PIN, systems and talkgroups untouched.
+func (api *Api) CentralWebhookUserSetGroupHandler(w http.ResponseWriter, r *http.Request) {
+	if !api.Controller.Options.CentralManagementEnabled {
+		api.exitWithError(w, http.StatusForbidden, "Central management not enabled")
+		return
+	}
+
+	if !api.authorizeCentralWebhook(w, r) {
+		return
+	}
+
+	var req CentralUserSetGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.exitWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.Email == "" {
+		api.exitWithError(w, http.StatusBadRequest, "Email is required")
+		return
+	}
+
+	user := api.Controller.Users.GetUserByEmail(req.Email)
+	if user == nil {
+		api.exitWithError(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	var groupId uint64
+	var group *UserGroup
+	if req.GroupID != nil && *req.GroupID != 0 {
+		group = api.Controller.UserGroups.Get(*req.GroupID)
+		if group == nil {
+			api.exitWithError(w, http.StatusBadRequest, "User group not found")
+			return
+		}
+		groupId = group.Id
+	}
+
+	oldGroupId := user.UserGroupId
+	if oldGroupId != groupId {
+		user.UserGroupId = groupId
+		// Group admin status does not carry over to another group.
+		user.IsGroupAdmin = false
+	}
+
+	api.Controller.Users.Update(user)
+
+	if _, err := api.Controller.Database.Sql.Exec(
+		`UPDATE "users" SET "userGroupId"=$1, "isGroupAdmin"=$2, "modifiedAt"=$3 WHERE "userId"=$4`,
+		user.UserGroupId, user.IsGroupAdmin, int64(user.ModifiedAt), user.Id,
+	); err != nil {
+		api.exitWithError(w, http.StatusInternalServerError, "Failed to save user group")
+		return
+	}
+
+	log.Printf("Central Management: Moved user %s from group %d to group %d", user.Email, oldGroupId, groupId)
+	api.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("central management: group for %s set to %d (was %d) from %s", user.Email, groupId, oldGroupId, GetRemoteAddr(r)))
+
+	var respGroupId *uint64
+	groupName := ""
+	if group != nil {
+		respGroupId = &groupId
+		groupName = group.Name
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":     "updated",
+		"user_id":    user.Id,
+		"group_id":   respGroupId,
+		"group_name": groupName,
+	})
+}
+
 // CentralWebhookTestConnectionHandler tests the connection to central management (INCOMING test from central system)
 func (api *Api) CentralWebhookTestConnectionHandler(w http.ResponseWriter, r *http.Request) {
 	// Verify API key
 	apiKey := r.Header.Get("X-API-Key")
 	expectedKey := r.URL.Query().Get("api_key")
 
-	if apiKey != expectedKey && expectedKey != "" {
+	if expectedKey != "" && !constantTimeEqual(apiKey, expectedKey) {
 		api.exitWithError(w, http.StatusUnauthorized, "Invalid API key")
 		return
 	}
@@ -294,18 +536,29 @@ type CentralUserUpdateEntry struct {
 	ConnectionLimit uint   `json:"connectionLimit"` // 0 = unlimited
 }
 
+// centralLimitLoweredMessage is sent to sessions dropped because Central
+// Management lowered the user's connection limit.
+const centralLimitLoweredMessage = "Connection limit lowered by central management"
+
+// CentralBatchUpdateChange reports a connection limit that changes (or would
+// change, on a dry run) in a batch update.
+type CentralBatchUpdateChange struct {
+	Email string `json:"email"`
+	From  uint   `json:"from"`
+	To    uint   `json:"to"`
+}
+
 // CentralWebhookUsersBatchUpdateHandler updates connection limits for multiple users in one call.
 // Central Management uses this when a billing plan's connection limit changes, so it only needs
 // to make one HTTP request per TLR server regardless of how many users are affected.
+// With ?dry_run=true nothing is written; the response reports what would change.
 func (api *Api) CentralWebhookUsersBatchUpdateHandler(w http.ResponseWriter, r *http.Request) {
 	if !api.Controller.Options.CentralManagementEnabled {
 		api.exitWithError(w, http.StatusForbidden, "Central management not enabled")
 		return
 	}
 
-	apiKey := r.Header.Get("X-API-Key")
-	if apiKey != api.Controller.Options.CentralManagementAPIKey {
-		api.exitWithError(w, http.StatusUnauthorized, "Invalid API key")
+	if !api.authorizeCentralWebhook(w, r) {
 		return
 	}
 
@@ -315,41 +568,79 @@ func (api *Api) CentralWebhookUsersBatchUpdateHandler(w http.ResponseWriter, r *
 		return
 	}
 
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
 	updated := 0
+	disconnected := 0
+	updatedEmails := []string{}
+	changes := []CentralBatchUpdateChange{}
+	unchanged := []string{}
+	notFound := []string{}
+	failed := []string{}
 	for _, entry := range req.Updates {
 		user := api.Controller.Users.GetUserByEmail(entry.Email)
 		if user == nil {
+			notFound = append(notFound, entry.Email)
 			continue
 		}
+		if user.ConnectionLimit != entry.ConnectionLimit {
+			changes = append(changes, CentralBatchUpdateChange{Email: entry.Email, From: user.ConnectionLimit, To: entry.ConnectionLimit})
+		} else {
+			unchanged = append(unchanged, entry.Email)
+		}
+		if dryRun {
+			updated++
+			continue
+		}
+
 		user.ConnectionLimit = entry.ConnectionLimit
 		api.Controller.Users.Update(user)
 
 		_, dbErr := api.Controller.Database.Sql.Exec(
-			`UPDATE "users" SET "connectionLimit"=$1 WHERE "userId"=$2`,
+			`UPDATE "users" SET "connectionLimit"=$1, "modifiedAt"=$2 WHERE "userId"=$3`,
 			int64(entry.ConnectionLimit),
+			int64(user.ModifiedAt),
 			user.Id,
 		)
 		if dbErr != nil {
 			log.Printf("Central Management: batch update failed for %s: %v", entry.Email, dbErr)
+			failed = append(failed, entry.Email)
 		} else {
 			updated++
+			updatedEmails = append(updatedEmails, fmt.Sprintf("%s=%d", entry.Email, entry.ConnectionLimit))
 		}
+		disconnected += api.Controller.enforceUserConnectionLimit(user, centralLimitLoweredMessage)
 	}
 
-	log.Printf("Central Management: Batch updated connectionLimit to %d for %d/%d users",
-		func() uint {
-			if len(req.Updates) > 0 {
-				return req.Updates[0].ConnectionLimit
-			}
-			return 0
-		}(),
-		updated, len(req.Updates))
+	if dryRun {
+		log.Printf("Central Management: Batch update dry run: %d of %d users would change, %d not found",
+			len(changes), len(req.Updates), len(notFound))
+	} else {
+		if updated > 0 {
+			api.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("central management: batch connection limit update from %s: %s", GetRemoteAddr(r), strings.Join(updatedEmails, ", ")))
+		}
+
+		log.Printf("Central Management: Batch updated connectionLimit to %d for %d/%d users",
+			func() uint {
+				if len(req.Updates) > 0 {
+					return req.Updates[0].ConnectionLimit
+				}
+				return 0
+			}(),
+			updated, len(req.Updates))
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"status":  "ok",
-		"updated": updated,
-		"total":   len(req.Updates),
+		"status":       "ok",
+		"dry_run":      dryRun,
+		"updated":      updated,
+		"total":        len(req.Updates),
+		"changes":      changes,
+		"unchanged":    unchanged,
+		"not_found":    notFound,
+		"failed":       failed,
+		"disconnected": disconnected,
 	})
 }
 
@@ -361,9 +652,7 @@ func (api *Api) CentralWebhookSystemsTalkgroupsGroupsHandler(w http.ResponseWrit
 		return
 	}
 
-	apiKey := r.Header.Get("X-API-Key")
-	if apiKey != api.Controller.Options.CentralManagementAPIKey {
-		api.exitWithError(w, http.StatusUnauthorized, "Invalid API key")
+	if !api.authorizeCentralWebhook(w, r) {
 		return
 	}
 
@@ -382,10 +671,15 @@ func (api *Api) CentralWebhookSystemsTalkgroupsGroupsHandler(w http.ResponseWrit
 				}
 			}
 			talkgroups = append(talkgroups, map[string]interface{}{
-				"id":    tg.TalkgroupRef,
-				"label": tg.Label,
-				"name":  tg.Name,
-				"tag":   tagLabel,
+				"id":        tg.TalkgroupRef,
+				"label":     tg.Label,
+				"name":      tg.Name,
+				"tag":       tagLabel,
+				"type":      tg.Kind,
+				"frequency": tg.Frequency,
+				"encrypted": tg.Encrypted,
+				"priority":  tg.ReconnectionPriority,
+				"alerting":  tg.AlertingTalkgroup,
 			})
 		}
 
@@ -415,21 +709,93 @@ func (api *Api) CentralWebhookSystemsTalkgroupsGroupsHandler(w http.ResponseWrit
 	})
 }
 
-// CentralWebhookUsersListHandler returns current users on this TLR server to central management.
+const (
+	centralUsersListDefaultLimit = 500
+	centralUsersListMaxLimit     = 5000
+)
+
+// userModifiedAt returns the user's last modification time, falling back to
+// createdAt for rows written before the modifiedAt column existed.
+func userModifiedAt(u *User) uint64 {
+	if u.ModifiedAt > 0 {
+		return u.ModifiedAt
+	}
+	createdAt, _ := strconv.ParseUint(u.CreatedAt, 10, 64)
+	return createdAt
+}
+
+// CentralWebhookUsersListHandler returns a page of users on this TLR server to central management.
+// Query params: limit (default 500, max 5000, or "all"), offset, modified_since (unix seconds),
+// include_hashes=true and include_pins=true (one-time CM import only; omitted by default).
 func (api *Api) CentralWebhookUsersListHandler(w http.ResponseWriter, r *http.Request) {
 	if !api.Controller.Options.CentralManagementEnabled {
 		api.exitWithError(w, http.StatusForbidden, "Central management not enabled")
 		return
 	}
 
-	apiKey := r.Header.Get("X-API-Key")
-	if apiKey == "" || apiKey != api.Controller.Options.CentralManagementAPIKey {
-		api.exitWithError(w, http.StatusUnauthorized, "Invalid API key")
+	if !api.authorizeCentralWebhook(w, r) {
 		return
 	}
 
+	query := r.URL.Query()
+	limit := centralUsersListDefaultLimit
+	all := false
+	if v := query.Get("limit"); v == "all" {
+		all = true
+	} else if v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			api.exitWithError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = min(n, centralUsersListMaxLimit)
+	}
+	offset := 0
+	if v := query.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			api.exitWithError(w, http.StatusBadRequest, "Invalid offset")
+			return
+		}
+		offset = n
+	}
+	var modifiedSince uint64
+	if v := query.Get("modified_since"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			api.exitWithError(w, http.StatusBadRequest, "Invalid modified_since")
+			return
+		}
+		modifiedSince = n
+	}
+	includeHashes := query.Get("include_hashes") == "true"
+	includePins := query.Get("include_pins") == "true"
+	if includeHashes {
+		api.Controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("central management: users list with password hashes requested from %s", GetRemoteAddr(r)))
+	}
+
 	now := uint64(time.Now().Unix())
 	users := api.Controller.Users.GetAllUsers()
+	if modifiedSince > 0 {
+		filtered := users[:0]
+		for _, u := range users {
+			if userModifiedAt(u) > modifiedSince {
+				filtered = append(filtered, u)
+			}
+		}
+		users = filtered
+	}
+	// Stable ordering so offsets stay meaningful between requests.
+	sort.Slice(users, func(i, j int) bool { return users[i].Id < users[j].Id })
+
+	total := len(users)
+	start := min(offset, total)
+	end := min(start+limit, total)
+	if all {
+		end = total
+		limit = end - start
+	}
+	page := users[start:end]
 
 	type ServerUser struct {
 		ID           uint64  `json:"id"`
@@ -443,37 +809,51 @@ func (api *Api) CentralWebhookUsersListHandler(w http.ResponseWriter, r *http.Re
 		PIN          string  `json:"pin,omitempty"`
 		PINActive    bool    `json:"pin_active"`
 		PasswordHash string  `json:"password_hash,omitempty"` // SHA-256 hex — for Central Management import only
+		ModifiedAt   uint64  `json:"modified_at"`
 	}
 
-	respUsers := make([]ServerUser, 0, len(users))
-	for _, u := range users {
+	// Users are encoded one at a time, so even a whole roster (limit=all) is
+	// never held as a second slice or as one JSON buffer.
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"status":"ok","count":%d,"total":%d,"offset":%d,"limit":%d,"has_more":%t,"users":[`, len(page), total, start, limit, end < total)
+
+	enc := json.NewEncoder(w)
+	for i, u := range page {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return
+			}
+		}
+
 		pinActive := u.Pin != "" && (u.PinExpiresAt == 0 || u.PinExpiresAt > now)
 		var groupID *uint64
 		if u.UserGroupId > 0 {
 			gid := u.UserGroupId
 			groupID = &gid
 		}
-		respUsers = append(respUsers, ServerUser{
-			ID:           u.Id,
-			Email:        u.Email,
-			FirstName:    u.FirstName,
-			LastName:     u.LastName,
-			Verified:     u.Verified,
-			Systems:      u.Systems,
-			Talkgroups:   u.Talkgroups,
-			UserGroupID:  groupID,
-			PIN:          u.Pin,
-			PINActive:    pinActive,
-			PasswordHash: u.Password, // SHA-256 hex stored on TLR
-		})
+		su := ServerUser{
+			ID:          u.Id,
+			Email:       u.Email,
+			FirstName:   u.FirstName,
+			LastName:    u.LastName,
+			Verified:    u.Verified,
+			Systems:     u.Systems,
+			Talkgroups:  u.Talkgroups,
+			UserGroupID: groupID,
+			PINActive:   pinActive,
+			ModifiedAt:  userModifiedAt(u),
+		}
+		if includePins {
+			su.PIN = u.Pin
+		}
+		if includeHashes {
+			su.PasswordHash = u.Password // SHA-256 hex stored on TLR
+		}
+		if err := enc.Encode(su); err != nil {
+			return
+		}
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"status": "ok",
-		"users":  respUsers,
-		"count":  len(respUsers),
-	})
+	io.WriteString(w, "]}\n")
 }
 
 // parseJSONStringOrNumberID decodes a JSON value that may be a string or number (e.g. Hydra rr_system_id).
@@ -501,7 +881,9 @@ func parseJSONStringOrNumberID(raw json.RawMessage) string {
 // canonical identifier stored as centralManagementServerID and sent on TLR register/heartbeat.
 // server_id is still accepted when rr_system_id is omitted (backward compatibility).
 type CentralManagementPairRequest struct {
-	AdminPassword        string          `json:"admin_password"`
+	Nonce                string          `json:"nonce"`                        // from GET /api/central-management/pair-challenge
+	NonceHMAC            string          `json:"nonce_hmac"`                   // hex HMAC-SHA256 of nonce keyed by the local pairing secret
+	AdminPassword        string          `json:"admin_password"`               // deprecated: password pairing, see Config.CMPasswordPairing
 	NewAdminPassword     string          `json:"new_admin_password,omitempty"` // if set, scanner admin password is changed to this after a successful pair
 	CentralManagementURL string          `json:"central_management_url"`
 	APIKey               string          `json:"api_key"`
@@ -509,14 +891,17 @@ type CentralManagementPairRequest struct {
 	ServerID             string          `json:"server_id"`
 	RrSystemID           json.RawMessage `json:"rr_system_id,omitempty"`
 	ServerURL            string          `json:"server_url"` // the TLR server's own public URL, so it can register back correctly
+	WebhookSecret        string          `json:"webhook_secret,omitempty"`
 }
 
 // PairWithCentralManagementHandler is called by the Central Management backend to authenticate
 // and push the API key + CM URL directly to this server, enabling centralized management mode
 // without any manual copy-paste on the TLR server side.
 //
-// This endpoint is intentionally NOT localhost-restricted so that the CM backend can reach it,
-// but it is protected by admin password verification (bcrypt).
+// This endpoint is intentionally NOT localhost-restricted so that the CM backend can reach it.
+// CM proves it was given the pairing secret the local admin entered by returning an HMAC of a
+// nonce from PairChallengeHandler. The legacy admin_password path (bcrypt) is still accepted
+// while cm_password_pairing is enabled, but sends the admin password over the wire.
 func (api *Api) PairWithCentralManagementHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -529,20 +914,55 @@ func (api *Api) PairWithCentralManagementHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
-	if req.AdminPassword == "" || req.CentralManagementURL == "" || req.APIKey == "" {
+	if req.CentralManagementURL == "" || req.APIKey == "" {
 		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "admin_password, central_management_url and api_key are required"})
+		json.NewEncoder(w).Encode(map[string]string{"error": "central_management_url and api_key are required"})
 		return
 	}
 
-	// Verify the admin password via bcrypt — same check as the normal admin login.
-	if err := bcrypt.CompareHashAndPassword(
-		[]byte(api.Controller.Options.adminPassword),
-		[]byte(req.AdminPassword),
-	); err != nil {
-		log.Printf("Central Management pairing: invalid admin password from %s", r.RemoteAddr)
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(map[string]string{"error": "invalid admin password"})
+	// currentPassword is handed to ChangePassword below; nil skips the old-password check,
+	// which the nonce challenge has already replaced.
+	var currentPassword any
+
+	switch {
+	case req.Nonce != "" || req.NonceHMAC != "":
+		cms := api.Controller.CentralManagement
+		if cms == nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "central management service not initialized"})
+			return
+		}
+		if err := cms.VerifyPairingResponse(req.Nonce, req.NonceHMAC); err != nil {
+			log.Printf("Central Management pairing: challenge failed from %s: %v", r.RemoteAddr, err)
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "pairing challenge failed"})
+			return
+		}
+
+	case req.AdminPassword != "":
+		if !api.Controller.Config.CMPasswordPairing {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{"error": "password pairing is disabled, use /api/central-management/pair-challenge"})
+			return
+		}
+
+		// Verify the admin password via bcrypt — same check as the normal admin login.
+		if err := bcrypt.CompareHashAndPassword(
+			[]byte(api.Controller.Options.adminPassword),
+			[]byte(req.AdminPassword),
+		); err != nil {
+			log.Printf("Central Management pairing: invalid admin password from %s", r.RemoteAddr)
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid admin password"})
+			return
+		}
+		currentPassword = req.AdminPassword
+
+		api.Controller.Logs.LogEvent(LogLevelWarn, "central management paired using the deprecated admin_password flow; this will be removed in the next release")
+
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "nonce and nonce_hmac are required"})
 		return
 	}
 
@@ -562,8 +982,20 @@ func (api *Api) PairWithCentralManagementHandler(w http.ResponseWriter, r *http.
 	// which made every brief CM outage cascade into a fleet-wide manual repair).
 	api.Controller.Options.mutex.Lock()
 	api.Controller.Options.CentralManagementEnabled = true
-	api.Controller.Options.CentralManagementURL = req.CentralManagementURL
+	// Keep a configured failover list when CM pairs through one of its URLs.
+	keepList := false
+	for _, u := range parseCentralManagementURLs(api.Controller.Options.CentralManagementURL) {
+		if u == strings.TrimRight(strings.TrimSpace(req.CentralManagementURL), "/") {
+			keepList = true
+		}
+	}
+	if !keepList {
+		api.Controller.Options.CentralManagementURL = req.CentralManagementURL
+	}
 	api.Controller.Options.CentralManagementAPIKey = req.APIKey
+	if req.WebhookSecret != "" {
+		api.Controller.Options.CentralManagementWebhookSecret = req.WebhookSecret
+	}
 	if req.ServerName != "" {
 		api.Controller.Options.CentralManagementServerName = req.ServerName
 		// Match scanner UI / emails / client branding to the name used in Central Management.
@@ -598,7 +1030,7 @@ func (api *Api) PairWithCentralManagementHandler(w http.ResponseWriter, r *http.
 			json.NewEncoder(w).Encode(map[string]string{"error": "cannot apply new admin password"})
 			return
 		}
-		if err := api.Controller.Admin.ChangePassword(req.AdminPassword, newPass); err != nil {
+		if err := api.Controller.Admin.ChangePassword(currentPassword, newPass); err != nil {
 			log.Printf("Central Management pairing: failed to set new admin password: %v", err)
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(map[string]string{"error": "paired but failed to set new admin password"})
@@ -623,6 +1055,111 @@ func (api *Api) PairWithCentralManagementHandler(w http.ResponseWriter, r *http.
 	})
 }
 
+// PairChallengeHandler issues a short-lived signed nonce for pairing. CM must answer with
+// HMAC-SHA256(pairing secret, nonce) in the pair request; each nonce is single-use.
+// GET /api/central-management/pair-challenge
+func (api *Api) PairChallengeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		api.exitWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	cms := api.Controller.CentralManagement
+	if cms == nil {
+		api.exitWithError(w, http.StatusInternalServerError, "Central management service not initialized")
+		return
+	}
+
+	nonce, expiry, err := cms.NewPairingNonce()
+	if err != nil {
+		api.exitWithError(w, http.StatusInternalServerError, "Failed to generate nonce")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"nonce":      nonce,
+		"expires_at": expiry.Unix(),
+		"algorithm":  "HMAC-SHA256",
+	})
+}
+
+// CentralManagementStatusHandler tells the local admin whether this server is
+// connected to Central Management: registration, last heartbeat and error,
+// and any pending removal code. URLs are masked.
+// GET /api/central-management/status
+func (api *Api) CentralManagementStatusHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		api.exitWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	token := r.Header.Get("Authorization")
+	if !api.Controller.Admin.ValidateToken(token) {
+		api.exitWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var status map[string]any
+	if cms := api.Controller.CentralManagement; cms != nil {
+		status = cms.Status()
+	} else {
+		// Unpaired: the service is torn down along with the settings.
+		status = map[string]any{"enabled": false, "paired": false, "registered": false}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// PairingSecretHandler lets the local admin set the secret that Central Management must prove
+// knowledge of when pairing. An empty secret generates one. The secret expires after 15 minutes
+// or after one successful pair.
+// POST /api/central-management/pairing-secret
+func (api *Api) PairingSecretHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		api.exitWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	// Require a valid admin session token
+	token := r.Header.Get("Authorization")
+	if !api.Controller.Admin.ValidateToken(token) {
+		api.exitWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req struct {
+		Secret string `json:"secret"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			api.exitWithError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	cms := api.Controller.CentralManagement
+	if cms == nil {
+		api.exitWithError(w, http.StatusInternalServerError, "Central management service not initialized")
+		return
+	}
+
+	secret, expiry, err := cms.SetPairingSecret(req.Secret)
+	if err != nil {
+		api.exitWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	log.Printf("Central Management: pairing secret set (expires in 15 min)")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"secret":     secret,
+		"expires_at": expiry.Unix(),
+	})
+}
+
 // TestCentralConnectionHandler tests the connection FROM this server TO the central management system
 func (admin *Admin) TestCentralConnectionHandler(w http.ResponseWriter, r *http.Request) {
 	// Read test parameters from request body (settings may not be saved yet)
@@ -700,43 +1237,34 @@ func (api *Api) CMAdminTokenHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Verify the API key
-	apiKey := r.Header.Get("X-API-Key")
-	if apiKey == "" || apiKey != api.Controller.Options.CentralManagementAPIKey {
-		api.exitWithError(w, http.StatusUnauthorized, "Invalid or missing API key")
+	if !api.authorizeCentralWebhook(w, r) {
 		return
 	}
 
-	// Generate a UUID claim ID
-	id, err := uuid.NewRandom()
-	if err != nil {
-		api.exitWithError(w, http.StatusInternalServerError, "Failed to generate token")
-		return
-	}
-
-	// Sign a JWT the same way LoginHandler does so it is accepted by ValidateToken
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{ID: id.String()})
-	sToken, err := token.SignedString([]byte(api.Controller.Options.secret))
+	// Sign a JWT the same way LoginHandler does so it is accepted by
+	// ValidateToken, but with an expiry so it can't outlive the CM session.
+	// This token starts a new session; the webapp renews it through
+	// /api/admin/token/refresh until the session's maximum lifetime.
+	admin := api.Controller.Admin
+	now := time.Now()
+	sToken, expiresAt, err := admin.issueCMToken(now, now)
 	if err != nil {
 		api.exitWithError(w, http.StatusInternalServerError, "Failed to sign token")
 		return
 	}
 
 	// Register the token in the Admin token list so it will be accepted
-	admin := api.Controller.Admin
 	admin.mutex.Lock()
-	if len(admin.Tokens) < 5 {
-		admin.Tokens = append(admin.Tokens, sToken)
-	} else {
-		admin.Tokens = append(admin.Tokens[1:], sToken)
-	}
+	admin.addCMToken(sToken, api.Controller.Config.CMAdminTokenLimit, now)
 	admin.mutex.Unlock()
 
-	log.Printf("Central Management: issued temporary admin token for CM access")
+	log.Printf("Central Management: issued temporary admin token for CM access (expires in %s)", expiresAt.Sub(now).Round(time.Second))
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"token": sToken,
+	json.NewEncoder(w).Encode(map[string]any{
+		"token":            sToken,
+		"expiresAt":        expiresAt.Unix(),
+		"sessionExpiresAt": cmAdminSessionEnd(api.Controller.Config, now).Unix(),
 	})
 }
 
@@ -756,10 +1284,7 @@ func (api *Api) SetRemovalCodeHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Authenticate via the CM API key
-	apiKey := r.Header.Get("X-API-Key")
-	if apiKey == "" || apiKey != api.Controller.Options.CentralManagementAPIKey {
-		api.exitWithError(w, http.StatusUnauthorized, "Invalid or missing API key")
+	if !api.authorizeCentralWebhook(w, r) {
 		return
 	}
 
@@ -836,7 +1361,7 @@ func (api *Api) LeaveCentralManagementHandler(w http.ResponseWriter, r *http.Req
 		api.exitWithError(w, http.StatusBadRequest, "Removal code has expired. Please generate a new one from Central Management.")
 		return
 	}
-	if enteredCode != validCode {
+	if !constantTimeEqual(enteredCode, validCode) {
 		api.exitWithError(w, http.StatusUnauthorized, "Invalid removal code.")
 		return
 	}
@@ -854,18 +1379,22 @@ func (api *Api) LeaveCentralManagementHandler(w http.ResponseWriter, r *http.Req
 
 	// Notify the CM system to remove this server from its list.
 	// This is best-effort — we proceed with unlinking even if CM is unreachable.
-	if cmURL != "" && cmAPIKey != "" {
-		selfRemoveURL := strings.TrimRight(cmURL, "/") + "/api/tlr/server"
+	// Every configured CM (primary and standbys) is told.
+	for _, base := range parseCentralManagementURLs(cmURL) {
+		if cmAPIKey == "" {
+			break
+		}
+		selfRemoveURL := base + "/api/tlr/server"
 		req, err := http.NewRequest(http.MethodDelete, selfRemoveURL, nil)
 		if err == nil {
 			req.Header.Set("X-API-Key", cmAPIKey)
 			client := &http.Client{Timeout: 10 * time.Second}
 			resp, err := client.Do(req)
 			if err != nil {
-				log.Printf("Central Management: could not notify CM to remove server (continuing anyway): %v", err)
+				log.Printf("Central Management: could not notify %s to remove server (continuing anyway): %v", base, err)
 			} else {
 				resp.Body.Close()
-				log.Printf("Central Management: notified CM to remove server (HTTP %d)", resp.StatusCode)
+				log.Printf("Central Management: notified %s to remove server (HTTP %d)", base, resp.StatusCode)
 			}
 		}
 	}
@@ -879,6 +1408,8 @@ func (api *Api) LeaveCentralManagementHandler(w http.ResponseWriter, r *http.Req
 	api.Controller.Options.CentralManagementEnabled = false
 	api.Controller.Options.CentralManagementURL = ""
 	api.Controller.Options.CentralManagementAPIKey = ""
+	api.Controller.Options.CentralManagementWebhookSecret = ""
+	api.Controller.Options.CentralManagementRequireSignature = false
 	api.Controller.Options.CentralManagementServerName = ""
 	api.Controller.Options.CentralManagementServerID = ""
 	api.Controller.Options.mutex.Unlock()
@@ -907,9 +1438,7 @@ func (api *Api) CentralWebhookSetRelayAPIKeyHandler(w http.ResponseWriter, r *ht
 		return
 	}
 
-	apiKey := r.Header.Get("X-API-Key")
-	if apiKey == "" || apiKey != api.Controller.Options.CentralManagementAPIKey {
-		api.exitWithError(w, http.StatusUnauthorized, "Invalid API key")
+	if !api.authorizeCentralWebhook(w, r) {
 		return
 	}
 
@@ -946,6 +1475,51 @@ func (api *Api) CentralWebhookSetRelayAPIKeyHandler(w http.ResponseWriter, r *ht
 	})
 }
 
+// CentralWebhookSetWebhookSecretHandler stores the secret Central Management
+// uses to sign webhooks, so servers paired before signing existed can switch
+// over without re-pairing. Sending it signed with the old secret rotates it.
+// POST /api/webhook/central-set-webhook-secret
+func (api *Api) CentralWebhookSetWebhookSecretHandler(w http.ResponseWriter, r *http.Request) {
+	if !api.Controller.Options.CentralManagementEnabled {
+		api.exitWithError(w, http.StatusForbidden, "Central management not enabled")
+		return
+	}
+
+	if !api.authorizeCentralWebhook(w, r) {
+		return
+	}
+
+	var req struct {
+		WebhookSecret string `json:"webhook_secret"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.exitWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if len(req.WebhookSecret) < 32 {
+		api.exitWithError(w, http.StatusBadRequest, "webhook_secret must be at least 32 characters")
+		return
+	}
+
+	api.Controller.Options.mutex.Lock()
+	api.Controller.Options.CentralManagementWebhookSecret = req.WebhookSecret
+	api.Controller.Options.mutex.Unlock()
+
+	if err := api.Controller.Options.Write(api.Controller.Database); err != nil {
+		log.Printf("CentralWebhookSetWebhookSecret: failed to persist webhook secret: %v", err)
+		api.exitWithError(w, http.StatusInternalServerError, "failed to save webhook secret")
+		return
+	}
+
+	log.Printf("CentralWebhookSetWebhookSecret: webhook signing secret updated via Central Management")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":  "ok",
+		"message": "Webhook secret updated successfully",
+	})
+}
+
 // CentralWebhookSetHydraConfigHandler receives Hydra API key and enabled status from
 // Central Management and saves it to this server's options so Hydra transcription
 // retrieval can be used.
@@ -956,9 +1530,7 @@ func (api *Api) CentralWebhookSetHydraConfigHandler(w http.ResponseWriter, r *ht
 		return
 	}
 
-	apiKey := r.Header.Get("X-API-Key")
-	if apiKey == "" || apiKey != api.Controller.Options.CentralManagementAPIKey {
-		api.exitWithError(w, http.StatusUnauthorized, "Invalid API key")
+	if !api.authorizeCentralWebhook(w, r) {
 		return
 	}
 
@@ -0,0 +1,506 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/websocket"
+)
+
+func TestConstantTimeEqual(t *testing.T) {
+	const key = "cm-3f9a1c0b7e24d5a8"
+
+	if !constantTimeEqual(key, key) {
+		t.Fatal("exact match must be accepted")
+	}
+
+	for _, given := range []string{
+		"",
+		"cm-3f9a1c0b7e24d5a9",
+		"CM-3F9A1C0B7E24D5A8",
+		key[:len(key)-1],
+		key + "0",
+		" " + key,
+	} {
+		if constantTimeEqual(given, key) {
+			t.Fatalf("%q must not match", given)
+		}
+	}
+
+	if constantTimeEqual("", "") {
+		t.Fatal("an unset expected value must never match")
+	}
+}
+
+func TestCentralWebhookUsersListOmitsSecretsByDefault(t *testing.T) {
+	const key = "cm-3f9a1c0b7e24d5a8"
+
+	controller := &Controller{Options: NewOptions(), Users: NewUsers()}
+	controller.Options.CentralManagementEnabled = true
+	controller.Options.CentralManagementAPIKey = key
+	controller.Users.Add(&User{Id: 1, Email: "alice@example.com", Password: "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8", Pin: "123456"})
+	api := NewApi(controller)
+
+	list := func(query string) map[string]any {
+		req := httptest.NewRequest(http.MethodGet, "/api/webhook/central-users"+query, nil)
+		req.Header.Set("X-API-Key", key)
+		rec := httptest.NewRecorder()
+		api.CentralWebhookUsersListHandler(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+		}
+		var body struct {
+			Users []map[string]any `json:"users"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if len(body.Users) != 1 {
+			t.Fatalf("expected 1 user, got %d", len(body.Users))
+		}
+		return body.Users[0]
+	}
+
+	user := list("")
+	if _, ok := user["password_hash"]; ok {
+		t.Fatal("password_hash must be omitted by default")
+	}
+	if _, ok := user["pin"]; ok {
+		t.Fatal("pin must be omitted by default")
+	}
+	if user["pin_active"] != true {
+		t.Fatal("pin_active should still be reported")
+	}
+
+	user = list("?include_pins=true")
+	if user["pin"] != "123456" {
+		t.Fatalf("expected pin with include_pins, got %v", user["pin"])
+	}
+	if _, ok := user["password_hash"]; ok {
+		t.Fatal("include_pins must not expose password_hash")
+	}
+}
+
+func TestCentralWebhookUsersListPages(t *testing.T) {
+	const key = "cm-3f9a1c0b7e24d5a8"
+
+	controller := &Controller{Options: NewOptions(), Users: NewUsers()}
+	controller.Options.CentralManagementEnabled = true
+	controller.Options.CentralManagementAPIKey = key
+	for id := uint64(1); id <= 5; id++ {
+		controller.Users.Add(&User{Id: id, Email: "user" + strconv.FormatUint(id, 10) + "@example.com"})
+	}
+	api := NewApi(controller)
+
+	type page struct {
+		Count   int  `json:"count"`
+		Total   int  `json:"total"`
+		Offset  int  `json:"offset"`
+		Limit   int  `json:"limit"`
+		HasMore bool `json:"has_more"`
+		Users   []struct {
+			ID uint64 `json:"id"`
+		} `json:"users"`
+	}
+	list := func(query string) page {
+		req := httptest.NewRequest(http.MethodGet, "/api/webhook/central-users"+query, nil)
+		req.Header.Set("X-API-Key", key)
+		rec := httptest.NewRecorder()
+		api.CentralWebhookUsersListHandler(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", query, rec.Code, rec.Body.String())
+		}
+		var body page
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: %v: %s", query, err, rec.Body.String())
+		}
+		if body.Count != len(body.Users) {
+			t.Fatalf("%s: count %d but %d users", query, body.Count, len(body.Users))
+		}
+		return body
+	}
+
+	p := list("?limit=2&offset=2")
+	if p.Total != 5 || p.HasMore != true || len(p.Users) != 2 || p.Users[0].ID != 3 || p.Users[1].ID != 4 {
+		t.Fatalf("second page: %+v", p)
+	}
+
+	p = list("?limit=all&offset=1")
+	if p.Total != 5 || p.HasMore || p.Limit != 4 || len(p.Users) != 4 || p.Users[0].ID != 2 || p.Users[3].ID != 5 {
+		t.Fatalf("limit=all: %+v", p)
+	}
+
+	p = list("?offset=10")
+	if p.Users == nil || len(p.Users) != 0 || p.HasMore {
+		t.Fatalf("past the end: %+v", p)
+	}
+}
+
+func TestCMAdminTokenLimitAndExpiry(t *testing.T) {
+	const key = "cm-3f9a1c0b7e24d5a8"
+
+	controller := &Controller{Options: NewOptions(), Config: &Config{CMAdminTokenTTL: 60, CMAdminTokenLimit: 2}}
+	controller.Options.CentralManagementEnabled = true
+	controller.Options.CentralManagementAPIKey = key
+	controller.Options.secret = "test-secret"
+	controller.Admin = &Admin{Controller: controller, Tokens: []string{"login-token"}}
+	api := NewApi(controller)
+
+	issue := func() string {
+		req := httptest.NewRequest(http.MethodPost, "/api/central-management/admin-token", nil)
+		req.Header.Set("X-API-Key", key)
+		rec := httptest.NewRecorder()
+		api.CMAdminTokenHandler(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+		}
+		var body struct {
+			Token     string `json:"token"`
+			ExpiresAt int64  `json:"expiresAt"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if body.ExpiresAt <= time.Now().Unix() {
+			t.Fatalf("expiresAt %d is not in the future", body.ExpiresAt)
+		}
+		return body.Token
+	}
+
+	first, second, third := issue(), issue(), issue()
+	if controller.Admin.ValidateToken(first) {
+		t.Fatal("oldest CM token should be revoked once the limit is exceeded")
+	}
+	if !controller.Admin.ValidateToken(second) || !controller.Admin.ValidateToken(third) {
+		t.Fatal("newest CM tokens should remain valid")
+	}
+	if len(controller.Admin.Tokens) != 3 || controller.Admin.Tokens[0] != "login-token" {
+		t.Fatalf("login tokens must not be evicted by CM tokens: %v", controller.Admin.Tokens)
+	}
+
+	past := time.Now().Add(-time.Minute)
+	expired, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{ID: "old", ExpiresAt: jwt.NewNumericDate(past)}).
+		SignedString([]byte(controller.Options.secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	controller.Admin.Tokens = append(controller.Admin.Tokens, expired)
+	controller.Admin.cmTokens = append(controller.Admin.cmTokens, expired)
+	if controller.Admin.ValidateToken(expired) {
+		t.Fatal("expired CM token must be rejected")
+	}
+
+	fourth := issue()
+	for _, tok := range controller.Admin.Tokens {
+		if tok == expired {
+			t.Fatal("expired token should have been pruned")
+		}
+	}
+	if !controller.Admin.ValidateToken(fourth) {
+		t.Fatal("freshly issued token should be valid")
+	}
+}
+
+func TestAdminTokenRefresh(t *testing.T) {
+	controller := &Controller{Options: NewOptions(), Logs: NewLogs(), Config: &Config{CMAdminTokenTTL: 60, CMAdminTokenLimit: 5, CMAdminSessionMax: 3600}}
+	controller.Options.secret = "test-secret"
+	controller.Admin = &Admin{Controller: controller, Tokens: []string{}}
+	admin := controller.Admin
+
+	type refreshResponse struct {
+		Token            string `json:"token"`
+		ExpiresAt        int64  `json:"expiresAt"`
+		SessionExpiresAt int64  `json:"sessionExpiresAt"`
+	}
+	refresh := func(token string) (int, refreshResponse) {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/token/refresh", nil)
+		req.Header.Set("Authorization", token)
+		rec := httptest.NewRecorder()
+		admin.TokenRefreshHandler(rec, req)
+		var body refreshResponse
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rec.Code, body
+	}
+	register := func(sessionStart time.Time) string {
+		sToken, _, err := admin.issueCMToken(sessionStart, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		admin.addCMToken(sToken, controller.Config.CMAdminTokenLimit, time.Now())
+		return sToken
+	}
+
+	start := time.Now().Add(-10 * time.Minute)
+	old := register(start)
+	code, body := refresh(old)
+	if code != http.StatusOK {
+		t.Fatalf("refresh status %d", code)
+	}
+	if body.Token == old || !admin.ValidateToken(body.Token) {
+		t.Fatal("refresh should issue a new valid token")
+	}
+	if admin.ValidateToken(old) {
+		t.Fatal("refreshed token should be revoked")
+	}
+	if body.SessionExpiresAt != start.Add(time.Hour).Unix() {
+		t.Fatalf("session end moved: %d, want %d", body.SessionExpiresAt, start.Add(time.Hour).Unix())
+	}
+
+	// Near the end of the session the new token is cut short at the session end.
+	nearEnd := register(time.Now().Add(-time.Hour + 30*time.Second))
+	code, body = refresh(nearEnd)
+	if code != http.StatusOK {
+		t.Fatalf("refresh status %d", code)
+	}
+	if body.ExpiresAt > body.SessionExpiresAt {
+		t.Fatalf("token expires at %d, after the session end %d", body.ExpiresAt, body.SessionExpiresAt)
+	}
+
+	// A token still inside its TTL cannot be refreshed past the session lifetime.
+	ended, err := jwt.NewWithClaims(jwt.SigningMethodHS256, cmAdminClaims{
+		RegisteredClaims: jwt.RegisteredClaims{ID: "ended", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+		SessionStart:     time.Now().Add(-2 * time.Hour).Unix(),
+	}).SignedString([]byte(controller.Options.secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin.addCMToken(ended, controller.Config.CMAdminTokenLimit, time.Now())
+	if code, _ := refresh(ended); code != http.StatusUnauthorized {
+		t.Fatalf("refresh past the session lifetime: status %d", code)
+	}
+
+	if code, _ := refresh("not-a-token"); code != http.StatusUnauthorized {
+		t.Fatalf("refresh with an unknown token: status %d", code)
+	}
+
+	// Password logins never expire and are handed back as they are.
+	login, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{ID: "login"}).SignedString([]byte(controller.Options.secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin.Tokens = append(admin.Tokens, login)
+	if code, body := refresh(login); code != http.StatusOK || body.Token != login || body.ExpiresAt != 0 {
+		t.Fatalf("password login refresh: %d %+v", code, body)
+	}
+}
+
+func TestCentralWebhookUsersBatchUpdateDryRun(t *testing.T) {
+	const key = "cm-3f9a1c0b7e24d5a8"
+
+	controller := &Controller{Options: NewOptions(), Users: NewUsers()}
+	controller.Options.CentralManagementEnabled = true
+	controller.Options.CentralManagementAPIKey = key
+	controller.Users.Add(&User{Id: 1, Email: "alice@example.com", ConnectionLimit: 2})
+	controller.Users.Add(&User{Id: 2, Email: "bob@example.com", ConnectionLimit: 5})
+	api := NewApi(controller)
+
+	body := `{"updates":[{"email":"alice@example.com","connectionLimit":5},{"email":"bob@example.com","connectionLimit":5},{"email":"carol@example.com","connectionLimit":5}]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/webhook/central-users-batch-update?dry_run=true", strings.NewReader(body))
+	req.Header.Set("X-API-Key", key)
+	rec := httptest.NewRecorder()
+	api.CentralWebhookUsersBatchUpdateHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp struct {
+		DryRun    bool                       `json:"dry_run"`
+		Updated   int                        `json:"updated"`
+		Total     int                        `json:"total"`
+		Changes   []CentralBatchUpdateChange `json:"changes"`
+		Unchanged []string                   `json:"unchanged"`
+		NotFound  []string                   `json:"not_found"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.DryRun || resp.Updated != 2 || resp.Total != 3 {
+		t.Fatalf("unexpected summary: %+v", resp)
+	}
+	if len(resp.Changes) != 1 || resp.Changes[0] != (CentralBatchUpdateChange{Email: "alice@example.com", From: 2, To: 5}) {
+		t.Fatalf("changes = %+v", resp.Changes)
+	}
+	if len(resp.Unchanged) != 1 || resp.Unchanged[0] != "bob@example.com" {
+		t.Fatalf("unchanged = %v", resp.Unchanged)
+	}
+	if len(resp.NotFound) != 1 || resp.NotFound[0] != "carol@example.com" {
+		t.Fatalf("not_found = %v", resp.NotFound)
+	}
+	if user := controller.Users.GetUserByEmail("alice@example.com"); user.ConnectionLimit != 2 {
+		t.Fatalf("dry run changed the connection limit to %d", user.ConnectionLimit)
+	}
+}
+
+func TestEnforceUserConnectionLimitDropsOldestSessions(t *testing.T) {
+	controller := &Controller{Clients: NewClients(), Logs: NewLogs(), UserGroups: NewUserGroups(), Unregister: make(chan *Client, 8)}
+	user := &User{Id: 7, Email: "alice@example.com", ConnectionLimit: 1}
+	other := &User{Id: 8, Email: "bob@example.com"}
+
+	start := time.Now()
+	newSession := func(u *User, age time.Duration) *Client {
+		c := &Client{User: u, Conn: &websocket.Conn{}, Send: make(chan *Message, 1), connectedAt: start.Add(-age)}
+		controller.Clients.Add(c)
+		return c
+	}
+	oldest := newSession(user, 3*time.Minute)
+	middle := newSession(user, 2*time.Minute)
+	newest := newSession(user, time.Minute)
+	newSession(other, 5*time.Minute)
+
+	if n := controller.enforceUserConnectionLimit(user, centralLimitLoweredMessage); n != 2 {
+		t.Fatalf("disconnected %d sessions, want 2", n)
+	}
+	for _, c := range []*Client{oldest, middle} {
+		if got := <-controller.Unregister; got != c {
+			t.Fatalf("unregistered %p, want oldest sessions first", got)
+		}
+		if msg := <-c.Send; msg.Payload != centralLimitLoweredMessage {
+			t.Fatalf("message = %v", msg.Payload)
+		}
+	}
+	if len(newest.Send) != 0 || len(controller.Unregister) != 0 {
+		t.Fatal("newest session and other users must be left alone")
+	}
+
+	user.ConnectionLimit = 0
+	if n := controller.enforceUserConnectionLimit(user, centralLimitLoweredMessage); n != 0 {
+		t.Fatalf("unlimited user had %d sessions disconnected", n)
+	}
+}
+
+func TestSetGroupMovesGroupAdmin(t *testing.T) {
+	users := NewUsers()
+	user := &User{Id: 7, Email: "alice@example.com", Pin: "1234", UserGroupId: 1, IsGroupAdmin: true}
+	users.Add(user)
+
+	// CentralWebhookUserSetGroupHandler changes the shared user in place.
+	moved := users.GetUserByEmail("alice@example.com")
+	moved.UserGroupId = 2
+	moved.IsGroupAdmin = false
+	users.Update(moved)
+
+	if admin := users.GetGroupAdmin(1); admin != nil {
+		t.Fatalf("user %d is still admin of their old group", admin.Id)
+	}
+	if admin := users.GetGroupAdmin(2); admin != nil {
+		t.Fatalf("user %d became admin of the new group", admin.Id)
+	}
+
+	moved.IsGroupAdmin = true
+	users.Update(moved)
+	moved.UserGroupId = 3
+	users.Update(moved)
+	if users.GetGroupAdmin(2) != nil || users.GetGroupAdmin(3) != moved {
+		t.Fatal("group admin was not moved to group 3")
+	}
+}
+
+func TestVerifyCentralWebhookSignature(t *testing.T) {
+	const secret = "whsec-2b7f0c91e6d84a3f9c5e1b0a7d2f4e68"
+	now := time.Unix(1760000000, 0)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	const method, uri = http.MethodPost, "/api/webhook/central-grant-user?notify=true"
+	body := []byte(`{"email":"alice@example.com"}`)
+	sig := centralWebhookSignature(secret, ts, method, uri, body)
+
+	if err := verifyCentralWebhookSignature(secret, ts, sig, method, uri, body, now); err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+	if err := verifyCentralWebhookSignature(secret, ts, sig, method, uri, body, now.Add(4*time.Minute)); err != nil {
+		t.Fatalf("signature within the skew window rejected: %v", err)
+	}
+
+	for name, c := range map[string]struct {
+		secret, ts, sig, method, uri string
+		body                         []byte
+		now                          time.Time
+	}{
+		"wrong secret":    {"other-secret", ts, sig, method, uri, body, now},
+		"tampered body":   {secret, ts, sig, method, uri, []byte(`{"email":"mallory@example.com"}`), now},
+		"other method":    {secret, ts, sig, http.MethodDelete, uri, body, now},
+		"other path":      {secret, ts, sig, method, "/api/webhook/central-revoke-user?notify=true", body, now},
+		"other query":     {secret, ts, sig, method, "/api/webhook/central-grant-user?notify=false", body, now},
+		"moved time":      {secret, strconv.FormatInt(now.Unix()+1, 10), sig, method, uri, body, now},
+		"stale":           {secret, ts, sig, method, uri, body, now.Add(6 * time.Minute)},
+		"future":          {secret, ts, sig, method, uri, body, now.Add(-6 * time.Minute)},
+		"no timestamp":    {secret, "", sig, method, uri, body, now},
+		"not hex":         {secret, ts, "zz" + sig[2:], method, uri, body, now},
+		"no secret set":   {"", ts, sig, method, uri, body, now},
+		"truncated sig":   {secret, ts, sig[:32], method, uri, body, now},
+		"empty signature": {secret, ts, "", method, uri, body, now},
+	} {
+		if err := verifyCentralWebhookSignature(c.secret, c.ts, c.sig, c.method, c.uri, c.body, c.now); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func TestAuthorizeCentralWebhookSignedRequest(t *testing.T) {
+	const key = "cm-3f9a1c0b7e24d5a8"
+	const secret = "whsec-2b7f0c91e6d84a3f9c5e1b0a7d2f4e68"
+
+	controller := &Controller{Options: NewOptions(), Users: NewUsers(), Logs: NewLogs()}
+	controller.Options.CentralManagementEnabled = true
+	controller.Options.CentralManagementAPIKey = key
+	controller.Options.CentralManagementWebhookSecret = secret
+	controller.Users.Add(&User{Id: 1, Email: "alice@example.com", ConnectionLimit: 2})
+	api := NewApi(controller)
+
+	body := `{"updates":[{"email":"alice@example.com","connectionLimit":5}]}`
+	send := func(sign func(*http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/webhook/central-users-batch-update?dry_run=true", strings.NewReader(body))
+		sign(req)
+		rec := httptest.NewRecorder()
+		api.CentralWebhookUsersBatchUpdateHandler(rec, req)
+		return rec
+	}
+	signedFor := func(b, uri string) func(*http.Request) {
+		return func(req *http.Request) {
+			ts := strconv.FormatInt(time.Now().Unix(), 10)
+			req.Header.Set("X-CM-Timestamp", ts)
+			req.Header.Set("X-CM-Signature", centralWebhookSignature(secret, ts, req.Method, uri, []byte(b)))
+		}
+	}
+	signed := func(b string) func(*http.Request) {
+		return func(req *http.Request) { signedFor(b, req.URL.RequestURI())(req) }
+	}
+	withKey := func(req *http.Request) { req.Header.Set("X-API-Key", key) }
+
+	// The handler must still see the body after it was read for verification.
+	if rec := send(signed(body)); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"updated":1`) {
+		t.Fatalf("signed request: status %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := send(signed(body + " ")); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("signature over a different body: status %d", rec.Code)
+	}
+	if rec := send(signedFor(body, "/api/webhook/central-users-batch-update")); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("signature over a different query: status %d", rec.Code)
+	}
+	// A bad signature is not rescued by a valid API key.
+	if rec := send(func(req *http.Request) { signed("{}")(req); withKey(req) }); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("bad signature with API key: status %d", rec.Code)
+	}
+	if rec := send(withKey); rec.Code != http.StatusOK {
+		t.Fatalf("legacy API key during transition: status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	controller.Options.CentralManagementRequireSignature = true
+	if rec := send(withKey); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("unsigned request with signatures required: status %d", rec.Code)
+	}
+	if rec := send(signed(body)); rec.Code != http.StatusOK {
+		t.Fatalf("signed request with signatures required: status %d: %s", rec.Code, rec.Body.String())
+	}
+}
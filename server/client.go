@@ -21,8 +21,10 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -54,6 +56,19 @@ type Client struct {
 	// client, used for sliding-window rate limiting.
 	DownloadTimestamps []time.Time
 	downloadMu         sync.Mutex
+
+	// lastCallId is the ID of the last call actually written to the socket,
+	// used by the reconnection manager to avoid replaying delivered calls.
+	lastCallId atomic.Uint64
+
+	// connectedAt is set when the client joins Clients; the oldest sessions
+	// are dropped first when a lowered connection limit is enforced.
+	connectedAt time.Time
+}
+
+// LastDeliveredCallId returns the ID of the last call written to this client.
+func (client *Client) LastDeliveredCallId() uint64 {
+	return client.lastCallId.Load()
 }
 
 // IsDownloadRateLimited returns true if the client has exceeded the configured
@@ -268,6 +283,12 @@ func (client *Client) Init(controller *Controller, request *http.Request, conn *
 						controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("websocket write error for ip %s: %v", client.GetRemoteAddr(), writeErr))
 						return
 					}
+
+					if message.Command == MessageCommandCall {
+						if call, ok := message.Payload.(*Call); ok && call.Id > client.lastCallId.Load() {
+							client.lastCallId.Store(call.Id)
+						}
+					}
 				}
 
 			case <-ticker.C:
@@ -309,6 +330,7 @@ func (client *Client) SendConfig(groups *Groups, options *Options, systems *Syst
 
 	// Get the user's group pricing options if user is authenticated and has a group
 	var pricingOptions []PricingOption
+	livefeedTags := options.DefaultLivefeedTags
 	if client.User != nil {
 		if client.User.UserGroupId > 0 {
 			if client.Controller != nil {
@@ -317,6 +339,9 @@ func (client *Client) SendConfig(groups *Groups, options *Options, systems *Syst
 					if userGroup.BillingEnabled {
 						pricingOptions = userGroup.GetPricingOptions()
 					}
+					if tags := userGroup.GetDefaultLivefeedTags(); len(tags) > 0 {
+						livefeedTags = tags
+					}
 				}
 			}
 		}
@@ -355,6 +380,11 @@ func (client *Client) SendConfig(groups *Groups, options *Options, systems *Syst
 		"time12hFormat":      options.Time12hFormat,
 	}
 
+	// Initial livefeed for clients that have no saved channel selection yet
+	if len(livefeedTags) > 0 {
+		payload["livefeedDefaults"] = NewLivefeedDefaults(client.TagsMap, livefeedTags)
+	}
+
 	// Include user settings if user is authenticated
 	if client.User != nil && client.User.Settings != "" {
 		var userSettings map[string]interface{}
@@ -468,6 +498,9 @@ func (clients *Clients) Add(client *Client) {
 	clients.mutex.Lock()
 	defer clients.mutex.Unlock()
 
+	if client.connectedAt.IsZero() {
+		client.connectedAt = time.Now()
+	}
 	clients.Map[client] = true
 }
 
@@ -637,6 +670,28 @@ func (clients *Clients) UserConnectionCount(user *User) uint {
 	return count
 }
 
+// ExcessUserSessions returns the live sessions of userId beyond limit, oldest
+// first, leaving the newest limit sessions out of the result.
+func (clients *Clients) ExcessUserSessions(userId uint64, limit uint) []*Client {
+	clients.mutex.Lock()
+	defer clients.mutex.Unlock()
+
+	sessions := []*Client{}
+	for c := range clients.Map {
+		if c.User != nil && c.User.Id == userId && c.Send != nil && c.Conn != nil {
+			sessions = append(sessions, c)
+		}
+	}
+	if uint(len(sessions)) <= limit {
+		return nil
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].connectedAt.Before(sessions[j].connectedAt)
+	})
+	return sessions[:uint(len(sessions))-limit]
+}
+
 // RefreshConfigForGroup refreshes configuration for all active clients belonging to users in the specified group
 func (clients *Clients) RefreshConfigForGroup(controller *Controller, groupId uint64) {
 	clients.mutex.Lock()
@@ -0,0 +1,208 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// compressMinSize is the smallest response worth compressing; below it the
+// gzip framing costs more than it saves.
+const compressMinSize = 1024
+
+// CompressionMiddleware gzip- or deflate-encodes text and JSON responses for
+// clients that accept it. The first compressMinSize bytes are buffered to
+// decide: smaller responses, already encoded ones and anything that is not
+// text (audio, images, archives) are passed through unchanged. Range requests
+// and WebSocket upgrades are never touched.
+func CompressionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead || r.Header.Get("Range") != "" || r.Header.Get("Upgrade") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressResponseWriter{ResponseWriter: w, encoding: encoding}
+		defer cw.Close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding picks gzip, then deflate, from an Accept-Encoding header,
+// skipping codings the client refused with q=0.
+func negotiateEncoding(header string) string {
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		ok := true
+		for _, param := range fields[1:] {
+			if k, v, found := strings.Cut(strings.TrimSpace(param), "="); found && strings.TrimSpace(k) == "q" {
+				if q, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil && q == 0 {
+					ok = false
+				}
+			}
+		}
+		if name != "" {
+			accepted[name] = ok
+		}
+	}
+	for _, encoding := range []string{"gzip", "deflate"} {
+		if accepted[encoding] {
+			return encoding
+		}
+	}
+	return ""
+}
+
+// isCompressibleType reports whether a response of contentType benefits from
+// compression. Streams (text/event-stream) are left alone so events are not
+// held back by the encoder.
+func isCompressibleType(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	switch {
+	case mediaType == "text/event-stream":
+		return false
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case mediaType == "application/json", mediaType == "application/javascript",
+		mediaType == "application/xml", mediaType == "image/svg+xml",
+		strings.HasSuffix(mediaType, "+json"):
+		return true
+	}
+	return false
+}
+
+// compressResponseWriter holds back the status and the first bytes of a
+// response until it knows whether to compress it.
+type compressResponseWriter struct {
+	http.ResponseWriter
+	encoding string
+
+	status  int
+	buf     []byte
+	decided bool
+	encoder io.WriteCloser // nil when passing through
+}
+
+func (cw *compressResponseWriter) WriteHeader(code int) {
+	if cw.decided || cw.status != 0 {
+		return
+	}
+	// Informational responses go straight out.
+	if code >= 100 && code < 200 {
+		cw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	cw.status = code
+}
+
+func (cw *compressResponseWriter) Write(b []byte) (int, error) {
+	if !cw.decided {
+		cw.buf = append(cw.buf, b...)
+		if len(cw.buf) < compressMinSize {
+			return len(b), nil
+		}
+		if err := cw.decide(); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if cw.encoder != nil {
+		return cw.encoder.Write(b)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+// decide sends the headers, choosing compression from the buffered bytes,
+// and writes out the buffer.
+func (cw *compressResponseWriter) decide() error {
+	cw.decided = true
+	status := cw.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	h := cw.Header()
+	if h.Get("Content-Type") == "" && len(cw.buf) > 0 {
+		// Sniff now, as net/http would, so the compressed bytes aren't sniffed instead.
+		h.Set("Content-Type", http.DetectContentType(cw.buf))
+	}
+	compressible := isCompressibleType(h.Get("Content-Type"))
+	if compressible {
+		h.Add("Vary", "Accept-Encoding")
+	}
+
+	if compressible && len(cw.buf) >= compressMinSize && h.Get("Content-Encoding") == "" &&
+		status != http.StatusNoContent && status != http.StatusNotModified && status != http.StatusPartialContent {
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", cw.encoding)
+		if cw.encoding == "gzip" {
+			cw.encoder = gzip.NewWriter(cw.ResponseWriter)
+		} else {
+			cw.encoder = zlib.NewWriter(cw.ResponseWriter)
+		}
+	}
+
+	cw.ResponseWriter.WriteHeader(status)
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if cw.encoder != nil {
+		_, err := cw.encoder.Write(buf)
+		return err
+	}
+	_, err := cw.ResponseWriter.Write(buf)
+	return err
+}
+
+// Close flushes a response that never reached compressMinSize and finishes
+// the compressed stream.
+func (cw *compressResponseWriter) Close() error {
+	if !cw.decided {
+		if cw.status == 0 && len(cw.buf) == 0 {
+			// Nothing was written; let net/http send its default response.
+			cw.decided = true
+			return nil
+		}
+		if err := cw.decide(); err != nil {
+			return err
+		}
+	}
+	if cw.encoder != nil {
+		return cw.encoder.Close()
+	}
+	return nil
+}
+
+// Flush implements http.Flusher for streaming responses.
+func (cw *compressResponseWriter) Flush() {
+	if !cw.decided {
+		cw.decide()
+	}
+	if f, ok := cw.encoder.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker for handlers that take over the connection.
+func (cw *compressResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := cw.ResponseWriter.(http.Hijacker); ok {
+		cw.decided = true
+		return hijacker.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
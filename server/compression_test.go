@@ -0,0 +1,112 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	cases := map[string]string{
+		"":                        "",
+		"gzip, deflate, br":       "gzip",
+		"deflate":                 "deflate",
+		"br;q=1.0, deflate;q=0.5": "deflate",
+		"gzip;q=0, deflate":       "deflate",
+		"GZIP":                    "gzip",
+		"identity":                "",
+		"gzip;q=0":                "",
+	}
+	for header, want := range cases {
+		if got := negotiateEncoding(header); got != want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestCompressionMiddleware(t *testing.T) {
+	largeJSON := `{"logs":[` + strings.Repeat(`{"level":"info","message":"call received"},`, 100) + `{}]}`
+	audio := append([]byte("ID3"), bytes.Repeat([]byte{0}, 4096)...)
+
+	serve := func(acceptEncoding string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/logs", nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		rec := httptest.NewRecorder()
+		CompressionMiddleware(handler).ServeHTTP(rec, req)
+		return rec
+	}
+	writeJSON := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(body))
+		}
+	}
+
+	rec := serve("gzip, deflate", writeJSON(largeJSON))
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("large JSON not gzipped: %v", rec.Header())
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := io.ReadAll(zr); string(b) != largeJSON {
+		t.Fatal("gzipped body does not round-trip")
+	}
+	if rec.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("Vary = %q", rec.Header().Get("Vary"))
+	}
+
+	rec = serve("deflate", writeJSON(largeJSON))
+	if rec.Header().Get("Content-Encoding") != "deflate" {
+		t.Fatalf("large JSON not deflated: %v", rec.Header())
+	}
+	fr, err := zlib.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := io.ReadAll(fr); string(b) != largeJSON {
+		t.Fatal("deflated body does not round-trip")
+	}
+
+	// Handlers that write JSON without a Content-Type are sniffed as text.
+	rec = serve("gzip", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(largeJSON)) })
+	if rec.Header().Get("Content-Encoding") != "gzip" || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Fatalf("untyped JSON: %v", rec.Header())
+	}
+
+	rec = serve("gzip", writeJSON(`{"ok":true}`))
+	if rec.Header().Get("Content-Encoding") != "" || rec.Body.String() != `{"ok":true}` {
+		t.Fatalf("small response was compressed: %v %q", rec.Header(), rec.Body.String())
+	}
+
+	rec = serve("", writeJSON(largeJSON))
+	if rec.Header().Get("Content-Encoding") != "" || rec.Body.String() != largeJSON {
+		t.Fatal("compressed without Accept-Encoding")
+	}
+
+	rec = serve("gzip", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Write(audio)
+	})
+	if rec.Header().Get("Content-Encoding") != "" || !bytes.Equal(rec.Body.Bytes(), audio) {
+		t.Fatal("audio was compressed")
+	}
+
+	rec = serve("gzip", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("Content-Encoding") != "" {
+		t.Fatalf("status-only response: %d %v", rec.Code, rec.Header())
+	}
+}
@@ -25,52 +25,116 @@ import (
 	"regexp"
 	"runtime"
 	"strconv"
+	"strings"
 
 	"gopkg.in/ini.v1"
 )
 
 const (
 	DbTypePostgresql string = "postgresql"
+
+	defaultDbPortPostgreSql uint = 5432
+
+	defaultDeviceTokenMaxAge uint = 90
+	defaultDebugLogMaxSize   uint = 50
+	defaultDebugAudioMaxSize uint = 500
+
+	defaultRestartDrainTimeout uint = 30
+
+	defaultCMAdminTokenTTL   uint = 600
+	defaultCMAdminTokenLimit uint = 5
+	defaultCMAdminSessionMax uint = 8 * 60 * 60
+
+	defaultDbMaintenanceInterval uint = 7
+
+	defaultLogPruneInterval uint = 24
+
+	defaultReconnectionMaxMemory uint = 256
+
+	defaultMqttTopicPrefix = "tlr"
+
+	defaultPinMinLength uint = 4
 )
 
+var defaultDbMaintenanceWindow = DbMaintenanceWindow{Start: 3, End: 5}
+
 type Config struct {
-	BaseDir              string
-	ConfigFile           string
-	DbType               string
-	DbHost               string
-	DbPort               uint
-	DbName               string
-	DbUsername           string
-	DbPassword           string
-	Listen               string
-	SslAutoCert          string
-	SslCaCertFile        string
-	SslCaKeyFile         string
-	SslCertFile          string
-	SslKeyFile           string
-	SslListen            string
-	EnableDebugLog       bool
-	AutoUpdate           bool   // Automatically check and apply updates from GitHub
-	daemon               *Daemon
-	newAdminPassword     string
+	BaseDir             string
+	ConfigFile          string
+	DbType              string
+	DbHost              string
+	DbPort              uint
+	DbName              string
+	DbUsername          string
+	DbPassword          string
+	Listen              string
+	SslAutoCert         string
+	SslCaCertFile       string
+	SslCaKeyFile        string
+	SslCertFile         string
+	SslKeyFile          string
+	SslListen           string
+	EnableDebugLog      bool
+	DebugLogMaxSize     uint   // MB a debug log may reach before it is rotated (0 = unlimited)
+	DebugAudioMaxSize   uint   // MB kept in debug-audio/ before the oldest clips are deleted (0 = unlimited)
+	DebugAudioEnabled   bool   // Save call clips to debug-audio/ for talkgroups that allow it
+	AutoUpdate          bool   // Automatically check and apply updates from GitHub
+	UpdateChannel       string // Release channel followed by the updater: "stable" or "beta"
+	GitHubToken         string // Optional token for GitHub API requests (avoids rate limiting)
+	CMPasswordPairing   bool   // Deprecated: accept admin_password on CM pairing (default true for one release)
+	DeviceTokenMaxAge   uint   // Days a push device token may go unused before it is pruned (0 = never)
+	FFMpegMaxConcurrent uint   // Simultaneous ffmpeg conversions allowed (0 = one per CPU)
+	RestartDrainTimeout uint   // Seconds an update restart waits for in-flight calls to finish (0 = no wait)
+	CMAdminTokenTTL     uint   // Seconds a Central Management admin token stays valid
+	CMAdminTokenLimit   uint   // Central Management admin tokens kept at once; the oldest is dropped first
+	CMAdminSessionMax   uint   // Seconds a Central Management admin session can be refreshed for
+	MetricsKey          string // Optional bearer key for /metrics; the admin token is always accepted
+
+	CorsOrigins           []string // Origins allowed to call the API from a browser (empty = same origin only)
+	ReconnectionMaxMemory uint     // MB all reconnection buffers may hold together (0 = unlimited)
+
+	MqttBroker      string // Optional MQTT broker URL calls and alerts are published to
+	MqttTopicPrefix string // First level of the MQTT topics
+	MqttUsername    string
+	MqttPassword    string
+	MqttClientId    string // MQTT client identifier (random when empty)
+
+	AudioStorage string // Where new call audio is written: "db" or "s3"
+	S3Endpoint   string // S3-compatible endpoint, e.g. https://s3.us-east-1.amazonaws.com
+	S3Region     string
+	S3Bucket     string
+	S3AccessKey  string
+	S3SecretKey  string
+	S3Prefix     string // Prepended to every object key
+
+	DbMaintenanceInterval uint                // Days between scheduled VACUUM runs on calls and logs (0 = never)
+	DbMaintenanceWindow   DbMaintenanceWindow // Local hours during which the scheduled VACUUM may start
+	LogPruneDays          uint                // Days of logs to keep (0 = follow the pruneDays option)
+	LogPruneInterval      uint                // Hours between log prunes
+
+	PinMinLength uint // Shortest PIN an admin or Central Management may set
+
+	daemon           *Daemon
+	newAdminPassword string
 }
 
 func NewConfig() *Config {
 	const (
-		defaultAdminUrl         = "/admin"
-		defaultConfigFile       = "thinline-radio.ini"
-		defaultDbType           = DbTypePostgresql
-		defaultDbHost           = "localhost"
-		defaultDbPortPostgreSql = uint(5432)
-		defaultListen           = ":3000"
+		defaultAdminUrl   = "/admin"
+		defaultConfigFile = "thinline-radio.ini"
+		defaultDbType     = DbTypePostgresql
+		defaultDbHost     = "localhost"
+		defaultListen     = ":3000"
 	)
 
 	var (
 		command       = flag.String(COMMAND_ARG, "", fmt.Sprintf("advanced administrative tasks (use -%s %s for usage)", COMMAND_ARG, COMMAND_HELP))
-		config        = &Config{}
+		config        = newConfigDefaults()
 		configSave    = flag.Bool("config_save", false, fmt.Sprintf("save configuration to %s", defaultConfigFile))
 		serviceAction = flag.String("service", "", "service command, one of start, stop, restart, install, uninstall")
 		version       = flag.Bool("version", false, "show application version")
+		checkUpdate   = flag.Bool("check_update", false, "check for a newer release and exit (status 0: up to date, 2: update available, 1: error)")
+		applyUpdate   = flag.Bool("apply_update", false, "install the newest release if there is one and exit; restart the server afterwards")
 	)
 
 	if exe, err := os.Executable(); err == nil {
@@ -129,67 +193,20 @@ func NewConfig() *Config {
 
 	default:
 		if cfg, err := ini.Load(config.GetConfigFilePath()); err == nil {
-			if v := cfg.Section("").Key("db_host").String(); len(v) > 0 {
-				config.DbHost = v
-			}
-
-			if v := cfg.Section("").Key("db_name").String(); len(v) > 0 {
-				config.DbName = v
-			}
-
-			if v := cfg.Section("").Key("db_pass").String(); len(v) > 0 {
-				config.DbPassword = v
-			}
-
-			if v := cfg.Section("").Key("db_type").String(); len(v) > 0 {
-				config.DbType = v
-			}
-
-			if config.DbPort, err = cfg.Section("").Key("db_port").Uint(); err != nil {
-				config.DbPort = defaultDbPortPostgreSql
-			}
-
-			if v := cfg.Section("").Key("db_user").String(); len(v) > 0 {
-				config.DbUsername = v
-			}
-
-			if v := cfg.Section("").Key("listen").String(); len(v) > 0 {
-				config.Listen = v
-			}
-
-			if v := cfg.Section("").Key("ssl_auto_cert").String(); len(v) > 0 {
-				config.SslAutoCert = v
-			}
-
-			if v := cfg.Section("").Key("ssl_cert_file").String(); len(v) > 0 {
-				config.SslCertFile = v
-			}
-
-			if v := cfg.Section("").Key("ssl_key_file").String(); len(v) > 0 {
-				config.SslKeyFile = v
-			}
-
-			if v := cfg.Section("").Key("ssl_listen").String(); len(v) > 0 {
-				config.SslListen = v
-			}
-
-		// Read enable_debug_log option (defaults to false)
-		if v, err := cfg.Section("").Key("enable_debug_log").Bool(); err == nil {
-			config.EnableDebugLog = v
+			config.readIni(cfg)
 		}
 
-		// Read auto_update setting (defaults to false)
-		if v, err := cfg.Section("").Key("auto_update").Bool(); err == nil {
-			config.AutoUpdate = v
-		}
-	}
-
 		if config.DbType != DbTypePostgresql {
 			fmt.Printf("unknown database type %s (only postgresql is supported)\n", config.DbType)
 			return nil
 		}
 	}
 
+	if *checkUpdate || *applyUpdate {
+		// The updater only reads the update settings of the controller's config
+		os.Exit(runUpdateCommand(NewUpdater(&Controller{Config: config}), *applyUpdate, os.Stdout))
+	}
+
 	if *command != "" {
 		NewCommand(config.BaseDir).Do(*command)
 	}
@@ -207,6 +224,190 @@ func NewConfig() *Config {
 	return config
 }
 
+// newConfigDefaults returns the settings used for keys missing from the ini.
+func newConfigDefaults() *Config {
+	return &Config{CMPasswordPairing: true, DeviceTokenMaxAge: defaultDeviceTokenMaxAge, DebugLogMaxSize: defaultDebugLogMaxSize, DebugAudioMaxSize: defaultDebugAudioMaxSize, DebugAudioEnabled: true, RestartDrainTimeout: defaultRestartDrainTimeout, CMAdminTokenTTL: defaultCMAdminTokenTTL, CMAdminTokenLimit: defaultCMAdminTokenLimit, CMAdminSessionMax: defaultCMAdminSessionMax, DbMaintenanceInterval: defaultDbMaintenanceInterval, DbMaintenanceWindow: defaultDbMaintenanceWindow, LogPruneInterval: defaultLogPruneInterval, ReconnectionMaxMemory: defaultReconnectionMaxMemory, MqttTopicPrefix: defaultMqttTopicPrefix, AudioStorage: AudioStorageDb, PinMinLength: defaultPinMinLength}
+}
+
+// readIni reads the settings of the ini file into config.
+func (config *Config) readIni(cfg *ini.File) {
+	if v := cfg.Section("").Key("db_host").String(); len(v) > 0 {
+		config.DbHost = v
+	}
+
+	if v := cfg.Section("").Key("db_name").String(); len(v) > 0 {
+		config.DbName = v
+	}
+
+	if v := cfg.Section("").Key("db_pass").String(); len(v) > 0 {
+		config.DbPassword = v
+	}
+
+	if v := cfg.Section("").Key("db_type").String(); len(v) > 0 {
+		config.DbType = v
+	}
+
+	if v, err := cfg.Section("").Key("db_port").Uint(); err == nil {
+		config.DbPort = v
+	} else {
+		config.DbPort = defaultDbPortPostgreSql
+	}
+
+	if v := cfg.Section("").Key("db_user").String(); len(v) > 0 {
+		config.DbUsername = v
+	}
+
+	if v := cfg.Section("").Key("listen").String(); len(v) > 0 {
+		config.Listen = v
+	}
+
+	if v := cfg.Section("").Key("ssl_auto_cert").String(); len(v) > 0 {
+		config.SslAutoCert = v
+	}
+
+	if v := cfg.Section("").Key("ssl_cert_file").String(); len(v) > 0 {
+		config.SslCertFile = v
+	}
+
+	if v := cfg.Section("").Key("ssl_key_file").String(); len(v) > 0 {
+		config.SslKeyFile = v
+	}
+
+	if v := cfg.Section("").Key("ssl_listen").String(); len(v) > 0 {
+		config.SslListen = v
+	}
+
+	// Read enable_debug_log option (defaults to false)
+	if v, err := cfg.Section("").Key("enable_debug_log").Bool(); err == nil {
+		config.EnableDebugLog = v
+	}
+
+	// Read debug_log_max_size_mb and debug_audio_max_size_mb (0 disables the cap)
+	if v, err := cfg.Section("").Key("debug_log_max_size_mb").Uint(); err == nil {
+		config.DebugLogMaxSize = v
+	}
+	if v, err := cfg.Section("").Key("debug_audio_max_size_mb").Uint(); err == nil {
+		config.DebugAudioMaxSize = v
+	}
+
+	if v, err := cfg.Section("").Key("ffmpeg_max_concurrent").Uint(); err == nil {
+		config.FFMpegMaxConcurrent = v
+	}
+	if v, err := cfg.Section("").Key("restart_drain_timeout").Uint(); err == nil {
+		config.RestartDrainTimeout = v
+	}
+	if v, err := cfg.Section("").Key("reconnection_max_memory_mb").Uint(); err == nil {
+		config.ReconnectionMaxMemory = v
+	}
+	if v, err := cfg.Section("").Key("pin_min_length").Uint(); err == nil {
+		config.PinMinLength = v
+	}
+
+	// Read cm_admin_token_ttl / cm_admin_token_limit / cm_admin_session_max
+	// (0 keeps the default; CM admin tokens always expire)
+	if v, err := cfg.Section("").Key("cm_admin_token_ttl").Uint(); err == nil && v > 0 {
+		config.CMAdminTokenTTL = v
+	}
+	if v, err := cfg.Section("").Key("cm_admin_token_limit").Uint(); err == nil && v > 0 {
+		config.CMAdminTokenLimit = v
+	}
+	if v, err := cfg.Section("").Key("cm_admin_session_max").Uint(); err == nil && v > 0 {
+		config.CMAdminSessionMax = v
+	}
+
+	// Read debug_audio_enabled (defaults to true; false keeps text-only debug logging)
+	if v, err := cfg.Section("").Key("debug_audio_enabled").Bool(); err == nil {
+		config.DebugAudioEnabled = v
+	}
+
+	// Read auto_update setting (defaults to false)
+	if v, err := cfg.Section("").Key("auto_update").Bool(); err == nil {
+		config.AutoUpdate = v
+	}
+
+	// Read cm_password_pairing setting (deprecated, defaults to true)
+	if v, err := cfg.Section("").Key("cm_password_pairing").Bool(); err == nil {
+		config.CMPasswordPairing = v
+	}
+
+	// Read device_token_max_age_days setting (defaults to 90, 0 disables pruning)
+	if v, err := cfg.Section("").Key("device_token_max_age_days").Uint(); err == nil {
+		config.DeviceTokenMaxAge = v
+	}
+
+	// Read db_maintenance_interval_days setting (defaults to 7, 0 disables the scheduled vacuum)
+	if v, err := cfg.Section("").Key("db_maintenance_interval_days").Uint(); err == nil {
+		config.DbMaintenanceInterval = v
+	}
+
+	// Read db_maintenance_window setting (defaults to 3-5)
+	if v := cfg.Section("").Key("db_maintenance_window").String(); len(v) > 0 {
+		if window, err := parseDbMaintenanceWindow(v); err == nil {
+			config.DbMaintenanceWindow = window
+		} else {
+			log.Printf("%v, using %s", err, defaultDbMaintenanceWindow)
+		}
+	}
+
+	// Read log_prune_days setting (defaults to 0, which follows the pruneDays option)
+	if v, err := cfg.Section("").Key("log_prune_days").Uint(); err == nil {
+		config.LogPruneDays = v
+	}
+
+	// Read log_prune_interval_hours setting (defaults to 24)
+	if v, err := cfg.Section("").Key("log_prune_interval_hours").Uint(); err == nil && v > 0 {
+		config.LogPruneInterval = v
+	}
+
+	// Read github_token setting (optional)
+	if v := cfg.Section("").Key("github_token").String(); len(v) > 0 {
+		config.GitHubToken = v
+	}
+
+	// Read metrics_key setting (optional)
+	if v := strings.TrimSpace(cfg.Section("").Key("metrics_key").String()); len(v) > 0 {
+		config.MetricsKey = v
+	}
+
+	// Read cors_allowed_origins (comma-separated, optional)
+	config.CorsOrigins = parseCorsOrigins(cfg.Section("").Key("cors_allowed_origins").String())
+
+	// Read mqtt_* settings (optional; publishing is off without a broker)
+	config.MqttBroker = strings.TrimSpace(cfg.Section("").Key("mqtt_broker").String())
+	if cfg.Section("").HasKey("mqtt_topic_prefix") {
+		config.MqttTopicPrefix = strings.TrimSpace(cfg.Section("").Key("mqtt_topic_prefix").String())
+	}
+	config.MqttUsername = cfg.Section("").Key("mqtt_username").String()
+	config.MqttPassword = cfg.Section("").Key("mqtt_password").String()
+	config.MqttClientId = strings.TrimSpace(cfg.Section("").Key("mqtt_client_id").String())
+
+	// Read audio_storage and s3_* settings (defaults to the database)
+	switch v := strings.ToLower(strings.TrimSpace(cfg.Section("").Key("audio_storage").String())); v {
+	case AudioStorageDb, AudioStorageS3:
+		config.AudioStorage = v
+	case "":
+	default:
+		log.Printf("unknown audio_storage %q, using %s", v, AudioStorageDb)
+	}
+	config.S3Endpoint = strings.TrimSpace(cfg.Section("").Key("s3_endpoint").String())
+	config.S3Region = strings.TrimSpace(cfg.Section("").Key("s3_region").String())
+	config.S3Bucket = strings.TrimSpace(cfg.Section("").Key("s3_bucket").String())
+	config.S3AccessKey = strings.TrimSpace(cfg.Section("").Key("s3_access_key").String())
+	config.S3SecretKey = strings.TrimSpace(cfg.Section("").Key("s3_secret_key").String())
+	config.S3Prefix = strings.TrimLeft(strings.TrimSpace(cfg.Section("").Key("s3_prefix").String()), "/")
+
+	// Read update_channel setting (defaults to stable)
+	switch v := strings.ToLower(strings.TrimSpace(cfg.Section("").Key("update_channel").String())); v {
+	case UpdateChannelStable, UpdateChannelBeta:
+		config.UpdateChannel = v
+	case "":
+		config.UpdateChannel = UpdateChannelStable
+	default:
+		log.Printf("unknown update_channel %q, using %s", v, UpdateChannelStable)
+		config.UpdateChannel = UpdateChannelStable
+	}
+}
+
 func (config *Config) GetConfigFilePath() string {
 	return config.GetPath(config.ConfigFile)
 }
@@ -294,6 +495,123 @@ func (config *Config) saveConfig() error {
 		ini = append(ini, "enable_debug_log = true")
 	}
 
+	if config.DebugLogMaxSize != defaultDebugLogMaxSize {
+		ini = append(ini, fmt.Sprintf("debug_log_max_size_mb = %d", config.DebugLogMaxSize))
+	}
+
+	if config.DebugAudioMaxSize != defaultDebugAudioMaxSize {
+		ini = append(ini, fmt.Sprintf("debug_audio_max_size_mb = %d", config.DebugAudioMaxSize))
+	}
+
+	if !config.DebugAudioEnabled {
+		ini = append(ini, "debug_audio_enabled = false")
+	}
+
+	if config.FFMpegMaxConcurrent > 0 {
+		ini = append(ini, fmt.Sprintf("ffmpeg_max_concurrent = %d", config.FFMpegMaxConcurrent))
+	}
+
+	if config.RestartDrainTimeout != defaultRestartDrainTimeout {
+		ini = append(ini, fmt.Sprintf("restart_drain_timeout = %d", config.RestartDrainTimeout))
+	}
+
+	if config.ReconnectionMaxMemory != defaultReconnectionMaxMemory {
+		ini = append(ini, fmt.Sprintf("reconnection_max_memory_mb = %d", config.ReconnectionMaxMemory))
+	}
+
+	if config.PinMinLength != defaultPinMinLength {
+		ini = append(ini, fmt.Sprintf("pin_min_length = %d", config.PinMinLength))
+	}
+
+	if config.CMAdminTokenTTL != defaultCMAdminTokenTTL {
+		ini = append(ini, fmt.Sprintf("cm_admin_token_ttl = %d", config.CMAdminTokenTTL))
+	}
+
+	if config.CMAdminTokenLimit != defaultCMAdminTokenLimit {
+		ini = append(ini, fmt.Sprintf("cm_admin_token_limit = %d", config.CMAdminTokenLimit))
+	}
+
+	if config.CMAdminSessionMax != defaultCMAdminSessionMax {
+		ini = append(ini, fmt.Sprintf("cm_admin_session_max = %d", config.CMAdminSessionMax))
+	}
+
+	if config.UpdateChannel == UpdateChannelBeta {
+		ini = append(ini, "update_channel = beta")
+	}
+
+	if config.GitHubToken != "" {
+		ini = append(ini, fmt.Sprintf("github_token = %s", config.GitHubToken))
+	}
+
+	if config.MetricsKey != "" {
+		ini = append(ini, fmt.Sprintf("metrics_key = %s", config.MetricsKey))
+	}
+
+	if len(config.CorsOrigins) > 0 {
+		ini = append(ini, fmt.Sprintf("cors_allowed_origins = %s", strings.Join(config.CorsOrigins, ", ")))
+	}
+
+	if config.MqttBroker != "" {
+		ini = append(ini, fmt.Sprintf("mqtt_broker = %s", config.MqttBroker))
+	}
+
+	if config.MqttTopicPrefix != defaultMqttTopicPrefix {
+		ini = append(ini, fmt.Sprintf("mqtt_topic_prefix = %s", config.MqttTopicPrefix))
+	}
+
+	if config.MqttUsername != "" {
+		ini = append(ini, fmt.Sprintf("mqtt_username = %s", config.MqttUsername))
+	}
+
+	if config.MqttPassword != "" {
+		ini = append(ini, fmt.Sprintf("mqtt_password = %s", config.MqttPassword))
+	}
+
+	if config.MqttClientId != "" {
+		ini = append(ini, fmt.Sprintf("mqtt_client_id = %s", config.MqttClientId))
+	}
+
+	if config.AudioStorage != AudioStorageDb {
+		ini = append(ini, fmt.Sprintf("audio_storage = %s", config.AudioStorage))
+	}
+
+	for _, setting := range [][2]string{
+		{"s3_endpoint", config.S3Endpoint},
+		{"s3_region", config.S3Region},
+		{"s3_bucket", config.S3Bucket},
+		{"s3_access_key", config.S3AccessKey},
+		{"s3_secret_key", config.S3SecretKey},
+		{"s3_prefix", config.S3Prefix},
+	} {
+		if setting[1] != "" {
+			ini = append(ini, fmt.Sprintf("%s = %s", setting[0], setting[1]))
+		}
+	}
+
+	if !config.CMPasswordPairing {
+		ini = append(ini, "cm_password_pairing = false")
+	}
+
+	if config.DeviceTokenMaxAge != defaultDeviceTokenMaxAge {
+		ini = append(ini, fmt.Sprintf("device_token_max_age_days = %d", config.DeviceTokenMaxAge))
+	}
+
+	if config.DbMaintenanceInterval != defaultDbMaintenanceInterval {
+		ini = append(ini, fmt.Sprintf("db_maintenance_interval_days = %d", config.DbMaintenanceInterval))
+	}
+
+	if config.DbMaintenanceWindow != defaultDbMaintenanceWindow {
+		ini = append(ini, fmt.Sprintf("db_maintenance_window = %s", config.DbMaintenanceWindow))
+	}
+
+	if config.LogPruneDays != 0 {
+		ini = append(ini, fmt.Sprintf("log_prune_days = %d", config.LogPruneDays))
+	}
+
+	if config.LogPruneInterval != defaultLogPruneInterval {
+		ini = append(ini, fmt.Sprintf("log_prune_interval_hours = %d", config.LogPruneInterval))
+	}
+
 	file, err := os.Create(config.GetConfigFilePath())
 	if err != nil {
 		return err
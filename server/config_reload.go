@@ -0,0 +1,217 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// ConfigReload is the outcome of re-reading the ini file and the options.
+type ConfigReload struct {
+	Changed         []string `json:"changed"`         // ini keys and options applied live
+	RestartRequired []string `json:"restartRequired"` // ini keys that changed but are only read at startup
+}
+
+// iniSetting is an ini key and the Config field it is read into.
+type iniSetting struct {
+	key   string
+	field func(config *Config) any // pointer to the field
+}
+
+// reloadableIniKeys are read where they are used, so copying the new value
+// into the running config is enough. A key removed from the ini goes back to
+// its default.
+var reloadableIniKeys = []iniSetting{
+	{"log_prune_days", func(c *Config) any { return &c.LogPruneDays }},
+	{"log_prune_interval_hours", func(c *Config) any { return &c.LogPruneInterval }},
+	{"device_token_max_age_days", func(c *Config) any { return &c.DeviceTokenMaxAge }},
+	{"db_maintenance_interval_days", func(c *Config) any { return &c.DbMaintenanceInterval }},
+	{"db_maintenance_window", func(c *Config) any { return &c.DbMaintenanceWindow }},
+	{"reconnection_max_memory_mb", func(c *Config) any { return &c.ReconnectionMaxMemory }},
+	{"restart_drain_timeout", func(c *Config) any { return &c.RestartDrainTimeout }},
+	{"update_channel", func(c *Config) any { return &c.UpdateChannel }},
+	{"github_token", func(c *Config) any { return &c.GitHubToken }},
+	{"metrics_key", func(c *Config) any { return &c.MetricsKey }},
+	{"cm_admin_token_ttl", func(c *Config) any { return &c.CMAdminTokenTTL }},
+	{"cm_admin_token_limit", func(c *Config) any { return &c.CMAdminTokenLimit }},
+	{"cm_admin_session_max", func(c *Config) any { return &c.CMAdminSessionMax }},
+	{"cm_password_pairing", func(c *Config) any { return &c.CMPasswordPairing }},
+	{"pin_min_length", func(c *Config) any { return &c.PinMinLength }},
+}
+
+// startupIniKeys are only read when the server starts: they bind listeners,
+// open the database, or size workers and connections created once.
+var startupIniKeys = []iniSetting{
+	{"db_type", func(c *Config) any { return &c.DbType }},
+	{"db_host", func(c *Config) any { return &c.DbHost }},
+	{"db_port", func(c *Config) any { return &c.DbPort }},
+	{"db_name", func(c *Config) any { return &c.DbName }},
+	{"db_user", func(c *Config) any { return &c.DbUsername }},
+	{"db_pass", func(c *Config) any { return &c.DbPassword }},
+	{"listen", func(c *Config) any { return &c.Listen }},
+	{"ssl_listen", func(c *Config) any { return &c.SslListen }},
+	{"ssl_auto_cert", func(c *Config) any { return &c.SslAutoCert }},
+	{"ssl_cert_file", func(c *Config) any { return &c.SslCertFile }},
+	{"ssl_key_file", func(c *Config) any { return &c.SslKeyFile }},
+	{"cors_allowed_origins", func(c *Config) any { return &c.CorsOrigins }},
+	{"enable_debug_log", func(c *Config) any { return &c.EnableDebugLog }},
+	{"debug_log_max_size_mb", func(c *Config) any { return &c.DebugLogMaxSize }},
+	{"debug_audio_max_size_mb", func(c *Config) any { return &c.DebugAudioMaxSize }},
+	{"debug_audio_enabled", func(c *Config) any { return &c.DebugAudioEnabled }},
+	{"ffmpeg_max_concurrent", func(c *Config) any { return &c.FFMpegMaxConcurrent }},
+	{"auto_update", func(c *Config) any { return &c.AutoUpdate }},
+	{"mqtt_broker", func(c *Config) any { return &c.MqttBroker }},
+	{"mqtt_topic_prefix", func(c *Config) any { return &c.MqttTopicPrefix }},
+	{"mqtt_username", func(c *Config) any { return &c.MqttUsername }},
+	{"mqtt_password", func(c *Config) any { return &c.MqttPassword }},
+	{"mqtt_client_id", func(c *Config) any { return &c.MqttClientId }},
+	{"audio_storage", func(c *Config) any { return &c.AudioStorage }},
+	{"s3_endpoint", func(c *Config) any { return &c.S3Endpoint }},
+	{"s3_region", func(c *Config) any { return &c.S3Region }},
+	{"s3_bucket", func(c *Config) any { return &c.S3Bucket }},
+	{"s3_access_key", func(c *Config) any { return &c.S3AccessKey }},
+	{"s3_secret_key", func(c *Config) any { return &c.S3SecretKey }},
+	{"s3_prefix", func(c *Config) any { return &c.S3Prefix }},
+}
+
+var configReloadMutex sync.Mutex
+
+// differs reports whether the setting has a different value in a and b.
+func (setting iniSetting) differs(a *Config, b *Config) bool {
+	return !reflect.DeepEqual(reflect.ValueOf(setting.field(a)).Elem().Interface(), reflect.ValueOf(setting.field(b)).Elem().Interface())
+}
+
+// copy sets the setting in dst to its value in src.
+func (setting iniSetting) copy(dst *Config, src *Config) {
+	reflect.ValueOf(setting.field(dst)).Elem().Set(reflect.ValueOf(setting.field(src)).Elem())
+}
+
+// ReloadConfig re-reads the ini file and the options from the database and
+// applies what can change while running, without dropping client
+// connections. Only the names of changed settings are logged, never their
+// values, as several are credentials.
+func (controller *Controller) ReloadConfig(source string) (*ConfigReload, error) {
+	configReloadMutex.Lock()
+	defer configReloadMutex.Unlock()
+
+	reload := &ConfigReload{Changed: []string{}, RestartRequired: []string{}}
+
+	// Without an ini file everything comes from flags and defaults
+	if _, err := os.Stat(controller.Config.GetConfigFilePath()); err == nil {
+		cfg, err := ini.Load(controller.Config.GetConfigFilePath())
+		if err != nil {
+			return nil, fmt.Errorf("config reload: %v", err)
+		}
+
+		read := newConfigDefaults()
+		read.readIni(cfg)
+		for _, setting := range reloadableIniKeys {
+			if setting.differs(controller.Config, read) {
+				setting.copy(controller.Config, read)
+				reload.Changed = append(reload.Changed, setting.key)
+			}
+		}
+
+		// Flags given on the command line are kept when the ini does not set
+		// the key, as at startup.
+		startup := *controller.Config
+		startup.readIni(cfg)
+		for _, setting := range startupIniKeys {
+			if setting.differs(&startup, controller.Config) {
+				reload.RestartRequired = append(reload.RestartRequired, setting.key)
+			}
+		}
+	}
+
+	before, err := controller.Options.snapshot()
+	if err != nil {
+		return nil, fmt.Errorf("config reload: %v", err)
+	}
+	if err := controller.Options.Read(controller.Database); err != nil {
+		return nil, fmt.Errorf("config reload: %v", err)
+	}
+	after, err := controller.Options.snapshot()
+	if err != nil {
+		return nil, fmt.Errorf("config reload: %v", err)
+	}
+
+	changed := map[string]any{}
+	for key, value := range after {
+		if !bytes.Equal(before[key], value) {
+			var v any
+			json.Unmarshal(value, &v)
+			changed[key] = v
+		}
+	}
+	if len(changed) > 0 {
+		keys := make([]string, 0, len(changed))
+		for key := range changed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		reload.Changed = append(reload.Changed, keys...)
+
+		controller.ApplyOptionsRuntimeSideEffects(changed)
+	}
+
+	// The reconnection manager copies its settings at startup
+	if rm := controller.ReconnectionMgr; rm != nil {
+		rm.mutex.Lock()
+		rm.HoldDuration = time.Duration(controller.Options.ReconnectionGracePeriod) * time.Second
+		rm.MaxBufferSize = int(controller.Options.ReconnectionMaxBufferSize)
+		rm.MaxMemory = int64(controller.Config.ReconnectionMaxMemory) << 20
+		rm.mutex.Unlock()
+	}
+
+	if len(reload.Changed) > 0 {
+		controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("config reload (%s): applied %s", source, strings.Join(reload.Changed, ", ")))
+	}
+	if len(reload.RestartRequired) > 0 {
+		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("config reload (%s): %s changed but only take effect after a restart", source, strings.Join(reload.RestartRequired, ", ")))
+	}
+	if len(reload.Changed) == 0 && len(reload.RestartRequired) == 0 {
+		controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("config reload (%s): nothing changed", source))
+	}
+
+	return reload, nil
+}
+
+// snapshot returns the options as JSON values by key, to find what a reload
+// changed.
+func (options *Options) snapshot() (map[string]json.RawMessage, error) {
+	options.mutex.Lock()
+	b, err := json.Marshal(options)
+	options.mutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	m := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,48 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import "testing"
+
+func TestIniSettingsAreListedOnce(t *testing.T) {
+	seen := map[string]bool{}
+	for _, settings := range [][]iniSetting{reloadableIniKeys, startupIniKeys} {
+		for _, setting := range settings {
+			if seen[setting.key] {
+				t.Errorf("%s is listed twice", setting.key)
+			}
+			seen[setting.key] = true
+		}
+	}
+}
+
+func TestIniSettingCopy(t *testing.T) {
+	live := &Config{LogPruneDays: 30, CorsOrigins: []string{"https://a.example"}, DbMaintenanceWindow: DbMaintenanceWindow{Start: 3, End: 5}}
+	read := &Config{LogPruneDays: 7, CorsOrigins: []string{"https://a.example"}, DbMaintenanceWindow: DbMaintenanceWindow{Start: 1, End: 2}}
+
+	changed := []string{}
+	for _, setting := range reloadableIniKeys {
+		if setting.differs(live, read) {
+			setting.copy(live, read)
+			changed = append(changed, setting.key)
+		}
+	}
+	if len(changed) != 2 || changed[0] != "log_prune_days" || changed[1] != "db_maintenance_window" {
+		t.Fatalf("changed = %v", changed)
+	}
+	if live.LogPruneDays != 7 || live.DbMaintenanceWindow.Start != 1 {
+		t.Fatalf("settings not copied: %+v", live)
+	}
+
+	for _, setting := range startupIniKeys {
+		if setting.differs(live, read) {
+			t.Errorf("%s differs", setting.key)
+		}
+	}
+	read.CorsOrigins = []string{"https://b.example"}
+	for _, setting := range startupIniKeys {
+		if setting.differs(live, read) != (setting.key == "cors_allowed_origins") {
+			t.Errorf("%s: differs = %v", setting.key, setting.differs(live, read))
+		}
+	}
+}
@@ -26,7 +26,6 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"math"
 	"net/http"
 	"os"
 	"os/exec"
@@ -43,6 +42,8 @@ type Controller struct {
 	Admin                            *Admin
 	Api                              *Api
 	Apikeys                          *Apikeys
+	AudioStorage                     AudioStorage
+	ChunkedUploads                   *ChunkedUploads
 	Calls                            *Calls
 	Clients                          *Clients
 	Config                           *Config
@@ -53,11 +54,13 @@ type Controller struct {
 	FFMpeg                           *FFMpeg
 	Groups                           *Groups
 	Logs                             *Logs
+	Mqtt                             *Mqtt
 	Options                          *Options
 	ReconnectionMgr                  *ReconnectionManager
 	Scheduler                        *Scheduler
 	Systems                          *Systems
 	Tags                             *Tags
+	Webhooks                         *Webhooks
 	Users                            *Users
 	UserGroups                       *UserGroups
 	RegistrationCodes                *RegistrationCodes
@@ -85,6 +88,8 @@ type Controller struct {
 	Unregister        chan *Client
 	Ingest            chan *Call
 	running           bool
+	draining          atomic.Bool  // set before an update restart; uploads are refused
+	ingestActive      atomic.Int64 // uploads being received plus calls being ingested
 	startupReady      atomic.Bool
 	startupReadyAt    atomic.Int64 // unix nanos when config finished loading
 	workerCancel      context.CancelFunc // Function to cancel worker context
@@ -103,6 +108,9 @@ type Controller struct {
 	// Tones detected on tone-only calls are stored here and attached to the first subsequent voice call
 	pendingTones      map[string]*PendingToneSequence // Key: "systemId:talkgroupId"
 	pendingTonesMutex sync.Mutex
+	// Voice calls that already claimed pending tones (callId -> claim time, Unix ms), guarded by
+	// pendingTonesMutex. Stops one transmission from being attached to two alerts.
+	toneClaims map[uint64]int64
 
 	// Waiting short calls per talkgroup (for waiting 15 seconds to see if a longer voice call arrives)
 	// Short transcripts that don't meet minimum requirements are stored here with a timer
@@ -110,6 +118,10 @@ type Controller struct {
 	waitingShortCalls      map[string]*WaitingShortCall // Key: "systemId:talkgroupId"
 	waitingShortCallsMutex sync.Mutex
 
+	// Open tone alerts holding for follow-up voice on talkgroups with a voice capture window
+	voiceCaptures      map[string]*voiceCapture // Key: "systemId:talkgroupId"
+	voiceCapturesMutex sync.Mutex
+
 	// Per-user mutexes to serialize authentication and prevent race conditions
 	authMutexes      map[uint64]*sync.Mutex // Key: user ID
 	authMutexesMutex sync.Mutex
@@ -117,6 +129,9 @@ type Controller struct {
 	// Stop channel for the system health monitoring ticker (StartSystemHealthMonitoring)
 	healthMonitorStop chan struct{}
 
+	// Progress of moving existing call audio to external storage
+	audioOffload audioOffloader
+
 	// Stop channels for per-system no-audio monitoring goroutines
 	noAudioMonitorStops   map[uint64]chan struct{}
 	noAudioMonitorStopsMu sync.Mutex
@@ -136,6 +151,9 @@ type Controller struct {
 	// upstream providers with on-disk caching + singleflight de-duplication,
 	// so it doesn't need (and shouldn't share) the general anti-abuse budget.
 	TileRateLimiter *RateLimiter
+	// CentralRateLimiter is a per-IP token bucket for the Central Management
+	// webhook and pairing endpoints, which can create users or list them.
+	CentralRateLimiter *TokenBucketLimiter
 
 	// Auto-updater
 	Updater *Updater
@@ -204,8 +222,9 @@ func NewController(config *Config) *Controller {
 		Clients:           NewClients(),
 		Config:            config,
 		Apikeys:           NewApikeys(),
+		ChunkedUploads:    NewChunkedUploads(),
 		Dirwatches:        NewDirwatches(),
-		FFMpeg:            NewFFMpeg(),
+		FFMpeg:            NewFFMpeg(int(config.FFMpegMaxConcurrent)),
 		Groups:            NewGroups(),
 		Logs:              NewLogs(),
 		Options:           NewOptions(),
@@ -215,7 +234,9 @@ func NewController(config *Config) *Controller {
 		Unregister:        make(chan *Client, 8192),
 		Ingest:            make(chan *Call, 8192),
 		pendingTones:      make(map[string]*PendingToneSequence),
+		toneClaims:        make(map[uint64]int64),
 		waitingShortCalls: make(map[string]*WaitingShortCall),
+		voiceCaptures:     make(map[string]*voiceCapture),
 		authMutexes:       make(map[uint64]*sync.Mutex),
 		RecentCalls:       NewRecentCallsRing(),
 	}
@@ -223,6 +244,7 @@ func NewController(config *Config) *Controller {
 	controller.Admin = NewAdmin(controller)
 	controller.Api = NewApi(controller)
 	controller.Calls = NewCalls(controller)
+	controller.Systems.controller = controller
 	controller.Database = NewDatabase(config)
 	controller.Users = NewUsers()
 	controller.UserGroups = NewUserGroups()
@@ -234,6 +256,14 @@ func NewController(config *Config) *Controller {
 	controller.Health = NewHealthService(controller)
 	controller.Delayer = NewDelayer(controller)
 	controller.Downstreams = NewDownstreams(controller)
+	controller.Webhooks = NewWebhooks(controller)
+	controller.Mqtt = NewMqtt(controller)
+
+	if storage, err := NewAudioStorage(config); err != nil {
+		log.Printf("audio storage: %v; call audio stays in the database", err)
+	} else {
+		controller.AudioStorage = storage
+	}
 	controller.Scheduler = NewScheduler(controller)
 
 	// Initialize performance caches
@@ -251,7 +281,7 @@ func NewController(config *Config) *Controller {
 
 	// Initialize debug logger for tones/keywords if enabled in config
 	if config.EnableDebugLog {
-		debugLogger, err := NewDebugLogger("tone-keyword-debug.log")
+		debugLogger, err := NewDebugLogger("tone-keyword-debug.log", int64(config.DebugLogMaxSize)<<20, int64(config.DebugAudioMaxSize)<<20, config.DebugAudioEnabled)
 		if err != nil {
 			log.Printf("Warning: Failed to create debug logger: %v", err)
 		} else {
@@ -260,7 +290,7 @@ func NewController(config *Config) *Controller {
 		}
 
 		// Also initialize transcription debug logger
-		transcriptionDebugLogger, err := NewTranscriptionDebugLogger("transcription-tone-debug.log")
+		transcriptionDebugLogger, err := NewTranscriptionDebugLogger("transcription-tone-debug.log", int64(config.DebugLogMaxSize)<<20)
 		if err != nil {
 			log.Printf("Warning: Failed to create transcription debug logger: %v", err)
 		} else {
@@ -284,6 +314,8 @@ func NewController(config *Config) *Controller {
 	// them) and are already disk-cached + singleflight-deduped, so they get
 	// their own budget instead of competing with API calls for the general one.
 	controller.TileRateLimiter = NewRateLimiter(12000, 1*time.Minute)
+	// Central Management limiter: bursts of 60, then 1 request per second
+	controller.CentralRateLimiter = NewTokenBucketLimiter(60, 1)
 	// Login attempt tracker: 6 failed attempts = 15 minute block
 	controller.LoginAttemptTracker = NewLoginAttemptTracker(6, 15*time.Minute)
 
@@ -294,6 +326,7 @@ func NewController(config *Config) *Controller {
 	// Initialize reconnection manager with default settings
 	// Will be reconfigured with actual settings from Options after Options.Read()
 	controller.ReconnectionMgr = NewReconnectionManager(controller, 60*time.Second, 100, true)
+	controller.ReconnectionMgr.MaxMemory = int64(config.ReconnectionMaxMemory) << 20
 
 	// Initialize transcription queue (if transcription is enabled in options)
 	// This will be initialized after Options.Read() in Start()
@@ -302,6 +335,10 @@ func NewController(config *Config) *Controller {
 }
 
 func (controller *Controller) EmitCall(call *Call) {
+	// Outbound webhooks, like downstreams, are integrations and never delayed.
+	go controller.Webhooks.SendCall(call)
+	controller.Mqtt.PublishCall(call)
+
 	// Forwarded calls (received from another TLR server via downstream) are never
 	// re-forwarded — only emitted to local clients — to prevent circular loops.
 	if call.IsForwarded {
@@ -355,6 +392,11 @@ func (controller *Controller) EmitCallToClient(call *Call, client *Client) {
 func (controller *Controller) EmitConfig() {
 	go controller.Clients.EmitConfig(controller)
 	go controller.Admin.BroadcastConfig()
+
+	// Systems/talkgroups saves all end here; CM gets a debounced diff.
+	if controller.CentralManagement != nil {
+		controller.CentralManagement.NotifyConfigChanged()
+	}
 }
 
 // resolveGroupIdForLabel returns the database groupId for a label, refreshing from the DB
@@ -418,7 +460,7 @@ func (controller *Controller) resolveTagIdForLabel(tagLabel string) (uint64, err
 		return tag.Id, nil
 	}
 
-	tag := &Tag{Label: tagLabel}
+	tag := &Tag{Label: tagLabel, Color: defaultTagColor(tagLabel)}
 	controller.Tags.List = append(controller.Tags.List, tag)
 	if err := controller.Tags.Write(controller.Database); err != nil {
 		return 0, err
@@ -433,6 +475,40 @@ func (controller *Controller) resolveTagIdForLabel(tagLabel string) (uint64, err
 	return 0, fmt.Errorf("unable to resolve tag %s", tagLabel)
 }
 
+// DrainForRestart stops taking new calls and waits up to timeout for queued
+// and in-flight calls, plus any transcription already running, to finish.
+// It reports whether everything finished in time; the caller restarts either
+// way.
+func (controller *Controller) DrainForRestart(timeout time.Duration) bool {
+	controller.draining.Store(true)
+	if controller.Mqtt != nil {
+		controller.Mqtt.Stop()
+	}
+
+	controller.Dirwatches.Stop()
+
+	busy := func() (int64, int, int64) {
+		var transcribing int64
+		if controller.TranscriptionQueue != nil {
+			transcribing = controller.TranscriptionQueue.ActiveJobs()
+		}
+		return controller.ingestActive.Load(), len(controller.Ingest), transcribing
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		active, queued, transcribing := busy()
+		if active == 0 && queued == 0 && transcribing == 0 {
+			return true
+		}
+		if !time.Now().Before(deadline) {
+			controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("restart drain timed out after %s: %d calls in progress, %d queued, %d transcriptions running", timeout, active, queued, transcribing))
+			return false
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
 func (controller *Controller) IngestCall(call *Call) {
 	var (
 		err         error
@@ -904,13 +980,16 @@ func (controller *Controller) processCallAfterDuplicateCheck(call *Call) {
 		}
 	}
 
-	// Stage 4: Encode audio to AAC/M4A for storage and streaming.
-	if convertErr := controller.FFMpeg.Convert(call, controller.Systems, controller.Tags, controller.Options.AudioConversion); convertErr != nil {
-		controller.Logs.LogEvent(LogLevelWarn, convertErr.Error())
-	}
+	// Stage 4: Encode audio (AAC/M4A, or FLAC when configured) for storage and streaming.
+	// A failed conversion still stores the original audio; the error is
+	// logged once the call has an id so it can be traced back.
+	convertErr := controller.FFMpeg.Convert(call, controller.Systems, controller.Tags, controller.Options)
 
 	if id, err := controller.Calls.WriteCall(call, controller.Database); err == nil {
 		call.Id = id
+		if convertErr != nil {
+			controller.logConvertError(call, convertErr)
+		}
 		// After writing, query the database to get the talkgroup ID that was actually written
 		// This ensures we have the correct database ID for logging (like v6 did)
 		// First try to get from cache, fallback to database query if needed
@@ -974,10 +1053,24 @@ func (controller *Controller) processCallAfterDuplicateCheck(call *Call) {
 		// This ensures we only attach pending tones to calls that actually have voice (not tone-only)
 		// See transcription_queue.go where checkAndAttachPendingTones is called after transcription confirms voice
 	} else {
+		if convertErr != nil {
+			controller.logConvertError(call, convertErr)
+		}
 		logError(err)
 	}
 }
 
+func (controller *Controller) logConvertError(call *Call, err error) {
+	ctx := &LogContext{CallId: call.Id}
+	if call.System != nil {
+		ctx.SystemId = call.System.Id
+	}
+	if call.Talkgroup != nil {
+		ctx.TalkgroupId = call.Talkgroup.Id
+	}
+	controller.Logs.LogEventWithContext(LogLevelWarn, fmt.Sprintf("audio conversion for call %d: %v", call.Id, err), ctx)
+}
+
 // purgeLegacyDuplicates deletes isDuplicate=true rows that were written before
 // duplicates were dropped at ingest. Runs once at startup in a background goroutine,
 // deleting in small batches so it never holds a long table lock.
@@ -1144,7 +1237,7 @@ func (controller *Controller) processToneDetection(call *Call) {
 
 		// Save audio file labeled as tone-only (will be updated if voice is found later)
 		if controller.DebugLogger != nil {
-			go controller.DebugLogger.SaveAudioFile(call.Id, call.Audio, call.AudioMime, "tone-only")
+			go controller.DebugLogger.SaveAudioFile(call.Id, call.Talkgroup, call.Audio, call.AudioMime, "tone-only")
 		}
 
 		// Match against configured tone sets - find ALL matches for stacked tones
@@ -1155,40 +1248,8 @@ func (controller *Controller) processToneDetection(call *Call) {
 		if controller.DebugLogger != nil {
 			for _, tone := range toneSequence.Tones {
 				// Find which tone set(s) matched this tone
-				matchedLabels := []string{}
-				for _, ts := range matchedToneSets {
-					// Check if this tone matches this tone set
-					baseTol := ts.Tolerance
-					actualTol := baseTol
-					if baseTol < 1.0 {
-						actualTol = baseTol * 500.0
-					}
-
-					matched := false
-					if tone.ToneType == "A" && ts.ATone != nil {
-						if math.Abs(tone.Frequency-ts.ATone.Frequency) <= actualTol {
-							matched = true
-						}
-					} else if tone.ToneType == "B" && ts.BTone != nil {
-						if math.Abs(tone.Frequency-ts.BTone.Frequency) <= actualTol {
-							matched = true
-						}
-					} else if tone.ToneType == "Long" && ts.LongTone != nil {
-						if math.Abs(tone.Frequency-ts.LongTone.Frequency) <= actualTol {
-							matched = true
-						}
-					}
-
-					if matched {
-						matchedLabels = append(matchedLabels, ts.Label)
-					}
-				}
-
-				matchedStr := "NO_MATCH"
-				if len(matchedLabels) > 0 {
-					matchedStr = fmt.Sprintf("MATCHED: %s", strings.Join(matchedLabels, ", "))
-				}
-				controller.DebugLogger.LogToneFrequency(call.Id, tone.Frequency, tone.Duration, len(matchedLabels) > 0, matchedStr)
+				matchedLabels := toneMatchedLabels(tone, matchedToneSets)
+				controller.DebugLogger.LogToneFrequency(call.Id, tone.Frequency, tone.Duration, len(matchedLabels) > 0, strings.Join(matchedLabels, ", "))
 			}
 		}
 
@@ -1270,9 +1331,9 @@ func (controller *Controller) processToneDetection(call *Call) {
 			controller.storePendingTones(call, toneSequence)
 			controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("tones detected on tone-only call %d, storing as pending for talkgroup %d (audio: %d bytes, duration: %.2fs, no alert created)", call.Id, call.Talkgroup.TalkgroupRef, len(call.Audio), audioDuration))
 		} else {
-			// Transcription completed and has voice - trigger alert immediately
+			// Transcription completed and has voice - trigger alert (or open its voice capture)
 			if call.System != nil && call.System.AlertsEnabled && call.Talkgroup != nil && call.Talkgroup.AlertsEnabled {
-				go controller.AlertEngine.TriggerToneAlerts(call)
+				controller.dispatchToneAlerts(call)
 			}
 		}
 	} else {
@@ -1315,6 +1376,8 @@ func (controller *Controller) getAudioDuration(audio []byte, audioMime string) (
 		ext = ".wav"
 	} else if strings.Contains(audioMime, "ogg") {
 		ext = ".ogg"
+	} else if strings.Contains(audioMime, "flac") {
+		ext = ".flac"
 	}
 
 	tempFile := filepath.Join(tempDir, fmt.Sprintf("duration_%d%s", time.Now().UnixNano(), ext))
@@ -1545,17 +1608,24 @@ func (controller *Controller) storePendingTones(call *Call, toneSequence *ToneSe
 		controller.scheduleOrphanedToneCheck(key, call.Id, call.Timestamp.UnixMilli())
 
 		// Cross-talkgroup voice association (Scenario 2).
-		// If this talkgroup is configured to watch a different talkgroup for its voice dispatch,
-		// register a second pending-tones entry keyed by the linked talkgroup's DB ID.
-		// The mutex is still held here, so we look up the linked ID under the lock (fast PK query).
-		if call.Talkgroup.LinkedVoiceTalkgroupRef > 0 {
+		// If this talkgroup is configured to watch other talkgroups for its voice dispatch,
+		// register a pending-tones entry keyed by each linked talkgroup's DB ID. Whichever
+		// linked talkgroup carries voice first claims the tones and clears its siblings.
+		// The mutex is still held here, so we look up the linked IDs under the lock (cache hit).
+		for _, linkedRef := range call.Talkgroup.LinkedVoiceRefs() {
 			// Use cache to resolve linked talkgroup ID
-			if linkedTalkgroupId, ok := controller.IdLookupsCache.GetTalkgroupId(call.System.Id, call.Talkgroup.LinkedVoiceTalkgroupRef); ok && linkedTalkgroupId > 0 {
+			if linkedTalkgroupId, ok := controller.IdLookupsCache.GetTalkgroupId(call.System.Id, linkedRef); ok && linkedTalkgroupId > 0 {
 				windowSecs := call.Talkgroup.LinkedVoiceWindowSeconds
 				if windowSecs == 0 {
 					windowSecs = 30 // sensible default: 30-second look-forward window
 				}
 				crossKey := fmt.Sprintf("%d:%d", call.System.Id, linkedTalkgroupId)
+				if previous, exists := controller.pendingTones[crossKey]; exists && previous != nil && previous.CrossTalkgroupSourceKey != "" && previous.CrossTalkgroupSourceKey != key {
+					controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf(
+						"cross-talkgroup watch on talkgroup ref %d moved from %s to talkgroup %d (newer page)",
+						linkedRef, previous.CrossTalkgroupSourceKey, call.Talkgroup.TalkgroupRef,
+					))
+				}
 				controller.pendingTones[crossKey] = &PendingToneSequence{
 					ToneSequence:            toneSequence,
 					CallId:                  call.Id,
@@ -1565,15 +1635,16 @@ func (controller *Controller) storePendingTones(call *Call, toneSequence *ToneSe
 					WindowSeconds:           windowSecs,
 					MinVoiceDurationSeconds: call.Talkgroup.LinkedVoiceMinDurationSeconds,
 					CrossTalkgroupSourceKey: key,
+					SourceTalkgroupRef:      call.Talkgroup.TalkgroupRef,
 				}
 				controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf(
 					"cross-talkgroup watch registered: tones from talkgroup %d will attach to voice on talkgroup ref %d (id=%d) within %ds (min duration: %ds)",
-					call.Talkgroup.TalkgroupRef, call.Talkgroup.LinkedVoiceTalkgroupRef, linkedTalkgroupId, windowSecs, call.Talkgroup.LinkedVoiceMinDurationSeconds,
+					call.Talkgroup.TalkgroupRef, linkedRef, linkedTalkgroupId, windowSecs, call.Talkgroup.LinkedVoiceMinDurationSeconds,
 				))
 			} else {
 				controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf(
-					"cross-talkgroup watch: could not resolve linkedVoiceTalkgroupRef %d for talkgroup %d (not in cache)",
-					call.Talkgroup.LinkedVoiceTalkgroupRef, call.Talkgroup.TalkgroupRef,
+					"cross-talkgroup watch: could not resolve linked voice talkgroup ref %d for talkgroup %d (not in cache)",
+					linkedRef, call.Talkgroup.TalkgroupRef,
 				))
 			}
 		}
@@ -1866,16 +1937,52 @@ func (controller *Controller) clearPendingToneStack(key string, pending *Pending
 	if pending != nil && pending.CrossTalkgroupSourceKey != "" {
 		delete(controller.pendingTones, pending.CrossTalkgroupSourceKey)
 		delete(controller.pendingTones, pending.CrossTalkgroupSourceKey+":next")
+		controller.clearCrossTalkgroupWatches(pending.CrossTalkgroupSourceKey)
 	}
+	controller.clearCrossTalkgroupWatches(key)
+	controller.pendingTonesMutex.Unlock()
+
+	controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("cleared pending tones for %s after orphan alert", key))
+}
+
+// clearCrossTalkgroupWatches removes every cross-talkgroup entry registered for tones on
+// sourceKey and returns how many were removed. Caller must hold pendingTonesMutex.
+func (controller *Controller) clearCrossTalkgroupWatches(sourceKey string) int {
+	removed := 0
 	for k, p := range controller.pendingTones {
-		if p != nil && p.CrossTalkgroupSourceKey == key {
+		if p != nil && p.CrossTalkgroupSourceKey == sourceKey {
 			delete(controller.pendingTones, k)
 			delete(controller.pendingTones, k+":next")
+			removed++
 		}
 	}
-	controller.pendingTonesMutex.Unlock()
+	return removed
+}
 
-	controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("cleared pending tones for %s after orphan alert", key))
+// claimPendingTones takes the pending entry at key for a voice call. It fails when the entry
+// was already consumed by another call or when the voice call has claimed tones before.
+// Caller must hold pendingTonesMutex.
+func (controller *Controller) claimPendingTones(key string, pending *PendingToneSequence, callId uint64) bool {
+	if controller.pendingTones[key] != pending {
+		return false
+	}
+
+	now := time.Now().UnixMilli()
+	maxAge := int64(pendingToneTimeoutMinutes) * 60 * 1000
+	for id, claimedAt := range controller.toneClaims {
+		if now-claimedAt > maxAge {
+			delete(controller.toneClaims, id)
+		}
+	}
+
+	if callId > 0 {
+		if _, claimed := controller.toneClaims[callId]; claimed {
+			return false
+		}
+		controller.toneClaims[callId] = now
+	}
+	delete(controller.pendingTones, key)
+	return true
 }
 
 // checkAndAttachPendingTones checks if there are pending tones for this call's talkgroup and attaches them if this is a voice call
@@ -1990,6 +2097,19 @@ func (controller *Controller) checkAndAttachPendingTones(call *Call) bool {
 		}
 	}
 
+	controller.pendingTonesMutex.Lock()
+	claimed := controller.claimPendingTones(key, pending, call.Id)
+	controller.pendingTonesMutex.Unlock()
+	if !claimed {
+		controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("skipping pending tone attachment: voice call %d already attached to an alert, or tones from call %d were claimed by another call", call.Id, pending.CallId))
+		return false
+	}
+
+	sourceTalkgroupRef := call.Talkgroup.TalkgroupRef
+	if pending.SourceTalkgroupRef > 0 {
+		sourceTalkgroupRef = pending.SourceTalkgroupRef
+	}
+
 	// This is a voice call without its own tones - attach pending tones
 	call.ToneSequence = pending.ToneSequence
 	call.HasTones = pending.ToneSequence != nil && len(pending.ToneSequence.Tones) > 0
@@ -2024,28 +2144,35 @@ func (controller *Controller) checkAndAttachPendingTones(call *Call) bool {
 
 		// Debug log
 		if controller.DebugLogger != nil {
-			controller.DebugLogger.LogToneAttachment(call.Id, pending.CallId, call.Talkgroup.TalkgroupRef, ageMinutes, toneSetLabels)
+			controller.DebugLogger.LogToneAttachment(call.Id, pending.CallId, call.Talkgroup.TalkgroupRef, sourceTalkgroupRef, ageMinutes, toneSetLabels)
 			// Save audio file labeled as tone+voice
-			go controller.DebugLogger.SaveAudioFile(call.Id, call.Audio, call.AudioMime, "tone+voice")
+			go controller.DebugLogger.SaveAudioFile(call.Id, call.Talkgroup, call.Audio, call.AudioMime, "tone+voice")
 		}
 	}
 
 	// Persist attached tones before incident mapping reloads the call from the DB.
 	controller.updateCallToneSequence(call.Id, pending.ToneSequence)
 
-	// Clear pending tones (only attach to FIRST voice call)
+	// Pending entry was removed when claimed (only attach to FIRST voice call)
 	controller.pendingTonesMutex.Lock()
-	delete(controller.pendingTones, key)
 
 	// Check if there are "next pending" tones waiting (arrived during lock)
 	// Promote them to current pending for the next voice call
-	// For cross-talkgroup entries, also remove the source talkgroup's pending entry so that
-	// a voice call later arriving on the tone talkgroup itself does not fire a second alert.
+	// For cross-talkgroup entries, also remove the source talkgroup's pending entry and the
+	// watches on its other linked talkgroups, so voice arriving on the tone talkgroup or a
+	// sibling channel does not fire a second alert. Same-talkgroup claims likewise retire
+	// any watches this talkgroup registered on its linked channels.
 	if pending.CrossTalkgroupSourceKey != "" {
 		delete(controller.pendingTones, pending.CrossTalkgroupSourceKey)
+		siblings := controller.clearCrossTalkgroupWatches(pending.CrossTalkgroupSourceKey)
 		controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf(
-			"cross-talkgroup: cleaned up source pending entry %q after voice call %d claimed tones",
-			pending.CrossTalkgroupSourceKey, call.Id,
+			"cross-talkgroup: cleaned up source pending entry %q and %d sibling watch(es) after voice call %d claimed tones",
+			pending.CrossTalkgroupSourceKey, siblings, call.Id,
+		))
+	} else if removed := controller.clearCrossTalkgroupWatches(key); removed > 0 {
+		controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf(
+			"cross-talkgroup: retired %d linked watch(es) for %q after voice call %d claimed tones on the tone talkgroup",
+			removed, key, call.Id,
 		))
 	}
 
@@ -2075,7 +2202,7 @@ func (controller *Controller) checkAndAttachPendingTones(call *Call) bool {
 		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to get audio duration for call %d: %v", call.Id, err))
 		audioDuration = 0.0
 	}
-	controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("attached pending tones from call %d to voice call %d (talkgroup %d, age: %.2f minutes, audio: %d bytes, duration: %.2fs)", pending.CallId, call.Id, call.Talkgroup.Id, ageMinutes, len(call.Audio), audioDuration))
+	controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("attached pending tones from call %d (talkgroup ref %d) to voice call %d (talkgroup %d, age: %.2f minutes, audio: %d bytes, duration: %.2fs)", pending.CallId, sourceTalkgroupRef, call.Id, call.Talkgroup.Id, ageMinutes, len(call.Audio), audioDuration))
 
 	// Note: Do NOT trigger alerts here - alerts will be triggered after transcription completes
 	// This function may be called before transcription completes, so we wait to ensure voice exists
@@ -2298,7 +2425,7 @@ func (controller *Controller) hasMeaningfulVoiceContent(transcript string) bool
 	return len(words) >= minVoiceWordCount
 }
 
-// updateCallToneSequence persists tone sequence and hasTones on the call row.
+// updateCallToneSequence persists tone sequence, detected tones and hasTones on the call row.
 func (controller *Controller) updateCallToneSequence(callId uint64, toneSequence *ToneSequence) {
 	toneSequenceJson, err := SerializeToneSequence(toneSequence)
 	if err != nil {
@@ -2312,12 +2439,14 @@ func (controller *Controller) updateCallToneSequence(callId uint64, toneSequence
 
 	hasTones := toneSequence != nil && len(toneSequence.Tones) > 0
 
-	query := fmt.Sprintf(`UPDATE "calls" SET "toneSequence" = $1, "hasTones" = %t WHERE "callId" = %d`, hasTones, callId)
+	detectedTonesJson := serializeDetectedTones(toneSequence)
+
+	query := fmt.Sprintf(`UPDATE "calls" SET "toneSequence" = $1, "detectedTones" = $2, "hasTones" = %t WHERE "callId" = %d`, hasTones, callId)
 	if controller.Database.Config.DbType == DbTypePostgresql {
-		_, err = controller.Database.Sql.Exec(query, toneSequenceJson)
+		_, err = controller.Database.Sql.Exec(query, toneSequenceJson, detectedTonesJson)
 	} else {
-		query = fmt.Sprintf(`UPDATE "calls" SET "toneSequence" = ?, "hasTones" = %t WHERE "callId" = %d`, hasTones, callId)
-		_, err = controller.Database.Sql.Exec(query, toneSequenceJson)
+		query = fmt.Sprintf(`UPDATE "calls" SET "toneSequence" = ?, "detectedTones" = ?, "hasTones" = %t WHERE "callId" = %d`, hasTones, callId)
+		_, err = controller.Database.Sql.Exec(query, toneSequenceJson, detectedTonesJson)
 	}
 
 	if err != nil {
@@ -2411,7 +2540,7 @@ func (controller *Controller) storeWaitingShortCall(call *Call, pending *Pending
 
 		// Trigger tone alerts for the short call
 		if shortCall.System != nil && shortCall.System.AlertsEnabled && shortCall.Talkgroup != nil && shortCall.Talkgroup.AlertsEnabled {
-			go controller.AlertEngine.TriggerToneAlerts(shortCall)
+			controller.dispatchToneAlerts(shortCall)
 		}
 	})
 
@@ -3409,6 +3538,9 @@ func (controller *Controller) Start() error {
 
 	// Start reconnection manager cleanup routine
 	if controller.ReconnectionMgr != nil {
+		if err := controller.ReconnectionMgr.Load(controller.Config.GetPath(reconnectionStateFile)); err != nil {
+			log.Printf("[ReconnectionManager] Failed to restore persisted states: %v", err)
+		}
 		controller.ReconnectionMgr.StartCleanup()
 		controller.Logs.LogEvent(LogLevelInfo, "Reconnection manager started")
 	}
@@ -3422,6 +3554,9 @@ func (controller *Controller) Start() error {
 	// Start auto-updater (no-op if auto_update = false in ini)
 	controller.Updater.Start()
 
+	// Start the MQTT publisher (no-op without mqtt_broker in the ini)
+	controller.Mqtt.Start()
+
 	// Purge any duplicate rows saved before duplicates were dropped at ingest.
 	// Runs once in the background at startup; deletes in small batches to avoid locking.
 	go controller.purgeLegacyDuplicates()
@@ -3452,7 +3587,9 @@ func (controller *Controller) Start() error {
 				case call := <-controller.Ingest:
 					if call != nil {
 						startTime := time.Now()
+						controller.ingestActive.Add(1)
 						controller.IngestCall(call)
+						controller.ingestActive.Add(-1)
 						processTime := time.Since(startTime)
 
 						controller.workerStats.Lock()
@@ -3620,10 +3757,11 @@ func (controller *Controller) readAllData() error {
 		}
 	}
 
-	wg.Add(17)
+	wg.Add(18)
 	go readFunc(func() error { return controller.Apikeys.Read(controller.Database) }, "apikeys")
 	go readFunc(func() error { return controller.Dirwatches.Read(controller.Database) }, "dirwatches")
 	go readFunc(func() error { return controller.Downstreams.Read(controller.Database) }, "downstreams")
+	go readFunc(func() error { return controller.Webhooks.Read(controller.Database) }, "webhooks")
 	go readFunc(func() error { return controller.Groups.Read(controller.Database) }, "groups")
 	go readFunc(func() error { return controller.Options.Read(controller.Database) }, "options")
 	go readFunc(func() error {
@@ -3657,6 +3795,13 @@ func (controller *Controller) readAllData() error {
 	// Check for duplicate emails and log them
 	controller.checkDuplicateEmails()
 
+	// Collapse device tokens re-registered under new rows so devices aren't pushed twice
+	if removed, err := controller.DeviceTokens.DedupeTokens(controller.Database); err != nil {
+		controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("device tokens: dedupe failed: %v", err))
+	} else if removed > 0 {
+		controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("device tokens: collapsed %d duplicate token(s)", removed))
+	}
+
 	// Update reconnection manager settings from options
 	if controller.ReconnectionMgr != nil {
 		controller.ReconnectionMgr.HoldDuration = time.Duration(controller.Options.ReconnectionGracePeriod) * time.Second
@@ -3702,6 +3847,24 @@ func (controller *Controller) userHasAccess(user *User, call *Call) bool {
 	return user.HasAccess(call)
 }
 
+// userInAlertGroups reports whether a user belongs to one of the user groups an
+// alert is routed to. An empty group list routes to every user.
+func (controller *Controller) userInAlertGroups(userId uint64, groupIds []uint64) bool {
+	if len(groupIds) == 0 {
+		return true
+	}
+	user := controller.Users.GetUserById(userId)
+	if user == nil || user.UserGroupId == 0 {
+		return false
+	}
+	for _, groupId := range groupIds {
+		if groupId == user.UserGroupId {
+			return true
+		}
+	}
+	return false
+}
+
 func (controller *Controller) userEligibleForTalkgroupAlert(userId uint64, call *Call) bool {
 	if call == nil || call.System == nil || call.Talkgroup == nil {
 		return false
@@ -3788,6 +3951,31 @@ func (controller *Controller) userEffectiveConnectionLimit(user *User) uint {
 	return user.ConnectionLimit
 }
 
+// enforceUserConnectionLimit disconnects the user's oldest sessions beyond
+// their effective connection limit, so a lowered limit applies immediately
+// instead of on the next reconnect.  It returns the number disconnected.
+func (controller *Controller) enforceUserConnectionLimit(user *User, reason string) int {
+	limit := controller.userEffectiveConnectionLimit(user)
+	if limit == 0 {
+		return 0
+	}
+
+	excess := controller.Clients.ExcessUserSessions(user.Id, limit)
+	for _, client := range excess {
+		msg := &Message{Command: MessageCommandError, Payload: reason}
+		select {
+		case client.Send <- msg:
+		default:
+		}
+		controller.Unregister <- client
+	}
+
+	if len(excess) > 0 {
+		controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("disconnected %d session(s) for %s over the connection limit of %d", len(excess), user.Email, limit))
+	}
+	return len(excess)
+}
+
 func (controller *Controller) fetchRadioReferenceAPIKey() {
 	relayServerURL := getRelayServerURL()
 
@@ -3986,6 +4174,11 @@ func (controller *Controller) Terminate() {
 	controller.apikeyNoAudioMonitorStarted = nil
 	controller.apikeyNoAudioMonitorStopsMu.Unlock()
 
+	// Persist reconnection states so buffered calls survive the restart
+	if controller.ReconnectionMgr != nil {
+		controller.ReconnectionMgr.Stop()
+	}
+
 	// Stop auto-updater background goroutine
 	if controller.Updater != nil {
 		controller.Updater.Stop()
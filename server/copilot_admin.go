@@ -153,7 +153,7 @@ func (admin *Admin) copilotToolGetAdminConfig(argsJSON string) (string, error) {
 		for _, list := range admin.Controller.KeywordListsCache.GetAllLists() {
 			lists = append(lists, map[string]any{
 				"id": list.Id, "label": list.Label, "description": list.Description,
-				"keywords": list.Keywords, "order": list.Order,
+				"keywords": list.Keywords, "order": list.Order, "userGroupIds": list.UserGroupIds,
 			})
 		}
 		out = map[string]any{"keywordLists": lists}
@@ -652,6 +652,12 @@ func (admin *Admin) copilotSaveSystem(payloadJSON []byte) error {
 		if _, has := incoming["duplicateDetectionEnabled"]; !has {
 			incoming["duplicateDetectionEnabled"] = existing.DuplicateDetectionEnabled
 		}
+		if _, has := incoming["audioFormat"]; !has {
+			incoming["audioFormat"] = existing.AudioFormat
+		}
+		if _, has := incoming["audioBitrate"]; !has {
+			incoming["audioBitrate"] = float64(existing.AudioBitrate)
+		}
 
 		if tgs, ok := incoming["talkgroups"].([]any); ok {
 			for _, tr := range tgs {
@@ -1116,10 +1122,10 @@ func (admin *Admin) copilotUpdateUser(payloadJSON []byte) (map[string]any, error
 	}
 	if request.Pin != nil {
 		pinValue := strings.TrimSpace(*request.Pin)
-		if pinValue != "" && !admin.Controller.Users.IsPinAvailable(pinValue, user.Id) {
-			return nil, fmt.Errorf("PIN already in use")
-		}
-		if pinValue != "" {
+		if pinValue != "" && pinValue != user.Pin {
+			if err := admin.Controller.Users.ValidatePin(pinValue, user.Id, admin.Controller.Config.PinMinLength); err != nil {
+				return nil, err
+			}
 			user.Pin = pinValue
 		}
 	}
@@ -1217,9 +1223,13 @@ func (admin *Admin) copilotCreateKeywordList(payloadJSON []byte) (map[string]any
 	if v, ok := list["order"].(float64); ok {
 		order = uint(v)
 	}
+	if err := ValidateKeywords(keywords); err != nil {
+		return nil, err
+	}
 	keywordsJSON, _ := json.Marshal(keywords)
-	query := fmt.Sprintf(`INSERT INTO "keywordLists" ("label", "description", "keywords", "order", "createdAt") VALUES ('%s', '%s', '%s', %d, %d) RETURNING "keywordListId"`,
-		escapeQuotes(label), escapeQuotes(description), escapeQuotes(string(keywordsJSON)), order, time.Now().UnixMilli())
+	userGroupIdsJSON, _ := json.Marshal(parseUserGroupIds(list["userGroupIds"]))
+	query := fmt.Sprintf(`INSERT INTO "keywordLists" ("label", "description", "keywords", "order", "createdAt", "userGroupIds") VALUES ('%s', '%s', '%s', %d, %d, '%s') RETURNING "keywordListId"`,
+		escapeQuotes(label), escapeQuotes(description), escapeQuotes(string(keywordsJSON)), order, time.Now().UnixMilli(), escapeQuotes(string(userGroupIdsJSON)))
 	var listId uint64
 	if err := admin.Controller.Database.Sql.QueryRow(query).Scan(&listId); err != nil {
 		return nil, err
@@ -1262,9 +1272,13 @@ func (admin *Admin) copilotUpdateKeywordList(payloadJSON []byte) (map[string]any
 	if v, ok := list["order"].(float64); ok {
 		order = uint(v)
 	}
+	if err := ValidateKeywords(keywords); err != nil {
+		return nil, err
+	}
 	keywordsJSON, _ := json.Marshal(keywords)
-	query := fmt.Sprintf(`UPDATE "keywordLists" SET "label" = '%s', "description" = '%s', "keywords" = '%s', "order" = %d WHERE "keywordListId" = %d`,
-		escapeQuotes(label), escapeQuotes(description), escapeQuotes(string(keywordsJSON)), order, req.ID)
+	userGroupIdsJSON, _ := json.Marshal(parseUserGroupIds(list["userGroupIds"]))
+	query := fmt.Sprintf(`UPDATE "keywordLists" SET "label" = '%s', "description" = '%s', "keywords" = '%s', "order" = %d, "userGroupIds" = '%s' WHERE "keywordListId" = %d`,
+		escapeQuotes(label), escapeQuotes(description), escapeQuotes(string(keywordsJSON)), order, escapeQuotes(string(userGroupIdsJSON)), req.ID)
 	if _, err := admin.Controller.Database.Sql.Exec(query); err != nil {
 		return nil, err
 	}
@@ -325,7 +325,7 @@ func (admin *Admin) copilotRadioReferenceBrowse(payload map[string]any) (map[str
 }
 
 func (admin *Admin) copilotConfigReload() error {
-	if err := admin.Controller.Options.Read(admin.Controller.Database); err != nil {
+	if _, err := admin.Controller.ReloadConfig("copilot"); err != nil {
 		return err
 	}
 	admin.copilotFinishWrite("config_reload")
@@ -0,0 +1,107 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"net/http"
+	"strings"
+)
+
+// corsExcludedPrefixes are never opened to other origins, whatever the
+// configuration: the admin and group admin APIs and everything Central
+// Management or the relay calls server-to-server.
+var corsExcludedPrefixes = []string{
+	"/api/admin",
+	"/api/group-admin/",
+	"/api/webhook/",
+	"/api/central-management/",
+}
+
+// parseCorsOrigins splits the cors_allowed_origins INI value.  Origins are
+// compared without a trailing slash; "*" allows any origin.
+func parseCorsOrigins(value string) []string {
+	origins := []string{}
+	for _, origin := range strings.Split(value, ",") {
+		if origin = strings.TrimRight(strings.TrimSpace(origin), "/"); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// ApiCorsMiddleware lets browsers on the configured origins call the API.
+// With no origins configured it returns next unchanged, so the API stays
+// same-origin only.  Requests from other origins and to excluded paths pass
+// through without CORS headers; browsers then block them as before.
+//
+// Endpoints that already allow every origin for the Central Management
+// frontend (corsMiddleware in main.go) keep doing so.
+func ApiCorsMiddleware(origins []string, next http.Handler) http.Handler {
+	if len(origins) == 0 {
+		return next
+	}
+
+	allowAll := false
+	allowed := map[string]bool{}
+	for _, origin := range origins {
+		if origin == "*" {
+			allowAll = true
+		}
+		allowed[strings.ToLower(origin)] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || !corsPathAllowed(r.URL.Path) || !(allowAll || allowed[strings.ToLower(strings.TrimRight(origin, "/"))]) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h := w.Header()
+		h.Add("Vary", "Origin")
+		if allowAll {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		h.Set("Access-Control-Expose-Headers", "Content-Length, Content-Disposition, Retry-After")
+
+		// Preflight
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			h.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+			h.Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+			h.Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func corsPathAllowed(path string) bool {
+	if !strings.HasPrefix(path, "/api/") {
+		return false
+	}
+	for _, prefix := range corsExcludedPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return false
+		}
+	}
+	return true
+}
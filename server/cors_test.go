@@ -0,0 +1,56 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestApiCorsMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	handler := ApiCorsMiddleware(parseCorsOrigins(" https://dash.example.org/ , https://app.example.org"), next)
+
+	serve := func(method, path, origin string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, nil)
+		r.Header.Set("Origin", origin)
+		if method == http.MethodOptions {
+			r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		return rec
+	}
+
+	rec := serve(http.MethodGet, "/api/calls", "https://dash.example.org")
+	if rec.Code != http.StatusTeapot || rec.Header().Get("Access-Control-Allow-Origin") != "https://dash.example.org" {
+		t.Fatalf("allowed origin: %d %q", rec.Code, rec.Header().Get("Access-Control-Allow-Origin"))
+	}
+
+	rec = serve(http.MethodOptions, "/api/calls", "https://app.example.org")
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Access-Control-Allow-Methods") == "" {
+		t.Fatalf("preflight: %d %v", rec.Code, rec.Header())
+	}
+
+	for _, tc := range []struct{ path, origin string }{
+		{"/api/calls", "https://evil.example.com"},
+		{"/api/admin/config", "https://dash.example.org"},
+		{"/api/admin", "https://dash.example.org"},
+		{"/api/group-admin/users", "https://dash.example.org"},
+		{"/api/webhook/central-user-grant", "https://dash.example.org"},
+		{"/api/central-management/pair", "https://dash.example.org"},
+		{"/index.html", "https://dash.example.org"},
+	} {
+		rec := serve(http.MethodOptions, tc.path, tc.origin)
+		if rec.Code != http.StatusTeapot || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Fatalf("%s from %s got CORS: %d %v", tc.path, tc.origin, rec.Code, rec.Header())
+		}
+	}
+
+	if h := ApiCorsMiddleware(nil, next); h == nil {
+		t.Fatal("nil handler")
+	}
+}
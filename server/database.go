@@ -22,6 +22,7 @@ import (
 	"log"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
@@ -30,6 +31,9 @@ import (
 type Database struct {
 	Config *Config
 	Sql    *sql.DB
+
+	maintenanceMutex sync.Mutex
+	maintenanceJob   string
 }
 
 func NewDatabase(config *Config) *Database {
@@ -252,6 +256,11 @@ func (db *Database) migrate() error {
 		return formatError(err, "")
 	}
 
+	// Migrate userGroups per-group reconnection overrides
+	if err := migrateUserGroupsReconnection(db); err != nil {
+		return formatError(err, "")
+	}
+
 	// Migrate userGroups pricingOptions column
 	if err := migrateUserGroupsPricingOptions(db); err != nil {
 		return formatError(err, "")
@@ -287,6 +296,11 @@ func (db *Database) migrate() error {
 		return formatError(err, "")
 	}
 
+	// Migrate users modifiedAt column (incremental Central Management sync)
+	if err := migrateUserModifiedAt(db); err != nil {
+		return formatError(err, "")
+	}
+
 	// Migrate transferRequests approval token columns
 	if err := migrateTransferRequestsApprovalTokens(db); err != nil {
 		return formatError(err, "")
@@ -369,6 +383,7 @@ func (db *Database) migrate() error {
 		fn   func(*Database) error
 	}{
 		{"migrateLogsCategory", migrateLogsCategory},
+		{"migrateLogsContext", migrateLogsContext},
 		{"migrateCallUnitsLabel", migrateCallUnitsLabel},
 		{"migrateAlertCooldown", migrateAlertCooldown},
 		{"migrateLinkedVoiceTalkgroup", migrateLinkedVoiceTalkgroup},
@@ -395,11 +410,27 @@ func (db *Database) migrate() error {
 		{"migratePostgresSiteRefToText", migratePostgresSiteRefToText},
 		{"migrateApikeyNoAudioMonitoring", migrateApikeyNoAudioMonitoring},
 		{"migrateRetentionDays", migrateRetentionDays},
+		{"migrateTalkgroupAllowDebugAudio", migrateTalkgroupAllowDebugAudio},
 		{"migrateSystemDuplicateDetection", migrateSystemDuplicateDetection},
 		{"migrateUserAlertPushPreferences", migrateUserAlertPushPreferences},
 		{"migrateIncidentMapping", migrateIncidentMapping},
 		{"migrateCallNatures", migrateCallNatures},
 		{"migrateKeywordAlertUnique", migrateKeywordAlertUnique},
+		{"migrateTalkgroupVoiceCaptureWindow", migrateTalkgroupVoiceCaptureWindow},
+		{"migrateTalkgroupLinkedVoiceRefs", migrateTalkgroupLinkedVoiceRefs},
+		{"migrateKeywordListUserGroups", migrateKeywordListUserGroups},
+		{"migrateCallDetectedTones", migrateCallDetectedTones},
+		{"migrateTranscriptionDeadLetters", migrateTranscriptionDeadLetters},
+		{"migrateTalkgroupTranscriptionLanguage", migrateTalkgroupTranscriptionLanguage},
+		{"migrateUserGroupDefaultLivefeedTags", migrateUserGroupDefaultLivefeedTags},
+		{"migrateTalkgroupReconnectionPriority", migrateTalkgroupReconnectionPriority},
+		{"migrateTalkgroupEncrypted", migrateTalkgroupEncrypted},
+		{"migrateDeviceTokenSubscription", migrateDeviceTokenSubscription},
+		{"migrateDeviceTokenQuietHours", migrateDeviceTokenQuietHours},
+		{"migrateWebhooks", migrateWebhooks},
+		{"migrateCallAudioKey", migrateCallAudioKey},
+		{"migrateSystemAudioOverrides", migrateSystemAudioOverrides},
+		{"migrateTranscriptSearchIndex", migrateTranscriptSearchIndex},
 	}
 	for _, step := range lateSteps {
 		if err := db.runMigrationStep(step.name, step.fn); err != nil {
@@ -0,0 +1,148 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dbMaintenanceTables are the tables pruning deletes from every hour. Autovacuum
+// keeps up with them slowly on busy servers, so they also get a scheduled VACUUM.
+var dbMaintenanceTables = []string{"calls", "logs"}
+
+// DbMaintenanceWindow is a range of local hours, [Start, End), during which
+// scheduled maintenance may start. End may be lower than Start to wrap past midnight.
+type DbMaintenanceWindow struct {
+	Start int
+	End   int
+}
+
+func parseDbMaintenanceWindow(s string) (DbMaintenanceWindow, error) {
+	var window DbMaintenanceWindow
+
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) != 2 {
+		return window, fmt.Errorf("invalid maintenance window %q, expected start-end hours such as 3-5", s)
+	}
+
+	for i, part := range parts {
+		hour, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || hour < 0 || hour > 24 {
+			return window, fmt.Errorf("invalid maintenance window hour %q", part)
+		}
+		if i == 0 {
+			window.Start = hour % 24
+		} else {
+			window.End = hour % 24
+		}
+	}
+
+	if window.Start == window.End {
+		return window, fmt.Errorf("maintenance window %q is empty", s)
+	}
+
+	return window, nil
+}
+
+func (window DbMaintenanceWindow) Contains(t time.Time) bool {
+	hour := t.Hour()
+	if window.Start < window.End {
+		return hour >= window.Start && hour < window.End
+	}
+	return hour >= window.Start || hour < window.End
+}
+
+func (window DbMaintenanceWindow) String() string {
+	return fmt.Sprintf("%d-%d", window.Start, window.End)
+}
+
+// TryLockMaintenance reserves the database for a long-running maintenance job
+// such as a VACUUM or a bulk audio conversion, so two of them never overlap.
+// It returns the job already holding the reservation when it fails.
+func (db *Database) TryLockMaintenance(job string) (bool, string) {
+	db.maintenanceMutex.Lock()
+	defer db.maintenanceMutex.Unlock()
+
+	if db.maintenanceJob != "" {
+		return false, db.maintenanceJob
+	}
+	db.maintenanceJob = job
+	return true, ""
+}
+
+func (db *Database) UnlockMaintenance() {
+	db.maintenanceMutex.Lock()
+	db.maintenanceJob = ""
+	db.maintenanceMutex.Unlock()
+}
+
+func (db *Database) relationSize(table string) (int64, error) {
+	var size int64
+	err := db.Sql.QueryRow(`SELECT pg_total_relation_size($1::regclass)`, table).Scan(&size)
+	return size, err
+}
+
+// lastVacuum returns the most recent manual VACUUM of any maintenance table, so a
+// restart inside the window does not repeat a run that has already happened.
+func (db *Database) lastVacuum() time.Time {
+	var last sql.NullTime
+	if err := db.Sql.QueryRow(`SELECT MAX("last_vacuum") FROM "pg_stat_user_tables" WHERE "relname" = ANY(string_to_array($1, ','))`, strings.Join(dbMaintenanceTables, ",")).Scan(&last); err != nil || !last.Valid {
+		return time.Time{}
+	}
+	return last.Time
+}
+
+// dbMaintenanceDue reports whether the scheduled VACUUM should start at now.
+func (scheduler *Scheduler) dbMaintenanceDue(now time.Time) bool {
+	config := scheduler.Controller.Config
+	if config.DbMaintenanceInterval == 0 || !config.DbMaintenanceWindow.Contains(now) {
+		return false
+	}
+
+	if scheduler.lastDbMaintenance.IsZero() {
+		scheduler.lastDbMaintenance = scheduler.Controller.Database.lastVacuum()
+	}
+
+	// Allow a few hours of slack so a weekly run does not drift out of its window.
+	interval := 24*time.Hour*time.Duration(config.DbMaintenanceInterval) - 6*time.Hour
+	return now.Sub(scheduler.lastDbMaintenance) >= interval
+}
+
+// maintainDatabase runs VACUUM (ANALYZE) on the pruned tables and logs how much
+// space each one gave back.
+func (scheduler *Scheduler) maintainDatabase() {
+	db := scheduler.Controller.Database
+	logs := scheduler.Controller.Logs
+
+	if ok, job := db.TryLockMaintenance("vacuum"); !ok {
+		logs.LogEvent(LogLevelWarn, fmt.Sprintf("database maintenance skipped: %s in progress", job))
+		return
+	}
+	defer db.UnlockMaintenance()
+
+	for _, table := range dbMaintenanceTables {
+		before, err := db.relationSize(table)
+		if err != nil {
+			logs.LogEvent(LogLevelError, fmt.Sprintf("database maintenance: size of %s: %v", table, err))
+			continue
+		}
+
+		started := time.Now()
+		if _, err := db.Sql.Exec(fmt.Sprintf(`VACUUM (ANALYZE) "%s"`, table)); err != nil {
+			logs.LogEvent(LogLevelError, fmt.Sprintf("database maintenance: vacuum %s: %v", table, err))
+			continue
+		}
+
+		after, err := db.relationSize(table)
+		if err != nil {
+			after = before
+		}
+		reclaimed := max(before-after, 0)
+
+		logs.LogEvent(LogLevelInfo, fmt.Sprintf("database maintenance: vacuumed %s in %s, %s -> %s (reclaimed %s)", table, time.Since(started).Round(time.Second), formatBytes(int(before)), formatBytes(int(after)), formatBytes(int(reclaimed))))
+	}
+}
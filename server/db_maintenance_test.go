@@ -0,0 +1,49 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDbMaintenanceWindow(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2025, 1, 1, hour, 30, 0, 0, time.Local) }
+
+	window, err := parseDbMaintenanceWindow(" 3-5 ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if window.Contains(at(2)) || !window.Contains(at(3)) || !window.Contains(at(4)) || window.Contains(at(5)) {
+		t.Fatalf("window %s has the wrong hours", window)
+	}
+
+	wrapped, err := parseDbMaintenanceWindow("22-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !wrapped.Contains(at(23)) || !wrapped.Contains(at(1)) || wrapped.Contains(at(2)) || wrapped.Contains(at(12)) {
+		t.Fatalf("window %s does not wrap past midnight", wrapped)
+	}
+
+	for _, bad := range []string{"", "3", "3-3", "0-24", "a-5", "3-25"} {
+		if _, err := parseDbMaintenanceWindow(bad); err == nil {
+			t.Fatalf("window %q accepted", bad)
+		}
+	}
+}
+
+func TestDatabaseMaintenanceLock(t *testing.T) {
+	db := &Database{}
+
+	if ok, _ := db.TryLockMaintenance("vacuum"); !ok {
+		t.Fatal("first lock failed")
+	}
+	if ok, job := db.TryLockMaintenance("opus migration"); ok || job != "vacuum" {
+		t.Fatalf("second lock = %v, %q; want false, vacuum", ok, job)
+	}
+	db.UnlockMaintenance()
+	if ok, _ := db.TryLockMaintenance("opus migration"); !ok {
+		t.Fatal("lock not released")
+	}
+}
@@ -289,20 +289,22 @@ func (controller *Controller) CallsAudioHandler(w http.ResponseWriter, r *http.R
 	defer cancel()
 
 	var audio []byte
-	var mime string
-	query := fmt.Sprintf(`SELECT "audio", "audioMime" FROM "calls" WHERE "callId" = %d`, id)
-	if err := controller.Database.Sql.QueryRowContext(ctx, query).Scan(&audio, &mime); err != nil {
+	var key, mime string
+	query := fmt.Sprintf(`SELECT "audio", "audioKey", "audioMime" FROM "calls" WHERE "callId" = %d`, id)
+	if err := controller.Database.Sql.QueryRowContext(ctx, query).Scan(&audio, &key, &mime); err != nil {
 		http.NotFound(w, r)
 		return
 	}
+	audio, err = controller.loadCallAudio(audio, key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
 
 	if mime == "" {
 		mime = "audio/mp4"
 	}
-	w.Header().Set("Content-Type", mime)
-	w.Header().Set("Content-Length", strconv.Itoa(len(audio)))
-	w.Header().Set("Accept-Ranges", "bytes")
-	w.Write(audio)
+	serveCallAudio(w, r, id, audio, mime, time.Time{})
 }
 
 func hashBadge(h string) string {
@@ -19,16 +19,27 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 )
 
+// debugLogGenerations is how many rotated copies (.1 … .N) of a debug log are kept.
+const debugLogGenerations = 3
+
 // DebugLogger handles writing debug logs to a dedicated file
 type DebugLogger struct {
 	file     *os.File
 	mutex    sync.Mutex
 	audioDir string // Directory to save debug audio files
 	closed   bool   // Flag to prevent writes after close
+
+	filename      string
+	size          int64
+	maxSize       int64 // Rotate the log past this many bytes (0 = unlimited)
+	maxAudioBytes int64 // Total bytes kept in audioDir (0 = unlimited)
+	audioMutex    sync.Mutex
+	audioEnabled  bool // Global switch; talkgroups must also opt in via AllowDebugAudio
 }
 
 // TranscriptionDebugLogger handles writing transcription tone removal debug logs
@@ -36,11 +47,45 @@ type TranscriptionDebugLogger struct {
 	file   *os.File
 	mutex  sync.Mutex
 	closed bool
+
+	filename string
+	size     int64
+	maxSize  int64
 }
 
-// NewDebugLogger creates a new debug logger that writes to tone-keyword-debug.log
-func NewDebugLogger(filename string) (*DebugLogger, error) {
+// openDebugLogFile opens filename for appending and returns its current size.
+func openDebugLogFile(filename string) (*os.File, int64, error) {
 	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, 0, err
+	}
+	var size int64
+	if info, err := file.Stat(); err == nil {
+		size = info.Size()
+	}
+	return file, size, nil
+}
+
+// rotateDebugLogFile closes file, shifts filename.1 … filename.N-1 up one
+// generation (dropping the oldest), moves filename to filename.1 and opens a
+// fresh file in its place.
+func rotateDebugLogFile(file *os.File, filename string) (*os.File, error) {
+	file.Close()
+	for i := debugLogGenerations - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", filename, i), fmt.Sprintf("%s.%d", filename, i+1))
+	}
+	if err := os.Rename(filename, filename+".1"); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	file, _, err := openDebugLogFile(filename)
+	return file, err
+}
+
+// NewDebugLogger creates a new debug logger that writes to tone-keyword-debug.log.
+// maxSize and maxAudioBytes cap the log file and the debug-audio directory (0 = no cap);
+// audioEnabled false keeps textual logging but never writes call audio.
+func NewDebugLogger(filename string, maxSize int64, maxAudioBytes int64, audioEnabled bool) (*DebugLogger, error) {
+	file, size, err := openDebugLogFile(filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open debug log file: %v", err)
 	}
@@ -53,15 +98,24 @@ func NewDebugLogger(filename string) (*DebugLogger, error) {
 	}
 
 	logger := &DebugLogger{
-		file:     file,
-		mutex:    sync.Mutex{},
-		audioDir: audioDir,
+		file:          file,
+		mutex:         sync.Mutex{},
+		audioDir:      audioDir,
+		filename:      filename,
+		size:          size,
+		maxSize:       maxSize,
+		maxAudioBytes: maxAudioBytes,
+		audioEnabled:  audioEnabled,
 	}
 
 	// Write header on startup
 	logger.WriteLog("=================================================")
 	logger.WriteLog("Tone & Keyword Debug Log - Server Started")
-	logger.WriteLog(fmt.Sprintf("Audio files will be saved to: %s/", audioDir))
+	if audioEnabled {
+		logger.WriteLog(fmt.Sprintf("Audio files will be saved to: %s/ (talkgroups with debug audio allowed only)", audioDir))
+	} else {
+		logger.WriteLog("Audio saving disabled (debug_audio_enabled = false)")
+	}
 	logger.WriteLog("=================================================")
 
 	return logger, nil
@@ -77,25 +131,47 @@ func (d *DebugLogger) WriteLog(message string) {
 		return
 	}
 
+	if d.maxSize > 0 && d.size >= d.maxSize {
+		file, err := rotateDebugLogFile(d.file, d.filename)
+		if err != nil {
+			d.file = nil
+			writeLogStdout(fmt.Sprintf("debug log rotation failed for %s: %v", d.filename, err))
+			return
+		}
+		d.file = file
+		d.size = 0
+	}
+
 	timestamp := time.Now().Format("2006-01-02 15:04:05.000")
 	logLine := fmt.Sprintf("[%s] %s\n", timestamp, message)
 
-	d.file.WriteString(logLine)
+	n, _ := d.file.WriteString(logLine)
+	d.size += int64(n)
 	d.file.Sync() // Flush to disk immediately
 }
 
 // LogToneDetection logs tone detection events
 func (d *DebugLogger) LogToneDetection(callId uint64, systemId uint64, talkgroupRef uint, message string) {
-	d.WriteLog(fmt.Sprintf("[TONE] Call=%d System=%d Talkgroup=%d | %s", callId, systemId, talkgroupRef, message))
+	d.WriteLog(formatToneDetectionLog(callId, systemId, talkgroupRef, message))
 }
 
 // LogToneFrequency logs detected tone frequencies
 func (d *DebugLogger) LogToneFrequency(callId uint64, frequency float64, duration float64, matched bool, toneSetLabel string) {
+	d.WriteLog(formatToneFrequencyLog(callId, frequency, duration, matched, toneSetLabel))
+}
+
+// formatToneDetectionLog formats a [TONE] line; shared with the tone replay endpoint.
+func formatToneDetectionLog(callId uint64, systemId uint64, talkgroupRef uint, message string) string {
+	return fmt.Sprintf("[TONE] Call=%d System=%d Talkgroup=%d | %s", callId, systemId, talkgroupRef, message)
+}
+
+// formatToneFrequencyLog formats a [TONE_FREQ] line; shared with the tone replay endpoint.
+func formatToneFrequencyLog(callId uint64, frequency float64, duration float64, matched bool, toneSetLabel string) string {
 	status := "NO_MATCH"
 	if matched {
 		status = fmt.Sprintf("MATCHED: %s", toneSetLabel)
 	}
-	d.WriteLog(fmt.Sprintf("[TONE_FREQ] Call=%d | %.1f Hz for %.2fs - %s", callId, frequency, duration, status))
+	return fmt.Sprintf("[TONE_FREQ] Call=%d | %.1f Hz for %.2fs - %s", callId, frequency, duration, status)
 }
 
 // LogPendingTones logs pending tone operations
@@ -116,13 +192,13 @@ func (d *DebugLogger) LogVoiceDetection(callId uint64, transcript string, isVoic
 	d.WriteLog(fmt.Sprintf("[VOICE_CHECK] Call=%d | Status=%s | Reason: %s | Transcript: %q", callId, status, reason, transcriptPreview))
 }
 
-// LogKeywordMatch logs keyword detection
-func (d *DebugLogger) LogKeywordMatch(callId uint64, keyword string, transcript string) {
+// LogKeywordMatch logs keyword detection along with the matching mode used
+func (d *DebugLogger) LogKeywordMatch(callId uint64, keyword string, mode string, transcript string) {
 	transcriptPreview := transcript
 	if len(transcriptPreview) > 100 {
 		transcriptPreview = transcriptPreview[:100] + "..."
 	}
-	d.WriteLog(fmt.Sprintf("[KEYWORD] Call=%d | Matched: %q | Transcript: %q", callId, keyword, transcriptPreview))
+	d.WriteLog(fmt.Sprintf("[KEYWORD] Call=%d | Matched: %q | Mode=%s | Transcript: %q", callId, keyword, mode, transcriptPreview))
 }
 
 // LogAlert logs alert creation
@@ -131,12 +207,30 @@ func (d *DebugLogger) LogAlert(alertType string, callId uint64, systemId uint64,
 }
 
 // LogToneAttachment logs when pending tones are attached to a voice call
-func (d *DebugLogger) LogToneAttachment(voiceCallId uint64, toneCallId uint64, talkgroupRef uint, ageMinutes float64, toneSetLabels []string) {
+func (d *DebugLogger) LogToneAttachment(voiceCallId uint64, toneCallId uint64, talkgroupRef uint, sourceTalkgroupRef uint, ageMinutes float64, toneSetLabels []string) {
+	if sourceTalkgroupRef != 0 && sourceTalkgroupRef != talkgroupRef {
+		d.WriteLog(fmt.Sprintf("[ATTACH] Voice Call=%d got pending tones from Tone Call=%d | Voice Talkgroup=%d Tone Talkgroup=%d Age=%.2f min | ToneSets: %v", voiceCallId, toneCallId, talkgroupRef, sourceTalkgroupRef, ageMinutes, toneSetLabels))
+		return
+	}
 	d.WriteLog(fmt.Sprintf("[ATTACH] Voice Call=%d got pending tones from Tone Call=%d | Talkgroup=%d Age=%.2f min | ToneSets: %v", voiceCallId, toneCallId, talkgroupRef, ageMinutes, toneSetLabels))
 }
 
-// SaveAudioFile saves call audio to the debug directory
-func (d *DebugLogger) SaveAudioFile(callId uint64, audioData []byte, mimeType string, callType string) error {
+// SaveAudioFile saves call audio to the debug directory. Audio is only written
+// when saving is enabled globally and the talkgroup has opted in; otherwise a
+// redaction notice is logged instead.
+func (d *DebugLogger) SaveAudioFile(callId uint64, talkgroup *Talkgroup, audioData []byte, mimeType string, callType string) error {
+	if !d.audioEnabled {
+		return nil
+	}
+	if talkgroup == nil || !talkgroup.AllowDebugAudio {
+		talkgroupRef := uint(0)
+		if talkgroup != nil {
+			talkgroupRef = talkgroup.TalkgroupRef
+		}
+		d.WriteLog(fmt.Sprintf("[AUDIO_REDACTED] Call=%d Type=%s Talkgroup=%d | Debug audio not allowed for this talkgroup, not saved", callId, callType, talkgroupRef))
+		return nil
+	}
+
 	if len(audioData) == 0 {
 		return fmt.Errorf("no audio data to save")
 	}
@@ -171,9 +265,64 @@ func (d *DebugLogger) SaveAudioFile(callId uint64, audioData []byte, mimeType st
 
 	// Log success
 	d.WriteLog(fmt.Sprintf("[AUDIO_SAVED] Call=%d Type=%s | Saved to: %s (%d bytes)", callId, callType, filename, len(audioData)))
+
+	d.enforceAudioBudget()
 	return nil
 }
 
+// enforceAudioBudget deletes the oldest files in the debug audio directory
+// until their total size is within maxAudioBytes.
+func (d *DebugLogger) enforceAudioBudget() {
+	if d.maxAudioBytes <= 0 {
+		return
+	}
+
+	d.audioMutex.Lock()
+	defer d.audioMutex.Unlock()
+
+	entries, err := os.ReadDir(d.audioDir)
+	if err != nil {
+		return
+	}
+
+	type clip struct {
+		name    string
+		size    int64
+		modTime time.Time
+	}
+	var clips []clip
+	var total int64
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		clips = append(clips, clip{name: entry.Name(), size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+	}
+	if total <= d.maxAudioBytes {
+		return
+	}
+
+	sort.Slice(clips, func(i, j int) bool { return clips[i].modTime.Before(clips[j].modTime) })
+
+	removed := 0
+	for _, c := range clips {
+		if total <= d.maxAudioBytes {
+			break
+		}
+		if err := os.Remove(filepath.Join(d.audioDir, c.name)); err != nil {
+			continue
+		}
+		total -= c.size
+		removed++
+	}
+	d.WriteLog(fmt.Sprintf("[AUDIO_PRUNED] Removed %d oldest clip(s) to keep %s under %d bytes", removed, d.audioDir, d.maxAudioBytes))
+}
+
 // Close closes the debug log file
 func (d *DebugLogger) Close() {
 	// Mark as closed first (this will cause WriteLog calls to return early)
@@ -195,16 +344,20 @@ func (d *DebugLogger) Close() {
 	}
 }
 
-// NewTranscriptionDebugLogger creates a new transcription debug logger
-func NewTranscriptionDebugLogger(filename string) (*TranscriptionDebugLogger, error) {
-	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+// NewTranscriptionDebugLogger creates a new transcription debug logger that is
+// rotated once it reaches maxSize bytes (0 = no cap)
+func NewTranscriptionDebugLogger(filename string, maxSize int64) (*TranscriptionDebugLogger, error) {
+	file, size, err := openDebugLogFile(filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open transcription debug log file: %v", err)
 	}
 
 	logger := &TranscriptionDebugLogger{
-		file:  file,
-		mutex: sync.Mutex{},
+		file:     file,
+		mutex:    sync.Mutex{},
+		filename: filename,
+		size:     size,
+		maxSize:  maxSize,
 	}
 
 	// Write header on startup
@@ -226,10 +379,22 @@ func (t *TranscriptionDebugLogger) WriteLog(message string) {
 		return
 	}
 
+	if t.maxSize > 0 && t.size >= t.maxSize {
+		file, err := rotateDebugLogFile(t.file, t.filename)
+		if err != nil {
+			t.file = nil
+			writeLogStdout(fmt.Sprintf("debug log rotation failed for %s: %v", t.filename, err))
+			return
+		}
+		t.file = file
+		t.size = 0
+	}
+
 	timestamp := time.Now().Format("2006-01-02 15:04:05.000")
 	logLine := fmt.Sprintf("[%s] %s\n", timestamp, message)
 
-	t.file.WriteString(logLine)
+	n, _ := t.file.WriteString(logLine)
+	t.size += int64(n)
 	t.file.Sync() // Flush to disk immediately
 }
 
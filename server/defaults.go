@@ -45,52 +45,59 @@ type DefaultDownstream struct {
 }
 
 type DefaultOptions struct {
-	autoPopulate                bool
-	audioConversion             uint
-	branding                    string
-	defaultSystemDelay          uint
-	disableDuplicateDetection   bool
-	duplicateDetectionTimeFrame uint
-	duplicateTimestampWindow    uint
-	email                       string
-	keypadBeeps                 string
-	maxClients                  uint
-	playbackGoesLive            bool
-	pruneDays                   uint
-	showListenersCount          bool
-	sortTalkgroups              bool
-	time12hFormat               bool
-	radioReferenceEnabled       bool
-	radioReferenceUsername      string
-	radioReferencePassword      string
-	userRegistrationEnabled     bool
-	publicRegistrationEnabled   bool
-	publicRegistrationMode      string
-	emailVerificationRequired   bool
-	stripePaywallEnabled        bool
-	emailServiceEnabled         bool
-	emailServiceApiKey          string
-	emailServiceDomain          string
-	emailServiceTemplateId      string
-	emailProvider               string
-	emailSendGridAPIKey         string
-	emailMailgunAPIKey          string
-	emailMailgunDomain          string
-	emailMailgunAPIBase         string
-	emailSmtpHost               string
-	emailSmtpPort               int
-	emailSmtpUsername           string
-	emailSmtpPassword           string
-	emailSmtpUseTLS             bool
-	emailSmtpSkipVerify         bool
-	emailSmtpFromEmail          string
-	emailSmtpFromName           string
-	emailLogoFilename           string
-	emailLogoBorderRadius       string
-	faviconFilename             string
-	stripePublishableKey        string
-	stripeSecretKey             string
-	stripeWebhookSecret         string
+	autoPopulate                       bool
+	audioConversion                    uint
+	audioConversionForce               bool
+	audioFormat                        string
+	audioLoudnormTwoPass               bool
+	silenceTrimEnabled                 bool
+	silenceTrimThreshold               int
+	silenceTrimDuration                uint
+	branding                           string
+	defaultSystemDelay                 uint
+	toneSetCooldownSeconds             uint
+	disableDuplicateDetection          bool
+	duplicateDetectionTimeFrame        uint
+	duplicateTimestampWindow           uint
+	email                              string
+	keypadBeeps                        string
+	maxClients                         uint
+	playbackGoesLive                   bool
+	pruneDays                          uint
+	showListenersCount                 bool
+	sortTalkgroups                     bool
+	time12hFormat                      bool
+	radioReferenceEnabled              bool
+	radioReferenceUsername             string
+	radioReferencePassword             string
+	userRegistrationEnabled            bool
+	publicRegistrationEnabled          bool
+	publicRegistrationMode             string
+	emailVerificationRequired          bool
+	stripePaywallEnabled               bool
+	emailServiceEnabled                bool
+	emailServiceApiKey                 string
+	emailServiceDomain                 string
+	emailServiceTemplateId             string
+	emailProvider                      string
+	emailSendGridAPIKey                string
+	emailMailgunAPIKey                 string
+	emailMailgunDomain                 string
+	emailMailgunAPIBase                string
+	emailSmtpHost                      string
+	emailSmtpPort                      int
+	emailSmtpUsername                  string
+	emailSmtpPassword                  string
+	emailSmtpUseTLS                    bool
+	emailSmtpSkipVerify                bool
+	emailSmtpFromEmail                 string
+	emailSmtpFromName                  string
+	emailLogoFilename                  string
+	emailLogoBorderRadius              string
+	faviconFilename                    string
+	stripePublishableKey               string
+	stripeSecretKey                    string
+	stripeWebhookSecret                string
 	stripeBillingPortalConfigurationId string
 	stripeGracePeriodDays        uint
 	stripePriceId               string
@@ -162,52 +169,59 @@ var defaults = Defaults{
 	},
 	keypadBeeps: "uniden",
 	options: DefaultOptions{
-		autoPopulate:                true,
-		audioConversion:             AUDIO_CONVERSION_ENABLED, // match rdio-scanner: on by default
-		branding:                    "",
-		defaultSystemDelay:          0,
-		disableDuplicateDetection:   false,
-		duplicateDetectionTimeFrame: 30000,
-		duplicateTimestampWindow:    800,
-		email:                       "",
-		keypadBeeps:                 "uniden",
-		maxClients:                  100,
-		playbackGoesLive:            false,
-		pruneDays:                   0,
-		showListenersCount:          true,
-		sortTalkgroups:              false,
-		time12hFormat:               false,
-		radioReferenceEnabled:       false,
-		radioReferenceUsername:      "",
-		radioReferencePassword:      "",
-		userRegistrationEnabled:     true,
-		publicRegistrationEnabled:   false, // Default to invite-only
-		publicRegistrationMode:      "both",
-		emailVerificationRequired:   false, // Default to not requiring email verification
-		stripePaywallEnabled:        false,
-		emailServiceEnabled:         false,
-		emailServiceApiKey:          "",
-		emailServiceDomain:          "",
-		emailServiceTemplateId:      "",
-		emailProvider:               "sendgrid",
-		emailSendGridAPIKey:         "",
-		emailMailgunAPIKey:          "",
-		emailMailgunDomain:          "",
-		emailMailgunAPIBase:         "https://api.mailgun.net",
-		emailSmtpHost:               "",
-		emailSmtpPort:               587,
-		emailSmtpUsername:           "",
-		emailSmtpPassword:           "",
-		emailSmtpUseTLS:             true,
-		emailSmtpSkipVerify:         false,
-		emailSmtpFromEmail:          "",
-		emailSmtpFromName:           "",
-		emailLogoFilename:           "",
-		emailLogoBorderRadius:       "0px",
-		faviconFilename:             "",
-		stripePublishableKey:        "",
-		stripeSecretKey:             "",
-		stripeWebhookSecret:         "",
+		autoPopulate:                       true,
+		audioConversion:                    AUDIO_CONVERSION_ENABLED, // match rdio-scanner: on by default
+		audioConversionForce:               false,
+		audioFormat:                        AUDIO_FORMAT_AAC,
+		audioLoudnormTwoPass:               false,
+		silenceTrimEnabled:                 false,
+		silenceTrimThreshold:               -50,
+		silenceTrimDuration:                500,
+		branding:                           "",
+		defaultSystemDelay:                 0,
+		toneSetCooldownSeconds:             0,
+		disableDuplicateDetection:          false,
+		duplicateDetectionTimeFrame:        30000,
+		duplicateTimestampWindow:           800,
+		email:                              "",
+		keypadBeeps:                        "uniden",
+		maxClients:                         100,
+		playbackGoesLive:                   false,
+		pruneDays:                          0,
+		showListenersCount:                 true,
+		sortTalkgroups:                     false,
+		time12hFormat:                      false,
+		radioReferenceEnabled:              false,
+		radioReferenceUsername:             "",
+		radioReferencePassword:             "",
+		userRegistrationEnabled:            true,
+		publicRegistrationEnabled:          false, // Default to invite-only
+		publicRegistrationMode:             "both",
+		emailVerificationRequired:          false, // Default to not requiring email verification
+		stripePaywallEnabled:               false,
+		emailServiceEnabled:                false,
+		emailServiceApiKey:                 "",
+		emailServiceDomain:                 "",
+		emailServiceTemplateId:             "",
+		emailProvider:                      "sendgrid",
+		emailSendGridAPIKey:                "",
+		emailMailgunAPIKey:                 "",
+		emailMailgunDomain:                 "",
+		emailMailgunAPIBase:                "https://api.mailgun.net",
+		emailSmtpHost:                      "",
+		emailSmtpPort:                      587,
+		emailSmtpUsername:                  "",
+		emailSmtpPassword:                  "",
+		emailSmtpUseTLS:                    true,
+		emailSmtpSkipVerify:                false,
+		emailSmtpFromEmail:                 "",
+		emailSmtpFromName:                  "",
+		emailLogoFilename:                  "",
+		emailLogoBorderRadius:              "0px",
+		faviconFilename:                    "",
+		stripePublishableKey:               "",
+		stripeSecretKey:                    "",
+		stripeWebhookSecret:                "",
 		stripeBillingPortalConfigurationId: "",
 		stripeGracePeriodDays:       0,
 		stripePriceId:               "",
@@ -18,8 +18,11 @@ package main
 import (
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 type DeviceToken struct {
@@ -27,11 +30,107 @@ type DeviceToken struct {
 	UserId    uint64
 	Token     string // Legacy field; kept for DB compatibility. No longer used for push delivery.
 	FCMToken  string // Firebase Cloud Messaging token — the active push token.
-	PushType  string // "fcm"
-	Platform  string // "ios" or "android"
+	PushType  string // "fcm", "voip" or "webpush"
+	Platform  string // "ios", "android" or "web"
 	Sound     string // Notification sound preference
 	CreatedAt int64
 	LastUsed  int64
+	// Subscription is the browser PushSubscription JSON for "webpush" tokens;
+	// Token holds the subscription endpoint.
+	Subscription string
+	// Quiet hours as minutes of day in Timezone (IANA name, empty = server local).
+	// Pushes inside the window are sent without sound; equal values disable it.
+	QuietStart int
+	QuietEnd   int
+	Timezone   string
+}
+
+// deviceTokenPlatforms maps the platform names clients have sent to the
+// canonical "ios", "android" and "web".
+var deviceTokenPlatforms = map[string]string{
+	"ios":     "ios",
+	"iphone":  "ios",
+	"ipad":    "ios",
+	"ipados":  "ios",
+	"apple":   "ios",
+	"android": "android",
+	"web":     "web",
+	"browser": "web",
+	"pwa":     "web",
+}
+
+// deviceTokenPushTypes maps push type aliases to the canonical "fcm", "voip",
+// "webpush" and legacy "onesignal".
+var deviceTokenPushTypes = map[string]string{
+	"fcm":        "fcm",
+	"firebase":   "fcm",
+	"gcm":        "fcm",
+	"voip":       "voip",
+	"pushkit":    "voip",
+	"webpush":    DeviceTokenPushTypeWebPush,
+	"web-push":   DeviceTokenPushTypeWebPush,
+	"web_push":   DeviceTokenPushTypeWebPush,
+	"onesignal":  "onesignal",
+	"one-signal": "onesignal",
+	"one_signal": "onesignal",
+}
+
+func normalizeDeviceTokenPlatform(platform string) (string, error) {
+	p := strings.ToLower(strings.TrimSpace(platform))
+	if p == "" {
+		return "", fmt.Errorf("platform is required")
+	}
+	if canonical, ok := deviceTokenPlatforms[p]; ok {
+		return canonical, nil
+	}
+	return "", fmt.Errorf("unknown platform %q, expected ios, android or web", platform)
+}
+
+// normalizeDeviceTokenPushType returns the canonical push type. An empty value
+// is kept: it marks a legacy row and is read back as "onesignal".
+func normalizeDeviceTokenPushType(pushType string) (string, error) {
+	p := strings.ToLower(strings.TrimSpace(pushType))
+	if p == "" {
+		return "", nil
+	}
+	if canonical, ok := deviceTokenPushTypes[p]; ok {
+		return canonical, nil
+	}
+	return "", fmt.Errorf("unknown push type %q, expected fcm, voip, webpush or onesignal", pushType)
+}
+
+// Normalize rewrites Platform and PushType to their canonical values, or
+// returns an error naming the value it does not recognise.
+func (token *DeviceToken) Normalize() error {
+	platform, err := normalizeDeviceTokenPlatform(token.Platform)
+	if err != nil {
+		return err
+	}
+	pushType, err := normalizeDeviceTokenPushType(token.PushType)
+	if err != nil {
+		return err
+	}
+	token.Platform = platform
+	token.PushType = pushType
+	return nil
+}
+
+// InQuietHours reports whether t falls inside the token's quiet window. The
+// window may wrap midnight (e.g. 22:00–06:00).
+func (token *DeviceToken) InQuietHours(t time.Time) bool {
+	if token.QuietStart == token.QuietEnd {
+		return false
+	}
+	if token.Timezone != "" {
+		if loc, err := time.LoadLocation(token.Timezone); err == nil {
+			t = t.In(loc)
+		}
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if token.QuietStart < token.QuietEnd {
+		return minute >= token.QuietStart && minute < token.QuietEnd
+	}
+	return minute >= token.QuietStart || minute < token.QuietEnd
 }
 
 type DeviceTokens struct {
@@ -63,7 +162,7 @@ func (dt *DeviceTokens) Load(db *Database) error {
 	dt.mutex.Lock()
 	defer dt.mutex.Unlock()
 
-	rows, err := db.Sql.Query(`SELECT "deviceTokenId", "userId", "token", "fcmToken", "pushType", "platform", "sound", "createdAt", "lastUsed" FROM "deviceTokens"`)
+	rows, err := db.Sql.Query(`SELECT "deviceTokenId", "userId", "token", "fcmToken", "pushType", "platform", "sound", "createdAt", "lastUsed", "subscription", "quietStart", "quietEnd", "timezone" FROM "deviceTokens"`)
 	if err != nil {
 		return err
 	}
@@ -76,6 +175,7 @@ func (dt *DeviceTokens) Load(db *Database) error {
 	tokenCount := 0
 	userTokenCounts := make(map[uint64]int)
 	uniqueUsers := make(map[uint64]bool)
+	var renamed []*DeviceToken
 
 	for rows.Next() {
 		token := &DeviceToken{}
@@ -90,6 +190,10 @@ func (dt *DeviceTokens) Load(db *Database) error {
 			&token.Sound,
 			&token.CreatedAt,
 			&token.LastUsed,
+			&token.Subscription,
+			&token.QuietStart,
+			&token.QuietEnd,
+			&token.Timezone,
 		)
 		if err != nil {
 			continue
@@ -105,6 +209,14 @@ func (dt *DeviceTokens) Load(db *Database) error {
 			token.PushType = "onesignal"
 		}
 
+		// Backfill rows written before platform and push type were validated
+		platform, storedPushType := token.Platform, token.PushType
+		if err := token.Normalize(); err != nil {
+			log.Printf("DeviceTokens.Load: device token %d: %v", token.Id, err)
+		} else if token.Platform != platform || (pushType != nil && token.PushType != storedPushType) {
+			renamed = append(renamed, token)
+		}
+
 		dt.tokens[token.Id] = token
 		dt.userTokens[token.UserId] = append(dt.userTokens[token.UserId], token)
 		// Index by FCMToken (the value we send to the relay server) so that
@@ -120,6 +232,21 @@ func (dt *DeviceTokens) Load(db *Database) error {
 		uniqueUsers[token.UserId] = true
 	}
 
+	rows.Close()
+
+	for _, token := range renamed {
+		var pushType *string
+		if token.PushType != "" {
+			pushType = &token.PushType
+		}
+		if _, err := db.Sql.Exec(`UPDATE "deviceTokens" SET "platform" = $1, "pushType" = $2 WHERE "deviceTokenId" = $3`, token.Platform, pushType, token.Id); err != nil {
+			log.Printf("DeviceTokens.Load: failed to normalize device token %d: %v", token.Id, err)
+		}
+	}
+	if len(renamed) > 0 {
+		log.Printf("DeviceTokens.Load: normalized platform/push type of %d device token(s)", len(renamed))
+	}
+
 	// Log token loading summary with more detail
 	fmt.Printf("DeviceTokens.Load: loaded %d total device tokens for %d users\n", tokenCount, len(uniqueUsers))
 	if tokenCount == 0 {
@@ -135,6 +262,10 @@ func (dt *DeviceTokens) Load(db *Database) error {
 }
 
 func (dt *DeviceTokens) Add(token *DeviceToken, db *Database) error {
+	if err := token.Normalize(); err != nil {
+		return err
+	}
+
 	dt.mutex.Lock()
 	defer dt.mutex.Unlock()
 
@@ -157,9 +288,9 @@ func (dt *DeviceTokens) Add(token *DeviceToken, db *Database) error {
 	
 	var tokenId int64
 	err := db.Sql.QueryRow(
-		`INSERT INTO "deviceTokens" ("userId", "token", "fcmToken", "pushType", "platform", "sound", "createdAt", "lastUsed") 
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING "deviceTokenId"`,
-		token.UserId, token.Token, fcmToken, pushType, token.Platform, token.Sound, token.CreatedAt, token.LastUsed,
+		`INSERT INTO "deviceTokens" ("userId", "token", "fcmToken", "pushType", "platform", "sound", "createdAt", "lastUsed", "subscription", "quietStart", "quietEnd", "timezone") 
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING "deviceTokenId"`,
+		token.UserId, token.Token, fcmToken, pushType, token.Platform, token.Sound, token.CreatedAt, token.LastUsed, token.Subscription, token.QuietStart, token.QuietEnd, token.Timezone,
 	).Scan(&tokenId)
 	if err != nil {
 		return err
@@ -178,6 +309,10 @@ func (dt *DeviceTokens) Add(token *DeviceToken, db *Database) error {
 }
 
 func (dt *DeviceTokens) Update(token *DeviceToken, db *Database) error {
+	if err := token.Normalize(); err != nil {
+		return err
+	}
+
 	dt.mutex.Lock()
 	defer dt.mutex.Unlock()
 
@@ -194,8 +329,8 @@ func (dt *DeviceTokens) Update(token *DeviceToken, db *Database) error {
 	}
 
 	_, err := db.Sql.Exec(
-		`UPDATE "deviceTokens" SET "token" = $1, "fcmToken" = $2, "pushType" = $3, "platform" = $4, "sound" = $5, "lastUsed" = $6 WHERE "deviceTokenId" = $7`,
-		token.Token, fcmToken, pushType, token.Platform, token.Sound, token.LastUsed, token.Id,
+		`UPDATE "deviceTokens" SET "token" = $1, "fcmToken" = $2, "pushType" = $3, "platform" = $4, "sound" = $5, "lastUsed" = $6, "subscription" = $7, "quietStart" = $8, "quietEnd" = $9, "timezone" = $10 WHERE "deviceTokenId" = $11`,
+		token.Token, fcmToken, pushType, token.Platform, token.Sound, token.LastUsed, token.Subscription, token.QuietStart, token.QuietEnd, token.Timezone, token.Id,
 	)
 	if err != nil {
 		return err
@@ -302,7 +437,7 @@ func (dt *DeviceTokens) RemoveAllLegacyTokensForUser(userId uint64, db *Database
 
 	var toDelete []uint64
 	for _, t := range userTokens {
-		if t.FCMToken == "" || t.PushType == "onesignal" {
+		if isLegacyOneSignalToken(t) {
 			toDelete = append(toDelete, t.Id)
 		}
 	}
@@ -356,3 +491,150 @@ func (dt *DeviceTokens) RemoveAllLegacyTokensForUser(userId uint64, db *Database
 	return nil
 }
 
+// RemoveAllForUser deletes every device token (OneSignal and FCM) registered to the user
+// and returns how many were removed. Used when a user's access is revoked so pushes stop.
+func (dt *DeviceTokens) RemoveAllForUser(userId uint64, db *Database, clients *Clients) (int, error) {
+	dt.mutex.Lock()
+
+	if _, err := db.Sql.Exec(`DELETE FROM "deviceTokens" WHERE "userId" = $1`, userId); err != nil {
+		dt.mutex.Unlock()
+		return 0, err
+	}
+
+	userTokens := dt.userTokens[userId]
+	var clearedKeys []string
+	for _, token := range userTokens {
+		pushKey := token.FCMToken
+		if pushKey == "" {
+			pushKey = token.Token
+		}
+		if pushKey != "" {
+			clearedKeys = append(clearedKeys, pushKey)
+		}
+		delete(dt.tokens, token.Id)
+		if token.Token != "" {
+			delete(dt.tokenIndex, token.Token)
+		}
+		if token.FCMToken != "" {
+			delete(dt.tokenIndex, token.FCMToken)
+		}
+	}
+	delete(dt.userTokens, userId)
+
+	dt.mutex.Unlock()
+	if clients != nil {
+		for _, k := range clearedKeys {
+			clients.ClearSessionsForPushToken(k)
+		}
+	}
+
+	return len(userTokens), nil
+}
+
+// DedupeTokens collapses rows of the same user that share a push token and
+// platform (a device re-registering under a new row) down to the most recently
+// used one, deleting the rest. It returns the number of rows removed.
+func (dt *DeviceTokens) DedupeTokens(db *Database) (int, error) {
+	dt.mutex.Lock()
+	defer dt.mutex.Unlock()
+
+	duplicates := make(map[uint64]bool)
+	var duplicateIds []int64
+	var keepers []*DeviceToken
+	for _, tokens := range dt.userTokens {
+		newest := make(map[string]*DeviceToken)
+		for _, token := range tokens {
+			value := token.FCMToken
+			if value == "" {
+				value = token.Token
+			}
+			if value == "" {
+				continue
+			}
+			key := value + "|" + token.Platform
+			current, ok := newest[key]
+			if !ok {
+				newest[key] = token
+				continue
+			}
+			if token.LastUsed > current.LastUsed || (token.LastUsed == current.LastUsed && token.Id > current.Id) {
+				newest[key], token = token, current
+			}
+			duplicates[token.Id] = true
+			duplicateIds = append(duplicateIds, int64(token.Id))
+		}
+		for _, token := range newest {
+			keepers = append(keepers, token)
+		}
+	}
+
+	if len(duplicateIds) == 0 {
+		return 0, nil
+	}
+	if _, err := db.Sql.Exec(`DELETE FROM "deviceTokens" WHERE "deviceTokenId" = ANY($1)`, pq.Array(duplicateIds)); err != nil {
+		return 0, err
+	}
+
+	for userId, tokens := range dt.userTokens {
+		kept := tokens[:0]
+		for _, token := range tokens {
+			if duplicates[token.Id] {
+				delete(dt.tokens, token.Id)
+			} else {
+				kept = append(kept, token)
+			}
+		}
+		dt.userTokens[userId] = kept
+	}
+	// Duplicates shared the index key, so point it back at the surviving row.
+	for _, token := range keepers {
+		if token.FCMToken != "" {
+			dt.tokenIndex[token.FCMToken] = token
+		} else {
+			dt.tokenIndex[token.Token] = token
+		}
+	}
+
+	return len(duplicateIds), nil
+}
+
+// PruneStale deletes tokens whose LastUsed is older than maxAge from the database
+// and the in-memory maps, returning how many were removed.
+func (dt *DeviceTokens) PruneStale(db *Database, maxAge time.Duration) (int, error) {
+	cutoff := time.Now().Add(-maxAge).Unix()
+
+	dt.mutex.Lock()
+	defer dt.mutex.Unlock()
+
+	if _, err := db.Sql.Exec(`DELETE FROM "deviceTokens" WHERE "lastUsed" < $1`, cutoff); err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for id, token := range dt.tokens {
+		if token.LastUsed >= cutoff {
+			continue
+		}
+		delete(dt.tokens, id)
+		if token.Token != "" {
+			delete(dt.tokenIndex, token.Token)
+		}
+		if token.FCMToken != "" {
+			delete(dt.tokenIndex, token.FCMToken)
+		}
+		kept := dt.userTokens[token.UserId][:0]
+		for _, t := range dt.userTokens[token.UserId] {
+			if t.Id != id {
+				kept = append(kept, t)
+			}
+		}
+		if len(kept) == 0 {
+			delete(dt.userTokens, token.UserId)
+		} else {
+			dt.userTokens[token.UserId] = kept
+		}
+		removed++
+	}
+
+	return removed, nil
+}
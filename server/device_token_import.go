@@ -19,6 +19,11 @@ func normalizeImportedDeviceTokenFields(m map[string]any) (token, fcmToken, push
 		pushType = mapString(m, "push_type")
 	}
 
+	// Web Push rows keep the endpoint in token and have no FCM token.
+	if pushType == DeviceTokenPushTypeWebPush {
+		return token, "", pushType
+	}
+
 	if fcmToken == "" && token != "" {
 		fcmToken = token
 	}
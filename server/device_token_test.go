@@ -0,0 +1,46 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import "testing"
+
+func TestDeviceTokenNormalize(t *testing.T) {
+	cases := []struct {
+		platform, pushType         string
+		wantPlatform, wantPushType string
+	}{
+		{"iOS", "FCM", "ios", "fcm"},
+		{"apple", "firebase", "ios", "fcm"},
+		{" Android ", "fcm", "android", "fcm"},
+		{"Web", "web-push", "web", "webpush"},
+		{"ios", "VoIP", "ios", "voip"},
+		{"android", "OneSignal", "android", "onesignal"},
+		{"android", "", "android", ""},
+	}
+	for _, c := range cases {
+		token := &DeviceToken{Platform: c.platform, PushType: c.pushType}
+		if err := token.Normalize(); err != nil {
+			t.Errorf("Normalize(%q, %q): %v", c.platform, c.pushType, err)
+			continue
+		}
+		if token.Platform != c.wantPlatform || token.PushType != c.wantPushType {
+			t.Errorf("Normalize(%q, %q) = %q, %q; want %q, %q", c.platform, c.pushType, token.Platform, token.PushType, c.wantPlatform, c.wantPushType)
+		}
+	}
+}
+
+func TestDeviceTokenNormalizeRejectsUnknown(t *testing.T) {
+	for _, token := range []*DeviceToken{
+		{Platform: "windows", PushType: "fcm"},
+		{Platform: "", PushType: "fcm"},
+		{Platform: "ios", PushType: "sms"},
+	} {
+		platform, pushType := token.Platform, token.PushType
+		if err := token.Normalize(); err == nil {
+			t.Errorf("Normalize(%q, %q) accepted an unknown value", platform, pushType)
+		}
+		if token.Platform != platform || token.PushType != pushType {
+			t.Errorf("rejected token was modified: %q, %q", token.Platform, token.PushType)
+		}
+	}
+}
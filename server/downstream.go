@@ -91,7 +91,14 @@ func (downstream *Downstream) HasAccess(call *Call) bool {
 		return false
 	}
 
-	switch v := downstream.Systems.(type) {
+	return systemsScopeIncludes(downstream.Systems, call)
+}
+
+// systemsScopeIncludes reports whether call falls in a system selection as
+// made with the admin systems picker: "*" or a list of
+// {"id": systemRef, "talkgroups": "*" | [talkgroupRef, ...]}.
+func systemsScopeIncludes(systems any, call *Call) bool {
+	switch v := systems.(type) {
 	case []any:
 		for _, f := range v {
 			switch v := f.(type) {
@@ -17,23 +17,51 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os/exec"
 	"path"
 	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
 type FFMpeg struct {
 	available bool
 	version43 bool
 	warned    bool
+
+	// slots caps how many ffmpeg processes run at once so an ingest burst
+	// queues up instead of starving the HTTP server of CPU.
+	slots       chan struct{}
+	inFlight    atomic.Int64
+	waiting     atomic.Int64
+	maxWait     atomic.Int64 // nanoseconds
+	conversions atomic.Uint64
+}
+
+// FFMpegStats is a snapshot of the conversion limiter.
+type FFMpegStats struct {
+	Limit       int     `json:"limit"`
+	InFlight    int64   `json:"inFlight"`
+	Waiting     int64   `json:"waiting"`
+	MaxWaitMs   float64 `json:"maxWaitMs"`
+	Conversions uint64  `json:"conversions"`
 }
 
-func NewFFMpeg() *FFMpeg {
-	ffmpeg := &FFMpeg{}
+// NewFFMpeg probes the installed ffmpeg. maxConcurrent bounds simultaneous
+// ffmpeg processes; 0 or less means one per CPU.
+func NewFFMpeg(maxConcurrent int) *FFMpeg {
+	if maxConcurrent <= 0 {
+		maxConcurrent = runtime.NumCPU()
+	}
+
+	ffmpeg := &FFMpeg{slots: make(chan struct{}, maxConcurrent)}
 
 	stdout := bytes.NewBuffer([]byte(nil))
 
@@ -61,6 +89,42 @@ func NewFFMpeg() *FFMpeg {
 	return ffmpeg
 }
 
+// acquire blocks until an ffmpeg slot is free and records how long that took.
+func (ffmpeg *FFMpeg) acquire() {
+	start := time.Now()
+
+	ffmpeg.waiting.Add(1)
+	ffmpeg.slots <- struct{}{}
+	ffmpeg.waiting.Add(-1)
+	ffmpeg.inFlight.Add(1)
+
+	wait := int64(time.Since(start))
+	for {
+		max := ffmpeg.maxWait.Load()
+		if wait <= max || ffmpeg.maxWait.CompareAndSwap(max, wait) {
+			break
+		}
+	}
+}
+
+func (ffmpeg *FFMpeg) release() {
+	ffmpeg.inFlight.Add(-1)
+	ffmpeg.conversions.Add(1)
+	<-ffmpeg.slots
+}
+
+// Stats reports the limiter state. MaxWaitMs is the longest any caller has
+// waited for a slot since startup.
+func (ffmpeg *FFMpeg) Stats() FFMpegStats {
+	return FFMpegStats{
+		Limit:       cap(ffmpeg.slots),
+		InFlight:    ffmpeg.inFlight.Load(),
+		Waiting:     ffmpeg.waiting.Load(),
+		MaxWaitMs:   float64(ffmpeg.maxWait.Load()) / float64(time.Millisecond),
+		Conversions: ffmpeg.conversions.Load(),
+	}
+}
+
 func (ffmpeg *FFMpeg) ProcessForTranscription(audio []byte) []byte {
 	if !ffmpeg.available {
 		return audio
@@ -85,6 +149,9 @@ func (ffmpeg *FFMpeg) ProcessForTranscription(audio []byte) []byte {
 	stderr := bytes.NewBuffer([]byte(nil))
 	cmd.Stderr = stderr
 
+	ffmpeg.acquire()
+	defer ffmpeg.release()
+
 	if err := cmd.Run(); err == nil {
 		return stdout.Bytes()
 	}
@@ -92,12 +159,16 @@ func (ffmpeg *FFMpeg) ProcessForTranscription(audio []byte) []byte {
 	return audio
 }
 
-func (ffmpeg *FFMpeg) Convert(call *Call, systems *Systems, tags *Tags, mode uint) error {
+func (ffmpeg *FFMpeg) Convert(call *Call, systems *Systems, tags *Tags, options *Options) error {
 	var (
-		args = []string{"-i", "-"}
-		err  error
+		args    = []string{"-i", "-"}
+		err     error
+		filters []string
+		mode    = options.AudioConversion
 	)
 
+	format, bitrate := convertTarget(call.System, options)
+
 	if mode == AUDIO_CONVERSION_DISABLED {
 		return nil
 	}
@@ -122,14 +193,55 @@ func (ffmpeg *FFMpeg) Convert(call *Call, systems *Systems, tags *Tags, mode uin
 	}
 
 	if ffmpeg.version43 {
-		if mode == AUDIO_CONVERSION_ENABLED_NORM {
-			args = append(args, "-af", "apad=whole_dur=3s,highpass=f=120,acompressor=threshold=-20dB:ratio=4:attack=8:release=80:makeup=6dB,afftdn=nf=-20,equalizer=f=250:width_type=q:width=2:g=-3,equalizer=f=3000:width_type=q:width=2:g=5,lowpass=f=3200,loudnorm=I=-14:TP=-1.5:LRA=11,alimiter=limit=0.891:attack=5:release=50")
-		} else if mode == AUDIO_CONVERSION_ENABLED_LOUD_NORM {
-			args = append(args, "-af", "apad=whole_dur=3s,highpass=f=120,acompressor=threshold=-20dB:ratio=4:attack=8:release=80:makeup=6dB,afftdn=nf=-20,equalizer=f=250:width_type=q:width=2:g=-3,equalizer=f=3000:width_type=q:width=2:g=5,lowpass=f=3200,loudnorm=I=-14:TP=-1.5:LRA=3,alimiter=limit=0.891:attack=5:release=50")
+		// Trimming runs first so the normalization chain below (including its
+		// apad) sees only the transmission itself, not the squelch tail.
+		if options.SilenceTrimEnabled {
+			filters = append(filters, silenceTrimFilter(options.SilenceTrimThreshold, options.SilenceTrimDuration))
+		}
+
+		if target := loudnormTarget(mode); target != "" {
+			loudnorm := "loudnorm=" + target
+
+			// The second pass applies the first pass's measurements linearly,
+			// so every call lands on the same integrated loudness instead of
+			// whatever the dynamic mode converged to. A failed measurement
+			// just falls back to the single-pass filter.
+			if options.AudioLoudnormTwoPass {
+				measure := append(append([]string{}, filters...), loudnormPreFilters, loudnorm+":print_format=json")
+				if stats, err := ffmpeg.measureLoudness(call.Audio, strings.Join(measure, ",")); err == nil {
+					loudnorm += stats.filterParams()
+				}
+			}
+
+			filters = append(filters, loudnormPreFilters, loudnorm, loudnormPostFilters)
 		}
 	}
 
-	args = append(args, "-c:a", "aac", "-b:a", "48k", "-movflags", "frag_keyframe+empty_moov", "-f", "ipod", "-")
+	// FLAC keeps every sample of the (optionally normalized) source, so no
+	// bitrate applies; tags are written as Vorbis comments by the muxer.
+	ext, mime := "m4a", "audio/mp4"
+	if format == AUDIO_FORMAT_FLAC {
+		ext, mime = "flac", "audio/flac"
+	}
+
+	// Nothing to gain from decoding and re-encoding audio that is already in
+	// the target codec when no filter has to run; it only costs CPU and, for
+	// AAC, a generation of quality. Metadata tags are not added in that case.
+	if len(filters) == 0 && !options.AudioConversionForce && ffmpeg.matchesTarget(call.Audio, format, bitrate) {
+		call.AudioFilename = fmt.Sprintf("%v.%v", strings.TrimSuffix(call.AudioFilename, path.Ext((call.AudioFilename))), ext)
+		call.AudioMime = mime
+		return nil
+	}
+
+	if len(filters) > 0 {
+		args = append(args, "-af", strings.Join(filters, ","))
+	}
+
+	if format == AUDIO_FORMAT_FLAC {
+		args = append(args, "-c:a", "flac", "-f", "flac", "-")
+	} else {
+		args = append(args, "-c:a", "aac", "-b:a", fmt.Sprintf("%dk", bitrate/1000), "-movflags", "frag_keyframe+empty_moov", "-f", "ipod", "-")
+	}
 
 	cmd := exec.Command("ffmpeg", args...)
 	cmd.Stdin = bytes.NewReader(call.Audio)
@@ -140,13 +252,199 @@ func (ffmpeg *FFMpeg) Convert(call *Call, systems *Systems, tags *Tags, mode uin
 	stderr := bytes.NewBuffer([]byte(nil))
 	cmd.Stderr = stderr
 
-	if err = cmd.Run(); err == nil {
+	ffmpeg.acquire()
+	err = cmd.Run()
+	ffmpeg.release()
+
+	if err == nil {
 		call.Audio = stdout.Bytes()
-		call.AudioFilename = fmt.Sprintf("%v.m4a", strings.TrimSuffix(call.AudioFilename, path.Ext((call.AudioFilename))))
-		call.AudioMime = "audio/mp4"
+		call.AudioFilename = fmt.Sprintf("%v.%v", strings.TrimSuffix(call.AudioFilename, path.Ext((call.AudioFilename))), ext)
+		call.AudioMime = mime
 	} else {
-		fmt.Println(stderr.String())
+		return fmt.Errorf("ffmpeg conversion failed: %w: %s", err, stderrTail(stderr.String(), ffmpegStderrTail))
 	}
 
 	return nil
 }
+
+// Normalization chain around loudnorm: clean up the radio audio before it is
+// measured, then catch any peaks the gain change pushed over the ceiling.
+const (
+	loudnormPreFilters  = "apad=whole_dur=3s,highpass=f=120,acompressor=threshold=-20dB:ratio=4:attack=8:release=80:makeup=6dB,afftdn=nf=-20,equalizer=f=250:width_type=q:width=2:g=-3,equalizer=f=3000:width_type=q:width=2:g=5,lowpass=f=3200"
+	loudnormPostFilters = "alimiter=limit=0.891:attack=5:release=50"
+)
+
+// loudnormTarget returns the loudnorm targets for a normalization mode, or ""
+// when the mode does not normalize.
+func loudnormTarget(mode uint) string {
+	switch mode {
+	case AUDIO_CONVERSION_ENABLED_NORM:
+		return "I=-14:TP=-1.5:LRA=11"
+	case AUDIO_CONVERSION_ENABLED_LOUD_NORM:
+		return "I=-14:TP=-1.5:LRA=3"
+	}
+	return ""
+}
+
+// loudnormStats holds the first-pass measurement loudnorm prints as JSON.
+type loudnormStats struct {
+	InputI       string `json:"input_i"`
+	InputTP      string `json:"input_tp"`
+	InputLRA     string `json:"input_lra"`
+	InputThresh  string `json:"input_thresh"`
+	TargetOffset string `json:"target_offset"`
+}
+
+func (stats *loudnormStats) filterParams() string {
+	return fmt.Sprintf(":measured_I=%s:measured_TP=%s:measured_LRA=%s:measured_thresh=%s:offset=%s:linear=true",
+		stats.InputI, stats.InputTP, stats.InputLRA, stats.InputThresh, stats.TargetOffset)
+}
+
+// parseLoudnormStats extracts the JSON block loudnorm writes at the end of
+// ffmpeg's stderr.
+func parseLoudnormStats(stderr string) (*loudnormStats, error) {
+	start := strings.LastIndex(stderr, "{")
+	end := strings.LastIndex(stderr, "}")
+	if start < 0 || end < start {
+		return nil, errors.New("no loudnorm measurement in ffmpeg output")
+	}
+
+	stats := &loudnormStats{}
+	if err := json.Unmarshal([]byte(stderr[start:end+1]), stats); err != nil {
+		return nil, err
+	}
+
+	for _, v := range []string{stats.InputI, stats.InputTP, stats.InputLRA, stats.InputThresh, stats.TargetOffset} {
+		// Silent input measures as -inf, which loudnorm will not accept back.
+		if _, err := strconv.ParseFloat(v, 64); err != nil || strings.Contains(v, "inf") {
+			return nil, fmt.Errorf("unusable loudnorm measurement %q", v)
+		}
+	}
+
+	return stats, nil
+}
+
+// measureLoudness runs the analysis pass of two-pass loudnorm over audio.
+func (ffmpeg *FFMpeg) measureLoudness(audio []byte, filter string) (*loudnormStats, error) {
+	cmd := exec.Command("ffmpeg", "-hide_banner", "-nostats", "-i", "-", "-af", filter, "-f", "null", "-")
+	cmd.Stdin = bytes.NewReader(audio)
+
+	stderr := bytes.NewBuffer([]byte(nil))
+	cmd.Stderr = stderr
+
+	ffmpeg.acquire()
+	err := cmd.Run()
+	ffmpeg.release()
+
+	if err != nil {
+		return nil, fmt.Errorf("loudnorm measurement failed: %w: %s", err, stderrTail(stderr.String(), ffmpegStderrTail))
+	}
+
+	return parseLoudnormStats(stderr.String())
+}
+
+// aacBitrate is the bitrate of the default AAC output, in bits per second.
+const aacBitrate = 48000
+
+// Range of the per-system AAC bitrate override, in kbps.
+const (
+	aacBitrateMin = 16
+	aacBitrateMax = 320
+)
+
+// convertTarget returns the format and AAC bitrate (bits per second) Convert
+// produces for calls of system: the system's overrides, else the global audio
+// format and aacBitrate. Tone-out channels can keep more quality this way
+// while voice-only systems stay small.
+func convertTarget(system *System, options *Options) (string, int) {
+	format, bitrate := options.AudioFormat, aacBitrate
+	if system != nil {
+		if system.AudioFormat != "" {
+			format = system.AudioFormat
+		}
+		if system.AudioBitrate > 0 {
+			bitrate = int(system.AudioBitrate) * 1000
+		}
+	}
+	return format, bitrate
+}
+
+// matchesTarget reports whether audio already is what Convert would produce
+// for format: FLAC, or AAC in an MP4 container at no more than bitrate.
+// Any probe failure (including ffprobe missing) answers false so the audio
+// is simply encoded as usual.
+func (ffmpeg *FFMpeg) matchesTarget(audio []byte, format string, bitrate int) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "ffprobe",
+		"-v", "error",
+		"-select_streams", "a:0",
+		"-show_entries", "stream=codec_name,bit_rate",
+		"-show_entries", "format=format_name,bit_rate",
+		"-of", "json",
+		"-",
+	)
+	cmd.Stdin = bytes.NewReader(audio)
+
+	stdout := bytes.NewBuffer([]byte(nil))
+	cmd.Stdout = stdout
+
+	if err := cmd.Run(); err != nil {
+		return false
+	}
+
+	var result struct {
+		Streams []struct {
+			CodecName string `json:"codec_name"`
+			BitRate   string `json:"bit_rate"`
+		} `json:"streams"`
+		Format struct {
+			FormatName string `json:"format_name"`
+			BitRate    string `json:"bit_rate"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil || len(result.Streams) == 0 {
+		return false
+	}
+
+	stream := result.Streams[0]
+
+	if format == AUDIO_FORMAT_FLAC {
+		return stream.CodecName == "flac" && result.Format.FormatName == "flac"
+	}
+
+	if stream.CodecName != "aac" || !strings.Contains(result.Format.FormatName, "mp4") {
+		return false
+	}
+
+	bitRate := stream.BitRate
+	if bitRate == "" || bitRate == "N/A" {
+		bitRate = result.Format.BitRate
+	}
+	rate, err := strconv.Atoi(bitRate)
+
+	return err == nil && rate > 0 && rate <= bitrate
+}
+
+// ffmpegStderrTail bounds how much of ffmpeg's stderr is carried in an error;
+// the banner comes first and the actual failure reason is at the end.
+const ffmpegStderrTail = 512
+
+func stderrTail(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if len(s) > n {
+		s = "..." + s[len(s)-n:]
+	}
+	return s
+}
+
+// silenceTrimFilter returns a filter chain that cuts leading and trailing
+// silence quieter than thresholdDb down to keepMs on each side. Silence in
+// the middle of a call is left alone: the trailing edge is handled by
+// reversing the stream and trimming its start again.
+func silenceTrimFilter(thresholdDb int, keepMs uint) string {
+	trim := fmt.Sprintf("silenceremove=start_periods=1:start_threshold=%ddB:start_silence=%.3f", thresholdDb, float64(keepMs)/1000)
+
+	return strings.Join([]string{trim, "areverse", trim, "areverse"}, ",")
+}
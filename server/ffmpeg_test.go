@@ -0,0 +1,96 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConvertBadInputReturnsError(t *testing.T) {
+	ffmpeg := NewFFMpeg(0)
+	if !ffmpeg.available {
+		t.Skip("ffmpeg not installed")
+	}
+
+	call := NewCall()
+	call.Audio = []byte("definitely not audio")
+	call.AudioFilename = "bad.wav"
+	call.AudioMime = "audio/wav"
+	call.System = NewSystem()
+	call.Talkgroup = NewTalkgroup()
+
+	options := NewOptions()
+	options.AudioConversion = AUDIO_CONVERSION_ENABLED
+	options.AudioFormat = AUDIO_FORMAT_AAC
+
+	err := ffmpeg.Convert(call, NewSystems(), NewTags(), options)
+	if err == nil {
+		t.Fatalf("expected an error for invalid input")
+	}
+	if call.AudioFilename != "bad.wav" || call.AudioMime != "audio/wav" {
+		t.Fatalf("call was modified on failure: %s %s", call.AudioFilename, call.AudioMime)
+	}
+}
+
+func TestStderrTail(t *testing.T) {
+	if got := stderrTail("  short\n", 10); got != "short" {
+		t.Fatalf("got %q", got)
+	}
+	if got := stderrTail(strings.Repeat("a", 20)+"END", 5); got != "...aaEND" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestParseLoudnormStats(t *testing.T) {
+	stderr := `[Parsed_loudnorm_9 @ 0x55d] 
+{
+	"input_i" : "-27.61",
+	"input_tp" : "-4.47",
+	"input_lra" : "18.06",
+	"input_thresh" : "-39.20",
+	"output_i" : "-16.58",
+	"output_tp" : "-1.50",
+	"output_lra" : "14.78",
+	"output_thresh" : "-27.71",
+	"normalization_type" : "dynamic",
+	"target_offset" : "0.58"
+}
+`
+	stats, err := parseLoudnormStats(stderr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := ":measured_I=-27.61:measured_TP=-4.47:measured_LRA=18.06:measured_thresh=-39.20:offset=0.58:linear=true"
+	if got := stats.filterParams(); got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+
+	if _, err := parseLoudnormStats(`{"input_i" : "-inf", "input_tp" : "-inf", "input_lra" : "0.00", "input_thresh" : "-70.00", "target_offset" : "0.00"}`); err == nil {
+		t.Fatalf("expected error for silent input")
+	}
+	if _, err := parseLoudnormStats("no json here"); err == nil {
+		t.Fatalf("expected error without measurement")
+	}
+}
+
+func TestConvertTarget(t *testing.T) {
+	options := &Options{AudioFormat: AUDIO_FORMAT_AAC}
+
+	if format, bitrate := convertTarget(nil, options); format != AUDIO_FORMAT_AAC || bitrate != aacBitrate {
+		t.Fatalf("no system: %s %d", format, bitrate)
+	}
+
+	system := NewSystem().FromMap(map[string]any{"audioFormat": "flac", "audioBitrate": float64(96)})
+	if format, bitrate := convertTarget(system, options); format != AUDIO_FORMAT_FLAC || bitrate != 96000 {
+		t.Fatalf("overrides: %s %d", format, bitrate)
+	}
+
+	system = NewSystem().FromMap(map[string]any{"audioFormat": "opus", "audioBitrate": float64(8)})
+	if system.AudioFormat != "" || system.AudioBitrate != 0 {
+		t.Fatalf("invalid overrides kept: %q %d", system.AudioFormat, system.AudioBitrate)
+	}
+	if format, bitrate := convertTarget(system, options); format != AUDIO_FORMAT_AAC || bitrate != aacBitrate {
+		t.Fatalf("invalid overrides: %s %d", format, bitrate)
+	}
+}
@@ -16,6 +16,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -56,6 +57,11 @@ type HealthService struct {
 	cachedBody  []byte
 	cachedReady bool
 	cachedCode  int
+
+	readyzMu   sync.Mutex
+	readyzAt   time.Time
+	readyzBody []byte
+	readyzCode int
 }
 
 const healthCacheTTL = 3 * time.Second
@@ -294,6 +300,9 @@ func (hs *HealthService) gather() (map[string]interface{}, bool) {
 		strings.TrimSpace(opts.CentralManagementAPIKey) != ""
 	payload["central_management_enabled"] = opts.CentralManagementEnabled
 	payload["central_management_paired"] = cmPaired
+	if cmPaired && ctrl.CentralManagement != nil {
+		payload["central_management_url"] = ctrl.CentralManagement.ActiveURL()
+	}
 	payload["relay_configured"] = opts.RelayServerAPIKey != ""
 	payload["hydra_transcription_enabled"] = opts.HydraTranscriptionEnabled
 	payload["hydra_api_key_present"] = strings.TrimSpace(opts.HydraAPIKey) != ""
@@ -306,3 +315,127 @@ func (hs *HealthService) gather() (map[string]interface{}, bool) {
 	}
 	return payload, ready
 }
+
+// probeTimeout bounds each dependency check made by /readyz so a hung database
+// or transcription server fails the probe instead of stalling it.
+const probeTimeout = 2 * time.Second
+
+// ProbeCheck is one subsystem's entry in the /readyz body.
+type ProbeCheck struct {
+	OK     bool           `json:"ok"`
+	Error  string         `json:"error,omitempty"`
+	Detail map[string]any `json:"detail,omitempty"`
+}
+
+// HealthzHandler is the unauthenticated liveness probe for orchestrators:
+// it answers 200 for as long as the process can serve HTTP.
+func (hs *HealthService) HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if r.Method == http.MethodGet {
+		_, _ = fmt.Fprint(w, `{"status":"ok"}`)
+	}
+}
+
+// ReadyzHandler is the unauthenticated readiness probe. It returns 503 unless
+// startup has finished, the database answers SELECT 1, ingest is accepting
+// calls and, when transcription is enabled, the provider is reachable. Only
+// pass/fail and counters are exposed, not the detail served by /api/health.
+func (hs *HealthService) ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, code := hs.readyzCached()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(body)
+	}
+}
+
+func (hs *HealthService) readyzCached() ([]byte, int) {
+	hs.readyzMu.Lock()
+	defer hs.readyzMu.Unlock()
+
+	if hs.readyzBody != nil && time.Since(hs.readyzAt) < healthCacheTTL {
+		return hs.readyzBody, hs.readyzCode
+	}
+
+	checks, ready := hs.probe()
+	status, code := "ok", http.StatusOK
+	if !ready {
+		status, code = "unavailable", http.StatusServiceUnavailable
+	}
+
+	body, err := json.Marshal(map[string]any{"status": status, "checks": checks})
+	if err != nil {
+		body, code = []byte(`{"status":"error"}`), http.StatusInternalServerError
+	}
+
+	hs.readyzBody, hs.readyzCode, hs.readyzAt = body, code, time.Now()
+	return body, code
+}
+
+// probe runs the /readyz checks and reports whether all of them passed.
+func (hs *HealthService) probe() (map[string]ProbeCheck, bool) {
+	ctrl := hs.controller
+	checks := map[string]ProbeCheck{}
+
+	checks["startup"] = ProbeCheck{OK: ctrl.IsStartupReady()}
+	if !checks["startup"].OK {
+		checks["startup"] = ProbeCheck{Error: "still loading"}
+	}
+
+	if ctrl.Database == nil || ctrl.Database.Sql == nil {
+		checks["database"] = ProbeCheck{Error: "not connected"}
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+		started := time.Now()
+		var one int
+		err := ctrl.Database.Sql.QueryRowContext(ctx, `SELECT 1`).Scan(&one)
+		cancel()
+		if err != nil {
+			checks["database"] = ProbeCheck{Error: "SELECT 1 failed"}
+		} else {
+			checks["database"] = ProbeCheck{OK: true, Detail: map[string]any{"latency_ms": time.Since(started).Milliseconds()}}
+		}
+	}
+
+	ingest := ProbeCheck{OK: true, Detail: map[string]any{"queue_depth": len(ctrl.Ingest), "queue_capacity": cap(ctrl.Ingest)}}
+	switch {
+	case ctrl.draining.Load():
+		ingest = ProbeCheck{Error: "draining for restart", Detail: ingest.Detail}
+	case cap(ctrl.Ingest) > 0 && len(ctrl.Ingest) >= cap(ctrl.Ingest):
+		ingest = ProbeCheck{Error: "queue full", Detail: ingest.Detail}
+	}
+	checks["ingest"] = ingest
+
+	if ctrl.Options.TranscriptionConfig.Enabled {
+		if ctrl.TranscriptionQueue == nil {
+			checks["transcription"] = ProbeCheck{Error: "queue not running"}
+		} else {
+			ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+			name, err := ctrl.TranscriptionQueue.ProviderStatus(ctx)
+			cancel()
+			check := ProbeCheck{OK: err == nil, Detail: map[string]any{"provider": ctrl.Options.TranscriptionConfig.Provider, "queue_depth": ctrl.TranscriptionQueue.QueueDepth()}}
+			if err != nil {
+				check.Error = fmt.Sprintf("%s unreachable", name)
+			}
+			checks["transcription"] = check
+		}
+	}
+
+	ready := true
+	for _, check := range checks {
+		ready = ready && check.OK
+	}
+	return checks, ready
+}
@@ -0,0 +1,47 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHealthzAndReadyz(t *testing.T) {
+	controller := &Controller{Options: NewOptions(), Ingest: make(chan *Call, 2)}
+	hs := NewHealthService(controller)
+
+	rec := httptest.NewRecorder()
+	hs.HealthzHandler(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"status":"ok"}` {
+		t.Fatalf("healthz = %d %s", rec.Code, rec.Body.String())
+	}
+
+	controller.startupReady.Store(true)
+	controller.draining.Store(true)
+
+	rec = httptest.NewRecorder()
+	hs.ReadyzHandler(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("readyz status = %d, want 503", rec.Code)
+	}
+
+	var body struct {
+		Status string                `json:"status"`
+		Checks map[string]ProbeCheck `json:"checks"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Status != "unavailable" || !body.Checks["startup"].OK {
+		t.Fatalf("readyz body = %s", rec.Body.String())
+	}
+	if body.Checks["database"].OK || body.Checks["ingest"].Error != "draining for restart" {
+		t.Fatalf("readyz checks = %+v", body.Checks)
+	}
+	if _, ok := body.Checks["transcription"]; ok {
+		t.Fatal("transcription checked while disabled")
+	}
+}
@@ -16,150 +16,182 @@
 package main
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 	"sync"
 )
 
+// Keyword matching modes. The mode is encoded in the keyword itself so that
+// keyword lists and user preferences keep storing plain strings:
+//
+//	fire       whole-word match (default)
+//	*fire*     substring match, also hits "campfire"
+//	/fire\d+/  regular expression, matched case-insensitively
+const (
+	KeywordModeWord      = "word"
+	KeywordModeSubstring = "substring"
+	KeywordModeRegex     = "regex"
+)
+
 // KeywordMatch represents a matched keyword in a transcript
 type KeywordMatch struct {
 	Keyword  string
+	Mode     string // Matching mode that produced this match
 	UserId   uint64
 	Context  string // Surrounding text (50 chars each side)
 	Position int    // Character position in transcript
 	CallId   uint64
 }
 
+type compiledKeyword struct {
+	re   *regexp.Regexp
+	mode string
+	err  error
+}
+
 // KeywordMatcher handles keyword matching in transcripts
 type KeywordMatcher struct {
 	contextChars int
 
-	// Compiled regex cache: keyed by the uppercased keyword so the same
+	// Compiled regex cache: keyed by the trimmed keyword so the same
 	// pattern is only compiled once for the lifetime of the process.
-	mu      sync.RWMutex
-	compiled map[string]*regexp.Regexp
+	mu       sync.RWMutex
+	compiled map[string]compiledKeyword
 }
 
 // NewKeywordMatcher creates a new keyword matcher
 func NewKeywordMatcher() *KeywordMatcher {
 	return &KeywordMatcher{
 		contextChars: 50,
-		compiled:     make(map[string]*regexp.Regexp),
+		compiled:     make(map[string]compiledKeyword),
 	}
 }
 
-// getCompiledPattern returns a cached *regexp.Regexp for the given uppercased
-// keyword, compiling and caching it on first use.
-func (matcher *KeywordMatcher) getCompiledPattern(keywordUpper string) (*regexp.Regexp, error) {
-	matcher.mu.RLock()
-	re, ok := matcher.compiled[keywordUpper]
-	matcher.mu.RUnlock()
-	if ok {
-		return re, nil
+// parseKeyword splits a stored keyword into its matching mode and the text
+// or pattern to match.
+func parseKeyword(keyword string) (string, string) {
+	keyword = strings.TrimSpace(keyword)
+	if len(keyword) > 2 && strings.HasPrefix(keyword, "/") && strings.HasSuffix(keyword, "/") {
+		return KeywordModeRegex, keyword[1 : len(keyword)-1]
+	}
+	if len(keyword) > 2 && strings.HasPrefix(keyword, "*") && strings.HasSuffix(keyword, "*") {
+		return KeywordModeSubstring, strings.TrimSpace(keyword[1 : len(keyword)-1])
+	}
+	return KeywordModeWord, keyword
+}
+
+func compileKeyword(keyword string) compiledKeyword {
+	mode, text := parseKeyword(keyword)
+
+	var pattern string
+	switch mode {
+	case KeywordModeRegex:
+		pattern = "(?i)" + text
+	case KeywordModeSubstring:
+		pattern = regexp.QuoteMeta(strings.ToUpper(text))
+	default:
+		pattern = `\b` + regexp.QuoteMeta(strings.ToUpper(text)) + `\b`
 	}
 
-	pattern := `\b` + regexp.QuoteMeta(keywordUpper) + `\b`
 	re, err := regexp.Compile(pattern)
 	if err != nil {
-		return nil, err
+		err = fmt.Errorf("invalid keyword pattern %q: %v", keyword, err)
+	} else if re.MatchString("") {
+		// A pattern matching the empty string would alert on every call.
+		err = fmt.Errorf("invalid keyword pattern %q: matches empty text", keyword)
+	}
+	return compiledKeyword{re: re, mode: mode, err: err}
+}
+
+// getCompiledPattern returns the cached compiled form of the given keyword,
+// compiling and caching it on first use. Invalid patterns are cached too so
+// they are only reported once.
+func (matcher *KeywordMatcher) getCompiledPattern(keyword string) compiledKeyword {
+	key := strings.TrimSpace(keyword)
+
+	matcher.mu.RLock()
+	compiled, ok := matcher.compiled[key]
+	matcher.mu.RUnlock()
+	if ok {
+		return compiled
 	}
 
+	compiled = compileKeyword(key)
+
 	matcher.mu.Lock()
-	matcher.compiled[keywordUpper] = re
+	matcher.compiled[key] = compiled
 	matcher.mu.Unlock()
-	return re, nil
+	return compiled
+}
+
+// Prepare compiles the given keywords ahead of matching and returns the
+// first invalid pattern, if any.
+func (matcher *KeywordMatcher) Prepare(keywords []string) error {
+	for _, keyword := range keywords {
+		if strings.TrimSpace(keyword) == "" {
+			continue
+		}
+		if compiled := matcher.getCompiledPattern(keyword); compiled.err != nil {
+			return compiled.err
+		}
+	}
+	return nil
 }
 
-// MatchKeywords matches keywords against a transcript (case-insensitive, whole-word only)
-// Transcript should already be in ALL CAPS
+// ValidateKeywords reports the first keyword whose pattern cannot be used for
+// matching, so admin and user saves can reject it up front.
+func ValidateKeywords(keywords []string) error {
+	for _, keyword := range keywords {
+		if strings.TrimSpace(keyword) == "" {
+			continue
+		}
+		if compiled := compileKeyword(strings.TrimSpace(keyword)); compiled.err != nil {
+			return compiled.err
+		}
+	}
+	return nil
+}
+
+// MatchKeywords matches keywords against a transcript, case-insensitively,
+// using the matching mode encoded in each keyword.
 func (matcher *KeywordMatcher) MatchKeywords(transcript string, keywords []string) []KeywordMatch {
 	matches := []KeywordMatch{}
-	
+
 	if transcript == "" || len(keywords) == 0 {
 		return matches
 	}
-	
+
 	// Ensure transcript is uppercase
 	transcriptUpper := strings.ToUpper(transcript)
-	
+
 	for _, keyword := range keywords {
-		if keyword == "" {
+		if strings.TrimSpace(keyword) == "" {
 			continue
 		}
-		
-		// Convert keyword to uppercase for case-insensitive matching
-		keywordUpper := strings.ToUpper(strings.TrimSpace(keyword))
-		
-		// Look up (or compile) the cached regex for this keyword.
-		re, err := matcher.getCompiledPattern(keywordUpper)
-		if err != nil {
-			// If regex compilation fails, fall back to simple substring matching
-			// but still check word boundaries manually
-			pos := 0
-			for {
-				index := strings.Index(transcriptUpper[pos:], keywordUpper)
-				if index == -1 {
-					break
-				}
-				
-				actualPos := pos + index
-				
-				// Check if it's a whole word match
-				if matcher.isWholeWord(transcriptUpper, actualPos, len(keywordUpper)) {
-					// Extract context (surrounding text)
-					context := matcher.extractContext(transcript, actualPos, len(keywordUpper))
-					
-					matches = append(matches, KeywordMatch{
-						Keyword:  keyword, // Store original keyword (not uppercase)
-						Context:  context,
-						Position: actualPos,
-					})
-				}
-				
-				pos = actualPos + 1
-			}
+
+		// Invalid patterns are rejected on save; skip any that predate validation.
+		compiled := matcher.getCompiledPattern(keyword)
+		if compiled.err != nil {
 			continue
 		}
-		
-		// Find all whole-word matches using regex
-		allMatches := re.FindAllStringIndex(transcriptUpper, -1)
-		for _, match := range allMatches {
+
+		for _, match := range compiled.re.FindAllStringIndex(transcriptUpper, -1) {
 			actualPos := match[0]
-			
+
 			// Extract context (surrounding text)
-			context := matcher.extractContext(transcript, actualPos, len(keywordUpper))
-			
+			context := matcher.extractContext(transcript, actualPos, match[1]-match[0])
+
 			matches = append(matches, KeywordMatch{
 				Keyword:  keyword, // Store original keyword (not uppercase)
+				Mode:     compiled.mode,
 				Context:  context,
 				Position: actualPos,
 			})
 		}
 	}
-	
-	return matches
-}
 
-// isWholeWord checks if a substring at the given position is a whole word
-// (not preceded or followed by alphanumeric characters)
-func (matcher *KeywordMatcher) isWholeWord(text string, pos int, length int) bool {
-	// Check character before the match
-	if pos > 0 {
-		charBefore := text[pos-1]
-		if (charBefore >= 'A' && charBefore <= 'Z') || (charBefore >= 'a' && charBefore <= 'z') || (charBefore >= '0' && charBefore <= '9') {
-			return false
-		}
-	}
-	
-	// Check character after the match
-	if pos+length < len(text) {
-		charAfter := text[pos+length]
-		if (charAfter >= 'A' && charAfter <= 'Z') || (charAfter >= 'a' && charAfter <= 'z') || (charAfter >= '0' && charAfter <= '9') {
-			return false
-		}
-	}
-	
-	return true
+	return matches
 }
 
 // extractContext extracts surrounding text from a transcript
@@ -0,0 +1,59 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchKeywordsModes(t *testing.T) {
+	matcher := NewKeywordMatcher()
+	transcript := "Engine 3 clearing the campfire, no fire found. Box alarm 4417"
+
+	cases := []struct {
+		keyword string
+		mode    string
+		count   int
+	}{
+		{"fire", KeywordModeWord, 1},
+		{"*fire*", KeywordModeSubstring, 2},
+		{"/box alarm \\d+/", KeywordModeRegex, 1},
+		{"/CAMP(FIRE|SITE)/", KeywordModeRegex, 1},
+		{"alarm 44", KeywordModeWord, 0},
+	}
+	for _, tc := range cases {
+		matches := matcher.MatchKeywords(transcript, []string{tc.keyword})
+		if len(matches) != tc.count {
+			t.Fatalf("%q matched %d times, want %d", tc.keyword, len(matches), tc.count)
+		}
+		for _, match := range matches {
+			if match.Mode != tc.mode || match.Keyword != tc.keyword {
+				t.Fatalf("%q matched as %q in mode %q, want mode %q", tc.keyword, match.Keyword, match.Mode, tc.mode)
+			}
+		}
+	}
+
+	// Context spans the whole regex match, not just the pattern length.
+	matches := matcher.MatchKeywords(transcript, []string{"/box alarm \\d+/"})
+	if !strings.HasSuffix(matches[0].Context, "Box alarm 4417") {
+		t.Fatalf("regex context %q should include the full matched span", matches[0].Context)
+	}
+}
+
+func TestValidateKeywords(t *testing.T) {
+	if err := ValidateKeywords([]string{"fire", "*smoke*", "/struct(ure)? fire/", ""}); err != nil {
+		t.Fatalf("valid keywords rejected: %v", err)
+	}
+	if err := ValidateKeywords([]string{"fire", "/fire(/"}); err == nil {
+		t.Fatal("unbalanced regex should be rejected")
+	}
+	if err := ValidateKeywords([]string{"/.*/"}); err == nil {
+		t.Fatal("regex matching empty text should be rejected")
+	}
+
+	matcher := NewKeywordMatcher()
+	if matches := matcher.MatchKeywords("fire fire", []string{"/fire(/"}); len(matches) != 0 {
+		t.Fatalf("invalid pattern produced %d matches", len(matches))
+	}
+}
@@ -16,7 +16,9 @@
 package main
 
 import (
+	"encoding/json"
 	"strconv"
+	"strings"
 	"sync"
 )
 
@@ -67,6 +69,68 @@ func (livefeed *Livefeed) FromMap(f any) *Livefeed {
 	return livefeed
 }
 
+// NewLivefeedDefaults builds the initial livefeed matrix for a fresh client: every
+// talkgroup carrying one of the given tag labels is enabled. Only talkgroups in
+// the client's scoped tags map are considered, so access rules still apply.
+func NewLivefeedDefaults(tagsMap TagsMap, labels []string) map[uint]map[uint]bool {
+	matrix := map[uint]map[uint]bool{}
+
+	for tag, systems := range tagsMap {
+		enabled := false
+		for _, label := range labels {
+			if strings.EqualFold(strings.TrimSpace(label), tag) {
+				enabled = true
+				break
+			}
+		}
+		if !enabled {
+			continue
+		}
+
+		for systemId, talkgroupIds := range systems {
+			if matrix[systemId] == nil {
+				matrix[systemId] = map[uint]bool{}
+			}
+			for _, talkgroupId := range talkgroupIds {
+				matrix[systemId][talkgroupId] = true
+			}
+		}
+	}
+
+	return matrix
+}
+
+// parseLivefeedTags reads a list of tag labels given either as a JSON array
+// or as a comma-separated string.
+func parseLivefeedTags(f any) []string {
+	labels := []string{}
+
+	switch v := f.(type) {
+	case []string:
+		for _, label := range v {
+			if label = strings.TrimSpace(label); label != "" {
+				labels = append(labels, label)
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if label, ok := item.(string); ok {
+				if label = strings.TrimSpace(label); label != "" {
+					labels = append(labels, label)
+				}
+			}
+		}
+	case string:
+		var list []any
+		if err := json.Unmarshal([]byte(v), &list); err == nil {
+			return parseLivefeedTags(list)
+		}
+		return parseLivefeedTags(strings.Split(v, ","))
+	}
+
+	return labels
+}
+
 func (livefeed *Livefeed) IsAllOff() bool {
 	livefeed.mutex.Lock()
 	defer livefeed.mutex.Unlock()
@@ -0,0 +1,47 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewLivefeedDefaults(t *testing.T) {
+	tagsMap := TagsMap{
+		"Fire":   {1: {10, 11}, 2: {20}},
+		"Police": {1: {12}},
+		"EMS":    {2: {21}},
+	}
+
+	got := NewLivefeedDefaults(tagsMap, []string{" fire ", "EMS", "Unknown"})
+	want := map[uint]map[uint]bool{
+		1: {10: true, 11: true},
+		2: {20: true, 21: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("defaults = %v, want %v", got, want)
+	}
+
+	if got := NewLivefeedDefaults(tagsMap, nil); len(got) != 0 {
+		t.Fatalf("defaults without tags = %v, want empty", got)
+	}
+}
+
+func TestParseLivefeedTags(t *testing.T) {
+	cases := []struct {
+		in   any
+		want []string
+	}{
+		{`["Fire", " EMS ", ""]`, []string{"Fire", "EMS"}},
+		{"Fire, EMS,,", []string{"Fire", "EMS"}},
+		{[]any{"Fire", 3.0, "Police"}, []string{"Fire", "Police"}},
+		{"", []string{}},
+		{nil, []string{}},
+	}
+	for _, c := range cases {
+		if got := parseLivefeedTags(c.in); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("parseLivefeedTags(%#v) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
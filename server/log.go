@@ -18,7 +18,10 @@ package main
 import (
 	"context"
 	"database/sql"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
 	"math"
 	"strings"
 	"sync"
@@ -32,11 +35,24 @@ const (
 )
 
 type Log struct {
-	Id       any       `json:"id"`
-	DateTime time.Time `json:"dateTime"`
-	Level    string    `json:"level"`
-	Category string    `json:"category"`
-	Message  string    `json:"message"`
+	Id          any            `json:"id"`
+	DateTime    time.Time      `json:"dateTime"`
+	Level       string         `json:"level"`
+	Category    string         `json:"category"`
+	Message     string         `json:"message"`
+	CallId      uint64         `json:"callId,omitempty"`
+	SystemId    uint64         `json:"systemId,omitempty"`
+	TalkgroupId uint64         `json:"talkgroupId,omitempty"`
+	Context     map[string]any `json:"context,omitempty"`
+}
+
+// LogContext is the optional structured data attached to a log entry. Ids are
+// database ids (as on calls); zero means not set.
+type LogContext struct {
+	CallId      uint64
+	SystemId    uint64
+	TalkgroupId uint64
+	Fields      map[string]any
 }
 
 func NewLog() *Log {
@@ -56,6 +72,12 @@ func NewLogs() *Logs {
 }
 
 func (logs *Logs) LogEvent(level string, message string) error {
+	return logs.LogEventWithContext(level, message, nil)
+}
+
+// LogEventWithContext logs like LogEvent and stores ctx alongside the entry so
+// it can be searched by call or system and linked back to the call.
+func (logs *Logs) LogEventWithContext(level string, message string, ctx *LogContext) error {
 	category := CategorizeLogMessage(message)
 
 	logs.mutex.Lock()
@@ -83,8 +105,21 @@ func (logs *Logs) LogEvent(level string, message string) error {
 			Message:  message,
 		}
 
-		query := `INSERT INTO "logs" ("level", "category", "message", "timestamp") VALUES ($1, $2, $3, $4)`
-		if _, err := logs.database.Sql.Exec(query, l.Level, l.Category, l.Message, l.DateTime.UnixMilli()); err != nil {
+		var callId, systemId, talkgroupId sql.NullInt64
+		var contextJSON sql.NullString
+		if ctx != nil {
+			callId = sql.NullInt64{Int64: int64(ctx.CallId), Valid: ctx.CallId > 0}
+			systemId = sql.NullInt64{Int64: int64(ctx.SystemId), Valid: ctx.SystemId > 0}
+			talkgroupId = sql.NullInt64{Int64: int64(ctx.TalkgroupId), Valid: ctx.TalkgroupId > 0}
+			if len(ctx.Fields) > 0 {
+				if b, err := json.Marshal(ctx.Fields); err == nil {
+					contextJSON = sql.NullString{String: string(b), Valid: true}
+				}
+			}
+		}
+
+		query := `INSERT INTO "logs" ("level", "category", "message", "timestamp", "callId", "systemId", "talkgroupId", "context") VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+		if _, err := logs.database.Sql.Exec(query, l.Level, l.Category, l.Message, l.DateTime.UnixMilli(), callId, systemId, talkgroupId, contextJSON); err != nil {
 			return fmt.Errorf("logs.logevent: %s in %s", err, query)
 		}
 	}
@@ -92,18 +127,38 @@ func (logs *Logs) LogEvent(level string, message string) error {
 	return nil
 }
 
-func (logs *Logs) Prune(db *Database, pruneDays uint) error {
+// Prune deletes log entries older than pruneDays and returns how many were removed.
+// It holds the logs mutex, so it never runs alongside a Search.
+func (logs *Logs) Prune(db *Database, pruneDays uint) (int64, error) {
 	logs.mutex.Lock()
 	defer logs.mutex.Unlock()
 
-	timestamp := time.Now().Add(-24 * time.Hour * time.Duration(pruneDays)).UnixMilli()
-	query := fmt.Sprintf(`DELETE FROM "logs" WHERE "timestamp" < %d`, timestamp)
+	query := fmt.Sprintf(`DELETE FROM "logs" WHERE "timestamp" < %d`, logsPruneCutoff(pruneDays))
 
-	if _, err := db.Sql.Exec(query); err != nil {
-		return fmt.Errorf("%s in %s", err, query)
+	res, err := db.Sql.Exec(query)
+	if err != nil {
+		return 0, fmt.Errorf("%s in %s", err, query)
 	}
 
-	return nil
+	removed, _ := res.RowsAffected()
+	return removed, nil
+}
+
+// PruneCount returns how many log entries Prune would delete, without
+// deleting them.
+func (logs *Logs) PruneCount(db *Database, pruneDays uint) (int64, error) {
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM "logs" WHERE "timestamp" < %d`, logsPruneCutoff(pruneDays))
+
+	var count int64
+	if err := db.Sql.QueryRow(query).Scan(&count); err != nil {
+		return 0, fmt.Errorf("%s in %s", err, query)
+	}
+
+	return count, nil
+}
+
+func logsPruneCutoff(pruneDays uint) int64 {
+	return time.Now().Add(-24 * time.Hour * time.Duration(pruneDays)).UnixMilli()
 }
 
 func (logs *Logs) PurgeAll(db *Database) error {
@@ -162,13 +217,16 @@ func (logs *Logs) Search(searchOptions *LogsSearchOptions, db *Database) (*LogsS
 		order  string
 		query  string
 
-		whereConditions []string
-
 		level     sql.NullString
 		category  sql.NullString
 		logId     sql.NullInt64
 		message   sql.NullString
 		timestamp sql.NullInt64
+
+		callId      sql.NullInt64
+		systemId    sql.NullInt64
+		talkgroupId sql.NullInt64
+		contextJSON sql.NullString
 	)
 
 	logs.mutex.Lock()
@@ -183,32 +241,6 @@ func (logs *Logs) Search(searchOptions *LogsSearchOptions, db *Database) (*LogsS
 		// The date picker in the UI will simply have no enforced min/max boundary.
 	}
 
-	// Level filter
-	switch v := searchOptions.Level.(type) {
-	case string:
-		whereConditions = append(whereConditions, fmt.Sprintf(`"level" = '%s'`, escapeSQLString(v)))
-	}
-
-	// Category filter
-	if cats := FilterLogCategories(searchOptions.Categories); len(cats) > 0 {
-		quoted := make([]string, len(cats))
-		for i, c := range cats {
-			quoted[i] = "'" + escapeSQLString(c) + "'"
-		}
-		whereConditions = append(whereConditions, `"category" IN (`+strings.Join(quoted, ",")+`)`)
-	}
-
-	// Keyword / text search filter — case-insensitive substring match on the message.
-	switch v := searchOptions.Search.(type) {
-	case string:
-		if v != "" {
-			escaped := strings.ReplaceAll(v, `\`, `\\`)
-			escaped = strings.ReplaceAll(escaped, `%`, `\%`)
-			escaped = strings.ReplaceAll(escaped, `_`, `\_`)
-			whereConditions = append(whereConditions, fmt.Sprintf(`"message" ILIKE '%%%s%%' ESCAPE '\'`, escaped))
-		}
-	}
-
 	// Sort order
 	switch v := searchOptions.Sort.(type) {
 	case int:
@@ -221,24 +253,8 @@ func (logs *Logs) Search(searchOptions *LogsSearchOptions, db *Database) (*LogsS
 		order = ascOrder
 	}
 
-	const maxSafeTimestampMs = int64(253402300800000)
-	whereConditions = append(whereConditions, fmt.Sprintf(`"timestamp" > 0 AND "timestamp" < %d`, maxSafeTimestampMs))
-
-	// Date filter
-	switch v := searchOptions.Date.(type) {
-	case time.Time:
-		whereConditions = append(whereConditions, fmt.Sprintf(`"timestamp" >= %d`, v.UnixMilli()))
-	default:
-		if order == descOrder {
-			defaultLookback := time.Now().Add(-24 * time.Hour)
-			whereConditions = append(whereConditions, fmt.Sprintf(`"timestamp" >= %d`, defaultLookback.UnixMilli()))
-		}
-	}
-
-	where := "TRUE"
-	if len(whereConditions) > 0 {
-		where = strings.Join(whereConditions, " AND ")
-	}
+	where, window := searchOptions.whereClause(order == descOrder)
+	logResults.Window = window
 
 	switch v := searchOptions.Limit.(type) {
 	case uint:
@@ -254,7 +270,7 @@ func (logs *Logs) Search(searchOptions *LogsSearchOptions, db *Database) (*LogsS
 
 	queryLimit := limit + 1
 
-	query = fmt.Sprintf(`SELECT "logId", "level", "category", "message", "timestamp" FROM "logs" WHERE %s ORDER BY "timestamp" %s LIMIT %d OFFSET %d`, where, order, queryLimit, offset)
+	query = fmt.Sprintf(`SELECT %s FROM "logs" WHERE %s ORDER BY "timestamp" %s LIMIT %d OFFSET %d`, logColumns, where, order, queryLimit, offset)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -271,7 +287,7 @@ func (logs *Logs) Search(searchOptions *LogsSearchOptions, db *Database) (*LogsS
 
 		l := NewLog()
 
-		if err = rows.Scan(&logId, &level, &category, &message, &timestamp); err != nil {
+		if err = rows.Scan(&logId, &level, &category, &message, &timestamp, &callId, &systemId, &talkgroupId, &contextJSON); err != nil {
 			continue
 		}
 
@@ -309,6 +325,8 @@ func (logs *Logs) Search(searchOptions *LogsSearchOptions, db *Database) (*LogsS
 			continue
 		}
 
+		l.setContext(callId, systemId, talkgroupId, contextJSON)
+
 		if uint(len(logResults.Logs)) < limit {
 			logResults.Logs = append(logResults.Logs, *l)
 		}
@@ -329,6 +347,233 @@ func (logs *Logs) Search(searchOptions *LogsSearchOptions, db *Database) (*LogsS
 	return logResults, nil
 }
 
+// logColumns is the column list read by Search and Export, in Scan order.
+const logColumns = `"logId", "level", "category", "message", "timestamp", "callId", "systemId", "talkgroupId", "context"`
+
+// setContext fills the structured fields from their nullable columns.
+func (l *Log) setContext(callId, systemId, talkgroupId sql.NullInt64, contextJSON sql.NullString) {
+	l.CallId = uint64(callId.Int64)
+	l.SystemId = uint64(systemId.Int64)
+	l.TalkgroupId = uint64(talkgroupId.Int64)
+	l.Context = nil
+	if contextJSON.Valid && contextJSON.String != "" {
+		json.Unmarshal([]byte(contextJSON.String), &l.Context)
+	}
+}
+
+func optionalLogId(id uint64) string {
+	if id == 0 {
+		return ""
+	}
+	return fmt.Sprint(id)
+}
+
+// logExportFetchSize is the number of rows pulled per FETCH from the export cursor.
+const logExportFetchSize = 1000
+
+// Export writes every log matching the search filters to w as "csv" or
+// "ndjson", ignoring limit and offset. Rows are read in batches through a
+// server-side cursor so a large window is never held in memory; flush, when
+// set, is called after each batch. It returns the number of logs written.
+func (logs *Logs) Export(searchOptions *LogsSearchOptions, db *Database, w io.Writer, format string, flush func()) (int, error) {
+	var (
+		level     sql.NullString
+		category  sql.NullString
+		logId     sql.NullInt64
+		message   sql.NullString
+		timestamp sql.NullInt64
+
+		callId      sql.NullInt64
+		systemId    sql.NullInt64
+		talkgroupId sql.NullInt64
+		contextJSON sql.NullString
+	)
+
+	formatError := errorFormatter("logs", "export")
+
+	order := "ASC"
+	if v, ok := searchOptions.Sort.(int); ok && v < 0 {
+		order = "DESC"
+	}
+	where, _ := searchOptions.whereClause(order == "DESC")
+
+	tx, err := db.Sql.Begin()
+	if err != nil {
+		return 0, formatError(err, "")
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`DECLARE "logsExport" NO SCROLL CURSOR FOR SELECT %s FROM "logs" WHERE %s ORDER BY "timestamp" %s`, logColumns, where, order)
+	if _, err = tx.Exec(query); err != nil {
+		return 0, formatError(err, query)
+	}
+
+	var (
+		csvWriter   *csv.Writer
+		jsonEncoder *json.Encoder
+	)
+	if format == "csv" {
+		csvWriter = csv.NewWriter(w)
+		csvWriter.Write([]string{"id", "dateTime", "level", "category", "message", "callId", "systemId", "talkgroupId", "context"})
+	} else {
+		jsonEncoder = json.NewEncoder(w)
+	}
+
+	count := 0
+	fetch := fmt.Sprintf(`FETCH %d FROM "logsExport"`, logExportFetchSize)
+	for {
+		rows, err := tx.Query(fetch)
+		if err != nil {
+			return count, formatError(err, fetch)
+		}
+
+		fetched := 0
+		for rows.Next() {
+			fetched++
+
+			if err = rows.Scan(&logId, &level, &category, &message, &timestamp, &callId, &systemId, &talkgroupId, &contextJSON); err != nil || !logId.Valid || !timestamp.Valid {
+				continue
+			}
+
+			l := &Log{
+				Id:       uint64(logId.Int64),
+				DateTime: time.UnixMilli(timestamp.Int64),
+				Level:    level.String,
+				Category: category.String,
+				Message:  message.String,
+			}
+			if l.Category == "" {
+				l.Category = CategorizeLogMessage(l.Message)
+			}
+			l.setContext(callId, systemId, talkgroupId, contextJSON)
+
+			if csvWriter != nil {
+				err = csvWriter.Write([]string{fmt.Sprint(l.Id), l.DateTime.Format(time.RFC3339Nano), l.Level, l.Category, l.Message,
+					optionalLogId(l.CallId), optionalLogId(l.SystemId), optionalLogId(l.TalkgroupId), contextJSON.String})
+			} else {
+				err = jsonEncoder.Encode(l)
+			}
+			if err != nil {
+				rows.Close()
+				return count, err
+			}
+			count++
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return count, formatError(err, fetch)
+		}
+
+		if csvWriter != nil {
+			csvWriter.Flush()
+			if err := csvWriter.Error(); err != nil {
+				return count, err
+			}
+		}
+		if flush != nil {
+			flush()
+		}
+
+		if fetched < logExportFetchSize {
+			break
+		}
+	}
+
+	return count, nil
+}
+
+// whereClause builds the WHERE clause shared by Search and Export, along with
+// the time window it covers.
+func (searchOptions *LogsSearchOptions) whereClause(newestFirst bool) (string, *LogsSearchWindow) {
+	var whereConditions []string
+
+	// Level filter
+	if condition := logLevelCondition(searchOptions.Level); condition != "" {
+		whereConditions = append(whereConditions, condition)
+	}
+
+	// Category filter
+	if cats := FilterLogCategories(searchOptions.Categories); len(cats) > 0 {
+		quoted := make([]string, len(cats))
+		for i, c := range cats {
+			quoted[i] = "'" + escapeSQLString(c) + "'"
+		}
+		whereConditions = append(whereConditions, `"category" IN (`+strings.Join(quoted, ",")+`)`)
+	}
+
+	// Keyword / text search filter — case-insensitive substring match on the message.
+	switch v := searchOptions.Search.(type) {
+	case string:
+		if v != "" {
+			whereConditions = append(whereConditions, fmt.Sprintf(`"message" ILIKE '%%%s%%' ESCAPE '\'`, escapeSQLString(escapeLikePattern(v))))
+		}
+	}
+
+	// Structured context filters
+	if v, ok := searchOptions.CallId.(uint64); ok && v > 0 {
+		whereConditions = append(whereConditions, fmt.Sprintf(`"callId" = %d`, v))
+	}
+	if v, ok := searchOptions.SystemId.(uint64); ok && v > 0 {
+		whereConditions = append(whereConditions, fmt.Sprintf(`"systemId" = %d`, v))
+	}
+
+	const maxSafeTimestampMs = int64(253402300800000)
+	whereConditions = append(whereConditions, fmt.Sprintf(`"timestamp" > 0 AND "timestamp" < %d`, maxSafeTimestampMs))
+
+	// Date filter. An unbounded DESC search defaults to the last 24 hours to keep
+	// the big-table case fast; allDates or an explicit dateStop lifts that.
+	window := &LogsSearchWindow{}
+	dateStop, hasDateStop := searchOptions.DateStop.(time.Time)
+	switch v := searchOptions.Date.(type) {
+	case time.Time:
+		window.Start = &v
+		whereConditions = append(whereConditions, fmt.Sprintf(`"timestamp" >= %d`, v.UnixMilli()))
+	default:
+		if newestFirst && !searchOptions.AllDates && !hasDateStop {
+			defaultLookback := time.Now().Add(-24 * time.Hour)
+			window.Start = &defaultLookback
+			window.DefaultLookback = true
+			whereConditions = append(whereConditions, fmt.Sprintf(`"timestamp" >= %d`, defaultLookback.UnixMilli()))
+		}
+	}
+	if hasDateStop {
+		window.Stop = &dateStop
+		whereConditions = append(whereConditions, fmt.Sprintf(`"timestamp" <= %d`, dateStop.UnixMilli()))
+	}
+
+	return strings.Join(whereConditions, " AND "), window
+}
+
+// logLevelCondition builds the WHERE condition for a level filter, which is
+// either a single level or a list of levels (e.g. warn and error together).
+func logLevelCondition(level any) string {
+	switch v := level.(type) {
+	case string:
+		return fmt.Sprintf(`"level" = '%s'`, escapeSQLString(v))
+	case []string:
+		if len(v) == 0 {
+			return ""
+		}
+		if len(v) == 1 {
+			return logLevelCondition(v[0])
+		}
+		quoted := make([]string, len(v))
+		for i, l := range v {
+			quoted[i] = "'" + escapeSQLString(l) + "'"
+		}
+		return `"level" IN (` + strings.Join(quoted, ",") + `)`
+	}
+	return ""
+}
+
+// escapeLikePattern escapes LIKE wildcards so s matches literally with ESCAPE '\'.
+func escapeLikePattern(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `%`, `\%`)
+	return strings.ReplaceAll(s, `_`, `\_`)
+}
+
 func escapeSQLString(s string) string {
 	return strings.ReplaceAll(s, `'`, `''`)
 }
@@ -342,13 +587,17 @@ func (logs *Logs) setDatabase(d *Database) {
 }
 
 type LogsSearchOptions struct {
+	AllDates   bool     `json:"allDates,omitempty"`
+	CallId     any      `json:"callId,omitempty"`
 	Categories []string `json:"categories,omitempty"`
 	Date       any      `json:"date,omitempty"`
+	DateStop   any      `json:"dateStop,omitempty"`
 	Level      any      `json:"level,omitempty"`
 	Limit      any      `json:"limit,omitempty"`
 	Offset     any      `json:"offset,omitempty"`
 	Search     any      `json:"search,omitempty"`
 	Sort       any      `json:"sort,omitempty"`
+	SystemId   any      `json:"systemId,omitempty"`
 }
 
 func NewLogSearchOptions() *LogsSearchOptions {
@@ -356,6 +605,11 @@ func NewLogSearchOptions() *LogsSearchOptions {
 }
 
 func (searchOptions *LogsSearchOptions) FromMap(m map[string]any) *LogsSearchOptions {
+	switch v := m["callId"].(type) {
+	case float64:
+		searchOptions.CallId = uint64(v)
+	}
+
 	switch v := m["categories"].(type) {
 	case []any:
 		for _, item := range v {
@@ -367,6 +621,11 @@ func (searchOptions *LogsSearchOptions) FromMap(m map[string]any) *LogsSearchOpt
 		searchOptions.Categories = append(searchOptions.Categories, v...)
 	}
 
+	switch v := m["allDates"].(type) {
+	case bool:
+		searchOptions.AllDates = v
+	}
+
 	switch v := m["date"].(type) {
 	case string:
 		if t, err := time.Parse(time.RFC3339, v); err == nil {
@@ -374,9 +633,30 @@ func (searchOptions *LogsSearchOptions) FromMap(m map[string]any) *LogsSearchOpt
 		}
 	}
 
+	switch v := m["dateStop"].(type) {
+	case string:
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			searchOptions.DateStop = t
+		}
+	}
+
 	switch v := m["level"].(type) {
 	case string:
 		searchOptions.Level = v
+	case []any:
+		levels := []string{}
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				levels = append(levels, s)
+			}
+		}
+		if len(levels) > 0 {
+			searchOptions.Level = levels
+		}
+	case []string:
+		if len(v) > 0 {
+			searchOptions.Level = v
+		}
 	}
 
 	switch v := m["limit"].(type) {
@@ -399,6 +679,11 @@ func (searchOptions *LogsSearchOptions) FromMap(m map[string]any) *LogsSearchOpt
 		searchOptions.Sort = int(v)
 	}
 
+	switch v := m["systemId"].(type) {
+	case float64:
+		searchOptions.SystemId = uint64(v)
+	}
+
 	return searchOptions
 }
 
@@ -408,9 +693,18 @@ type LogsSearchResults struct {
 	DateStart time.Time          `json:"dateStart"`
 	DateStop  time.Time          `json:"dateStop"`
 	Options   *LogsSearchOptions `json:"options"`
+	Window    *LogsSearchWindow  `json:"window"`
 	Logs      []Log              `json:"logs"`
 }
 
+// LogsSearchWindow is the time range a search actually covered; nil bounds are
+// open. DefaultLookback is set when the implicit 24-hour limit was applied.
+type LogsSearchWindow struct {
+	Start           *time.Time `json:"start,omitempty"`
+	Stop            *time.Time `json:"stop,omitempty"`
+	DefaultLookback bool       `json:"defaultLookback"`
+}
+
 type LogCategoryInfo struct {
 	Key   string `json:"key"`
 	Label string `json:"label"`
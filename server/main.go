@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"log"
 	"mime"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -190,9 +191,13 @@ func main() {
 		os.Exit(0)
 	}
 
+	// updateProbeExe is set when this start has to prove a just-applied update
+	// healthy; a crash loop is rolled back here (see updater_probe.go).
+	var updateProbeExe string
 	if config.newAdminPassword == "" {
 		fmt.Printf("\nThinLine Radio v%s\n", Version)
 		fmt.Printf("----------------------------------\n")
+		updateProbeExe = beginUpdateProbe()
 	}
 
 	controller := NewController(config)
@@ -247,6 +252,10 @@ func main() {
 		return RateLimitMiddleware(controller.RateLimiter)(handler)
 	}
 
+	centralRateLimitWrapper := func(handler http.Handler) http.Handler {
+		return TokenBucketMiddleware(controller.CentralRateLimiter)(handler)
+	}
+
 	// Apply security headers to all routes
 	securityHeadersWrapper := func(handler http.Handler) http.Handler {
 		return SecurityHeadersMiddleware(handler)
@@ -265,9 +274,10 @@ func main() {
 		})
 	}
 
-	// Helper to wrap handlers with recovery, rate limiting, and security headers
+	// Helper to wrap handlers with recovery, rate limiting, security headers and
+	// response compression
 	wrapHandler := func(handler http.Handler) http.Handler {
-		return startupConnectionMiddleware(securityHeadersWrapper(rateLimitWrapper(recoveryMiddleware(handler))))
+		return startupConnectionMiddleware(securityHeadersWrapper(rateLimitWrapper(recoveryMiddleware(CompressionMiddleware(handler)))))
 	}
 
 	// Tile-specific rate limiting: a single map viewport load or radar
@@ -285,7 +295,8 @@ func main() {
 
 	// corsMiddleware adds CORS headers so the Central Management frontend (a different
 	// origin) can call user-facing API endpoints.  Authentication is still enforced by
-	// each handler via PIN, so opening these endpoints to any origin is safe.
+	// each handler via PIN, so opening these endpoints to any origin is safe.  The rest
+	// of the API is opened only to cors_allowed_origins, by ApiCorsMiddleware.
 	corsMiddleware := func(handler http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -333,9 +344,11 @@ func main() {
 
 	http.HandleFunc("/api/admin/system-no-audio-settings", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.SystemNoAudioSettingsHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/system-retention-settings", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.SystemRetentionSettingsHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/prune-preview", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.PrunePreviewHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/system-duplicate-detection-settings", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.SystemDuplicateDetectionSettingsHandler)).ServeHTTP)
 
 	http.HandleFunc("/api/admin/transcription-failures", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.TranscriptionFailuresHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/transcription-dead-letters", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.TranscriptionDeadLettersHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/transcription-failure-threshold", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.TranscriptionFailureThresholdHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/transcript-parser", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.TranscriptParserHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/mapping/config", wrapHandler(controller.Admin.requireLocalhost(http.HandlerFunc(controller.Api.MappingConfigHandler))).ServeHTTP)
@@ -352,6 +365,8 @@ func main() {
 	http.HandleFunc("/api/admin/mapping/suggest-talkgroup-locations", wrapHandler(controller.Admin.requireLocalhost(http.HandlerFunc(controller.Api.MappingSuggestTalkgroupLocationsHandler))).ServeHTTP)
 	http.HandleFunc("/api/admin/mapping/regeocode/", wrapHandler(controller.Admin.requireLocalhost(http.HandlerFunc(controller.Api.MappingRegeocodeCallHandler))).ServeHTTP)
 	http.HandleFunc("/api/admin/relay-suspension", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.RelaySuspensionStatusHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/reconnection-stats", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.ReconnectionStatsHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/ffmpeg-stats", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.FFMpegStatsHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/relay-unlock-public-client", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.RelayUnlockPublicClientHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/relay-account/status", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.RelayAccountStatusHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/relay-account/login", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.RelayAccountLoginHandler)).ServeHTTP)
@@ -378,13 +393,21 @@ func main() {
 	http.HandleFunc("/api/admin/tone-import", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.ToneImportHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/sync-tone-sets", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.SyncToneSetsHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/tone-history-analyze", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.ToneHistoryAnalyzeHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/tone-export", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.ToneExportHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/tone-search", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.ToneSearchHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/tone-replay", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.ToneReplayHandler)).ServeHTTP)
 
 	http.HandleFunc("/api/admin/config", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.ConfigHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/options", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.OptionsPatchHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/apikeys", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.ApikeysHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/tags", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.TagsHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/tags/reorder", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.TagsReorderHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/tags/merge", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.TagsMergeHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/talkgroup-groups", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.GroupsConfigHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/downstreams", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.DownstreamsHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/webhooks", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.WebhooksHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/webhooks/deliveries", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.WebhookDeliveriesHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/audio-storage", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.AudioStorageHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/dirwatch", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.DirwatchConfigHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/systems/save", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.SystemSaveHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/systems/delete/", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.SystemDeleteHandler)).ServeHTTP)
@@ -438,8 +461,12 @@ func main() {
 
 	http.HandleFunc("/api/admin/logout", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.LogoutHandler)).ServeHTTP)
 
+	http.HandleFunc("/api/admin/token/refresh", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.TokenRefreshHandler)).ServeHTTP)
+
 	http.HandleFunc("/api/admin/logs", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.LogsHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/logs/categories", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.LogsCategoriesHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/logs/export", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.LogsExportHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/transcripts/search", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.TranscriptsSearchHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/copilot/chat", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.CopilotChatHandler)).ServeHTTP)
 
 	http.HandleFunc("/api/admin/calls", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.CallsHandler)).ServeHTTP)
@@ -450,6 +477,7 @@ func main() {
 
 	http.HandleFunc("/api/admin/users", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.UsersListHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/users/create", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.UserCreateHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/users/profile", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.UserProfileHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/users/", wrapHandler(controller.Admin.requireLocalhost(func(w http.ResponseWriter, r *http.Request) {
 		// Check if it's a device-tokens endpoint: /api/admin/users/{userId}/device-tokens/{tokenId}
 		pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
@@ -519,6 +547,8 @@ func main() {
 	http.HandleFunc("/api/user/reset-password", wrapHandler(http.HandlerFunc(controller.Api.ResetPasswordHandler)).ServeHTTP)
 	http.HandleFunc("/api/user/force-password-reset", wrapHandler(http.HandlerFunc(controller.Api.UserForcePasswordResetHandler)).ServeHTTP)
 	http.HandleFunc("/api/user/device-token", wrapHandler(http.HandlerFunc(controller.Api.UserDeviceTokenHandler)).ServeHTTP)
+	http.HandleFunc("/api/user/test-push", wrapHandler(http.HandlerFunc(controller.Api.UserTestPushHandler)).ServeHTTP)
+	http.HandleFunc("/api/user/webpush", wrapHandler(http.HandlerFunc(controller.Api.UserWebPushHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/relay-server-auth-key", wrapHandler(http.HandlerFunc(controller.Api.RelayServerAuthKeyHandler)).ServeHTTP)
 
 	// Group admin routes
@@ -608,25 +638,30 @@ func main() {
 	http.HandleFunc("/api/stripe/webhook", securityHeadersWrapper(recoveryMiddleware(http.HandlerFunc(controller.Api.StripeWebhookHandler))).ServeHTTP)
 
 	// Central Management webhook routes (for receiving user grant/revoke from central system)
-	http.HandleFunc("/api/webhook/central-user-grant", securityHeadersWrapper(recoveryMiddleware(http.HandlerFunc(controller.Api.CentralWebhookUserGrantHandler))).ServeHTTP)
-	http.HandleFunc("/api/webhook/central-user-revoke", securityHeadersWrapper(recoveryMiddleware(http.HandlerFunc(controller.Api.CentralWebhookUserRevokeHandler))).ServeHTTP)
-	http.HandleFunc("/api/webhook/central-test", securityHeadersWrapper(recoveryMiddleware(http.HandlerFunc(controller.Api.CentralWebhookTestConnectionHandler))).ServeHTTP)
-	http.HandleFunc("/api/webhook/central-users", securityHeadersWrapper(recoveryMiddleware(http.HandlerFunc(controller.Api.CentralWebhookUsersListHandler))).ServeHTTP)
-	http.HandleFunc("/api/webhook/central-users-batch-update", securityHeadersWrapper(recoveryMiddleware(http.HandlerFunc(controller.Api.CentralWebhookUsersBatchUpdateHandler))).ServeHTTP)
-	http.HandleFunc("/api/webhook/central-systems-talkgroups-groups", securityHeadersWrapper(recoveryMiddleware(http.HandlerFunc(controller.Api.CentralWebhookSystemsTalkgroupsGroupsHandler))).ServeHTTP)
-	http.HandleFunc("/api/webhook/central-set-relay-key", securityHeadersWrapper(recoveryMiddleware(http.HandlerFunc(controller.Api.CentralWebhookSetRelayAPIKeyHandler))).ServeHTTP)
-	http.HandleFunc("/api/webhook/central-set-hydra-config", securityHeadersWrapper(recoveryMiddleware(http.HandlerFunc(controller.Api.CentralWebhookSetHydraConfigHandler))).ServeHTTP)
+	http.HandleFunc("/api/webhook/central-user-grant", securityHeadersWrapper(centralRateLimitWrapper(recoveryMiddleware(http.HandlerFunc(controller.Api.CentralWebhookUserGrantHandler)))).ServeHTTP)
+	http.HandleFunc("/api/webhook/central-user-revoke", securityHeadersWrapper(centralRateLimitWrapper(recoveryMiddleware(http.HandlerFunc(controller.Api.CentralWebhookUserRevokeHandler)))).ServeHTTP)
+	http.HandleFunc("/api/webhook/central-user-set-group", securityHeadersWrapper(centralRateLimitWrapper(recoveryMiddleware(http.HandlerFunc(controller.Api.CentralWebhookUserSetGroupHandler)))).ServeHTTP)
+	http.HandleFunc("/api/webhook/central-test", securityHeadersWrapper(centralRateLimitWrapper(recoveryMiddleware(http.HandlerFunc(controller.Api.CentralWebhookTestConnectionHandler)))).ServeHTTP)
+	http.HandleFunc("/api/webhook/central-users", securityHeadersWrapper(centralRateLimitWrapper(recoveryMiddleware(http.HandlerFunc(controller.Api.CentralWebhookUsersListHandler)))).ServeHTTP)
+	http.HandleFunc("/api/webhook/central-users-batch-update", securityHeadersWrapper(centralRateLimitWrapper(recoveryMiddleware(http.HandlerFunc(controller.Api.CentralWebhookUsersBatchUpdateHandler)))).ServeHTTP)
+	http.HandleFunc("/api/webhook/central-systems-talkgroups-groups", securityHeadersWrapper(centralRateLimitWrapper(recoveryMiddleware(http.HandlerFunc(controller.Api.CentralWebhookSystemsTalkgroupsGroupsHandler)))).ServeHTTP)
+	http.HandleFunc("/api/webhook/central-set-relay-key", securityHeadersWrapper(centralRateLimitWrapper(recoveryMiddleware(http.HandlerFunc(controller.Api.CentralWebhookSetRelayAPIKeyHandler)))).ServeHTTP)
+	http.HandleFunc("/api/webhook/central-set-webhook-secret", securityHeadersWrapper(centralRateLimitWrapper(recoveryMiddleware(http.HandlerFunc(controller.Api.CentralWebhookSetWebhookSecretHandler)))).ServeHTTP)
+	http.HandleFunc("/api/webhook/central-set-hydra-config", securityHeadersWrapper(centralRateLimitWrapper(recoveryMiddleware(http.HandlerFunc(controller.Api.CentralWebhookSetHydraConfigHandler)))).ServeHTTP)
 	http.HandleFunc("/api/webhook/relay-suspension", securityHeadersWrapper(recoveryMiddleware(http.HandlerFunc(controller.Api.RelaySuspensionWebhookHandler))).ServeHTTP)
 	http.HandleFunc("/api/webhook/relay-billing", securityHeadersWrapper(recoveryMiddleware(http.HandlerFunc(controller.Api.RelayBillingWebhookHandler))).ServeHTTP)
 	http.HandleFunc("/api/webhook/relay-listener-pin", securityHeadersWrapper(recoveryMiddleware(http.HandlerFunc(controller.Api.RelayListenerPinWebhookHandler))).ServeHTTP)
 
 	// Central Management pairing endpoint — called by the CM backend to push the API key and
-	// enable centralized mode. Not localhost-restricted; protected by admin password (bcrypt).
-	http.HandleFunc("/api/central-management/pair", securityHeadersWrapper(rateLimitWrapper(http.HandlerFunc(controller.Api.PairWithCentralManagementHandler))).ServeHTTP)
-	http.HandleFunc("/api/central-management/admin-token", securityHeadersWrapper(rateLimitWrapper(http.HandlerFunc(controller.Api.CMAdminTokenHandler))).ServeHTTP)
+	// enable centralized mode. Not localhost-restricted; protected by the pair challenge.
+	http.HandleFunc("/api/central-management/pair", securityHeadersWrapper(centralRateLimitWrapper(http.HandlerFunc(controller.Api.PairWithCentralManagementHandler))).ServeHTTP)
+	http.HandleFunc("/api/central-management/pair-challenge", securityHeadersWrapper(centralRateLimitWrapper(http.HandlerFunc(controller.Api.PairChallengeHandler))).ServeHTTP)
+	http.HandleFunc("/api/central-management/status", securityHeadersWrapper(centralRateLimitWrapper(http.HandlerFunc(controller.Api.CentralManagementStatusHandler))).ServeHTTP)
+	http.HandleFunc("/api/central-management/pairing-secret", securityHeadersWrapper(centralRateLimitWrapper(http.HandlerFunc(controller.Api.PairingSecretHandler))).ServeHTTP)
+	http.HandleFunc("/api/central-management/admin-token", securityHeadersWrapper(centralRateLimitWrapper(http.HandlerFunc(controller.Api.CMAdminTokenHandler))).ServeHTTP)
 	// CM pushes a one-time removal code here; local admin then calls /leave to unlink the server
-	http.HandleFunc("/api/central-management/set-removal-code", securityHeadersWrapper(rateLimitWrapper(http.HandlerFunc(controller.Api.SetRemovalCodeHandler))).ServeHTTP)
-	http.HandleFunc("/api/central-management/leave", securityHeadersWrapper(rateLimitWrapper(http.HandlerFunc(controller.Api.LeaveCentralManagementHandler))).ServeHTTP)
+	http.HandleFunc("/api/central-management/set-removal-code", securityHeadersWrapper(centralRateLimitWrapper(http.HandlerFunc(controller.Api.SetRemovalCodeHandler))).ServeHTTP)
+	http.HandleFunc("/api/central-management/leave", securityHeadersWrapper(centralRateLimitWrapper(http.HandlerFunc(controller.Api.LeaveCentralManagementHandler))).ServeHTTP)
 
 	// Admin endpoint to test connection TO central management system
 	http.HandleFunc("/api/admin/test-central-connection", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.TestCentralConnectionHandler)).ServeHTTP)
@@ -634,6 +669,7 @@ func main() {
 	// Auto-update endpoints
 	http.HandleFunc("/api/admin/update/check", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.UpdateCheckHandler)).ServeHTTP)
 	http.HandleFunc("/api/admin/update/apply", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.UpdateApplyHandler)).ServeHTTP)
+	http.HandleFunc("/api/admin/update/rollback", wrapHandler(controller.Admin.requireLocalhost(controller.Admin.UpdateRollbackHandler)).ServeHTTP)
 
 	// Stripe checkout session route
 	http.HandleFunc("/api/stripe/create-checkout-session", wrapHandler(http.HandlerFunc(controller.Api.CreateCheckoutSessionHandler)).ServeHTTP)
@@ -670,6 +706,10 @@ func main() {
 	// Match v6 registration pattern exactly - pass handler directly without wrapping
 	http.HandleFunc("/api/call-upload", controller.Api.CallUploadHandler)
 
+	// Resumable chunked uploads for recorders on unreliable links
+	http.HandleFunc("/api/call-upload/chunked", controller.Api.ChunkedCallUploadHandler)
+	http.HandleFunc("/api/call-upload/chunked/", controller.Api.ChunkedCallUploadHandler)
+
 	http.HandleFunc("/api/trunk-recorder-call-upload", controller.Api.TrunkRecorderCallUploadHandler)
 
 	// Pager-alert audio download — authenticated by admin PIN.
@@ -738,6 +778,13 @@ func main() {
 	http.HandleFunc("/api/health/live", wrapHandler(controller.Admin.requireAdminBasicAuth(controller.Health.LiveHandler)).ServeHTTP)
 	http.HandleFunc("/api/health/ready", wrapHandler(controller.Admin.requireAdminBasicAuth(controller.Health.ReadyHandler)).ServeHTTP)
 
+	// Unauthenticated liveness/readiness probes for load balancers and orchestrators.
+	http.HandleFunc("/healthz", wrapHandler(http.HandlerFunc(controller.Health.HealthzHandler)).ServeHTTP)
+	http.HandleFunc("/readyz", wrapHandler(http.HandlerFunc(controller.Health.ReadyzHandler)).ServeHTTP)
+
+	// Prometheus metrics, authenticated with metrics_key (Bearer) or an admin token.
+	http.HandleFunc("/metrics", wrapHandler(http.HandlerFunc(controller.Admin.MetricsHandler)).ServeHTTP)
+
 	// Login blocked countdown page
 	http.HandleFunc("/login-blocked", wrapHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		secondsParam := r.URL.Query().Get("seconds")
@@ -955,7 +1002,10 @@ func main() {
 			!isStaticAsset(requestPath) &&
 			!strings.EqualFold(r.Header.Get("upgrade"), "websocket") &&
 			(r.Method == http.MethodGet || r.Method == http.MethodHead) {
-			baseCentralURL := strings.TrimRight(strings.TrimSpace(controller.Options.CentralManagementURL), "/")
+			baseCentralURL := ""
+			if controller.CentralManagement != nil {
+				baseCentralURL = controller.CentralManagement.ActiveURL()
+			}
 			if baseCentralURL != "" {
 				target := baseCentralURL + requestPath
 				if rawQuery := strings.TrimSpace(r.URL.RawQuery); rawQuery != "" {
@@ -1059,6 +1109,7 @@ func main() {
 	newServer := func(addr string, tlsConfig *tls.Config) *http.Server {
 		s := &http.Server{
 			Addr:         addr,
+			Handler:      ApiCorsMiddleware(config.CorsOrigins, http.DefaultServeMux),
 			TLSConfig:    tlsConfig,
 			ReadTimeout:  10 * time.Minute,                                         // Increased from 30s to 10 minutes for long imports
 			WriteTimeout: 10 * time.Minute,                                         // Increased from 30s to 10 minutes for long imports
@@ -1118,13 +1169,20 @@ func main() {
 	}
 
 	// Accept HTTP immediately so static assets load while controller.Start() reads the DB.
+	// The port is bound here rather than in ListenAndServe so the update probe
+	// knows whether it succeeded.
 	httpServer = newServer(fmt.Sprintf("%s:%s", addr, port), nil)
-	go func() {
-		log.Printf("startup: HTTP listening on %s:%s (loading configuration...)", addr, port)
-		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Printf("HTTP server error: %v", err)
-		}
-	}()
+	httpListener, err := net.Listen("tcp", httpServer.Addr)
+	if err != nil {
+		log.Printf("HTTP server error: %v", err)
+	} else {
+		go func() {
+			log.Printf("startup: HTTP listening on %s:%s (loading configuration...)", addr, port)
+			if err := httpServer.Serve(httpListener); err != nil && err != http.ErrServerClosed {
+				log.Printf("HTTP server error: %v", err)
+			}
+		}()
+	}
 
 	if err := controller.Start(); err != nil {
 		log.Printf("FATAL: Failed to start controller: %v", err)
@@ -1132,8 +1190,23 @@ func main() {
 		os.Exit(1)
 	}
 
+	if httpListener != nil {
+		confirmUpdateHealthy(updateProbeExe)
+	}
+
 	deferPostStartupMaintenance(controller.Database)
 
+	// SIGHUP reloads the settings that can change without a restart
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	go func() {
+		for range hupChan {
+			if _, err := controller.ReloadConfig("SIGHUP"); err != nil {
+				controller.Logs.LogEvent(LogLevelError, err.Error())
+			}
+		}
+	}()
+
 	// Wait for interrupt signal
 	<-sigChan
 	log.Println("Shutdown signal received, starting graceful shutdown...")
@@ -0,0 +1,145 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metricsWriter renders the Prometheus text exposition format.  Each metric
+// family is written once with its HELP and TYPE lines.
+type metricsWriter struct {
+	w io.Writer
+}
+
+func (m metricsWriter) write(name, kind, help string, value float64, labels map[string]string) {
+	fmt.Fprintf(m.w, "# HELP %s %s\n# TYPE %s %s\n%s%s %s\n",
+		name, help, name, kind, name, formatMetricLabels(labels), strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+func (m metricsWriter) gauge(name, help string, value float64) {
+	m.write(name, "gauge", help, value, nil)
+}
+
+func (m metricsWriter) counter(name, help string, value float64) {
+	m.write(name, "counter", help, value, nil)
+}
+
+func formatMetricLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		v := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(labels[k])
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, k, v))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func boolMetric(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// writeMetrics reports ingest, client, queue and Central Management state.
+// Like the health payload, every source is an in-memory read.
+func (controller *Controller) writeMetrics(out io.Writer) {
+	m := metricsWriter{w: out}
+
+	m.write("thinline_build_info", "gauge", "Running server version.", 1, map[string]string{"version": Version})
+	if !processStartTime.IsZero() {
+		m.gauge("thinline_start_time_seconds", "Unix time the server started.", float64(processStartTime.Unix()))
+	}
+
+	controller.workerStats.Lock()
+	totalCalls := controller.workerStats.totalCalls
+	avgProcess := controller.workerStats.avgProcessTime
+	controller.workerStats.Unlock()
+	m.counter("thinline_calls_ingested_total", "Calls processed by the ingest workers since startup.", float64(totalCalls))
+	m.gauge("thinline_call_process_seconds", "Rolling average time to ingest a call.", avgProcess.Seconds())
+	m.gauge("thinline_ingest_queue_depth", "Calls waiting for an ingest worker.", float64(len(controller.Ingest)))
+	m.gauge("thinline_ingest_active", "Uploads being received plus calls being ingested.", float64(controller.ingestActive.Load()))
+
+	if controller.Clients != nil {
+		m.gauge("thinline_websocket_clients", "Connected WebSocket clients.", float64(controller.Clients.Count()))
+	}
+
+	if controller.TranscriptionQueue != nil {
+		m.gauge("thinline_transcription_queue_depth", "Transcription jobs waiting for a worker.", float64(controller.TranscriptionQueue.QueueDepth()))
+	}
+
+	if controller.FFMpeg != nil {
+		stats := controller.FFMpeg.Stats()
+		m.gauge("thinline_ffmpeg_conversions_in_flight", "ffmpeg conversions currently running.", float64(stats.InFlight))
+		m.gauge("thinline_ffmpeg_conversions_waiting", "ffmpeg conversions waiting for a slot.", float64(stats.Waiting))
+		m.gauge("thinline_ffmpeg_conversion_slots", "Maximum concurrent ffmpeg conversions.", float64(stats.Limit))
+		m.counter("thinline_ffmpeg_conversions_total", "ffmpeg conversions completed since startup.", float64(stats.Conversions))
+	}
+
+	if controller.ReconnectionMgr != nil {
+		stats := controller.ReconnectionMgr.GetStats()
+		disconnected, _ := stats["disconnectedUsers"].(int)
+		buffered, _ := stats["totalBufferedCalls"].(int)
+		m.gauge("thinline_reconnection_held_users", "Disconnected users whose session is held for reconnection.", float64(disconnected))
+		m.gauge("thinline_reconnection_buffered_calls", "Calls buffered for disconnected users.", float64(buffered))
+		memory, _ := stats["memoryBytes"].(int64)
+		m.gauge("thinline_reconnection_memory_bytes", "Estimated memory held by reconnection buffers.", float64(memory))
+	}
+
+	if controller.Mqtt.Enabled() {
+		m.gauge("thinline_mqtt_connected", "Whether the MQTT publisher is connected to its broker.", boolMetric(controller.Mqtt.connected.Load()))
+		m.gauge("thinline_mqtt_queued_messages", "MQTT messages waiting to be published.", float64(len(controller.Mqtt.queue)))
+		m.counter("thinline_mqtt_dropped_messages_total", "MQTT messages dropped because the queue was full.", float64(controller.Mqtt.dropped.Load()))
+	}
+
+	m.gauge("thinline_central_management_enabled", "Whether Central Management is enabled.", boolMetric(controller.Options.CentralManagementEnabled))
+	if controller.CentralManagement != nil {
+		status := controller.CentralManagement.Status()
+		registered, _ := status["registered"].(bool)
+		failures, _ := status["consecutive_failures"].(int)
+		m.gauge("thinline_central_management_registered", "Whether this server is registered with Central Management.", boolMetric(registered))
+		m.gauge("thinline_central_management_heartbeat_failures", "Consecutive failed Central Management heartbeats.", float64(failures))
+		if last, ok := status["last_heartbeat"].(int64); ok {
+			m.gauge("thinline_central_management_last_heartbeat_seconds", "Unix time of the last successful heartbeat.", float64(last))
+		}
+	}
+
+	m.gauge("thinline_scrape_timestamp_seconds", "Unix time these metrics were collected.", float64(time.Now().Unix()))
+}
+
+// MetricsHandler serves Prometheus metrics.  Scrapers authenticate with
+// "Authorization: Bearer <metrics_key>"; an admin session token also works.
+func (admin *Admin) MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	authorization := admin.GetAuthorization(r)
+	key := ""
+	if admin.Controller.Config != nil {
+		key = admin.Controller.Config.MetricsKey
+	}
+	bearer := strings.TrimSpace(strings.TrimPrefix(authorization, "Bearer "))
+	if !constantTimeEqual(bearer, key) && !admin.ValidateToken(authorization) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	admin.Controller.writeMetrics(w)
+}
@@ -0,0 +1,56 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	controller := &Controller{Options: NewOptions(), Config: &Config{MetricsKey: "scrape-key"}, Clients: NewClients(), FFMpeg: &FFMpeg{slots: make(chan struct{}, 2)}}
+	controller.Admin = &Admin{Controller: controller}
+	controller.workerStats.totalCalls = 42
+
+	scrape := func(authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		controller.Admin.MetricsHandler(rec, req)
+		return rec
+	}
+
+	for _, auth := range []string{"", "Bearer wrong", "scrape-key-extra"} {
+		if rec := scrape(auth); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("authorization %q: status %d, want 401", auth, rec.Code)
+		}
+	}
+
+	rec := scrape("Bearer scrape-key")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE thinline_calls_ingested_total counter\nthinline_calls_ingested_total 42\n",
+		"thinline_websocket_clients 0\n",
+		"thinline_ffmpeg_conversion_slots 2\n",
+		"thinline_central_management_enabled 0\n",
+		`thinline_build_info{version="` + Version + `"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("metrics missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestFormatMetricLabels(t *testing.T) {
+	got := formatMetricLabels(map[string]string{"b": `say "hi"`, "a": "x\\y"})
+	if want := `{a="x\\y",b="say \"hi\""}`; got != want {
+		t.Fatalf("formatMetricLabels = %s, want %s", got, want)
+	}
+}
@@ -1826,6 +1826,20 @@ func migrateUserGroupsAllowAddExistingUsers(db *Database) error {
 	return nil
 }
 
+// migrateUserGroupsReconnection adds the per-group reconnection grace period and buffer size overrides
+func migrateUserGroupsReconnection(db *Database) error {
+	queries := []string{
+		`ALTER TABLE "userGroups" ADD COLUMN IF NOT EXISTS "reconnectionGracePeriod" integer NOT NULL DEFAULT 0`,
+		`ALTER TABLE "userGroups" ADD COLUMN IF NOT EXISTS "reconnectionMaxBufferSize" integer NOT NULL DEFAULT 0`,
+	}
+	for _, query := range queries {
+		if _, err := db.Sql.Exec(query); err != nil {
+			log.Printf("migration note: %v", err)
+		}
+	}
+	return nil
+}
+
 // migrateUserGroupsBillingFields adds stripePriceId and billingMode columns to userGroups table
 func migrateUserGroupsBillingFields(db *Database) error {
 	queries := []string{
@@ -1878,6 +1892,15 @@ func migrateUserMobileWelcomeEmailSent(db *Database) error {
 	return nil
 }
 
+// migrateUserModifiedAt adds the last-modified timestamp used by the central users list to page only changed users.
+func migrateUserModifiedAt(db *Database) error {
+	query := `ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "modifiedAt" bigint NOT NULL DEFAULT 0`
+	if _, err := db.Sql.Exec(query); err != nil {
+		log.Printf("migration note: %v", err)
+	}
+	return nil
+}
+
 // migrateUserForcePasswordReset adds forcePasswordReset column to users table
 func migrateUserForcePasswordReset(db *Database) error {
 	query := `ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "forcePasswordReset" boolean NOT NULL DEFAULT false`
@@ -2590,6 +2613,7 @@ func ensureLogsTimestampIndexBackground(db *Database) {
 func deferPostStartupMaintenance(db *Database) {
 	go ensureBootstrapIndexesBackground(db)
 	go ensureLogsTimestampIndexBackground(db)
+	go ensureTranscriptSearchIndexBackground(db)
 	startLogsCategoryMaintenance(db)
 }
 
@@ -2992,6 +3016,24 @@ func migrateLogsCategory(db *Database) error {
 	return nil
 }
 
+// migrateLogsContext adds the structured context columns written by
+// LogEventWithContext. They are nullable without defaults for the same reason
+// as "category": adding them must not rewrite a large logs table.
+func migrateLogsContext(db *Database) error {
+	queries := []string{
+		`ALTER TABLE "logs" ADD COLUMN IF NOT EXISTS "callId" bigint`,
+		`ALTER TABLE "logs" ADD COLUMN IF NOT EXISTS "systemId" bigint`,
+		`ALTER TABLE "logs" ADD COLUMN IF NOT EXISTS "talkgroupId" bigint`,
+		`ALTER TABLE "logs" ADD COLUMN IF NOT EXISTS "context" text`,
+	}
+	for _, query := range queries {
+		if _, err := db.Sql.Exec(query); err != nil {
+			log.Printf("migration note (logs context columns): %v", err)
+		}
+	}
+	return nil
+}
+
 // startLogsCategoryMaintenance runs after the server is ready. It builds the
 // category index and backfills rows in small throttled batches so the shared
 // DB pool stays available for calls and live clients.
@@ -3239,6 +3281,139 @@ func migrateRetentionDays(db *Database) error {
 	return nil
 }
 
+// migrateTalkgroupAllowDebugAudio adds the per-talkgroup opt-in for saving debug audio clips.
+func migrateTalkgroupAllowDebugAudio(db *Database) error {
+	query := `ALTER TABLE "talkgroups" ADD COLUMN IF NOT EXISTS "allowDebugAudio" boolean NOT NULL DEFAULT false`
+	if _, err := db.Sql.Exec(query); err != nil {
+		log.Printf("migration note (talkgroup debug audio): %v", err)
+	}
+	return nil
+}
+
+// migrateTalkgroupVoiceCaptureWindow adds the per-talkgroup tone alert voice capture window.
+// DEFAULT 0 keeps the existing behaviour of alerting on the first voice call.
+func migrateTalkgroupVoiceCaptureWindow(db *Database) error {
+	query := `ALTER TABLE "talkgroups" ADD COLUMN IF NOT EXISTS "voiceCaptureWindowSeconds" integer NOT NULL DEFAULT 0`
+	if _, err := db.Sql.Exec(query); err != nil {
+		log.Printf("migration note (talkgroup voice capture window): %v", err)
+	}
+	return nil
+}
+
+// migrateTalkgroupLinkedVoiceRefs adds the list of additional voice talkgroups watched
+// after tones, stored as comma-separated refs. Empty keeps the single linked talkgroup.
+func migrateTalkgroupLinkedVoiceRefs(db *Database) error {
+	query := `ALTER TABLE "talkgroups" ADD COLUMN IF NOT EXISTS "linkedVoiceTalkgroupRefs" text NOT NULL DEFAULT ''`
+	if _, err := db.Sql.Exec(query); err != nil {
+		log.Printf("migration note (talkgroup linked voice refs): %v", err)
+	}
+	return nil
+}
+
+// migrateCallDetectedTones adds the compact list of detected tone frequencies and
+// durations to calls. Existing calls keep '[]'; new detections populate it.
+func migrateCallDetectedTones(db *Database) error {
+	query := `ALTER TABLE "calls" ADD COLUMN IF NOT EXISTS "detectedTones" text NOT NULL DEFAULT '[]'`
+	if _, err := db.Sql.Exec(query); err != nil {
+		log.Printf("migration note (call detected tones): %v", err)
+	}
+	return nil
+}
+
+// migrateTalkgroupTranscriptionLanguage adds the per-talkgroup transcription language hint.
+func migrateTalkgroupTranscriptionLanguage(db *Database) error {
+	query := `ALTER TABLE "talkgroups" ADD COLUMN IF NOT EXISTS "transcriptionLanguage" text NOT NULL DEFAULT ''`
+	if _, err := db.Sql.Exec(query); err != nil {
+		log.Printf("migration note (talkgroup transcription language): %v", err)
+	}
+	return nil
+}
+
+// migrateTalkgroupReconnectionPriority adds the flag that protects a talkgroup's
+// calls in the reconnection buffer.
+func migrateTalkgroupReconnectionPriority(db *Database) error {
+	query := `ALTER TABLE "talkgroups" ADD COLUMN IF NOT EXISTS "reconnectionPriority" boolean NOT NULL DEFAULT false`
+	if _, err := db.Sql.Exec(query); err != nil {
+		log.Printf("migration note (talkgroup reconnection priority): %v", err)
+	}
+	return nil
+}
+
+// migrateTalkgroupEncrypted adds the flag recording that a talkgroup is encrypted.
+func migrateTalkgroupEncrypted(db *Database) error {
+	query := `ALTER TABLE "talkgroups" ADD COLUMN IF NOT EXISTS "encrypted" boolean NOT NULL DEFAULT false`
+	if _, err := db.Sql.Exec(query); err != nil {
+		log.Printf("migration note (talkgroup encrypted): %v", err)
+	}
+	return nil
+}
+
+// migrateDeviceTokenSubscription adds the Web Push subscription JSON (endpoint
+// and p256dh/auth keys) used by "webpush" device tokens.
+func migrateDeviceTokenSubscription(db *Database) error {
+	query := `ALTER TABLE "deviceTokens" ADD COLUMN IF NOT EXISTS "subscription" text NOT NULL DEFAULT ''`
+	if _, err := db.Sql.Exec(query); err != nil {
+		log.Printf("migration note (device token subscription): %v", err)
+	}
+	return nil
+}
+
+// migrateDeviceTokenQuietHours adds per-device quiet hours: minutes of day in
+// the device's timezone, where start == end disables them.
+func migrateDeviceTokenQuietHours(db *Database) error {
+	queries := []string{
+		`ALTER TABLE "deviceTokens" ADD COLUMN IF NOT EXISTS "quietStart" integer NOT NULL DEFAULT 0`,
+		`ALTER TABLE "deviceTokens" ADD COLUMN IF NOT EXISTS "quietEnd" integer NOT NULL DEFAULT 0`,
+		`ALTER TABLE "deviceTokens" ADD COLUMN IF NOT EXISTS "timezone" text NOT NULL DEFAULT ''`,
+	}
+	for _, query := range queries {
+		if _, err := db.Sql.Exec(query); err != nil {
+			log.Printf("migration note (device token quiet hours): %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateUserGroupDefaultLivefeedTags adds the tags a group's new clients start
+// their livefeed with, stored as a JSON array of tag labels.
+func migrateUserGroupDefaultLivefeedTags(db *Database) error {
+	query := `ALTER TABLE "userGroups" ADD COLUMN IF NOT EXISTS "defaultLivefeedTags" text NOT NULL DEFAULT ''`
+	if _, err := db.Sql.Exec(query); err != nil {
+		log.Printf("migration note (user group default livefeed tags): %v", err)
+	}
+	return nil
+}
+
+// migrateTranscriptionDeadLetters adds the table holding transcription jobs that
+// failed after all retries, so they can be listed and re-enqueued from the admin.
+func migrateTranscriptionDeadLetters(db *Database) error {
+	query := `CREATE TABLE IF NOT EXISTS "transcriptionDeadLetters" (
+		"callId" bigint NOT NULL PRIMARY KEY,
+		"systemId" bigint NOT NULL,
+		"talkgroupId" bigint NOT NULL,
+		"priority" integer NOT NULL DEFAULT 0,
+		"reasons" text NOT NULL DEFAULT '[]',
+		"attempts" integer NOT NULL DEFAULT 0,
+		"lastError" text NOT NULL DEFAULT '',
+		"deadAt" bigint NOT NULL DEFAULT 0,
+		CONSTRAINT "transcriptionDeadLetters_callId" FOREIGN KEY ("callId") REFERENCES "calls" ("callId") ON DELETE CASCADE ON UPDATE CASCADE
+	)`
+	if _, err := db.Sql.Exec(query); err != nil {
+		return fmt.Errorf("migrateTranscriptionDeadLetters: %w", err)
+	}
+	return nil
+}
+
+// migrateKeywordListUserGroups adds the user groups a keyword list is routed to,
+// stored as a JSON array. An empty array keeps the list available to every user.
+func migrateKeywordListUserGroups(db *Database) error {
+	query := `ALTER TABLE "keywordLists" ADD COLUMN IF NOT EXISTS "userGroupIds" text NOT NULL DEFAULT '[]'`
+	if _, err := db.Sql.Exec(query); err != nil {
+		log.Printf("migration note (keyword list user groups): %v", err)
+	}
+	return nil
+}
+
 // migrateSystemDuplicateDetection adds per-system duplicate detection toggle.
 func migrateSystemDuplicateDetection(db *Database) error {
 	query := `ALTER TABLE "systems" ADD COLUMN IF NOT EXISTS "duplicateDetectionEnabled" boolean NOT NULL DEFAULT true`
@@ -3276,3 +3451,95 @@ func migrateKeywordAlertUnique(db *Database) error {
 	}
 	return nil
 }
+
+// migrateWebhooks adds the outbound webhooks table.
+func migrateWebhooks(db *Database) error {
+	query := `CREATE TABLE IF NOT EXISTS "webhooks" (
+		"webhookId" bigserial NOT NULL PRIMARY KEY,
+		"disabled" boolean NOT NULL DEFAULT false,
+		"events" text NOT NULL DEFAULT '["call"]',
+		"name" text NOT NULL DEFAULT '',
+		"order" integer NOT NULL DEFAULT 0,
+		"secret" text NOT NULL DEFAULT '',
+		"systems" text NOT NULL DEFAULT '"*"',
+		"tagIds" text NOT NULL DEFAULT '[]',
+		"url" text NOT NULL
+	)`
+	if _, err := db.Sql.Exec(query); err != nil {
+		return fmt.Errorf("migrateWebhooks: %w", err)
+	}
+	return nil
+}
+
+// migrateSystemAudioOverrides adds the per-system audio format and AAC
+// bitrate. Empty and 0 keep the global audio format and the default bitrate.
+func migrateSystemAudioOverrides(db *Database) error {
+	queries := []string{
+		`ALTER TABLE "systems" ADD COLUMN IF NOT EXISTS "audioFormat" text NOT NULL DEFAULT ''`,
+		`ALTER TABLE "systems" ADD COLUMN IF NOT EXISTS "audioBitrate" integer NOT NULL DEFAULT 0`,
+	}
+	for _, query := range queries {
+		if _, err := db.Sql.Exec(query); err != nil {
+			return fmt.Errorf("migrateSystemAudioOverrides: %w", err)
+		}
+	}
+	return nil
+}
+
+// migrateTranscriptSearchIndex adds a trigram index on call transcripts so
+// ILIKE searches do not scan the calls table. Building it on a large calls
+// table takes a while, so like the logs timestamp index it is built
+// concurrently in the background after startup; see
+// ensureTranscriptSearchIndexBackground.
+func migrateTranscriptSearchIndex(db *Database) error {
+	exists, err := pgIndexExists(db, "calls_transcript_trgm_idx")
+	if err != nil {
+		log.Printf("migration note (transcript search index check): %v", err)
+		return nil
+	}
+	if exists {
+		log.Println("transcript search index already exists, skipping")
+		return nil
+	}
+
+	log.Println("transcript search index will be built in background after startup")
+	return nil
+}
+
+// ensureTranscriptSearchIndexBackground builds calls_transcript_trgm_idx.
+// pg_trgm ships with Postgres but creating it may need privileges the
+// database user lacks; searches then still work, only slower.
+func ensureTranscriptSearchIndexBackground(db *Database) {
+	exists, err := pgIndexExists(db, "calls_transcript_trgm_idx")
+	if err != nil {
+		writeLogStdout(fmt.Sprintf("migration note (transcript search index check): %v", err))
+		return
+	}
+	if exists {
+		return
+	}
+
+	if _, err := db.Sql.Exec(`CREATE EXTENSION IF NOT EXISTS pg_trgm`); err != nil {
+		writeLogStdout(fmt.Sprintf("migration note (transcript search index): pg_trgm unavailable, transcript search will not be indexed: %v", err))
+		return
+	}
+
+	writeLogStdout("building calls_transcript_trgm_idx concurrently in background...")
+	if _, err := db.Sql.Exec(`CREATE INDEX CONCURRENTLY "calls_transcript_trgm_idx" ON "calls" USING gin ("transcript" gin_trgm_ops) WHERE "transcript" <> ''`); err != nil {
+		writeLogStdout(fmt.Sprintf("migration note (transcript search index): %v", err))
+		// A failed concurrent build leaves an invalid index behind; drop it so
+		// the next startup tries again.
+		db.Sql.Exec(`DROP INDEX CONCURRENTLY IF EXISTS "calls_transcript_trgm_idx"`)
+		return
+	}
+	writeLogStdout("transcript search index build completed")
+}
+
+// migrateCallAudioKey adds the object key of call audio kept in external
+// storage. Empty for audio stored in the "audio" column.
+func migrateCallAudioKey(db *Database) error {
+	if _, err := db.Sql.Exec(`ALTER TABLE "calls" ADD COLUMN IF NOT EXISTS "audioKey" text NOT NULL DEFAULT ''`); err != nil {
+		return fmt.Errorf("migrateCallAudioKey: %w", err)
+	}
+	return nil
+}
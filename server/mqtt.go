@@ -0,0 +1,463 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	mqttKeepAlive     = 60 * time.Second
+	mqttDialTimeout   = 15 * time.Second
+	mqttQueueSize     = 256
+	mqttRetryMinDelay = time.Second
+	mqttRetryMaxDelay = time.Minute
+
+	mqttPacketConnect    byte = 0x10
+	mqttPacketConnack    byte = 0x20
+	mqttPacketPublish    byte = 0x30
+	mqttPacketPingreq    byte = 0xc0
+	mqttPacketDisconnect byte = 0xe0
+)
+
+var mqttConnackErrors = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "client identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+type mqttMessage struct {
+	topic   string
+	payload []byte
+}
+
+// Mqtt publishes a compact JSON message per ingested call and per alert to
+// an MQTT broker, for home automation and SCADA. It is a minimal MQTT 3.1.1
+// client: QoS 0, publish only. Messages are queued while the broker is
+// unreachable and the connection is retried with a doubling delay; when the
+// queue is full the newest messages are dropped.
+type Mqtt struct {
+	controller *Controller
+	broker     string
+	prefix     string
+	username   string
+	password   string
+	clientId   string
+	queue      chan mqttMessage
+	stopChan   chan struct{}
+	retryDelay time.Duration
+	connected  atomic.Bool
+	dropped    atomic.Uint64
+}
+
+// NewMqtt creates the MQTT publisher from the mqtt_* settings. It does nothing
+// until Start is called, and nothing at all without mqtt_broker.
+func NewMqtt(controller *Controller) *Mqtt {
+	config := controller.Config
+
+	clientId := config.MqttClientId
+	if clientId == "" {
+		b := make([]byte, 4)
+		rand.Read(b)
+		clientId = "thinline-radio-" + hex.EncodeToString(b)
+	}
+
+	return &Mqtt{
+		controller: controller,
+		broker:     config.MqttBroker,
+		prefix:     strings.Trim(config.MqttTopicPrefix, "/"),
+		username:   config.MqttUsername,
+		password:   config.MqttPassword,
+		clientId:   clientId,
+		stopChan:   make(chan struct{}),
+		retryDelay: mqttRetryMinDelay,
+	}
+}
+
+// Start connects to the broker in the background if mqtt_broker is set.
+func (mqtt *Mqtt) Start() {
+	if mqtt.broker == "" {
+		return
+	}
+
+	mqtt.queue = make(chan mqttMessage, mqttQueueSize)
+	log.Printf("mqtt: publishing to %s under %s/", mqtt.redactedBroker(), mqtt.prefix)
+
+	go mqtt.run()
+}
+
+// Stop disconnects from the broker.
+func (mqtt *Mqtt) Stop() {
+	select {
+	case <-mqtt.stopChan:
+		// already closed
+	default:
+		close(mqtt.stopChan)
+	}
+}
+
+// PublishCall queues a message on {prefix}/{system}/{talkgroup}/call.
+func (mqtt *Mqtt) PublishCall(call *Call) {
+	if !mqtt.Enabled() || call == nil || call.System == nil || call.Talkgroup == nil {
+		return
+	}
+
+	mqtt.publish(mqtt.topic(call, "call"), mqtt.callMessage(call))
+}
+
+// PublishAlert queues a message on {prefix}/{system}/{talkgroup}/alert.
+func (mqtt *Mqtt) PublishAlert(alert *AlertRecord) {
+	if !mqtt.Enabled() || alert == nil {
+		return
+	}
+
+	call := mqtt.controller.alertCall(alert)
+	if call == nil {
+		return
+	}
+
+	m := mqtt.callMessage(call)
+	m["alertId"] = alert.AlertId
+	m["type"] = alert.AlertType
+	if alert.ToneSetId != "" {
+		m["toneSetId"] = alert.ToneSetId
+	}
+	if alert.KeywordsMatched != "" {
+		keywords := []string{}
+		json.Unmarshal([]byte(alert.KeywordsMatched), &keywords)
+		m["keywords"] = keywords
+	}
+
+	mqtt.publish(mqtt.topic(call, "alert"), m)
+}
+
+// Enabled reports whether mqtt_broker is set and the publisher started.
+func (mqtt *Mqtt) Enabled() bool {
+	return mqtt != nil && mqtt.queue != nil
+}
+
+// topic uses the system and talkgroup IDs rather than labels, which may
+// contain the MQTT separators and wildcards.
+func (mqtt *Mqtt) topic(call *Call, event string) string {
+	topic := fmt.Sprintf("%d/%d/%s", call.System.SystemRef, call.Talkgroup.TalkgroupRef, event)
+	if mqtt.prefix != "" {
+		topic = mqtt.prefix + "/" + topic
+	}
+	return topic
+}
+
+func (mqtt *Mqtt) callMessage(call *Call) map[string]any {
+	m := map[string]any{
+		"callId":         call.Id,
+		"timestamp":      call.Timestamp.UnixMilli(),
+		"system":         call.System.SystemRef,
+		"systemLabel":    call.System.Label,
+		"talkgroup":      call.Talkgroup.TalkgroupRef,
+		"talkgroupLabel": call.Talkgroup.Label,
+	}
+	if mqtt.controller != nil && mqtt.controller.Tags != nil {
+		if tag, ok := mqtt.controller.Tags.GetTagById(call.Talkgroup.TagId); ok {
+			m["tag"] = tag.Label
+		}
+	}
+	if call.Frequency > 0 {
+		m["frequency"] = call.Frequency
+	}
+	if call.Transcript != "" {
+		m["transcript"] = call.Transcript
+	}
+	return m
+}
+
+func (mqtt *Mqtt) publish(topic string, m map[string]any) {
+	payload, err := json.Marshal(m)
+	if err != nil {
+		return
+	}
+
+	select {
+	case mqtt.queue <- mqttMessage{topic: topic, payload: payload}:
+	default:
+		mqtt.dropped.Add(1)
+	}
+}
+
+func (mqtt *Mqtt) run() {
+	delay := mqtt.retryDelay
+	var unsent *mqttMessage
+
+	for {
+		conn, err := mqtt.connect()
+		if err != nil {
+			log.Printf("mqtt: %s: %v, retrying in %s", mqtt.redactedBroker(), err, delay)
+			select {
+			case <-mqtt.stopChan:
+				return
+			case <-time.After(delay):
+			}
+			if delay *= 2; delay > mqttRetryMaxDelay {
+				delay = mqttRetryMaxDelay
+			}
+			continue
+		}
+
+		delay = mqtt.retryDelay
+		mqtt.connected.Store(true)
+		log.Printf("mqtt: connected to %s", mqtt.redactedBroker())
+
+		var stopped bool
+		stopped, unsent, err = mqtt.serve(conn, unsent)
+		mqtt.connected.Store(false)
+		conn.Close()
+		if stopped {
+			return
+		}
+		log.Printf("mqtt: connection to %s lost: %v", mqtt.redactedBroker(), err)
+	}
+}
+
+// serve publishes queued messages and keeps the connection alive until it
+// fails or the publisher stops. A message whose write failed is handed back
+// so it is sent first after reconnecting.
+func (mqtt *Mqtt) serve(conn net.Conn, unsent *mqttMessage) (bool, *mqttMessage, error) {
+	lost := make(chan error, 1)
+	go func() {
+		// Drain the broker's answers (PINGRESP); any error means the
+		// connection is gone.
+		reader := bufio.NewReader(conn)
+		for {
+			conn.SetReadDeadline(time.Now().Add(mqttKeepAlive + mqttKeepAlive/2))
+			if _, _, err := mqttReadPacket(reader); err != nil {
+				lost <- err
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(mqttKeepAlive / 2)
+	defer ping.Stop()
+
+	write := func(packet []byte) error {
+		conn.SetWriteDeadline(time.Now().Add(mqttDialTimeout))
+		_, err := conn.Write(packet)
+		return err
+	}
+
+	if unsent != nil {
+		if err := write(mqttPublishPacket(unsent.topic, unsent.payload)); err != nil {
+			return false, unsent, err
+		}
+	}
+
+	for {
+		select {
+		case <-mqtt.stopChan:
+			write(mqttPacket(mqttPacketDisconnect, nil))
+			return true, nil, nil
+
+		case err := <-lost:
+			return false, nil, err
+
+		case message := <-mqtt.queue:
+			if err := write(mqttPublishPacket(message.topic, message.payload)); err != nil {
+				return false, &message, err
+			}
+
+		case <-ping.C:
+			if err := write(mqttPacket(mqttPacketPingreq, nil)); err != nil {
+				return false, nil, err
+			}
+		}
+	}
+}
+
+// connect dials the broker (mqtt:// or tcp:// on 1883, mqtts://, ssl:// or
+// tls:// on 8883) and completes the MQTT handshake.
+func (mqtt *Mqtt) connect() (net.Conn, error) {
+	u, err := url.Parse(mqtt.broker)
+	if err != nil {
+		return nil, err
+	}
+
+	username, password := mqtt.username, mqtt.password
+	if username == "" && u.User != nil {
+		username = u.User.Username()
+		password, _ = u.User.Password()
+	}
+
+	var secure bool
+	switch u.Scheme {
+	case "mqtt", "tcp":
+	case "mqtts", "ssl", "tls":
+		secure = true
+	default:
+		return nil, fmt.Errorf("unsupported broker scheme %q", u.Scheme)
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		if secure {
+			host = net.JoinHostPort(u.Hostname(), "8883")
+		} else {
+			host = net.JoinHostPort(u.Hostname(), "1883")
+		}
+	}
+
+	dialer := &net.Dialer{Timeout: mqttDialTimeout}
+	var conn net.Conn
+	if secure {
+		conn, err = tls.DialWithDialer(dialer, "tcp", host, &tls.Config{ServerName: u.Hostname()})
+	} else {
+		conn, err = dialer.Dial("tcp", host)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	conn.SetDeadline(time.Now().Add(mqttDialTimeout))
+	if _, err = conn.Write(mqttConnectPacket(mqtt.clientId, username, password, mqttKeepAlive)); err == nil {
+		var header byte
+		var body []byte
+		header, body, err = mqttReadPacket(bufio.NewReader(conn))
+		switch {
+		case err != nil:
+		case header&0xf0 != mqttPacketConnack || len(body) < 2:
+			err = errors.New("unexpected answer to CONNECT")
+		case body[1] != 0:
+			if reason, ok := mqttConnackErrors[body[1]]; ok {
+				err = fmt.Errorf("connection refused: %s", reason)
+			} else {
+				err = fmt.Errorf("connection refused: code %d", body[1])
+			}
+		}
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+
+	return conn, nil
+}
+
+func (mqtt *Mqtt) redactedBroker() string {
+	if u, err := url.Parse(mqtt.broker); err == nil {
+		return u.Redacted()
+	}
+	return mqtt.broker
+}
+
+func mqttConnectPacket(clientId string, username string, password string, keepAlive time.Duration) []byte {
+	var b bytes.Buffer
+	mqttWriteString(&b, "MQTT")
+	b.WriteByte(4) // protocol level 3.1.1
+
+	flags := byte(0x02) // clean session
+	if username != "" {
+		flags |= 0x80
+		if password != "" {
+			flags |= 0x40
+		}
+	}
+	b.WriteByte(flags)
+	binary.Write(&b, binary.BigEndian, uint16(keepAlive/time.Second))
+
+	mqttWriteString(&b, clientId)
+	if username != "" {
+		mqttWriteString(&b, username)
+		if password != "" {
+			mqttWriteString(&b, password)
+		}
+	}
+
+	return mqttPacket(mqttPacketConnect, b.Bytes())
+}
+
+func mqttPublishPacket(topic string, payload []byte) []byte {
+	var b bytes.Buffer
+	mqttWriteString(&b, topic)
+	b.Write(payload)
+	return mqttPacket(mqttPacketPublish, b.Bytes())
+}
+
+func mqttPacket(header byte, body []byte) []byte {
+	packet := []byte{header}
+	length := len(body)
+	for {
+		digit := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			digit |= 0x80
+		}
+		packet = append(packet, digit)
+		if length == 0 {
+			break
+		}
+	}
+	return append(packet, body...)
+}
+
+func mqttReadPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, errors.New("malformed remaining length")
+		}
+		digit, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(digit&0x7f) * multiplier
+		multiplier *= 128
+		if digit&0x80 == 0 {
+			break
+		}
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+
+	return header, body, nil
+}
+
+func mqttWriteString(b *bytes.Buffer, s string) {
+	binary.Write(b, binary.BigEndian, uint16(len(s)))
+	b.WriteString(s)
+}
@@ -0,0 +1,102 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestMqttPacketLength(t *testing.T) {
+	for _, size := range []int{0, 127, 128, 16383, 16384} {
+		packet := mqttPacket(mqttPacketPublish, bytes.Repeat([]byte{'x'}, size))
+		header, body, err := mqttReadPacket(bufio.NewReader(bytes.NewReader(packet)))
+		if err != nil || header != mqttPacketPublish || len(body) != size {
+			t.Fatalf("size %d: header %#x, body %d bytes, err %v", size, header, len(body), err)
+		}
+	}
+}
+
+func TestMqttPublishesAndReconnects(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	mqtt := &Mqtt{
+		broker:     "mqtt://" + ln.Addr().String(),
+		prefix:     "tlr",
+		username:   "scada",
+		password:   "secret",
+		clientId:   "test",
+		stopChan:   make(chan struct{}),
+		retryDelay: 10 * time.Millisecond,
+	}
+	mqtt.Start()
+	defer mqtt.Stop()
+
+	accept := func() (net.Conn, *bufio.Reader) {
+		ln.(*net.TCPListener).SetDeadline(time.Now().Add(5 * time.Second))
+		conn, err := ln.Accept()
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		reader := bufio.NewReader(conn)
+
+		header, body, err := mqttReadPacket(reader)
+		if err != nil || header != mqttPacketConnect {
+			t.Fatalf("CONNECT: header %#x, err %v", header, err)
+		}
+		fields := []string{}
+		for r := bytes.NewReader(body[10:]); r.Len() > 0; {
+			var n uint16
+			binary.Read(r, binary.BigEndian, &n)
+			s := make([]byte, n)
+			r.Read(s)
+			fields = append(fields, string(s))
+		}
+		if body[7]&0xc0 != 0xc0 || len(fields) != 3 || fields[0] != "test" || fields[1] != "scada" || fields[2] != "secret" {
+			t.Fatalf("CONNECT flags %#x, payload %q", body[7], fields)
+		}
+
+		conn.Write([]byte{mqttPacketConnack, 2, 0, 0})
+		return conn, reader
+	}
+
+	expect := func(reader *bufio.Reader, topic string, callId uint64) {
+		header, body, err := mqttReadPacket(reader)
+		if err != nil || header != mqttPacketPublish {
+			t.Fatalf("PUBLISH: header %#x, err %v", header, err)
+		}
+		n := int(binary.BigEndian.Uint16(body))
+		if got := string(body[2 : 2+n]); got != topic {
+			t.Fatalf("topic %q, want %q", got, topic)
+		}
+		var m map[string]any
+		if err := json.Unmarshal(body[2+n:], &m); err != nil || m["callId"] != float64(callId) {
+			t.Fatalf("payload %s", body[2+n:])
+		}
+	}
+
+	call := &Call{Id: 7, System: &System{SystemRef: 1, Label: "County"}, Talkgroup: &Talkgroup{TalkgroupRef: 100, Label: "FD"}, Timestamp: time.Now()}
+
+	conn, reader := accept()
+	mqtt.PublishCall(call)
+	expect(reader, "tlr/1/100/call", 7)
+
+	// The broker goes away; the publisher reconnects on its own.
+	conn.Close()
+	conn, reader = accept()
+	defer conn.Close()
+
+	call.Id = 8
+	mqtt.PublishCall(call)
+	expect(reader, "tlr/1/100/call", 8)
+}
@@ -89,9 +89,16 @@ func anyToBool(v any) (bool, bool) {
 
 type Options struct {
 	AudioConversion             uint   `json:"audioConversion"`
+	AudioConversionForce        bool   `json:"audioConversionForce"` // re-encode even when input already matches the target codec
+	AudioFormat                 string `json:"audioFormat"`
+	AudioLoudnormTwoPass        bool   `json:"audioLoudnormTwoPass"` // measure then normalize; doubles ffmpeg work
+	SilenceTrimEnabled          bool   `json:"silenceTrimEnabled"`
+	SilenceTrimThreshold        int    `json:"silenceTrimThreshold"` // dBFS below which audio counts as silence
+	SilenceTrimDuration         uint   `json:"silenceTrimDuration"`  // ms of silence kept at each end
 	AutoPopulate                bool   `json:"autoPopulate"`
 	Branding                    string `json:"branding"`
 	DefaultSystemDelay          uint   `json:"defaultSystemDelay"`
+	ToneSetCooldownSeconds      uint   `json:"toneSetCooldownSeconds"` // suppress repeat alerts per tone set; tone sets may override
 	DisableDuplicateDetection   bool   `json:"disableDuplicateDetection"`
 	DuplicateDetectionTimeFrame uint   `json:"duplicateDetectionTimeFrame"` // in-memory cache TTL (ms)
 	DuplicateTimestampWindow    uint   `json:"duplicateTimestampWindow"`    // ±ms window for timestamp fallback (default 800)
@@ -218,12 +225,18 @@ type Options struct {
 	ReconnectionEnabled       bool `json:"reconnectionEnabled"`
 	ReconnectionGracePeriod   uint `json:"reconnectionGracePeriod"`   // In seconds
 	ReconnectionMaxBufferSize uint `json:"reconnectionMaxBufferSize"` // Maximum calls to buffer per user
+	// Tag labels whose talkgroups start enabled in a new client's livefeed (empty = none)
+	DefaultLivefeedTags []string `json:"defaultLivefeedTags"`
 	// Centralized Management Integration
-	CentralManagementEnabled    bool   `json:"centralManagementEnabled"`
-	CentralManagementURL        string `json:"centralManagementURL"`
-	CentralManagementAPIKey     string `json:"centralManagementAPIKey"`
-	CentralManagementServerName string `json:"centralManagementServerName"` // Optional friendly name for this server
-	CentralManagementServerID   string `json:"centralManagementServerID"`   // CM correlation id; when provisioned from CM with Hydra, equals rr_system_id (Radio Reference system id)
+	CentralManagementEnabled      bool   `json:"centralManagementEnabled"`
+	CentralManagementURL          string `json:"centralManagementURL"`
+	CentralManagementAPIKey       string `json:"centralManagementAPIKey"`
+	CentralManagementServerName   string `json:"centralManagementServerName"`   // Optional friendly name for this server
+	CentralManagementServerID     string `json:"centralManagementServerID"`     // CM correlation id; when provisioned from CM with Hydra, equals rr_system_id (Radio Reference system id)
+	CentralManagementStrictGrants bool   `json:"centralManagementStrictGrants"` // Reject CM grants naming unknown systems/talkgroups instead of dropping them
+	// HMAC key for X-CM-Signature on CM webhooks; when signatures are required the static API key is refused
+	CentralManagementWebhookSecret    string `json:"centralManagementWebhookSecret"`
+	CentralManagementRequireSignature bool   `json:"centralManagementRequireSignature"`
 	// Hydra transcription integration (provisioned from Central Management)
 	HydraAPIKey               string `json:"hydraAPIKey"`               // Hydra API key for transcription retrieval
 	HydraTranscriptionEnabled bool   `json:"hydraTranscriptionEnabled"` // Per-server toggle for Hydra transcription
@@ -233,39 +246,43 @@ type Options struct {
 	// refresh token, which is what re-authenticates silently after restart.
 	RelayAccountUsername     string `json:"relayAccountUsername"`
 	RelayAccountRefreshToken string `json:"relayAccountRefreshToken"`
-	adminPassword             string
-	adminPasswordNeedChange   bool
-	mutex                     sync.Mutex
-	secret                    string
+	adminPassword            string
+	// VAPID key pair for Web Push (see webpush.go). Generated on first use and
+	// never sent to the admin UI, so a config save can't clear it.
+	vapidPublicKey          string
+	vapidPrivateKey         string
+	adminPasswordNeedChange bool
+	mutex                   sync.Mutex
+	secret                  string
 }
 
 // TranscriptionConfig contains configuration for transcription
 type TranscriptionConfig struct {
-	Enabled                     bool     `json:"enabled"`
-	Provider                    string   `json:"provider"` // "whisper-api", "azure", "google", "assemblyai", "cloudflare", "gemini"
-	Language                    string   `json:"language"` // "en", "auto"
-	Prompt                      string   `json:"prompt"`   // Custom prompt for Whisper to guide transcription (e.g., terminology, formatting)
-	WorkerPoolSize              int      `json:"workerPoolSize"`
-	MinCallDuration             float64  `json:"minCallDuration"`             // Minimum call duration in seconds to transcribe (default: 0 = transcribe all)
-	WhisperAPIURL               string   `json:"whisperAPIURL"`               // Base URL for external Whisper API server (e.g., "http://localhost:8000") or OpenAI API URL
-	WhisperAPIKey               string   `json:"whisperAPIKey"`               // Optional API key for external Whisper API server or OpenAI API key
-	WhisperAPIModel             string   `json:"whisperAPIModel"`             // Model to use for transcription (e.g., "whisper-1", "gpt-4o-transcribe")
-	AzureKey                    string   `json:"azureKey"`                    // Azure Speech Services subscription key
-	AzureRegion                 string   `json:"azureRegion"`                 // Azure Speech Services region (e.g., "eastus", "westus2")
-	GoogleAPIKey                string   `json:"googleAPIKey"`                // Google Cloud Speech-to-Text API key
-	GoogleCredentials           string   `json:"googleCredentials"`           // Google Cloud service account JSON credentials (alternative to API key)
-	GeminiAPIKey                string   `json:"geminiAPIKey"`                // Google AI Studio / Gemini API key
-	GeminiModel                 string   `json:"geminiModel"`                 // Gemini model id (default gemini-3.1-flash-lite)
-	AssemblyAIKey               string   `json:"assemblyAIKey"`               // AssemblyAI API key
-	AssemblyAISpeechModel       string   `json:"assemblyAISpeechModel"`       // Speech model for AssemblyAI: "universal-2" (default) or "universal-3-pro"
-	AssemblyAIWordBoost         []string `json:"assemblyAIWordBoost"`         // Sent as AssemblyAI keyterms_prompt (max 100 terms, 50 chars each)
-	CloudflareAccountID         string   `json:"cloudflareAccountID"`         // Cloudflare account ID for Workers AI
-	CloudflareAPIToken          string   `json:"cloudflareAPIToken"`          // Cloudflare API token for Workers AI
-	CloudflareModel             string   `json:"cloudflareModel"`             // Cloudflare Workers AI model (default: @cf/openai/whisper-large-v3-turbo)
-	HallucinationPatterns       []string `json:"hallucinationPatterns"`       // Patterns to remove from transcripts (Whisper hallucinations)
-	HallucinationDetectionMode         string   `json:"hallucinationDetectionMode"`         // "off", "manual", "auto"
-	HallucinationMinOccurrences        int      `json:"hallucinationMinOccurrences"`        // Minimum times a phrase must appear in rejected calls before flagging (default: 5)
-	HallucinationConfidenceThreshold   float64  `json:"hallucinationConfidenceThreshold"`   // 0.0-1.0; auto-removal requires score >= threshold*10 (default: 0.6)
+	Enabled                          bool     `json:"enabled"`
+	Provider                         string   `json:"provider"` // "whisper-api", "whisper-cpp", "azure", "google", "assemblyai", "cloudflare", "gemini"
+	Language                         string   `json:"language"` // "en", "auto"
+	Prompt                           string   `json:"prompt"`   // Custom prompt for Whisper to guide transcription (e.g., terminology, formatting)
+	WorkerPoolSize                   int      `json:"workerPoolSize"`
+	MinCallDuration                  float64  `json:"minCallDuration"`                  // Minimum call duration in seconds to transcribe (default: 0 = transcribe all)
+	WhisperAPIURL                    string   `json:"whisperAPIURL"`                    // Base URL for external Whisper API server (e.g., "http://localhost:8000") or OpenAI API URL
+	WhisperAPIKey                    string   `json:"whisperAPIKey"`                    // Optional API key for external Whisper API server or OpenAI API key
+	WhisperAPIModel                  string   `json:"whisperAPIModel"`                  // Model to use for transcription (e.g., "whisper-1", "gpt-4o-transcribe")
+	AzureKey                         string   `json:"azureKey"`                         // Azure Speech Services subscription key
+	AzureRegion                      string   `json:"azureRegion"`                      // Azure Speech Services region (e.g., "eastus", "westus2")
+	GoogleAPIKey                     string   `json:"googleAPIKey"`                     // Google Cloud Speech-to-Text API key
+	GoogleCredentials                string   `json:"googleCredentials"`                // Google Cloud service account JSON credentials (alternative to API key)
+	GeminiAPIKey                     string   `json:"geminiAPIKey"`                     // Google AI Studio / Gemini API key
+	GeminiModel                      string   `json:"geminiModel"`                      // Gemini model id (default gemini-3.1-flash-lite)
+	AssemblyAIKey                    string   `json:"assemblyAIKey"`                    // AssemblyAI API key
+	AssemblyAISpeechModel            string   `json:"assemblyAISpeechModel"`            // Speech model for AssemblyAI: "universal-2" (default) or "universal-3-pro"
+	AssemblyAIWordBoost              []string `json:"assemblyAIWordBoost"`              // Sent as AssemblyAI keyterms_prompt (max 100 terms, 50 chars each)
+	CloudflareAccountID              string   `json:"cloudflareAccountID"`              // Cloudflare account ID for Workers AI
+	CloudflareAPIToken               string   `json:"cloudflareAPIToken"`               // Cloudflare API token for Workers AI
+	CloudflareModel                  string   `json:"cloudflareModel"`                  // Cloudflare Workers AI model (default: @cf/openai/whisper-large-v3-turbo)
+	HallucinationPatterns            []string `json:"hallucinationPatterns"`            // Patterns to remove from transcripts (Whisper hallucinations)
+	HallucinationDetectionMode       string   `json:"hallucinationDetectionMode"`       // "off", "manual", "auto"
+	HallucinationMinOccurrences      int      `json:"hallucinationMinOccurrences"`      // Minimum times a phrase must appear in rejected calls before flagging (default: 5)
+	HallucinationConfidenceThreshold float64  `json:"hallucinationConfidenceThreshold"` // 0.0-1.0; auto-removal requires score >= threshold*10 (default: 0.6)
 	// TimeoutSeconds controls the maximum time to wait for a transcription response.
 	// This sets both the overall HTTP client timeout and the per-transport response-header timeout,
 	// which is the one most likely to fire on slow local Whisper servers (they don't send headers
@@ -275,6 +292,11 @@ type TranscriptionConfig struct {
 	// SendLocationContext appends talkgroup/system incident-mapping location
 	// context (LocationContext / GeoCity) to the STT prompt when available.
 	SendLocationContext bool `json:"sendLocationContext"`
+	// Local whisper.cpp provider: CLI binary (name on PATH or full path), ggml model
+	// file and threads per transcription (0 = whisper.cpp default).
+	WhisperCppBinary  string `json:"whisperCppBinary"`
+	WhisperCppModel   string `json:"whisperCppModel"`
+	WhisperCppThreads int    `json:"whisperCppThreads"`
 	// Whisper training export — reviewed transcripts sent to transcript-collector on approve.
 	CollectorURL    string `json:"collectorURL"`
 	CollectorAPIKey string `json:"collectorAPIKey"`
@@ -295,6 +317,40 @@ const (
 	AUDIO_CONVERSION_ENABLED_LOUD_NORM = 3 // loudnorm I=-16 (louder target)
 )
 
+// Output container for converted audio. FLAC is lossless and meant for
+// archival; expect files several times larger than the 48k AAC default.
+const (
+	AUDIO_FORMAT_AAC  = "aac"
+	AUDIO_FORMAT_FLAC = "flac"
+)
+
+// Silence trimming bounds. Anything quieter than -20 dBFS risks eating soft
+// speech, and keeping less than 100 ms clips word onsets.
+const (
+	silenceTrimThresholdMin = -90
+	silenceTrimThresholdMax = -20
+	silenceTrimDurationMin  = 100
+	silenceTrimDurationMax  = 5000
+)
+
+func clampSilenceTrimThreshold(db int) int {
+	if db < silenceTrimThresholdMin {
+		return silenceTrimThresholdMin
+	} else if db > silenceTrimThresholdMax {
+		return silenceTrimThresholdMax
+	}
+	return db
+}
+
+func clampSilenceTrimDuration(ms uint) uint {
+	if ms < silenceTrimDurationMin {
+		return silenceTrimDurationMin
+	} else if ms > silenceTrimDurationMax {
+		return silenceTrimDurationMax
+	}
+	return ms
+}
+
 const relayServerBaseURL = "https://app.thinlineradio.com"
 
 // getRelayServerURL returns the fixed relay server base URL. All TLR instances
@@ -332,6 +388,52 @@ func (options *Options) FromMap(m map[string]any) *Options {
 		options.AudioConversion = defaults.options.audioConversion
 	}
 
+	switch v := m["audioConversionForce"].(type) {
+	case bool:
+		options.AudioConversionForce = v
+	default:
+		options.AudioConversionForce = defaults.options.audioConversionForce
+	}
+
+	switch v := m["audioFormat"].(type) {
+	case string:
+		if v == AUDIO_FORMAT_FLAC {
+			options.AudioFormat = AUDIO_FORMAT_FLAC
+		} else {
+			options.AudioFormat = AUDIO_FORMAT_AAC
+		}
+	default:
+		options.AudioFormat = defaults.options.audioFormat
+	}
+
+	switch v := m["audioLoudnormTwoPass"].(type) {
+	case bool:
+		options.AudioLoudnormTwoPass = v
+	default:
+		options.AudioLoudnormTwoPass = defaults.options.audioLoudnormTwoPass
+	}
+
+	switch v := m["silenceTrimEnabled"].(type) {
+	case bool:
+		options.SilenceTrimEnabled = v
+	default:
+		options.SilenceTrimEnabled = defaults.options.silenceTrimEnabled
+	}
+
+	switch v := m["silenceTrimThreshold"].(type) {
+	case float64:
+		options.SilenceTrimThreshold = clampSilenceTrimThreshold(int(v))
+	default:
+		options.SilenceTrimThreshold = defaults.options.silenceTrimThreshold
+	}
+
+	switch v := m["silenceTrimDuration"].(type) {
+	case float64:
+		options.SilenceTrimDuration = clampSilenceTrimDuration(uint(v))
+	default:
+		options.SilenceTrimDuration = defaults.options.silenceTrimDuration
+	}
+
 	switch v := m["autoPopulate"].(type) {
 	case bool:
 		options.AutoPopulate = v
@@ -350,6 +452,13 @@ func (options *Options) FromMap(m map[string]any) *Options {
 		options.DefaultSystemDelay = defaults.options.defaultSystemDelay
 	}
 
+	switch v := m["toneSetCooldownSeconds"].(type) {
+	case float64:
+		options.ToneSetCooldownSeconds = uint(v)
+	default:
+		options.ToneSetCooldownSeconds = defaults.options.toneSetCooldownSeconds
+	}
+
 	switch v := m["branding"].(type) {
 	case string:
 		options.Branding = v
@@ -720,6 +829,27 @@ func (options *Options) FromMap(m map[string]any) *Options {
 		options.CentralManagementAPIKey = ""
 	}
 
+	switch v := m["centralManagementStrictGrants"].(type) {
+	case bool:
+		options.CentralManagementStrictGrants = v
+	default:
+		options.CentralManagementStrictGrants = false
+	}
+
+	switch v := m["centralManagementWebhookSecret"].(type) {
+	case string:
+		options.CentralManagementWebhookSecret = v
+	default:
+		options.CentralManagementWebhookSecret = ""
+	}
+
+	switch v := m["centralManagementRequireSignature"].(type) {
+	case bool:
+		options.CentralManagementRequireSignature = v
+	default:
+		options.CentralManagementRequireSignature = false
+	}
+
 	switch v := m["centralManagementServerName"].(type) {
 	case string:
 		options.CentralManagementServerName = v
@@ -991,6 +1121,8 @@ func (options *Options) FromMap(m map[string]any) *Options {
 		options.ReconnectionMaxBufferSize = defaults.options.reconnectionMaxBufferSize
 	}
 
+	options.DefaultLivefeedTags = parseLivefeedTags(m["defaultLivefeedTags"])
+
 	if v, ok := m["transcriptionEnhancement"].(bool); ok {
 		options.TranscriptionEnhancement = v
 	}
@@ -1060,6 +1192,15 @@ func (options *Options) FromMap(m map[string]any) *Options {
 		if v, ok := tc["cloudflareModel"].(string); ok {
 			options.TranscriptionConfig.CloudflareModel = v
 		}
+		if v, ok := tc["whisperCppBinary"].(string); ok {
+			options.TranscriptionConfig.WhisperCppBinary = v
+		}
+		if v, ok := tc["whisperCppModel"].(string); ok {
+			options.TranscriptionConfig.WhisperCppModel = v
+		}
+		if v, ok := tc["whisperCppThreads"].(float64); ok && v >= 0 {
+			options.TranscriptionConfig.WhisperCppThreads = int(v)
+		}
 		if v, ok := tc["assemblyAIWordBoost"].([]interface{}); ok {
 			wordBoost := make([]string, 0, len(v))
 			for _, wb := range v {
@@ -1230,9 +1371,16 @@ func (options *Options) Read(db *Database) error {
 	options.adminPassword = string(defaultPassword)
 	options.adminPasswordNeedChange = defaults.adminPasswordNeedChange
 	options.AudioConversion = defaults.options.audioConversion
+	options.AudioConversionForce = defaults.options.audioConversionForce
+	options.AudioFormat = defaults.options.audioFormat
+	options.AudioLoudnormTwoPass = defaults.options.audioLoudnormTwoPass
+	options.SilenceTrimEnabled = defaults.options.silenceTrimEnabled
+	options.SilenceTrimThreshold = defaults.options.silenceTrimThreshold
+	options.SilenceTrimDuration = defaults.options.silenceTrimDuration
 	options.AutoPopulate = defaults.options.autoPopulate
 	options.Branding = defaults.options.branding
 	options.DefaultSystemDelay = defaults.options.defaultSystemDelay
+	options.ToneSetCooldownSeconds = defaults.options.toneSetCooldownSeconds
 	options.DisableDuplicateDetection = defaults.options.disableDuplicateDetection
 	options.DuplicateDetectionTimeFrame = defaults.options.duplicateDetectionTimeFrame
 	options.DuplicateTimestampWindow = defaults.options.duplicateTimestampWindow
@@ -1312,6 +1460,50 @@ func (options *Options) Read(db *Database) error {
 					options.AudioConversion = uint(v)
 				}
 			}
+		case "audioConversionForce":
+			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
+				switch v := f.(type) {
+				case bool:
+					options.AudioConversionForce = v
+				}
+			}
+		case "audioFormat":
+			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
+				switch v := f.(type) {
+				case string:
+					if v == AUDIO_FORMAT_FLAC {
+						options.AudioFormat = AUDIO_FORMAT_FLAC
+					}
+				}
+			}
+		case "audioLoudnormTwoPass":
+			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
+				switch v := f.(type) {
+				case bool:
+					options.AudioLoudnormTwoPass = v
+				}
+			}
+		case "silenceTrimEnabled":
+			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
+				switch v := f.(type) {
+				case bool:
+					options.SilenceTrimEnabled = v
+				}
+			}
+		case "silenceTrimThreshold":
+			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
+				switch v := f.(type) {
+				case float64:
+					options.SilenceTrimThreshold = clampSilenceTrimThreshold(int(v))
+				}
+			}
+		case "silenceTrimDuration":
+			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
+				switch v := f.(type) {
+				case float64:
+					options.SilenceTrimDuration = clampSilenceTrimDuration(uint(v))
+				}
+			}
 		case "autoPopulate":
 			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
 				switch v := f.(type) {
@@ -1333,6 +1525,13 @@ func (options *Options) Read(db *Database) error {
 					options.DefaultSystemDelay = uint(v)
 				}
 			}
+		case "toneSetCooldownSeconds":
+			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
+				switch v := f.(type) {
+				case float64:
+					options.ToneSetCooldownSeconds = uint(v)
+				}
+			}
 		case "disableDuplicateDetection":
 			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
 				switch v := f.(type) {
@@ -1493,6 +1692,27 @@ func (options *Options) Read(db *Database) error {
 					options.CentralManagementAPIKey = v
 				}
 			}
+		case "centralManagementStrictGrants":
+			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
+				switch v := f.(type) {
+				case bool:
+					options.CentralManagementStrictGrants = v
+				}
+			}
+		case "centralManagementWebhookSecret":
+			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
+				switch v := f.(type) {
+				case string:
+					options.CentralManagementWebhookSecret = v
+				}
+			}
+		case "centralManagementRequireSignature":
+			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
+				switch v := f.(type) {
+				case bool:
+					options.CentralManagementRequireSignature = v
+				}
+			}
 		case "centralManagementServerName":
 			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
 				switch v := f.(type) {
@@ -1897,6 +2117,20 @@ func (options *Options) Read(db *Database) error {
 					options.RelayAccountRefreshToken = v
 				}
 			}
+		case "vapidPublicKey":
+			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
+				switch v := f.(type) {
+				case string:
+					options.vapidPublicKey = v
+				}
+			}
+		case "vapidPrivateKey":
+			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
+				switch v := f.(type) {
+				case string:
+					options.vapidPrivateKey = v
+				}
+			}
 		case "relayListenerEmailsInitialSyncDone":
 			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
 				switch v := f.(type) {
@@ -2030,6 +2264,10 @@ func (options *Options) Read(db *Database) error {
 					options.ReconnectionMaxBufferSize = uint(v)
 				}
 			}
+		case "defaultLivefeedTags":
+			if err = json.Unmarshal([]byte(value.String), &f); err == nil {
+				options.DefaultLivefeedTags = parseLivefeedTags(f)
+			}
 		}
 	}
 
@@ -2090,9 +2328,16 @@ func (options *Options) Write(db *Database) error {
 	set("adminPassword", options.adminPassword)
 	set("adminPasswordNeedChange", options.adminPasswordNeedChange)
 	set("audioConversion", options.AudioConversion)
+	set("audioConversionForce", options.AudioConversionForce)
+	set("audioFormat", options.AudioFormat)
+	set("audioLoudnormTwoPass", options.AudioLoudnormTwoPass)
+	set("silenceTrimEnabled", options.SilenceTrimEnabled)
+	set("silenceTrimThreshold", options.SilenceTrimThreshold)
+	set("silenceTrimDuration", options.SilenceTrimDuration)
 	set("autoPopulate", options.AutoPopulate)
 	set("branding", options.Branding)
 	set("defaultSystemDelay", options.DefaultSystemDelay)
+	set("toneSetCooldownSeconds", options.ToneSetCooldownSeconds)
 	set("disableDuplicateDetection", options.DisableDuplicateDetection)
 	set("duplicateDetectionTimeFrame", options.DuplicateDetectionTimeFrame)
 	set("duplicateTimestampWindow", options.DuplicateTimestampWindow)
@@ -2117,6 +2362,9 @@ func (options *Options) Write(db *Database) error {
 	set("centralManagementAPIKey", options.CentralManagementAPIKey)
 	set("centralManagementServerName", options.CentralManagementServerName)
 	set("centralManagementServerID", options.CentralManagementServerID)
+	set("centralManagementStrictGrants", options.CentralManagementStrictGrants)
+	set("centralManagementWebhookSecret", options.CentralManagementWebhookSecret)
+	set("centralManagementRequireSignature", options.CentralManagementRequireSignature)
 	set("stripePaywallEnabled", options.StripePaywallEnabled)
 	set("emailServiceEnabled", options.EmailServiceEnabled)
 	set("emailServiceApiKey", options.EmailServiceApiKey)
@@ -2167,6 +2415,10 @@ func (options *Options) Write(db *Database) error {
 	set("relayServerAPIKey", options.RelayServerAPIKey)
 	set("relayAccountUsername", options.RelayAccountUsername)
 	set("relayAccountRefreshToken", options.RelayAccountRefreshToken)
+	if options.vapidPrivateKey != "" {
+		set("vapidPublicKey", options.vapidPublicKey)
+		set("vapidPrivateKey", options.vapidPrivateKey)
+	}
 	set("relayListenerEmailsInitialSyncDone", options.RelayListenerEmailsInitialSyncDone)
 	set("relayOwnerUnlockedPublicClient", options.RelayOwnerUnlockedPublicClient)
 	set("audioEncryptionEnabled", options.AudioEncryptionEnabled)
@@ -2186,6 +2438,7 @@ func (options *Options) Write(db *Database) error {
 	set("reconnectionEnabled", options.ReconnectionEnabled)
 	set("reconnectionGracePeriod", options.ReconnectionGracePeriod)
 	set("reconnectionMaxBufferSize", options.ReconnectionMaxBufferSize)
+	set("defaultLivefeedTags", options.DefaultLivefeedTags)
 	// Persist entire transcription config as a single JSON blob
 	set("transcriptionConfig", options.TranscriptionConfig)
 	set("openAIIntegration", options.OpenAIIntegration)
@@ -196,7 +196,11 @@ var PostgresqlSchema = []string{
     "level" text NOT NULL,
     "category" text NOT NULL DEFAULT 'system',
     "message" text NOT NULL,
-    "timestamp" bigint NOT NULL
+    "timestamp" bigint NOT NULL,
+    "callId" bigint,
+    "systemId" bigint,
+    "talkgroupId" bigint,
+    "context" text
   );`,
 
 	// Index for fast timestamp-based sorting and filtering on the logs table
@@ -257,6 +261,7 @@ var PostgresqlSchema = []string{
     "subscriptionStatus" text NOT NULL DEFAULT '',
     "accountExpiresAt" bigint NOT NULL DEFAULT 0,
     "forcePasswordReset" boolean NOT NULL DEFAULT false,
+    "modifiedAt" bigint NOT NULL DEFAULT 0,
     "settings" text NOT NULL DEFAULT ''
   );`,
 
@@ -281,7 +286,8 @@ var PostgresqlSchema = []string{
     "description" text NOT NULL DEFAULT '',
     "keywords" text NOT NULL DEFAULT '[]',
     "order" integer NOT NULL DEFAULT 0,
-    "createdAt" bigint NOT NULL DEFAULT 0
+    "createdAt" bigint NOT NULL DEFAULT 0,
+    "userGroupIds" text NOT NULL DEFAULT '[]'
   );`,
 
 	`CREATE TABLE IF NOT EXISTS "callNatures" (
@@ -361,6 +367,9 @@ var PostgresqlSchema = []string{
     "stripeTaxRateId" text NOT NULL DEFAULT '',
     "isPublicRegistration" boolean NOT NULL DEFAULT false,
     "allowAddExistingUsers" boolean NOT NULL DEFAULT false,
+    "reconnectionGracePeriod" integer NOT NULL DEFAULT 0,
+    "reconnectionMaxBufferSize" integer NOT NULL DEFAULT 0,
+    "defaultLivefeedTags" text NOT NULL DEFAULT '',
     "createdAt" bigint NOT NULL DEFAULT 0
   );`,
 
@@ -428,4 +437,10 @@ var PostgresqlSchema = []string{
 	// Add fcmToken and pushType columns if they don't exist (migration for existing databases)
 	`ALTER TABLE "deviceTokens" ADD COLUMN IF NOT EXISTS "fcmToken" text;`,
 	`ALTER TABLE "deviceTokens" ADD COLUMN IF NOT EXISTS "pushType" text NOT NULL DEFAULT 'onesignal';`,
+	// Web Push subscription JSON (endpoint + p256dh/auth keys) for pushType "webpush"
+	`ALTER TABLE "deviceTokens" ADD COLUMN IF NOT EXISTS "subscription" text NOT NULL DEFAULT '';`,
+	// Per-device quiet hours (minutes of day in the device's timezone; start == end disables)
+	`ALTER TABLE "deviceTokens" ADD COLUMN IF NOT EXISTS "quietStart" integer NOT NULL DEFAULT 0;`,
+	`ALTER TABLE "deviceTokens" ADD COLUMN IF NOT EXISTS "quietEnd" integer NOT NULL DEFAULT 0;`,
+	`ALTER TABLE "deviceTokens" ADD COLUMN IF NOT EXISTS "timezone" text NOT NULL DEFAULT '';`,
 }
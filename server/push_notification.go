@@ -18,17 +18,23 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
 // isLegacyOneSignalToken returns true for device tokens that were registered via
 // OneSignal and can no longer receive notifications through the FCM-only pipeline.
+// Web Push subscriptions have no FCM token but are not legacy.
 func isLegacyOneSignalToken(dt *DeviceToken) bool {
+	if dt.PushType == DeviceTokenPushTypeWebPush {
+		return false
+	}
 	return dt.FCMToken == "" || dt.PushType == "onesignal"
 }
 
@@ -228,12 +234,32 @@ func (controller *Controller) sendPushNotification(userId uint64, alertType stri
 	androidSound := "startup.wav"
 	iosSound := "startup.wav"
 	notifiedUsers := make(map[uint64]struct{})
+	var webDevices []*DeviceToken
+	// Devices inside their quiet hours get a silent push without pager_alert.
+	var quietAndroid, quietIOS []string
+	now := time.Now()
 
 	for _, device := range deviceTokens {
 		if isLegacyOneSignalToken(device) {
 			controller.handleLegacyOneSignalToken(device, notifiedUsers)
 			continue
 		}
+		if device.PushType == DeviceTokenPushTypeWebPush {
+			webDevices = append(webDevices, device)
+			continue
+		}
+		if device.InQuietHours(now) {
+			// VoIP always rings through CallKit, so it is dropped entirely.
+			if device.PushType == "voip" {
+				continue
+			}
+			if device.Platform == "ios" {
+				quietIOS = append(quietIOS, device.FCMToken)
+			} else {
+				quietAndroid = append(quietAndroid, device.FCMToken)
+			}
+			continue
+		}
 
 		// Sound priority: per-channel override → device default → fallback
 		effectiveSound := channelSound
@@ -276,7 +302,7 @@ func (controller *Controller) sendPushNotification(userId uint64, alertType stri
 		}
 	}
 
-	controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("push notification: grouped devices for user %d - Android: %d, iOS: %d (pager enabled: %v, pager claimed: %v)", userId, len(androidDevices), len(iosDevices), userPagerEnabled, pagerClaimed))
+	controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("push notification: grouped devices for user %d - Android: %d, iOS: %d, quiet: %d (pager enabled: %v, pager claimed: %v)", userId, len(androidDevices), len(iosDevices), len(quietAndroid)+len(quietIOS), userPagerEnabled, pagerClaimed))
 
 	// Build per-call extra data. pager_alert is only set when the user has the
 	// feature enabled AND the device doesn't have live feed active.
@@ -338,11 +364,26 @@ func (controller *Controller) sendPushNotification(userId uint64, alertType stri
 			controller.sendNotificationBatch(ids, title, "", message, "ios", sound, call, systemLabel, talkgroupLabel, extra)
 		}(iosDevices, iosSoundStripped, iosExtra)
 	}
+
+	if len(quietAndroid) > 0 {
+		go controller.sendNotificationBatch(quietAndroid, title, "", message, "android", "", call, systemLabel, talkgroupLabel, nil)
+	}
+	if len(quietIOS) > 0 {
+		go controller.sendNotificationBatch(quietIOS, title, "", message, "ios", "", call, systemLabel, talkgroupLabel, nil)
+	}
+
+	// Browser subscriptions are delivered directly with VAPID, not via the relay.
+	if len(webDevices) > 0 {
+		go controller.sendWebPushBatch(webDevices, title, message, webPushCallData(call))
+	}
 }
 
-func (controller *Controller) sendNotificationBatch(playerIDs []string, title, subtitle, message, platform, sound string, call *Call, systemLabel, talkgroupLabel string, extraData map[string]interface{}) {
+// sendNotificationBatch posts one notification for a platform to the relay server.
+// It returns the tokens the relay reported as invalid (already removed here) and
+// an error if the batch as a whole was not delivered.
+func (controller *Controller) sendNotificationBatch(playerIDs []string, title, subtitle, message, platform, sound string, call *Call, systemLabel, talkgroupLabel string, extraData map[string]interface{}) ([]string, error) {
 	if controller.RelayPushSuspended() {
-		return
+		return nil, errors.New("relay push suspended")
 	}
 	controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("push notification: sendNotificationBatch called with %d player ID(s) for %s platform", len(playerIDs), platform))
 	for i, playerID := range playerIDs {
@@ -424,7 +465,7 @@ func (controller *Controller) sendNotificationBatch(playerIDs []string, title, s
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
 		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to marshal push notification: %v", err))
-		return
+		return nil, err
 	}
 
 	// Send to relay server (hardcoded URL)
@@ -432,7 +473,7 @@ func (controller *Controller) sendNotificationBatch(playerIDs []string, title, s
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to create push notification request: %v", err))
-		return
+		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -446,7 +487,7 @@ func (controller *Controller) sendNotificationBatch(playerIDs []string, title, s
 	resp, err := client.Do(req)
 	if err != nil {
 		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("failed to send push notification: %v", err))
-		return
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -468,10 +509,10 @@ func (controller *Controller) sendNotificationBatch(playerIDs []string, title, s
 		// Fallback if response parsing fails
 		if resp.StatusCode != http.StatusOK {
 			controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("push notification failed (status %d): %s - this failure does not affect other batches", resp.StatusCode, string(body)))
-		} else {
-			controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("push notification sent to %d %s devices", len(playerIDs), platform))
+			return nil, fmt.Errorf("relay returned status %d", resp.StatusCode)
 		}
-		return
+		controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("push notification sent to %d %s devices", len(playerIDs), platform))
+		return nil, nil
 	}
 
 	// Handle invalid FCM tokens — relay server reports tokens it could not deliver to.
@@ -493,7 +534,7 @@ func (controller *Controller) sendNotificationBatch(playerIDs []string, title, s
 
 	if resp.StatusCode != http.StatusOK {
 		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("push notification failed (status %d): %s - this failure does not affect other batches", resp.StatusCode, response.Error))
-		return
+		return response.InvalidPlayerIDs, fmt.Errorf("relay returned status %d: %s", resp.StatusCode, response.Error)
 	}
 
 	// Handle successful response
@@ -502,6 +543,162 @@ func (controller *Controller) sendNotificationBatch(playerIDs []string, title, s
 	} else {
 		controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("push notification sent to %d %s devices", response.Recipients, platform))
 	}
+	return response.InvalidPlayerIDs, nil
+}
+
+// PushNotification is a single notification sent to every device a user has registered.
+type PushNotification struct {
+	Title    string
+	Subtitle string
+	Message  string
+	// Sound overrides the per-device sound; when empty the device's own sound is
+	// used, then DefaultSound.
+	Sound        string
+	DefaultSound string
+	Data         map[string]interface{}
+}
+
+// PushTokenResult reports what happened to one device token in SendToUser.
+type PushTokenResult struct {
+	TokenId   uint64 `json:"tokenId"`
+	Platform  string `json:"platform"`
+	Delivered bool   `json:"delivered"`
+	Pruned    bool   `json:"pruned"`
+	Error     string `json:"error,omitempty"`
+}
+
+// SendToUser sends a notification to all of a user's devices with one relay request per
+// platform. Legacy OneSignal tokens and tokens the relay reports as unregistered are
+// deleted; the result has one entry per token.
+func (dt *DeviceTokens) SendToUser(controller *Controller, userId uint64, notification *PushNotification) []PushTokenResult {
+	tokens := dt.GetByUser(userId)
+	results := make([]PushTokenResult, 0, len(tokens))
+	if len(tokens) == 0 {
+		return results
+	}
+
+	type platformBatch struct {
+		platform string
+		quiet    bool
+		tokens   []*DeviceToken
+		sound    string
+	}
+	batches := make(map[string]*platformBatch)
+	notifiedUsers := make(map[uint64]struct{})
+	var webDevices []*DeviceToken
+	now := time.Now()
+
+	for _, device := range tokens {
+		if isLegacyOneSignalToken(device) {
+			controller.handleLegacyOneSignalToken(device, notifiedUsers)
+			results = append(results, PushTokenResult{TokenId: device.Id, Platform: device.Platform, Pruned: true, Error: "legacy OneSignal token"})
+			continue
+		}
+		if device.PushType == DeviceTokenPushTypeWebPush {
+			webDevices = append(webDevices, device)
+			continue
+		}
+		quiet := device.InQuietHours(now)
+		if quiet && device.PushType == "voip" {
+			continue
+		}
+		platform := device.Platform
+		if platform != "ios" {
+			platform = "android"
+		}
+		// Quiet devices share a silent batch per platform.
+		key := platform
+		if quiet {
+			key = platform + "+quiet"
+		}
+		batch, ok := batches[key]
+		if !ok {
+			batch = &platformBatch{platform: platform, quiet: quiet}
+			batches[key] = batch
+		}
+		batch.tokens = append(batch.tokens, device)
+		if quiet {
+			continue
+		}
+
+		sound := notification.Sound
+		if sound == "" {
+			sound = device.Sound
+		}
+		if sound == "" {
+			sound = notification.DefaultSound
+		}
+		batch.sound = sound
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for _, batch := range batches {
+		ids := make([]string, len(batch.tokens))
+		for i, device := range batch.tokens {
+			ids[i] = device.FCMToken
+		}
+		platform := batch.platform
+		sound := batch.sound
+		if platform == "ios" {
+			sound = strings.TrimSuffix(sound, ".wav")
+			sound = strings.TrimSuffix(sound, ".mp3")
+			sound = strings.TrimSuffix(sound, ".m4a")
+		}
+
+		wg.Add(1)
+		go func(platform string, devices []*DeviceToken, ids []string, sound string) {
+			defer wg.Done()
+			invalid, err := controller.sendNotificationBatch(ids, notification.Title, notification.Subtitle, notification.Message, platform, sound, nil, "", "", notification.Data)
+
+			invalidSet := make(map[string]struct{}, len(invalid))
+			for _, token := range invalid {
+				invalidSet[token] = struct{}{}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, device := range devices {
+				result := PushTokenResult{TokenId: device.Id, Platform: platform}
+				if _, bad := invalidSet[device.FCMToken]; bad {
+					result.Pruned = true
+					result.Error = "unregistered"
+				} else if err != nil {
+					result.Error = err.Error()
+				} else {
+					result.Delivered = true
+				}
+				results = append(results, result)
+			}
+		}(platform, batch.tokens, ids, sound)
+	}
+
+	if len(webDevices) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, device := range webDevices {
+				result := PushTokenResult{TokenId: device.Id, Platform: device.Platform}
+				gone, err := controller.sendWebPush(device, notification.Title, notification.Message, notification.Data)
+				if gone {
+					if delErr := dt.Delete(device.Id, controller.Database, controller.Clients); delErr == nil {
+						result.Pruned = true
+					}
+				}
+				if err != nil {
+					result.Error = err.Error()
+				} else {
+					result.Delivered = true
+				}
+				mu.Lock()
+				results = append(results, result)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
 }
 
 // sendDisconnectPushNotification sends a push notification to a user's devices
@@ -511,19 +708,11 @@ func (controller *Controller) sendDisconnectPushNotification(user *User) {
 		return
 	}
 
-	deviceTokens := controller.DeviceTokens.GetByUser(user.Id)
-	if len(deviceTokens) == 0 {
-		return
-	}
-
 	serverName := controller.Options.Branding
 	if serverName == "" {
 		serverName = "TLR Server"
 	}
 
-	title := "DISCONNECTED"
-	message := fmt.Sprintf("You have been disconnected from %s", strings.ToUpper(serverName))
-
 	// Read the user's chosen disconnect alert sound (set via the mobile app's
 	// Notification Sounds screen). Falls back to the device default then "startup.wav".
 	disconnectSound := ""
@@ -536,60 +725,18 @@ func (controller *Controller) sendDisconnectPushNotification(user *User) {
 		}
 	}
 
-	androidDevices := []string{}
-	iosDevices := []string{}
-	androidSound := disconnectSound
-	iosSound := disconnectSound
-	notifiedUsers := make(map[uint64]struct{})
-
-	for _, device := range deviceTokens {
-		if isLegacyOneSignalToken(device) {
-			controller.handleLegacyOneSignalToken(device, notifiedUsers)
-			continue
-		}
-		// Fall back to device default sound if no disconnect-specific sound is set.
-		effectiveSound := disconnectSound
-		if effectiveSound == "" {
-			effectiveSound = device.Sound
-		}
-		if effectiveSound == "" {
-			effectiveSound = "startup.wav"
-		}
-		if device.Platform == "ios" {
-			iosDevices = append(iosDevices, device.FCMToken)
-			iosSound = effectiveSound
-		} else {
-			androidDevices = append(androidDevices, device.FCMToken)
-			androidSound = effectiveSound
-		}
-	}
-
-	if androidSound == "" {
-		androidSound = "startup.wav"
-	}
-	if iosSound == "" {
-		iosSound = "startup.wav"
-	}
-
-	disconnectExtra := map[string]interface{}{
-		"type":                 "disconnect",
-		"notification_message": "false",
-	}
-
-	if len(androidDevices) > 0 {
-		go func(ids []string, sound string) {
-			controller.sendNotificationBatch(ids, title, "", message, "android", sound, nil, "", "", disconnectExtra)
-		}(androidDevices, androidSound)
+	notification := &PushNotification{
+		Title:        "DISCONNECTED",
+		Message:      fmt.Sprintf("You have been disconnected from %s", strings.ToUpper(serverName)),
+		Sound:        disconnectSound,
+		DefaultSound: "startup.wav",
+		Data: map[string]interface{}{
+			"type":                 "disconnect",
+			"notification_message": "false",
+		},
 	}
 
-	if len(iosDevices) > 0 {
-		iosSoundStripped := strings.TrimSuffix(iosSound, ".wav")
-		iosSoundStripped = strings.TrimSuffix(iosSoundStripped, ".mp3")
-		iosSoundStripped = strings.TrimSuffix(iosSoundStripped, ".m4a")
-		go func(ids []string, sound string) {
-			controller.sendNotificationBatch(ids, title, "", message, "ios", sound, nil, "", "", disconnectExtra)
-		}(iosDevices, iosSoundStripped)
-	}
+	go controller.DeviceTokens.SendToUser(controller, user.Id, notification)
 }
 
 // sendDisconnectPushNotificationToDevice sends a disconnect notification to a
@@ -797,6 +944,8 @@ func (controller *Controller) sendBatchedPushNotificationWithToneSet(userIds []u
 	// some production timing cases; the admin test path was never split that way.
 	deviceGroups := make(map[string][]string)
 	notifiedUsers := make(map[uint64]struct{})
+	now := time.Now()
+	var webDevices []*DeviceToken
 
 	for _, userId := range userIds {
 		user := controller.Users.GetUserById(userId)
@@ -883,6 +1032,18 @@ func (controller *Controller) sendBatchedPushNotificationWithToneSet(userIds []u
 				controller.handleLegacyOneSignalToken(device, notifiedUsers)
 				continue
 			}
+			if device.PushType == DeviceTokenPushTypeWebPush {
+				webDevices = append(webDevices, device)
+				continue
+			}
+			// Quiet hours: silent batch (empty sound key, never +pager); no VoIP.
+			if device.InQuietHours(now) {
+				if device.PushType != "voip" {
+					key := device.Platform + ":"
+					deviceGroups[key] = append(deviceGroups[key], device.FCMToken)
+				}
+				continue
+			}
 
 			// Sound priority: per-tone-set/per-channel → device default → fallback
 			sound := channelSound
@@ -979,4 +1140,8 @@ func (controller *Controller) sendBatchedPushNotificationWithToneSet(userIds []u
 		}(playerIDs, platform, finalSound, batchExtra, delay)
 		batchIndex++
 	}
+
+	if len(webDevices) > 0 {
+		go controller.sendWebPushBatch(webDevices, title, message, webPushCallData(call))
+	}
 }
@@ -18,6 +18,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"strings"
 	"sync"
@@ -132,6 +133,87 @@ func (rl *RateLimiter) cleanup() {
 	}
 }
 
+// TokenBucketLimiter is a per-IP token bucket: each IP may burst up to
+// capacity requests, then is limited to refillRate requests per second.
+type TokenBucketLimiter struct {
+	buckets    map[string]*tokenBucket
+	mutex      sync.Mutex
+	capacity   float64
+	refillRate float64
+}
+
+type tokenBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// NewTokenBucketLimiter creates a new token bucket limiter
+// capacity: burst size per IP (e.g., 60)
+// refillRate: tokens added per second (e.g., 1)
+func NewTokenBucketLimiter(capacity int, refillRate float64) *TokenBucketLimiter {
+	tbl := &TokenBucketLimiter{
+		buckets:    make(map[string]*tokenBucket),
+		capacity:   float64(capacity),
+		refillRate: refillRate,
+	}
+
+	// Start cleanup goroutine
+	go tbl.cleanup()
+
+	return tbl
+}
+
+// Allow takes a token from the IP's bucket, returning false when it is empty
+func (tbl *TokenBucketLimiter) Allow(ip string) bool {
+	tbl.mutex.Lock()
+	defer tbl.mutex.Unlock()
+
+	now := time.Now()
+	bucket, exists := tbl.buckets[ip]
+	if !exists {
+		bucket = &tokenBucket{tokens: tbl.capacity, lastSeen: now}
+		tbl.buckets[ip] = bucket
+	} else {
+		bucket.tokens += now.Sub(bucket.lastSeen).Seconds() * tbl.refillRate
+		if bucket.tokens > tbl.capacity {
+			bucket.tokens = tbl.capacity
+		}
+		bucket.lastSeen = now
+	}
+
+	if bucket.tokens < 1 {
+		return false
+	}
+
+	bucket.tokens--
+	return true
+}
+
+// retryAfter is how long until an empty bucket has a token again
+func (tbl *TokenBucketLimiter) retryAfter() time.Duration {
+	if tbl.refillRate <= 0 {
+		return time.Minute
+	}
+	return time.Duration(float64(time.Second) / tbl.refillRate)
+}
+
+// cleanup removes buckets that have refilled completely
+func (tbl *TokenBucketLimiter) cleanup() {
+	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		tbl.mutex.Lock()
+		now := time.Now()
+		for ip, bucket := range tbl.buckets {
+			if bucket.tokens+now.Sub(bucket.lastSeen).Seconds()*tbl.refillRate >= tbl.capacity {
+				delete(tbl.buckets, ip)
+			}
+		}
+		tbl.mutex.Unlock()
+	}
+}
+
 // NewLoginAttemptTracker creates a new login attempt tracker
 // maxAttempts: maximum failed attempts before blocking (e.g., 6)
 // blockDuration: duration to block IP after max attempts (e.g., 15 minutes)
@@ -292,6 +374,27 @@ func RateLimitMiddleware(limiter *RateLimiter) func(http.Handler) http.Handler {
 	}
 }
 
+// TokenBucketMiddleware rejects requests with 429 once the client IP's bucket is empty
+func TokenBucketMiddleware(limiter *TokenBucketLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := getRemoteAddr(r)
+
+			if !limiter.Allow(ip) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Retry-After", fmt.Sprintf("%.0f", math.Ceil(limiter.retryAfter().Seconds())))
+				w.WriteHeader(http.StatusTooManyRequests)
+				json.NewEncoder(w).Encode(map[string]string{
+					"error": "Too many requests. Please try again later.",
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // LoginAttemptMiddleware checks if IP is blocked from login attempts
 // Returns JSON error with redirect URL for API calls
 func LoginAttemptMiddleware(tracker *LoginAttemptTracker) func(http.Handler) http.Handler {
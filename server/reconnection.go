@@ -16,12 +16,22 @@
 package main
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"log"
+	"os"
+	"sort"
 	"sync"
 	"time"
 )
 
+// reconnectionStateFile is written on shutdown so buffered calls survive a
+// server restart (e.g. after an auto-update).
+const reconnectionStateFile = "reconnection-state.json"
+
 // DisconnectedClientState holds the state of a recently disconnected client
 type DisconnectedClientState struct {
 	User          *User
@@ -29,6 +39,28 @@ type DisconnectedClientState struct {
 	MissedCalls   []*Call
 	Livefeed      *Livefeed
 	MaxBufferSize int
+	// HoldDuration is the effective grace period for this user, which may
+	// come from a per-group override.
+	HoldDuration time.Duration
+	// PendingCallIds are missed calls restored from disk after a restart;
+	// their payloads are fetched from the database on reconnect.
+	PendingCallIds []uint64
+	// LastCallId is the last call delivered before the disconnect; calls at
+	// or below it are never buffered.
+	LastCallId uint64
+}
+
+// persistedClientState is the on-disk form of a DisconnectedClientState.
+// Only call IDs are stored, not audio, and PIN-only users are keyed by a
+// hash of their PIN rather than the PIN itself.
+type persistedClientState struct {
+	UserId     uint64                 `json:"userId,omitempty"`
+	PinHash    string                 `json:"pinHash,omitempty"`
+	Pin        string                 `json:"pin,omitempty"` // written by older versions; read only
+	LastSeen   int64                  `json:"lastSeen"`
+	Livefeed   map[uint]map[uint]bool `json:"livefeed"`
+	CallIds    []uint64               `json:"callIds,omitempty"`
+	LastCallId uint64                 `json:"lastCallId,omitempty"`
 }
 
 // ReconnectionManager manages reconnection states for disconnected clients
@@ -37,10 +69,23 @@ type ReconnectionManager struct {
 	mutex        sync.RWMutex
 	HoldDuration time.Duration // How long to hold buffers
 	MaxBufferSize int          // Maximum calls to buffer per user
+	// MaxMemory caps the estimated bytes held by all states together (0 = no
+	// cap). Past it, buffered calls are reduced to IDs and, if that is not
+	// enough, the oldest states are dropped.
+	MaxMemory    int64
 	Enabled      bool
 	controller   *Controller
+	stop         chan struct{}
+	evicted      int // states dropped by the memory cap since startup
 }
 
+const (
+	// Rough per-call and per-state footprints beyond the audio itself, used
+	// by memoryEstimate.
+	reconnectionCallOverhead  = 1024
+	reconnectionStateOverhead = 512
+)
+
 // NewReconnectionManager creates a new reconnection manager
 func NewReconnectionManager(controller *Controller, holdDuration time.Duration, maxBufferSize int, enabled bool) *ReconnectionManager {
 	return &ReconnectionManager{
@@ -48,8 +93,10 @@ func NewReconnectionManager(controller *Controller, holdDuration time.Duration,
 		mutex:         sync.RWMutex{},
 		HoldDuration:  holdDuration,
 		MaxBufferSize: maxBufferSize,
+		MaxMemory:     int64(defaultReconnectionMaxMemory) << 20,
 		Enabled:       enabled,
 		controller:    controller,
+		stop:          make(chan struct{}),
 	}
 }
 
@@ -77,14 +124,20 @@ func (rm *ReconnectionManager) SaveDisconnectedState(client *Client) {
 		}
 	}
 
+	holdDuration, maxBufferSize := rm.limitsFor(client.User)
+
 	rm.States[userKey] = &DisconnectedClientState{
 		User:          client.User,
 		LastSeen:      time.Now(),
-		MissedCalls:   make([]*Call, 0, rm.MaxBufferSize),
+		MissedCalls:   make([]*Call, 0, maxBufferSize),
 		Livefeed:      livefeedCopy,
-		MaxBufferSize: rm.MaxBufferSize,
+		MaxBufferSize: maxBufferSize,
+		HoldDuration:  holdDuration,
+		LastCallId:    client.LastDeliveredCallId(),
 	}
 
+	rm.enforceMemoryLimit()
+
 	log.Printf("[ReconnectionManager] Saved state for user %s (PIN: %s)", userKey, client.User.Pin)
 }
 
@@ -101,7 +154,15 @@ func (rm *ReconnectionManager) BufferCallForDisconnected(call *Call) {
 	
 	for _, state := range rm.States {
 		// Skip if grace period expired
-		if now.Sub(state.LastSeen) > rm.HoldDuration {
+		if now.Sub(state.LastSeen) > state.HoldDuration {
+			continue
+		}
+
+		// Skip calls the client already received before disconnecting
+		if call.Id != 0 && call.Id <= state.LastCallId {
+			continue
+		}
+		if call.Timestamp.Before(state.LastSeen) {
 			continue
 		}
 
@@ -117,14 +178,202 @@ func (rm *ReconnectionManager) BufferCallForDisconnected(call *Call) {
 			}
 		}
 
-		// Add to buffer if not full
-		if len(state.MissedCalls) < state.MaxBufferSize {
-			state.MissedCalls = append(state.MissedCalls, call)
-		} else {
-			// Buffer full - remove oldest call and add new one (FIFO)
-			state.MissedCalls = append(state.MissedCalls[1:], call)
+		state.MissedCalls = bufferMissedCall(state.MissedCalls, call, state.MaxBufferSize)
+
+		// Calls restored from disk count against the same cap; they are the
+		// oldest, so they go first.
+		if over := len(state.PendingCallIds) + len(state.MissedCalls) - state.MaxBufferSize; over > 0 && len(state.PendingCallIds) > 0 {
+			state.PendingCallIds = state.PendingCallIds[min(over, len(state.PendingCallIds)):]
+		}
+	}
+
+	rm.enforceMemoryLimit()
+}
+
+// memoryEstimate approximates the bytes held by all states. A call buffered
+// for many users is shared, so its audio is counted once. Callers hold the
+// mutex.
+func (rm *ReconnectionManager) memoryEstimate() int64 {
+	var total int64
+	seen := map[*Call]bool{}
+
+	for _, state := range rm.States {
+		total += reconnectionStateOverhead + int64(8*(len(state.MissedCalls)+len(state.PendingCallIds)))
+		for _, call := range state.MissedCalls {
+			if call == nil || seen[call] {
+				continue
+			}
+			seen[call] = true
+			total += reconnectionCallOverhead + int64(len(call.Audio))
+		}
+	}
+
+	return total
+}
+
+// enforceMemoryLimit keeps memoryEstimate under MaxMemory. Regular calls
+// already saved to the database are reduced to their IDs first, oldest call
+// first and only until the estimate is under the cap; they are fetched again
+// on reconnect like calls restored after a restart. Only if that is not
+// enough are the oldest states dropped. Callers hold the mutex.
+func (rm *ReconnectionManager) enforceMemoryLimit() {
+	if rm.MaxMemory <= 0 {
+		return
+	}
+
+	estimate := rm.memoryEstimate()
+	if estimate <= rm.MaxMemory {
+		return
+	}
+
+	// A shared call is counted once, so its audio is only released once
+	// every state holding it has reduced it to its ID.
+	spilled := 0
+	for _, call := range rm.spillableCalls() {
+		if estimate <= rm.MaxMemory {
+			break
+		}
+		for _, state := range rm.States {
+			if spillMissedCall(state, call) {
+				spilled++
+			}
+		}
+		estimate -= reconnectionCallOverhead + int64(len(call.Audio))
+	}
+
+	keys := make([]string, 0, len(rm.States))
+	for key := range rm.States {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return rm.States[keys[i]].LastSeen.Before(rm.States[keys[j]].LastSeen)
+	})
+
+	evicted, dropped := 0, 0
+	for _, key := range keys {
+		if estimate <= rm.MaxMemory {
+			break
+		}
+		state := rm.States[key]
+		dropped += len(state.MissedCalls) + len(state.PendingCallIds)
+		delete(rm.States, key)
+		evicted++
+		estimate = rm.memoryEstimate()
+	}
+	rm.evicted += evicted
+
+	if evicted > 0 {
+		log.Printf("[ReconnectionManager] Memory cap of %d bytes reached: dropped the %d oldest states (%d calls); %d calls reduced to IDs",
+			rm.MaxMemory, evicted, dropped, spilled)
+	} else if spilled > 0 {
+		log.Printf("[ReconnectionManager] Memory cap of %d bytes reached: %d buffered calls reduced to IDs", rm.MaxMemory, spilled)
+	}
+}
+
+// spillableCalls returns the buffered calls that can be reduced to IDs,
+// oldest first: calls with a database ID, not on a priority talkgroup (so the
+// buffer cannot trim them) and not the newest call of any state holding them,
+// so every client gets its latest call without a database round trip.
+// Callers hold the mutex.
+func (rm *ReconnectionManager) spillableCalls() []*Call {
+	spillable := map[*Call]bool{}
+	for _, state := range rm.States {
+		for i, call := range state.MissedCalls {
+			if call == nil {
+				continue
+			}
+			ok := call.Id > 0 && !isReconnectionPriority(call) && i < len(state.MissedCalls)-1
+			if prev, seen := spillable[call]; seen {
+				ok = ok && prev
+			}
+			spillable[call] = ok
+		}
+	}
+
+	calls := make([]*Call, 0, len(spillable))
+	for call, ok := range spillable {
+		if ok {
+			calls = append(calls, call)
 		}
 	}
+	sort.Slice(calls, func(i, j int) bool {
+		if !calls[i].Timestamp.Equal(calls[j].Timestamp) {
+			return calls[i].Timestamp.Before(calls[j].Timestamp)
+		}
+		return calls[i].Id < calls[j].Id
+	})
+
+	return calls
+}
+
+// spillMissedCall moves call from the state's buffer to PendingCallIds and
+// reports whether the state held it.
+func spillMissedCall(state *DisconnectedClientState, call *Call) bool {
+	for i, c := range state.MissedCalls {
+		if c != call {
+			continue
+		}
+		copy(state.MissedCalls[i:], state.MissedCalls[i+1:])
+		state.MissedCalls[len(state.MissedCalls)-1] = nil
+		state.MissedCalls = state.MissedCalls[:len(state.MissedCalls)-1]
+		state.PendingCallIds = append(state.PendingCallIds, call.Id)
+		return true
+	}
+	return false
+}
+
+// reconnectionPriorityReserve is the share of each buffer (1/n) kept for calls
+// on priority talkgroups, so a busy channel cannot crowd them out.
+const reconnectionPriorityReserve = 10
+
+func isReconnectionPriority(call *Call) bool {
+	return call != nil && call.Talkgroup != nil && call.Talkgroup.ReconnectionPriority
+}
+
+// bufferMissedCall appends call to a reconnection buffer of maxSize calls.
+// Regular calls are trimmed FIFO and may only fill the slots not reserved for
+// priority calls. Priority calls are never evicted: when the buffer is full they
+// displace the oldest regular call, or exceed maxSize if there is none.
+func bufferMissedCall(buffer []*Call, call *Call, maxSize int) []*Call {
+	priority := 0
+	for _, c := range buffer {
+		if isReconnectionPriority(c) {
+			priority++
+		}
+	}
+
+	if isReconnectionPriority(call) {
+		if len(buffer) >= maxSize {
+			buffer = evictOldestRegularCall(buffer)
+		}
+		return append(buffer, call)
+	}
+
+	regularLimit := maxSize - max(maxSize/reconnectionPriorityReserve, priority)
+	if regularLimit <= 0 {
+		return buffer
+	}
+	for regular := len(buffer) - priority; regular >= regularLimit; regular-- {
+		buffer = evictOldestRegularCall(buffer)
+	}
+	return append(buffer, call)
+}
+
+func evictOldestRegularCall(buffer []*Call) []*Call {
+	for i, c := range buffer {
+		if !isReconnectionPriority(c) {
+			return append(buffer[:i], buffer[i+1:]...)
+		}
+	}
+	return buffer
+}
+
+// orderMissedCalls puts priority calls ahead of regular ones for delivery,
+// keeping each in chronological order.
+func orderMissedCalls(calls []*Call) {
+	sort.SliceStable(calls, func(i, j int) bool {
+		return isReconnectionPriority(calls[i]) && !isReconnectionPriority(calls[j])
+	})
 }
 
 // RestoreClientState restores buffered calls to a reconnecting client
@@ -144,7 +393,7 @@ func (rm *ReconnectionManager) RestoreClientState(client *Client) bool {
 	}
 
 	// Check if still within grace period
-	if time.Since(state.LastSeen) > rm.HoldDuration {
+	if time.Since(state.LastSeen) > state.HoldDuration {
 		delete(rm.States, userKey)
 		rm.mutex.Unlock()
 		log.Printf("[ReconnectionManager] Grace period expired for user %s (PIN: %s)", userKey, client.User.Pin)
@@ -153,7 +402,7 @@ func (rm *ReconnectionManager) RestoreClientState(client *Client) bool {
 
 	// Get buffered calls before unlocking
 	missedCalls := state.MissedCalls
-	missedCount := len(missedCalls)
+	pendingCallIds := state.PendingCallIds
 	disconnectDuration := time.Since(state.LastSeen)
 	
 	// Restore livefeed state
@@ -163,6 +412,17 @@ func (rm *ReconnectionManager) RestoreClientState(client *Client) bool {
 	delete(rm.States, userKey)
 	rm.mutex.Unlock()
 
+	// Calls buffered before a server restart were persisted by ID only.
+	if len(pendingCallIds) > 0 && rm.controller != nil && rm.controller.Calls != nil {
+		restored := rm.controller.Calls.GetCallsBulk(pendingCallIds)
+		sort.Slice(restored, func(i, j int) bool {
+			return restored[i].Timestamp.Before(restored[j].Timestamp)
+		})
+		missedCalls = append(restored, missedCalls...)
+	}
+	orderMissedCalls(missedCalls)
+	missedCount := len(missedCalls)
+
 	if missedCount == 0 {
 		log.Printf("[ReconnectionManager] User %s (PIN: %s) reconnected after %.1fs - no missed calls", 
 			userKey, client.User.Pin, disconnectDuration.Seconds())
@@ -208,14 +468,20 @@ func (rm *ReconnectionManager) StartCleanup() {
 		log.Printf("[ReconnectionManager] Cleanup routine started (grace period: %v, max buffer: %d)", 
 			rm.HoldDuration, rm.MaxBufferSize)
 
-		for range ticker.C {
+		for {
+			select {
+			case <-ticker.C:
+			case <-rm.stop:
+				return
+			}
+
 			rm.mutex.Lock()
 			now := time.Now()
 			expiredCount := 0
 			totalDroppedCalls := 0
 
 			for userKey, state := range rm.States {
-				if now.Sub(state.LastSeen) > rm.HoldDuration {
+				if now.Sub(state.LastSeen) > state.HoldDuration {
 					totalDroppedCalls += len(state.MissedCalls)
 					delete(rm.States, userKey)
 					expiredCount++
@@ -232,6 +498,169 @@ func (rm *ReconnectionManager) StartCleanup() {
 	}()
 }
 
+// Stop ends the cleanup routine and persists the current states, including
+// every connected user (who is about to be disconnected by the shutdown), so
+// they can be restored by Load after the restart.
+func (rm *ReconnectionManager) Stop() {
+	select {
+	case <-rm.stop:
+		return
+	default:
+		close(rm.stop)
+	}
+
+	if !rm.Enabled || rm.controller == nil || rm.controller.Config == nil {
+		return
+	}
+
+	if rm.controller.Clients != nil {
+		connected := []*Client{}
+		rm.controller.Clients.mutex.Lock()
+		for c := range rm.controller.Clients.Map {
+			if c.User != nil && c.Livefeed != nil {
+				connected = append(connected, c)
+			}
+		}
+		rm.controller.Clients.mutex.Unlock()
+
+		for _, c := range connected {
+			rm.SaveDisconnectedState(c)
+		}
+	}
+
+	if err := rm.Persist(rm.controller.Config.GetPath(reconnectionStateFile)); err != nil {
+		log.Printf("[ReconnectionManager] Failed to persist states: %v", err)
+	}
+}
+
+// Persist writes every state still within its grace period to path.
+func (rm *ReconnectionManager) Persist(path string) error {
+	rm.mutex.RLock()
+	list := []persistedClientState{}
+	now := time.Now()
+	for _, state := range rm.States {
+		if now.Sub(state.LastSeen) > state.HoldDuration || state.User == nil {
+			continue
+		}
+		p := persistedClientState{
+			UserId:     state.User.Id,
+			LastSeen:   state.LastSeen.Unix(),
+			Livefeed:   map[uint]map[uint]bool{},
+			CallIds:    append([]uint64{}, state.PendingCallIds...),
+			LastCallId: state.LastCallId,
+		}
+		if state.User.Id == 0 {
+			p.PinHash = rm.pinHash(state.User.Pin)
+		}
+		if state.Livefeed != nil {
+			state.Livefeed.mutex.Lock()
+			for sysId, talkgroups := range state.Livefeed.Matrix {
+				p.Livefeed[sysId] = map[uint]bool{}
+				for tgId, enabled := range talkgroups {
+					p.Livefeed[sysId][tgId] = enabled
+				}
+			}
+			state.Livefeed.mutex.Unlock()
+		}
+		for _, call := range state.MissedCalls {
+			if call != nil && call.Id > 0 {
+				p.CallIds = append(p.CallIds, call.Id)
+			}
+		}
+		list = append(list, p)
+	}
+	rm.mutex.RUnlock()
+
+	b, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	log.Printf("[ReconnectionManager] Persisted %d client states to %s", len(list), path)
+	return nil
+}
+
+// Load restores states written by Persist that are still within the grace
+// period, then removes the file so stale states are never loaded twice.
+func (rm *ReconnectionManager) Load(path string) error {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+
+	var list []persistedClientState
+	if err := json.Unmarshal(b, &list); err != nil {
+		return err
+	}
+
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+
+	now := time.Now()
+	restored := 0
+	for _, p := range list {
+		var user *User
+		if p.UserId != 0 {
+			user = rm.controller.Users.GetUserById(p.UserId)
+		} else if p.PinHash != "" {
+			user = rm.userByPinHash(p.PinHash)
+		} else if p.Pin != "" {
+			user = rm.controller.Users.GetUserByPin(p.Pin)
+		}
+		if user == nil {
+			continue
+		}
+
+		holdDuration, maxBufferSize := rm.limitsFor(user)
+		lastSeen := time.Unix(p.LastSeen, 0)
+		if now.Sub(lastSeen) > holdDuration {
+			continue
+		}
+
+		livefeed := NewLivefeed()
+		for sysId, talkgroups := range p.Livefeed {
+			livefeed.Matrix[sysId] = map[uint]bool{}
+			for tgId, enabled := range talkgroups {
+				livefeed.Matrix[sysId][tgId] = enabled
+			}
+		}
+
+		callIds := p.CallIds
+		if len(callIds) > maxBufferSize {
+			callIds = callIds[len(callIds)-maxBufferSize:]
+		}
+
+		rm.States[rm.getUserKey(user)] = &DisconnectedClientState{
+			User:           user,
+			LastSeen:       lastSeen,
+			MissedCalls:    make([]*Call, 0, maxBufferSize),
+			Livefeed:       livefeed,
+			MaxBufferSize:  maxBufferSize,
+			HoldDuration:   holdDuration,
+			PendingCallIds: callIds,
+			LastCallId:     p.LastCallId,
+		}
+		restored++
+	}
+
+	if restored > 0 {
+		log.Printf("[ReconnectionManager] Restored %d client states from %s", restored, path)
+	}
+	return nil
+}
+
 // GetStats returns current statistics about the reconnection manager
 func (rm *ReconnectionManager) GetStats() map[string]interface{} {
 	rm.mutex.RLock()
@@ -239,7 +668,7 @@ func (rm *ReconnectionManager) GetStats() map[string]interface{} {
 
 	totalBufferedCalls := 0
 	for _, state := range rm.States {
-		totalBufferedCalls += len(state.MissedCalls)
+		totalBufferedCalls += len(state.MissedCalls) + len(state.PendingCallIds)
 	}
 
 	return map[string]interface{}{
@@ -248,7 +677,88 @@ func (rm *ReconnectionManager) GetStats() map[string]interface{} {
 		"totalBufferedCalls": totalBufferedCalls,
 		"gracePeriod":        rm.HoldDuration.String(),
 		"maxBufferSize":      rm.MaxBufferSize,
+		"memoryBytes":        rm.memoryEstimate(),
+		"maxMemoryBytes":     rm.MaxMemory,
+		"evictedStates":      rm.evicted,
+	}
+}
+
+// GetUserStats returns per-user detail for every held state, soonest to
+// expire first. PINs are never included, only whether one is set.
+func (rm *ReconnectionManager) GetUserStats() []map[string]interface{} {
+	rm.mutex.RLock()
+	defer rm.mutex.RUnlock()
+
+	now := time.Now()
+	list := make([]map[string]interface{}, 0, len(rm.States))
+	for _, state := range rm.States {
+		if state.User == nil {
+			continue
+		}
+
+		elapsed := now.Sub(state.LastSeen)
+		remaining := state.HoldDuration - elapsed
+		if remaining < 0 {
+			remaining = 0
+		}
+		buffered := len(state.MissedCalls) + len(state.PendingCallIds)
+
+		list = append(list, map[string]interface{}{
+			"userId":           state.User.Id,
+			"email":            state.User.Email,
+			"hasPin":           state.User.Pin != "",
+			"secondsSinceSeen": int64(elapsed.Seconds()),
+			"secondsRemaining": int64(remaining.Seconds()),
+			"bufferedCalls":    buffered,
+			"bufferFull":       buffered >= state.MaxBufferSize,
+		})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i]["secondsRemaining"].(int64) < list[j]["secondsRemaining"].(int64)
+	})
+
+	return list
+}
+
+// limitsFor returns the grace period and buffer size for a user, applying
+// their user group's overrides over the global defaults.
+func (rm *ReconnectionManager) limitsFor(user *User) (time.Duration, int) {
+	holdDuration, maxBufferSize := rm.HoldDuration, rm.MaxBufferSize
+
+	if user == nil || user.UserGroupId == 0 || rm.controller == nil || rm.controller.UserGroups == nil {
+		return holdDuration, maxBufferSize
+	}
+
+	if group := rm.controller.UserGroups.Get(user.UserGroupId); group != nil {
+		if group.ReconnectionGracePeriod > 0 {
+			holdDuration = time.Duration(group.ReconnectionGracePeriod) * time.Second
+		}
+		if group.ReconnectionMaxBufferSize > 0 {
+			maxBufferSize = int(group.ReconnectionMaxBufferSize)
+		}
 	}
+
+	return holdDuration, maxBufferSize
+}
+
+// pinHash keys a PIN with the server secret, so a persisted state file does
+// not reveal the PINs of the users it holds.
+func (rm *ReconnectionManager) pinHash(pin string) string {
+	secret := ""
+	if rm.controller != nil && rm.controller.Options != nil {
+		secret = rm.controller.Options.secret
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(pin))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// userByPinHash finds the user whose PIN matches a hash made by pinHash.
+func (rm *ReconnectionManager) userByPinHash(hash string) *User {
+	return rm.controller.Users.FindUserByPin(func(pin string) bool {
+		return hmac.Equal([]byte(rm.pinHash(pin)), []byte(hash))
+	})
 }
 
 // getUserKey generates a unique key for a user (prefer ID over PIN)
@@ -0,0 +1,199 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReconnectionSkipsDeliveredCalls(t *testing.T) {
+	system := &System{SystemRef: 1}
+	talkgroup := &Talkgroup{TalkgroupRef: 100}
+
+	client := &Client{User: &User{Id: 7}, Livefeed: NewLivefeed()}
+	client.Livefeed.Matrix[1] = map[uint]bool{100: true}
+
+	// The call in flight at the moment of disconnect has already been
+	// written to the socket when the disconnect is detected.
+	delivered := &Call{Id: 42, System: system, Talkgroup: talkgroup, Timestamp: time.Now()}
+	client.lastCallId.Store(delivered.Id)
+
+	rm := NewReconnectionManager(&Controller{Options: &Options{}}, time.Minute, 10, true)
+	rm.SaveDisconnectedState(client)
+
+	rm.BufferCallForDisconnected(delivered)
+	rm.BufferCallForDisconnected(&Call{Id: 41, System: system, Talkgroup: talkgroup, Timestamp: time.Now().Add(time.Second)})
+	rm.BufferCallForDisconnected(&Call{Id: 50, System: system, Talkgroup: talkgroup, Timestamp: time.Now().Add(-time.Minute)})
+
+	missed := &Call{Id: 43, System: system, Talkgroup: talkgroup, Timestamp: time.Now().Add(time.Second)}
+	rm.BufferCallForDisconnected(missed)
+
+	state := rm.States[rm.getUserKey(client.User)]
+	if state == nil {
+		t.Fatal("state not saved")
+	}
+	if len(state.MissedCalls) != 1 || state.MissedCalls[0] != missed {
+		t.Fatalf("expected only call 43 to be buffered, got %d calls", len(state.MissedCalls))
+	}
+}
+
+func TestReconnectionBufferKeepsPriorityCalls(t *testing.T) {
+	chatter := &Talkgroup{TalkgroupRef: 100}
+	mayday := &Talkgroup{TalkgroupRef: 200, ReconnectionPriority: true}
+
+	var buffer []*Call
+	buffer = bufferMissedCall(buffer, &Call{Id: 1, Talkgroup: mayday}, 10)
+	for id := uint64(2); id <= 30; id++ {
+		buffer = bufferMissedCall(buffer, &Call{Id: id, Talkgroup: chatter}, 10)
+	}
+
+	if len(buffer) != 10 {
+		t.Fatalf("buffer holds %d calls, want 10", len(buffer))
+	}
+	if buffer[0].Id != 1 {
+		t.Fatalf("priority call evicted, oldest buffered call is %d", buffer[0].Id)
+	}
+	if last := buffer[len(buffer)-1].Id; last != 30 {
+		t.Fatalf("newest buffered call is %d, want 30", last)
+	}
+
+	// A full buffer makes room for priority traffic by dropping old chatter.
+	buffer = bufferMissedCall(buffer, &Call{Id: 31, Talkgroup: mayday}, 10)
+	if len(buffer) != 10 || buffer[1].Id == 22 {
+		t.Fatalf("priority call did not displace the oldest regular call")
+	}
+
+	// With only chatter buffered, one slot stays reserved for priority calls.
+	buffer = nil
+	for id := uint64(1); id <= 20; id++ {
+		buffer = bufferMissedCall(buffer, &Call{Id: id, Talkgroup: chatter}, 10)
+	}
+	if len(buffer) != 9 {
+		t.Fatalf("regular calls fill %d slots, want 9", len(buffer))
+	}
+}
+
+func TestOrderMissedCallsPriorityFirst(t *testing.T) {
+	chatter := &Talkgroup{TalkgroupRef: 100}
+	mayday := &Talkgroup{TalkgroupRef: 200, ReconnectionPriority: true}
+
+	calls := []*Call{
+		{Id: 1, Talkgroup: chatter},
+		{Id: 2, Talkgroup: mayday},
+		{Id: 3, Talkgroup: chatter},
+		{Id: 4, Talkgroup: mayday},
+	}
+	orderMissedCalls(calls)
+
+	want := []uint64{2, 4, 1, 3}
+	for i, call := range calls {
+		if call.Id != want[i] {
+			t.Fatalf("delivery order at %d = %d, want %v", i, call.Id, want)
+		}
+	}
+}
+
+func TestReconnectionPendingCallsCountAgainstBuffer(t *testing.T) {
+	system := &System{SystemRef: 1}
+	talkgroup := &Talkgroup{TalkgroupRef: 100}
+
+	client := &Client{User: &User{Id: 7}, Livefeed: NewLivefeed()}
+	client.Livefeed.Matrix[1] = map[uint]bool{100: true}
+
+	rm := NewReconnectionManager(&Controller{Options: &Options{}}, time.Minute, 3, true)
+	rm.SaveDisconnectedState(client)
+	state := rm.States[rm.getUserKey(client.User)]
+	state.PendingCallIds = []uint64{1, 2, 3}
+
+	rm.BufferCallForDisconnected(&Call{Id: 10, System: system, Talkgroup: talkgroup, Timestamp: time.Now().Add(time.Second)})
+	rm.BufferCallForDisconnected(&Call{Id: 11, System: system, Talkgroup: talkgroup, Timestamp: time.Now().Add(time.Second)})
+
+	if n := len(state.PendingCallIds) + len(state.MissedCalls); n != 3 {
+		t.Fatalf("buffered %d calls, want 3", n)
+	}
+	if len(state.PendingCallIds) != 1 || state.PendingCallIds[0] != 3 {
+		t.Fatalf("expected the oldest pending calls to be dropped, got %v", state.PendingCallIds)
+	}
+}
+
+func TestReconnectionPersistHashesPin(t *testing.T) {
+	users := NewUsers()
+	user := &User{Pin: "73915"}
+	controller := &Controller{Options: &Options{secret: "test-secret"}, Users: users}
+	users.pins[user.Pin] = user
+	users.pins["11111"] = &User{Id: 99, Pin: "11111"}
+
+	rm := NewReconnectionManager(controller, time.Minute, 10, true)
+	rm.States[rm.getUserKey(user)] = &DisconnectedClientState{User: user, LastSeen: time.Now(), Livefeed: NewLivefeed(), HoldDuration: time.Minute, PendingCallIds: []uint64{5}}
+
+	path := filepath.Join(t.TempDir(), reconnectionStateFile)
+	if err := rm.Persist(path); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), user.Pin) {
+		t.Fatalf("persisted state contains the PIN: %s", b)
+	}
+
+	// PIN-only users are matched back by hash.
+	loaded := NewReconnectionManager(controller, time.Minute, 10, true)
+	if err := loaded.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if state := loaded.States[loaded.getUserKey(user)]; state == nil || state.User != user {
+		t.Fatal("state for the PIN-only user was not restored")
+	}
+}
+
+func TestReconnectionMemoryCap(t *testing.T) {
+	system := &System{SystemRef: 1}
+	chatter := &Talkgroup{TalkgroupRef: 100}
+	mayday := &Talkgroup{TalkgroupRef: 200, ReconnectionPriority: true}
+	ops := &Talkgroup{TalkgroupRef: 300}
+
+	rm := NewReconnectionManager(&Controller{Options: &Options{}}, time.Minute, 50, true)
+	rm.MaxMemory = 64 << 10
+
+	for id := uint64(1); id <= 3; id++ {
+		client := &Client{User: &User{Id: id}, Livefeed: NewLivefeed()}
+		client.Livefeed.Matrix[1] = map[uint]bool{100: true, 200: true, 300: id == 1}
+		rm.SaveDisconnectedState(client)
+		rm.States[rm.getUserKey(client.User)].LastSeen = time.Now().Add(-time.Duration(10-id) * time.Second)
+	}
+
+	audio := make([]byte, 20<<10)
+	rm.BufferCallForDisconnected(&Call{Id: 1, System: system, Talkgroup: mayday, Timestamp: time.Now(), Audio: audio})
+	for id := uint64(2); id <= 5; id++ {
+		rm.BufferCallForDisconnected(&Call{Id: id, System: system, Talkgroup: chatter, Timestamp: time.Now(), Audio: audio})
+	}
+
+	// Calls shared by every state count once, so only the regular calls are
+	// reduced to IDs; the priority call and the newest call stay in memory.
+	if len(rm.States) != 3 {
+		t.Fatalf("%d states held, want 3", len(rm.States))
+	}
+	for _, state := range rm.States {
+		if len(state.MissedCalls) != 2 || len(state.PendingCallIds) != 3 || state.MissedCalls[0].Id != 1 {
+			t.Fatalf("state holds %d calls and %d IDs, want 2 and 3", len(state.MissedCalls), len(state.PendingCallIds))
+		}
+	}
+
+	// Calls without a database ID cannot be reduced, so the oldest state,
+	// the only one holding them, is dropped.
+	for i := 0; i < 2; i++ {
+		rm.BufferCallForDisconnected(&Call{System: system, Talkgroup: ops, Timestamp: time.Now(), Audio: audio})
+	}
+	if _, ok := rm.States["id:1"]; ok || len(rm.States) != 2 {
+		t.Fatalf("%d states held, want the oldest dropped", len(rm.States))
+	}
+	if stats := rm.GetStats(); stats["evictedStates"].(int) != 1 || stats["memoryBytes"].(int64) > rm.MaxMemory {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const s3RequestTimeout = 60 * time.Second
+
+var errS3NotFound = errors.New("object not found")
+
+// S3Storage stores call audio in a bucket of any S3-compatible service (AWS,
+// MinIO, Backblaze B2, Cloudflare R2, Wasabi...). Requests use path-style
+// URLs, {endpoint}/{bucket}/{key}, signed with AWS Signature Version 4.
+type S3Storage struct {
+	endpoint  *url.URL
+	bucket    string
+	region    string
+	accessKey string
+	secretKey string
+	client    *http.Client
+}
+
+func NewS3Storage(endpoint string, bucket string, region string, accessKey string, secretKey string) (*S3Storage, error) {
+	u, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid s3_endpoint %q", endpoint)
+	}
+	if bucket == "" {
+		return nil, errors.New("s3_bucket is required")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	return &S3Storage{
+		endpoint:  u,
+		bucket:    bucket,
+		region:    region,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		client:    &http.Client{Timeout: s3RequestTimeout},
+	}, nil
+}
+
+func (s3 *S3Storage) Name() string {
+	return "s3"
+}
+
+func (s3 *S3Storage) Put(key string, data []byte, mime string) error {
+	headers := map[string]string{}
+	if mime != "" {
+		headers["Content-Type"] = mime
+	}
+	_, err := s3.do(http.MethodPut, key, data, headers)
+	return err
+}
+
+func (s3 *S3Storage) Get(key string) ([]byte, error) {
+	return s3.do(http.MethodGet, key, nil, nil)
+}
+
+func (s3 *S3Storage) Delete(key string) error {
+	_, err := s3.do(http.MethodDelete, key, nil, nil)
+	if errors.Is(err, errS3NotFound) {
+		return nil
+	}
+	return err
+}
+
+func (s3 *S3Storage) do(method string, key string, body []byte, headers map[string]string) ([]byte, error) {
+	u := *s3.endpoint
+	u.Path = u.Path + "/" + s3.bucket + "/" + key
+	u.RawPath = s3EscapePath(u.Path) // send the path exactly as it is signed
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	s3Sign(req, body, s3.accessKey, s3.secretKey, s3.region, time.Now())
+
+	res, err := s3.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		io.Copy(io.Discard, res.Body)
+		return nil, fmt.Errorf("s3 %s %s: %w", method, key, errS3NotFound)
+	case res.StatusCode < 200 || res.StatusCode > 299:
+		b, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return nil, fmt.Errorf("s3 %s %s: %s: %s", method, key, res.Status, strings.TrimSpace(string(b)))
+	}
+
+	if method != http.MethodGet {
+		io.Copy(io.Discard, res.Body)
+		return nil, nil
+	}
+	return io.ReadAll(res.Body)
+}
+
+// s3Sign adds the AWS Signature Version 4 headers to req. The host, any
+// Range header and every x-amz-* header are signed.
+func s3Sign(req *http.Request, body []byte, accessKey string, secretKey string, region string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+
+	payloadHash := sha256.Sum256(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+
+	signed := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "range" || strings.HasPrefix(lower, "x-amz-") {
+			signed[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(signed))
+	for name := range signed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + signed[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3EscapePath(req.URL.Path),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := day + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := s3Hmac([]byte("AWS4"+secretKey), day)
+	key = s3Hmac(key, region)
+	key = s3Hmac(key, "s3")
+	key = s3Hmac(key, "aws4_request")
+	signature := hex.EncodeToString(s3Hmac(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", accessKey, scope, signedHeaders, signature))
+}
+
+func s3Hmac(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3EscapePath encodes everything but unreserved characters and slashes, as
+// SigV4 requires for S3 object keys.
+func s3EscapePath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if c == '/' || c == '-' || c == '_' || c == '.' || c == '~' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := []string{}
+	for _, k := range keys {
+		values := append([]string{}, query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, strings.ReplaceAll(url.QueryEscape(k), "+", "%20")+"="+strings.ReplaceAll(url.QueryEscape(v), "+", "%20"))
+		}
+	}
+	return strings.Join(parts, "&")
+}
@@ -26,6 +26,10 @@ type Scheduler struct {
 	Ticker     *time.Ticker
 	cancel     chan any
 	started    bool
+
+	lastDeviceTokenPrune time.Time
+	lastLogPrune         time.Time
+	lastDbMaintenance    time.Time
 }
 
 func NewScheduler(controller *Controller) *Scheduler {
@@ -36,29 +40,57 @@ func NewScheduler(controller *Controller) *Scheduler {
 }
 
 func (scheduler *Scheduler) pruneDatabase() error {
-	scheduler.Controller.Logs.LogEvent(LogLevelInfo, "database pruning (audio and logs)")
+	scheduler.Controller.Logs.LogEvent(LogLevelInfo, "database pruning (audio)")
 
 	// Prune calls using hierarchical retention: talkgroup > system > global pruneDays.
 	if err := scheduler.Controller.Calls.Prune(scheduler.Controller.Database, scheduler.Controller.Options.PruneDays); err != nil {
 		return fmt.Errorf("prune calls failed: %v", err)
 	}
 
-	if scheduler.Controller.Options.PruneDays == 0 {
-		return nil
+	return nil
+}
+
+// pruneLogs deletes log entries past their retention: log_prune_days when set,
+// otherwise the pruneDays option.
+func (scheduler *Scheduler) pruneLogs() {
+	days := scheduler.Controller.Config.LogPruneDays
+	if days == 0 {
+		days = scheduler.Controller.Options.PruneDays
+	}
+	if days == 0 {
+		return
 	}
 
-	if err := scheduler.Controller.Logs.Prune(scheduler.Controller.Database, scheduler.Controller.Options.PruneDays); err != nil {
-		return fmt.Errorf("prune logs failed: %v", err)
+	removed, err := scheduler.Controller.Logs.Prune(scheduler.Controller.Database, days)
+	if err != nil {
+		scheduler.Controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("scheduler.pruneLogs: %s", err.Error()))
+		return
 	}
+	scheduler.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("pruned %d log entries older than %d days", removed, days))
+}
 
-	return nil
+// pruneDeviceTokens removes push tokens the app hasn't refreshed within the configured age.
+func (scheduler *Scheduler) pruneDeviceTokens() {
+	maxAge := scheduler.Controller.Config.DeviceTokenMaxAge
+	if maxAge == 0 {
+		return
+	}
+
+	removed, err := scheduler.Controller.DeviceTokens.PruneStale(scheduler.Controller.Database, 24*time.Hour*time.Duration(maxAge))
+	if err != nil {
+		scheduler.Controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("scheduler.pruneDeviceTokens: %s", err.Error()))
+		return
+	}
+	if removed > 0 {
+		scheduler.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("pruned %d device token(s) unused for more than %d days", removed, maxAge))
+	}
 }
 
 func (scheduler *Scheduler) run() {
 	// Run cleanup operations in background goroutines to avoid blocking the scheduler ticker
 	// This ensures the scheduler continues to run on schedule even if cleanup takes a long time
 
-	// Prune database (audio and logs) - runs in background
+	// Prune database (audio) - runs in background
 	go func() {
 		if err := scheduler.pruneDatabase(); err != nil {
 			scheduler.Controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("scheduler.pruneDatabase: %s", err.Error()))
@@ -77,6 +109,24 @@ func (scheduler *Scheduler) run() {
 		scheduler.Controller.CleanupOldSystemAlerts()
 	}()
 
+	// Prune logs on their own interval (daily by default)
+	if time.Since(scheduler.lastLogPrune) >= time.Hour*time.Duration(max(scheduler.Controller.Config.LogPruneInterval, 1)) {
+		scheduler.lastLogPrune = time.Now()
+		go scheduler.pruneLogs()
+	}
+
+	// Prune stale device tokens once a day
+	if time.Since(scheduler.lastDeviceTokenPrune) >= 24*time.Hour {
+		scheduler.lastDeviceTokenPrune = time.Now()
+		go scheduler.pruneDeviceTokens()
+	}
+
+	// Vacuum the pruned tables inside the configured low-traffic window
+	if now := time.Now(); scheduler.dbMaintenanceDue(now) {
+		scheduler.lastDbMaintenance = now
+		go scheduler.maintainDatabase()
+	}
+
 	// Prune authMutexes entries for users that no longer exist
 	go scheduler.Controller.pruneAuthMutexes()
 
@@ -48,6 +48,8 @@ type System struct {
 	// When true, heard unit refs + labels from calls are merged into this system's unit list (independent of AutoPopulate).
 	AutoPopulateUnits bool `json:"autoPopulateUnits"`
 	TranscriptionPrompt string // Custom Whisper/AssemblyAI prompt; overrides the global prompt when non-empty
+	AudioFormat         string // "aac" or "flac"; overrides the global audioFormat when non-empty
+	AudioBitrate        uint   // AAC bitrate in kbps; 0 = default
 	// When true, talkgroups with autoLearnToneSets may observe paging patterns for admin review emails.
 	AutoLearnToneSets              bool     `json:"autoLearnToneSets"`
 	AutoLearnToneSetsTagIds        []uint64 `json:"autoLearnToneSetsTagIds"`
@@ -154,6 +156,20 @@ func (system *System) FromMap(m map[string]any) *System {
 		system.RetentionDays = uint(v)
 	}
 
+	switch v := m["audioFormat"].(type) {
+	case string:
+		if v == AUDIO_FORMAT_AAC || v == AUDIO_FORMAT_FLAC {
+			system.AudioFormat = v
+		}
+	}
+
+	switch v := m["audioBitrate"].(type) {
+	case float64:
+		if v >= aacBitrateMin && v <= aacBitrateMax {
+			system.AudioBitrate = uint(v)
+		}
+	}
+
 	switch v := m["duplicateDetectionEnabled"].(type) {
 	case bool:
 		system.DuplicateDetectionEnabled = v
@@ -304,6 +320,14 @@ func (system *System) MarshalJSON() ([]byte, error) {
 		m["retentionDays"] = system.RetentionDays
 	}
 
+	if system.AudioFormat != "" {
+		m["audioFormat"] = system.AudioFormat
+	}
+
+	if system.AudioBitrate > 0 {
+		m["audioBitrate"] = system.AudioBitrate
+	}
+
 	m["duplicateDetectionEnabled"] = system.DuplicateDetectionEnabled
 
 	// Always include alertsEnabled
@@ -348,6 +372,9 @@ type SystemMap map[string]any
 type Systems struct {
 	List  []*System
 	mutex sync.RWMutex
+	// controller removes the stored audio of calls deleted with a system or
+	// talkgroup; set by NewController.
+	controller *Controller
 }
 
 func NewSystems() *Systems {
@@ -710,7 +737,7 @@ func (systems *Systems) Read(db *Database) error {
 	formatError := errorFormatter("systems", "read")
 
 	// --- Query 1: systems ---
-	query := `SELECT "systemId", "autoPopulate", "blacklists", "delay", "label", "order", "systemRef", "type", "preferredApiKeyId", "noAudioAlertsEnabled", "noAudioThresholdMinutes", "retentionDays", "duplicateDetectionEnabled", "alertsEnabled", "autoPopulateAlertsEnabled", "autoPopulateUnits", "transcriptionPrompt", "autoLearnToneSets", "autoLearnToneSetsTagIds", "autoLearnToneSetsAutoOffDays", "autoLearnToneSetsExpiresAt", "bulkToneDetectionEnabled", "bulkToneDetectionTagIds", "bulkToneDetectionAutoOffDays", "bulkToneDetectionExpiresAt", "autoLearnUnitAliases", "autoLearnUnitAliasesTagIds", "autoLearnUnitAliasesAutoOffDays", "autoLearnUnitAliasesExpiresAt" FROM "systems"`
+	query := `SELECT "systemId", "autoPopulate", "blacklists", "delay", "label", "order", "systemRef", "type", "preferredApiKeyId", "noAudioAlertsEnabled", "noAudioThresholdMinutes", "retentionDays", "duplicateDetectionEnabled", "alertsEnabled", "autoPopulateAlertsEnabled", "autoPopulateUnits", "transcriptionPrompt", "autoLearnToneSets", "autoLearnToneSetsTagIds", "autoLearnToneSetsAutoOffDays", "autoLearnToneSetsExpiresAt", "bulkToneDetectionEnabled", "bulkToneDetectionTagIds", "bulkToneDetectionAutoOffDays", "bulkToneDetectionExpiresAt", "autoLearnUnitAliases", "autoLearnUnitAliasesTagIds", "autoLearnUnitAliasesAutoOffDays", "autoLearnUnitAliasesExpiresAt", "audioFormat", "audioBitrate" FROM "systems"`
 	rows, err := db.Sql.Query(query)
 	if err != nil {
 		return formatError(err, query)
@@ -724,7 +751,7 @@ func (systems *Systems) Read(db *Database) error {
 		var bulkTagIdsJson string
 		var toneLearnTagIdsJson string
 		var unitLearnTagIdsJson string
-		if err = rows.Scan(&system.Id, &system.AutoPopulate, &system.Blacklists, &system.Delay, &system.Label, &system.Order, &system.SystemRef, &system.Kind, &preferredApiKeyUnused, &system.NoAudioAlertsEnabled, &system.NoAudioThresholdMinutes, &system.RetentionDays, &system.DuplicateDetectionEnabled, &system.AlertsEnabled, &system.AutoPopulateAlertsEnabled, &system.AutoPopulateUnits, &system.TranscriptionPrompt, &system.AutoLearnToneSets, &toneLearnTagIdsJson, &system.AutoLearnToneSetsAutoOffDays, &system.AutoLearnToneSetsExpiresAt, &system.BulkToneDetectionEnabled, &bulkTagIdsJson, &system.BulkToneDetectionAutoOffDays, &system.BulkToneDetectionExpiresAt, &system.AutoLearnUnitAliases, &unitLearnTagIdsJson, &system.AutoLearnUnitAliasesAutoOffDays, &system.AutoLearnUnitAliasesExpiresAt); err != nil {
+		if err = rows.Scan(&system.Id, &system.AutoPopulate, &system.Blacklists, &system.Delay, &system.Label, &system.Order, &system.SystemRef, &system.Kind, &preferredApiKeyUnused, &system.NoAudioAlertsEnabled, &system.NoAudioThresholdMinutes, &system.RetentionDays, &system.DuplicateDetectionEnabled, &system.AlertsEnabled, &system.AutoPopulateAlertsEnabled, &system.AutoPopulateUnits, &system.TranscriptionPrompt, &system.AutoLearnToneSets, &toneLearnTagIdsJson, &system.AutoLearnToneSetsAutoOffDays, &system.AutoLearnToneSetsExpiresAt, &system.BulkToneDetectionEnabled, &bulkTagIdsJson, &system.BulkToneDetectionAutoOffDays, &system.BulkToneDetectionExpiresAt, &system.AutoLearnUnitAliases, &unitLearnTagIdsJson, &system.AutoLearnUnitAliasesAutoOffDays, &system.AutoLearnUnitAliasesExpiresAt, &system.AudioFormat, &system.AudioBitrate); err != nil {
 			return formatError(err, query)
 		}
 		system.AutoLearnToneSetsTagIds = parseBulkToneTagIds(toneLearnTagIdsJson)
@@ -779,9 +806,9 @@ func (systems *Systems) Read(db *Database) error {
 	// --- Query 3: all talkgroups (bulk, no per-system loop) ---
 	var tgQuery string
 	if db.Config.DbType == DbTypePostgresql {
-		tgQuery = `SELECT t."talkgroupId", t."systemId", t."delay", t."frequency", t."label", t."name", t."order", t."tagId", t."talkgroupRef", t."type", t."toneDetectionEnabled", t."toneSets", t."preferredApiKeyId", t."excludeFromPreferredSite", t."toneDownstreamEnabled", t."toneDownstreamURL", t."toneDownstreamAPIKey", t."alertCooldownSeconds", t."linkedVoiceTalkgroupRef", t."linkedVoiceWindowSeconds", t."linkedVoiceMinDurationSeconds", t."alertsEnabled", t."transcriptionPrompt", t."autoLearnToneSets", t."alertingTalkgroup", t."autoLearnUnitAliases", t."retentionDays", STRING_AGG(CAST(COALESCE(tg."groupId", 0) AS text), ',') FROM "talkgroups" AS t LEFT JOIN "talkgroupGroups" AS tg ON tg."talkgroupId" = t."talkgroupId" GROUP BY t."talkgroupId", t."systemId", t."preferredApiKeyId", t."excludeFromPreferredSite", t."toneDownstreamEnabled", t."toneDownstreamURL", t."toneDownstreamAPIKey", t."alertCooldownSeconds", t."linkedVoiceTalkgroupRef", t."linkedVoiceWindowSeconds", t."linkedVoiceMinDurationSeconds", t."alertsEnabled", t."transcriptionPrompt", t."autoLearnToneSets", t."alertingTalkgroup", t."autoLearnUnitAliases", t."retentionDays" ORDER BY t."systemId", t."order", t."talkgroupId"`
+		tgQuery = `SELECT t."talkgroupId", t."systemId", t."delay", t."frequency", t."label", t."name", t."order", t."tagId", t."talkgroupRef", t."type", t."toneDetectionEnabled", t."toneSets", t."preferredApiKeyId", t."excludeFromPreferredSite", t."toneDownstreamEnabled", t."toneDownstreamURL", t."toneDownstreamAPIKey", t."alertCooldownSeconds", t."linkedVoiceTalkgroupRef", t."linkedVoiceWindowSeconds", t."linkedVoiceMinDurationSeconds", t."voiceCaptureWindowSeconds", t."linkedVoiceTalkgroupRefs", t."alertsEnabled", t."transcriptionPrompt", t."autoLearnToneSets", t."alertingTalkgroup", t."autoLearnUnitAliases", t."retentionDays", t."allowDebugAudio", t."transcriptionLanguage", t."reconnectionPriority", t."encrypted", STRING_AGG(CAST(COALESCE(tg."groupId", 0) AS text), ',') FROM "talkgroups" AS t LEFT JOIN "talkgroupGroups" AS tg ON tg."talkgroupId" = t."talkgroupId" GROUP BY t."talkgroupId", t."systemId", t."preferredApiKeyId", t."excludeFromPreferredSite", t."toneDownstreamEnabled", t."toneDownstreamURL", t."toneDownstreamAPIKey", t."alertCooldownSeconds", t."linkedVoiceTalkgroupRef", t."linkedVoiceWindowSeconds", t."linkedVoiceMinDurationSeconds", t."voiceCaptureWindowSeconds", t."linkedVoiceTalkgroupRefs", t."alertsEnabled", t."transcriptionPrompt", t."autoLearnToneSets", t."alertingTalkgroup", t."autoLearnUnitAliases", t."retentionDays", t."allowDebugAudio", t."transcriptionLanguage", t."reconnectionPriority", t."encrypted" ORDER BY t."systemId", t."order", t."talkgroupId"`
 	} else {
-		tgQuery = `SELECT t."talkgroupId", t."systemId", t."delay", t."frequency", t."label", t."name", t."order", t."tagId", t."talkgroupRef", t."type", t."toneDetectionEnabled", t."toneSets", t."preferredApiKeyId", t."excludeFromPreferredSite", t."toneDownstreamEnabled", t."toneDownstreamURL", t."toneDownstreamAPIKey", t."alertCooldownSeconds", t."linkedVoiceTalkgroupRef", t."linkedVoiceWindowSeconds", t."linkedVoiceMinDurationSeconds", t."alertsEnabled", t."transcriptionPrompt", t."autoLearnToneSets", t."alertingTalkgroup", t."autoLearnUnitAliases", t."retentionDays", GROUP_CONCAT(COALESCE(tg."groupId", 0)) FROM "talkgroups" AS t LEFT JOIN "talkgroupGroups" AS tg ON tg."talkgroupId" = t."talkgroupId" GROUP BY t."talkgroupId" ORDER BY t."systemId", t."order", t."talkgroupId"`
+		tgQuery = `SELECT t."talkgroupId", t."systemId", t."delay", t."frequency", t."label", t."name", t."order", t."tagId", t."talkgroupRef", t."type", t."toneDetectionEnabled", t."toneSets", t."preferredApiKeyId", t."excludeFromPreferredSite", t."toneDownstreamEnabled", t."toneDownstreamURL", t."toneDownstreamAPIKey", t."alertCooldownSeconds", t."linkedVoiceTalkgroupRef", t."linkedVoiceWindowSeconds", t."linkedVoiceMinDurationSeconds", t."voiceCaptureWindowSeconds", t."linkedVoiceTalkgroupRefs", t."alertsEnabled", t."transcriptionPrompt", t."autoLearnToneSets", t."alertingTalkgroup", t."autoLearnUnitAliases", t."retentionDays", t."allowDebugAudio", t."transcriptionLanguage", t."reconnectionPriority", t."encrypted", GROUP_CONCAT(COALESCE(tg."groupId", 0)) FROM "talkgroups" AS t LEFT JOIN "talkgroupGroups" AS tg ON tg."talkgroupId" = t."talkgroupId" GROUP BY t."talkgroupId" ORDER BY t."systemId", t."order", t."talkgroupId"`
 	}
 
 	tgRows, err := db.Sql.Query(tgQuery)
@@ -795,10 +822,11 @@ func (systems *Systems) Read(db *Database) error {
 		var systemId uint64
 		var toneSetsJson string
 		var groupIds string
+		var linkedVoiceRefs string
 		var preferredApiKeyUnused sql.NullInt64
 		var excludePreferredUnused bool
 
-		if err = tgRows.Scan(&talkgroup.Id, &systemId, &talkgroup.Delay, &talkgroup.Frequency, &talkgroup.Label, &talkgroup.Name, &talkgroup.Order, &talkgroup.TagId, &talkgroup.TalkgroupRef, &talkgroup.Kind, &talkgroup.ToneDetectionEnabled, &toneSetsJson, &preferredApiKeyUnused, &excludePreferredUnused, &talkgroup.ToneDownstreamEnabled, &talkgroup.ToneDownstreamURL, &talkgroup.ToneDownstreamAPIKey, &talkgroup.AlertCooldownSeconds, &talkgroup.LinkedVoiceTalkgroupRef, &talkgroup.LinkedVoiceWindowSeconds, &talkgroup.LinkedVoiceMinDurationSeconds, &talkgroup.AlertsEnabled, &talkgroup.TranscriptionPrompt, &talkgroup.AutoLearnToneSets, &talkgroup.AlertingTalkgroup, &talkgroup.AutoLearnUnitAliases, &talkgroup.RetentionDays, &groupIds); err != nil {
+		if err = tgRows.Scan(&talkgroup.Id, &systemId, &talkgroup.Delay, &talkgroup.Frequency, &talkgroup.Label, &talkgroup.Name, &talkgroup.Order, &talkgroup.TagId, &talkgroup.TalkgroupRef, &talkgroup.Kind, &talkgroup.ToneDetectionEnabled, &toneSetsJson, &preferredApiKeyUnused, &excludePreferredUnused, &talkgroup.ToneDownstreamEnabled, &talkgroup.ToneDownstreamURL, &talkgroup.ToneDownstreamAPIKey, &talkgroup.AlertCooldownSeconds, &talkgroup.LinkedVoiceTalkgroupRef, &talkgroup.LinkedVoiceWindowSeconds, &talkgroup.LinkedVoiceMinDurationSeconds, &talkgroup.VoiceCaptureWindowSeconds, &linkedVoiceRefs, &talkgroup.AlertsEnabled, &talkgroup.TranscriptionPrompt, &talkgroup.AutoLearnToneSets, &talkgroup.AlertingTalkgroup, &talkgroup.AutoLearnUnitAliases, &talkgroup.RetentionDays, &talkgroup.AllowDebugAudio, &talkgroup.TranscriptionLanguage, &talkgroup.ReconnectionPriority, &talkgroup.Encrypted, &groupIds); err != nil {
 			return formatError(err, tgQuery)
 		}
 		if toneSetsJson != "" && toneSetsJson != "[]" {
@@ -811,6 +839,7 @@ func (systems *Systems) Read(db *Database) error {
 				talkgroup.GroupIds = append(talkgroup.GroupIds, uint64(i))
 			}
 		}
+		talkgroup.LinkedVoiceTalkgroupRefs = parseTalkgroupRefList(linkedVoiceRefs)
 		if sys, ok := systemById[systemId]; ok {
 			sys.Talkgroups.mutex.Lock()
 			sys.Talkgroups.List = append(sys.Talkgroups.List, talkgroup)
@@ -867,6 +896,7 @@ func (systems *Systems) Read(db *Database) error {
 
 func (systems *Systems) Write(db *Database) error {
 	var (
+		audioKeys []string
 		err       error
 		query     string
 		res       sql.Result
@@ -927,6 +957,12 @@ func (systems *Systems) Write(db *Database) error {
 		if b, err := json.Marshal(systemIds); err == nil {
 			in := strings.ReplaceAll(strings.ReplaceAll(string(b), "[", "("), "]", ")")
 
+			// Calls are deleted by cascade; their stored audio is not.
+			if audioKeys, err = storedAudioKeys(tx, fmt.Sprintf(`"systemId" IN %s`, in)); err != nil {
+				tx.Rollback()
+				return formatError(err, "")
+			}
+
 			query = fmt.Sprintf(`DELETE FROM "systems" WHERE "systemId" IN %s`, in)
 			if res, err = tx.Exec(query); err != nil {
 				tx.Rollback()
@@ -986,10 +1022,10 @@ func (systems *Systems) Write(db *Database) error {
 		if count == 0 {
 			if system.Id > 0 {
 				// Preserve the explicit ID when inserting
-				query = fmt.Sprintf(`INSERT INTO "systems" ("systemId", "autoPopulate", "blacklists", "delay", "label", "order", "systemRef", "type", "preferredApiKeyId", "noAudioAlertsEnabled", "noAudioThresholdMinutes", "retentionDays", "duplicateDetectionEnabled", "alertsEnabled", "autoPopulateAlertsEnabled", "autoPopulateUnits", "transcriptionPrompt", "autoLearnToneSets", "autoLearnToneSetsTagIds", "autoLearnToneSetsAutoOffDays", "autoLearnToneSetsExpiresAt", "bulkToneDetectionEnabled", "bulkToneDetectionTagIds", "bulkToneDetectionAutoOffDays", "bulkToneDetectionExpiresAt", "autoLearnUnitAliases", "autoLearnUnitAliasesTagIds", "autoLearnUnitAliasesAutoOffDays", "autoLearnUnitAliasesExpiresAt") VALUES (%d, %t, '%s', %d, '%s', %d, %d, '%s', %s, %t, %d, %d, %t, %t, %t, %t, '%s', %t, '%s', %d, %d, %t, '%s', %d, %d, %t, '%s', %d, %d)`, system.Id, system.AutoPopulate, system.Blacklists, system.Delay, escapeQuotes(system.Label), system.Order, system.SystemRef, system.Kind, preferredApiKeyIdSQL, system.NoAudioAlertsEnabled, system.NoAudioThresholdMinutes, system.RetentionDays, system.DuplicateDetectionEnabled, system.AlertsEnabled, system.AutoPopulateAlertsEnabled, system.AutoPopulateUnits, escapeQuotes(system.TranscriptionPrompt), system.AutoLearnToneSets, escapeQuotes(serializeBulkToneTagIds(system.AutoLearnToneSetsTagIds)), system.AutoLearnToneSetsAutoOffDays, system.AutoLearnToneSetsExpiresAt, system.BulkToneDetectionEnabled, escapeQuotes(serializeBulkToneTagIds(system.BulkToneDetectionTagIds)), system.BulkToneDetectionAutoOffDays, system.BulkToneDetectionExpiresAt, system.AutoLearnUnitAliases, escapeQuotes(serializeBulkToneTagIds(system.AutoLearnUnitAliasesTagIds)), system.AutoLearnUnitAliasesAutoOffDays, system.AutoLearnUnitAliasesExpiresAt)
+				query = fmt.Sprintf(`INSERT INTO "systems" ("systemId", "autoPopulate", "blacklists", "delay", "label", "order", "systemRef", "type", "preferredApiKeyId", "noAudioAlertsEnabled", "noAudioThresholdMinutes", "retentionDays", "duplicateDetectionEnabled", "alertsEnabled", "autoPopulateAlertsEnabled", "autoPopulateUnits", "transcriptionPrompt", "autoLearnToneSets", "autoLearnToneSetsTagIds", "autoLearnToneSetsAutoOffDays", "autoLearnToneSetsExpiresAt", "bulkToneDetectionEnabled", "bulkToneDetectionTagIds", "bulkToneDetectionAutoOffDays", "bulkToneDetectionExpiresAt", "autoLearnUnitAliases", "autoLearnUnitAliasesTagIds", "autoLearnUnitAliasesAutoOffDays", "autoLearnUnitAliasesExpiresAt", "audioFormat", "audioBitrate") VALUES (%d, %t, '%s', %d, '%s', %d, %d, '%s', %s, %t, %d, %d, %t, %t, %t, %t, '%s', %t, '%s', %d, %d, %t, '%s', %d, %d, %t, '%s', %d, %d, '%s', %d)`, system.Id, system.AutoPopulate, system.Blacklists, system.Delay, escapeQuotes(system.Label), system.Order, system.SystemRef, system.Kind, preferredApiKeyIdSQL, system.NoAudioAlertsEnabled, system.NoAudioThresholdMinutes, system.RetentionDays, system.DuplicateDetectionEnabled, system.AlertsEnabled, system.AutoPopulateAlertsEnabled, system.AutoPopulateUnits, escapeQuotes(system.TranscriptionPrompt), system.AutoLearnToneSets, escapeQuotes(serializeBulkToneTagIds(system.AutoLearnToneSetsTagIds)), system.AutoLearnToneSetsAutoOffDays, system.AutoLearnToneSetsExpiresAt, system.BulkToneDetectionEnabled, escapeQuotes(serializeBulkToneTagIds(system.BulkToneDetectionTagIds)), system.BulkToneDetectionAutoOffDays, system.BulkToneDetectionExpiresAt, system.AutoLearnUnitAliases, escapeQuotes(serializeBulkToneTagIds(system.AutoLearnUnitAliasesTagIds)), system.AutoLearnUnitAliasesAutoOffDays, system.AutoLearnUnitAliasesExpiresAt, escapeQuotes(system.AudioFormat), system.AudioBitrate)
 			} else {
 				// Let database assign auto-increment ID
-				query = fmt.Sprintf(`INSERT INTO "systems" ("autoPopulate", "blacklists", "delay", "label", "order", "systemRef", "type", "preferredApiKeyId", "noAudioAlertsEnabled", "noAudioThresholdMinutes", "retentionDays", "duplicateDetectionEnabled", "alertsEnabled", "autoPopulateAlertsEnabled", "autoPopulateUnits", "transcriptionPrompt", "autoLearnToneSets", "autoLearnToneSetsTagIds", "autoLearnToneSetsAutoOffDays", "autoLearnToneSetsExpiresAt", "bulkToneDetectionEnabled", "bulkToneDetectionTagIds", "bulkToneDetectionAutoOffDays", "bulkToneDetectionExpiresAt", "autoLearnUnitAliases", "autoLearnUnitAliasesTagIds", "autoLearnUnitAliasesAutoOffDays", "autoLearnUnitAliasesExpiresAt") VALUES (%t, '%s', %d, '%s', %d, %d, '%s', %s, %t, %d, %d, %t, %t, %t, %t, '%s', %t, '%s', %d, %d, %t, '%s', %d, %d, %t, '%s', %d, %d)`, system.AutoPopulate, system.Blacklists, system.Delay, escapeQuotes(system.Label), system.Order, system.SystemRef, system.Kind, preferredApiKeyIdSQL, system.NoAudioAlertsEnabled, system.NoAudioThresholdMinutes, system.RetentionDays, system.DuplicateDetectionEnabled, system.AlertsEnabled, system.AutoPopulateAlertsEnabled, system.AutoPopulateUnits, escapeQuotes(system.TranscriptionPrompt), system.AutoLearnToneSets, escapeQuotes(serializeBulkToneTagIds(system.AutoLearnToneSetsTagIds)), system.AutoLearnToneSetsAutoOffDays, system.AutoLearnToneSetsExpiresAt, system.BulkToneDetectionEnabled, escapeQuotes(serializeBulkToneTagIds(system.BulkToneDetectionTagIds)), system.BulkToneDetectionAutoOffDays, system.BulkToneDetectionExpiresAt, system.AutoLearnUnitAliases, escapeQuotes(serializeBulkToneTagIds(system.AutoLearnUnitAliasesTagIds)), system.AutoLearnUnitAliasesAutoOffDays, system.AutoLearnUnitAliasesExpiresAt)
+				query = fmt.Sprintf(`INSERT INTO "systems" ("autoPopulate", "blacklists", "delay", "label", "order", "systemRef", "type", "preferredApiKeyId", "noAudioAlertsEnabled", "noAudioThresholdMinutes", "retentionDays", "duplicateDetectionEnabled", "alertsEnabled", "autoPopulateAlertsEnabled", "autoPopulateUnits", "transcriptionPrompt", "autoLearnToneSets", "autoLearnToneSetsTagIds", "autoLearnToneSetsAutoOffDays", "autoLearnToneSetsExpiresAt", "bulkToneDetectionEnabled", "bulkToneDetectionTagIds", "bulkToneDetectionAutoOffDays", "bulkToneDetectionExpiresAt", "autoLearnUnitAliases", "autoLearnUnitAliasesTagIds", "autoLearnUnitAliasesAutoOffDays", "autoLearnUnitAliasesExpiresAt", "audioFormat", "audioBitrate") VALUES (%t, '%s', %d, '%s', %d, %d, '%s', %s, %t, %d, %d, %t, %t, %t, %t, '%s', %t, '%s', %d, %d, %t, '%s', %d, %d, %t, '%s', %d, %d, '%s', %d)`, system.AutoPopulate, system.Blacklists, system.Delay, escapeQuotes(system.Label), system.Order, system.SystemRef, system.Kind, preferredApiKeyIdSQL, system.NoAudioAlertsEnabled, system.NoAudioThresholdMinutes, system.RetentionDays, system.DuplicateDetectionEnabled, system.AlertsEnabled, system.AutoPopulateAlertsEnabled, system.AutoPopulateUnits, escapeQuotes(system.TranscriptionPrompt), system.AutoLearnToneSets, escapeQuotes(serializeBulkToneTagIds(system.AutoLearnToneSetsTagIds)), system.AutoLearnToneSetsAutoOffDays, system.AutoLearnToneSetsExpiresAt, system.BulkToneDetectionEnabled, escapeQuotes(serializeBulkToneTagIds(system.BulkToneDetectionTagIds)), system.BulkToneDetectionAutoOffDays, system.BulkToneDetectionExpiresAt, system.AutoLearnUnitAliases, escapeQuotes(serializeBulkToneTagIds(system.AutoLearnUnitAliasesTagIds)), system.AutoLearnUnitAliasesAutoOffDays, system.AutoLearnUnitAliasesExpiresAt, escapeQuotes(system.AudioFormat), system.AudioBitrate)
 			}
 
 			if db.Config.DbType == DbTypePostgresql {
@@ -1021,7 +1057,7 @@ func (systems *Systems) Write(db *Database) error {
 			}
 
 		} else {
-			query = fmt.Sprintf(`UPDATE "systems" SET "autoPopulate" = %t, "blacklists" = '%s', "delay" = %d, "label" = '%s', "order" = %d, "systemRef" = %d, "type" = '%s', "preferredApiKeyId" = %s, "noAudioAlertsEnabled" = %t, "noAudioThresholdMinutes" = %d, "retentionDays" = %d, "duplicateDetectionEnabled" = %t, "alertsEnabled" = %t, "autoPopulateAlertsEnabled" = %t, "autoPopulateUnits" = %t, "transcriptionPrompt" = '%s', "autoLearnToneSets" = %t, "autoLearnToneSetsTagIds" = '%s', "autoLearnToneSetsAutoOffDays" = %d, "autoLearnToneSetsExpiresAt" = %d, "bulkToneDetectionEnabled" = %t, "bulkToneDetectionTagIds" = '%s', "bulkToneDetectionAutoOffDays" = %d, "bulkToneDetectionExpiresAt" = %d, "autoLearnUnitAliases" = %t, "autoLearnUnitAliasesTagIds" = '%s', "autoLearnUnitAliasesAutoOffDays" = %d, "autoLearnUnitAliasesExpiresAt" = %d WHERE "systemId" = %d`, system.AutoPopulate, system.Blacklists, system.Delay, escapeQuotes(system.Label), system.Order, system.SystemRef, system.Kind, preferredApiKeyIdSQL, system.NoAudioAlertsEnabled, system.NoAudioThresholdMinutes, system.RetentionDays, system.DuplicateDetectionEnabled, system.AlertsEnabled, system.AutoPopulateAlertsEnabled, system.AutoPopulateUnits, escapeQuotes(system.TranscriptionPrompt), system.AutoLearnToneSets, escapeQuotes(serializeBulkToneTagIds(system.AutoLearnToneSetsTagIds)), system.AutoLearnToneSetsAutoOffDays, system.AutoLearnToneSetsExpiresAt, system.BulkToneDetectionEnabled, escapeQuotes(serializeBulkToneTagIds(system.BulkToneDetectionTagIds)), system.BulkToneDetectionAutoOffDays, system.BulkToneDetectionExpiresAt, system.AutoLearnUnitAliases, escapeQuotes(serializeBulkToneTagIds(system.AutoLearnUnitAliasesTagIds)), system.AutoLearnUnitAliasesAutoOffDays, system.AutoLearnUnitAliasesExpiresAt, system.Id)
+			query = fmt.Sprintf(`UPDATE "systems" SET "autoPopulate" = %t, "blacklists" = '%s', "delay" = %d, "label" = '%s', "order" = %d, "systemRef" = %d, "type" = '%s', "preferredApiKeyId" = %s, "noAudioAlertsEnabled" = %t, "noAudioThresholdMinutes" = %d, "retentionDays" = %d, "duplicateDetectionEnabled" = %t, "alertsEnabled" = %t, "autoPopulateAlertsEnabled" = %t, "autoPopulateUnits" = %t, "transcriptionPrompt" = '%s', "autoLearnToneSets" = %t, "autoLearnToneSetsTagIds" = '%s', "autoLearnToneSetsAutoOffDays" = %d, "autoLearnToneSetsExpiresAt" = %d, "bulkToneDetectionEnabled" = %t, "bulkToneDetectionTagIds" = '%s', "bulkToneDetectionAutoOffDays" = %d, "bulkToneDetectionExpiresAt" = %d, "autoLearnUnitAliases" = %t, "autoLearnUnitAliasesTagIds" = '%s', "autoLearnUnitAliasesAutoOffDays" = %d, "autoLearnUnitAliasesExpiresAt" = %d, "audioFormat" = '%s', "audioBitrate" = %d WHERE "systemId" = %d`, system.AutoPopulate, system.Blacklists, system.Delay, escapeQuotes(system.Label), system.Order, system.SystemRef, system.Kind, preferredApiKeyIdSQL, system.NoAudioAlertsEnabled, system.NoAudioThresholdMinutes, system.RetentionDays, system.DuplicateDetectionEnabled, system.AlertsEnabled, system.AutoPopulateAlertsEnabled, system.AutoPopulateUnits, escapeQuotes(system.TranscriptionPrompt), system.AutoLearnToneSets, escapeQuotes(serializeBulkToneTagIds(system.AutoLearnToneSetsTagIds)), system.AutoLearnToneSetsAutoOffDays, system.AutoLearnToneSetsExpiresAt, system.BulkToneDetectionEnabled, escapeQuotes(serializeBulkToneTagIds(system.BulkToneDetectionTagIds)), system.BulkToneDetectionAutoOffDays, system.BulkToneDetectionExpiresAt, system.AutoLearnUnitAliases, escapeQuotes(serializeBulkToneTagIds(system.AutoLearnUnitAliasesTagIds)), system.AutoLearnUnitAliasesAutoOffDays, system.AutoLearnUnitAliasesExpiresAt, escapeQuotes(system.AudioFormat), system.AudioBitrate, system.Id)
 			if _, err = tx.Exec(query); err != nil {
 				break
 			}
@@ -1037,9 +1073,11 @@ func (systems *Systems) Write(db *Database) error {
 			break
 		}
 
-		if err = system.Talkgroups.WriteTx(tx, system.Id, db.Config.DbType); err != nil {
+		var keys []string
+		if keys, err = system.Talkgroups.WriteTx(tx, system.Id, db.Config.DbType); err != nil {
 			break
 		}
+		audioKeys = append(audioKeys, keys...)
 
 		if err = system.Units.WriteTx(tx, system.Id); err != nil {
 			break
@@ -1057,6 +1095,10 @@ func (systems *Systems) Write(db *Database) error {
 		return formatError(err, "")
 	}
 
+	if systems.controller != nil {
+		go systems.controller.deleteStoredAudio(audioKeys)
+	}
+
 	// Idempotent cleanup: delete user alert preferences for any talkgroup or system
 	// that now has alertsEnabled = false. Runs on every config save but only touches
 	// rows that are actually disabled, so it's safe and self-healing.
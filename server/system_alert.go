@@ -166,15 +166,22 @@ func (controller *Controller) SendSystemAlertNotification(title, message, alertT
 		ids   []string
 		sound string
 	}
-	platformBatches := make(map[string]*platformBatch) // key: "ios" or "android"
+	platformBatches := make(map[string]*platformBatch) // key: "ios" or "android", "+quiet" for silent batches
+	now := time.Now()
 
 	totalDevices := 0
+	var webDevices []*DeviceToken
 	for _, userId := range targetUserIds {
 		tokens := controller.DeviceTokens.GetByUser(userId)
 		for _, device := range tokens {
 			if isLegacyOneSignalToken(device) {
 				continue // Skip; will be cleaned up at next push attempt
 			}
+			if device.PushType == DeviceTokenPushTypeWebPush {
+				webDevices = append(webDevices, device)
+				totalDevices++
+				continue
+			}
 			token := device.FCMToken
 			if token == "" {
 				continue
@@ -189,6 +196,14 @@ func (controller *Controller) SendSystemAlertNotification(title, message, alertT
 			if sound == "" {
 				sound = defaultSound
 			}
+			// Devices in their quiet hours get the alert without sound, and no VoIP ring.
+			if device.InQuietHours(now) {
+				if device.PushType == "voip" {
+					continue
+				}
+				platform += "+quiet"
+				sound = ""
+			}
 
 			if _, ok := platformBatches[platform]; !ok {
 				platformBatches[platform] = &platformBatch{sound: sound}
@@ -211,6 +226,7 @@ func (controller *Controller) SendSystemAlertNotification(title, message, alertT
 		}
 
 		finalSound := batch.sound
+		platform = strings.TrimSuffix(platform, "+quiet")
 		if platform == "ios" {
 			// iOS requires sound name without file extension
 			finalSound = strings.TrimSuffix(finalSound, ".wav")
@@ -231,6 +247,10 @@ func (controller *Controller) SendSystemAlertNotification(title, message, alertT
 		batchIndex++
 	}
 
+	if len(webDevices) > 0 {
+		go controller.sendWebPushBatch(webDevices, notificationTitle, message, nil)
+	}
+
 	controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("[%s] system alert notification sent to %d device(s) across %d platform(s) (%s)", alertType, totalDevices, batchIndex, targetDescription))
 }
 
@@ -318,6 +338,8 @@ func getProviderDisplayName(provider string) string {
 	switch provider {
 	case "whisper-api":
 		return "Whisper API Server"
+	case "whisper-cpp":
+		return "whisper.cpp"
 	case "azure":
 		return "Azure Speech Services"
 	case "google":
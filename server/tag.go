@@ -19,11 +19,43 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"regexp"
 	"sort"
 	"strings"
 	"sync"
 )
 
+// tagColorPalette is used to give tags without an explicit color a stable,
+// distinct color derived from their label.
+var tagColorPalette = []string{
+	"#E53935", "#FB8C00", "#FDD835", "#43A047",
+	"#00ACC1", "#1E88E5", "#3949AB", "#8E24AA",
+	"#D81B60", "#6D4C41", "#00897B", "#7CB342",
+}
+
+var tagColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// normalizeTagColor accepts #RGB or #RRGGBB and returns the upper-case
+// #RRGGBB form.
+func normalizeTagColor(color string) (string, bool) {
+	color = strings.TrimSpace(color)
+	if !tagColorPattern.MatchString(color) {
+		return "", false
+	}
+	if len(color) == 4 {
+		color = string([]byte{'#', color[1], color[1], color[2], color[2], color[3], color[3]})
+	}
+	return strings.ToUpper(color), true
+}
+
+// defaultTagColor picks a palette color from the hash of the tag label.
+func defaultTagColor(label string) string {
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(strings.TrimSpace(label))))
+	return tagColorPalette[h.Sum32()%uint32(len(tagColorPalette))]
+}
+
 type Tag struct {
 	Id    uint64
 	Label string
@@ -55,7 +87,15 @@ func (tag *Tag) FromMap(m map[string]any) *Tag {
 
 	switch v := m["color"].(type) {
 	case string:
-		tag.Color = v
+		// Invalid colors are kept as-is so the admin save path can reject them.
+		if color, ok := normalizeTagColor(v); ok {
+			tag.Color = color
+		} else {
+			tag.Color = strings.TrimSpace(v)
+		}
+	case nil:
+		// No color given at all; an empty string is the "None" choice.
+		tag.Color = defaultTagColor(tag.Label)
 	}
 
 	return tag
@@ -107,6 +147,42 @@ func (tags *Tags) FromMap(f []any) *Tags {
 	return tags
 }
 
+// ValidateColors normalizes every tag color to #RRGGBB.  An empty color is
+// kept, as it is the "None" choice.  It returns an error naming the first tag
+// whose color is not a valid #RGB/#RRGGBB value.
+func (tags *Tags) ValidateColors() error {
+	tags.mutex.Lock()
+	defer tags.mutex.Unlock()
+
+	for _, tag := range tags.List {
+		if tag.Color == "" {
+			continue
+		}
+		color, ok := normalizeTagColor(tag.Color)
+		if !ok {
+			return fmt.Errorf("invalid color %q for tag %q (expected #RGB or #RRGGBB)", tag.Color, tag.Label)
+		}
+		tag.Color = color
+	}
+	return nil
+}
+
+// normalizeColors is the lenient form of ValidateColors used when tags are
+// read and written: legacy or free-form colors (e.g. "red") are replaced with
+// the label's palette color instead of failing the whole list.
+func (tags *Tags) normalizeColors() {
+	for _, tag := range tags.List {
+		if tag.Color == "" {
+			continue
+		}
+		if color, ok := normalizeTagColor(tag.Color); ok {
+			tag.Color = color
+		} else {
+			tag.Color = defaultTagColor(tag.Label)
+		}
+	}
+}
+
 func (tags *Tags) GetTagById(id uint64) (tag *Tag, ok bool) {
 	tags.mutex.RLock()
 	defer tags.mutex.RUnlock()
@@ -265,6 +341,8 @@ func (tags *Tags) Read(db *Database) error {
 		return formatError(err, "")
 	}
 
+	tags.normalizeColors()
+
 	sort.Slice(tags.List, func(i int, j int) bool {
 		return tags.List[i].Order < tags.List[j].Order
 	})
@@ -287,6 +365,8 @@ func (tags *Tags) Write(db *Database) error {
 
 	formatError := errorFormatter("tags", "write")
 
+	tags.normalizeColors()
+
 	if tx, err = db.Sql.Begin(); err != nil {
 		return formatError(err, "")
 	}
@@ -407,4 +487,260 @@ func (tags *Tags) Write(db *Database) error {
 	return nil
 }
 
+// Reorder persists a new tag ordering.  ids must contain every existing tag
+// id exactly once; tag i in the slice is given order i+1.  All updates run in
+// a single transaction and the in-memory list is re-sorted afterwards.
+func (tags *Tags) Reorder(db *Database, ids []uint64) error {
+	var (
+		err   error
+		query string
+		rows  *sql.Rows
+		tx    *sql.Tx
+	)
+
+	tags.mutex.Lock()
+	defer tags.mutex.Unlock()
+
+	formatError := errorFormatter("tags", "reorder")
+
+	seen := map[uint64]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			return formatError(fmt.Errorf("duplicate tag id %d", id), "")
+		}
+		seen[id] = true
+	}
+
+	if tx, err = db.Sql.Begin(); err != nil {
+		return formatError(err, "")
+	}
+
+	query = `SELECT "tagId" FROM "tags"`
+	if rows, err = tx.Query(query); err != nil {
+		tx.Rollback()
+		return formatError(err, query)
+	}
+
+	existing := 0
+	for rows.Next() {
+		var tagId uint64
+		if err = rows.Scan(&tagId); err != nil {
+			break
+		}
+		if !seen[tagId] {
+			err = fmt.Errorf("tag id %d missing from new order", tagId)
+			break
+		}
+		existing++
+	}
+
+	rows.Close()
+
+	if err == nil && existing != len(ids) {
+		err = fmt.Errorf("new order has %d tag ids but %d tags exist", len(ids), existing)
+	}
+
+	if err != nil {
+		tx.Rollback()
+		return formatError(err, "")
+	}
+
+	for i, id := range ids {
+		query = fmt.Sprintf(`UPDATE "tags" SET "order" = %d WHERE "tagId" = %d`, i+1, id)
+		if _, err = tx.Exec(query); err != nil {
+			tx.Rollback()
+			return formatError(err, query)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		tx.Rollback()
+		return formatError(err, "")
+	}
+
+	for i, id := range ids {
+		for _, tag := range tags.List {
+			if tag.Id == id {
+				tag.Order = uint(i + 1)
+				break
+			}
+		}
+	}
+
+	sort.Slice(tags.List, func(i int, j int) bool {
+		return tags.List[i].Order < tags.List[j].Order
+	})
+
+	return nil
+}
+
+// Merge repoints every talkgroup and per-system tag list referencing one of
+// sourceIds to targetId and then deletes the source tags, all in one
+// transaction.  It returns the number
+// of talkgroups repointed.  Callers must reload systems afterwards so the
+// in-memory talkgroups pick up the new tag.
+func (tags *Tags) Merge(db *Database, sourceIds []uint64, targetId uint64) (int64, error) {
+	var (
+		count    int64
+		err      error
+		query    string
+		res      sql.Result
+		targetOk bool
+		tx       *sql.Tx
+	)
+
+	tags.mutex.Lock()
+	defer tags.mutex.Unlock()
+
+	formatError := errorFormatter("tags", "merge")
+
+	if len(sourceIds) == 0 {
+		return 0, formatError(fmt.Errorf("no source tags given"), "")
+	}
+
+	for _, tag := range tags.List {
+		if tag.Id == targetId {
+			targetOk = true
+			break
+		}
+	}
+	if !targetOk {
+		return 0, formatError(fmt.Errorf("target tag %d does not exist", targetId), "")
+	}
+
+	for _, id := range sourceIds {
+		if id == targetId {
+			return 0, formatError(fmt.Errorf("cannot merge tag %d into itself", id), "")
+		}
+		found := false
+		for _, tag := range tags.List {
+			if tag.Id == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, formatError(fmt.Errorf("source tag %d does not exist", id), "")
+		}
+	}
+
+	b, _ := json.Marshal(sourceIds)
+	in := strings.ReplaceAll(strings.ReplaceAll(string(b), "[", "("), "]", ")")
+
+	if tx, err = db.Sql.Begin(); err != nil {
+		return 0, formatError(err, "")
+	}
+
+	// Repoint first: talkgroups cascade-delete with their tag.
+	query = fmt.Sprintf(`UPDATE "talkgroups" SET "tagId" = %d WHERE "tagId" IN %s`, targetId, in)
+	if res, err = tx.Exec(query); err != nil {
+		tx.Rollback()
+		return 0, formatError(err, query)
+	}
+	count, _ = res.RowsAffected()
+
+	if err = mergeSystemTagIds(tx, sourceIds, targetId); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	query = fmt.Sprintf(`DELETE FROM "tags" WHERE "tagId" IN %s`, in)
+	if _, err = tx.Exec(query); err != nil {
+		tx.Rollback()
+		return 0, formatError(err, query)
+	}
+
+	if err = tx.Commit(); err != nil {
+		tx.Rollback()
+		return 0, formatError(err, "")
+	}
+
+	list := []*Tag{}
+	for _, tag := range tags.List {
+		remove := false
+		for _, id := range sourceIds {
+			if tag.Id == id {
+				remove = true
+				break
+			}
+		}
+		if !remove {
+			list = append(list, tag)
+		}
+	}
+	tags.List = list
+
+	return count, nil
+}
+
+// mergeSystemTagIds rewrites the per-system tag ID lists (bulk tone detection
+// and the auto-learn rollouts) so they follow a tag merge.
+func mergeSystemTagIds(tx *sql.Tx, sourceIds []uint64, targetId uint64) error {
+	type systemTagIds struct {
+		id      uint64
+		columns [3]string
+	}
+
+	formatError := errorFormatter("tags", "merge")
+
+	columns := [3]string{"bulkToneDetectionTagIds", "autoLearnToneSetsTagIds", "autoLearnUnitAliasesTagIds"}
+
+	query := `SELECT "systemId", "bulkToneDetectionTagIds", "autoLearnToneSetsTagIds", "autoLearnUnitAliasesTagIds" FROM "systems"`
+	rows, err := tx.Query(query)
+	if err != nil {
+		return formatError(err, query)
+	}
+
+	systems := []systemTagIds{}
+	for rows.Next() {
+		var s systemTagIds
+		if err = rows.Scan(&s.id, &s.columns[0], &s.columns[1], &s.columns[2]); err != nil {
+			break
+		}
+		systems = append(systems, s)
+	}
+	rows.Close()
+	if err != nil {
+		return formatError(err, "")
+	}
+
+	for _, s := range systems {
+		for i, column := range columns {
+			ids, changed := mergeTagIdList(parseBulkToneTagIds(s.columns[i]), sourceIds, targetId)
+			if !changed {
+				continue
+			}
+			query = fmt.Sprintf(`UPDATE "systems" SET "%s" = '%s' WHERE "systemId" = %d`, column, escapeQuotes(serializeBulkToneTagIds(ids)), s.id)
+			if _, err = tx.Exec(query); err != nil {
+				return formatError(err, query)
+			}
+		}
+	}
+
+	return nil
+}
+
+// mergeTagIdList replaces any of sourceIds in ids with targetId, keeping each
+// ID once.  It reports whether the list changed.
+func mergeTagIdList(ids []uint64, sourceIds []uint64, targetId uint64) ([]uint64, bool) {
+	changed := false
+	seen := map[uint64]bool{}
+	merged := []uint64{}
+	for _, id := range ids {
+		for _, sourceId := range sourceIds {
+			if id == sourceId {
+				id = targetId
+				changed = true
+				break
+			}
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		merged = append(merged, id)
+	}
+	return merged, changed
+}
+
 type TagsMap map[string]map[uint][]uint
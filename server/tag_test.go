@@ -0,0 +1,73 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import "testing"
+
+func TestNormalizeTagColor(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"#abc", "#AABBCC", true},
+		{"#1e88e5", "#1E88E5", true},
+		{" #FFF ", "#FFFFFF", true},
+		{"", "", false},
+		{"red", "", false},
+		{"#12345", "", false},
+		{"#GGGGGG", "", false},
+	}
+	for _, tc := range cases {
+		got, ok := normalizeTagColor(tc.in)
+		if ok != tc.ok || got != tc.want {
+			t.Fatalf("in=%q got=(%q,%v) want=(%q,%v)", tc.in, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestValidateColors(t *testing.T) {
+	tags := NewTags()
+	tags.List = []*Tag{{Label: "Fire"}, {Label: "EMS", Color: "#0f0"}}
+	if err := tags.ValidateColors(); err != nil {
+		t.Fatal(err)
+	}
+	if tags.List[0].Color != "" {
+		t.Fatalf("empty color (None) changed to %q", tags.List[0].Color)
+	}
+	if tags.List[1].Color != "#00FF00" {
+		t.Fatalf("got %q", tags.List[1].Color)
+	}
+
+	tags.List = []*Tag{{Label: "Police", Color: "blue"}}
+	if err := tags.ValidateColors(); err == nil {
+		t.Fatal("invalid color must be rejected")
+	}
+}
+
+func TestTagColorPaletteFallback(t *testing.T) {
+	if tag := NewTag().FromMap(map[string]any{"label": "Fire"}); tag.Color != defaultTagColor("Fire") {
+		t.Fatalf("tag without a color got %q", tag.Color)
+	}
+	if tag := NewTag().FromMap(map[string]any{"label": "Fire", "color": ""}); tag.Color != "" {
+		t.Fatalf("tag with the None color got %q", tag.Color)
+	}
+
+	// Stored legacy colors must not fail a write of the whole list.
+	tags := NewTags()
+	tags.List = []*Tag{{Label: "Police", Color: "red"}, {Label: "EMS", Color: ""}, {Label: "Fire", Color: "#abc"}}
+	tags.normalizeColors()
+	if tags.List[0].Color != defaultTagColor("Police") || tags.List[1].Color != "" || tags.List[2].Color != "#AABBCC" {
+		t.Fatalf("got %q %q %q", tags.List[0].Color, tags.List[1].Color, tags.List[2].Color)
+	}
+}
+
+func TestMergeTagIdList(t *testing.T) {
+	ids, changed := mergeTagIdList([]uint64{1, 2, 3, 4}, []uint64{2, 4}, 3)
+	if !changed || len(ids) != 2 || ids[0] != 1 || ids[1] != 3 {
+		t.Fatalf("got %v changed=%v", ids, changed)
+	}
+	if _, changed := mergeTagIdList([]uint64{1, 5}, []uint64{2}, 3); changed {
+		t.Fatal("list without a source tag must be left alone")
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -54,6 +55,14 @@ type Talkgroup struct {
 	LinkedVoiceTalkgroupRef      uint `json:"linkedVoiceTalkgroupRef"`
 	LinkedVoiceWindowSeconds     uint `json:"linkedVoiceWindowSeconds"`
 	LinkedVoiceMinDurationSeconds uint `json:"linkedVoiceMinDurationSeconds"`
+	// Additional voice talkgroups watched alongside LinkedVoiceTalkgroupRef, for agencies that
+	// dispatch a page on whichever of several tactical channels is free.
+	LinkedVoiceTalkgroupRefs []uint `json:"linkedVoiceTalkgroupRefs"`
+
+	// Voice capture window: after a tone alert is claimed by voice on this talkgroup, hold the alert
+	// open for N seconds and append further voice transmissions before the final push goes out.
+	// Each appended fragment restarts the window, up to voiceCaptureMaxHoldSeconds. 0 = disabled.
+	VoiceCaptureWindowSeconds uint `json:"voiceCaptureWindowSeconds"`
 
 	// Admin toggle: false suppresses all alerts & transcription for this talkgroup regardless of user prefs.
 	// Default true preserves existing behaviour.
@@ -65,18 +74,81 @@ type Talkgroup struct {
 	// Custom transcription prompt for this talkgroup. Overrides the system-level and global prompt when non-empty.
 	TranscriptionPrompt string `json:"transcriptionPrompt"`
 
+	// Language hint sent with this talkgroup's transcription requests (e.g. "es").
+	// Empty uses the global transcription language.
+	TranscriptionLanguage string `json:"transcriptionLanguage"`
+
+	// When true, missed calls on this talkgroup are kept ahead of other traffic in
+	// the reconnection buffer and delivered first when the user comes back.
+	ReconnectionPriority bool `json:"reconnectionPriority"`
+
+	// Marks a talkgroup known to carry encrypted traffic (set from RadioReference
+	// on import, or by hand). Informational only.
+	Encrypted bool `json:"encrypted"`
+
 	// When true, observe paging patterns for auto-learn on this talkgroup.
 	AutoLearnToneSets bool `json:"autoLearnToneSets"`
 
 	// When true, learn radio unitRef → label mappings on this talkgroup.
 	AutoLearnUnitAliases bool `json:"autoLearnUnitAliases"`
 
+	// When true, the tone/keyword debug logger may save this talkgroup's call audio to disk.
+	// Off by default so protected channels never have raw audio written outside the database.
+	AllowDebugAudio bool `json:"allowDebugAudio"`
+
 	// Days to retain calls; 0 = inherit system retention, then global pruneDays.
 	RetentionDays uint `json:"retentionDays"`
 
 	IncidentMapping IncidentMappingConfig `json:"incidentMapping"`
 }
 
+// LinkedVoiceRefs returns every talkgroup ref watched for voice after tones on this
+// talkgroup, without duplicates or the talkgroup's own ref.
+func (talkgroup *Talkgroup) LinkedVoiceRefs() []uint {
+	refs := []uint{}
+	seen := map[uint]bool{0: true, talkgroup.TalkgroupRef: true}
+	for _, ref := range append([]uint{talkgroup.LinkedVoiceTalkgroupRef}, talkgroup.LinkedVoiceTalkgroupRefs...) {
+		if !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// transcriptionLanguagePattern matches a language code such as "es", "fil" or
+// "pt-br", or "auto" for provider-side detection.
+var transcriptionLanguagePattern = regexp.MustCompile(`^(auto|[a-z]{2,3}(-[a-z0-9]{2,4})?)$`)
+
+// normalizeTranscriptionLanguage lower-cases a language hint and returns "" when
+// it is not a usable code, so the talkgroup falls back to the global language.
+func normalizeTranscriptionLanguage(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if !transcriptionLanguagePattern.MatchString(s) {
+		return ""
+	}
+	return s
+}
+
+// parseTalkgroupRefList parses a comma or whitespace separated list of talkgroup refs.
+func parseTalkgroupRefList(s string) []uint {
+	refs := []uint{}
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == ';' }) {
+		if i, err := strconv.ParseUint(f, 10, 32); err == nil && i > 0 {
+			refs = append(refs, uint(i))
+		}
+	}
+	return refs
+}
+
+func formatTalkgroupRefList(refs []uint) string {
+	parts := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		parts = append(parts, strconv.FormatUint(uint64(ref), 10))
+	}
+	return strings.Join(parts, ",")
+}
+
 func NewTalkgroup() *Talkgroup {
 	return &Talkgroup{
 		GroupIds: []uint64{},
@@ -196,6 +268,24 @@ func (talkgroup *Talkgroup) FromMap(m map[string]any) *Talkgroup {
 		talkgroup.LinkedVoiceMinDurationSeconds = uint(v)
 	}
 
+	// Accept an array of refs, or the comma-separated text the admin form submits
+	switch v := m["linkedVoiceTalkgroupRefs"].(type) {
+	case []any:
+		talkgroup.LinkedVoiceTalkgroupRefs = []uint{}
+		for _, v := range v {
+			if f, ok := v.(float64); ok && f > 0 {
+				talkgroup.LinkedVoiceTalkgroupRefs = append(talkgroup.LinkedVoiceTalkgroupRefs, uint(f))
+			}
+		}
+	case string:
+		talkgroup.LinkedVoiceTalkgroupRefs = parseTalkgroupRefList(v)
+	}
+
+	switch v := m["voiceCaptureWindowSeconds"].(type) {
+	case float64:
+		talkgroup.VoiceCaptureWindowSeconds = uint(v)
+	}
+
 	// Parse alertsEnabled (defaults to true — no change in behaviour for existing data)
 	switch v := m["alertsEnabled"].(type) {
 	case bool:
@@ -204,6 +294,11 @@ func (talkgroup *Talkgroup) FromMap(m map[string]any) *Talkgroup {
 		talkgroup.AlertsEnabled = true
 	}
 
+	switch v := m["allowDebugAudio"].(type) {
+	case bool:
+		talkgroup.AllowDebugAudio = v
+	}
+
 	switch v := m["alertingTalkgroup"].(type) {
 	case bool:
 		talkgroup.AlertingTalkgroup = v
@@ -215,6 +310,21 @@ func (talkgroup *Talkgroup) FromMap(m map[string]any) *Talkgroup {
 		talkgroup.TranscriptionPrompt = v
 	}
 
+	switch v := m["transcriptionLanguage"].(type) {
+	case string:
+		talkgroup.TranscriptionLanguage = normalizeTranscriptionLanguage(v)
+	}
+
+	switch v := m["reconnectionPriority"].(type) {
+	case bool:
+		talkgroup.ReconnectionPriority = v
+	}
+
+	switch v := m["encrypted"].(type) {
+	case bool:
+		talkgroup.Encrypted = v
+	}
+
 	switch v := m["autoLearnToneSets"].(type) {
 	case bool:
 		talkgroup.AutoLearnToneSets = v
@@ -287,11 +397,21 @@ func (talkgroup *Talkgroup) MarshalJSON() ([]byte, error) {
 	m["linkedVoiceTalkgroupRef"] = talkgroup.LinkedVoiceTalkgroupRef
 	m["linkedVoiceWindowSeconds"] = talkgroup.LinkedVoiceWindowSeconds
 	m["linkedVoiceMinDurationSeconds"] = talkgroup.LinkedVoiceMinDurationSeconds
+	if len(talkgroup.LinkedVoiceTalkgroupRefs) > 0 {
+		m["linkedVoiceTalkgroupRefs"] = talkgroup.LinkedVoiceTalkgroupRefs
+	}
+	m["voiceCaptureWindowSeconds"] = talkgroup.VoiceCaptureWindowSeconds
 	m["alertsEnabled"] = talkgroup.AlertsEnabled
 	m["transcriptionPrompt"] = talkgroup.TranscriptionPrompt
+	if talkgroup.TranscriptionLanguage != "" {
+		m["transcriptionLanguage"] = talkgroup.TranscriptionLanguage
+	}
+	m["reconnectionPriority"] = talkgroup.ReconnectionPriority
+	m["encrypted"] = talkgroup.Encrypted
 	m["autoLearnToneSets"] = talkgroup.AutoLearnToneSets
 	m["autoLearnUnitAliases"] = talkgroup.AutoLearnUnitAliases
 	m["alertingTalkgroup"] = talkgroup.AlertingTalkgroup
+	m["allowDebugAudio"] = talkgroup.AllowDebugAudio
 
 	if talkgroup.RetentionDays > 0 {
 		m["retentionDays"] = talkgroup.RetentionDays
@@ -378,7 +498,8 @@ func (talkgroups *Talkgroups) ReadTx(tx *sql.Tx, systemId uint64, dbType string)
 		query string
 		rows  *sql.Rows
 
-		groupIds string
+		groupIds        string
+		linkedVoiceRefs string
 	)
 
 	talkgroups.mutex.Lock()
@@ -389,10 +510,10 @@ func (talkgroups *Talkgroups) ReadTx(tx *sql.Tx, systemId uint64, dbType string)
 	formatError := errorFormatter("talkgroups", "read")
 
 	if dbType == DbTypePostgresql {
-		query = fmt.Sprintf(`SELECT t."talkgroupId", t."delay", t."frequency", t."label", t."name", t."order", t."tagId", t."talkgroupRef", t."type", t."toneDetectionEnabled", t."toneSets", t."preferredApiKeyId", t."excludeFromPreferredSite", t."toneDownstreamEnabled", t."toneDownstreamURL", t."toneDownstreamAPIKey", t."alertCooldownSeconds", t."linkedVoiceTalkgroupRef", t."linkedVoiceWindowSeconds", t."linkedVoiceMinDurationSeconds", t."alertsEnabled", t."transcriptionPrompt", t."autoLearnToneSets", t."alertingTalkgroup", t."autoLearnUnitAliases", t."retentionDays", STRING_AGG(CAST(COALESCE(tg."groupId", 0) AS text), ',') FROM "talkgroups" AS t LEFT JOIN "talkgroupGroups" AS tg ON tg."talkgroupId" = t."talkgroupId" WHERE t."systemId" = %d GROUP BY t."talkgroupId", t."preferredApiKeyId", t."excludeFromPreferredSite", t."toneDownstreamEnabled", t."toneDownstreamURL", t."toneDownstreamAPIKey", t."alertCooldownSeconds", t."linkedVoiceTalkgroupRef", t."linkedVoiceWindowSeconds", t."linkedVoiceMinDurationSeconds", t."alertsEnabled", t."transcriptionPrompt", t."autoLearnToneSets", t."alertingTalkgroup", t."autoLearnUnitAliases", t."retentionDays"`, systemId)
+		query = fmt.Sprintf(`SELECT t."talkgroupId", t."delay", t."frequency", t."label", t."name", t."order", t."tagId", t."talkgroupRef", t."type", t."toneDetectionEnabled", t."toneSets", t."preferredApiKeyId", t."excludeFromPreferredSite", t."toneDownstreamEnabled", t."toneDownstreamURL", t."toneDownstreamAPIKey", t."alertCooldownSeconds", t."linkedVoiceTalkgroupRef", t."linkedVoiceWindowSeconds", t."linkedVoiceMinDurationSeconds", t."voiceCaptureWindowSeconds", t."linkedVoiceTalkgroupRefs", t."alertsEnabled", t."transcriptionPrompt", t."autoLearnToneSets", t."alertingTalkgroup", t."autoLearnUnitAliases", t."retentionDays", t."allowDebugAudio", t."transcriptionLanguage", t."reconnectionPriority", t."encrypted", STRING_AGG(CAST(COALESCE(tg."groupId", 0) AS text), ',') FROM "talkgroups" AS t LEFT JOIN "talkgroupGroups" AS tg ON tg."talkgroupId" = t."talkgroupId" WHERE t."systemId" = %d GROUP BY t."talkgroupId", t."preferredApiKeyId", t."excludeFromPreferredSite", t."toneDownstreamEnabled", t."toneDownstreamURL", t."toneDownstreamAPIKey", t."alertCooldownSeconds", t."linkedVoiceTalkgroupRef", t."linkedVoiceWindowSeconds", t."linkedVoiceMinDurationSeconds", t."voiceCaptureWindowSeconds", t."linkedVoiceTalkgroupRefs", t."alertsEnabled", t."transcriptionPrompt", t."autoLearnToneSets", t."alertingTalkgroup", t."autoLearnUnitAliases", t."retentionDays", t."allowDebugAudio", t."transcriptionLanguage", t."reconnectionPriority", t."encrypted"`, systemId)
 
 	} else {
-		query = fmt.Sprintf(`SELECT t."talkgroupId", t."delay", t."frequency", t."label", t."name", t."order", t."tagId", t."talkgroupRef", t."type", t."toneDetectionEnabled", t."toneSets", t."preferredApiKeyId", t."excludeFromPreferredSite", t."toneDownstreamEnabled", t."toneDownstreamURL", t."toneDownstreamAPIKey", t."alertCooldownSeconds", t."linkedVoiceTalkgroupRef", t."linkedVoiceWindowSeconds", t."linkedVoiceMinDurationSeconds", t."alertsEnabled", t."transcriptionPrompt", t."autoLearnToneSets", t."alertingTalkgroup", t."autoLearnUnitAliases", t."retentionDays", GROUP_CONCAT(COALESCE(tg."groupId", 0)) FROM "talkgroups" AS t LEFT JOIN "talkgroupGroups" AS tg ON tg."talkgroupId" = t."talkgroupId" WHERE t."systemId" = %d GROUP BY t."talkgroupId"`, systemId)
+		query = fmt.Sprintf(`SELECT t."talkgroupId", t."delay", t."frequency", t."label", t."name", t."order", t."tagId", t."talkgroupRef", t."type", t."toneDetectionEnabled", t."toneSets", t."preferredApiKeyId", t."excludeFromPreferredSite", t."toneDownstreamEnabled", t."toneDownstreamURL", t."toneDownstreamAPIKey", t."alertCooldownSeconds", t."linkedVoiceTalkgroupRef", t."linkedVoiceWindowSeconds", t."linkedVoiceMinDurationSeconds", t."voiceCaptureWindowSeconds", t."linkedVoiceTalkgroupRefs", t."alertsEnabled", t."transcriptionPrompt", t."autoLearnToneSets", t."alertingTalkgroup", t."autoLearnUnitAliases", t."retentionDays", t."allowDebugAudio", t."transcriptionLanguage", t."reconnectionPriority", t."encrypted", GROUP_CONCAT(COALESCE(tg."groupId", 0)) FROM "talkgroups" AS t LEFT JOIN "talkgroupGroups" AS tg ON tg."talkgroupId" = t."talkgroupId" WHERE t."systemId" = %d GROUP BY t."talkgroupId"`, systemId)
 	}
 
 	if rows, err = tx.Query(query); err != nil {
@@ -405,7 +526,7 @@ func (talkgroups *Talkgroups) ReadTx(tx *sql.Tx, systemId uint64, dbType string)
 		var preferredApiKeyUnused sql.NullInt64
 		var excludePreferredUnused bool
 
-		if err = rows.Scan(&talkgroup.Id, &talkgroup.Delay, &talkgroup.Frequency, &talkgroup.Label, &talkgroup.Name, &talkgroup.Order, &talkgroup.TagId, &talkgroup.TalkgroupRef, &talkgroup.Kind, &talkgroup.ToneDetectionEnabled, &toneSetsJson, &preferredApiKeyUnused, &excludePreferredUnused, &talkgroup.ToneDownstreamEnabled, &talkgroup.ToneDownstreamURL, &talkgroup.ToneDownstreamAPIKey, &talkgroup.AlertCooldownSeconds, &talkgroup.LinkedVoiceTalkgroupRef, &talkgroup.LinkedVoiceWindowSeconds, &talkgroup.LinkedVoiceMinDurationSeconds, &talkgroup.AlertsEnabled, &talkgroup.TranscriptionPrompt, &talkgroup.AutoLearnToneSets, &talkgroup.AlertingTalkgroup, &talkgroup.AutoLearnUnitAliases, &talkgroup.RetentionDays, &groupIds); err != nil {
+		if err = rows.Scan(&talkgroup.Id, &talkgroup.Delay, &talkgroup.Frequency, &talkgroup.Label, &talkgroup.Name, &talkgroup.Order, &talkgroup.TagId, &talkgroup.TalkgroupRef, &talkgroup.Kind, &talkgroup.ToneDetectionEnabled, &toneSetsJson, &preferredApiKeyUnused, &excludePreferredUnused, &talkgroup.ToneDownstreamEnabled, &talkgroup.ToneDownstreamURL, &talkgroup.ToneDownstreamAPIKey, &talkgroup.AlertCooldownSeconds, &talkgroup.LinkedVoiceTalkgroupRef, &talkgroup.LinkedVoiceWindowSeconds, &talkgroup.LinkedVoiceMinDurationSeconds, &talkgroup.VoiceCaptureWindowSeconds, &linkedVoiceRefs, &talkgroup.AlertsEnabled, &talkgroup.TranscriptionPrompt, &talkgroup.AutoLearnToneSets, &talkgroup.AlertingTalkgroup, &talkgroup.AutoLearnUnitAliases, &talkgroup.RetentionDays, &talkgroup.AllowDebugAudio, &talkgroup.TranscriptionLanguage, &talkgroup.ReconnectionPriority, &talkgroup.Encrypted, &groupIds); err != nil {
 			break
 		}
 
@@ -422,6 +543,8 @@ func (talkgroups *Talkgroups) ReadTx(tx *sql.Tx, systemId uint64, dbType string)
 			}
 		}
 
+		talkgroup.LinkedVoiceTalkgroupRefs = parseTalkgroupRefList(linkedVoiceRefs)
+
 		talkgroups.List = append(talkgroups.List, talkgroup)
 	}
 
@@ -443,12 +566,15 @@ func (talkgroups *Talkgroups) ReadTx(tx *sql.Tx, systemId uint64, dbType string)
 	return nil
 }
 
-func (talkgroups *Talkgroups) WriteTx(tx *sql.Tx, systemId uint64, dbType string) error {
+// WriteTx saves the talkgroups of a system and returns the audio object keys
+// of calls deleted along with removed talkgroups.
+func (talkgroups *Talkgroups) WriteTx(tx *sql.Tx, systemId uint64, dbType string) ([]string, error) {
 	var (
-		err   error
-		query string
-		res   sql.Result
-		rows  *sql.Rows
+		audioKeys []string
+		err       error
+		query     string
+		res       sql.Result
+		rows      *sql.Rows
 
 		talkgroupGroupIds = []uint64{}
 		talkgroupIds      = []uint64{}
@@ -461,7 +587,7 @@ func (talkgroups *Talkgroups) WriteTx(tx *sql.Tx, systemId uint64, dbType string
 
 	query = fmt.Sprintf(`SELECT "talkgroupId" FROM "talkgroups" WHERE "systemId" = %d`, systemId)
 	if rows, err = tx.Query(query); err != nil {
-		return formatError(err, query)
+		return nil, formatError(err, query)
 	}
 
 	for rows.Next() {
@@ -484,21 +610,25 @@ func (talkgroups *Talkgroups) WriteTx(tx *sql.Tx, systemId uint64, dbType string
 	rows.Close()
 
 	if err != nil {
-		return formatError(err, "")
+		return nil, formatError(err, "")
 	}
 
 	if len(talkgroupIds) > 0 {
 		if b, err := json.Marshal(talkgroupIds); err == nil {
 			in := strings.ReplaceAll(strings.ReplaceAll(string(b), "[", "("), "]", ")")
 
+			if audioKeys, err = storedAudioKeys(tx, fmt.Sprintf(`"talkgroupId" IN %s`, in)); err != nil {
+				return nil, formatError(err, "")
+			}
+
 			query = fmt.Sprintf(`DELETE FROM "talkgroups" WHERE "talkgroupId" IN %s`, in)
 			if _, err = tx.Exec(query); err != nil {
-				return formatError(err, query)
+				return nil, formatError(err, query)
 			}
 
 			query = fmt.Sprintf(`DELETE FROM "talkgroupGroups" WHERE "talkgroupId" IN %s`, in)
 			if _, err = tx.Exec(query); err != nil {
-				return formatError(err, query)
+				return nil, formatError(err, query)
 			}
 		}
 	}
@@ -572,10 +702,10 @@ func (talkgroups *Talkgroups) WriteTx(tx *sql.Tx, systemId uint64, dbType string
 		if count == 0 {
 			if talkgroup.Id > 0 {
 				// Preserve the explicit ID when inserting
-				query = fmt.Sprintf(`INSERT INTO "talkgroups" ("talkgroupId", "delay", "frequency", "label", "name", "order", "systemId", "tagId", "talkgroupRef", "type", "toneDetectionEnabled", "toneSets", "preferredApiKeyId", "excludeFromPreferredSite", "toneDownstreamEnabled", "toneDownstreamURL", "toneDownstreamAPIKey", "alertCooldownSeconds", "linkedVoiceTalkgroupRef", "linkedVoiceWindowSeconds", "linkedVoiceMinDurationSeconds", "alertsEnabled", "transcriptionPrompt", "autoLearnToneSets", "alertingTalkgroup", "autoLearnUnitAliases", "retentionDays") VALUES (%d, %d, %d, '%s', '%s', %d, %d, %d, %d, '%s', %t, '%s', %s, %t, %t, '%s', '%s', %d, %d, %d, %d, %t, '%s', %t, %t, %t, %d)`, talkgroup.Id, talkgroup.Delay, talkgroup.Frequency, escapeQuotes(talkgroup.Label), escapeQuotes(talkgroup.Name), talkgroup.Order, systemId, validTagId, talkgroup.TalkgroupRef, talkgroup.Kind, talkgroup.ToneDetectionEnabled, escapeQuotes(toneSetsJson), preferredApiKeyIdSQL, false, talkgroup.ToneDownstreamEnabled, escapeQuotes(talkgroup.ToneDownstreamURL), escapeQuotes(talkgroup.ToneDownstreamAPIKey), talkgroup.AlertCooldownSeconds, talkgroup.LinkedVoiceTalkgroupRef, talkgroup.LinkedVoiceWindowSeconds, talkgroup.LinkedVoiceMinDurationSeconds, talkgroup.AlertsEnabled, escapeQuotes(talkgroup.TranscriptionPrompt), talkgroup.AutoLearnToneSets, talkgroup.AlertingTalkgroup, talkgroup.AutoLearnUnitAliases, talkgroup.RetentionDays)
+				query = fmt.Sprintf(`INSERT INTO "talkgroups" ("talkgroupId", "delay", "frequency", "label", "name", "order", "systemId", "tagId", "talkgroupRef", "type", "toneDetectionEnabled", "toneSets", "preferredApiKeyId", "excludeFromPreferredSite", "toneDownstreamEnabled", "toneDownstreamURL", "toneDownstreamAPIKey", "alertCooldownSeconds", "linkedVoiceTalkgroupRef", "linkedVoiceWindowSeconds", "linkedVoiceMinDurationSeconds", "voiceCaptureWindowSeconds", "linkedVoiceTalkgroupRefs", "alertsEnabled", "transcriptionPrompt", "autoLearnToneSets", "alertingTalkgroup", "autoLearnUnitAliases", "retentionDays", "allowDebugAudio", "transcriptionLanguage", "reconnectionPriority", "encrypted") VALUES (%d, %d, %d, '%s', '%s', %d, %d, %d, %d, '%s', %t, '%s', %s, %t, %t, '%s', '%s', %d, %d, %d, %d, %d, '%s', %t, '%s', %t, %t, %t, %d, %t, '%s', %t, %t)`, talkgroup.Id, talkgroup.Delay, talkgroup.Frequency, escapeQuotes(talkgroup.Label), escapeQuotes(talkgroup.Name), talkgroup.Order, systemId, validTagId, talkgroup.TalkgroupRef, talkgroup.Kind, talkgroup.ToneDetectionEnabled, escapeQuotes(toneSetsJson), preferredApiKeyIdSQL, false, talkgroup.ToneDownstreamEnabled, escapeQuotes(talkgroup.ToneDownstreamURL), escapeQuotes(talkgroup.ToneDownstreamAPIKey), talkgroup.AlertCooldownSeconds, talkgroup.LinkedVoiceTalkgroupRef, talkgroup.LinkedVoiceWindowSeconds, talkgroup.LinkedVoiceMinDurationSeconds, talkgroup.VoiceCaptureWindowSeconds, escapeQuotes(formatTalkgroupRefList(talkgroup.LinkedVoiceTalkgroupRefs)), talkgroup.AlertsEnabled, escapeQuotes(talkgroup.TranscriptionPrompt), talkgroup.AutoLearnToneSets, talkgroup.AlertingTalkgroup, talkgroup.AutoLearnUnitAliases, talkgroup.RetentionDays, talkgroup.AllowDebugAudio, escapeQuotes(talkgroup.TranscriptionLanguage), talkgroup.ReconnectionPriority, talkgroup.Encrypted)
 			} else {
 				// Let database assign auto-increment ID
-				query = fmt.Sprintf(`INSERT INTO "talkgroups" ("delay", "frequency", "label", "name", "order", "systemId", "tagId", "talkgroupRef", "type", "toneDetectionEnabled", "toneSets", "preferredApiKeyId", "excludeFromPreferredSite", "toneDownstreamEnabled", "toneDownstreamURL", "toneDownstreamAPIKey", "alertCooldownSeconds", "linkedVoiceTalkgroupRef", "linkedVoiceWindowSeconds", "linkedVoiceMinDurationSeconds", "alertsEnabled", "transcriptionPrompt", "autoLearnToneSets", "alertingTalkgroup", "autoLearnUnitAliases", "retentionDays") VALUES (%d, %d, '%s', '%s', %d, %d, %d, %d, '%s', %t, '%s', %s, %t, %t, '%s', '%s', %d, %d, %d, %d, %t, '%s', %t, %t, %t, %d)`, talkgroup.Delay, talkgroup.Frequency, escapeQuotes(talkgroup.Label), escapeQuotes(talkgroup.Name), talkgroup.Order, systemId, validTagId, talkgroup.TalkgroupRef, talkgroup.Kind, talkgroup.ToneDetectionEnabled, escapeQuotes(toneSetsJson), preferredApiKeyIdSQL, false, talkgroup.ToneDownstreamEnabled, escapeQuotes(talkgroup.ToneDownstreamURL), escapeQuotes(talkgroup.ToneDownstreamAPIKey), talkgroup.AlertCooldownSeconds, talkgroup.LinkedVoiceTalkgroupRef, talkgroup.LinkedVoiceWindowSeconds, talkgroup.LinkedVoiceMinDurationSeconds, talkgroup.AlertsEnabled, escapeQuotes(talkgroup.TranscriptionPrompt), talkgroup.AutoLearnToneSets, talkgroup.AlertingTalkgroup, talkgroup.AutoLearnUnitAliases, talkgroup.RetentionDays)
+				query = fmt.Sprintf(`INSERT INTO "talkgroups" ("delay", "frequency", "label", "name", "order", "systemId", "tagId", "talkgroupRef", "type", "toneDetectionEnabled", "toneSets", "preferredApiKeyId", "excludeFromPreferredSite", "toneDownstreamEnabled", "toneDownstreamURL", "toneDownstreamAPIKey", "alertCooldownSeconds", "linkedVoiceTalkgroupRef", "linkedVoiceWindowSeconds", "linkedVoiceMinDurationSeconds", "voiceCaptureWindowSeconds", "linkedVoiceTalkgroupRefs", "alertsEnabled", "transcriptionPrompt", "autoLearnToneSets", "alertingTalkgroup", "autoLearnUnitAliases", "retentionDays", "allowDebugAudio", "transcriptionLanguage", "reconnectionPriority", "encrypted") VALUES (%d, %d, '%s', '%s', %d, %d, %d, %d, '%s', %t, '%s', %s, %t, %t, '%s', '%s', %d, %d, %d, %d, %d, '%s', %t, '%s', %t, %t, %t, %d, %t, '%s', %t, %t)`, talkgroup.Delay, talkgroup.Frequency, escapeQuotes(talkgroup.Label), escapeQuotes(talkgroup.Name), talkgroup.Order, systemId, validTagId, talkgroup.TalkgroupRef, talkgroup.Kind, talkgroup.ToneDetectionEnabled, escapeQuotes(toneSetsJson), preferredApiKeyIdSQL, false, talkgroup.ToneDownstreamEnabled, escapeQuotes(talkgroup.ToneDownstreamURL), escapeQuotes(talkgroup.ToneDownstreamAPIKey), talkgroup.AlertCooldownSeconds, talkgroup.LinkedVoiceTalkgroupRef, talkgroup.LinkedVoiceWindowSeconds, talkgroup.LinkedVoiceMinDurationSeconds, talkgroup.VoiceCaptureWindowSeconds, escapeQuotes(formatTalkgroupRefList(talkgroup.LinkedVoiceTalkgroupRefs)), talkgroup.AlertsEnabled, escapeQuotes(talkgroup.TranscriptionPrompt), talkgroup.AutoLearnToneSets, talkgroup.AlertingTalkgroup, talkgroup.AutoLearnUnitAliases, talkgroup.RetentionDays, talkgroup.AllowDebugAudio, escapeQuotes(talkgroup.TranscriptionLanguage), talkgroup.ReconnectionPriority, talkgroup.Encrypted)
 			}
 
 			if dbType == DbTypePostgresql {
@@ -604,7 +734,7 @@ func (talkgroups *Talkgroups) WriteTx(tx *sql.Tx, systemId uint64, dbType string
 				}
 			}
 			// preferredApiKeyIdSQL is already calculated above
-			query = fmt.Sprintf(`UPDATE "talkgroups" SET "delay" = %d, "frequency" = %d, "label" = '%s', "name" = '%s', "order" = %d, "tagId" = %d, "talkgroupRef" = %d, "type" = '%s', "toneDetectionEnabled" = %t, "toneSets" = '%s', "preferredApiKeyId" = %s, "excludeFromPreferredSite" = %t, "toneDownstreamEnabled" = %t, "toneDownstreamURL" = '%s', "toneDownstreamAPIKey" = '%s', "alertCooldownSeconds" = %d, "linkedVoiceTalkgroupRef" = %d, "linkedVoiceWindowSeconds" = %d, "linkedVoiceMinDurationSeconds" = %d, "alertsEnabled" = %t, "transcriptionPrompt" = '%s', "autoLearnToneSets" = %t, "alertingTalkgroup" = %t, "autoLearnUnitAliases" = %t, "retentionDays" = %d WHERE "talkgroupId" = %d`, talkgroup.Delay, talkgroup.Frequency, escapeQuotes(talkgroup.Label), escapeQuotes(talkgroup.Name), talkgroup.Order, validTagId, talkgroup.TalkgroupRef, talkgroup.Kind, talkgroup.ToneDetectionEnabled, escapeQuotes(toneSetsJson), preferredApiKeyIdSQL, false, talkgroup.ToneDownstreamEnabled, escapeQuotes(talkgroup.ToneDownstreamURL), escapeQuotes(talkgroup.ToneDownstreamAPIKey), talkgroup.AlertCooldownSeconds, talkgroup.LinkedVoiceTalkgroupRef, talkgroup.LinkedVoiceWindowSeconds, talkgroup.LinkedVoiceMinDurationSeconds, talkgroup.AlertsEnabled, escapeQuotes(talkgroup.TranscriptionPrompt), talkgroup.AutoLearnToneSets, talkgroup.AlertingTalkgroup, talkgroup.AutoLearnUnitAliases, talkgroup.RetentionDays, talkgroup.Id)
+			query = fmt.Sprintf(`UPDATE "talkgroups" SET "delay" = %d, "frequency" = %d, "label" = '%s', "name" = '%s', "order" = %d, "tagId" = %d, "talkgroupRef" = %d, "type" = '%s', "toneDetectionEnabled" = %t, "toneSets" = '%s', "preferredApiKeyId" = %s, "excludeFromPreferredSite" = %t, "toneDownstreamEnabled" = %t, "toneDownstreamURL" = '%s', "toneDownstreamAPIKey" = '%s', "alertCooldownSeconds" = %d, "linkedVoiceTalkgroupRef" = %d, "linkedVoiceWindowSeconds" = %d, "linkedVoiceMinDurationSeconds" = %d, "voiceCaptureWindowSeconds" = %d, "linkedVoiceTalkgroupRefs" = '%s', "alertsEnabled" = %t, "transcriptionPrompt" = '%s', "autoLearnToneSets" = %t, "alertingTalkgroup" = %t, "autoLearnUnitAliases" = %t, "retentionDays" = %d, "allowDebugAudio" = %t, "transcriptionLanguage" = '%s', "reconnectionPriority" = %t, "encrypted" = %t WHERE "talkgroupId" = %d`, talkgroup.Delay, talkgroup.Frequency, escapeQuotes(talkgroup.Label), escapeQuotes(talkgroup.Name), talkgroup.Order, validTagId, talkgroup.TalkgroupRef, talkgroup.Kind, talkgroup.ToneDetectionEnabled, escapeQuotes(toneSetsJson), preferredApiKeyIdSQL, false, talkgroup.ToneDownstreamEnabled, escapeQuotes(talkgroup.ToneDownstreamURL), escapeQuotes(talkgroup.ToneDownstreamAPIKey), talkgroup.AlertCooldownSeconds, talkgroup.LinkedVoiceTalkgroupRef, talkgroup.LinkedVoiceWindowSeconds, talkgroup.LinkedVoiceMinDurationSeconds, talkgroup.VoiceCaptureWindowSeconds, escapeQuotes(formatTalkgroupRefList(talkgroup.LinkedVoiceTalkgroupRefs)), talkgroup.AlertsEnabled, escapeQuotes(talkgroup.TranscriptionPrompt), talkgroup.AutoLearnToneSets, talkgroup.AlertingTalkgroup, talkgroup.AutoLearnUnitAliases, talkgroup.RetentionDays, talkgroup.AllowDebugAudio, escapeQuotes(talkgroup.TranscriptionLanguage), talkgroup.ReconnectionPriority, talkgroup.Encrypted, talkgroup.Id)
 			if _, err = tx.Exec(query); err != nil {
 				break
 			}
@@ -638,7 +768,7 @@ func (talkgroups *Talkgroups) WriteTx(tx *sql.Tx, systemId uint64, dbType string
 		rows.Close()
 
 		if err != nil {
-			return formatError(err, "")
+			return nil, formatError(err, "")
 		}
 
 		if len(talkgroupGroupIds) > 0 {
@@ -646,7 +776,7 @@ func (talkgroups *Talkgroups) WriteTx(tx *sql.Tx, systemId uint64, dbType string
 				in := strings.ReplaceAll(strings.ReplaceAll(string(b), "[", "("), "]", ")")
 				query = fmt.Sprintf(`DELETE FROM "talkgroupGroups" WHERE "talkgroupGroupId" IN %s`, in)
 				if _, err = tx.Exec(query); err != nil {
-					return formatError(err, query)
+					return nil, formatError(err, query)
 				}
 			}
 		}
@@ -690,10 +820,10 @@ func (talkgroups *Talkgroups) WriteTx(tx *sql.Tx, systemId uint64, dbType string
 	}
 
 	if err != nil {
-		return formatError(err, query)
+		return nil, formatError(err, query)
 	}
 
-	return nil
+	return audioKeys, nil
 }
 
 type TalkgroupsMap []TalkgroupMap
@@ -59,6 +59,12 @@ type ToneSet struct {
 	DownstreamEnabled bool   `json:"downstreamEnabled"` // Forward alerts for this tone set to an external endpoint
 	DownstreamURL     string `json:"downstreamURL"`     // Destination URL (TonesToActive server)
 	DownstreamAPIKey  string `json:"downstreamAPIKey"`  // API key sent in X-API-Key header
+	// Repeat-page suppression: seconds after an alert during which further matches of this tone set on
+	// the same talkgroup are ignored. nil uses the global toneSetCooldownSeconds option; 0 disables.
+	CooldownSeconds *uint `json:"cooldownSeconds,omitempty"`
+	// UserGroupIds restricts tone alerts for this set to members of these user groups.
+	// Empty notifies every user with access to the talkgroup.
+	UserGroupIds []uint64 `json:"userGroupIds,omitempty"`
 	// Incident mapping jurisdiction (takes priority over parent talkgroup geo when this tone set matches)
 	GeoCity         string  `json:"geoCity"`
 	GeoLat          float64 `json:"geoLat"`
@@ -105,6 +111,8 @@ type PendingToneSequence struct {
 	// it is used to also clean up the source talkgroup's own pending-tones entry so a second alert
 	// is not fired if a voice call later arrives on the original (tone) talkgroup.
 	CrossTalkgroupSourceKey string
+	// SourceTalkgroupRef is the talkgroup ref the tones were detected on (cross-talkgroup entries only).
+	SourceTalkgroupRef uint
 }
 
 // ToneDetector handles tone detection in audio calls
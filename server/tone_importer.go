@@ -30,8 +30,9 @@ import (
 type ToneImportFormat string
 
 const (
-	ToneImportFormatTwoTone ToneImportFormat = "twotone"
-	ToneImportFormatCSV     ToneImportFormat = "csv"
+	ToneImportFormatTwoTone  ToneImportFormat = "twotone"
+	ToneImportFormatCSV      ToneImportFormat = "csv"
+	ToneImportFormatThinline ToneImportFormat = "thinline"
 )
 
 type ToneImportRequest struct {
@@ -40,15 +41,17 @@ type ToneImportRequest struct {
 }
 
 type ToneImportResponse struct {
-	Format   string    `json:"format"`
-	Count    int       `json:"count"`
-	ToneSets []ToneSet `json:"toneSets"`
-	Warnings []string  `json:"warnings,omitempty"`
+	Format                    string    `json:"format"`
+	Count                     int       `json:"count"`
+	ToneSets                  []ToneSet `json:"toneSets"`
+	Warnings                  []string  `json:"warnings,omitempty"`
+	VoiceCaptureWindowSeconds uint      `json:"voiceCaptureWindowSeconds,omitempty"`
 }
 
 type toneImportResult struct {
-	toneSets []ToneSet
-	warnings []string
+	toneSets                  []ToneSet
+	warnings                  []string
+	voiceCaptureWindowSeconds uint
 }
 
 func ParseToneImport(format string, content string) (*toneImportResult, error) {
@@ -62,6 +65,8 @@ func ParseToneImport(format string, content string) (*toneImportResult, error) {
 		return parseTwoToneDetectConfig(content)
 	case ToneImportFormatCSV:
 		return parseToneCSV(content)
+	case ToneImportFormatThinline:
+		return parseToneShareFile(content)
 	default:
 		return nil, fmt.Errorf("unsupported format: %s", format)
 	}
@@ -398,9 +398,9 @@ func (controller *Controller) fetchToneHistoryCallBatch(systemId, talkgroupId ui
 
 	var query string
 	if controller.Database.Config.DbType == DbTypePostgresql {
-		query = `SELECT "callId", "audio", "audioMime", "audioFilename", "transcript", "reviewedTranscript", "timestamp" FROM "calls" WHERE "systemId" = $1 AND "talkgroupId" = $2 AND "timestamp" >= $3 AND length("audio") > 0 ORDER BY "timestamp" DESC LIMIT $4`
+		query = `SELECT "callId", "audio", "audioKey", "audioMime", "audioFilename", "transcript", "reviewedTranscript", "timestamp" FROM "calls" WHERE "systemId" = $1 AND "talkgroupId" = $2 AND "timestamp" >= $3 AND (length("audio") > 0 OR "audioKey" != '') ORDER BY "timestamp" DESC LIMIT $4`
 	} else {
-		query = `SELECT "callId", "audio", "audioMime", "audioFilename", "transcript", "reviewedTranscript", "timestamp" FROM "calls" WHERE "systemId" = ? AND "talkgroupId" = ? AND "timestamp" >= ? AND length("audio") > 0 ORDER BY "timestamp" DESC LIMIT ?`
+		query = `SELECT "callId", "audio", "audioKey", "audioMime", "audioFilename", "transcript", "reviewedTranscript", "timestamp" FROM "calls" WHERE "systemId" = ? AND "talkgroupId" = ? AND "timestamp" >= ? AND (length("audio") > 0 OR "audioKey" != '') ORDER BY "timestamp" DESC LIMIT ?`
 	}
 
 	rows, err := controller.Database.Sql.Query(query, systemId, talkgroupId, since, fetchLimit)
@@ -414,18 +414,22 @@ func (controller *Controller) fetchToneHistoryCallBatch(systemId, talkgroupId ui
 		var (
 			callId             uint64
 			audio              []byte
+			audioKey           string
 			audioMime          string
 			audioFilename      string
 			transcript         sql.NullString
 			reviewedTranscript sql.NullString
 			timestamp          int64
 		)
-		if err := rows.Scan(&callId, &audio, &audioMime, &audioFilename, &transcript, &reviewedTranscript, &timestamp); err != nil {
+		if err := rows.Scan(&callId, &audio, &audioKey, &audioMime, &audioFilename, &transcript, &reviewedTranscript, &timestamp); err != nil {
 			return nil, fmt.Errorf("scan call: %w", err)
 		}
 		if exclude[callId] {
 			continue
 		}
+		if audio, err = controller.loadCallAudio(audio, audioKey); err != nil {
+			continue
+		}
 		row := toneHistoryCallInput{
 			callId:        callId,
 			audio:         audio,
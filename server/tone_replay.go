@@ -0,0 +1,197 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+)
+
+// ToneReplayRequest asks for tone detection to be re-run against a stored call.
+// ToneSets optionally replaces the talkgroup's saved tone sets so unsaved edits
+// can be tried before they are applied.
+type ToneReplayRequest struct {
+	CallId   uint64    `json:"callId"`
+	ToneSets []ToneSet `json:"toneSets,omitempty"`
+}
+
+type ToneReplayTone struct {
+	Tone
+	MatchedToneSets []string `json:"matchedToneSets"`
+}
+
+type ToneReplayMatch struct {
+	Id    string `json:"id"`
+	Label string `json:"label"`
+}
+
+// ToneReplayResponse reports what the detector would have done for the call.
+// No alerts, pushes or downstream forwards are sent and the call is not updated.
+type ToneReplayResponse struct {
+	CallId          uint64            `json:"callId"`
+	SystemId        uint64            `json:"systemId"`
+	TalkgroupId     uint64            `json:"talkgroupId"`
+	TalkgroupRef    uint              `json:"talkgroupRef"`
+	ToneSetsTested  int               `json:"toneSetsTested"`
+	Tones           []ToneReplayTone  `json:"tones"`
+	MatchedToneSets []ToneReplayMatch `json:"matchedToneSets"`
+	Log             []string          `json:"log"`
+}
+
+// toneMatchedLabels returns the labels of the matched tone sets whose A, B or
+// long tone this detected tone falls within tolerance of.
+func toneMatchedLabels(tone Tone, matchedToneSets []*ToneSet) []string {
+	labels := []string{}
+	for _, ts := range matchedToneSets {
+		tolerance := ts.Tolerance
+		if tolerance < 1.0 {
+			tolerance = tolerance * 500.0
+		}
+
+		var expected *ToneSpec
+		switch tone.ToneType {
+		case "A":
+			expected = ts.ATone
+		case "B":
+			expected = ts.BTone
+		case "Long":
+			expected = ts.LongTone
+		}
+		if expected != nil && math.Abs(tone.Frequency-expected.Frequency) <= tolerance {
+			labels = append(labels, ts.Label)
+		}
+	}
+	return labels
+}
+
+// replayToneDetection runs the live tone detection and matching steps against a
+// stored call without touching alerts or the database.
+func (controller *Controller) replayToneDetection(req ToneReplayRequest) (*ToneReplayResponse, error) {
+	if controller == nil || controller.Database == nil {
+		return nil, fmt.Errorf("server not ready")
+	}
+	if req.CallId == 0 {
+		return nil, fmt.Errorf("callId is required")
+	}
+
+	var (
+		audio         []byte
+		audioKey      string
+		audioMime     string
+		audioFilename string
+		systemId      uint64
+		talkgroupId   uint64
+	)
+	query := fmt.Sprintf(`SELECT "audio", "audioKey", "audioMime", "audioFilename", "systemId", "talkgroupId" FROM "calls" WHERE "callId" = %d`, req.CallId)
+	if err := controller.Database.Sql.QueryRow(query).Scan(&audio, &audioKey, &audioMime, &audioFilename, &systemId, &talkgroupId); err != nil {
+		return nil, fmt.Errorf("call %d not found", req.CallId)
+	}
+	audio, err := controller.loadCallAudio(audio, audioKey)
+	if err != nil {
+		return nil, fmt.Errorf("call %d: %w", req.CallId, err)
+	}
+	if len(audio) == 0 {
+		return nil, fmt.Errorf("call %d has no stored audio", req.CallId)
+	}
+
+	system, ok := controller.Systems.GetSystemById(systemId)
+	if !ok {
+		return nil, fmt.Errorf("system %d not found", systemId)
+	}
+	talkgroup, ok := system.Talkgroups.GetTalkgroupById(talkgroupId)
+	if !ok {
+		return nil, fmt.Errorf("talkgroup %d not found on system %d", talkgroupId, systemId)
+	}
+
+	toneSets := talkgroup.ToneSets
+	if len(req.ToneSets) > 0 {
+		toneSets = req.ToneSets
+	}
+	if len(toneSets) == 0 {
+		return nil, fmt.Errorf("talkgroup %d has no tone sets configured", talkgroup.TalkgroupRef)
+	}
+
+	resp := &ToneReplayResponse{
+		CallId:          req.CallId,
+		SystemId:        systemId,
+		TalkgroupId:     talkgroupId,
+		TalkgroupRef:    talkgroup.TalkgroupRef,
+		ToneSetsTested:  len(toneSets),
+		Tones:           []ToneReplayTone{},
+		MatchedToneSets: []ToneReplayMatch{},
+		Log:             []string{},
+	}
+	logLine := func(message string) {
+		resp.Log = append(resp.Log, formatToneDetectionLog(req.CallId, systemId, talkgroup.TalkgroupRef, message))
+	}
+
+	logLine(fmt.Sprintf("Replay - %d tone sets configured, audio size: %d bytes", len(toneSets), len(audio)))
+
+	toneSequence, err := controller.ToneDetector.Detect(audio, toneHistoryAudioMime(audioMime, audioFilename), toneSets)
+	if err != nil {
+		logLine(fmt.Sprintf("FAILED: %v", err))
+		return resp, nil
+	}
+	if toneSequence == nil || len(toneSequence.Tones) == 0 {
+		logLine("No tones detected")
+		return resp, nil
+	}
+
+	matchedToneSets := controller.ToneDetector.MatchToneSets(toneSequence, toneSets)
+	for _, tone := range toneSequence.Tones {
+		labels := toneMatchedLabels(tone, matchedToneSets)
+		resp.Tones = append(resp.Tones, ToneReplayTone{Tone: tone, MatchedToneSets: labels})
+		resp.Log = append(resp.Log, formatToneFrequencyLog(req.CallId, tone.Frequency, tone.Duration, len(labels) > 0, strings.Join(labels, ", ")))
+	}
+
+	if len(matchedToneSets) == 0 {
+		logLine("No tone sets matched")
+		return resp, nil
+	}
+	labels := make([]string, len(matchedToneSets))
+	for i, ts := range matchedToneSets {
+		labels[i] = ts.Label
+		resp.MatchedToneSets = append(resp.MatchedToneSets, ToneReplayMatch{Id: ts.Id, Label: ts.Label})
+	}
+	logLine(fmt.Sprintf("MATCHED %d tone sets: %s", len(matchedToneSets), strings.Join(labels, ", ")))
+
+	return resp, nil
+}
+
+// ToneReplayHandler re-runs tone detection on a stored call for tuning tone sets.
+func (admin *Admin) ToneReplayHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := admin.GetAuthorization(r)
+	if !admin.ValidateToken(token) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var req ToneReplayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	result, err := admin.Controller.replayToneDetection(req)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(fmt.Sprintf(`{"error":"%s"}`, escapeQuotes(err.Error()))))
+		return
+	}
+
+	if b, err := json.Marshal(result); err == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b)
+	} else {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
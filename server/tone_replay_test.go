@@ -0,0 +1,32 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import "testing"
+
+func TestToneMatchedLabels(t *testing.T) {
+	station1 := &ToneSet{Label: "Station 1", Tolerance: 10, ATone: &ToneSpec{Frequency: 600}, BTone: &ToneSpec{Frequency: 900}}
+	station2 := &ToneSet{Label: "Station 2", Tolerance: 0.02, ATone: &ToneSpec{Frequency: 605}}
+	matched := []*ToneSet{station1, station2}
+
+	if got := toneMatchedLabels(Tone{Frequency: 604, ToneType: "A"}, matched); len(got) != 2 {
+		t.Fatalf("A tone labels = %v, want both stations", got)
+	}
+	if got := toneMatchedLabels(Tone{Frequency: 905, ToneType: "B"}, matched); len(got) != 1 || got[0] != "Station 1" {
+		t.Fatalf("B tone labels = %v, want [Station 1]", got)
+	}
+	if got := toneMatchedLabels(Tone{Frequency: 1200, ToneType: "Long"}, matched); len(got) != 0 {
+		t.Fatalf("unmatched long tone labels = %v, want none", got)
+	}
+}
+
+func TestFormatToneFrequencyLog(t *testing.T) {
+	want := "[TONE_FREQ] Call=7 | 604.0 Hz for 1.00s - MATCHED: Station 1"
+	if got := formatToneFrequencyLog(7, 604, 1, true, "Station 1"); got != want {
+		t.Fatalf("formatToneFrequencyLog = %q, want %q", got, want)
+	}
+	want = "[TONE_FREQ] Call=7 | 1200.0 Hz for 3.50s - NO_MATCH"
+	if got := formatToneFrequencyLog(7, 1200, 3.5, false, ""); got != want {
+		t.Fatalf("formatToneFrequencyLog = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Portable tone set files let agencies that share paging plans exchange tone
+// sets. Agency-local values (tone set ids, user group ids) and secrets (the
+// downstream API key) are never written, so a file is safe to hand out.
+const (
+	toneShareFormat  = "thinline-tone-sets"
+	toneShareVersion = 1
+
+	toneShareMinFrequency = 100.0
+	toneShareMaxFrequency = 4000.0
+	toneShareMaxDuration  = 60.0
+	toneShareMaxTolerance = 100.0
+)
+
+type ToneShareFile struct {
+	Format                    string         `json:"format"`
+	Version                   int            `json:"version"`
+	ExportedAt                string         `json:"exportedAt,omitempty"`
+	Source                    string         `json:"source,omitempty"`
+	VoiceCaptureWindowSeconds uint           `json:"voiceCaptureWindowSeconds,omitempty"`
+	ToneSets                  []ToneShareSet `json:"toneSets"`
+}
+
+type ToneShareSet struct {
+	Label             string    `json:"label"`
+	ATone             *ToneSpec `json:"aTone,omitempty"`
+	BTone             *ToneSpec `json:"bTone,omitempty"`
+	LongTone          *ToneSpec `json:"longTone,omitempty"`
+	Tolerance         float64   `json:"tolerance"`
+	MinDuration       float64   `json:"minDuration,omitempty"`
+	CooldownSeconds   *uint     `json:"cooldownSeconds,omitempty"`
+	DownstreamEnabled bool      `json:"downstreamEnabled,omitempty"`
+	DownstreamURL     string    `json:"downstreamURL,omitempty"`
+	GeoCity           string    `json:"geoCity,omitempty"`
+	GeoLat            float64   `json:"geoLat,omitempty"`
+	GeoLon            float64   `json:"geoLon,omitempty"`
+	GeoRadiusMiles    float64   `json:"geoRadiusMiles,omitempty"`
+	LocationContext   string    `json:"locationContext,omitempty"`
+}
+
+func newToneShareFile(talkgroup *Talkgroup, source string) ToneShareFile {
+	file := ToneShareFile{
+		Format:     toneShareFormat,
+		Version:    toneShareVersion,
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+		Source:     source,
+		ToneSets:   []ToneShareSet{},
+	}
+	if talkgroup == nil {
+		return file
+	}
+	file.VoiceCaptureWindowSeconds = talkgroup.VoiceCaptureWindowSeconds
+	for _, toneSet := range talkgroup.ToneSets {
+		file.ToneSets = append(file.ToneSets, ToneShareSet{
+			Label:             toneSet.Label,
+			ATone:             toneSet.ATone,
+			BTone:             toneSet.BTone,
+			LongTone:          toneSet.LongTone,
+			Tolerance:         toneSet.Tolerance,
+			MinDuration:       toneSet.MinDuration,
+			CooldownSeconds:   toneSet.CooldownSeconds,
+			DownstreamEnabled: toneSet.DownstreamEnabled,
+			DownstreamURL:     toneSet.DownstreamURL,
+			GeoCity:           toneSet.GeoCity,
+			GeoLat:            toneSet.GeoLat,
+			GeoLon:            toneSet.GeoLon,
+			GeoRadiusMiles:    toneSet.GeoRadiusMiles,
+			LocationContext:   toneSet.LocationContext,
+		})
+	}
+	return file
+}
+
+func validateToneShareSpec(name string, spec *ToneSpec) error {
+	if spec == nil {
+		return nil
+	}
+	if spec.Frequency < toneShareMinFrequency || spec.Frequency > toneShareMaxFrequency {
+		return fmt.Errorf("%s frequency %.1f Hz is outside %.0f-%.0f Hz", name, spec.Frequency, toneShareMinFrequency, toneShareMaxFrequency)
+	}
+	if spec.MinDuration < 0 || spec.MinDuration > toneShareMaxDuration {
+		return fmt.Errorf("%s minimum duration %.2fs is outside 0-%.0fs", name, spec.MinDuration, toneShareMaxDuration)
+	}
+	if spec.MaxDuration < 0 || spec.MaxDuration > toneShareMaxDuration {
+		return fmt.Errorf("%s maximum duration %.2fs is outside 0-%.0fs", name, spec.MaxDuration, toneShareMaxDuration)
+	}
+	if spec.MaxDuration > 0 && spec.MaxDuration < spec.MinDuration {
+		return fmt.Errorf("%s maximum duration is shorter than its minimum", name)
+	}
+	return nil
+}
+
+func validateToneShareSet(set ToneShareSet) error {
+	if strings.TrimSpace(set.Label) == "" {
+		return fmt.Errorf("label is required")
+	}
+	if set.ATone == nil && set.BTone == nil && set.LongTone == nil {
+		return fmt.Errorf("no tone definitions")
+	}
+	if err := validateToneShareSpec("A tone", set.ATone); err != nil {
+		return err
+	}
+	if err := validateToneShareSpec("B tone", set.BTone); err != nil {
+		return err
+	}
+	if err := validateToneShareSpec("long tone", set.LongTone); err != nil {
+		return err
+	}
+	if set.Tolerance < 0 || set.Tolerance > toneShareMaxTolerance {
+		return fmt.Errorf("tolerance %.2f is outside 0-%.0f", set.Tolerance, toneShareMaxTolerance)
+	}
+	if set.MinDuration < 0 || set.MinDuration > toneShareMaxDuration {
+		return fmt.Errorf("minimum duration %.2fs is outside 0-%.0fs", set.MinDuration, toneShareMaxDuration)
+	}
+	return nil
+}
+
+// parseToneShareFile reads a portable tone set file. Any malformed or
+// out-of-range tone set rejects the whole file so a partial plan is never
+// imported silently.
+func parseToneShareFile(content string) (*toneImportResult, error) {
+	var file ToneShareFile
+	if err := json.Unmarshal([]byte(content), &file); err != nil {
+		return nil, fmt.Errorf("invalid tone set file: %v", err)
+	}
+	if file.Format != toneShareFormat {
+		return nil, fmt.Errorf("not a tone set file (format %q)", file.Format)
+	}
+	if file.Version > toneShareVersion {
+		return nil, fmt.Errorf("tone set file version %d is newer than supported version %d", file.Version, toneShareVersion)
+	}
+
+	result := &toneImportResult{
+		toneSets:                  []ToneSet{},
+		warnings:                  []string{},
+		voiceCaptureWindowSeconds: file.VoiceCaptureWindowSeconds,
+	}
+	seen := map[string]bool{}
+	for i, set := range file.ToneSets {
+		if err := validateToneShareSet(set); err != nil {
+			name := strings.TrimSpace(set.Label)
+			if name == "" {
+				name = "#" + strconv.Itoa(i+1)
+			}
+			return nil, fmt.Errorf("tone set %s: %v", name, err)
+		}
+
+		key := strings.ToLower(strings.TrimSpace(set.Label))
+		if seen[key] {
+			result.warnings = append(result.warnings, fmt.Sprintf("duplicate tone set %q skipped.", set.Label))
+			continue
+		}
+		seen[key] = true
+
+		tolerance := set.Tolerance
+		if tolerance == 0 {
+			tolerance = 10
+		}
+		toneSet := ToneSet{
+			Id:                uuid.NewString(),
+			Label:             strings.TrimSpace(set.Label),
+			ATone:             set.ATone,
+			BTone:             set.BTone,
+			LongTone:          set.LongTone,
+			Tolerance:         tolerance,
+			MinDuration:       set.MinDuration,
+			CooldownSeconds:   set.CooldownSeconds,
+			DownstreamEnabled: set.DownstreamEnabled,
+			DownstreamURL:     set.DownstreamURL,
+			GeoCity:           set.GeoCity,
+			GeoLat:            set.GeoLat,
+			GeoLon:            set.GeoLon,
+			GeoRadiusMiles:    set.GeoRadiusMiles,
+			LocationContext:   set.LocationContext,
+		}
+		if toneSet.MinDuration == 0 {
+			toneSet.MinDuration = minDurationFromToneSpecs(&toneSet)
+		}
+		result.toneSets = append(result.toneSets, toneSet)
+	}
+	if result.voiceCaptureWindowSeconds > voiceCaptureMaxHoldSeconds {
+		result.voiceCaptureWindowSeconds = voiceCaptureMaxHoldSeconds
+	}
+
+	return result, nil
+}
+
+var toneShareFilenameUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// ToneExportHandler downloads a talkgroup's saved tone sets as a portable file.
+func (admin *Admin) ToneExportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := admin.GetAuthorization(r)
+	if !admin.ValidateToken(token) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	systemId, _ := strconv.ParseUint(r.URL.Query().Get("systemId"), 10, 64)
+	talkgroupId, _ := strconv.ParseUint(r.URL.Query().Get("talkgroupId"), 10, 64)
+	system, ok := admin.Controller.Systems.GetSystemById(systemId)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	talkgroup, ok := system.Talkgroups.GetTalkgroupById(talkgroupId)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	source := strings.TrimSpace(fmt.Sprintf("%s / %s", system.Label, talkgroup.Label))
+	b, err := json.MarshalIndent(newToneShareFile(talkgroup, source), "", "  ")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	filename := toneShareFilenameUnsafe.ReplaceAllString(talkgroup.Label, "-")
+	if filename == "" || filename == "-" {
+		filename = fmt.Sprintf("talkgroup-%d", talkgroup.TalkgroupRef)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-tone-sets.json"`, filename))
+	w.Write(b)
+}
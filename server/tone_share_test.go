@@ -0,0 +1,83 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestToneShareRoundTrip(t *testing.T) {
+	cooldown := uint(90)
+	talkgroup := &Talkgroup{
+		Label:                     "Fire Dispatch",
+		VoiceCaptureWindowSeconds: 20,
+		ToneSets: []ToneSet{{
+			Id:               "local-id",
+			Label:            "Station 1",
+			ATone:            &ToneSpec{Frequency: 600, MinDuration: 0.8},
+			BTone:            &ToneSpec{Frequency: 900, MinDuration: 2.5, MaxDuration: 4},
+			Tolerance:        10,
+			CooldownSeconds:  &cooldown,
+			DownstreamURL:    "https://example.org/hook",
+			DownstreamAPIKey: "secret",
+			UserGroupIds:     []uint64{3},
+		}},
+	}
+
+	b, err := json.Marshal(newToneShareFile(talkgroup, "County / Fire Dispatch"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(b); strings.Contains(s, "secret") || strings.Contains(s, "local-id") || strings.Contains(s, "userGroupIds") {
+		t.Fatalf("exported file leaks local fields: %s", s)
+	}
+
+	result, err := ParseToneImport(string(ToneImportFormatThinline), string(b))
+	if err != nil {
+		t.Fatalf("ParseToneImport: %v", err)
+	}
+	if len(result.toneSets) != 1 || result.voiceCaptureWindowSeconds != 20 {
+		t.Fatalf("got %d tone sets, window %d", len(result.toneSets), result.voiceCaptureWindowSeconds)
+	}
+	got := result.toneSets[0]
+	if got.Id == "" || got.Id == "local-id" {
+		t.Fatalf("imported id = %q, want a fresh id", got.Id)
+	}
+	if got.BTone.MaxDuration != 4 || got.CooldownSeconds == nil || *got.CooldownSeconds != 90 || got.DownstreamURL != "https://example.org/hook" {
+		t.Fatalf("imported tone set = %+v", got)
+	}
+}
+
+func TestToneShareRejectsInvalid(t *testing.T) {
+	cases := map[string]string{
+		"not json":       `{"format":`,
+		"wrong format":   `{"format":"other","version":1,"toneSets":[]}`,
+		"newer version":  `{"format":"thinline-tone-sets","version":99,"toneSets":[]}`,
+		"no label":       `{"format":"thinline-tone-sets","version":1,"toneSets":[{"aTone":{"frequency":600}}]}`,
+		"no tones":       `{"format":"thinline-tone-sets","version":1,"toneSets":[{"label":"A"}]}`,
+		"low frequency":  `{"format":"thinline-tone-sets","version":1,"toneSets":[{"label":"A","aTone":{"frequency":12}}]}`,
+		"high frequency": `{"format":"thinline-tone-sets","version":1,"toneSets":[{"label":"A","longTone":{"frequency":9000}}]}`,
+		"max below min":  `{"format":"thinline-tone-sets","version":1,"toneSets":[{"label":"A","aTone":{"frequency":600,"minDuration":2,"maxDuration":1}}]}`,
+		"bad tolerance":  `{"format":"thinline-tone-sets","version":1,"toneSets":[{"label":"A","aTone":{"frequency":600},"tolerance":-1}]}`,
+	}
+	for name, content := range cases {
+		if _, err := ParseToneImport(string(ToneImportFormatThinline), content); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestToneShareSkipsDuplicateLabels(t *testing.T) {
+	content := `{"format":"thinline-tone-sets","version":1,"toneSets":[
+		{"label":"Station 1","aTone":{"frequency":600}},
+		{"label":"station 1","aTone":{"frequency":700}}]}`
+	result, err := ParseToneImport(string(ToneImportFormatThinline), content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.toneSets) != 1 || len(result.warnings) != 1 || result.toneSets[0].Tolerance != 10 {
+		t.Fatalf("got %d tone sets, warnings %v", len(result.toneSets), result.warnings)
+	}
+}
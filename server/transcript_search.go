@@ -0,0 +1,337 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	transcriptSearchMaxTerms = 8
+	transcriptSnippetContext = 60 // characters shown on each side of the first match
+)
+
+type TranscriptsSearchOptions struct {
+	Date        any `json:"date,omitempty"`
+	DateStop    any `json:"dateStop,omitempty"`
+	Limit       any `json:"limit,omitempty"`
+	Offset      any `json:"offset,omitempty"`
+	Search      any `json:"search,omitempty"`
+	Sort        any `json:"sort,omitempty"`
+	SystemId    any `json:"systemId,omitempty"`
+	TalkgroupId any `json:"talkgroupId,omitempty"`
+}
+
+func NewTranscriptsSearchOptions() *TranscriptsSearchOptions {
+	return &TranscriptsSearchOptions{}
+}
+
+func (searchOptions *TranscriptsSearchOptions) FromMap(m map[string]any) *TranscriptsSearchOptions {
+	switch v := m["date"].(type) {
+	case string:
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			searchOptions.Date = t
+		}
+	}
+
+	switch v := m["dateStop"].(type) {
+	case string:
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			searchOptions.DateStop = t
+		}
+	}
+
+	switch v := m["limit"].(type) {
+	case float64:
+		searchOptions.Limit = uint(v)
+	}
+
+	switch v := m["offset"].(type) {
+	case float64:
+		searchOptions.Offset = uint(v)
+	}
+
+	switch v := m["search"].(type) {
+	case string:
+		searchOptions.Search = v
+	}
+
+	switch v := m["sort"].(type) {
+	case float64:
+		searchOptions.Sort = int(v)
+	}
+
+	switch v := m["systemId"].(type) {
+	case float64:
+		searchOptions.SystemId = uint64(v)
+	}
+
+	switch v := m["talkgroupId"].(type) {
+	case float64:
+		searchOptions.TalkgroupId = uint64(v)
+	}
+
+	return searchOptions
+}
+
+// TranscriptSnippetPart is a piece of a result snippet; parts with Match set
+// are the searched words, for the client to highlight.
+type TranscriptSnippetPart struct {
+	Text  string `json:"text"`
+	Match bool   `json:"match,omitempty"`
+}
+
+type TranscriptSearchResult struct {
+	CallId         uint64                  `json:"callId"`
+	DateTime       time.Time               `json:"dateTime"`
+	SystemId       uint64                  `json:"systemId"`
+	SystemLabel    string                  `json:"systemLabel"`
+	TalkgroupId    uint64                  `json:"talkgroupId"`
+	TalkgroupLabel string                  `json:"talkgroupLabel"`
+	Snippet        []TranscriptSnippetPart `json:"snippet"`
+}
+
+type TranscriptsSearchResults struct {
+	Count   uint64                    `json:"count"`
+	HasMore bool                      `json:"hasMore"`
+	Options *TranscriptsSearchOptions `json:"options"`
+	Results []TranscriptSearchResult  `json:"results"`
+}
+
+// transcriptSearchTerms splits a search into the terms a transcript must all
+// contain: words, and "quoted phrases" kept whole.
+func transcriptSearchTerms(search string) []string {
+	terms := []string{}
+	for i, part := range strings.Split(search, `"`) {
+		if i%2 == 1 {
+			if phrase := strings.Join(strings.Fields(part), " "); phrase != "" {
+				terms = append(terms, phrase)
+			}
+			continue
+		}
+		terms = append(terms, strings.Fields(part)...)
+	}
+	if len(terms) > transcriptSearchMaxTerms {
+		terms = terms[:transcriptSearchMaxTerms]
+	}
+	return terms
+}
+
+// whereClause matches every term case-insensitively, with LIKE wildcards
+// escaped as in Logs.Search. Requiring a non-empty transcript lets Postgres
+// use the partial trigram index on transcripts.
+func (searchOptions *TranscriptsSearchOptions) whereClause(terms []string) string {
+	whereConditions := []string{`c."transcript" <> ''`}
+
+	for _, term := range terms {
+		whereConditions = append(whereConditions, fmt.Sprintf(`c."transcript" ILIKE '%%%s%%' ESCAPE '\'`, escapeSQLString(escapeLikePattern(term))))
+	}
+
+	if v, ok := searchOptions.SystemId.(uint64); ok && v > 0 {
+		whereConditions = append(whereConditions, fmt.Sprintf(`c."systemId" = %d`, v))
+	}
+	if v, ok := searchOptions.TalkgroupId.(uint64); ok && v > 0 {
+		whereConditions = append(whereConditions, fmt.Sprintf(`c."talkgroupId" = %d`, v))
+	}
+	if v, ok := searchOptions.Date.(time.Time); ok {
+		whereConditions = append(whereConditions, fmt.Sprintf(`c."timestamp" >= %d`, v.UnixMilli()))
+	}
+	if v, ok := searchOptions.DateStop.(time.Time); ok {
+		whereConditions = append(whereConditions, fmt.Sprintf(`c."timestamp" <= %d`, v.UnixMilli()))
+	}
+
+	return strings.Join(whereConditions, " AND ")
+}
+
+// SearchTranscripts finds the calls whose transcript contains every search
+// term and returns a snippet of each around the first match, newest first
+// unless sort is positive.
+func (calls *Calls) SearchTranscripts(searchOptions *TranscriptsSearchOptions, db *Database) (*TranscriptsSearchResults, error) {
+	var (
+		limit  uint = 100
+		offset uint
+		order  = "DESC"
+		search string
+	)
+
+	formatError := errorFormatter("calls", "searchTranscripts")
+
+	switch v := searchOptions.Limit.(type) {
+	case uint:
+		limit = uint(math.Min(float64(500), float64(v)))
+	}
+
+	switch v := searchOptions.Offset.(type) {
+	case uint:
+		offset = v
+	}
+
+	switch v := searchOptions.Sort.(type) {
+	case int:
+		if v > 0 {
+			order = "ASC"
+		}
+	}
+
+	switch v := searchOptions.Search.(type) {
+	case string:
+		search = v
+	}
+
+	terms := transcriptSearchTerms(search)
+
+	query := fmt.Sprintf(`SELECT c."callId", c."timestamp", c."systemId", c."talkgroupId", c."transcript", COALESCE(s."label", ''), COALESCE(t."label", '') FROM "calls" AS c LEFT JOIN "systems" AS s ON s."systemId" = c."systemId" LEFT JOIN "talkgroups" AS t ON t."talkgroupId" = c."talkgroupId" WHERE %s ORDER BY c."timestamp" %s LIMIT %d OFFSET %d`, searchOptions.whereClause(terms), order, limit+1, offset)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	rows, err := db.Sql.QueryContext(ctx, query)
+	if err != nil {
+		return nil, formatError(err, query)
+	}
+	defer rows.Close()
+
+	results := &TranscriptsSearchResults{
+		Options: searchOptions,
+		Results: []TranscriptSearchResult{},
+	}
+
+	for rows.Next() {
+		var (
+			result     TranscriptSearchResult
+			timestamp  int64
+			transcript string
+		)
+
+		if err := rows.Scan(&result.CallId, &timestamp, &result.SystemId, &result.TalkgroupId, &transcript, &result.SystemLabel, &result.TalkgroupLabel); err != nil {
+			return nil, formatError(err, query)
+		}
+
+		if uint(len(results.Results)) == limit {
+			results.HasMore = true
+			break
+		}
+
+		result.DateTime = time.UnixMilli(timestamp)
+		result.Snippet = transcriptSnippet(transcript, terms)
+		results.Results = append(results.Results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, formatError(err, query)
+	}
+
+	results.Count = uint64(offset) + uint64(len(results.Results))
+	if results.HasMore {
+		results.Count++
+	}
+
+	return results, nil
+}
+
+// transcriptSnippet cuts transcript to about transcriptSnippetContext
+// characters around the first match of any term, at word boundaries, and
+// splits it so every match in the snippet is its own part. Ellipses mark
+// where the transcript was cut.
+func transcriptSnippet(transcript string, terms []string) []TranscriptSnippetPart {
+	text := []rune(transcript)
+
+	// Lowered rune by rune so offsets in lower are offsets in text
+	lower := make([]rune, len(text))
+	for i, r := range text {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	matches := [][2]int{}
+	for _, term := range terms {
+		needle := []rune(term)
+		for i, r := range needle {
+			needle[i] = unicode.ToLower(r)
+		}
+		if len(needle) == 0 {
+			continue
+		}
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if string(lower[i:i+len(needle)]) == string(needle) {
+				matches = append(matches, [2]int{i, i + len(needle)})
+			}
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i][0] < matches[j][0] })
+
+	merged := [][2]int{}
+	for _, m := range matches {
+		if n := len(merged); n > 0 && m[0] <= merged[n-1][1] {
+			merged[n-1][1] = max(merged[n-1][1], m[1])
+			continue
+		}
+		merged = append(merged, m)
+	}
+
+	// The first match is always shown whole
+	first := [2]int{0, 0}
+	if len(merged) > 0 {
+		first = merged[0]
+	}
+	start := max(0, first[0]-transcriptSnippetContext)
+	end := min(len(text), first[1]+transcriptSnippetContext)
+	if start > 0 {
+		for start < first[0] && !unicode.IsSpace(text[start-1]) {
+			start++
+		}
+	}
+	if end < len(text) {
+		for end > first[1] && !unicode.IsSpace(text[end]) {
+			end--
+		}
+	}
+	for start < first[0] && unicode.IsSpace(text[start]) {
+		start++
+	}
+	for end > first[1] && unicode.IsSpace(text[end-1]) {
+		end--
+	}
+
+	parts := []TranscriptSnippetPart{}
+	if start > 0 {
+		parts = append(parts, TranscriptSnippetPart{Text: "…"})
+	}
+	pos := start
+	for _, m := range merged {
+		if m[1] <= start || m[0] >= end {
+			continue
+		}
+		m[0], m[1] = max(m[0], start), min(m[1], end)
+		if m[0] > pos {
+			parts = append(parts, TranscriptSnippetPart{Text: string(text[pos:m[0]])})
+		}
+		parts = append(parts, TranscriptSnippetPart{Text: string(text[m[0]:m[1]]), Match: true})
+		pos = m[1]
+	}
+	if pos < end {
+		parts = append(parts, TranscriptSnippetPart{Text: string(text[pos:end])})
+	}
+	if end < len(text) {
+		parts = append(parts, TranscriptSnippetPart{Text: "…"})
+	}
+
+	return parts
+}
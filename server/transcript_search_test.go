@@ -0,0 +1,47 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTranscriptSearchTerms(t *testing.T) {
+	got := transcriptSearchTerms(`fire  "Main   Street" 100%`)
+	if want := []string{"fire", "Main Street", "100%"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("terms = %q, want %q", got, want)
+	}
+
+	where := NewTranscriptsSearchOptions().whereClause(got)
+	if !strings.Contains(where, `ILIKE '%100\%%' ESCAPE '\'`) || !strings.Contains(where, `ILIKE '%Main Street%'`) {
+		t.Fatalf("where = %s", where)
+	}
+}
+
+func TestTranscriptSnippet(t *testing.T) {
+	parts := transcriptSnippet("Engine 4 respond to 12 Main Street for smoke, MAIN street is closed", []string{"main street"})
+	want := []TranscriptSnippetPart{
+		{Text: "Engine 4 respond to 12 "},
+		{Text: "Main Street", Match: true},
+		{Text: " for smoke, "},
+		{Text: "MAIN street", Match: true},
+		{Text: " is closed"},
+	}
+	if !reflect.DeepEqual(parts, want) {
+		t.Fatalf("parts = %+v", parts)
+	}
+
+	long := strings.Repeat("unit clear ", 20) + "Main Street" + strings.Repeat(" copy that", 20)
+	parts = transcriptSnippet(long, []string{"street", "main street"})
+	if len(parts) != 5 || parts[0].Text != "…" || parts[4].Text != "…" {
+		t.Fatalf("long parts = %+v", parts)
+	}
+	if parts[2].Text != "Main Street" || !parts[2].Match {
+		t.Fatalf("overlapping matches not merged: %+v", parts)
+	}
+	if !strings.HasPrefix(parts[1].Text, "unit ") && !strings.HasPrefix(parts[1].Text, "clear ") {
+		t.Fatalf("snippet not cut at a word: %q", parts[1].Text)
+	}
+}
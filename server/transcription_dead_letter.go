@@ -0,0 +1,262 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	transcriptionMaxAttempts    = 4
+	transcriptionRetryBaseDelay = 30 * time.Second
+	transcriptionRetryMaxDelay  = 10 * time.Minute
+)
+
+// transcriptionStatusCodePattern finds the HTTP status providers put in their
+// errors ("status 503", "API request failed with status 429").
+var transcriptionStatusCodePattern = regexp.MustCompile(`(?i)status(?: code)?:? (\d{3})`)
+
+// TranscriptionDeadLetter is a transcription job that failed for good, kept so
+// an admin can see why and re-enqueue it once the provider is fixed.
+type TranscriptionDeadLetter struct {
+	CallId         uint64   `json:"callId"`
+	SystemId       uint64   `json:"systemId"`
+	TalkgroupId    uint64   `json:"talkgroupId"`
+	SystemLabel    string   `json:"systemLabel"`
+	TalkgroupLabel string   `json:"talkgroupLabel"`
+	CallTimestamp  int64    `json:"callTimestamp"`
+	Priority       int      `json:"priority"`
+	Reasons        []string `json:"reasons"`
+	Attempts       int      `json:"attempts"`
+	LastError      string   `json:"lastError"`
+	DeadAt         int64    `json:"deadAt"`
+}
+
+// transcriptionErrorRetryable reports whether a provider error may succeed on
+// a later attempt. Client errors (bad audio, bad key, unconfigured provider)
+// fail the same way every time; anything else, including timeouts, 5xx and 429,
+// is retried.
+func transcriptionErrorRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "not configured") || strings.Contains(msg, "empty audio") {
+		return false
+	}
+	if m := transcriptionStatusCodePattern.FindStringSubmatch(msg); m != nil {
+		code, _ := strconv.Atoi(m[1])
+		if code >= 400 && code < 500 {
+			return code == http.StatusRequestTimeout || code == http.StatusTooManyRequests
+		}
+	}
+	return true
+}
+
+// transcriptionRetryDelay is the wait before retry number attempt (1-based):
+// 30s, 1m, 2m... capped at transcriptionRetryMaxDelay.
+func transcriptionRetryDelay(attempt int) time.Duration {
+	delay := transcriptionRetryBaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= transcriptionRetryMaxDelay {
+			return transcriptionRetryMaxDelay
+		}
+	}
+	return delay
+}
+
+// retryOrDeadLetter handles a failed transcription. A retryable failure with
+// attempts left is queued again after a backoff and true is returned; otherwise
+// the job is dead-lettered and the caller marks the call failed.
+func (queue *TranscriptionQueue) retryOrDeadLetter(job TranscriptionJob, err error) bool {
+	job.Attempt++
+
+	if transcriptionErrorRetryable(err) && job.Attempt < transcriptionMaxAttempts {
+		delay := transcriptionRetryDelay(job.Attempt)
+		queue.updateCallTranscriptionStatus(job.CallId, "pending")
+		queue.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("transcription of call %d failed (attempt %d of %d), retrying in %s", job.CallId, job.Attempt, transcriptionMaxAttempts, delay))
+		time.AfterFunc(delay, func() { queue.QueueJob(job) })
+		return true
+	}
+
+	if dlErr := queue.storeDeadLetter(job, err); dlErr != nil {
+		queue.controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("failed to dead-letter transcription of call %d: %v", job.CallId, dlErr))
+	} else {
+		queue.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("transcription of call %d dead-lettered after %d attempt(s): %v", job.CallId, job.Attempt, err))
+	}
+	return false
+}
+
+func (queue *TranscriptionQueue) storeDeadLetter(job TranscriptionJob, cause error) error {
+	reasons, _ := json.Marshal(job.Reasons)
+	lastError := cause.Error()
+	if len(lastError) > 500 {
+		lastError = lastError[:500]
+	}
+
+	query := `INSERT INTO "transcriptionDeadLetters" ("callId", "systemId", "talkgroupId", "priority", "reasons", "attempts", "lastError", "deadAt") VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT ("callId") DO UPDATE SET "attempts" = "transcriptionDeadLetters"."attempts" + EXCLUDED."attempts", "lastError" = EXCLUDED."lastError", "deadAt" = EXCLUDED."deadAt"`
+	_, err := queue.controller.Database.Sql.Exec(query, job.CallId, job.SystemId, job.TalkgroupId, job.Priority, string(reasons), job.Attempt, lastError, time.Now().UnixMilli())
+	return err
+}
+
+// listTranscriptionDeadLetters returns dead-lettered jobs, newest first.
+func (controller *Controller) listTranscriptionDeadLetters(limit int) ([]TranscriptionDeadLetter, error) {
+	query := `SELECT d."callId", d."systemId", d."talkgroupId", d."priority", d."reasons", d."attempts", d."lastError", d."deadAt", c."timestamp", s."label", t."label"
+		FROM "transcriptionDeadLetters" d
+		LEFT JOIN "calls" c ON c."callId" = d."callId"
+		LEFT JOIN "systems" s ON s."systemId" = d."systemId"
+		LEFT JOIN "talkgroups" t ON t."talkgroupId" = d."talkgroupId"
+		ORDER BY d."deadAt" DESC LIMIT $1`
+
+	rows, err := controller.Database.Sql.Query(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	letters := []TranscriptionDeadLetter{}
+	for rows.Next() {
+		var (
+			letter         TranscriptionDeadLetter
+			reasons        string
+			callTimestamp  sql.NullInt64
+			systemLabel    sql.NullString
+			talkgroupLabel sql.NullString
+		)
+		if err := rows.Scan(&letter.CallId, &letter.SystemId, &letter.TalkgroupId, &letter.Priority, &reasons, &letter.Attempts, &letter.LastError, &letter.DeadAt, &callTimestamp, &systemLabel, &talkgroupLabel); err != nil {
+			return nil, err
+		}
+		_ = json.Unmarshal([]byte(reasons), &letter.Reasons)
+		letter.CallTimestamp = callTimestamp.Int64
+		letter.SystemLabel = systemLabel.String
+		letter.TalkgroupLabel = talkgroupLabel.String
+		letters = append(letters, letter)
+	}
+	return letters, rows.Err()
+}
+
+// requeueTranscriptionDeadLetter reloads a dead-lettered call's audio and puts
+// it back on the transcription queue with a fresh attempt budget.
+func (controller *Controller) requeueTranscriptionDeadLetter(callId uint64) error {
+	queue := controller.TranscriptionQueue
+	if queue == nil || !controller.Options.TranscriptionConfig.Enabled {
+		return fmt.Errorf("transcription is not running")
+	}
+
+	var (
+		priority int
+		reasons  string
+	)
+	if err := controller.Database.Sql.QueryRow(`SELECT "priority", "reasons" FROM "transcriptionDeadLetters" WHERE "callId" = $1`, callId).Scan(&priority, &reasons); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("call %d is not dead-lettered", callId)
+		}
+		return err
+	}
+
+	call, err := controller.Calls.GetCall(callId)
+	if err != nil {
+		return err
+	}
+	if call == nil || len(call.Audio) == 0 || call.System == nil || call.Talkgroup == nil {
+		return fmt.Errorf("call %d has no audio", callId)
+	}
+
+	job := TranscriptionJob{
+		CallId:        call.Id,
+		Audio:         call.Audio,
+		AudioMime:     call.AudioMime,
+		OriginalAudio: call.Audio,
+		OriginalMime:  call.AudioMime,
+		SystemId:      call.System.Id,
+		TalkgroupId:   call.Talkgroup.Id,
+		Priority:      priority,
+	}
+	_ = json.Unmarshal([]byte(reasons), &job.Reasons)
+
+	if _, err := controller.Database.Sql.Exec(`DELETE FROM "transcriptionDeadLetters" WHERE "callId" = $1`, callId); err != nil {
+		return err
+	}
+	queue.updateCallTranscriptionStatus(callId, "pending")
+	queue.QueueJob(job)
+	return nil
+}
+
+// TranscriptionDeadLettersHandler lists dead-lettered transcription jobs (GET)
+// and re-enqueues them (POST {"callIds": [...]}, empty for all).
+func (admin *Admin) TranscriptionDeadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		letters, err := admin.Controller.listTranscriptionDeadLetters(500)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("failed to list dead-lettered transcriptions: %v", err)})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"jobs":  letters,
+			"count": len(letters),
+		})
+
+	case http.MethodPost:
+		var request struct {
+			CallIds []uint64 `json:"callIds"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		callIds := request.CallIds
+		if len(callIds) == 0 {
+			letters, err := admin.Controller.listTranscriptionDeadLetters(500)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("failed to list dead-lettered transcriptions: %v", err)})
+				return
+			}
+			for _, letter := range letters {
+				callIds = append(callIds, letter.CallId)
+			}
+		}
+
+		requeued := []uint64{}
+		failed := map[string]string{}
+		for _, callId := range callIds {
+			if err := admin.Controller.requeueTranscriptionDeadLetter(callId); err != nil {
+				failed[strconv.FormatUint(callId, 10)] = err.Error()
+				continue
+			}
+			requeued = append(requeued, callId)
+		}
+
+		admin.Controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("re-enqueued %d dead-lettered transcription(s), %d failed", len(requeued), len(failed)))
+
+		json.NewEncoder(w).Encode(map[string]any{
+			"success":  len(failed) == 0,
+			"requeued": requeued,
+			"failed":   failed,
+		})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTranscriptionErrorRetryable(t *testing.T) {
+	cases := map[string]bool{
+		"API request failed with status 503: upstream unavailable": true,
+		"gemini: status 429: quota (retryable)":                    true,
+		"failed to send request: context deadline exceeded":        true,
+		"API request failed with status 400: invalid audio":        false,
+		"API request failed with status 401: bad key":              false,
+		"gemini: API key not configured":                           false,
+		"gemini: empty audio":                                      false,
+	}
+	for msg, want := range cases {
+		if got := transcriptionErrorRetryable(errors.New(msg)); got != want {
+			t.Errorf("transcriptionErrorRetryable(%q) = %v, want %v", msg, got, want)
+		}
+	}
+}
+
+func TestTranscriptionRetryDelay(t *testing.T) {
+	want := []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 10 * time.Minute, 10 * time.Minute}
+	for i, w := range want {
+		if got := transcriptionRetryDelay(i + 1); got != w {
+			t.Errorf("transcriptionRetryDelay(%d) = %s, want %s", i+1, got, w)
+		}
+	}
+}
@@ -218,6 +218,8 @@ func (google *GoogleTranscription) getAudioEncoding(mimeType string) string {
 		return "MP3"
 	case "audio/ogg":
 		return "OGG_OPUS"
+	case "audio/flac":
+		return "FLAC"
 	case "audio/webm":
 		return "WEBM_OPUS"
 	default:
@@ -16,6 +16,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
@@ -36,17 +37,19 @@ type TranscriptionJob struct {
 	TalkgroupId   uint64
 	Priority      int // Higher priority processed first
 	Reasons       []string
+	Attempt       int // Failed attempts so far; see retryOrDeadLetter
 }
 
 // TranscriptionQueue manages transcription jobs with a worker pool
 type TranscriptionQueue struct {
-	jobs            chan TranscriptionJob
-	workers         int
-	provider        TranscriptionProvider
-	controller      *Controller
-	mutex           sync.Mutex
-	running         bool
-	processedCount  atomic.Uint64 // total transcriptions completed since startup
+	jobs           chan TranscriptionJob
+	workers        int
+	provider       TranscriptionProvider
+	controller     *Controller
+	mutex          sync.Mutex
+	running        bool
+	processedCount atomic.Uint64 // total transcriptions completed since startup
+	active         atomic.Int64  // jobs currently being worked on
 }
 
 // NewTranscriptionQueue creates a new transcription queue with worker pool
@@ -114,6 +117,14 @@ func NewTranscriptionQueue(controller *Controller, config TranscriptionConfig) *
 			Model:          config.CloudflareModel,
 			TimeoutSeconds: config.TimeoutSeconds,
 		})
+	case "whisper-cpp":
+		// Local whisper.cpp binary; audio stays on this server
+		queue.provider = NewWhisperCppTranscription(&WhisperCppConfig{
+			Binary:         config.WhisperCppBinary,
+			Model:          config.WhisperCppModel,
+			Threads:        config.WhisperCppThreads,
+			TimeoutSeconds: config.TimeoutSeconds,
+		})
 	case "hydra":
 		// Hydra transcription uses a separate retrieval queue, not the transcription queue
 		// This provider case should not be used, but we handle it gracefully
@@ -143,6 +154,9 @@ func NewTranscriptionQueue(controller *Controller, config TranscriptionConfig) *
 			go queue.worker(i)
 		}
 		controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("transcription queue started with %d workers using provider: %s", queue.workers, queue.provider.GetName()))
+		if prober, ok := queue.provider.(transcriptionProviderProber); ok {
+			go queue.logProviderProbe(prober)
+		}
 	} else {
 		providerName := queue.provider.GetName()
 		controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("transcription provider '%s' not available, queue will not process jobs", providerName))
@@ -152,6 +166,21 @@ func NewTranscriptionQueue(controller *Controller, config TranscriptionConfig) *
 	return queue
 }
 
+// logProviderProbe checks the provider once at startup and logs the result. The
+// workers keep running either way: a provider that is down now may be back by
+// the time calls arrive, and each job reports its own failure.
+func (queue *TranscriptionQueue) logProviderProbe(prober transcriptionProviderProber) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	name := queue.provider.GetName()
+	if route, err := prober.Probe(ctx); err != nil {
+		queue.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("transcription provider %s did not answer its health probes: %v", name, err))
+	} else {
+		queue.controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("transcription provider %s reachable (%s)", name, route))
+	}
+}
+
 // QueueJob adds a job to the transcription queue
 func (queue *TranscriptionQueue) QueueJob(job TranscriptionJob) {
 	if !queue.running {
@@ -175,6 +204,10 @@ func (queue *TranscriptionQueue) worker(workerId int) {
 			return
 		}
 
+		// Every exit from this iteration must decrement active so a restart
+		// drain can tell when the current job has finished.
+		queue.active.Add(1)
+
 		startTime := time.Now()
 
 		// Resolve system and talkgroup labels for richer log lines
@@ -217,6 +250,7 @@ func (queue *TranscriptionQueue) worker(workerId int) {
 					queue.controller.markTranscriptionSkipped(job.CallId, reason)
 					queue.updateCallTranscriptionStatus(job.CallId, "skipped")
 				}
+				queue.active.Add(-1)
 				continue
 			}
 		}
@@ -298,6 +332,7 @@ func (queue *TranscriptionQueue) worker(workerId int) {
 					"[transcription] worker %d | call %d | %s / %s | skipped tone-only in %.2fs | total #%d",
 					workerId, job.CallId, systemLabel, talkgroupLabel, duration.Seconds(), queue.processedCount.Add(1),
 				))
+				queue.active.Add(-1)
 				continue
 			}
 
@@ -325,6 +360,7 @@ func (queue *TranscriptionQueue) worker(workerId int) {
 					"[transcription] worker %d | call %d | %s / %s | skipped tone-only filter in %.2fs | total #%d",
 					workerId, job.CallId, systemLabel, talkgroupLabel, duration.Seconds(), queue.processedCount.Add(1),
 				))
+				queue.active.Add(-1)
 				continue
 			}
 		} else {
@@ -357,8 +393,12 @@ func (queue *TranscriptionQueue) worker(workerId int) {
 		}
 
 		// Transcribe audio (filtered if tones were present, original otherwise)
+		transcriptionLanguage := queue.controller.Options.TranscriptionConfig.Language
+		if promptTalkgroup != nil && promptTalkgroup.TranscriptionLanguage != "" {
+			transcriptionLanguage = promptTalkgroup.TranscriptionLanguage
+		}
 		transcriptionOpts := TranscriptionOptions{
-			Language:       queue.controller.Options.TranscriptionConfig.Language,
+			Language:       transcriptionLanguage,
 			InitialPrompt:  resolvedPrompt,
 			AudioMime:      audioMimeType,
 			SystemLabel:    systemLabel,
@@ -395,8 +435,13 @@ func (queue *TranscriptionQueue) worker(workerId int) {
 
 		if err != nil {
 			errorMsg := err.Error()
-			queue.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("transcription worker %d failed for call %d after retries: %v", workerId, job.CallId, err))
 			provider := queue.controller.Options.TranscriptionConfig.Provider
+			queue.controller.Logs.LogEventWithContext(LogLevelWarn, fmt.Sprintf("transcription worker %d failed for call %d after retries: %v", workerId, job.CallId, err), &LogContext{
+				CallId:      job.CallId,
+				SystemId:    job.SystemId,
+				TalkgroupId: job.TalkgroupId,
+				Fields:      map[string]any{"provider": provider, "usedFilteredAudio": usedFilteredAudio},
+			})
 			if provider == "whisper-api" || provider == "" {
 				queue.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("transcription debug: provider=%s, apiURL=%s, usedFilteredAudio=%v, error=%s", provider, queue.controller.Options.TranscriptionConfig.WhisperAPIURL, usedFilteredAudio, errorMsg))
 			} else {
@@ -413,7 +458,10 @@ func (queue *TranscriptionQueue) worker(workerId int) {
 				}
 			}
 
-			queue.updateCallTranscriptionStatus(job.CallId, "failed", errorMsg)
+			// Transient failures are retried with backoff; the rest are dead-lettered.
+			if !queue.retryOrDeadLetter(job, err) {
+				queue.updateCallTranscriptionStatus(job.CallId, "failed", errorMsg)
+			}
 
 			// Release the pending-tones lock so future voice calls can still attach tones.
 			// Without this, a transcription failure would permanently lock the talkgroup's
@@ -428,6 +476,7 @@ func (queue *TranscriptionQueue) worker(workerId int) {
 				queue.controller.pendingTonesMutex.Unlock()
 			}
 
+			queue.active.Add(-1)
 			continue
 		}
 
@@ -494,7 +543,7 @@ func (queue *TranscriptionQueue) worker(workerId int) {
 					if hasVoiceForTones {
 						queue.controller.DebugLogger.LogVoiceDetection(job.CallId, cleanedTranscript, true, logMsg)
 						// Save audio file labeled as voice
-						go queue.controller.DebugLogger.SaveAudioFile(job.CallId, job.Audio, job.AudioMime, "voice")
+						go queue.controller.DebugLogger.SaveAudioFile(job.CallId, call.Talkgroup, job.Audio, job.AudioMime, "voice")
 					} else {
 						queue.controller.DebugLogger.LogVoiceDetection(job.CallId, cleanedTranscript, false, "Transcription completed - rejected as not voice for tone alerts")
 					}
@@ -516,10 +565,11 @@ func (queue *TranscriptionQueue) worker(workerId int) {
 						// Check for pending tones from previous tone-only calls (from other calls)
 						attachedPending := queue.controller.checkAndAttachPendingTones(call)
 
-						if attachedPending {
-							go queue.controller.AlertEngine.TriggerToneAlerts(call)
-						} else if call.HasTones {
-							go queue.controller.AlertEngine.TriggerToneAlerts(call)
+						if attachedPending || call.HasTones {
+							queue.controller.dispatchToneAlerts(call)
+						} else {
+							// Follow-up transmission for a tone alert still holding its voice capture window
+							queue.controller.appendVoiceCapture(call)
 						}
 					}
 				} else {
@@ -646,6 +696,7 @@ func (queue *TranscriptionQueue) worker(workerId int) {
 			workerId, job.CallId, systemLabel, talkgroupLabel,
 			duration.Seconds(), result.Confidence, count,
 		))
+		queue.active.Add(-1)
 	}
 }
 
@@ -752,10 +803,19 @@ func (queue *TranscriptionQueue) processKeywords(callId uint64, systemId uint64,
 			continue
 		}
 
+		// Keyword lists routed to specific user groups only apply to their members
+		keywordListIds := make([]uint64, 0, len(pref.KeywordListIds))
+		for _, listId := range pref.KeywordListIds {
+			if list := queue.controller.KeywordListsCache.GetList(listId); list != nil && !queue.controller.userInAlertGroups(userId, list.UserGroupIds) {
+				continue
+			}
+			keywordListIds = append(keywordListIds, listId)
+		}
+
 		user := userKeywords{
 			userId:         userId,
 			keywords:       pref.Keywords,
-			keywordListIds: pref.KeywordListIds,
+			keywordListIds: keywordListIds,
 		}
 		users = append(users, user)
 	}
@@ -825,7 +885,7 @@ func (queue *TranscriptionQueue) processKeywords(callId uint64, systemId uint64,
 		// Debug log keyword matches
 		if queue.controller.DebugLogger != nil {
 			for _, match := range matches {
-				queue.controller.DebugLogger.LogKeywordMatch(callId, match.Keyword, result.Transcript)
+				queue.controller.DebugLogger.LogKeywordMatch(callId, match.Keyword, match.Mode, result.Transcript)
 			}
 		}
 
@@ -1017,6 +1077,34 @@ func (queue *TranscriptionQueue) QueueDepth() int {
 	return len(queue.jobs)
 }
 
+// transcriptionProviderProber is implemented by providers that can cheaply
+// check they are reachable without transcribing anything.
+type transcriptionProviderProber interface {
+	Probe(ctx context.Context) (string, error)
+}
+
+// ProviderStatus reports the provider name and whether it is usable: reachable
+// for providers that can be probed, otherwise configured.
+func (queue *TranscriptionQueue) ProviderStatus(ctx context.Context) (string, error) {
+	if queue.provider == nil {
+		return "", fmt.Errorf("no provider")
+	}
+	name := queue.provider.GetName()
+	if prober, ok := queue.provider.(transcriptionProviderProber); ok {
+		_, err := prober.Probe(ctx)
+		return name, err
+	}
+	if !queue.provider.IsAvailable() {
+		return name, fmt.Errorf("provider not available")
+	}
+	return name, nil
+}
+
+// ActiveJobs returns how many jobs workers are processing right now.
+func (queue *TranscriptionQueue) ActiveJobs() int64 {
+	return queue.active.Load()
+}
+
 // Stop stops the transcription queue
 func (queue *TranscriptionQueue) Stop() {
 	queue.mutex.Lock()
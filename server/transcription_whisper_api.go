@@ -17,6 +17,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -408,6 +409,61 @@ func (api *WhisperAPITranscription) IsAvailable() bool {
 	return true
 }
 
+// whisperAPIProbes are tried in order by Probe. Local Whisper servers usually
+// serve /health, but OpenAI-compatible clouds (Groq, Together...) 404 on it, so
+// the models list and an empty transcription request (which an authenticated
+// server rejects with 400) are the fallbacks.
+var whisperAPIProbes = []struct {
+	method    string
+	path      string
+	accept400 bool
+}{
+	{http.MethodGet, "/health", false},
+	{http.MethodGet, "/v1/models", false},
+	{http.MethodPost, "/v1/audio/transcriptions", true},
+}
+
+// Probe checks that the Whisper API server is reachable and accepts the API key.
+// It returns the probe that succeeded, e.g. "GET /v1/models".
+func (api *WhisperAPITranscription) Probe(ctx context.Context) (string, error) {
+	if api.baseURL == "" {
+		return "", fmt.Errorf("no server URL configured")
+	}
+
+	var lastErr error
+	for _, probe := range whisperAPIProbes {
+		route := probe.method + " " + probe.path
+
+		req, err := http.NewRequestWithContext(ctx, probe.method, api.baseURL+probe.path, nil)
+		if err != nil {
+			return "", err
+		}
+		if api.apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+api.apiKey)
+		}
+
+		resp, err := api.httpClient.Do(req)
+		if err != nil {
+			// The host itself is unreachable; the other routes would fail the same way.
+			return "", err
+		}
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+			return route, nil
+		case resp.StatusCode == http.StatusBadRequest && probe.accept400:
+			return route, nil
+		case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+			return "", fmt.Errorf("%s: API key rejected (HTTP %d)", route, resp.StatusCode)
+		}
+		lastErr = fmt.Errorf("%s: HTTP %d", route, resp.StatusCode)
+	}
+
+	return "", lastErr
+}
+
 // GetName returns the name of this transcription provider
 func (api *WhisperAPITranscription) GetName() string {
 	return fmt.Sprintf("Whisper API Server (%s)", api.baseURL)
@@ -0,0 +1,48 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWhisperAPIProbeFallsBackFromHealth(t *testing.T) {
+	var models bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/models":
+			if models {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+		case "/v1/audio/transcriptions":
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	api := NewWhisperAPITranscription(&WhisperAPIConfig{BaseURL: srv.URL, APIKey: "key"})
+
+	if route, err := api.Probe(context.Background()); err != nil || route != "POST /v1/audio/transcriptions" {
+		t.Fatalf("probe = %q, %v; want the transcription route", route, err)
+	}
+
+	models = true
+	if route, err := api.Probe(context.Background()); err != nil || route != "GET /v1/models" {
+		t.Fatalf("probe = %q, %v; want the models route", route, err)
+	}
+
+	api = NewWhisperAPITranscription(&WhisperAPIConfig{BaseURL: srv.URL, APIKey: "wrong"})
+	if _, err := api.Probe(context.Background()); err == nil {
+		t.Fatal("probe accepted a rejected API key")
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultWhisperCppBinary = "whisper-cli"
+
+// WhisperCppTranscription implements TranscriptionProvider by running a local
+// whisper.cpp binary, so call audio never leaves the server.
+type WhisperCppTranscription struct {
+	binary  string
+	model   string
+	threads int
+	timeout time.Duration
+}
+
+// WhisperCppConfig contains configuration for the local whisper.cpp provider
+type WhisperCppConfig struct {
+	Binary         string // whisper.cpp CLI; a name on PATH or a full path (default "whisper-cli")
+	Model          string // Path to a ggml model file, e.g. /opt/whisper/ggml-base.en.bin
+	Threads        int    // Threads per transcription; 0 = whisper.cpp default
+	TimeoutSeconds int    // Maximum run time per call; 0 = 300s
+}
+
+// NewWhisperCppTranscription creates a new local whisper.cpp transcription provider
+func NewWhisperCppTranscription(config *WhisperCppConfig) *WhisperCppTranscription {
+	binary := strings.TrimSpace(config.Binary)
+	if binary == "" {
+		binary = defaultWhisperCppBinary
+	}
+	timeoutSecs := config.TimeoutSeconds
+	if timeoutSecs <= 0 {
+		timeoutSecs = 300
+	}
+	return &WhisperCppTranscription{
+		binary:  binary,
+		model:   strings.TrimSpace(config.Model),
+		threads: config.Threads,
+		timeout: time.Duration(timeoutSecs) * time.Second,
+	}
+}
+
+// args builds the whisper.cpp command line. Audio is read from stdin ("-f -")
+// and only the transcript text is printed (-nt no timestamps, -np no progress).
+func (cpp *WhisperCppTranscription) args(options TranscriptionOptions) []string {
+	args := []string{"-m", cpp.model, "-f", "-", "-nt", "-np"}
+	if language := strings.TrimSpace(options.Language); language != "" {
+		args = append(args, "-l", language)
+	}
+	if cpp.threads > 0 {
+		args = append(args, "-t", strconv.Itoa(cpp.threads))
+	}
+	if prompt := strings.TrimSpace(options.InitialPrompt); prompt != "" {
+		args = append(args, "--prompt", prompt)
+	}
+	return args
+}
+
+// Transcribe converts the call to 16 kHz mono WAV and runs whisper.cpp on it
+func (cpp *WhisperCppTranscription) Transcribe(audio []byte, options TranscriptionOptions) (*TranscriptionResult, error) {
+	if err := cpp.check(); err != nil {
+		return nil, err
+	}
+
+	wavAudio, err := convertToWAV(audio)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert audio to WAV: %v", err)
+	}
+	if len(wavAudio) == 0 {
+		return nil, fmt.Errorf("whisper.cpp: empty audio after conversion")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cpp.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, cpp.binary, cpp.args(options)...)
+	cmd.Stdin = bytes.NewReader(wavAudio)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("whisper.cpp timed out after %s", cpp.timeout)
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > 300 {
+			msg = msg[len(msg)-300:]
+		}
+		return nil, fmt.Errorf("whisper.cpp failed: %v: %s", err, msg)
+	}
+
+	language := options.Language
+	if language == "" {
+		language = "auto"
+	}
+	return &TranscriptionResult{
+		Transcript: parseWhisperCppOutput(stdout.String()),
+		Confidence: 0.9,
+		Language:   language,
+	}, nil
+}
+
+// parseWhisperCppOutput joins the transcript lines printed by whisper.cpp and
+// drops its non-speech markers such as [BLANK_AUDIO].
+func parseWhisperCppOutput(out string) string {
+	var parts []string
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(strings.ReplaceAll(line, "[BLANK_AUDIO]", ""))
+		if line != "" {
+			parts = append(parts, line)
+		}
+	}
+	return strings.ToUpper(strings.Join(parts, " "))
+}
+
+// check reports a configuration problem: a missing model file or binary.
+func (cpp *WhisperCppTranscription) check() error {
+	if cpp.model == "" {
+		return fmt.Errorf("whisper.cpp model not configured")
+	}
+	if _, err := os.Stat(cpp.model); err != nil {
+		return fmt.Errorf("whisper.cpp model not configured: %v", err)
+	}
+	if _, err := exec.LookPath(cpp.binary); err != nil {
+		return fmt.Errorf("whisper.cpp binary not configured: %v", err)
+	}
+	return nil
+}
+
+// IsAvailable checks that the binary and model file are present
+func (cpp *WhisperCppTranscription) IsAvailable() bool {
+	return cpp.check() == nil
+}
+
+// Probe reports whether the binary and model are in place; nothing is run.
+func (cpp *WhisperCppTranscription) Probe(ctx context.Context) (string, error) {
+	if err := cpp.check(); err != nil {
+		return "", err
+	}
+	return "local binary and model present", nil
+}
+
+// GetName returns the name of this transcription provider
+func (cpp *WhisperCppTranscription) GetName() string {
+	return fmt.Sprintf("whisper.cpp (%s)", cpp.model)
+}
+
+// GetSupportedLanguages returns supported languages
+func (cpp *WhisperCppTranscription) GetSupportedLanguages() []string {
+	// Multilingual ggml models cover the same languages as Whisper; ".en" models are English only
+	return []string{
+		"auto", "en", "es", "fr", "de", "it", "pt", "ru", "ja", "ko", "zh",
+		"nl", "tr", "pl", "ca", "fa", "ar", "cs", "el", "fi", "he", "hi",
+		"hu", "id", "ms", "no", "ro", "sk", "sv", "uk", "vi",
+	}
+}
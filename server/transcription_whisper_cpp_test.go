@@ -0,0 +1,47 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWhisperCppArgs(t *testing.T) {
+	cpp := NewWhisperCppTranscription(&WhisperCppConfig{Model: "/models/ggml-base.bin", Threads: 4})
+
+	got := cpp.args(TranscriptionOptions{Language: "es", InitialPrompt: "Engine 5"})
+	want := []string{"-m", "/models/ggml-base.bin", "-f", "-", "-nt", "-np", "-l", "es", "-t", "4", "--prompt", "Engine 5"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("args = %q, want %q", got, want)
+	}
+
+	got = NewWhisperCppTranscription(&WhisperCppConfig{Model: "m.bin"}).args(TranscriptionOptions{})
+	want = []string{"-m", "m.bin", "-f", "-", "-nt", "-np"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("args = %q, want %q", got, want)
+	}
+}
+
+func TestParseWhisperCppOutput(t *testing.T) {
+	out := " engine 5 responding\n[BLANK_AUDIO]\n\n copy that\n"
+	if got := parseWhisperCppOutput(out); got != "ENGINE 5 RESPONDING COPY THAT" {
+		t.Fatalf("parse = %q", got)
+	}
+	if got := parseWhisperCppOutput(" [BLANK_AUDIO]\n"); got != "" {
+		t.Fatalf("blank audio parsed as %q", got)
+	}
+}
+
+func TestWhisperCppMissingModelIsPermanent(t *testing.T) {
+	cpp := NewWhisperCppTranscription(&WhisperCppConfig{Model: filepath.Join(t.TempDir(), "missing.bin")})
+
+	if cpp.IsAvailable() {
+		t.Fatal("provider available without a model file")
+	}
+	_, err := cpp.Transcribe([]byte("audio"), TranscriptionOptions{})
+	if err == nil || transcriptionErrorRetryable(err) {
+		t.Fatalf("missing model error = %v, want a permanent failure", err)
+	}
+}
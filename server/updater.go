@@ -18,31 +18,45 @@ package main
 import (
 	"archive/tar"
 	"archive/zip"
+	"bytes"
 	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/shirou/gopsutil/v4/disk"
 )
 
 const (
 	githubOwner         = "Thinline-Dynamic-Solutions"
 	githubRepo          = "ThinLineRadio"
-	githubAPIURL        = "https://api.github.com/repos/Thinline-Dynamic-Solutions/ThinLineRadio/releases/latest"
+	githubAPIURL        = "https://api.github.com/repos/Thinline-Dynamic-Solutions/ThinLineRadio/releases"
 	updateCheckInterval = 30 * time.Minute
 	updateCheckDelay    = 30 * time.Second // Wait after startup before first check
+	updateCacheTTL      = 30 * time.Minute // How long the admin UI is served a cached check result
+	updateSpaceHeadroom = 32 << 20         // Extra bytes required on top of the estimate
+
+	UpdateChannelStable = "stable"
+	UpdateChannelBeta   = "beta"
 )
 
 // GitHubRelease represents the GitHub releases API response.
 type GitHubRelease struct {
 	TagName string        `json:"tag_name"`
+	Draft   bool          `json:"draft"`
 	Assets  []GitHubAsset `json:"assets"`
 }
 
@@ -50,6 +64,7 @@ type GitHubRelease struct {
 type GitHubAsset struct {
 	Name               string `json:"name"`
 	BrowserDownloadURL string `json:"browser_download_url"`
+	Size               int64  `json:"size"`
 }
 
 // UpdateInfo is returned to callers (and the admin API) describing update status.
@@ -58,13 +73,39 @@ type UpdateInfo struct {
 	LatestVersion   string `json:"latest_version"`
 	UpdateAvailable bool   `json:"update_available"`
 	DownloadURL     string `json:"download_url,omitempty"`
+	ChecksumURL     string `json:"checksum_url,omitempty"`
+	DownloadSize    int64  `json:"download_size,omitempty"`
 	Platform        string `json:"platform"`
+	Channel         string `json:"channel"`
+	LatestStable    string `json:"latest_stable,omitempty"`
+	LatestBeta      string `json:"latest_beta,omitempty"`
+	CheckedAt       int64  `json:"checked_at"` // unix seconds when GitHub was queried
+	Cached          bool   `json:"cached"`
+
+	DiskSpace *UpdateDiskSpace `json:"disk_space,omitempty"`
+}
+
+// UpdateDiskSpace is the result of the pre-update free space check.  The
+// download lands in the temp directory; the extracted binary and the backup
+// of the current one sit beside the executable.
+type UpdateDiskSpace struct {
+	RequiredBytes    uint64 `json:"required_bytes"`
+	InstallDir       string `json:"install_dir"`
+	InstallFreeBytes uint64 `json:"install_free_bytes"`
+	TempDir          string `json:"temp_dir"`
+	TempFreeBytes    uint64 `json:"temp_free_bytes"`
+	Sufficient       bool   `json:"sufficient"`
+	Error            string `json:"error,omitempty"`
 }
 
 // Updater handles checking for and applying updates from GitHub Releases.
 type Updater struct {
 	controller *Controller
 	stopChan   chan struct{}
+	apiURL     string
+
+	cacheMutex sync.Mutex
+	lastInfo   *UpdateInfo
 }
 
 // NewUpdater creates a new Updater bound to the given controller.
@@ -72,6 +113,7 @@ func NewUpdater(controller *Controller) *Updater {
 	return &Updater{
 		controller: controller,
 		stopChan:   make(chan struct{}),
+		apiURL:     githubAPIURL,
 	}
 }
 
@@ -88,6 +130,7 @@ func (u *Updater) Start() {
 	} else {
 		log.Printf("Auto-update: enabled (checking every %s, first check in %s)", updateCheckInterval, updateCheckDelay)
 	}
+	log.Printf("Auto-update: following the %s release channel", u.channel())
 
 	go u.checkLoop()
 }
@@ -140,22 +183,57 @@ func (u *Updater) checkAndApply() {
 	log.Printf("Auto-update: new version available %s → %s", info.CurrentVersion, info.LatestVersion)
 	log.Println("Auto-update: downloading and applying update...")
 
-	if err := u.ApplyUpdate(info.DownloadURL); err != nil {
+	if err := u.ApplyUpdate(info); err != nil {
 		log.Printf("Auto-update: failed to apply update: %v", err)
 	}
 }
 
-// CheckForUpdate queries the GitHub Releases API and returns update status.
-// This is also called directly from the admin API handler.
+// CheckForUpdate queries the GitHub Releases API and returns update status
+// for the configured release channel.  A successful result is remembered for
+// CachedCheckForUpdate.
 func (u *Updater) CheckForUpdate() (*UpdateInfo, error) {
+	info, err := u.fetchUpdateInfo()
+	if err == nil {
+		u.cacheMutex.Lock()
+		cached := *info
+		u.lastInfo = &cached
+		u.cacheMutex.Unlock()
+	}
+	return info, err
+}
+
+// CachedCheckForUpdate returns the last successful check result when it is
+// younger than updateCacheTTL, otherwise (or when force is set) it queries
+// GitHub again.  Used by the admin API so a polling dashboard does not burn
+// through the GitHub rate limit.
+func (u *Updater) CachedCheckForUpdate(force bool) (*UpdateInfo, error) {
+	if !force {
+		u.cacheMutex.Lock()
+		last := u.lastInfo
+		u.cacheMutex.Unlock()
+
+		if last != nil && time.Since(time.Unix(last.CheckedAt, 0)) < updateCacheTTL {
+			info := *last
+			info.Cached = true
+			return &info, nil
+		}
+	}
+	return u.CheckForUpdate()
+}
+
+// fetchUpdateInfo performs the actual GitHub Releases API request.
+func (u *Updater) fetchUpdateInfo() (*UpdateInfo, error) {
 	client := &http.Client{Timeout: 15 * time.Second}
 
-	req, err := http.NewRequest("GET", githubAPIURL, nil)
+	req, err := http.NewRequest("GET", u.apiURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build request: %w", err)
 	}
 	req.Header.Set("User-Agent", fmt.Sprintf("ThinLineRadio/%s", Version))
 	req.Header.Set("Accept", "application/vnd.github+json")
+	if u.controller != nil && u.controller.Config != nil && u.controller.Config.GitHubToken != "" {
+		req.Header.Set("Authorization", "Bearer "+u.controller.Config.GitHubToken)
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -163,99 +241,390 @@ func (u *Updater) CheckForUpdate() (*UpdateInfo, error) {
 	}
 	defer resp.Body.Close()
 
+	if err := githubRateLimitError(resp); err != nil {
+		return nil, err
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("github API returned HTTP %d", resp.StatusCode)
 	}
 
-	var release GitHubRelease
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+	var releases []GitHubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
 		return nil, fmt.Errorf("failed to decode github response: %w", err)
 	}
 
-	latestVersion := strings.TrimPrefix(release.TagName, "v")
-	updateAvailable := latestVersion != Version && isNewerVersion(latestVersion, Version)
+	channel := u.channel()
+	stable, beta := newestReleases(releases)
 
 	info := &UpdateInfo{
-		CurrentVersion:  Version,
-		LatestVersion:   latestVersion,
-		UpdateAvailable: updateAvailable,
-		Platform:        fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+		CurrentVersion: Version,
+		LatestVersion:  Version,
+		Platform:       fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+		Channel:        channel,
+		CheckedAt:      time.Now().Unix(),
+	}
+	if stable != nil {
+		info.LatestStable = strings.TrimPrefix(stable.TagName, "v")
+	}
+	if beta != nil {
+		info.LatestBeta = strings.TrimPrefix(beta.TagName, "v")
+	}
+
+	// The beta channel follows whichever release is newest so beta users are
+	// still offered a stable release that supersedes their beta.
+	release := stable
+	if channel == UpdateChannelBeta && beta != nil && (release == nil || isNewerVersion(beta.TagName, release.TagName)) {
+		release = beta
+	}
+	if release == nil {
+		return info, nil
 	}
 
-	if updateAvailable {
+	latestVersion := strings.TrimPrefix(release.TagName, "v")
+	info.LatestVersion = latestVersion
+	info.UpdateAvailable = latestVersion != Version && isNewerVersion(latestVersion, Version)
+
+	if info.UpdateAvailable {
 		assetName := buildAssetName(latestVersion)
-		for _, asset := range release.Assets {
-			if asset.Name == assetName {
-				info.DownloadURL = asset.BrowserDownloadURL
-				break
-			}
-		}
-		if info.DownloadURL == "" {
+		asset, exact := matchReleaseAsset(release.Assets, runtime.GOOS, runtime.GOARCH, latestVersion)
+		if asset == nil {
 			return info, fmt.Errorf("update available (%s) but no matching asset found for platform %s/%s (looked for: %s)",
 				latestVersion, runtime.GOOS, runtime.GOARCH, assetName)
 		}
+		if !exact {
+			log.Printf("Auto-update: %s not in release, using %s instead", assetName, asset.Name)
+		}
+		info.DownloadURL = asset.BrowserDownloadURL
+		info.DownloadSize = asset.Size
+
+		checksumName := asset.Name + ".sha256"
+		for _, a := range release.Assets {
+			if a.Name == checksumName {
+				info.ChecksumURL = a.BrowserDownloadURL
+			}
+		}
 	}
 
 	return info, nil
 }
 
-// ApplyUpdate downloads the release at downloadURL, extracts the binary,
-// swaps it in place, and triggers a graceful restart.
-func (u *Updater) ApplyUpdate(downloadURL string) error {
+// githubRateLimitError returns a descriptive error when resp is a GitHub
+// rate-limit rejection (HTTP 403/429 with X-RateLimit-Remaining: 0).
+func githubRateLimitError(resp *http.Response) error {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		return nil
+	}
+
+	reset := "unknown"
+	if v, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		reset = time.Unix(v, 0).Local().Format(time.RFC1123)
+	}
+	return fmt.Errorf("github API rate limit exceeded (limit resets at %s); set github_token in thinline-radio.ini to raise the limit", reset)
+}
+
+// channel returns the configured release channel, defaulting to stable.
+func (u *Updater) channel() string {
+	if u.controller != nil && u.controller.Config != nil && u.controller.Config.UpdateChannel == UpdateChannelBeta {
+		return UpdateChannelBeta
+	}
+	return UpdateChannelStable
+}
+
+// ApplyUpdate downloads the release described by info, verifies its checksum
+// when one is published, extracts the binary, swaps it in place, and triggers
+// a graceful restart.
+func (u *Updater) ApplyUpdate(info *UpdateInfo) error {
+	exePath, err := u.installUpdate(info)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Auto-update: binary replaced successfully (%s → %s)", Version, exePath)
+	u.controller.Logs.LogEvent(LogLevelInfo, "Auto-update applied — restarting server")
+	u.restart(exePath)
+	return nil
+}
+
+// installUpdate downloads, verifies and swaps in the release described by
+// info, and returns the path of the replaced executable. The running process
+// keeps the old binary until it is restarted.
+func (u *Updater) installUpdate(info *UpdateInfo) (string, error) {
+	if info == nil || info.DownloadURL == "" {
+		return "", fmt.Errorf("no download URL for update")
+	}
+	downloadURL := info.DownloadURL
+
 	exePath, err := os.Executable()
 	if err != nil {
-		return fmt.Errorf("failed to resolve executable path: %w", err)
+		return "", fmt.Errorf("failed to resolve executable path: %w", err)
 	}
 	// Resolve symlinks so we get the real file path.
 	exePath, err = filepath.EvalSymlinks(exePath)
 	if err != nil {
-		return fmt.Errorf("failed to resolve symlinks on executable: %w", err)
+		return "", fmt.Errorf("failed to resolve symlinks on executable: %w", err)
+	}
+
+	if space := checkUpdateDiskSpace(info, exePath); !space.Sufficient {
+		return "", fmt.Errorf("%s", space.Error)
 	}
 
 	// Create a temp directory for the download.
 	tmpDir, err := os.MkdirTemp("", "thinline-update-*")
 	if err != nil {
-		return fmt.Errorf("failed to create temp directory: %w", err)
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
 	}
 	defer os.RemoveAll(tmpDir)
 
+	// Fetch the published checksum first so a corrupt download is caught
+	// before anything is extracted.  Older releases have no .sha256 asset.
+	expectedSHA256 := ""
+	if info.ChecksumURL != "" {
+		sum, err := fetchChecksum(info.ChecksumURL)
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch checksum: %w", err)
+		}
+		expectedSHA256 = sum
+	} else {
+		log.Println("Auto-update: warning — release has no .sha256 asset, skipping checksum verification")
+	}
+
 	// Download the release archive.
 	archivePath := filepath.Join(tmpDir, "update.archive")
 	log.Printf("Auto-update: downloading %s", downloadURL)
-	if err := downloadFile(downloadURL, archivePath); err != nil {
-		return fmt.Errorf("download failed: %w", err)
+	if err := downloadFile(downloadURL, archivePath, expectedSHA256); err != nil {
+		return "", fmt.Errorf("download failed: %w", err)
+	}
+	if expectedSHA256 != "" {
+		log.Println("Auto-update: checksum verified")
 	}
 	log.Println("Auto-update: download complete, extracting binary...")
 
-	// Extract the binary from the archive.
+	// Extract the binary from the archive.  On Unix it goes straight into a
+	// temp file beside the executable so the final swap is a same-directory
+	// rename; tmpDir is often a separate mount where os.Rename would fail.
 	newBinaryPath := filepath.Join(tmpDir, "thinline-radio-new")
+	if runtime.GOOS != "windows" {
+		if staged, err := newStagingFile(exePath); err == nil {
+			newBinaryPath = staged
+			defer os.Remove(staged)
+		} else {
+			log.Printf("Auto-update: cannot stage beside %s, extracting to temp dir: %v", exePath, err)
+		}
+	}
 	binaryName := "thinline-radio"
 	if runtime.GOOS == "windows" {
 		binaryName = "thinline-radio.exe"
 		if err := extractFromZip(archivePath, binaryName, newBinaryPath); err != nil {
-			return fmt.Errorf("zip extraction failed: %w", err)
+			return "", fmt.Errorf("zip extraction failed: %w", err)
 		}
 	} else {
 		if err := extractFromTarGz(archivePath, binaryName, newBinaryPath); err != nil {
-			return fmt.Errorf("tar.gz extraction failed: %w", err)
+			return "", fmt.Errorf("tar.gz extraction failed: %w", err)
 		}
 	}
 
 	// Make the new binary executable (no-op on Windows, harmless).
 	if err := os.Chmod(newBinaryPath, 0755); err != nil {
-		return fmt.Errorf("failed to chmod new binary: %w", err)
+		return "", fmt.Errorf("failed to chmod new binary: %w", err)
+	}
+
+	// Run the new binary before anything is backed up or replaced, so a
+	// wrong-arch or mis-tagged asset never takes the server down.
+	if err := verifyUpdateBinary(newBinaryPath, info.LatestVersion); err != nil {
+		return "", fmt.Errorf("new binary rejected: %w", err)
+	}
+	log.Printf("Auto-update: new binary reports version %s", info.LatestVersion)
+
+	// Written before the swap because on Windows installBinary does not
+	// return; the new binary confirms or rolls back (see updater_probe.go).
+	marker := &UpdateMarker{
+		PreviousVersion: Version,
+		NewVersion:      info.LatestVersion,
+		BackupPath:      exePath + ".bak",
+		AppliedAt:       time.Now().Unix(),
+	}
+	if err := writeUpdateMarker(exePath, marker); err != nil {
+		log.Printf("Auto-update: warning — could not write update marker, no automatic rollback: %v", err)
 	}
 
-	// Platform-specific swap and restart.
+	if err := u.installBinary(newBinaryPath, exePath); err != nil {
+		removeUpdateMarker(exePath)
+		return "", err
+	}
+
+	return exePath, nil
+}
+
+// PreflightDiskSpace reports whether there is room to apply info.
+func (u *Updater) PreflightDiskSpace(info *UpdateInfo) *UpdateDiskSpace {
+	exePath, err := os.Executable()
+	if err == nil {
+		exePath, err = filepath.EvalSymlinks(exePath)
+	}
+	if err != nil {
+		return &UpdateDiskSpace{Error: fmt.Sprintf("failed to resolve executable path: %v", err)}
+	}
+	return checkUpdateDiskSpace(info, exePath)
+}
+
+// checkUpdateDiskSpace estimates the space ApplyUpdate needs (the download
+// plus a copy of the current binary) and compares it against the free space
+// on the temp and install filesystems.  When both are the same filesystem
+// the install check already covers the download.
+func checkUpdateDiskSpace(info *UpdateInfo, exePath string) *UpdateDiskSpace {
+	space := &UpdateDiskSpace{
+		InstallDir: filepath.Dir(exePath),
+		TempDir:    os.TempDir(),
+	}
+
+	exe, err := os.Stat(exePath)
+	if err != nil {
+		space.Error = fmt.Sprintf("failed to stat current binary: %v", err)
+		return space
+	}
+	downloadSize := info.DownloadSize
+	if downloadSize <= 0 {
+		downloadSize = fetchContentLength(info.DownloadURL)
+	}
+	if downloadSize <= 0 {
+		// The archive is compressed, so the binary size is a safe upper bound.
+		downloadSize = exe.Size()
+	}
+
+	space.RequiredBytes = uint64(downloadSize) + uint64(exe.Size()) + updateSpaceHeadroom
+
+	installUsage, err := disk.Usage(space.InstallDir)
+	if err != nil {
+		space.Error = fmt.Sprintf("failed to read free space on %s: %v", space.InstallDir, err)
+		return space
+	}
+	space.InstallFreeBytes = installUsage.Free
+
+	tempUsage, err := disk.Usage(space.TempDir)
+	if err != nil {
+		space.Error = fmt.Sprintf("failed to read free space on %s: %v", space.TempDir, err)
+		return space
+	}
+	space.TempFreeBytes = tempUsage.Free
+
+	switch {
+	case space.InstallFreeBytes < space.RequiredBytes:
+		space.Error = fmt.Sprintf("not enough disk space to update: %s needs %s free, %s available",
+			space.InstallDir, formatBytes(int(space.RequiredBytes)), formatBytes(int(space.InstallFreeBytes)))
+	case space.TempFreeBytes < uint64(downloadSize)+updateSpaceHeadroom:
+		space.Error = fmt.Sprintf("not enough disk space to download update: %s needs %s free, %s available",
+			space.TempDir, formatBytes(int(downloadSize)+updateSpaceHeadroom), formatBytes(int(space.TempFreeBytes)))
+	default:
+		space.Sufficient = true
+	}
+	return space
+}
+
+// fetchContentLength returns the size advertised by a HEAD request to url,
+// or 0 when it is unknown.
+func fetchContentLength(url string) int64 {
+	if url == "" {
+		return 0
+	}
+	client := &http.Client{Timeout: 15 * time.Second}
+	req, err := http.NewRequest(http.MethodHead, url, nil)
+	if err != nil {
+		return 0
+	}
+	req.Header.Set("User-Agent", fmt.Sprintf("ThinLineRadio/%s", Version))
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0
+	}
+	return resp.ContentLength
+}
+
+// RollbackUpdate restores the binary saved by the last ApplyUpdate
+// (exePath + ".bak") and triggers a graceful restart.  The binary being
+// replaced becomes the new .bak, so a second rollback undoes the first.
+func (u *Updater) RollbackUpdate() (string, error) {
+	exePath, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve executable path: %w", err)
+	}
+	exePath, err = filepath.EvalSymlinks(exePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve symlinks on executable: %w", err)
+	}
+
+	backupPath := exePath + ".bak"
+	if _, err := os.Stat(backupPath); err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("no backup binary found at %s", backupPath)
+		}
+		return "", fmt.Errorf("failed to stat backup binary: %w", err)
+	}
+	if err := checkExecutable(backupPath); err != nil {
+		return "", fmt.Errorf("backup binary is not usable: %w", err)
+	}
+
+	backupVersion, err := readBinaryVersion(backupPath)
+	if err != nil {
+		log.Printf("Auto-update: could not read version of backup binary: %v", err)
+		backupVersion = "unknown"
+	}
+	log.Printf("Auto-update: rolling back %s → %s", Version, backupVersion)
+
+	// Move the backup aside first so installBinary can write the running
+	// binary to .bak without clobbering the file it is about to install.
+	rollbackPath := exePath + ".rollback"
+	if err := os.Rename(backupPath, rollbackPath); err != nil {
+		return "", fmt.Errorf("failed to stage backup binary: %w", err)
+	}
+	if err := os.Chmod(rollbackPath, 0755); err != nil {
+		log.Printf("Auto-update: warning — could not chmod backup binary: %v", err)
+	}
+
+	if err := u.installBinary(rollbackPath, exePath); err != nil {
+		if restoreErr := os.Rename(rollbackPath, backupPath); restoreErr != nil {
+			log.Printf("Auto-update: CRITICAL — failed to put backup binary back: %v", restoreErr)
+		}
+		return "", err
+	}
+
+	log.Printf("Auto-update: rollback complete (%s → %s)", Version, backupVersion)
+	u.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("Auto-update rolled back from %s to %s — restarting server", Version, backupVersion))
+	u.restart(exePath)
+	return backupVersion, nil
+}
+
+// installBinary swaps newBinaryPath into exePath, keeping the current binary
+// as exePath + ".bak".  On Windows the swap (and the restart) is performed by
+// a detached script after this process exits, so this call does not return.
+func (u *Updater) installBinary(newBinaryPath, exePath string) error {
 	if runtime.GOOS == "windows" {
 		// On Windows ALL file operations (backup, swap, restart) are handled by a
 		// detached PowerShell script AFTER the Go process exits and releases the
 		// exe file lock.  We must NOT touch the current exe here — if anything
 		// goes wrong before os.Exit the old binary stays intact.
+		u.drain()
 		return applyUpdateWindows(newBinaryPath, exePath)
 	}
 
-	// Unix: back up then atomically rename the new binary into place.
+	// Unix: the final rename must stay on one filesystem, so copy the new
+	// binary next to exePath first when it lives elsewhere.
+	if filepath.Dir(newBinaryPath) != filepath.Dir(exePath) {
+		staged, err := stageBinary(newBinaryPath, exePath)
+		if err != nil {
+			return fmt.Errorf("failed to stage new binary: %w", err)
+		}
+		defer os.Remove(staged)
+		newBinaryPath = staged
+	}
+
+	// Back up then atomically rename the new binary into place.
 	backupPath := exePath + ".bak"
 	if err := os.Rename(exePath, backupPath); err != nil {
 		return fmt.Errorf("failed to backup current binary: %w", err)
@@ -270,15 +639,81 @@ func (u *Updater) ApplyUpdate(downloadURL string) error {
 		return fmt.Errorf("failed to replace binary (backup restored): %w", err)
 	}
 
-	// Re-apply executable permission on the final path.  Permissions survive
-	// os.Rename on the same filesystem, but on systems where /tmp is a separate
-	// mount (tmpfs, noexec, etc.) the mode bits can be lost during the move.
+	// Re-apply executable permission on the final path in case the staging
+	// file was created under a restrictive umask.
 	if err := os.Chmod(exePath, 0755); err != nil {
 		log.Printf("Auto-update: warning — could not chmod new binary: %v", err)
 	}
 
-	log.Printf("Auto-update: binary replaced successfully (%s → %s)", Version, exePath)
-	u.controller.Logs.LogEvent(LogLevelInfo, "Auto-update applied — restarting server")
+	return nil
+}
+
+// newStagingFile creates an empty temp file in the same directory as exePath.
+func newStagingFile(exePath string) (string, error) {
+	f, err := os.CreateTemp(filepath.Dir(exePath), "."+filepath.Base(exePath)+".new-*")
+	if err != nil {
+		return "", err
+	}
+	name := f.Name()
+	if err := f.Close(); err != nil {
+		os.Remove(name)
+		return "", err
+	}
+	return name, nil
+}
+
+// stageBinary copies srcPath into a new staging file beside exePath and
+// returns its path.  The copy is synced so a crash after the rename cannot
+// leave a truncated executable behind.
+func stageBinary(srcPath, exePath string) (string, error) {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	dst, err := os.CreateTemp(filepath.Dir(exePath), "."+filepath.Base(exePath)+".new-*")
+	if err != nil {
+		return "", err
+	}
+	name := dst.Name()
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(name)
+		return "", err
+	}
+	if err := dst.Sync(); err != nil {
+		dst.Close()
+		os.Remove(name)
+		return "", err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(name)
+		return "", err
+	}
+	if err := os.Chmod(name, 0755); err != nil {
+		os.Remove(name)
+		return "", err
+	}
+	return name, nil
+}
+
+// drain lets in-flight call uploads and processing finish before the process
+// goes away, bounded by restart_drain_timeout.
+func (u *Updater) drain() {
+	timeout := time.Duration(u.controller.Config.RestartDrainTimeout) * time.Second
+	if timeout <= 0 {
+		return
+	}
+	log.Printf("Auto-update: draining in-flight calls (up to %s)...", timeout)
+	if u.controller.DrainForRestart(timeout) {
+		log.Println("Auto-update: drain complete")
+	}
+}
+
+// restart launches the binary at exePath and shuts the current process down.
+func (u *Updater) restart(exePath string) {
+	u.drain()
 
 	// Spawn the new binary as a fully detached process before shutting down.
 	// This guarantees the server restarts even when not managed by systemd
@@ -293,7 +728,6 @@ func (u *Updater) ApplyUpdate(downloadURL string) error {
 
 	// Give logs a moment to flush, then signal graceful shutdown.
 	time.AfterFunc(1*time.Second, triggerRestart)
-	return nil
 }
 
 // ── helpers ──────────────────────────────────────────────────────────────────
@@ -304,15 +738,70 @@ func (u *Updater) ApplyUpdate(downloadURL string) error {
 //	thinline-radio-{GOOS}-{GOARCH}-v{VERSION}.tar.gz   (Unix)
 //	thinline-radio-{GOOS}-{GOARCH}-v{VERSION}.zip      (Windows)
 func buildAssetName(version string) string {
-	ext := "tar.gz"
-	if runtime.GOOS == "windows" {
-		ext = "zip"
+	return assetFileName(runtime.GOOS, runtime.GOARCH, version)
+}
+
+func assetFileName(goos, goarch, version string) string {
+	return fmt.Sprintf("thinline-radio-%s-%s-v%s.%s", goos, goarch, version, assetExtension(goos))
+}
+
+func assetExtension(goos string) string {
+	if goos == "windows" {
+		return "zip"
 	}
-	return fmt.Sprintf("thinline-radio-%s-%s-v%s.%s", runtime.GOOS, runtime.GOARCH, version, ext)
+	return "tar.gz"
 }
 
-// downloadFile streams a URL to a local file.
-func downloadFile(url, destPath string) error {
+// matchReleaseAsset picks the release archive for goos/goarch. The exact name
+// from assetFileName wins; failing that, any archive with the right extension
+// whose name has goos and goarch as separate tokens is accepted (so a renamed
+// or "-musl" build is still found), preferring one that names the version.
+// Tokens are compared whole, which keeps "arm" from matching "arm64".
+func matchReleaseAsset(assets []GitHubAsset, goos, goarch, version string) (asset *GitHubAsset, exact bool) {
+	want := assetFileName(goos, goarch, version)
+	ext := "." + assetExtension(goos)
+
+	var fallback *GitHubAsset
+	for i := range assets {
+		a := &assets[i]
+		if a.Name == want {
+			return a, true
+		}
+		if !strings.HasSuffix(a.Name, ext) {
+			continue
+		}
+
+		hasOS, hasArch, hasVersion := false, false, false
+		for _, token := range strings.FieldsFunc(strings.TrimSuffix(a.Name, ext), func(r rune) bool {
+			return r == '-' || r == '_'
+		}) {
+			switch strings.TrimPrefix(token, "v") {
+			case goos:
+				hasOS = true
+			case goarch:
+				hasArch = true
+			case version:
+				hasVersion = true
+			}
+		}
+		if !hasOS || !hasArch {
+			continue
+		}
+		if fallback == nil || hasVersion {
+			fallback = a
+			if hasVersion {
+				break
+			}
+		}
+	}
+
+	return fallback, false
+}
+
+// downloadFile streams a URL to a local file.  When expectedSHA256 is not
+// empty the SHA-256 of the downloaded bytes must match it, otherwise the file
+// is removed and an error is returned.
+func downloadFile(url, destPath, expectedSHA256 string) error {
 	client := &http.Client{Timeout: 10 * time.Minute}
 	resp, err := client.Get(url)
 	if err != nil {
@@ -328,10 +817,140 @@ func downloadFile(url, destPath string) error {
 	if err != nil {
 		return err
 	}
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(f, hash), resp.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(destPath)
+		return err
+	}
+
+	if expectedSHA256 != "" {
+		actual := hex.EncodeToString(hash.Sum(nil))
+		if !strings.EqualFold(actual, expectedSHA256) {
+			os.Remove(destPath)
+			return fmt.Errorf("checksum mismatch (expected %s, got %s)", expectedSHA256, actual)
+		}
+	}
+
+	return nil
+}
+
+// fetchChecksum downloads a .sha256 asset and returns the hex digest it
+// contains.  Both the bare digest and the sha256sum "<digest>  <file>" format
+// are accepted.
+func fetchChecksum(url string) (string, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("checksum download returned HTTP %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return "", err
+	}
+
+	return parseChecksum(string(body))
+}
+
+// parseChecksum extracts the hex SHA-256 digest from the contents of a
+// .sha256 file.
+func parseChecksum(content string) (string, error) {
+	fields := strings.Fields(content)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("checksum file is empty")
+	}
+	sum := strings.ToLower(fields[0])
+	if len(sum) != sha256.Size*2 {
+		return "", fmt.Errorf("invalid checksum %q", fields[0])
+	}
+	if _, err := hex.DecodeString(sum); err != nil {
+		return "", fmt.Errorf("invalid checksum %q", fields[0])
+	}
+	return sum, nil
+}
+
+// checkExecutable returns an error unless path is a non-empty regular file
+// that starts with a native executable header (ELF, Mach-O or PE).
+func checkExecutable(path string) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !fi.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", path)
+	}
+	if fi.Size() == 0 {
+		return fmt.Errorf("%s is empty", path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
 	defer f.Close()
 
-	_, err = io.Copy(f, resp.Body)
-	return err
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return fmt.Errorf("failed to read header of %s: %w", path, err)
+	}
+
+	switch {
+	case bytes.Equal(header, []byte{0x7f, 'E', 'L', 'F'}):
+	case bytes.Equal(header[:2], []byte{'M', 'Z'}):
+	case bytes.Equal(header, []byte{0xfe, 0xed, 0xfa, 0xce}),
+		bytes.Equal(header, []byte{0xfe, 0xed, 0xfa, 0xcf}),
+		bytes.Equal(header, []byte{0xce, 0xfa, 0xed, 0xfe}),
+		bytes.Equal(header, []byte{0xcf, 0xfa, 0xed, 0xfe}),
+		bytes.Equal(header, []byte{0xca, 0xfe, 0xba, 0xbe}):
+	default:
+		return fmt.Errorf("%s is not a recognised executable", path)
+	}
+	return nil
+}
+
+// readBinaryVersion runs the binary at path with -version and returns the
+// version it reports.
+func readBinaryVersion(path string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, path, "-version").Output()
+	if err != nil {
+		return "", err
+	}
+
+	version := strings.TrimPrefix(strings.TrimSpace(string(out)), "v")
+	if version == "" {
+		return "", fmt.Errorf("binary reported an empty version")
+	}
+	return version, nil
+}
+
+// verifyUpdateBinary checks that the binary at path runs on this machine and
+// reports the version the release was published as.
+func verifyUpdateBinary(path string, expectedVersion string) error {
+	if err := checkExecutable(path); err != nil {
+		return err
+	}
+
+	version, err := readBinaryVersion(path)
+	if err != nil {
+		return fmt.Errorf("it does not run on %s/%s: %w", runtime.GOOS, runtime.GOARCH, err)
+	}
+	if expected := strings.TrimPrefix(expectedVersion, "v"); version != expected {
+		return fmt.Errorf("it reports version %s, expected %s", version, expected)
+	}
+	return nil
 }
 
 // extractFromTarGz finds binaryName inside a .tar.gz and writes it to destPath.
@@ -401,6 +1020,31 @@ func extractFromZip(archivePath, binaryName, destPath string) error {
 	return fmt.Errorf("binary %q not found in zip", binaryName)
 }
 
+// isPreReleaseVersion reports whether version carries a pre-release suffix
+// (e.g. "7.0.0-beta9.7.22").
+func isPreReleaseVersion(version string) bool {
+	return strings.Contains(strings.TrimPrefix(version, "v"), "-")
+}
+
+// newestReleases returns the newest stable release and the newest
+// pre-release from releases, skipping drafts.  Either may be nil.
+func newestReleases(releases []GitHubRelease) (stable, beta *GitHubRelease) {
+	for i := range releases {
+		r := &releases[i]
+		if r.Draft || r.TagName == "" {
+			continue
+		}
+		if isPreReleaseVersion(r.TagName) {
+			if beta == nil || isNewerVersion(r.TagName, beta.TagName) {
+				beta = r
+			}
+		} else if stable == nil || isNewerVersion(r.TagName, stable.TagName) {
+			stable = r
+		}
+	}
+	return stable, beta
+}
+
 // isNewerVersion returns true if candidate is strictly newer than current.
 // Handles standard semver and pre-release suffixes (e.g. "7.0.0-beta9.6.1").
 // A stable release (no pre-release) is considered newer than a beta with the
@@ -448,7 +1092,7 @@ func isNewerVersion(candidate, current string) bool {
 		return false // beta doesn't beat stable with same core
 	}
 
-	// Both pre-release — compare numerically segment by segment.
+	// Both pre-release — compare by semver precedence, numbers numerically.
 	// e.g. "beta9.7.10" must beat "beta9.7.8"; string comparison gets this wrong.
 	if !candidateIsStable && !currentIsStable {
 		return comparePreRelease(cParts[1], rParts[1])
@@ -457,41 +1101,53 @@ func isNewerVersion(candidate, current string) bool {
 	return false // identical
 }
 
-// comparePreRelease compares two pre-release strings (e.g. "beta9.7.10" vs "beta9.7.8")
-// by stripping any leading alphabetic prefix then comparing each dot-separated segment numerically.
+// comparePreRelease reports whether pre-release a has higher precedence than
+// b, following semver: dot-separated identifiers are compared left to right,
+// numeric ones numerically, others lexically, numeric below alphanumeric, and
+// a shorter list below a longer one it is a prefix of. Identifiers that mix
+// letters and digits ("beta9", "rc10") are further split into letter and digit
+// runs so the numbers in them also compare numerically.
 func comparePreRelease(a, b string) bool {
-	stripAlpha := func(s string) string {
-		i := 0
-		for i < len(s) && !(s[i] >= '0' && s[i] <= '9') {
-			i++
+	aIds, bIds := preReleaseIdentifiers(a), preReleaseIdentifiers(b)
+
+	for i := 0; i < len(aIds) && i < len(bIds); i++ {
+		x, y := aIds[i], bIds[i]
+		xNum, yNum := isNumericIdentifier(x), isNumericIdentifier(y)
+		switch {
+		case xNum && yNum:
+			if x, y := parseVersionInt(x), parseVersionInt(y); x != y {
+				return x > y
+			}
+		case xNum != yNum:
+			return yNum
+		case x != y:
+			return x > y
 		}
-		return s[i:]
-	}
-
-	aParts := strings.Split(stripAlpha(a), ".")
-	bParts := strings.Split(stripAlpha(b), ".")
-
-	maxLen := len(aParts)
-	if len(bParts) > maxLen {
-		maxLen = len(bParts)
 	}
+	return len(aIds) > len(bIds)
+}
 
-	for i := 0; i < maxLen; i++ {
-		aVal, bVal := 0, 0
-		if i < len(aParts) {
-			aVal = parseVersionInt(aParts[i])
-		}
-		if i < len(bParts) {
-			bVal = parseVersionInt(bParts[i])
-		}
-		if aVal > bVal {
-			return true
-		}
-		if aVal < bVal {
-			return false
+// preReleaseIdentifiers splits "beta9.7.22" into [beta 9 7 22].
+func preReleaseIdentifiers(s string) []string {
+	ids := []string{}
+	for _, part := range strings.Split(s, ".") {
+		start := 0
+		for i := 1; i <= len(part); i++ {
+			if i == len(part) || isVersionDigit(part[i]) != isVersionDigit(part[i-1]) {
+				ids = append(ids, part[start:i])
+				start = i
+			}
 		}
 	}
-	return false
+	return ids
+}
+
+func isNumericIdentifier(s string) bool {
+	return s != "" && isVersionDigit(s[0])
+}
+
+func isVersionDigit(c byte) bool {
+	return c >= '0' && c <= '9'
 }
 
 func parseVersionInt(s string) int {
@@ -0,0 +1,68 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+)
+
+// Exit statuses of -check_update and -apply_update, for deployment scripts.
+const (
+	updateExitUpToDate  = 0
+	updateExitError     = 1
+	updateExitAvailable = 2
+)
+
+// runUpdateCommand checks for a release on the configured channel without
+// starting the server and, with apply, installs it. The running server keeps
+// its binary until it is restarted; on its first start the new binary
+// confirms the update or rolls back like an update from the admin API.
+func runUpdateCommand(updater *Updater, apply bool, out io.Writer) int {
+	info, err := updater.CheckForUpdate()
+	if err != nil {
+		fmt.Fprintf(out, "error: %v\n", err)
+		return updateExitError
+	}
+
+	fmt.Fprintf(out, "current version: %s\n", info.CurrentVersion)
+	fmt.Fprintf(out, "latest version: %s\n", info.LatestVersion)
+	fmt.Fprintf(out, "channel: %s\n", info.Channel)
+	fmt.Fprintf(out, "platform: %s\n", info.Platform)
+	fmt.Fprintf(out, "update available: %t\n", info.UpdateAvailable)
+
+	if !info.UpdateAvailable {
+		return updateExitUpToDate
+	}
+	if !apply {
+		return updateExitAvailable
+	}
+
+	if runtime.GOOS == "windows" {
+		fmt.Fprintln(out, "error: -apply_update is not supported on Windows, where the running server locks its binary; use the admin API instead")
+		return updateExitError
+	}
+
+	exePath, err := updater.installUpdate(info)
+	if err != nil {
+		fmt.Fprintf(out, "error: %v\n", err)
+		return updateExitError
+	}
+
+	fmt.Fprintf(out, "installed %s to %s; restart the server to run it\n", info.LatestVersion, exePath)
+	return updateExitUpToDate
+}
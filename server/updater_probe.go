@@ -0,0 +1,205 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Post-update health probe.
+//
+// ApplyUpdate cannot tell whether the binary it installed will come up, so
+// the check happens in the new process, with a marker file as the handshake:
+//
+//  1. Before swapping binaries, ApplyUpdate writes <exe>.update-pending with
+//     the previous and new versions and the .bak path.
+//  2. Every start calls beginUpdateProbe before anything else. A pending
+//     marker for the running version has its start count bumped and saved,
+//     so a start that crashes leaves its count behind.
+//  3. When the controller has started, the HTTP port is bound and the process
+//     has stayed up for updateProbeSettle, confirmUpdateHealthy deletes the
+//     marker. The update is then final.
+//  4. Whatever restarts the process after a crash is the watchdog: systemd
+//     (Restart=on-failure or always) or, without systemd, the monitor started
+//     by spawnNewProcess, which retries a crashed binary a few times. When a
+//     start finds updateProbeMaxStarts unconfirmed starts already recorded,
+//     it restores the .bak binary, removes the marker and re-execs into the
+//     previous version.
+//
+// A marker older than updateProbeWindow, or written for another version, is
+// stale and removed without rolling back.
+const (
+	updateMarkerSuffix   = ".update-pending"
+	updateProbeMaxStarts = 2                // unconfirmed starts tolerated before rolling back
+	updateProbeSettle    = 60 * time.Second // uptime after bind that counts as a healthy start
+	updateProbeWindow    = 30 * time.Minute // markers older than this are ignored
+)
+
+// UpdateMarker records an update whose binary has not yet proven it starts.
+type UpdateMarker struct {
+	PreviousVersion string `json:"previous_version"`
+	NewVersion      string `json:"new_version"`
+	BackupPath      string `json:"backup_path"`
+	AppliedAt       int64  `json:"applied_at"` // unix seconds
+	Starts          int    `json:"starts"`     // starts of the new binary so far
+}
+
+type updateProbeAction int
+
+const (
+	updateProbeNone     updateProbeAction = iota // no marker, or a stale one to remove
+	updateProbeWatch                             // count this start and wait for it to settle
+	updateProbeRollback                          // crash loop: restore the backup
+)
+
+func updateMarkerPath(exePath string) string {
+	return exePath + updateMarkerSuffix
+}
+
+func writeUpdateMarker(exePath string, marker *UpdateMarker) error {
+	b, err := json.Marshal(marker)
+	if err != nil {
+		return err
+	}
+	path := updateMarkerPath(exePath)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// readUpdateMarker returns nil and no error when there is no marker.
+func readUpdateMarker(exePath string) (*UpdateMarker, error) {
+	b, err := os.ReadFile(updateMarkerPath(exePath))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	marker := &UpdateMarker{}
+	if err := json.Unmarshal(b, marker); err != nil {
+		return nil, fmt.Errorf("corrupt update marker: %w", err)
+	}
+	return marker, nil
+}
+
+func removeUpdateMarker(exePath string) {
+	if err := os.Remove(updateMarkerPath(exePath)); err != nil && !os.IsNotExist(err) {
+		log.Printf("Auto-update: failed to remove update marker: %v", err)
+	}
+}
+
+// evaluateUpdateMarker decides what a start of version should do with marker.
+func evaluateUpdateMarker(marker *UpdateMarker, version string, now time.Time) updateProbeAction {
+	if marker == nil || marker.NewVersion != version {
+		return updateProbeNone
+	}
+	if now.Sub(time.Unix(marker.AppliedAt, 0)) > updateProbeWindow {
+		return updateProbeNone
+	}
+	if marker.Starts >= updateProbeMaxStarts {
+		return updateProbeRollback
+	}
+	return updateProbeWatch
+}
+
+// beginUpdateProbe runs first thing in main. It returns the executable path
+// to confirm once the server is healthy, or "" when there is nothing to
+// confirm. On a crash loop it rolls back and does not return.
+func beginUpdateProbe() string {
+	exePath, err := os.Executable()
+	if err == nil {
+		exePath, err = filepath.EvalSymlinks(exePath)
+	}
+	if err != nil {
+		return ""
+	}
+
+	marker, err := readUpdateMarker(exePath)
+	if err != nil {
+		log.Printf("Auto-update: ignoring update marker: %v", err)
+		removeUpdateMarker(exePath)
+		return ""
+	}
+
+	switch evaluateUpdateMarker(marker, Version, time.Now()) {
+	case updateProbeWatch:
+		marker.Starts++
+		if err := writeUpdateMarker(exePath, marker); err != nil {
+			log.Printf("Auto-update: failed to record start in update marker: %v", err)
+		}
+		log.Printf("Auto-update: verifying update %s → %s (start %d)", marker.PreviousVersion, marker.NewVersion, marker.Starts)
+		return exePath
+
+	case updateProbeRollback:
+		log.Printf("Auto-update: %s failed to start %d times since the update, rolling back to %s", marker.NewVersion, marker.Starts, marker.PreviousVersion)
+		if err := restoreBackupBinary(exePath, marker.BackupPath); err != nil {
+			// Keep the marker so the next start tries again.
+			log.Printf("Auto-update: CRITICAL — automatic rollback failed: %v", err)
+			os.Exit(1)
+		}
+		removeUpdateMarker(exePath)
+		if err := reexecBinary(exePath); err != nil {
+			log.Printf("Auto-update: rolled back but could not start %s: %v", exePath, err)
+		}
+		os.Exit(1)
+
+	default:
+		if marker != nil {
+			removeUpdateMarker(exePath)
+		}
+	}
+	return ""
+}
+
+// confirmUpdateHealthy clears the update marker once the process has been up
+// for updateProbeSettle. It is called after the controller has started and
+// the HTTP port is bound.
+func confirmUpdateHealthy(exePath string) {
+	if exePath == "" {
+		return
+	}
+	time.AfterFunc(updateProbeSettle, func() {
+		removeUpdateMarker(exePath)
+		log.Printf("Auto-update: version %s started cleanly, update confirmed", Version)
+	})
+}
+
+// restoreBackupBinary puts backupPath back at exePath. The binary that failed
+// is kept as exePath + ".failed" so it can be inspected.
+func restoreBackupBinary(exePath, backupPath string) error {
+	if backupPath == "" {
+		backupPath = exePath + ".bak"
+	}
+	if err := checkExecutable(backupPath); err != nil {
+		return fmt.Errorf("backup binary is not usable: %w", err)
+	}
+
+	rollbackPath := exePath + ".rollback"
+	if err := os.Rename(backupPath, rollbackPath); err != nil {
+		return fmt.Errorf("failed to stage backup binary: %w", err)
+	}
+	failedPath := exePath + ".failed"
+	if err := os.Rename(exePath, failedPath); err != nil {
+		os.Rename(rollbackPath, backupPath)
+		return fmt.Errorf("failed to move failed binary aside: %w", err)
+	}
+	if err := os.Rename(rollbackPath, exePath); err != nil {
+		if restoreErr := os.Rename(failedPath, exePath); restoreErr != nil {
+			log.Printf("Auto-update: CRITICAL — failed to put binary back: %v", restoreErr)
+		}
+		os.Rename(rollbackPath, backupPath)
+		return fmt.Errorf("failed to install backup binary: %w", err)
+	}
+	if err := os.Chmod(exePath, 0755); err != nil {
+		log.Printf("Auto-update: warning — could not chmod restored binary: %v", err)
+	}
+	log.Printf("Auto-update: restored %s, failed binary kept at %s", exePath, failedPath)
+	return nil
+}
@@ -0,0 +1,384 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDownloadFileChecksum(t *testing.T) {
+	payload := []byte("thinline-radio release archive")
+	sum := sha256.Sum256(payload)
+	good := hex.EncodeToString(sum[:])
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(payload)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+
+	dest := filepath.Join(dir, "match.archive")
+	if err := downloadFile(srv.URL, dest, good); err != nil {
+		t.Fatalf("matching checksum: %v", err)
+	}
+	if b, err := os.ReadFile(dest); err != nil || string(b) != string(payload) {
+		t.Fatalf("downloaded content mismatch: %q %v", b, err)
+	}
+
+	dest = filepath.Join(dir, "mismatch.archive")
+	bad := hex.EncodeToString(make([]byte, sha256.Size))
+	if err := downloadFile(srv.URL, dest, bad); err == nil {
+		t.Fatal("mismatching checksum must fail")
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Fatal("corrupt download must be removed")
+	}
+
+	dest = filepath.Join(dir, "unverified.archive")
+	if err := downloadFile(srv.URL, dest, ""); err != nil {
+		t.Fatalf("no checksum: %v", err)
+	}
+}
+
+func TestParseChecksum(t *testing.T) {
+	sum := sha256.Sum256([]byte("x"))
+	hexSum := hex.EncodeToString(sum[:])
+
+	for _, in := range []string{
+		hexSum,
+		hexSum + "\n",
+		hexSum + "  thinline-radio-linux-amd64-v1.0.0.tar.gz\n",
+	} {
+		got, err := parseChecksum(in)
+		if err != nil || got != hexSum {
+			t.Fatalf("in=%q got=(%q,%v)", in, got, err)
+		}
+	}
+
+	for _, in := range []string{"", "not-a-checksum", "<html>error</html>"} {
+		if _, err := parseChecksum(in); err == nil {
+			t.Fatalf("in=%q must fail", in)
+		}
+	}
+}
+
+func TestNewestReleases(t *testing.T) {
+	releases := []GitHubRelease{
+		{TagName: "v7.0.0-beta9.7.22"},
+		{TagName: "v26.07.23"},
+		{TagName: "v26.08.1-beta1"},
+		{TagName: "v26.06.2"},
+		{TagName: "v27.01.1", Draft: true},
+	}
+	stable, beta := newestReleases(releases)
+	if stable == nil || stable.TagName != "v26.07.23" {
+		t.Fatalf("stable: got %+v", stable)
+	}
+	if beta == nil || beta.TagName != "v26.08.1-beta1" {
+		t.Fatalf("beta: got %+v", beta)
+	}
+
+	stable, beta = newestReleases([]GitHubRelease{{TagName: "v1.0.0-rc1"}})
+	if stable != nil || beta == nil {
+		t.Fatalf("beta-only list: stable=%+v beta=%+v", stable, beta)
+	}
+}
+
+func TestCheckForUpdateRateLimited(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "1893456000")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	u := &Updater{controller: &Controller{Config: &Config{}}, apiURL: srv.URL}
+	_, err := u.CheckForUpdate()
+	if err == nil || !strings.Contains(err.Error(), "rate limit") {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), time.Unix(1893456000, 0).Local().Format(time.RFC1123)) {
+		t.Fatalf("error should include the reset time: %v", err)
+	}
+}
+
+func TestCheckForUpdateWithToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`[{"tag_name":"v` + Version + `","assets":[]}]`))
+	}))
+	defer srv.Close()
+
+	u := &Updater{controller: &Controller{Config: &Config{GitHubToken: "secret-token"}}, apiURL: srv.URL}
+	info, err := u.CheckForUpdate()
+	if err != nil {
+		t.Fatalf("authenticated check: %v", err)
+	}
+	if info.UpdateAvailable || info.LatestVersion != Version {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+}
+
+func TestMatchReleaseAsset(t *testing.T) {
+	assets := func(names ...string) []GitHubAsset {
+		list := make([]GitHubAsset, len(names))
+		for i, name := range names {
+			list[i] = GitHubAsset{Name: name, BrowserDownloadURL: "https://example.invalid/" + name}
+		}
+		return list
+	}
+
+	cases := []struct {
+		name   string
+		assets []GitHubAsset
+		goos   string
+		goarch string
+		want   string
+		exact  bool
+	}{
+		{"exact", assets("thinline-radio-linux-arm-v1.2.0.tar.gz", "thinline-radio-linux-arm64-v1.2.0.tar.gz"), "linux", "arm64", "thinline-radio-linux-arm64-v1.2.0.tar.gz", true},
+		{"exact arm", assets("thinline-radio-linux-arm64-v1.2.0.tar.gz", "thinline-radio-linux-arm-v1.2.0.tar.gz"), "linux", "arm", "thinline-radio-linux-arm-v1.2.0.tar.gz", true},
+		{"fuzzy arm64 ignores arm", assets("thinline-radio-linux-arm-musl-v1.2.0.tar.gz", "thinline-radio-linux-arm64-musl-v1.2.0.tar.gz"), "linux", "arm64", "thinline-radio-linux-arm64-musl-v1.2.0.tar.gz", false},
+		{"fuzzy arm ignores arm64", assets("thinline-radio-linux-arm64-musl-v1.2.0.tar.gz", "thinline-radio-linux-arm-musl-v1.2.0.tar.gz"), "linux", "arm", "thinline-radio-linux-arm-musl-v1.2.0.tar.gz", false},
+		{"only arm available", assets("thinline-radio-linux-arm-musl-v1.2.0.tar.gz"), "linux", "arm64", "", false},
+		{"wrong extension", assets("thinline-radio-windows-amd64-v1.2.0.tar.gz", "thinline-radio-linux-amd64-v1.2.0.tar.gz.sha256"), "windows", "amd64", "", false},
+		{"prefers version", assets("thinline-radio-linux-amd64-musl.tar.gz", "thinline-radio-linux-amd64-musl-v1.2.0.tar.gz"), "linux", "amd64", "thinline-radio-linux-amd64-musl-v1.2.0.tar.gz", false},
+	}
+
+	for _, tc := range cases {
+		got, exact := matchReleaseAsset(tc.assets, tc.goos, tc.goarch, "1.2.0")
+		name := ""
+		if got != nil {
+			name = got.Name
+		}
+		if name != tc.want || exact != tc.exact {
+			t.Fatalf("%s: got (%q, %v) want (%q, %v)", tc.name, name, exact, tc.want, tc.exact)
+		}
+	}
+}
+
+func TestIsNewerVersion(t *testing.T) {
+	cases := []struct {
+		candidate, current string
+		want               bool
+	}{
+		{"7.0.0-beta9.7.22", "7.0.0-beta9.7.9", true},
+		{"7.0.0-beta9.7.9", "7.0.0-beta9.7.22", false},
+		{"1.0.0-rc2", "1.0.0-rc1", true},
+		{"1.0.0-rc1", "1.0.0-rc2", false},
+		{"1.0.0-rc10", "1.0.0-rc9", true},
+		{"1.0.0-beta.1", "1.0.0-beta", true},
+		{"1.0.0-beta", "1.0.0-beta.1", false},
+		{"1.0.0-rc1", "1.0.0-beta9", true},
+		{"1.0.0-beta1", "1.0.0-alpha2", true},
+		{"1.0.0-beta.x", "1.0.0-beta.2", true},
+		{"1.0.0", "1.0.0-rc1", true},
+		{"1.0.0-rc1", "1.0.0", false},
+		{"v26.08.1-beta1", "26.07.23", true},
+		{"1.0.0-beta9.7.22", "1.0.0-beta9.7.22", false},
+		{"1.0.1", "1.0.0", true},
+	}
+	for _, tc := range cases {
+		if got := isNewerVersion(tc.candidate, tc.current); got != tc.want {
+			t.Fatalf("isNewerVersion(%q, %q) = %v, want %v", tc.candidate, tc.current, got, tc.want)
+		}
+	}
+}
+
+func TestInstallBinaryStagesBesideExecutable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows installs via a detached script")
+	}
+
+	exeDir := t.TempDir()
+	exePath := filepath.Join(exeDir, "thinline-radio")
+	if err := os.WriteFile(exePath, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	newBinaryPath := filepath.Join(t.TempDir(), "thinline-radio-new")
+	if err := os.WriteFile(newBinaryPath, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := (&Updater{}).installBinary(newBinaryPath, exePath); err != nil {
+		t.Fatalf("installBinary: %v", err)
+	}
+
+	if b, _ := os.ReadFile(exePath); string(b) != "new" {
+		t.Fatalf("executable = %q, want new binary", b)
+	}
+	if b, _ := os.ReadFile(exePath + ".bak"); string(b) != "old" {
+		t.Fatalf("backup = %q, want old binary", b)
+	}
+	if info, err := os.Stat(exePath); err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Fatalf("installed binary is not executable: %v", err)
+	}
+	entries, _ := os.ReadDir(exeDir)
+	if len(entries) != 2 {
+		t.Fatalf("staging file left behind: %v", entries)
+	}
+}
+
+func TestCheckUpdateDiskSpace(t *testing.T) {
+	exePath := filepath.Join(t.TempDir(), "thinline-radio")
+	if err := os.WriteFile(exePath, make([]byte, 1024), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	space := checkUpdateDiskSpace(&UpdateInfo{DownloadSize: 2048}, exePath)
+	if !space.Sufficient || space.Error != "" {
+		t.Fatalf("small update rejected: %+v", space)
+	}
+	if want := uint64(2048 + 1024 + updateSpaceHeadroom); space.RequiredBytes != want {
+		t.Fatalf("RequiredBytes = %d, want %d", space.RequiredBytes, want)
+	}
+
+	space = checkUpdateDiskSpace(&UpdateInfo{DownloadSize: 1 << 62}, exePath)
+	if space.Sufficient || !strings.Contains(space.Error, "not enough disk space") {
+		t.Fatalf("oversized update accepted: %+v", space)
+	}
+}
+
+func TestEvaluateUpdateMarker(t *testing.T) {
+	now := time.Unix(1760000000, 0)
+	marker := func(starts int, age time.Duration) *UpdateMarker {
+		return &UpdateMarker{PreviousVersion: "7.0.0", NewVersion: "7.1.0", AppliedAt: now.Add(-age).Unix(), Starts: starts}
+	}
+
+	cases := []struct {
+		name    string
+		marker  *UpdateMarker
+		version string
+		want    updateProbeAction
+	}{
+		{"no marker", nil, "7.1.0", updateProbeNone},
+		{"first start", marker(0, time.Minute), "7.1.0", updateProbeWatch},
+		{"one crash", marker(updateProbeMaxStarts-1, time.Minute), "7.1.0", updateProbeWatch},
+		{"crash loop", marker(updateProbeMaxStarts, 2*time.Minute), "7.1.0", updateProbeRollback},
+		{"other version running", marker(updateProbeMaxStarts, time.Minute), "7.0.0", updateProbeNone},
+		{"stale marker", marker(updateProbeMaxStarts, updateProbeWindow+time.Minute), "7.1.0", updateProbeNone},
+	}
+	for _, tc := range cases {
+		if got := evaluateUpdateMarker(tc.marker, tc.version, now); got != tc.want {
+			t.Errorf("%s: got %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestUpdateMarkerRoundTrip(t *testing.T) {
+	exePath := filepath.Join(t.TempDir(), "thinline-radio")
+
+	if m, err := readUpdateMarker(exePath); m != nil || err != nil {
+		t.Fatalf("missing marker = %+v, %v", m, err)
+	}
+	want := &UpdateMarker{PreviousVersion: "7.0.0", NewVersion: "7.1.0", BackupPath: exePath + ".bak", AppliedAt: 1760000000, Starts: 1}
+	if err := writeUpdateMarker(exePath, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readUpdateMarker(exePath)
+	if err != nil || *got != *want {
+		t.Fatalf("marker = %+v, %v; want %+v", got, err, want)
+	}
+	removeUpdateMarker(exePath)
+	if _, err := os.Stat(updateMarkerPath(exePath)); !os.IsNotExist(err) {
+		t.Fatalf("marker not removed: %v", err)
+	}
+}
+
+func TestRestoreBackupBinary(t *testing.T) {
+	exePath := filepath.Join(t.TempDir(), "thinline-radio")
+	elf := func(tag string) []byte { return append([]byte{0x7f, 'E', 'L', 'F'}, tag...) }
+	if err := os.WriteFile(exePath, elf("new"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := restoreBackupBinary(exePath, ""); err == nil {
+		t.Fatal("restore without a backup succeeded")
+	}
+	if b, _ := os.ReadFile(exePath); string(b) != string(elf("new")) {
+		t.Fatalf("failed restore changed the executable to %q", b)
+	}
+
+	if err := os.WriteFile(exePath+".bak", elf("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := restoreBackupBinary(exePath, exePath+".bak"); err != nil {
+		t.Fatalf("restoreBackupBinary: %v", err)
+	}
+	if b, _ := os.ReadFile(exePath); string(b) != string(elf("old")) {
+		t.Fatalf("executable = %q, want the backup", b)
+	}
+	if b, _ := os.ReadFile(exePath + ".failed"); string(b) != string(elf("new")) {
+		t.Fatalf("failed binary = %q, want it kept aside", b)
+	}
+	if _, err := os.Stat(exePath + ".bak"); !os.IsNotExist(err) {
+		t.Fatalf("backup still present: %v", err)
+	}
+}
+
+func TestVerifyUpdateBinary(t *testing.T) {
+	dir := t.TempDir()
+
+	text := filepath.Join(dir, "text")
+	if err := os.WriteFile(text, []byte("not a binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyUpdateBinary(text, Version); err == nil {
+		t.Fatal("accepted a file that is not an executable")
+	}
+
+	// An ELF header with nothing behind it cannot be run, like a binary
+	// built for another architecture.
+	broken := filepath.Join(dir, "broken")
+	if err := os.WriteFile(broken, []byte{0x7f, 'E', 'L', 'F', 0, 0, 0, 0}, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyUpdateBinary(broken, Version); err == nil {
+		t.Fatal("accepted a binary that does not run")
+	}
+}
+
+func TestRunUpdateCommand(t *testing.T) {
+	latest := Version
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if latest == "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`[{"tag_name":"v` + latest + `","assets":[{"name":"` + buildAssetName(latest) + `","browser_download_url":"https://example.com/a"}]}]`))
+	}))
+	defer srv.Close()
+
+	run := func() (int, string) {
+		var out strings.Builder
+		u := &Updater{controller: &Controller{Config: &Config{}}, apiURL: srv.URL}
+		return runUpdateCommand(u, false, &out), out.String()
+	}
+
+	if status, out := run(); status != updateExitUpToDate || !strings.Contains(out, "update available: false") {
+		t.Fatalf("up to date: status %d, output %q", status, out)
+	}
+
+	latest = "999.0.0"
+	if status, out := run(); status != updateExitAvailable || !strings.Contains(out, "latest version: 999.0.0") {
+		t.Fatalf("update available: status %d, output %q", status, out)
+	}
+
+	latest = ""
+	if status, out := run(); status != updateExitError || !strings.HasPrefix(out, "error: ") {
+		t.Fatalf("failed check: status %d, output %q", status, out)
+	}
+}
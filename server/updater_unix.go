@@ -33,18 +33,28 @@ func triggerRestart() {
 // terminal.  This ensures the server restarts even when it is NOT managed by
 // systemd (e.g. run directly in a terminal or via a startup script).
 //
-// A 5-second shell sleep is used before exec-ing the new binary.  Without it
+// A 5-second shell sleep is used before starting the new binary.  Without it
 // the new process would race to bind the port while the current process is
 // still in its graceful shutdown, fail immediately, and exit — leaving the
 // server down.  The sleep lets the current process finish shutting down and
 // release the port before the new binary tries to bind it.
 //
+// The shell stays behind as a small watchdog for the post-update probe (see
+// updater_probe.go): if the binary exits with an error it is started again,
+// up to updateProbeMaxStarts+1 times, so a crash loop reaches the start that
+// rolls back.  A clean exit (status 0, e.g. the next update's restart) ends it.
+//
 // When systemd IS managing the process it will also restart it after SIGTERM;
 // whichever instance loses the port race exits immediately — no double-server.
 func spawnNewProcess(exePath string) error {
-	// "sleep 5 && exec <path>" — the shell waits 5 s then replaces itself with
-	// the new binary (exec-in-place, no extra process left behind).
-	script := fmt.Sprintf("sleep 5 && exec '%s'", exePath)
+	script := fmt.Sprintf(`sleep 5
+n=0
+while :; do
+	'%s' && exit 0
+	n=$((n+1))
+	[ "$n" -gt %d ] && exit 1
+	sleep 5
+done`, exePath, updateProbeMaxStarts)
 	cmd := exec.Command("sh", "-c", script)
 	cmd.SysProcAttr = &syscall.SysProcAttr{
 		Setsid: true, // new session — detached from parent's terminal
@@ -55,6 +65,12 @@ func spawnNewProcess(exePath string) error {
 	return cmd.Start()
 }
 
+// reexecBinary replaces the current process image with exePath, keeping the
+// PID so systemd or the restart monitor keep tracking it.
+func reexecBinary(exePath string) error {
+	return syscall.Exec(exePath, append([]string{exePath}, os.Args[1:]...), os.Environ())
+}
+
 // applyUpdateWindows is a no-op stub on non-Windows platforms.
 // It is never called on Unix; it exists only to satisfy the shared call site
 // in updater.go without requiring build tags there.
@@ -36,6 +36,16 @@ func spawnNewProcess(_ string) error {
 	return nil
 }
 
+// reexecBinary starts exePath as a new detached process; the caller exits.
+func reexecBinary(exePath string) error {
+	cmd := exec.Command(exePath, os.Args[1:]...)
+	cmd.Dir = filepath.Dir(exePath)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: createNewProcessGroup | createNoWindow,
+	}
+	return cmd.Start()
+}
+
 // applyUpdateWindows handles the Windows-specific binary swap using a plain
 // cmd.exe batch script (.cmd) instead of PowerShell.  Batch scripts are NOT
 // subject to PowerShell execution policies, so they run regardless of whether
@@ -22,6 +22,7 @@ import (
 	"encoding/base32"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -67,9 +68,10 @@ type User struct {
 	PasswordChangeCode        string
 	PasswordChangeCodeExpires uint64
 	AccountExpiresAt          uint64 // Unix timestamp, 0 = no expiration
-	MobileSetupTokenHash     string // SHA256 hex of one-time mobile setup token; empty = none
-	MobileSetupTokenExpires  uint64 // legacy time-box field; validity is hash match until consume clears it
-	MobileWelcomeEmailSent   bool   // one-time mobile app welcome / setup link email already sent
+	MobileSetupTokenHash      string // SHA256 hex of one-time mobile setup token; empty = none
+	MobileSetupTokenExpires   uint64 // legacy time-box field; validity is hash match until consume clears it
+	MobileWelcomeEmailSent    bool   // one-time mobile app welcome / setup link email already sent
+	ModifiedAt                uint64 // Unix timestamp of the last Update/SaveNewUser, 0 = unknown (pre-migration)
 	systemsData               any
 	systemDelaysMap           map[uint64]uint
 	talkgroupDelaysMap        map[string]uint
@@ -423,6 +425,26 @@ func (users *Users) IsPinAvailable(pin string, excludeID uint64) bool {
 	return existing.Id == excludeID
 }
 
+// ErrPinInUse is returned by ValidatePin when another user has the PIN.
+var ErrPinInUse = errors.New("PIN already in use by another user")
+
+// ValidatePin checks a PIN chosen by an admin or Central Management: at least
+// minLength characters, not one character repeated, and not another user's
+// PIN, since users are looked up by PIN.
+func (users *Users) ValidatePin(pin string, excludeID uint64, minLength uint) error {
+	pin = strings.TrimSpace(pin)
+	if uint(len(pin)) < minLength {
+		return fmt.Errorf("PIN must be at least %d characters long", minLength)
+	}
+	if len(pin) > 1 && strings.Count(pin, pin[:1]) == len(pin) {
+		return errors.New("PIN must not be a single repeated character")
+	}
+	if !users.IsPinAvailable(pin, excludeID) {
+		return ErrPinInUse
+	}
+	return nil
+}
+
 func (users *Users) GenerateUniquePin(excludeID uint64) (string, error) {
 	const maxAttempts = 1000
 
@@ -670,10 +692,14 @@ func (users *Users) RecordLastLogin(user *User, db *Database) error {
 		return nil
 	}
 
+	// A login is not an account change; keep modifiedAt so incremental
+	// Central Management syncs don't re-pull every active user.
+	modifiedAt := user.ModifiedAt
 	user.UpdateLastLogin()
 	if err := users.Update(user); err != nil {
 		return err
 	}
+	user.ModifiedAt = modifiedAt
 
 	_, err := db.Sql.Exec(`UPDATE "users" SET "lastLogin" = $1 WHERE "userId" = $2`, user.LastLogin, user.Id)
 	return err
@@ -828,6 +854,8 @@ func (users *Users) Add(user *User) error {
 func (users *Users) Update(user *User) error {
 	users.mutex.Lock()
 
+	user.ModifiedAt = uint64(time.Now().Unix())
+
 	user.ensurePinsLoaded()
 	user.loadSystemScopes()
 	user.loadNoAudioAlertScopes()
@@ -844,9 +872,14 @@ func (users *Users) Update(user *User) error {
 		if existing.Pin != "" && existing.Pin != user.Pin {
 			delete(users.pins, existing.Pin)
 		}
-		// If this user was previously the group admin for a different group, remove that entry.
-		if existing.IsGroupAdmin && existing.UserGroupId > 0 && existing.UserGroupId != user.UserGroupId {
-			delete(users.groupAdmins, existing.UserGroupId)
+	}
+
+	// Callers often change the shared *User in place, so existing may already
+	// hold the new group. Drop every admin entry this user no longer holds
+	// instead of trusting existing.UserGroupId.
+	for groupId, admin := range users.groupAdmins {
+		if admin.Id == user.Id && (!user.IsGroupAdmin || groupId != user.UserGroupId) {
+			delete(users.groupAdmins, groupId)
 		}
 	}
 
@@ -857,11 +890,6 @@ func (users *Users) Update(user *User) error {
 	}
 	if user.IsGroupAdmin && user.UserGroupId > 0 {
 		users.groupAdmins[user.UserGroupId] = user
-	} else if !user.IsGroupAdmin {
-		// User was demoted from admin — remove from index if they were the admin.
-		if admin, ok := users.groupAdmins[user.UserGroupId]; ok && admin.Id == user.Id {
-			delete(users.groupAdmins, user.UserGroupId)
-		}
 	}
 	users.mutex.Unlock()
 
@@ -906,7 +934,7 @@ func (users *Users) Read(db *Database) error {
 	users.pins = make(map[string]*User)
 	users.groupAdmins = make(map[uint64]*User)
 
-	rows, err := db.Sql.Query(`SELECT "userId", "email", "password", "pin", "pinExpiresAt", "connectionLimit", "verified", "verificationToken", "createdAt", "lastLogin", "firstName", "lastName", "zipCode", "systems", "talkgroups", "delay", "systemDelays", "talkgroupDelays", "settings", "stripeCustomerId", "stripeSubscriptionId", "subscriptionStatus", "userGroupId", "isGroupAdmin", COALESCE("systemAdmin", false), COALESCE("pushSystemNoAudioAlerts", false), COALESCE("pushApiKeyNoAudioAlerts", false), COALESCE("systemNoAudioAlertSystems", ''), COALESCE("apiKeyNoAudioAlertApiKeys", ''), COALESCE("forcePasswordReset", false), "resetCode", "resetCodeExpires", "accountExpiresAt", COALESCE("mobileSetupTokenHash", ''), COALESCE("mobileSetupTokenExpires", 0), COALESCE("mobileWelcomeEmailSent", false) FROM "users"`)
+	rows, err := db.Sql.Query(`SELECT "userId", "email", "password", "pin", "pinExpiresAt", "connectionLimit", "verified", "verificationToken", "createdAt", "lastLogin", "firstName", "lastName", "zipCode", "systems", "talkgroups", "delay", "systemDelays", "talkgroupDelays", "settings", "stripeCustomerId", "stripeSubscriptionId", "subscriptionStatus", "userGroupId", "isGroupAdmin", COALESCE("systemAdmin", false), COALESCE("pushSystemNoAudioAlerts", false), COALESCE("pushApiKeyNoAudioAlerts", false), COALESCE("systemNoAudioAlertSystems", ''), COALESCE("apiKeyNoAudioAlertApiKeys", ''), COALESCE("forcePasswordReset", false), "resetCode", "resetCodeExpires", "accountExpiresAt", COALESCE("mobileSetupTokenHash", ''), COALESCE("mobileSetupTokenExpires", 0), COALESCE("mobileWelcomeEmailSent", false), COALESCE("modifiedAt", 0) FROM "users"`)
 	if err != nil {
 		return formatError(err, "")
 	}
@@ -934,8 +962,9 @@ func (users *Users) Read(db *Database) error {
 		var mobileSetupTokenHash sql.NullString
 		var mobileSetupTokenExpires sql.NullInt64
 		var mobileWelcomeEmailSent sql.NullBool
+		var modifiedAt int64
 
-		err := rows.Scan(&user.Id, &user.Email, &user.Password, &pin, &pinExpiresAt, &connectionLimit, &user.Verified, &user.VerificationToken, &user.CreatedAt, &user.LastLogin, &user.FirstName, &user.LastName, &user.ZipCode, &systems, &talkgroups, &user.Delay, &systemDelays, &talkgroupDelays, &settings, &stripeCustomerId, &stripeSubscriptionId, &subscriptionStatus, &userGroupId, &isGroupAdmin, &systemAdmin, &pushSystemNoAudioAlerts, &pushApiKeyNoAudioAlerts, &systemNoAudioAlertSystems, &apiKeyNoAudioAlertApiKeys, &forcePasswordReset, &resetCode, &resetCodeExpires, &accountExpiresAt, &mobileSetupTokenHash, &mobileSetupTokenExpires, &mobileWelcomeEmailSent)
+		err := rows.Scan(&user.Id, &user.Email, &user.Password, &pin, &pinExpiresAt, &connectionLimit, &user.Verified, &user.VerificationToken, &user.CreatedAt, &user.LastLogin, &user.FirstName, &user.LastName, &user.ZipCode, &systems, &talkgroups, &user.Delay, &systemDelays, &talkgroupDelays, &settings, &stripeCustomerId, &stripeSubscriptionId, &subscriptionStatus, &userGroupId, &isGroupAdmin, &systemAdmin, &pushSystemNoAudioAlerts, &pushApiKeyNoAudioAlerts, &systemNoAudioAlertSystems, &apiKeyNoAudioAlertApiKeys, &forcePasswordReset, &resetCode, &resetCodeExpires, &accountExpiresAt, &mobileSetupTokenHash, &mobileSetupTokenExpires, &mobileWelcomeEmailSent, &modifiedAt)
 		if err != nil {
 			return formatError(err, "")
 		}
@@ -1016,6 +1045,9 @@ func (users *Users) Read(db *Database) error {
 		if mobileWelcomeEmailSent.Valid {
 			user.MobileWelcomeEmailSent = mobileWelcomeEmailSent.Bool
 		}
+		if modifiedAt > 0 {
+			user.ModifiedAt = uint64(modifiedAt)
+		}
 
 		if settings.Valid {
 			user.Settings = settings.String
@@ -1176,8 +1208,8 @@ func (users *Users) Write(db *Database) error {
 				accountExpiresAtVal = int64(0)
 			}
 
-			_, err = db.Sql.Exec(`UPDATE "users" SET "email"=$1, "password"=$2, "pin"=$3, "pinExpiresAt"=$4, "connectionLimit"=$5, "verified"=$6, "verificationToken"=$7, "createdAt"=$8, "lastLogin"=$9, "firstName"=$10, "lastName"=$11, "zipCode"=$12, "systems"=$13, "talkgroups"=$14, "delay"=$15, "systemDelays"=$16, "talkgroupDelays"=$17, "settings"=$18, "stripeCustomerId"=$19, "stripeSubscriptionId"=$20, "subscriptionStatus"=$21, "userGroupId"=$22, "isGroupAdmin"=$23, "systemAdmin"=$24, "pushSystemNoAudioAlerts"=$25, "pushApiKeyNoAudioAlerts"=$26, "systemNoAudioAlertSystems"=$27, "apiKeyNoAudioAlertApiKeys"=$28, "forcePasswordReset"=$29, "resetCode"=$30, "resetCodeExpires"=$31, "accountExpiresAt"=$32, "mobileSetupTokenHash"=$33, "mobileSetupTokenExpires"=$34, "mobileWelcomeEmailSent"=$35 WHERE "userId"=$36`,
-				user.Email, user.Password, pin, pinExpiresAt, connectionLimit, user.Verified, user.VerificationToken, createdAtStr, lastLoginStr, user.FirstName, user.LastName, user.ZipCode, systems, talkgroups, user.Delay, systemDelays, talkgroupDelays, settings, stripeCustomerId, stripeSubscriptionId, subscriptionStatus, user.UserGroupId, user.IsGroupAdmin, user.SystemAdmin, user.PushSystemNoAudioAlerts, user.PushApiKeyNoAudioAlerts, user.SystemNoAudioAlertSystems, user.ApiKeyNoAudioAlertApiKeys, user.ForcePasswordReset, resetCodeVal, resetCodeExpiresVal, accountExpiresAtVal, user.MobileSetupTokenHash, int64(user.MobileSetupTokenExpires), user.MobileWelcomeEmailSent, user.Id)
+			_, err = db.Sql.Exec(`UPDATE "users" SET "email"=$1, "password"=$2, "pin"=$3, "pinExpiresAt"=$4, "connectionLimit"=$5, "verified"=$6, "verificationToken"=$7, "createdAt"=$8, "lastLogin"=$9, "firstName"=$10, "lastName"=$11, "zipCode"=$12, "systems"=$13, "talkgroups"=$14, "delay"=$15, "systemDelays"=$16, "talkgroupDelays"=$17, "settings"=$18, "stripeCustomerId"=$19, "stripeSubscriptionId"=$20, "subscriptionStatus"=$21, "userGroupId"=$22, "isGroupAdmin"=$23, "systemAdmin"=$24, "pushSystemNoAudioAlerts"=$25, "pushApiKeyNoAudioAlerts"=$26, "systemNoAudioAlertSystems"=$27, "apiKeyNoAudioAlertApiKeys"=$28, "forcePasswordReset"=$29, "resetCode"=$30, "resetCodeExpires"=$31, "accountExpiresAt"=$32, "mobileSetupTokenHash"=$33, "mobileSetupTokenExpires"=$34, "mobileWelcomeEmailSent"=$35, "modifiedAt"=$36 WHERE "userId"=$37`,
+				user.Email, user.Password, pin, pinExpiresAt, connectionLimit, user.Verified, user.VerificationToken, createdAtStr, lastLoginStr, user.FirstName, user.LastName, user.ZipCode, systems, talkgroups, user.Delay, systemDelays, talkgroupDelays, settings, stripeCustomerId, stripeSubscriptionId, subscriptionStatus, user.UserGroupId, user.IsGroupAdmin, user.SystemAdmin, user.PushSystemNoAudioAlerts, user.PushApiKeyNoAudioAlerts, user.SystemNoAudioAlertSystems, user.ApiKeyNoAudioAlertApiKeys, user.ForcePasswordReset, resetCodeVal, resetCodeExpiresVal, accountExpiresAtVal, user.MobileSetupTokenHash, int64(user.MobileSetupTokenExpires), user.MobileWelcomeEmailSent, int64(user.ModifiedAt), user.Id)
 			if err != nil {
 				return formatError(err, "")
 			}
@@ -1214,6 +1246,20 @@ func (users *Users) GetUserByPin(pin string) *User {
 	return users.pins[pin]
 }
 
+// FindUserByPin returns the first user whose PIN satisfies match, for lookups
+// that only hold a derived form of the PIN.
+func (users *Users) FindUserByPin(match func(pin string) bool) *User {
+	users.mutex.RLock()
+	defer users.mutex.RUnlock()
+
+	for pin, user := range users.pins {
+		if match(pin) {
+			return user
+		}
+	}
+	return nil
+}
+
 func (users *Users) GetUserById(id uint64) *User {
 	users.mutex.RLock()
 	defer users.mutex.RUnlock()
@@ -1297,6 +1343,8 @@ func (users *Users) SaveNewUser(user *User, db *Database) error {
 	stripeSubscriptionId := user.StripeSubscriptionId
 	subscriptionStatus := user.SubscriptionStatus
 
+	user.ModifiedAt = uint64(time.Now().Unix())
+
 	// Insert new user - let database auto-generate ID
 	var userId int64
 
@@ -1328,8 +1376,8 @@ func (users *Users) SaveNewUser(user *User, db *Database) error {
 	}
 
 	// Insert user with all fields including systems, delays, settings, and Stripe data
-	err := db.Sql.QueryRow(`INSERT INTO "users" ("email", "password", "pin", "pinExpiresAt", "connectionLimit", "verified", "verificationToken", "createdAt", "lastLogin", "firstName", "lastName", "zipCode", "systems", "talkgroups", "delay", "systemDelays", "talkgroupDelays", "settings", "stripeCustomerId", "stripeSubscriptionId", "subscriptionStatus", "accountExpiresAt", "userGroupId", "isGroupAdmin", "systemAdmin", "pushSystemNoAudioAlerts", "pushApiKeyNoAudioAlerts", "systemNoAudioAlertSystems", "apiKeyNoAudioAlertApiKeys", "forcePasswordReset", "mobileSetupTokenHash", "mobileSetupTokenExpires", "mobileWelcomeEmailSent") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33) RETURNING "userId"`,
-		user.Email, user.Password, user.Pin, user.PinExpiresAt, user.ConnectionLimit, user.Verified, user.VerificationToken, createdAtStr, lastLoginStr, user.FirstName, user.LastName, user.ZipCode, systems, user.Talkgroups, user.Delay, systemDelays, talkgroupDelays, settings, stripeCustomerId, stripeSubscriptionId, subscriptionStatus, user.AccountExpiresAt, user.UserGroupId, user.IsGroupAdmin, user.SystemAdmin, user.PushSystemNoAudioAlerts, user.PushApiKeyNoAudioAlerts, user.SystemNoAudioAlertSystems, user.ApiKeyNoAudioAlertApiKeys, user.ForcePasswordReset, user.MobileSetupTokenHash, int64(user.MobileSetupTokenExpires), user.MobileWelcomeEmailSent).Scan(&userId)
+	err := db.Sql.QueryRow(`INSERT INTO "users" ("email", "password", "pin", "pinExpiresAt", "connectionLimit", "verified", "verificationToken", "createdAt", "lastLogin", "firstName", "lastName", "zipCode", "systems", "talkgroups", "delay", "systemDelays", "talkgroupDelays", "settings", "stripeCustomerId", "stripeSubscriptionId", "subscriptionStatus", "accountExpiresAt", "userGroupId", "isGroupAdmin", "systemAdmin", "pushSystemNoAudioAlerts", "pushApiKeyNoAudioAlerts", "systemNoAudioAlertSystems", "apiKeyNoAudioAlertApiKeys", "forcePasswordReset", "mobileSetupTokenHash", "mobileSetupTokenExpires", "mobileWelcomeEmailSent", "modifiedAt") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34) RETURNING "userId"`,
+		user.Email, user.Password, user.Pin, user.PinExpiresAt, user.ConnectionLimit, user.Verified, user.VerificationToken, createdAtStr, lastLoginStr, user.FirstName, user.LastName, user.ZipCode, systems, user.Talkgroups, user.Delay, systemDelays, talkgroupDelays, settings, stripeCustomerId, stripeSubscriptionId, subscriptionStatus, user.AccountExpiresAt, user.UserGroupId, user.IsGroupAdmin, user.SystemAdmin, user.PushSystemNoAudioAlerts, user.PushApiKeyNoAudioAlerts, user.SystemNoAudioAlertSystems, user.ApiKeyNoAudioAlertApiKeys, user.ForcePasswordReset, user.MobileSetupTokenHash, int64(user.MobileSetupTokenExpires), user.MobileWelcomeEmailSent, int64(user.ModifiedAt)).Scan(&userId)
 	if err != nil {
 		return formatError(err, "")
 	}
@@ -51,13 +51,17 @@ type UserGroup struct {
 	TaxMode               string // "none", "automatic", or "fixed"
 	StripeTaxRateId       string // Stripe Tax Rate ID (e.g. txr_xxx) used when TaxMode = "fixed"
 	IsPublicRegistration  bool
-	AllowAddExistingUsers bool // Allow group admins to add existing users from any group
-	CreatedAt             int64
-	systemAccessData      []uint64 // Legacy format: simple array of system IDs
-	systemAccessDataNew   any      // New format: array of objects with id and talkgroups (same format as user systemsData)
-	systemDelaysMap       map[uint64]uint
-	talkgroupDelaysMap    map[string]uint
-	pricingOptionsData    []PricingOption
+	AllowAddExistingUsers bool   // Allow group admins to add existing users from any group
+	DefaultLivefeedTags   string // JSON array of tag labels new clients start their livefeed with (empty = server-wide default)
+	// Reconnection overrides (0 = use the server-wide reconnection options)
+	ReconnectionGracePeriod   uint // Seconds to hold missed calls after a disconnect
+	ReconnectionMaxBufferSize uint // Maximum missed calls buffered per user
+	CreatedAt                 int64
+	systemAccessData          []uint64 // Legacy format: simple array of system IDs
+	systemAccessDataNew       any      // New format: array of objects with id and talkgroups (same format as user systemsData)
+	systemDelaysMap           map[uint64]uint
+	talkgroupDelaysMap        map[string]uint
+	pricingOptionsData        []PricingOption
 }
 
 type UserGroups struct {
@@ -149,6 +153,12 @@ func (ug *UserGroup) GetPricingOptions() []PricingOption {
 	return ug.pricingOptionsData
 }
 
+// GetDefaultLivefeedTags returns the tag labels this group's new clients start
+// their livefeed with; empty means the server-wide default applies.
+func (ug *UserGroup) GetDefaultLivefeedTags() []string {
+	return parseLivefeedTags(ug.DefaultLivefeedTags)
+}
+
 // HasAnySystemAccess reports whether the group grants access to at least one system.
 func (ug *UserGroup) HasAnySystemAccess() bool {
 	if ug == nil {
@@ -299,7 +309,7 @@ func (ugs *UserGroups) Load(db *Database) error {
 	ugs.mutex.Lock()
 	defer ugs.mutex.Unlock()
 
-	rows, err := db.Sql.Query(`SELECT "userGroupId", "name", "description", "systemAccess", "delay", "systemDelays", "talkgroupDelays", "connectionLimit", "maxUsers", "billingEnabled", "stripePriceId", "pricingOptions", "billingMode", "collectSalesTax", "taxMode", "stripeTaxRateId", "isPublicRegistration", "allowAddExistingUsers", "createdAt" FROM "userGroups"`)
+	rows, err := db.Sql.Query(`SELECT "userGroupId", "name", "description", "systemAccess", "delay", "systemDelays", "talkgroupDelays", "connectionLimit", "maxUsers", "billingEnabled", "stripePriceId", "pricingOptions", "billingMode", "collectSalesTax", "taxMode", "stripeTaxRateId", "isPublicRegistration", "allowAddExistingUsers", "reconnectionGracePeriod", "reconnectionMaxBufferSize", "defaultLivefeedTags", "createdAt" FROM "userGroups"`)
 	if err != nil {
 		return err
 	}
@@ -324,6 +334,9 @@ func (ugs *UserGroups) Load(db *Database) error {
 		var collectSalesTax sql.NullBool
 		var taxMode sql.NullString
 		var stripeTaxRateId sql.NullString
+		var reconnectionGracePeriod sql.NullInt64
+		var reconnectionMaxBufferSize sql.NullInt64
+		var defaultLivefeedTags sql.NullString
 
 		err := rows.Scan(
 			&group.Id,
@@ -344,6 +357,9 @@ func (ugs *UserGroups) Load(db *Database) error {
 			&stripeTaxRateId,
 			&group.IsPublicRegistration,
 			&allowAddExistingUsers,
+			&reconnectionGracePeriod,
+			&reconnectionMaxBufferSize,
+			&defaultLivefeedTags,
 			&createdAt,
 		)
 		if err != nil {
@@ -355,6 +371,18 @@ func (ugs *UserGroups) Load(db *Database) error {
 			group.MaxUsers = uint(maxUsers.Int64)
 		}
 
+		if reconnectionGracePeriod.Valid && reconnectionGracePeriod.Int64 > 0 {
+			group.ReconnectionGracePeriod = uint(reconnectionGracePeriod.Int64)
+		}
+
+		if reconnectionMaxBufferSize.Valid && reconnectionMaxBufferSize.Int64 > 0 {
+			group.ReconnectionMaxBufferSize = uint(reconnectionMaxBufferSize.Int64)
+		}
+
+		if defaultLivefeedTags.Valid {
+			group.DefaultLivefeedTags = defaultLivefeedTags.String
+		}
+
 		if allowAddExistingUsers.Valid {
 			group.AllowAddExistingUsers = allowAddExistingUsers.Bool
 		} else {
@@ -472,9 +500,9 @@ func (ugs *UserGroups) Add(group *UserGroup, db *Database) error {
 
 	var userId int64
 	err := db.Sql.QueryRow(
-		`INSERT INTO "userGroups" ("name", "description", "systemAccess", "delay", "systemDelays", "talkgroupDelays", "connectionLimit", "maxUsers", "billingEnabled", "stripePriceId", "pricingOptions", "billingMode", "collectSalesTax", "taxMode", "stripeTaxRateId", "isPublicRegistration", "allowAddExistingUsers", "createdAt") 
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18) RETURNING "userGroupId"`,
-		group.Name, group.Description, group.SystemAccess, group.Delay, group.SystemDelays, group.TalkgroupDelays, group.ConnectionLimit, group.MaxUsers, group.BillingEnabled, group.StripePriceId, group.PricingOptions, group.BillingMode, group.CollectSalesTax, group.TaxMode, group.StripeTaxRateId, group.IsPublicRegistration, group.AllowAddExistingUsers, group.CreatedAt,
+		`INSERT INTO "userGroups" ("name", "description", "systemAccess", "delay", "systemDelays", "talkgroupDelays", "connectionLimit", "maxUsers", "billingEnabled", "stripePriceId", "pricingOptions", "billingMode", "collectSalesTax", "taxMode", "stripeTaxRateId", "isPublicRegistration", "allowAddExistingUsers", "reconnectionGracePeriod", "reconnectionMaxBufferSize", "defaultLivefeedTags", "createdAt") 
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21) RETURNING "userGroupId"`,
+		group.Name, group.Description, group.SystemAccess, group.Delay, group.SystemDelays, group.TalkgroupDelays, group.ConnectionLimit, group.MaxUsers, group.BillingEnabled, group.StripePriceId, group.PricingOptions, group.BillingMode, group.CollectSalesTax, group.TaxMode, group.StripeTaxRateId, group.IsPublicRegistration, group.AllowAddExistingUsers, group.ReconnectionGracePeriod, group.ReconnectionMaxBufferSize, group.DefaultLivefeedTags, group.CreatedAt,
 	).Scan(&userId)
 
 	if err != nil {
@@ -497,8 +525,8 @@ func (ugs *UserGroups) Update(group *UserGroup, db *Database) error {
 	group.loadPricingOptions()
 
 	_, err := db.Sql.Exec(
-		`UPDATE "userGroups" SET "name" = $1, "description" = $2, "systemAccess" = $3, "delay" = $4, "systemDelays" = $5, "talkgroupDelays" = $6, "connectionLimit" = $7, "maxUsers" = $8, "billingEnabled" = $9, "stripePriceId" = $10, "pricingOptions" = $11, "billingMode" = $12, "collectSalesTax" = $13, "taxMode" = $14, "stripeTaxRateId" = $15, "isPublicRegistration" = $16, "allowAddExistingUsers" = $17 WHERE "userGroupId" = $18`,
-		group.Name, group.Description, group.SystemAccess, group.Delay, group.SystemDelays, group.TalkgroupDelays, group.ConnectionLimit, group.MaxUsers, group.BillingEnabled, group.StripePriceId, group.PricingOptions, group.BillingMode, group.CollectSalesTax, group.TaxMode, group.StripeTaxRateId, group.IsPublicRegistration, group.AllowAddExistingUsers, group.Id,
+		`UPDATE "userGroups" SET "name" = $1, "description" = $2, "systemAccess" = $3, "delay" = $4, "systemDelays" = $5, "talkgroupDelays" = $6, "connectionLimit" = $7, "maxUsers" = $8, "billingEnabled" = $9, "stripePriceId" = $10, "pricingOptions" = $11, "billingMode" = $12, "collectSalesTax" = $13, "taxMode" = $14, "stripeTaxRateId" = $15, "isPublicRegistration" = $16, "allowAddExistingUsers" = $17, "reconnectionGracePeriod" = $18, "reconnectionMaxBufferSize" = $19, "defaultLivefeedTags" = $20 WHERE "userGroupId" = $21`,
+		group.Name, group.Description, group.SystemAccess, group.Delay, group.SystemDelays, group.TalkgroupDelays, group.ConnectionLimit, group.MaxUsers, group.BillingEnabled, group.StripePriceId, group.PricingOptions, group.BillingMode, group.CollectSalesTax, group.TaxMode, group.StripeTaxRateId, group.IsPublicRegistration, group.AllowAddExistingUsers, group.ReconnectionGracePeriod, group.ReconnectionMaxBufferSize, group.DefaultLivefeedTags, group.Id,
 	)
 
 	if err != nil {
@@ -0,0 +1,36 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidatePin(t *testing.T) {
+	users := NewUsers()
+	users.pins["4821"] = &User{Id: 7, Pin: "4821"}
+
+	for _, test := range []struct {
+		pin       string
+		excludeID uint64
+		ok        bool
+		inUse     bool
+	}{
+		{pin: "4821", excludeID: 7, ok: true},
+		{pin: "4821", excludeID: 8, inUse: true},
+		{pin: "123", excludeID: 8},
+		{pin: " 1234 ", excludeID: 8, ok: true},
+		{pin: "1111", excludeID: 8},
+		{pin: "", excludeID: 8},
+	} {
+		err := users.ValidatePin(test.pin, test.excludeID, 4)
+		if (err == nil) != test.ok || errors.Is(err, ErrPinInUse) != test.inUse {
+			t.Errorf("ValidatePin(%q, %d) = %v", test.pin, test.excludeID, err)
+		}
+	}
+
+	if err := users.ValidatePin("7", 8, 1); err != nil {
+		t.Errorf("single character PIN with pin_min_length = 1: %v", err)
+	}
+}
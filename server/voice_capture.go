@@ -0,0 +1,197 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// voiceCaptureMaxHoldSeconds caps how long a tone alert stays open collecting voice,
+// no matter how many fragments keep restarting the talkgroup's capture window.
+const voiceCaptureMaxHoldSeconds = 120
+
+// voiceCapture is a tone alert held open for follow-up voice on the same talkgroup.
+// Dispatchers often key up several times for one page; each transmission that arrives
+// before the window closes is appended to the alert call's transcript.
+type voiceCapture struct {
+	call      *Call
+	fragments []voiceCaptureFragment
+	openedAt  time.Time
+	deadline  time.Time
+	window    time.Duration
+	timer     *time.Timer
+}
+
+type voiceCaptureFragment struct {
+	callId     uint64
+	timestamp  time.Time
+	transcript string
+}
+
+func voiceCaptureKey(call *Call) string {
+	if call == nil || call.System == nil || call.Talkgroup == nil || call.System.Id == 0 || call.Talkgroup.Id == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d", call.System.Id, call.Talkgroup.Id)
+}
+
+// voiceCaptureWindow returns the talkgroup's capture window, or 0 when disabled.
+func voiceCaptureWindow(talkgroup *Talkgroup) time.Duration {
+	if talkgroup == nil || talkgroup.VoiceCaptureWindowSeconds == 0 {
+		return 0
+	}
+	seconds := talkgroup.VoiceCaptureWindowSeconds
+	if seconds > voiceCaptureMaxHoldSeconds {
+		seconds = voiceCaptureMaxHoldSeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// voiceCaptureDeadline restarts the window from now without running past the max hold.
+func voiceCaptureDeadline(now, openedAt time.Time, window time.Duration) time.Time {
+	deadline := now.Add(window)
+	if limit := openedAt.Add(voiceCaptureMaxHoldSeconds * time.Second); deadline.After(limit) {
+		return limit
+	}
+	return deadline
+}
+
+// mergeVoiceCaptureTranscript appends the captured fragments to the alert transcript in
+// transmission order, regardless of the order their transcriptions finished in.
+func mergeVoiceCaptureTranscript(transcript string, fragments []voiceCaptureFragment) string {
+	ordered := make([]voiceCaptureFragment, len(fragments))
+	copy(ordered, fragments)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].timestamp.Before(ordered[j].timestamp)
+	})
+
+	parts := []string{}
+	if t := strings.TrimSpace(transcript); t != "" {
+		parts = append(parts, t)
+	}
+	for _, fragment := range ordered {
+		if t := strings.TrimSpace(fragment.transcript); t != "" {
+			parts = append(parts, t)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// dispatchToneAlerts triggers tone alerts for a voice call, or opens a voice capture when
+// the talkgroup has a capture window so follow-up transmissions land in the same alert.
+func (controller *Controller) dispatchToneAlerts(call *Call) {
+	// Tones that matched no tone set never alert; such a call is just more voice.
+	if call.ToneSequence == nil || (len(call.ToneSequence.MatchedToneSets) == 0 && call.ToneSequence.MatchedToneSet == nil) {
+		if !controller.appendVoiceCapture(call) {
+			go controller.AlertEngine.TriggerToneAlerts(call)
+		}
+		return
+	}
+
+	window := voiceCaptureWindow(call.Talkgroup)
+	key := voiceCaptureKey(call)
+	if window == 0 || key == "" {
+		go controller.AlertEngine.TriggerToneAlerts(call)
+		return
+	}
+
+	controller.voiceCapturesMutex.Lock()
+	existing := controller.voiceCaptures[key]
+	if existing != nil && existing.call.Id == call.Id {
+		controller.voiceCapturesMutex.Unlock()
+		return
+	}
+	if existing != nil {
+		existing.timer.Stop()
+		delete(controller.voiceCaptures, key)
+	}
+	now := time.Now()
+	capture := &voiceCapture{
+		call:     call,
+		openedAt: now,
+		deadline: now.Add(window),
+		window:   window,
+	}
+	capture.timer = time.AfterFunc(window, func() {
+		controller.flushVoiceCapture(key, capture)
+	})
+	controller.voiceCaptures[key] = capture
+	controller.voiceCapturesMutex.Unlock()
+
+	// A new page on the talkgroup closes whatever the previous one had collected.
+	if existing != nil {
+		controller.finishVoiceCapture(existing)
+	}
+
+	controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("voice capture opened for call %d on talkgroup %d (window: %s)", call.Id, call.Talkgroup.TalkgroupRef, window))
+}
+
+// appendVoiceCapture attaches a voice call to the talkgroup's open capture. It returns
+// false when there is no capture to join and the call should be handled normally.
+func (controller *Controller) appendVoiceCapture(call *Call) bool {
+	key := voiceCaptureKey(call)
+	if key == "" {
+		return false
+	}
+
+	controller.voiceCapturesMutex.Lock()
+	capture := controller.voiceCaptures[key]
+	if capture == nil || capture.call.Id == call.Id || call.Timestamp.Before(capture.call.Timestamp) {
+		controller.voiceCapturesMutex.Unlock()
+		return false
+	}
+	now := time.Now()
+	if !now.Before(capture.deadline) {
+		controller.voiceCapturesMutex.Unlock()
+		return false
+	}
+	capture.fragments = append(capture.fragments, voiceCaptureFragment{
+		callId:     call.Id,
+		timestamp:  call.Timestamp,
+		transcript: call.Transcript,
+	})
+	capture.deadline = voiceCaptureDeadline(now, capture.openedAt, capture.window)
+	capture.timer.Reset(capture.deadline.Sub(now))
+	anchorId := capture.call.Id
+	count := len(capture.fragments)
+	controller.voiceCapturesMutex.Unlock()
+
+	controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("voice capture: appended call %d to tone alert call %d (%d fragment(s))", call.Id, anchorId, count))
+	return true
+}
+
+// flushVoiceCapture closes a capture once its deadline has passed. Timer runs that were
+// overtaken by a Reset find the deadline still ahead and leave the capture alone.
+func (controller *Controller) flushVoiceCapture(key string, capture *voiceCapture) {
+	controller.voiceCapturesMutex.Lock()
+	if controller.voiceCaptures[key] != capture || time.Now().Before(capture.deadline) {
+		controller.voiceCapturesMutex.Unlock()
+		return
+	}
+	delete(controller.voiceCaptures, key)
+	controller.voiceCapturesMutex.Unlock()
+
+	controller.finishVoiceCapture(capture)
+}
+
+// finishVoiceCapture persists the merged transcript and sends the tone alert.
+func (controller *Controller) finishVoiceCapture(capture *voiceCapture) {
+	call := capture.call
+
+	if len(capture.fragments) > 0 {
+		call.Transcript = mergeVoiceCaptureTranscript(call.Transcript, capture.fragments)
+
+		query := fmt.Sprintf(`UPDATE "calls" SET "transcript" = '%s' WHERE "callId" = %d`, escapeQuotes(call.Transcript), call.Id)
+		if _, err := controller.Database.Sql.Exec(query); err != nil {
+			controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("voice capture: failed to update transcript for call %d: %v", call.Id, err))
+		}
+		go controller.remapIncidentIfTranscriptReady(call.Id)
+	}
+
+	controller.Logs.LogEvent(LogLevelInfo, fmt.Sprintf("voice capture closed for call %d with %d appended transmission(s)", call.Id, len(capture.fragments)))
+
+	go controller.AlertEngine.TriggerToneAlerts(call)
+}
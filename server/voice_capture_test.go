@@ -0,0 +1,61 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeVoiceCaptureTranscript(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Transcriptions can finish out of order; fragments are merged by transmission time.
+	fragments := []voiceCaptureFragment{
+		{callId: 3, timestamp: base.Add(40 * time.Second), transcript: "cross street elm"},
+		{callId: 2, timestamp: base.Add(20 * time.Second), transcript: " 123 main street "},
+		{callId: 4, timestamp: base.Add(50 * time.Second), transcript: "  "},
+	}
+	got := mergeVoiceCaptureTranscript("engine 1 respond", fragments)
+	want := "engine 1 respond\n123 main street\ncross street elm"
+	if got != want {
+		t.Fatalf("merged transcript = %q, want %q", got, want)
+	}
+
+	if got := mergeVoiceCaptureTranscript("engine 1 respond", nil); got != "engine 1 respond" {
+		t.Fatalf("merged transcript without fragments = %q", got)
+	}
+}
+
+func TestVoiceCaptureDeadline(t *testing.T) {
+	opened := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	window := 30 * time.Second
+
+	if got := voiceCaptureDeadline(opened.Add(20*time.Second), opened, window); !got.Equal(opened.Add(50 * time.Second)) {
+		t.Fatalf("deadline = %v, want window restarted from fragment", got)
+	}
+
+	limit := opened.Add(voiceCaptureMaxHoldSeconds * time.Second)
+	if got := voiceCaptureDeadline(opened.Add(110*time.Second), opened, window); !got.Equal(limit) {
+		t.Fatalf("deadline = %v, want capped at max hold %v", got, limit)
+	}
+}
+
+func TestVoiceCaptureWindow(t *testing.T) {
+	cases := []struct {
+		seconds uint
+		want    time.Duration
+	}{
+		{0, 0},
+		{15, 15 * time.Second},
+		{600, voiceCaptureMaxHoldSeconds * time.Second},
+	}
+	for _, tc := range cases {
+		if got := voiceCaptureWindow(&Talkgroup{VoiceCaptureWindowSeconds: tc.seconds}); got != tc.want {
+			t.Fatalf("voiceCaptureWindow(%d) = %v, want %v", tc.seconds, got, tc.want)
+		}
+	}
+	if got := voiceCaptureWindow(nil); got != 0 {
+		t.Fatalf("voiceCaptureWindow(nil) = %v, want 0", got)
+	}
+}
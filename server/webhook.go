@@ -0,0 +1,903 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	WebhookEventCall  = "call"
+	WebhookEventAlert = "alert"
+
+	webhookMaxAttempts    = 5
+	webhookRetryBaseDelay = 10 * time.Second
+	webhookRetryMaxDelay  = 5 * time.Minute
+	webhookTimeout        = 15 * time.Second
+	webhookMaxConcurrent  = 16   // requests in flight across all webhooks
+	webhookDeliveryLog    = 500  // deliveries kept for the admin delivery log
+	webhookRetryQueue     = 1000 // failed deliveries waiting for another attempt
+	webhookRetryWorkers   = 4    // retry attempts in flight
+)
+
+// Webhook posts call metadata or alerts as JSON to an outside URL, for
+// integrations such as Slack, Discord or Node-RED.  Systems takes the same
+// "*" or per-system talkgroup selection as downstreams; TagIds, when set,
+// further limits it to talkgroups with one of those tags.
+type Webhook struct {
+	Id       uint64
+	Disabled bool
+	Events   []string
+	Name     string
+	Order    uint
+	Secret   string
+	Systems  any
+	TagIds   []uint64
+	Url      string
+}
+
+func NewWebhook() *Webhook {
+	return &Webhook{Events: []string{WebhookEventCall}, Systems: "*", TagIds: []uint64{}}
+}
+
+func (webhook *Webhook) FromMap(m map[string]any) *Webhook {
+	switch v := m["id"].(type) {
+	case float64:
+		webhook.Id = uint64(v)
+	}
+
+	switch v := m["disabled"].(type) {
+	case bool:
+		webhook.Disabled = v
+	}
+
+	switch v := m["events"].(type) {
+	case []any:
+		webhook.Events = []string{}
+		for _, e := range v {
+			if s, ok := e.(string); ok && (s == WebhookEventCall || s == WebhookEventAlert) {
+				webhook.Events = append(webhook.Events, s)
+			}
+		}
+	}
+
+	switch v := m["name"].(type) {
+	case string:
+		webhook.Name = v
+	}
+
+	switch v := m["order"].(type) {
+	case float64:
+		webhook.Order = uint(v)
+	}
+
+	switch v := m["secret"].(type) {
+	case string:
+		webhook.Secret = v
+	}
+
+	if v, ok := m["systems"]; ok && v != nil {
+		webhook.Systems = v
+	}
+
+	switch v := m["tagIds"].(type) {
+	case []any:
+		webhook.TagIds = []uint64{}
+		for _, id := range v {
+			if f, ok := id.(float64); ok && f > 0 {
+				webhook.TagIds = append(webhook.TagIds, uint64(f))
+			}
+		}
+	}
+
+	switch v := m["url"].(type) {
+	case string:
+		webhook.Url = strings.TrimSpace(v)
+	}
+
+	return webhook
+}
+
+func (webhook *Webhook) MarshalJSON() ([]byte, error) {
+	m := map[string]any{
+		"id":       webhook.Id,
+		"disabled": webhook.Disabled,
+		"events":   webhook.Events,
+		"name":     webhook.Name,
+		"secret":   webhook.Secret,
+		"systems":  webhook.Systems,
+		"tagIds":   webhook.TagIds,
+		"url":      webhook.Url,
+	}
+
+	if webhook.Order > 0 {
+		m["order"] = webhook.Order
+	}
+
+	return json.Marshal(m)
+}
+
+// Matches reports whether the webhook wants event for call.
+func (webhook *Webhook) Matches(event string, call *Call) bool {
+	if webhook.Disabled || webhook.Url == "" || call == nil {
+		return false
+	}
+
+	wanted := false
+	for _, e := range webhook.Events {
+		if e == event {
+			wanted = true
+			break
+		}
+	}
+	if !wanted || !systemsScopeIncludes(webhook.Systems, call) {
+		return false
+	}
+
+	if len(webhook.TagIds) == 0 {
+		return true
+	}
+	if call.Talkgroup == nil {
+		return false
+	}
+	for _, id := range webhook.TagIds {
+		if id == call.Talkgroup.TagId {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one entry of the delivery log.
+type WebhookDelivery struct {
+	Id         string `json:"id"`
+	WebhookId  uint64 `json:"webhookId"`
+	Name       string `json:"name"`
+	Url        string `json:"url"`
+	Event      string `json:"event"`
+	CallId     uint64 `json:"callId"`
+	Attempts   int    `json:"attempts"`
+	Status     string `json:"status"` // "pending", "retrying", "delivered" or "failed"
+	StatusCode int    `json:"statusCode"`
+	Error      string `json:"error,omitempty"`
+	CreatedAt  int64  `json:"createdAt"`
+	UpdatedAt  int64  `json:"updatedAt"`
+}
+
+type Webhooks struct {
+	List       []*Webhook
+	controller *Controller
+	mutex      sync.Mutex
+
+	client     *http.Client
+	slots      chan struct{}
+	deliveries []*WebhookDelivery // oldest first
+	logMutex   sync.Mutex
+
+	// Failed deliveries wait in retries until due, then go to a fixed pool
+	// of workers through retryDue, so a dead endpoint cannot pile up
+	// goroutines.
+	retries    []*webhookRetry
+	retryDue   chan *webhookRetry
+	retryMutex sync.Mutex
+}
+
+// webhookRetry is a delivery waiting for its next attempt.
+type webhookRetry struct {
+	webhook  *Webhook
+	delivery *WebhookDelivery
+	event    string
+	callId   uint64
+	body     []byte
+	attempts int
+	due      time.Time
+}
+
+func NewWebhooks(controller *Controller) *Webhooks {
+	webhooks := &Webhooks{
+		List:       []*Webhook{},
+		controller: controller,
+		client:     &http.Client{Timeout: webhookTimeout},
+		slots:      make(chan struct{}, webhookMaxConcurrent),
+		deliveries: []*WebhookDelivery{},
+		retries:    []*webhookRetry{},
+		retryDue:   make(chan *webhookRetry),
+	}
+
+	go webhooks.scheduleRetries()
+	for i := 0; i < webhookRetryWorkers; i++ {
+		go func() {
+			for retry := range webhooks.retryDue {
+				webhooks.attempt(retry)
+			}
+		}()
+	}
+
+	return webhooks
+}
+
+func (webhooks *Webhooks) FromMap(f []any) *Webhooks {
+	webhooks.mutex.Lock()
+	defer webhooks.mutex.Unlock()
+
+	webhooks.List = []*Webhook{}
+
+	for _, r := range f {
+		switch m := r.(type) {
+		case map[string]any:
+			webhooks.List = append(webhooks.List, NewWebhook().FromMap(m))
+		}
+	}
+
+	return webhooks
+}
+
+// GetWebhooks returns a copy of the list, safe to encode while the admin
+// saves a new one.
+func (webhooks *Webhooks) GetWebhooks() []*Webhook {
+	webhooks.mutex.Lock()
+	defer webhooks.mutex.Unlock()
+
+	list := make([]*Webhook, 0, len(webhooks.List))
+	for _, webhook := range webhooks.List {
+		w := *webhook
+		list = append(list, &w)
+	}
+	return list
+}
+
+// GetWebhookById returns a copy of the webhook with id.
+func (webhooks *Webhooks) GetWebhookById(id uint64) (*Webhook, bool) {
+	webhooks.mutex.Lock()
+	defer webhooks.mutex.Unlock()
+
+	for _, webhook := range webhooks.List {
+		if webhook.Id == id {
+			w := *webhook
+			return &w, true
+		}
+	}
+	return nil, false
+}
+
+func (webhooks *Webhooks) matching(event string, call *Call) []*Webhook {
+	webhooks.mutex.Lock()
+	defer webhooks.mutex.Unlock()
+
+	matched := []*Webhook{}
+	for _, webhook := range webhooks.List {
+		if webhook.Matches(event, call) {
+			w := *webhook
+			matched = append(matched, &w)
+		}
+	}
+	return matched
+}
+
+// SendCall posts a newly ingested call to every webhook subscribed to calls.
+func (webhooks *Webhooks) SendCall(call *Call) {
+	if webhooks == nil || call == nil || call.System == nil || call.Talkgroup == nil {
+		return
+	}
+
+	matched := webhooks.matching(WebhookEventCall, call)
+	if len(matched) == 0 {
+		return
+	}
+
+	payload := map[string]any{"call": webhooks.callPayload(call)}
+	for _, webhook := range matched {
+		go webhooks.deliver(webhook, WebhookEventCall, call.Id, payload)
+	}
+}
+
+// SendAlert posts a newly created tone or keyword alert.
+func (webhooks *Webhooks) SendAlert(alert *AlertRecord) {
+	if webhooks == nil || alert == nil || webhooks.controller == nil {
+		return
+	}
+
+	call := webhooks.controller.alertCall(alert)
+	if call == nil {
+		return
+	}
+
+	matched := webhooks.matching(WebhookEventAlert, call)
+	if len(matched) == 0 {
+		return
+	}
+
+	keywords := []string{}
+	if alert.KeywordsMatched != "" {
+		json.Unmarshal([]byte(alert.KeywordsMatched), &keywords)
+	}
+
+	payload := map[string]any{
+		"alert": map[string]any{
+			"id":                alert.AlertId,
+			"type":              alert.AlertType,
+			"toneDetected":      alert.ToneDetected,
+			"toneSetId":         alert.ToneSetId,
+			"keywords":          keywords,
+			"transcriptSnippet": alert.TranscriptSnippet,
+			"createdAt":         alert.CreatedAt,
+		},
+		"call": webhooks.callPayload(call),
+	}
+	for _, webhook := range matched {
+		go webhooks.deliver(webhook, WebhookEventAlert, alert.CallId, payload)
+	}
+}
+
+func (webhooks *Webhooks) callPayload(call *Call) map[string]any {
+	talkgroup := map[string]any{
+		"id":    call.Talkgroup.TalkgroupRef,
+		"label": call.Talkgroup.Label,
+		"name":  call.Talkgroup.Name,
+	}
+
+	if webhooks.controller != nil {
+		if webhooks.controller.Tags != nil {
+			if tag, ok := webhooks.controller.Tags.GetTagById(call.Talkgroup.TagId); ok {
+				talkgroup["tag"] = tag.Label
+			}
+		}
+		if webhooks.controller.Groups != nil {
+			groups := []string{}
+			for _, id := range call.Talkgroup.GroupIds {
+				if group, ok := webhooks.controller.Groups.GetGroupById(id); ok {
+					groups = append(groups, group.Label)
+				}
+			}
+			talkgroup["groups"] = groups
+		}
+	}
+
+	units := []uint{}
+	for _, unit := range call.Units {
+		if unit.UnitRef > 0 {
+			units = append(units, unit.UnitRef)
+		}
+	}
+
+	m := map[string]any{
+		"id":        call.Id,
+		"dateTime":  call.Timestamp.UTC().Format(time.RFC3339),
+		"timestamp": call.Timestamp.UnixMilli(),
+		"system":    map[string]any{"id": call.System.SystemRef, "label": call.System.Label},
+		"talkgroup": talkgroup,
+		"units":     units,
+	}
+	if call.Frequency > 0 {
+		m["frequency"] = call.Frequency
+	}
+	if len(call.Patches) > 0 {
+		m["patches"] = call.Patches
+	}
+	if call.Transcript != "" {
+		m["transcript"] = call.Transcript
+	}
+
+	return m
+}
+
+// deliver posts one event and returns after the first attempt.  Network
+// errors, 5xx and 429 are retried from the retry queue with a doubling
+// backoff.  Each attempt is recorded in the delivery log.
+func (webhooks *Webhooks) deliver(webhook *Webhook, event string, callId uint64, payload map[string]any) *WebhookDelivery {
+	id := make([]byte, 8)
+	rand.Read(id)
+
+	now := time.Now().UnixMilli()
+	delivery := &WebhookDelivery{
+		Id:        hex.EncodeToString(id),
+		WebhookId: webhook.Id,
+		Name:      webhook.Name,
+		Url:       webhook.Url,
+		Event:     event,
+		CallId:    callId,
+		Status:    "pending",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	webhooks.record(delivery)
+
+	body := map[string]any{"event": event, "deliveryId": delivery.Id, "sentAt": now}
+	for k, v := range payload {
+		body[k] = v
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		webhooks.update(delivery, func(d *WebhookDelivery) { d.Status, d.Error = "failed", err.Error() })
+		return delivery
+	}
+
+	webhooks.attempt(&webhookRetry{webhook: webhook, delivery: delivery, event: event, callId: callId, body: b})
+
+	return delivery
+}
+
+// attempt makes the next attempt at a delivery and queues another one if it
+// failed in a way worth retrying.
+func (webhooks *Webhooks) attempt(retry *webhookRetry) {
+	statusCode, err := webhooks.post(retry.webhook, retry.event, retry.delivery.Id, retry.body)
+
+	retry.attempts++
+	attempts := retry.attempts
+	delivered := err == nil && statusCode >= 200 && statusCode < 300
+	retryable := err != nil || statusCode == http.StatusTooManyRequests || statusCode >= 500
+
+	queued, full := false, false
+	if !delivered && retryable && attempts < webhookMaxAttempts {
+		retry.due = time.Now().Add(webhookRetryDelay(attempts))
+		queued = webhooks.queueRetry(retry)
+		full = !queued
+	}
+
+	var status, reason string
+	webhooks.update(retry.delivery, func(d *WebhookDelivery) {
+		d.Attempts = attempts
+		d.StatusCode = statusCode
+		d.Error = ""
+		switch {
+		case delivered:
+			d.Status = "delivered"
+		case queued:
+			d.Status = "retrying"
+		default:
+			d.Status = "failed"
+		}
+		if err != nil {
+			d.Error = err.Error()
+		} else if !delivered {
+			d.Error = fmt.Sprintf("bad status: %d", statusCode)
+		}
+		if full {
+			d.Error += " (retry queue full)"
+		}
+		status, reason = d.Status, d.Error
+	})
+
+	if status == "failed" && webhooks.controller != nil && webhooks.controller.Logs != nil {
+		webhooks.controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("webhook %q: %s delivery for call %d to %s failed after %d attempts: %s", retry.webhook.Name, retry.event, retry.callId, retry.webhook.Url, attempts, reason))
+	}
+}
+
+// queueRetry holds a failed delivery for its next attempt, or reports false
+// when webhookRetryQueue deliveries are already waiting.
+func (webhooks *Webhooks) queueRetry(retry *webhookRetry) bool {
+	webhooks.retryMutex.Lock()
+	defer webhooks.retryMutex.Unlock()
+
+	if len(webhooks.retries) >= webhookRetryQueue {
+		return false
+	}
+	webhooks.retries = append(webhooks.retries, retry)
+	return true
+}
+
+func (webhooks *Webhooks) scheduleRetries() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		webhooks.dispatchRetries(now)
+	}
+}
+
+// dispatchRetries hands due retries to idle workers.  Those that find every
+// worker busy stay queued for the next tick.
+func (webhooks *Webhooks) dispatchRetries(now time.Time) {
+	webhooks.retryMutex.Lock()
+	defer webhooks.retryMutex.Unlock()
+
+	waiting := webhooks.retries[:0]
+	for _, retry := range webhooks.retries {
+		if !retry.due.After(now) {
+			select {
+			case webhooks.retryDue <- retry:
+				continue
+			default:
+			}
+		}
+		waiting = append(waiting, retry)
+	}
+	clear(webhooks.retries[len(waiting):])
+	webhooks.retries = waiting
+}
+
+func (webhooks *Webhooks) post(webhook *Webhook, event string, deliveryId string, body []byte) (int, error) {
+	webhooks.slots <- struct{}{}
+	defer func() { <-webhooks.slots }()
+
+	req, err := http.NewRequest(http.MethodPost, webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := fmt.Sprintf("%d", time.Now().Unix())
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ThinLine-Radio-Webhook/"+Version)
+	req.Header.Set("X-TLR-Event", event)
+	req.Header.Set("X-TLR-Delivery", deliveryId)
+	req.Header.Set("X-TLR-Timestamp", timestamp)
+	if webhook.Secret != "" {
+		req.Header.Set("X-TLR-Signature", "sha256="+webhookSignature(webhook.Secret, timestamp, body))
+	}
+
+	res, err := webhooks.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	res.Body.Close()
+
+	return res.StatusCode, nil
+}
+
+// webhookSignature is the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with
+// the webhook secret, so receivers can verify the sender and reject replays.
+func webhookSignature(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookRetryDelay is the wait after failed attempt number attempt
+// (1-based): 10s, 20s, 40s... capped at webhookRetryMaxDelay.
+func webhookRetryDelay(attempt int) time.Duration {
+	delay := webhookRetryBaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= webhookRetryMaxDelay {
+			return webhookRetryMaxDelay
+		}
+	}
+	return delay
+}
+
+func (webhooks *Webhooks) record(delivery *WebhookDelivery) {
+	webhooks.logMutex.Lock()
+	defer webhooks.logMutex.Unlock()
+
+	webhooks.deliveries = append(webhooks.deliveries, delivery)
+	if over := len(webhooks.deliveries) - webhookDeliveryLog; over > 0 {
+		webhooks.deliveries = append([]*WebhookDelivery{}, webhooks.deliveries[over:]...)
+	}
+}
+
+func (webhooks *Webhooks) update(delivery *WebhookDelivery, fn func(d *WebhookDelivery)) {
+	webhooks.logMutex.Lock()
+	defer webhooks.logMutex.Unlock()
+
+	fn(delivery)
+	delivery.UpdatedAt = time.Now().UnixMilli()
+}
+
+// Deliveries returns the delivery log, newest first, optionally for one webhook.
+func (webhooks *Webhooks) Deliveries(webhookId uint64) []WebhookDelivery {
+	webhooks.logMutex.Lock()
+	defer webhooks.logMutex.Unlock()
+
+	list := []WebhookDelivery{}
+	for i := len(webhooks.deliveries) - 1; i >= 0; i-- {
+		if d := webhooks.deliveries[i]; webhookId == 0 || d.WebhookId == webhookId {
+			list = append(list, *d)
+		}
+	}
+	return list
+}
+
+// SendTest posts a sample call event to a webhook and waits for the result,
+// without retries, so the admin can check the endpoint and signature.
+func (webhooks *Webhooks) SendTest(webhook *Webhook) *WebhookDelivery {
+	test := *webhook
+	test.Disabled = false
+
+	now := time.Now()
+	payload := map[string]any{
+		"test": true,
+		"call": map[string]any{
+			"id":        0,
+			"dateTime":  now.UTC().Format(time.RFC3339),
+			"timestamp": now.UnixMilli(),
+			"system":    map[string]any{"id": 0, "label": "Test System"},
+			"talkgroup": map[string]any{"id": 0, "label": "TEST", "name": "Webhook test", "groups": []string{}},
+			"units":     []uint{},
+		},
+	}
+
+	id := make([]byte, 8)
+	rand.Read(id)
+	delivery := &WebhookDelivery{
+		Id:        hex.EncodeToString(id),
+		WebhookId: webhook.Id,
+		Name:      webhook.Name,
+		Url:       webhook.Url,
+		Event:     WebhookEventCall,
+		Attempts:  1,
+		CreatedAt: now.UnixMilli(),
+	}
+
+	body := map[string]any{"event": WebhookEventCall, "deliveryId": delivery.Id, "sentAt": now.UnixMilli()}
+	for k, v := range payload {
+		body[k] = v
+	}
+	b, _ := json.Marshal(body)
+
+	statusCode, err := webhooks.post(&test, WebhookEventCall, delivery.Id, b)
+	delivery.StatusCode = statusCode
+	delivery.UpdatedAt = time.Now().UnixMilli()
+	switch {
+	case err != nil:
+		delivery.Status, delivery.Error = "failed", err.Error()
+	case statusCode < 200 || statusCode >= 300:
+		delivery.Status, delivery.Error = "failed", fmt.Sprintf("bad status: %d", statusCode)
+	default:
+		delivery.Status = "delivered"
+	}
+	webhooks.record(delivery)
+
+	return delivery
+}
+
+func (webhooks *Webhooks) Read(db *Database) error {
+	webhooks.mutex.Lock()
+	defer webhooks.mutex.Unlock()
+
+	webhooks.List = []*Webhook{}
+
+	formatError := webhooks.errorFormatter("read")
+
+	query := `SELECT "webhookId", "disabled", "events", "name", "order", "secret", "systems", "tagIds", "url" FROM "webhooks"`
+	rows, err := db.Sql.Query(query)
+	if err != nil {
+		return formatError(err, query)
+	}
+
+	for rows.Next() {
+		var (
+			webhook = NewWebhook()
+			events  string
+			systems string
+			tagIds  string
+		)
+
+		if err = rows.Scan(&webhook.Id, &webhook.Disabled, &events, &webhook.Name, &webhook.Order, &webhook.Secret, &systems, &tagIds, &webhook.Url); err != nil {
+			break
+		}
+
+		json.Unmarshal([]byte(events), &webhook.Events)
+		if len(systems) > 0 {
+			json.Unmarshal([]byte(systems), &webhook.Systems)
+		}
+		json.Unmarshal([]byte(tagIds), &webhook.TagIds)
+
+		webhooks.List = append(webhooks.List, webhook)
+	}
+
+	rows.Close()
+
+	if err != nil {
+		return formatError(err, "")
+	}
+
+	sort.Slice(webhooks.List, func(i int, j int) bool {
+		return webhooks.List[i].Order < webhooks.List[j].Order
+	})
+
+	return nil
+}
+
+func (webhooks *Webhooks) Write(db *Database) error {
+	webhooks.mutex.Lock()
+	defer webhooks.mutex.Unlock()
+
+	formatError := webhooks.errorFormatter("write")
+
+	tx, err := db.Sql.Begin()
+	if err != nil {
+		return formatError(err, "")
+	}
+
+	keep := []any{}
+	for _, webhook := range webhooks.List {
+		if webhook.Id > 0 {
+			keep = append(keep, webhook.Id)
+		}
+	}
+
+	query := `DELETE FROM "webhooks"`
+	if len(keep) > 0 {
+		placeholders := make([]string, len(keep))
+		for i := range keep {
+			placeholders[i] = fmt.Sprintf("$%d", i+1)
+		}
+		query += ` WHERE "webhookId" NOT IN (` + strings.Join(placeholders, ", ") + `)`
+	}
+	if _, err = tx.Exec(query, keep...); err != nil {
+		tx.Rollback()
+		return formatError(err, query)
+	}
+
+	for _, webhook := range webhooks.List {
+		events, _ := json.Marshal(webhook.Events)
+		tagIds, _ := json.Marshal(webhook.TagIds)
+		systems := ""
+		if webhook.Systems != nil {
+			if b, err := json.Marshal(webhook.Systems); err == nil {
+				systems = string(b)
+			}
+		}
+
+		if webhook.Id > 0 {
+			query = `UPDATE "webhooks" SET "disabled" = $1, "events" = $2, "name" = $3, "order" = $4, "secret" = $5, "systems" = $6, "tagIds" = $7, "url" = $8 WHERE "webhookId" = $9`
+			var res sql.Result
+			if res, err = tx.Exec(query, webhook.Disabled, string(events), webhook.Name, webhook.Order, webhook.Secret, systems, string(tagIds), webhook.Url, webhook.Id); err != nil {
+				break
+			}
+			if n, _ := res.RowsAffected(); n > 0 {
+				continue
+			}
+		}
+
+		query = `INSERT INTO "webhooks" ("disabled", "events", "name", "order", "secret", "systems", "tagIds", "url") VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+		if _, err = tx.Exec(query, webhook.Disabled, string(events), webhook.Name, webhook.Order, webhook.Secret, systems, string(tagIds), webhook.Url); err != nil {
+			break
+		}
+	}
+
+	if err != nil {
+		tx.Rollback()
+		return formatError(err, query)
+	}
+
+	if err = tx.Commit(); err != nil {
+		tx.Rollback()
+		return formatError(err, "")
+	}
+
+	return nil
+}
+
+func (webhooks *Webhooks) errorFormatter(label string) func(err error, query string) error {
+	return func(err error, query string) error {
+		s := fmt.Sprintf("webhooks.%s: %s", label, err.Error())
+
+		if len(query) > 0 {
+			s = fmt.Sprintf("%s in %s", s, query)
+		}
+
+		return errors.New(s)
+	}
+}
+
+// WebhooksHandler is the API-driven endpoint for the admin Webhooks screen.
+//
+//	GET  /api/admin/webhooks    -> { "webhooks": [...] }
+//	PUT  /api/admin/webhooks    body: [...]  (full list)
+func (admin *Admin) WebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		json.NewEncoder(w).Encode(map[string]any{"webhooks": admin.Controller.Webhooks.GetWebhooks()})
+
+	case http.MethodPut:
+		var list []any
+		if err := json.NewDecoder(r.Body).Decode(&list); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		for _, item := range list {
+			m, _ := item.(map[string]any)
+			webhook := NewWebhook().FromMap(m)
+			if u, err := url.Parse(webhook.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("invalid webhook URL %q", webhook.Url)})
+				return
+			}
+		}
+
+		admin.mutex.Lock()
+		admin.Controller.Webhooks.FromMap(list)
+		err := admin.Controller.Webhooks.Write(admin.Controller.Database)
+		if err == nil {
+			err = admin.Controller.Webhooks.Read(admin.Controller.Database)
+		}
+		admin.mutex.Unlock()
+
+		if err != nil {
+			admin.Controller.Logs.LogEvent(LogLevelError, fmt.Sprintf("admin.webhooks.put: %s", err.Error()))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{"webhooks": admin.Controller.Webhooks.GetWebhooks()})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// WebhookDeliveriesHandler returns the delivery log, newest first (GET,
+// optional ?webhookId=), or sends a test event to a saved webhook (POST
+// {"id": n}).
+func (admin *Admin) WebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	t := admin.GetAuthorization(r)
+	if !admin.ValidateToken(t) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		webhookId, _ := strconv.ParseUint(r.URL.Query().Get("webhookId"), 10, 64)
+		deliveries := admin.Controller.Webhooks.Deliveries(webhookId)
+		json.NewEncoder(w).Encode(map[string]any{"deliveries": deliveries, "count": len(deliveries)})
+
+	case http.MethodPost:
+		var request struct {
+			Id uint64 `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		webhook, ok := admin.Controller.Webhooks.GetWebhookById(request.Id)
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "webhook not found"})
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{"delivery": admin.Controller.Webhooks.SendTest(webhook)})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookMatches(t *testing.T) {
+	call := &Call{System: &System{SystemRef: 1}, Talkgroup: &Talkgroup{TalkgroupRef: 100, TagId: 3}}
+
+	webhook := NewWebhook().FromMap(map[string]any{
+		"url":     "https://hooks.example.org/x",
+		"events":  []any{"alert"},
+		"systems": []any{map[string]any{"id": float64(1), "talkgroups": []any{float64(100)}}},
+		"tagIds":  []any{float64(3)},
+	})
+
+	if webhook.Matches(WebhookEventCall, call) {
+		t.Fatal("call event matched an alert-only webhook")
+	}
+	if !webhook.Matches(WebhookEventAlert, call) {
+		t.Fatal("alert on a selected talkgroup and tag did not match")
+	}
+
+	call.Talkgroup.TagId = 4
+	if webhook.Matches(WebhookEventAlert, call) {
+		t.Fatal("alert matched with a tag outside the filter")
+	}
+
+	call.Talkgroup = &Talkgroup{TalkgroupRef: 200, TagId: 3}
+	if webhook.Matches(WebhookEventAlert, call) {
+		t.Fatal("alert matched on a talkgroup outside the selection")
+	}
+}
+
+func TestWebhookDeliverySignedAndLogged(t *testing.T) {
+	var received struct {
+		signature, timestamp string
+		body                 []byte
+	}
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.signature = r.Header.Get("X-TLR-Signature")
+		received.timestamp = r.Header.Get("X-TLR-Timestamp")
+		received.body, _ = io.ReadAll(r.Body)
+	}))
+	defer ok.Close()
+	rejected := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer rejected.Close()
+
+	webhooks := NewWebhooks(nil)
+
+	delivery := webhooks.deliver(&Webhook{Id: 1, Url: ok.URL, Secret: "s3cret"}, WebhookEventCall, 42, map[string]any{"call": map[string]any{"id": 42}})
+	if delivery.Status != "delivered" || delivery.Attempts != 1 {
+		t.Fatalf("delivery = %+v", delivery)
+	}
+	if want := "sha256=" + webhookSignature("s3cret", received.timestamp, received.body); received.signature != want {
+		t.Fatalf("signature %q, want %q", received.signature, want)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(received.body, &body); err != nil || body["event"] != "call" || body["deliveryId"] != delivery.Id {
+		t.Fatalf("body = %s", received.body)
+	}
+
+	// A 4xx answer is final: no retries.
+	delivery = webhooks.deliver(&Webhook{Id: 2, Url: rejected.URL}, WebhookEventAlert, 43, map[string]any{})
+	if delivery.Status != "failed" || delivery.Attempts != 1 || delivery.StatusCode != http.StatusBadRequest {
+		t.Fatalf("rejected delivery = %+v", delivery)
+	}
+
+	if log := webhooks.Deliveries(0); len(log) != 2 || log[0].WebhookId != 2 {
+		t.Fatalf("delivery log = %+v", log)
+	}
+	if log := webhooks.Deliveries(1); len(log) != 1 || log[0].CallId != 42 {
+		t.Fatalf("delivery log for webhook 1 = %+v", log)
+	}
+}
+
+func TestWebhookRetryQueue(t *testing.T) {
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+
+	webhooks := NewWebhooks(nil)
+	webhook := &Webhook{Id: 1, Url: unavailable.URL}
+
+	// A 5xx answer is queued for a later attempt instead of waiting in its
+	// own goroutine.
+	delivery := webhooks.deliver(webhook, WebhookEventCall, 42, map[string]any{})
+	if log := webhooks.Deliveries(1); len(log) != 1 || log[0].Status != "retrying" || log[0].Attempts != 1 {
+		t.Fatalf("delivery log = %+v", log)
+	}
+	webhooks.retryMutex.Lock()
+	queued := len(webhooks.retries)
+	due := webhooks.retries[0].due
+	webhooks.retryMutex.Unlock()
+	if queued != 1 || time.Until(due) < webhookRetryBaseDelay-time.Second {
+		t.Fatalf("%d retries queued, due in %s", queued, time.Until(due))
+	}
+
+	// When due, a worker makes the next attempt and queues it again.
+	webhooks.retryMutex.Lock()
+	webhooks.retries[0].due = time.Now()
+	webhooks.retryMutex.Unlock()
+	webhooks.dispatchRetries(time.Now())
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if log := webhooks.Deliveries(1); log[0].Attempts == 2 && log[0].Status == "retrying" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("retry not attempted: %+v", webhooks.Deliveries(1))
+		}
+	}
+
+	// Once the queue is full, failures are final.
+	webhooks.retryMutex.Lock()
+	for len(webhooks.retries) < webhookRetryQueue {
+		webhooks.retries = append(webhooks.retries, &webhookRetry{webhook: webhook, delivery: delivery, due: time.Now().Add(time.Hour)})
+	}
+	webhooks.retryMutex.Unlock()
+
+	webhooks.deliver(webhook, WebhookEventCall, 43, map[string]any{})
+	if log := webhooks.Deliveries(1); log[0].CallId != 43 || log[0].Status != "failed" || !strings.Contains(log[0].Error, "retry queue full") {
+		t.Fatalf("delivery with a full retry queue = %+v", log[0])
+	}
+	webhooks.retryMutex.Lock()
+	queued = len(webhooks.retries)
+	webhooks.retryMutex.Unlock()
+	if queued != webhookRetryQueue {
+		t.Fatalf("%d retries queued, want %d", queued, webhookRetryQueue)
+	}
+}
+
+func TestWebhookRetryDelay(t *testing.T) {
+	for attempt, want := range map[int]time.Duration{1: 10 * time.Second, 2: 20 * time.Second, 3: 40 * time.Second, 10: webhookRetryMaxDelay} {
+		if got := webhookRetryDelay(attempt); got != want {
+			t.Fatalf("webhookRetryDelay(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+}
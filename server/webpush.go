@@ -0,0 +1,418 @@
+// Copyright (C) 2025 Thinline Dynamic Solutions
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>
+
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/crypto/hkdf"
+)
+
+// DeviceTokenPushTypeWebPush marks a device token that is a browser Web Push
+// subscription delivered directly with VAPID rather than through the relay.
+const DeviceTokenPushTypeWebPush = "webpush"
+
+const (
+	webPushRecordSize  = 4096
+	webPushTTL         = 3600
+	webPushMaxBodyLen  = 1000
+	webPushJWTLifetime = 12 * time.Hour
+)
+
+// WebPushSubscription is the JSON form of a browser PushSubscription.
+type WebPushSubscription struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+var vapidMutex sync.Mutex
+
+// vapidKeys returns the server's VAPID key pair, generating and persisting one
+// the first time Web Push is used.
+func (controller *Controller) vapidKeys() (*ecdsa.PrivateKey, string, error) {
+	vapidMutex.Lock()
+	defer vapidMutex.Unlock()
+
+	controller.Options.mutex.Lock()
+	privB64, pubB64 := controller.Options.vapidPrivateKey, controller.Options.vapidPublicKey
+	controller.Options.mutex.Unlock()
+
+	if privB64 != "" {
+		der, err := base64.RawURLEncoding.DecodeString(privB64)
+		if err != nil {
+			return nil, "", fmt.Errorf("webpush: decode vapid key: %w", err)
+		}
+		parsed, err := x509.ParsePKCS8PrivateKey(der)
+		if err != nil {
+			return nil, "", fmt.Errorf("webpush: parse vapid key: %w", err)
+		}
+		key, ok := parsed.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, "", errors.New("webpush: vapid key is not ECDSA")
+		}
+		return key, pubB64, nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, "", fmt.Errorf("webpush: generate vapid key: %w", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, "", fmt.Errorf("webpush: marshal vapid key: %w", err)
+	}
+	ecdhKey, err := key.PublicKey.ECDH()
+	if err != nil {
+		return nil, "", fmt.Errorf("webpush: vapid public key: %w", err)
+	}
+	privB64 = base64.RawURLEncoding.EncodeToString(der)
+	pubB64 = base64.RawURLEncoding.EncodeToString(ecdhKey.Bytes())
+
+	if err := controller.Options.WriteKey(controller.Database, "vapidPrivateKey", privB64, func() {
+		controller.Options.vapidPrivateKey = privB64
+	}); err != nil {
+		return nil, "", err
+	}
+	if err := controller.Options.WriteKey(controller.Database, "vapidPublicKey", pubB64, func() {
+		controller.Options.vapidPublicKey = pubB64
+	}); err != nil {
+		return nil, "", err
+	}
+
+	log.Printf("webpush: generated VAPID key pair")
+	return key, pubB64, nil
+}
+
+// encryptWebPushPayload encrypts a payload for one subscription using the
+// aes128gcm content coding from RFC 8291, as a single record.
+func encryptWebPushPayload(sub *WebPushSubscription, payload []byte) ([]byte, error) {
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return encryptWebPushPayloadWith(sub, payload, asPrivate, salt)
+}
+
+func encryptWebPushPayloadWith(sub *WebPushSubscription, payload []byte, asPrivate *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	uaPublicBytes, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(sub.Keys.P256dh, "="))
+	if err != nil {
+		return nil, fmt.Errorf("webpush: decode p256dh: %w", err)
+	}
+	authSecret, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(sub.Keys.Auth, "="))
+	if err != nil {
+		return nil, fmt.Errorf("webpush: decode auth: %w", err)
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicBytes)
+	if err != nil {
+		return nil, fmt.Errorf("webpush: p256dh: %w", err)
+	}
+	if len(payload)+17 > webPushRecordSize {
+		return nil, errors.New("webpush: payload too large")
+	}
+
+	sharedSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+	asPublicBytes := asPrivate.PublicKey().Bytes()
+
+	keyInfo := append(append([]byte("WebPush: info\x00"), uaPublicBytes...), asPublicBytes...)
+	ikm := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, sharedSecret, authSecret, keyInfo), ikm); err != nil {
+		return nil, err
+	}
+	cek := make([]byte, 16)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, ikm, salt, []byte("Content-Encoding: aes128gcm\x00")), cek); err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, 12)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, ikm, salt, []byte("Content-Encoding: nonce\x00")), nonce); err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// 0x02 marks the last (and only) record; no padding.
+	ciphertext := gcm.Seal(nil, nonce, append(payload, 0x02), nil)
+
+	body := make([]byte, 0, 16+4+1+len(asPublicBytes)+len(ciphertext))
+	body = append(body, salt...)
+	body = binary.BigEndian.AppendUint32(body, webPushRecordSize)
+	body = append(body, byte(len(asPublicBytes)))
+	body = append(body, asPublicBytes...)
+	body = append(body, ciphertext...)
+	return body, nil
+}
+
+// vapidAuthorization builds the VAPID Authorization header for a push service endpoint.
+func (controller *Controller) vapidAuthorization(endpoint string) (string, error) {
+	key, publicKey, err := controller.vapidKeys()
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("webpush: endpoint: %w", err)
+	}
+
+	subject := "mailto:" + controller.Options.Email
+	if controller.Options.Email == "" {
+		if controller.Options.BaseUrl != "" {
+			subject = controller.Options.BaseUrl
+		} else {
+			subject = "mailto:admin@localhost"
+		}
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": fmt.Sprintf("%s://%s", u.Scheme, u.Host),
+		"exp": time.Now().Add(webPushJWTLifetime).Unix(),
+		"sub": subject,
+	})
+	signed, err := token.SignedString(key)
+	if err != nil {
+		return "", fmt.Errorf("webpush: sign vapid jwt: %w", err)
+	}
+	return fmt.Sprintf("vapid t=%s, k=%s", signed, publicKey), nil
+}
+
+// sendWebPush delivers one notification to a browser subscription. gone reports
+// that the push service no longer knows the subscription and it should be deleted.
+func (controller *Controller) sendWebPush(device *DeviceToken, title, message string, data map[string]interface{}) (gone bool, err error) {
+	var sub WebPushSubscription
+	if err := json.Unmarshal([]byte(device.Subscription), &sub); err != nil || sub.Endpoint == "" {
+		return true, errors.New("webpush: invalid stored subscription")
+	}
+
+	if runes := []rune(message); len(runes) > webPushMaxBodyLen {
+		message = string(runes[:webPushMaxBodyLen]) + "…"
+	}
+	// Angular service worker (ngsw) notification format.
+	payload, err := json.Marshal(map[string]interface{}{
+		"notification": map[string]interface{}{
+			"title": title,
+			"body":  message,
+			"icon":  "assets/icons/icon-192x192.png",
+			"data":  data,
+			// Quiet hours: the browser shows the notification without sound or vibration.
+			"silent": device.InQuietHours(time.Now()),
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	body, err := encryptWebPushPayload(&sub, payload)
+	if err != nil {
+		return false, err
+	}
+	authorization, err := controller.vapidAuthorization(sub.Endpoint)
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", fmt.Sprintf("%d", webPushTTL))
+	req.Header.Set("Urgency", "high")
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return true, fmt.Errorf("webpush: subscription expired (status %d)", resp.StatusCode)
+	case resp.StatusCode >= 300:
+		return false, fmt.Errorf("webpush: push service returned status %d", resp.StatusCode)
+	}
+	return false, nil
+}
+
+// webPushCallData carries the call identifiers the webapp needs to open the call
+// from a notification click.
+func webPushCallData(call *Call) map[string]interface{} {
+	data := map[string]interface{}{}
+	if call == nil {
+		return data
+	}
+	data["callId"] = fmt.Sprintf("%d", call.Id)
+	if call.System != nil {
+		data["systemId"] = fmt.Sprintf("%d", call.System.Id)
+	}
+	if call.Talkgroup != nil {
+		data["talkgroupId"] = fmt.Sprintf("%d", call.Talkgroup.Id)
+	}
+	return data
+}
+
+// sendWebPushBatch sends a notification to each Web Push device and removes
+// subscriptions the push service reports as gone.
+func (controller *Controller) sendWebPushBatch(devices []*DeviceToken, title, message string, data map[string]interface{}) {
+	for _, device := range devices {
+		gone, err := controller.sendWebPush(device, title, message, data)
+		if gone {
+			if delErr := controller.DeviceTokens.Delete(device.Id, controller.Database, controller.Clients); delErr != nil {
+				controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("push notification: failed to remove expired web push subscription %d for user %d: %v", device.Id, device.UserId, delErr))
+			}
+		}
+		if err != nil {
+			controller.Logs.LogEvent(LogLevelWarn, fmt.Sprintf("push notification: web push to user %d failed: %v", device.UserId, err))
+		}
+	}
+}
+
+// UserWebPushHandler manages the caller's browser Web Push subscription.
+// GET returns the VAPID public key to pass to PushManager.subscribe, POST
+// registers a PushSubscription and DELETE removes one by endpoint.
+func (api *Api) UserWebPushHandler(w http.ResponseWriter, r *http.Request) {
+	client := api.getClient(r)
+	if client == nil || client.User == nil {
+		api.exitWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		_, publicKey, err := api.Controller.vapidKeys()
+		if err != nil {
+			log.Printf("webpush: %v", err)
+			api.exitWithError(w, http.StatusInternalServerError, "Web push unavailable")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"publicKey": publicKey,
+		})
+
+	case http.MethodPost:
+		var sub WebPushSubscription
+		if err := json.NewDecoder(r.Body).Decode(&sub); err != nil {
+			api.exitWithError(w, http.StatusBadRequest, "Invalid JSON")
+			return
+		}
+		if u, err := url.Parse(sub.Endpoint); err != nil || u.Scheme != "https" || u.Host == "" {
+			api.exitWithError(w, http.StatusBadRequest, "Invalid subscription endpoint")
+			return
+		}
+		if sub.Keys.P256dh == "" || sub.Keys.Auth == "" {
+			api.exitWithError(w, http.StatusBadRequest, "Subscription keys are required")
+			return
+		}
+		subscriptionJSON, err := json.Marshal(sub)
+		if err != nil {
+			api.exitWithError(w, http.StatusBadRequest, "Invalid subscription")
+			return
+		}
+
+		if existing := api.Controller.DeviceTokens.FindByUserAndToken(client.User.Id, sub.Endpoint); existing != nil {
+			existing.PushType = DeviceTokenPushTypeWebPush
+			existing.Platform = "web"
+			existing.Subscription = string(subscriptionJSON)
+			if err := api.Controller.DeviceTokens.Update(existing, api.Controller.Database); err != nil {
+				api.exitWithError(w, http.StatusInternalServerError, "Failed to update web push subscription")
+				return
+			}
+		} else {
+			deviceToken := &DeviceToken{
+				UserId:       client.User.Id,
+				Token:        sub.Endpoint,
+				PushType:     DeviceTokenPushTypeWebPush,
+				Platform:     "web",
+				Subscription: string(subscriptionJSON),
+				CreatedAt:    time.Now().Unix(),
+				LastUsed:     time.Now().Unix(),
+			}
+			if err := api.Controller.DeviceTokens.Add(deviceToken, api.Controller.Database); err != nil {
+				api.exitWithError(w, http.StatusInternalServerError, "Failed to register web push subscription")
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": "Web push subscription registered successfully",
+		})
+
+	case http.MethodDelete:
+		var request struct {
+			Endpoint string `json:"endpoint"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Endpoint == "" {
+			api.exitWithError(w, http.StatusBadRequest, "endpoint is required")
+			return
+		}
+		existing := api.Controller.DeviceTokens.FindByUserAndToken(client.User.Id, request.Endpoint)
+		if existing == nil {
+			api.exitWithError(w, http.StatusNotFound, "Subscription not found")
+			return
+		}
+		if err := api.Controller.DeviceTokens.Delete(existing.Id, api.Controller.Database, api.Controller.Clients); err != nil {
+			api.exitWithError(w, http.StatusInternalServerError, "Failed to remove web push subscription")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": "Web push subscription removed successfully",
+		})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}